@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// errCodeAccessDenied is an error code of requests rejected by access rules.
+const errCodeAccessDenied = "ACCESS_DENIED"
+
+// allRoutes is a route name matching all routes in access rules.
+const allRoutes = "*"
+
+// accessRule restricts client addresses of requests to the routes, addresses
+// from the deny list are rejected, the allow list (if it's set) contains the
+// only addresses accepted. Rules of tenants are applied to requests to their
+// containers only, containers are compared by resolved IDs, so any spelling of
+// the container in the request path is matched.
+type accessRule struct {
+	routes     map[string]struct{}
+	containers []string
+	allow      []netip.Prefix
+	deny       []netip.Prefix
+}
+
+// accessRules are rules of route access by client addresses, the request
+// must satisfy all rules of its route.
+type accessRules []accessRule
+
+func newAccessRule(routes, allow, deny []string) (accessRule, error) {
+	var (
+		r   = accessRule{routes: make(map[string]struct{}, len(routes))}
+		err error
+	)
+	for _, route := range routes {
+		r.routes[route] = struct{}{}
+	}
+	if r.allow, err = clientip.ParsePrefixes(allow); err != nil {
+		return r, fmt.Errorf("allow: %w", err)
+	}
+	if r.deny, err = clientip.ParsePrefixes(deny); err != nil {
+		return r, fmt.Errorf("deny: %w", err)
+	}
+	return r, nil
+}
+
+// scoped limits the rule to requests to the given containers (IDs or names as
+// in request paths).
+func (r accessRule) scoped(containers []string) accessRule {
+	r.containers = append(make([]string, 0, len(containers)), containers...)
+	return r
+}
+
+func (r accessRule) hasRoute(route string) bool {
+	_, ok := r.routes[route]
+	if !ok {
+		_, ok = r.routes[allRoutes]
+	}
+	return ok
+}
+
+// hasContainer checks whether the rule containers include the container,
+// containers which can't be resolved are skipped.
+func (r accessRule) hasContainer(ctx context.Context, cnr cid.ID, res resolver.Resolver) bool {
+	for _, name := range r.containers {
+		if id, err := utils.GetContainerID(ctx, name, res); err == nil && id.Equals(cnr) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo checks whether the rule applies to the request to the route and
+// the container (nil if the request isn't sent to a container).
+func (r accessRule) appliesTo(ctx context.Context, route string, cnr *cid.ID, res resolver.Resolver) bool {
+	if !r.hasRoute(route) {
+		return false
+	}
+	return r.containers == nil || cnr != nil && r.hasContainer(ctx, *cnr, res)
+}
+
+func (r accessRule) allowed(addr netip.Addr) bool {
+	if containsAddr(r.deny, addr) {
+		return false
+	}
+	return len(r.allow) == 0 || containsAddr(r.allow, addr)
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedTo checks whether any of the rules limited to containers applies to
+// the route, the request container must be resolved to check them.
+func (rs accessRules) scopedTo(route string) bool {
+	for _, r := range rs {
+		if r.containers != nil && r.hasRoute(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns the index of the first rule of the route and the container
+// rejecting the client address, addresses that can't be parsed are rejected
+// by any rule. Containers of the rules are resolved with res.
+func (rs accessRules) check(ctx context.Context, route string, cnr *cid.ID, ip net.IP, res resolver.Resolver) (int, bool) {
+	addr, ok := netip.AddrFromSlice(ip)
+	addr = addr.Unmap()
+	for i, r := range rs {
+		if r.appliesTo(ctx, route, cnr, res) && (!ok || !r.allowed(addr)) {
+			return i, false
+		}
+	}
+	return 0, true
+}
+
+// updateAccessRules sets the rules of route access by client addresses from
+// the gate configuration and tenants, requests aren't restricted if there are
+// no rules.
+func (a *app) updateAccessRules() error {
+	var rules accessRules
+	for i := 0; ; i++ {
+		key := cfgAccessControl + "." + strconv.Itoa(i) + "."
+		routes := a.cfg.GetStringSlice(key + "routes")
+		if len(routes) == 0 {
+			break
+		}
+		r, err := newAccessRule(routes, a.cfg.GetStringSlice(key+"allow"), a.cfg.GetStringSlice(key+"deny"))
+		if err != nil {
+			return fmt.Errorf("access rule %d: %w", i, err)
+		}
+		rules = append(rules, r)
+	}
+	for _, t := range a.tenants {
+		for i, tr := range t.AccessRules {
+			r, err := newAccessRule(tr.Routes, tr.Allow, tr.Deny)
+			if err != nil {
+				return fmt.Errorf("access rule %d of tenant '%s': %w", i, t.Name, err)
+			}
+			rules = append(rules, r.scoped(t.ContainerNames()))
+		}
+	}
+	a.accessRules.Store(&rules)
+	return nil
+}
+
+// accessAllowed checks whether the client address of the request is allowed
+// to access the route, rejected requests are logged and get 403 Forbidden.
+func (a *app) accessAllowed(ctx *fasthttp.RequestCtx, route string) bool {
+	rules := a.accessRules.Load()
+	if rules == nil {
+		return true
+	}
+	var (
+		ip  = clientip.Load(ctx)
+		cnr *cid.ID
+	)
+	if rules.scopedTo(route) {
+		var err error
+		if cnr, err = a.requestContainer(ctx); err != nil {
+			utils.ContainerIDError(ctx, a.log, err)
+			return false
+		}
+	}
+	rule, ok := rules.check(ctx, route, cnr, ip, a.resolverContainer)
+	if !ok {
+		a.log.Warn("request denied by access rule", zap.String("route", route), zap.Int("rule", rule),
+			zap.Stringer("remote", ip), zap.ByteString("method", ctx.Method()),
+			zap.ByteString("path", ctx.Path()), zap.Uint64("id", ctx.ID()))
+		response.ErrorWithCode(ctx, errCodeAccessDenied, "access denied", fasthttp.StatusForbidden)
+	}
+	return ok
+}
+
+// defaultBearerToken returns the default bearer token for the request if its
+// client is trusted and it's sent to one of the token containers of the main
+// network, containers are compared by resolved IDs. The token is attached
+// only to requests without tokens of their own.
+func (a *app) defaultBearerToken(ctx *fasthttp.RequestCtx) *bearer.Token {
+	d := a.bearerDefaults.Load()
+	if d == nil {
+		return nil
+	}
+	if addr, ok := netip.AddrFromSlice(clientip.Load(ctx)); !ok || !d.Trusts(addr) {
+		return nil
+	}
+	if n, _ := a.networkOf(ctx.Host(), ctx.RequestURI()); n != nil {
+		return nil
+	}
+	cnr, err := a.requestContainer(ctx)
+	if err != nil || cnr == nil || !d.HasContainer(ctx, *cnr, a.resolverContainer) {
+		return nil
+	}
+	return d.Token()
+}
+
+// requestContainer resolves the container the request is sent to, it's taken
+// from the container route parameter or from the object address. Nil is
+// returned if the request isn't sent to a container or the address is invalid,
+// the handler rejects such requests.
+func (a *app) requestContainer(ctx *fasthttp.RequestCtx) (*cid.ID, error) {
+	name, _ := ctx.UserValue("cid").(string)
+	if raw, ok := ctx.UserValue("address").(string); ok && name == "" {
+		addr, err := url.PathUnescape(raw)
+		if err != nil {
+			addr = raw
+		}
+		name, _, _ = utils.SplitAddress(addr)
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	var res resolver.Resolver = a.resolverContainer
+	if n, _ := a.networkOf(ctx.Host(), ctx.RequestURI()); n != nil {
+		res = n.resolver
+	}
+	return utils.GetContainerID(ctx, name, res)
+}
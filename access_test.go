@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tenants"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestAccessRules(t *testing.T) {
+	ctx := context.Background()
+	internal, err := newAccessRule([]string{"upload", "delete"}, []string{"10.0.0.0/8", "::1"}, []string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	blocked, err := newAccessRule([]string{allRoutes}, nil, []string{"192.0.2.1"})
+	require.NoError(t, err)
+	rules := accessRules{internal, blocked}
+
+	for _, tc := range []struct {
+		route, ip string
+		rule      int
+		allowed   bool
+	}{
+		{route: "upload", ip: "10.0.0.1", allowed: true},
+		{route: "upload", ip: "::ffff:10.0.0.1", allowed: true},
+		{route: "upload", ip: "::1", allowed: true},
+		{route: "upload", ip: "10.1.0.1", rule: 0},
+		{route: "upload", ip: "203.0.113.1", rule: 0},
+		{route: "get", ip: "203.0.113.1", allowed: true},
+		{route: "get", ip: "192.0.2.1", rule: 1},
+		{route: "delete", ip: "192.0.2.1", rule: 0},
+	} {
+		rule, allowed := rules.check(ctx, tc.route, nil, net.ParseIP(tc.ip), nil)
+		require.Equal(t, tc.allowed, allowed, tc)
+		if !allowed {
+			require.Equal(t, tc.rule, rule, tc)
+		}
+	}
+
+	_, allowed := rules.check(ctx, "upload", nil, nil, nil)
+	require.False(t, allowed)
+	_, allowed = rules[:1].check(ctx, "info", nil, nil, nil)
+	require.True(t, allowed)
+
+	// tenant rules are applied to their containers only
+	tenant, err := newAccessRule([]string{"upload"}, []string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+	photos, docs := cidtest.ID(), cidtest.ID()
+	res, err := resolver.NewStaticResolver(map[string]string{"photos": photos.EncodeToString()})
+	require.NoError(t, err)
+	rules = accessRules{tenant.scoped([]string{"photos", "unknown"})}
+	require.True(t, rules.scopedTo("upload"))
+	require.False(t, rules.scopedTo("get"))
+	_, allowed = rules.check(ctx, "upload", &photos, net.ParseIP("203.0.113.1"), res)
+	require.False(t, allowed)
+	_, allowed = rules.check(ctx, "upload", &docs, net.ParseIP("203.0.113.1"), res)
+	require.True(t, allowed)
+	_, allowed = rules.check(ctx, "upload", nil, net.ParseIP("203.0.113.1"), res)
+	require.True(t, allowed)
+
+	_, err = newAccessRule([]string{"upload"}, []string{"10.0.0.0/33"}, nil)
+	require.Error(t, err)
+}
+
+func TestRouterAccessRules(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.cfg.Set(cfgPoolRedialInterval, time.Second)
+	a.cfg.SetConfigType("yaml")
+	require.NoError(t, a.cfg.ReadConfig(strings.NewReader(`
+access_control:
+  - routes: [upload]
+    allow: [10.0.0.0/8]
+`)))
+	require.NoError(t, a.updateAccessRules())
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+	request := func(method, path, remote string) *fasthttp.RequestCtx {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(path)
+		ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(remote)})
+		a.webServer.Handler(&ctx)
+		return &ctx
+	}
+
+	ctx := request(fasthttp.MethodPost, "/upload/cid", "203.0.113.1")
+	require.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+	require.Equal(t, errCodeAccessDenied, string(ctx.Response.Header.Peek(response.HeaderErrorCode)))
+
+	// the gate isn't connected to NeoFS, so allowed requests aren't served anyway
+	ctx = request(fasthttp.MethodPost, "/upload/cid", "10.0.0.1")
+	require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+	ctx = request(fasthttp.MethodGet, "/get/cid/oid", "203.0.113.1")
+	require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+}
+
+func TestRouterAccessRulesAllRoutes(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.cfg.Set(cfgPoolRedialInterval, time.Second)
+	a.cfg.SetConfigType("yaml")
+	require.NoError(t, a.cfg.ReadConfig(strings.NewReader(`
+access_control:
+  - routes: ["*"]
+    allow: [10.0.0.0/8]
+`)))
+	require.NoError(t, a.updateAccessRules())
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+	for _, path := range []string{"/upload_progress/id", "/import_status/id", "/v1/jobs/id"} {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+		ctx.Request.SetRequestURI(path)
+		ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP("203.0.113.1")})
+		a.webServer.Handler(&ctx)
+		require.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode(), path)
+	}
+}
+
+func TestRouterTenantAccessRules(t *testing.T) {
+	photos := cidtest.ID()
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+		tenants: []tenants.Tenant{{
+			Name:        "acme",
+			Aliases:     map[string]string{"photos": photos.EncodeToString()},
+			AccessRules: []tenants.AccessRule{{Routes: []string{allRoutes}, Allow: []string{"10.0.0.0/8"}}},
+		}},
+	}
+	a.cfg.Set(cfgPoolRedialInterval, time.Second)
+	var err error
+	a.resolverContainer, err = resolver.NewContainer(context.Background(), resolver.Config{Aliases: a.aliases()})
+	require.NoError(t, err)
+	require.NoError(t, a.updateAccessRules())
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+	request := func(method, path string) *fasthttp.RequestCtx {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(path)
+		ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP("203.0.113.1")})
+		a.webServer.Handler(&ctx)
+		return &ctx
+	}
+
+	obj := oidtest.ID().EncodeToString()
+	for _, path := range []string{
+		"/get/photos/" + obj,
+		"/get/" + photos.EncodeToString() + "/" + obj,
+		"/get/photos:" + obj,
+		"/get/photos%3A" + obj,
+	} {
+		for _, method := range []string{fasthttp.MethodGet, fasthttp.MethodHead} {
+			ctx := request(method, path)
+			require.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode(), method+" "+path)
+		}
+	}
+
+	// other containers aren't restricted, the gate isn't connected to NeoFS
+	// to serve them
+	ctx := request(fasthttp.MethodGet, "/get/"+cidtest.ID().EncodeToString()+":"+obj)
+	require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+	ctx = request(fasthttp.MethodGet, "/get/unknown:"+obj)
+	require.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestDefaultBearerToken(t *testing.T) {
+	tkn := new(bearer.Token)
+	private, public := cidtest.ID(), cidtest.ID()
+	a := &app{}
+	a.bearerDefaults.Store(tokens.NewDefaults(tkn, []string{private.EncodeToString()},
+		[]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}))
+
+	request := func(cnr cid.ID, remote string) *fasthttp.RequestCtx {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("/get/" + cnr.EncodeToString() + "/" + oidtest.ID().EncodeToString())
+		ctx.SetUserValue("cid", cnr.EncodeToString())
+		ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(remote)})
+		return &ctx
+	}
+
+	require.Equal(t, tkn, a.defaultBearerToken(request(private, "10.0.0.1")))
+	require.Nil(t, a.defaultBearerToken(request(private, "203.0.113.1")), "untrusted client")
+	require.Nil(t, a.defaultBearerToken(request(public, "10.0.0.1")), "other container")
+
+	a.bearerDefaults.Store(nil)
+	require.Nil(t, a.defaultBearerToken(request(private, "10.0.0.1")))
+}
@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/fasthttp/router"
 	"github.com/nspcc-dev/neo-go/cli/flags"
@@ -17,11 +22,33 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-http-gw/attrcache"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/chaos"
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/coalesce"
+	"github.com/nspcc-dev/neofs-http-gw/compat"
 	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/hashring"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
+	"github.com/nspcc-dev/neofs-http-gw/maintenance"
+	"github.com/nspcc-dev/neofs-http-gw/metacache"
 	"github.com/nspcc-dev/neofs-http-gw/metrics"
+	"github.com/nspcc-dev/neofs-http-gw/pathfilter"
+	"github.com/nspcc-dev/neofs-http-gw/placement"
+	"github.com/nspcc-dev/neofs-http-gw/purge"
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
 	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/searchlimit"
+	"github.com/nspcc-dev/neofs-http-gw/servertiming"
+	"github.com/nspcc-dev/neofs-http-gw/streamlimit"
+	"github.com/nspcc-dev/neofs-http-gw/tenants"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/transform"
 	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-http-gw/usage"
 	"github.com/nspcc-dev/neofs-http-gw/utils"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
@@ -34,24 +61,64 @@ import (
 
 const (
 	defaultObjectSize = int64(1 << 21) // 2MB
+
+	// otherContainerLabel is a container label value for requests to
+	// containers that are not listed in the metrics configuration.
+	otherContainerLabel = "other"
 )
 
 type (
 	app struct {
 		log               *zap.Logger
 		logLevel          zap.AtomicLevel
-		pool              *pool.Pool
+		pool              *backend.Pool
 		poolStat          *stat.PoolStat
+		poolConnected     atomic.Bool
 		owner             *user.ID
 		cfg               *viper.Viper
 		webServer         *fasthttp.Server
+		routes            fasthttp.RequestHandler
 		webDone           chan struct{}
 		resolverContainer *resolver.Container
 		metrics           *gateMetrics
 		services          []*metrics.Service
+		pusher            *metrics.Pusher
+		placements        map[string]*placement.Backend
+		metaCaches        map[string]*metacache.Backend
+		coalescers        map[string]*coalesce.Backend
+		streamLimits      map[string]*streamlimit.Backend
+		bearerDefaults    atomic.Pointer[tokens.Defaults]
+		resolutions       map[string]*attrcache.Cache
+		pathFilters       map[string]*pathfilter.Filters
 		settings          *appSettings
 		servers           []Server
 		signer            user.Signer
+		clientIP          *clientip.Extractor
+		jobs              *jobs.Manager
+		gc                *maintenance.Worker
+		versions          *compat.Versions
+		usage             *usage.Tracker
+		accounting        http.Handler
+		purgers           *purge.Purgers
+		transforms        *transform.Hooks
+		networks          []*network
+		networkHosts      map[string]*network
+		allowedHosts      atomic.Pointer[utils.HostAllowlist]
+		accessRules       atomic.Pointer[accessRules]
+		tenantsDir        string
+		tenants           []tenants.Tenant
+		tenantsChanged    chan struct{}
+		serverTiming      atomic.Bool
+		searchLimits      atomic.Pointer[searchlimit.Config]
+		epochs            *epochWatcher
+		streams           *instrument.Streams
+		zipBuffers        *instrument.BufferPool
+		chaos             *chaos.Injector
+		extCache          extcache.Store
+		// opCtx is a context of NeoFS operations of requests and jobs, it's
+		// canceled on shutdown when the drain timeout is over.
+		opCtx     context.Context
+		cancelOps context.CancelFunc
 	}
 
 	appSettings struct {
@@ -69,14 +136,22 @@ type (
 	Option func(a *app)
 
 	gateMetrics struct {
-		logger   *zap.Logger
-		provider GateMetricsProvider
-		mu       sync.RWMutex
-		enabled  bool
+		logger     *zap.Logger
+		provider   GateMetricsProvider
+		mu         sync.RWMutex
+		enabled    bool
+		containers map[string]struct{}
 	}
 
 	GateMetricsProvider interface {
 		SetHealth(int32)
+		ObserveRequest(route, container string, status int, dur time.Duration)
+		IncAccessDenied(route, container string)
+		AddDownloadedBytes(n int)
+		IncDownloadStalls(reason string)
+		IncUploadAborts()
+		AddReclaimed(task string, items int, bytes int64)
+		IncMaintenanceFailures(task string)
 		Unregister()
 	}
 )
@@ -130,8 +205,9 @@ func newApp(ctx context.Context, opt ...Option) App {
 	a.webServer.MaxRequestBodySize = a.cfg.GetInt(cfgWebMaxRequestBodySize)
 	a.webServer.DisablePreParseMultipartForm = true
 	a.webServer.StreamRequestBody = a.cfg.GetBool(cfgWebStreamRequestBody)
+	a.setConnectionLimits()
 	// -- -- -- -- -- -- -- -- -- -- -- -- -- --
-	key, err = getNeoFSKey(a)
+	key, err = getNeoFSKey(a, "")
 	if err != nil {
 		a.log.Fatal("failed to get neofs credentials", zap.Error(err))
 	}
@@ -141,6 +217,81 @@ func newApp(ctx context.Context, opt ...Option) App {
 	owner := signer.UserID()
 	a.owner = &owner
 
+	a.poolStat = stat.NewPoolStatistic()
+
+	p, addresses := a.newPool(a.log, signer, cfgPeers)
+	a.pool = backend.NewPool(p)
+	a.dialPool(ctx, a.log, p, &a.poolConnected, func() {
+		a.updateMaxObjectSize(ctx)
+		a.setHealthStatus()
+	})
+	a.versions = a.detectVersions(ctx, a.log, addresses)
+	a.epochs = newEpochWatcher(a.log, a.pool, &a.poolConnected)
+
+	a.opCtx, a.cancelOps = context.WithCancel(context.Background())
+	a.jobs = jobs.NewManager(a.opCtx, a.log)
+	a.streams = instrument.NewStreams()
+	a.streams.SetEnabled(a.cfg.GetBool(cfgInstrumentationEnabled))
+	a.zipBuffers = instrument.NewBufferPool("zip", downloader.ZipBufferSize)
+	a.chaos = chaos.NewInjector(a.log)
+	a.chaos.SetEnabled(a.cfg.GetBool(cfgChaosEnabled))
+	if a.chaos.Enabled() {
+		a.log.Warn("fault injection is enabled, it must not be used in production")
+	}
+
+	if a.cfg.GetBool(cfgAccountingEnabled) {
+		a.usage = usage.NewTracker(a.cfg.GetInt(cfgAccountingMaxIssuers))
+	}
+
+	a.initPurgers()
+	a.initTransforms()
+	a.initExternalCache()
+	a.initTenants()
+	a.initAppSettings(ctx)
+	a.initClientIP()
+	a.initResolver(ctx)
+	a.initNetworks(ctx)
+	if err := a.updateAllowedHosts(); err != nil {
+		a.log.Fatal("failed to parse allowed hosts", zap.Error(err))
+	}
+	if err := a.updateAccessRules(); err != nil {
+		a.log.Fatal("failed to parse access rules", zap.Error(err))
+	}
+	if err := a.updateBearerDefaults(); err != nil {
+		a.log.Fatal("failed to load default bearer token", zap.Error(err))
+	}
+	if err := a.updateImportHosts(); err != nil {
+		a.log.Fatal("failed to parse import allowed hosts", zap.Error(err))
+	}
+	a.initMetrics()
+	a.initGC()
+
+	return a
+}
+
+// newPool creates connection pool to the nodes listed under the given config
+// key, node addresses are returned along with the pool.
+func (a *app) newPool(log *zap.Logger, signer user.Signer, peersKey string) (*pool.Pool, []string) {
+	prm := a.poolParameters(signer)
+
+	var addresses []string
+	for _, node := range a.peers(peersKey) {
+		prm.AddNode(node.nodeParam())
+		addresses = append(addresses, node.address)
+		log.Info("add connection", zap.String("address", node.address),
+			zap.Float64("weight", node.weight), zap.Int("priority", node.priority))
+	}
+
+	p, err := pool.NewPool(prm)
+	if err != nil {
+		log.Fatal("failed to create connection pool", zap.Error(err))
+	}
+
+	return p, addresses
+}
+
+// poolParameters returns connection pool parameters without nodes.
+func (a *app) poolParameters(signer user.Signer) pool.InitParameters {
 	var prm pool.InitParameters
 	prm.SetSigner(signer)
 	prm.SetNodeDialTimeout(a.cfg.GetDuration(cfgConTimeout))
@@ -148,11 +299,33 @@ func newApp(ctx context.Context, opt ...Option) App {
 	prm.SetHealthcheckTimeout(a.cfg.GetDuration(cfgReqTimeout))
 	prm.SetClientRebalanceInterval(a.cfg.GetDuration(cfgRebalance))
 	prm.SetErrorThreshold(a.cfg.GetUint32(cfgPoolErrorThreshold))
+	// sessions are refreshed by the pool one epoch before expiration, node
+	// epochs are tracked by health checks every rebalance interval
+	prm.SetSessionExpirationDuration(a.cfg.GetUint64(cfgPoolSessionLifetime))
+
+	prm.SetStatisticCallback(a.poolStat.OperationCallback)
 
+	return prm
+}
+
+// peer is a storage node the connection pool is connected to.
+type peer struct {
+	address  string
+	weight   float64
+	priority int
+}
+
+func (p peer) nodeParam() pool.NodeParam {
+	return pool.NewNodeParam(p.priority, p.address, p.weight)
+}
+
+// peers returns the nodes listed under the given config key.
+func (a *app) peers(peersKey string) []peer {
+	var res []peer
 	for i := 0; ; i++ {
-		address := a.cfg.GetString(cfgPeers + "." + strconv.Itoa(i) + ".address")
-		weight := a.cfg.GetFloat64(cfgPeers + "." + strconv.Itoa(i) + ".weight")
-		priority := a.cfg.GetInt(cfgPeers + "." + strconv.Itoa(i) + ".priority")
+		address := a.cfg.GetString(peersKey + "." + strconv.Itoa(i) + ".address")
+		weight := a.cfg.GetFloat64(peersKey + "." + strconv.Itoa(i) + ".weight")
+		priority := a.cfg.GetInt(peersKey + "." + strconv.Itoa(i) + ".priority")
 		if address == "" {
 			break
 		}
@@ -162,46 +335,712 @@ func newApp(ctx context.Context, opt ...Option) App {
 		if priority <= 0 { // unspecified or wrong
 			priority = 1
 		}
-		prm.AddNode(pool.NewNodeParam(priority, address, weight))
-		a.log.Info("add connection", zap.String("address", address),
-			zap.Float64("weight", weight), zap.Int("priority", priority))
+		res = append(res, peer{address: address, weight: weight, priority: priority})
 	}
+	return res
+}
 
-	a.poolStat = stat.NewPoolStatistic()
-	prm.SetStatisticCallback(a.poolStat.OperationCallback)
+func (a *app) initAppSettings(ctx context.Context) {
+	a.settings = &appSettings{
+		Uploader:   &uploader.Settings{},
+		Downloader: &downloader.Settings{},
+	}
+
+	if a.cfg.GetBool(cfgZipCacheEnabled) {
+		cache, err := downloader.NewZipCache(downloader.ZipCacheConfig{
+			Dir:          a.cfg.GetString(cfgZipCacheDir),
+			TTL:          a.cfg.GetDuration(cfgZipCacheTTL),
+			MaxSize:      a.cfg.GetInt64(cfgZipCacheMaxSize),
+			MaxEntrySize: a.cfg.GetInt64(cfgZipCacheMaxEntrySize),
+		})
+		if err != nil {
+			a.log.Fatal("failed to create zip cache", zap.Error(err))
+		}
+		a.settings.Downloader.SetZipCache(cache)
+	}
+
+	a.updateSettings(ctx)
+}
+
+func (a *app) expirationFloor() bool {
+	switch rounding := a.cfg.GetString(cfgUploaderHeaderExpirationRounding); rounding {
+	case expirationRoundingCeil:
+		return false
+	case expirationRoundingFloor:
+		return true
+	default:
+		a.log.Warn("unknown expiration rounding, ceil is used", zap.String("rounding", rounding))
+		return false
+	}
+}
+
+func (a *app) ignoreClockSkew() bool {
+	switch action := a.cfg.GetString(cfgUploaderHeaderExpirationSkewAction); action {
+	case clockSkewActionReject:
+		return false
+	case clockSkewActionIgnore:
+		return true
+	default:
+		a.log.Warn("unknown clock skew action, reject is used", zap.String("action", action))
+		return false
+	}
+}
+
+// captchaVerifier returns the verifier of challenge tokens of anonymous
+// uploads, nil if verification is disabled.
+func (a *app) captchaVerifier(enabled bool) *uploader.CaptchaVerifier {
+	if !enabled {
+		return nil
+	}
+	if a.cfg.GetString(cfgUploadCaptchaSecret) == "" {
+		a.log.Warn("captcha secret isn't set, requests requiring captcha will be rejected")
+	}
+	return uploader.NewCaptchaVerifier(a.cfg.GetString(cfgUploadCaptchaVerifyURL),
+		a.cfg.GetString(cfgUploadCaptchaSecret), a.cfg.GetDuration(cfgUploadCaptchaTimeout))
+}
+
+// abuseReports returns the abuse reports intake, nil if reports are disabled
+// or no container is configured for them.
+func (a *app) abuseReports() *uploader.AbuseReports {
+	if !a.cfg.GetBool(cfgAbuseReportEnabled) {
+		return nil
+	}
+	container := a.cfg.GetString(cfgAbuseReportContainer)
+	if container == "" {
+		a.log.Warn("abuse reports container isn't set, reports are disabled")
+		return nil
+	}
+	return uploader.NewAbuseReports(uploader.AbuseReportConfig{
+		Container:      container,
+		WebhookURL:     a.cfg.GetString(cfgAbuseReportWebhookURL),
+		WebhookTimeout: a.cfg.GetDuration(cfgAbuseReportWebhookTimeout),
+		RateLimit:      a.cfg.GetInt(cfgAbuseReportRateLimit),
+		RateWindow:     a.cfg.GetDuration(cfgAbuseReportRateWindow),
+		Captcha:        a.captchaVerifier(a.cfg.GetBool(cfgAbuseReportCaptcha)),
+	})
+}
 
-	a.pool, err = pool.NewPool(prm)
+// tailConfig returns parameters of log objects tailing, nil if it's disabled.
+func (a *app) tailConfig() *downloader.TailConfig {
+	if !a.cfg.GetBool(cfgTailEnabled) {
+		return nil
+	}
+	cfg := &downloader.TailConfig{
+		DefaultBytes: a.cfg.GetUint64(cfgTailDefaultBytes),
+		MaxBytes:     a.cfg.GetUint64(cfgTailMaxBytes),
+		MaxObjects:   a.cfg.GetInt(cfgTailMaxObjects),
+		PollInterval: a.cfg.GetDuration(cfgTailPollInterval),
+		MaxFollow:    a.cfg.GetDuration(cfgTailMaxFollow),
+	}
+	if cfg.PollInterval <= 0 {
+		a.log.Warn("invalid tail poll interval, default is used", zap.Duration("interval", cfg.PollInterval))
+		cfg.PollInterval = 2 * time.Second
+	}
+	if writeTimeout := a.cfg.GetDuration(cfgWebWriteTimeout); writeTimeout > 0 && (cfg.MaxFollow == 0 || cfg.MaxFollow >= writeTimeout) {
+		a.log.Warn("following objects is limited by web write timeout",
+			zap.Duration("max_follow", cfg.MaxFollow), zap.Duration("write_timeout", writeTimeout))
+	}
+	return cfg
+}
+
+// searchLimitsConfig returns default search limits and limits of routes, route
+// limits without routes are ignored.
+func (a *app) searchLimitsConfig() *searchlimit.Config {
+	cfg := &searchlimit.Config{
+		Default: searchlimit.Limits{
+			Timeout:    a.cfg.GetDuration(cfgSearchTimeout),
+			MaxResults: a.cfg.GetInt(cfgSearchMaxResults),
+		},
+		Routes: make(map[string]searchlimit.Limits),
+	}
+	for i := 0; ; i++ {
+		key := cfgSearchRoutes + "." + strconv.Itoa(i) + "."
+		if !a.cfg.IsSet(key + "routes") {
+			break
+		}
+		limits := searchlimit.Limits{
+			Timeout:    a.cfg.GetDuration(key + "timeout"),
+			MaxResults: a.cfg.GetInt(key + "max_results"),
+		}
+		for _, route := range a.cfg.GetStringSlice(key + "routes") {
+			cfg.Routes[route] = limits
+		}
+	}
+	return cfg
+}
+
+// setConnectionLimits configures limits of web server connections. Invalid
+// values are replaced with defaults.
+func (a *app) setConnectionLimits() {
+	nonNegative := func(key string, def int) int {
+		val := a.cfg.GetInt(key)
+		if val < 0 {
+			a.log.Warn("invalid web server limit, default is used", zap.String("key", key), zap.Int("value", val))
+			return def
+		}
+		return val
+	}
+
+	// zero concurrency means the default one for fasthttp
+	a.webServer.Concurrency = nonNegative(cfgWebConcurrency, fasthttp.DefaultConcurrency)
+	a.webServer.MaxConnsPerIP = nonNegative(cfgWebMaxConnsPerIP, 0)
+	a.webServer.MaxRequestsPerConn = nonNegative(cfgWebMaxRequestsPerConn, 0)
+
+	a.webServer.IdleTimeout = a.cfg.GetDuration(cfgWebIdleTimeout)
+	if a.webServer.IdleTimeout < 0 {
+		a.log.Warn("invalid web server idle timeout, read timeout is used", zap.Duration("idle_timeout", a.webServer.IdleTimeout))
+		a.webServer.IdleTimeout = 0
+	}
+}
+
+// tcpKeepalivePeriod returns the keep-alive period of accepted connections,
+// negative period disables keep-alive probes.
+func (a *app) tcpKeepalivePeriod() time.Duration {
+	if !a.cfg.GetBool(cfgWebTCPKeepalive) {
+		return -1
+	}
+	period := a.cfg.GetDuration(cfgWebTCPKeepalivePeriod)
+	if period <= 0 {
+		a.log.Warn("invalid TCP keep-alive period, default is used", zap.Duration("period", period))
+		period = defaultTCPKeepalivePeriod
+	}
+	return period
+}
+
+// externalURL returns the external base URL of the gate, empty if it isn't
+// set or is invalid.
+func (a *app) externalURL() string {
+	externalURL := a.cfg.GetString(cfgExternalURL)
+	if externalURL == "" {
+		return ""
+	}
+	if u, err := url.Parse(externalURL); err != nil || u.Scheme == "" || u.Host == "" {
+		a.log.Warn("invalid external URL, it's ignored", zap.String("external_url", externalURL))
+		return ""
+	}
+	return externalURL
+}
+
+// canonicalLinks returns canonical links builder, nil if they're disabled or
+// the external base URL isn't set or is invalid. The canonical link base URL
+// defaults to the external URL of the gate.
+func (a *app) canonicalLinks(externalURL string) *downloader.CanonicalLinks {
+	if !a.cfg.GetBool(cfgCanonicalLinkEnabled) {
+		return nil
+	}
+	baseURL := a.cfg.GetString(cfgCanonicalLinkBaseURL)
+	if baseURL == "" {
+		baseURL = externalURL
+	}
+	if baseURL == "" {
+		a.log.Warn("canonical links are enabled, but neither base URL nor external URL is set")
+		return nil
+	}
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		a.log.Warn("invalid canonical link base URL, canonical links are disabled", zap.String("base_url", baseURL))
+		return nil
+	}
+	return downloader.NewCanonicalLinks(baseURL, a.cfg.GetBool(cfgCanonicalLinkUseFilePath),
+		a.aliases())
+}
+
+// zipCachePeers returns gateways sharing the archive cache, nil if sharding
+// isn't configured or is misconfigured.
+func (a *app) zipCachePeers() *downloader.ZipCachePeers {
+	peers := a.cfg.GetStringSlice(cfgZipCachePeers)
+	if len(peers) == 0 {
+		return nil
+	}
+
+	self := a.cfg.GetString(cfgZipCacheSelf)
+	ring := hashring.New(peers, hashring.DefaultReplicas)
+	if !ring.Has(self) {
+		a.log.Warn("gateway isn't in archive cache peers, cache isn't shared",
+			zap.String("self", self), zap.Strings("peers", peers))
+		return nil
+	}
+	return &downloader.ZipCachePeers{
+		Ring:           ring,
+		Self:           self,
+		ClientIPHeader: a.cfg.GetString(cfgWebClientIPHeader),
+		Timeout:        a.cfg.GetDuration(cfgZipCachePeerTimeout),
+	}
+}
+
+func (a *app) slicing() string {
+	switch mode := a.cfg.GetString(cfgUploadSlicingMode); mode {
+	case uploader.SlicingNode, uploader.SlicingGate:
+		return mode
+	default:
+		a.log.Warn("unknown upload slicing mode, node is used", zap.String("mode", mode))
+		return uploader.SlicingNode
+	}
+}
+
+func (a *app) pathConflict() string {
+	switch policy := a.cfg.GetString(cfgUploadPathConflict); policy {
+	case uploader.PathConflictVersion, uploader.PathConflictReject, uploader.PathConflictReplace:
+		return policy
+	default:
+		a.log.Warn("unknown upload path conflict policy, version is used", zap.String("policy", policy))
+		return uploader.PathConflictVersion
+	}
+}
+
+// updateBearerDefaults reads the default bearer token file, the token is
+// attached to requests without tokens of the configured clients to the
+// configured containers.
+func (a *app) updateBearerDefaults() error {
+	path := a.cfg.GetString(cfgDefaultBearerPath)
+	if path == "" {
+		a.bearerDefaults.Store(nil)
+		return nil
+	}
+	containers := a.cfg.GetStringSlice(cfgDefaultBearerContainers)
+	if len(containers) == 0 {
+		return errors.New("no containers to use the token for")
+	}
+	clients, err := clientip.ParsePrefixes(a.cfg.GetStringSlice(cfgDefaultBearerClients))
 	if err != nil {
-		a.log.Fatal("failed to create connection pool", zap.Error(err))
+		return fmt.Errorf("invalid clients: %w", err)
 	}
+	if len(clients) == 0 {
+		return errors.New("no clients to use the token for")
+	}
+	d, err := tokens.ReadDefaults(path, containers, clients)
+	if err != nil {
+		return err
+	}
+	a.bearerDefaults.Store(d)
+	return nil
+}
 
-	err = a.pool.Dial(ctx)
+// updateImportHosts reads the hosts import manifests can fetch files from,
+// all hosts are allowed if the list is empty.
+func (a *app) updateImportHosts() error {
+	patterns := a.cfg.GetStringSlice(cfgImportAllowedHosts)
+	if len(patterns) == 0 {
+		a.settings.Uploader.SetImportAllowedHosts(nil)
+		return nil
+	}
+	l, err := utils.NewHostAllowlist(patterns)
 	if err != nil {
-		a.log.Fatal("failed to dial pool", zap.Error(err))
+		return err
 	}
+	a.settings.Uploader.SetImportAllowedHosts(l)
+	return nil
+}
 
-	a.initAppSettings(ctx)
-	a.initResolver(ctx)
-	a.initMetrics()
+func (a *app) joinDuplicateAttributes() bool {
+	switch mode := a.cfg.GetString(cfgUploaderHeaderAttributeDuplicates); mode {
+	case attributeDuplicatesReject:
+		return false
+	case attributeDuplicatesJoin:
+		return true
+	default:
+		a.log.Warn("unknown attribute duplicates mode, reject is used", zap.String("mode", mode))
+		return false
+	}
+}
 
-	return a
+func (a *app) attributeCase() string {
+	switch mode := a.cfg.GetString(cfgUploaderHeaderAttributeCase); mode {
+	case utils.AttributeCasePreserve, utils.AttributeCaseKnown, utils.AttributeCaseCanonical:
+		return mode
+	default:
+		a.log.Warn("unknown attribute case mode, preserve is used", zap.String("mode", mode))
+		return utils.AttributeCasePreserve
+	}
 }
 
-func (a *app) initAppSettings(ctx context.Context) {
-	a.settings = &appSettings{
-		Uploader:   &uploader.Settings{},
-		Downloader: &downloader.Settings{},
+func (a *app) detectVersions(ctx context.Context, log *zap.Logger, addresses []string) *compat.Versions {
+	versions := compat.Negotiate(compat.Probe(ctx, addresses, a.cfg.GetDuration(cfgConTimeout)))
+
+	for _, n := range versions.Nodes() {
+		if n.Err != nil {
+			log.Warn("could not detect node API version", zap.String("address", n.Address), zap.Error(n.Err))
+			continue
+		}
+		log.Info("node API version", zap.String("address", n.Address), zap.Stringer("version", n.Version))
 	}
 
-	a.updateSettings(ctx)
+	if versions.Mixed() {
+		log.Warn("connected nodes use different API versions, the lowest one is used",
+			zap.String("version", versions.Negotiated()))
+	}
+
+	for f, ok := range versions.Features() {
+		if !ok {
+			log.Warn("feature is disabled", zap.Error(versions.Unsupported(f)))
+		}
+	}
+
+	return versions
+}
+
+func (a *app) initClientIP() {
+	extractor, err := clientip.New(a.trustedProxies(), a.cfg.GetString(cfgWebClientIPHeader))
+	if err != nil {
+		a.log.Fatal("failed to parse trusted proxies", zap.Error(err))
+	}
+
+	a.clientIP = extractor
+}
+
+// trustedProxies returns trusted proxies and addresses of archive cache peers,
+// they forward client addresses of archive requests to each other. Peer host
+// names are resolved on start and SIGHUP.
+func (a *app) trustedProxies() []string {
+	proxies := a.cfg.GetStringSlice(cfgWebTrustedProxies)
+	for _, peer := range a.cfg.GetStringSlice(cfgZipCachePeers) {
+		u, err := url.Parse(peer)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		if ip := net.ParseIP(u.Hostname()); ip != nil {
+			proxies = append(proxies, ip.String())
+			continue
+		}
+		ips, err := net.LookupIP(u.Hostname())
+		if err != nil {
+			a.log.Warn("could not resolve archive cache peer, it isn't trusted", zap.String("peer", peer), zap.Error(err))
+			continue
+		}
+		for _, ip := range ips {
+			proxies = append(proxies, ip.String())
+		}
+	}
+	return proxies
+}
+
+func (a *app) resolverConfig() resolver.Config {
+	return resolver.Config{
+		RPCEndpoint:      a.cfg.GetString(cfgRPCEndpoint),
+		Order:            a.cfg.GetStringSlice(cfgResolveOrder),
+		DNSServer:        a.cfg.GetString(cfgResolverDNSServer),
+		CacheTTL:         a.cfg.GetDuration(cfgResolverCacheTTL),
+		CacheSize:        a.cfg.GetInt(cfgResolverCacheSize),
+		NegativeCacheTTL: a.cfg.GetDuration(cfgResolverNegativeCacheTTL),
+		Aliases:          a.aliases(),
+	}
+}
+
+func (a *app) readQuota(key string) usage.Quota {
+	return usage.Quota{
+		Daily: usage.Limits{
+			Requests: a.cfg.GetUint64(key + ".daily_requests"),
+			Bytes:    a.cfg.GetUint64(key + ".daily_bytes"),
+		},
+		Monthly: usage.Limits{
+			Requests: a.cfg.GetUint64(key + ".monthly_requests"),
+			Bytes:    a.cfg.GetUint64(key + ".monthly_bytes"),
+		},
+	}
+}
+
+func (a *app) quotas() usage.Quotas {
+	res := usage.Quotas{
+		Default:   a.readQuota(cfgAccountingQuota),
+		Anonymous: a.readQuota(cfgAccountingAnonymousQuota),
+		Issuers:   make(map[string]usage.Quota),
+		APIKeys:   make(map[string]usage.APIKey),
+	}
+
+	for i := 0; ; i++ {
+		key := cfgAccountingQuotas + "." + strconv.Itoa(i)
+		issuer := a.cfg.GetString(key + ".issuer")
+		if issuer == "" {
+			break
+		}
+		res.Issuers[issuer] = a.readQuota(key)
+	}
+
+	for i := 0; ; i++ {
+		key := cfgAccountingAPIKeys + "." + strconv.Itoa(i)
+		apiKey := a.cfg.GetString(key + ".key")
+		if apiKey == "" {
+			break
+		}
+		name := a.cfg.GetString(key + ".name")
+		if name == "" {
+			a.log.Warn("API key without name is ignored", zap.Int("index", i))
+			continue
+		}
+		res.APIKeys[apiKey] = usage.APIKey{Name: name, Quota: a.readQuota(key)}
+	}
+
+	// quotas of the gate configuration take precedence over tenant ones
+	for _, t := range a.tenants {
+		for issuer, q := range t.Quotas {
+			if _, ok := res.Issuers[issuer]; !ok {
+				res.Issuers[issuer] = q
+			}
+		}
+	}
+
+	return res
+}
+
+func (a *app) initPurgers() {
+	a.purgers = purge.NewPurgers()
+	timeout := a.cfg.GetDuration(cfgPurgeTimeout)
+
+	if serviceID := a.cfg.GetString(cfgPurgeFastlyServiceID); serviceID != "" {
+		a.purgers.Add(purge.FastlyPurgerName, purge.NewFastly(serviceID, a.cfg.GetString(cfgPurgeFastlyToken), timeout))
+	}
+	if zoneID := a.cfg.GetString(cfgPurgeCloudflareZoneID); zoneID != "" {
+		a.purgers.Add(purge.CloudflarePurgerName, purge.NewCloudflare(zoneID, a.cfg.GetString(cfgPurgeCloudflareToken), timeout))
+	}
+
+	if a.purgers.Len() != 0 {
+		a.log.Info("CDN purge is configured", zap.Int("purgers", a.purgers.Len()))
+	}
+}
+
+// initTransforms registers transformation hooks running external commands
+// from the configuration.
+func (a *app) initTransforms() {
+	a.transforms = transform.NewHooks()
+
+	for i := 0; ; i++ {
+		key := cfgTransform + "." + strconv.Itoa(i)
+		name := a.cfg.GetString(key + ".name")
+		if name == "" {
+			break
+		}
+
+		path := a.cfg.GetString(key + ".exec")
+		if path == "" {
+			a.log.Fatal("transformation hook has no command", zap.String("transform", name))
+		}
+		concurrency := transform.DefaultConcurrency
+		if a.cfg.IsSet(key + ".concurrency") {
+			concurrency = a.cfg.GetInt(key + ".concurrency")
+		}
+		err := a.transforms.Register(transform.Hook{
+			Name:        name,
+			Types:       a.cfg.GetStringSlice(key + ".types"),
+			ContentType: a.cfg.GetString(key + ".content_type"),
+			Concurrency: concurrency,
+			Transformer: transform.Exec{
+				Path:    path,
+				Args:    a.cfg.GetStringSlice(key + ".args"),
+				Timeout: a.cfg.GetDuration(key + ".timeout"),
+			},
+		})
+		if err != nil {
+			a.log.Fatal("invalid transformation hook", zap.String("transform", name), zap.Error(err))
+		}
+	}
+
+	if names := a.transforms.Names(); len(names) != 0 {
+		a.log.Info("transformation hooks are configured", zap.Strings("transforms", names))
+	}
+}
+
+func (a *app) initExternalCache() {
+	typ := a.cfg.GetString(cfgExternalCacheType)
+	if typ == "" {
+		return
+	}
+
+	store, err := extcache.NewStore(typ, extcache.StoreConfig{
+		Addresses: a.cfg.GetStringSlice(cfgExternalCacheAddresses),
+		Password:  a.cfg.GetString(cfgExternalCachePassword),
+		Timeout:   a.cfg.GetDuration(cfgExternalCacheTimeout),
+		PoolSize:  a.cfg.GetInt(cfgExternalCachePoolSize),
+	})
+	if err != nil {
+		a.log.Fatal("failed to create external cache", zap.Error(err))
+	}
+	a.extCache = store
+	a.log.Info("external cache is configured", zap.String("type", typ),
+		zap.Strings("addresses", a.cfg.GetStringSlice(cfgExternalCacheAddresses)))
+}
+
+func (a *app) externalCacheConfig(namespace string) extcache.Config {
+	return extcache.Config{
+		Namespace:      namespace,
+		Format:         a.externalCacheFormat(),
+		HeadTTL:        a.cfg.GetDuration(cfgExternalCacheHeadTTL),
+		PayloadTTL:     a.cfg.GetDuration(cfgExternalCachePayloadTTL),
+		MaxPayloadSize: a.cfg.GetUint64(cfgExternalCacheMaxPayloadSize),
+	}
+}
+
+// externalCacheInvalidator returns the invalidator of objects cached for the
+// network, nil if the external cache isn't configured.
+func (a *app) externalCacheInvalidator(namespace string) *extcache.Invalidator {
+	if a.extCache == nil {
+		return nil
+	}
+	return extcache.NewInvalidator(a.extCache, a.externalCacheConfig(namespace))
+}
+
+func (a *app) externalCacheFormat() string {
+	switch format := a.cfg.GetString(cfgExternalCacheFormat); format {
+	case extcache.FormatProtobuf, extcache.FormatJSON:
+		return format
+	default:
+		a.log.Warn("unknown external cache format, protobuf is used", zap.String("format", format))
+		return extcache.FormatProtobuf
+	}
+}
+
+// backend returns the backend executing operations via the pool, objects are
+// read from their nodes directly if placement reads are enabled and cached if
+// the external cache is configured. Namespace separates cached objects of
+// different networks.
+func (a *app) backend(p *backend.Pool, namespace string) backend.Backend {
+	var b backend.Backend = p
+	if c := a.metadataCache(p, namespace); c != nil {
+		b = c
+	}
+	if a.cfg.GetBool(cfgPlacementReadsEnabled) {
+		b = a.placementBackend(b, p, namespace)
+	}
+	if c := a.resolutionCache(namespace); c != nil {
+		b = attrcache.NewBackend(b, c)
+	}
+	if f := a.pathFilter(namespace); f != nil {
+		b = pathfilter.NewBackend(b, f)
+	}
+	b = a.streamLimitBackend(b, namespace)
+	if a.extCache != nil {
+		b = extcache.NewBackend(b, a.extCache, a.externalCacheConfig(namespace), a.log)
+	}
+	if a.cfg.GetBool(cfgCoalescingEnabled) {
+		b = a.coalescingBackend(b, namespace)
+	}
+	return b
+}
+
+// coalescingBackend returns the backend merging concurrent reads of the same
+// object, it's created once per network and shared by its handlers, so reads
+// of all of them are merged.
+func (a *app) coalescingBackend(next backend.Backend, namespace string) *coalesce.Backend {
+	if b, ok := a.coalescers[namespace]; ok {
+		return b
+	}
+
+	b := coalesce.NewBackend(a.opCtx, next, a.cfg.GetUint64(cfgCoalescingMaxPayloadSize),
+		a.cfg.GetUint64(cfgCoalescingMaxTotalSize))
+	if a.coalescers == nil {
+		a.coalescers = make(map[string]*coalesce.Backend)
+	}
+	a.coalescers[namespace] = b
+	return b
+}
+
+// streamLimitBackend returns the backend limiting simultaneous streams of the
+// same object and container, it's created once per network and shared by its
+// handlers, so streams of all of them are counted. It sits below the caches,
+// so only streams actually reaching storage nodes are limited.
+func (a *app) streamLimitBackend(next backend.Backend, namespace string) *streamlimit.Backend {
+	if b, ok := a.streamLimits[namespace]; ok {
+		return b
+	}
+
+	b := streamlimit.NewBackend(next, a.streamLimitConfig())
+	if a.streamLimits == nil {
+		a.streamLimits = make(map[string]*streamlimit.Backend)
+	}
+	a.streamLimits[namespace] = b
+	return b
+}
+
+func (a *app) streamLimitConfig() streamlimit.Config {
+	overflow := a.cfg.GetString(cfgStreamLimitsOverflow)
+	switch overflow {
+	case streamlimit.OverflowReject, streamlimit.OverflowQueue:
+	default:
+		a.log.Warn("unknown stream limits overflow mode, reject is used", zap.String("mode", overflow))
+		overflow = streamlimit.OverflowReject
+	}
+	return streamlimit.Config{
+		PerObject:    a.cfg.GetInt(cfgStreamLimitsPerObject),
+		PerContainer: a.cfg.GetInt(cfgStreamLimitsPerContainer),
+		Overflow:     overflow,
+		QueueTimeout: a.cfg.GetDuration(cfgStreamLimitsQueueTimeout),
+	}
+}
+
+// metadataCache returns the backend caching container and network info of
+// the pool, it's created once per network and shared by its handlers. Nil is
+// returned if caching is disabled.
+func (a *app) metadataCache(p *backend.Pool, namespace string) *metacache.Backend {
+	cfg := metacache.Config{
+		ContainerTTL:   a.cfg.GetDuration(cfgMetadataCacheContainerTTL),
+		NetworkInfoTTL: a.cfg.GetDuration(cfgMetadataCacheNetworkInfoTTL),
+		Size:           a.cfg.GetInt(cfgMetadataCacheSize),
+	}
+	if cfg.ContainerTTL <= 0 && cfg.NetworkInfoTTL <= 0 {
+		return nil
+	}
+	if c, ok := a.metaCaches[namespace]; ok {
+		return c
+	}
+
+	c := metacache.NewBackend(a.opCtx, p, cfg)
+	if a.metaCaches == nil {
+		a.metaCaches = make(map[string]*metacache.Backend)
+	}
+	a.metaCaches[namespace] = c
+	return c
+}
+
+// resolutionCache returns the cache of objects found by attribute, it's
+// created once per network and shared by its handlers, so writes invalidate
+// results read by downloads. Nil is returned if caching is disabled.
+func (a *app) resolutionCache(namespace string) *attrcache.Cache {
+	ttl := a.cfg.GetDuration(cfgAttributeCacheTTL)
+	if ttl <= 0 {
+		return nil
+	}
+	if c, ok := a.resolutions[namespace]; ok {
+		return c
+	}
+
+	c := attrcache.New(ttl, a.cfg.GetDuration(cfgAttributeCacheNegativeTTL), a.cfg.GetInt(cfgAttributeCacheSize))
+	if a.resolutions == nil {
+		a.resolutions = make(map[string]*attrcache.Cache)
+	}
+	a.resolutions[namespace] = c
+	return c
+}
+
+// pathFilter returns filters of container paths, they're created once per
+// network and shared by its handlers, so written paths are added to filters
+// used by downloads. Nil is returned if filters are disabled.
+func (a *app) pathFilter(namespace string) *pathfilter.Filters {
+	if !a.cfg.GetBool(cfgPathFilterEnabled) {
+		return nil
+	}
+	if f, ok := a.pathFilters[namespace]; ok {
+		return f
+	}
+
+	f := pathfilter.New(a.opCtx, pathfilter.Config{
+		RefreshInterval:   a.cfg.GetDuration(cfgPathFilterRefreshInterval),
+		MaxObjects:        a.cfg.GetInt(cfgPathFilterMaxObjects),
+		MaxContainers:     a.cfg.GetInt(cfgPathFilterMaxContainers),
+		FalsePositiveRate: a.cfg.GetFloat64(cfgPathFilterFalsePositiveRate),
+		Concurrency:       a.cfg.GetInt(cfgPathFilterConcurrency),
+	}, a.log)
+	if a.pathFilters == nil {
+		a.pathFilters = make(map[string]*pathfilter.Filters)
+	}
+	a.pathFilters[namespace] = f
+	return f
 }
 
 func (a *app) initResolver(ctx context.Context) {
-	endpoint := a.cfg.GetString(cfgRPCEndpoint)
+	cfg := a.resolverConfig()
 
-	a.log.Info("rpc endpoint", zap.String("address", endpoint))
+	a.log.Info("rpc endpoint", zap.String("address", cfg.RPCEndpoint))
+	a.log.Info("container resolvers", zap.Strings("order", cfg.Order))
 
-	res, err := resolver.NewContainer(ctx, endpoint)
+	res, err := resolver.NewContainer(ctx, cfg)
 	if err != nil {
 		a.log.Fatal("failed to create resolver", zap.Error(err))
 	}
@@ -210,9 +1049,10 @@ func (a *app) initResolver(ctx context.Context) {
 }
 
 func (a *app) initMetrics() {
-	gateMetricsProvider := metrics.NewGateMetrics(a.pool, a.poolStat)
+	gateMetricsProvider := metrics.NewGateMetrics(a.poolStat, a.usage, a.streams, a.zipBuffers)
 	gateMetricsProvider.SetGWVersion(Version)
-	a.metrics = newGateMetrics(a.log, gateMetricsProvider, a.cfg.GetBool(cfgPrometheusEnabled))
+	a.metrics = newGateMetrics(a.log, gateMetricsProvider, a.metricsEnabled())
+	a.metrics.SetContainerLabels(a.cfg.GetStringSlice(cfgPrometheusContainerLabels))
 }
 
 func newGateMetrics(logger *zap.Logger, provider GateMetricsProvider, enabled bool) *gateMetrics {
@@ -246,6 +1086,103 @@ func (m *gateMetrics) SetHealth(status int32) {
 	m.provider.SetHealth(status)
 }
 
+// SetContainerLabels sets the list of containers having their own label value
+// in request metrics. Requests to other containers are accounted with
+// otherContainerLabel to keep the metric cardinality bounded.
+func (m *gateMetrics) SetContainerLabels(containers []string) {
+	allowed := make(map[string]struct{}, len(containers))
+	for _, cnr := range containers {
+		allowed[cnr] = struct{}{}
+	}
+
+	m.mu.Lock()
+	m.containers = allowed
+	m.mu.Unlock()
+}
+
+func (m *gateMetrics) ObserveRequest(route, container string, status int, dur time.Duration) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	if _, ok := m.containers[container]; !ok && container != "" {
+		container = otherContainerLabel
+	}
+	m.mu.RUnlock()
+
+	m.provider.ObserveRequest(route, container, status, dur)
+}
+
+func (m *gateMetrics) IncAccessDenied(route, container string) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	if _, ok := m.containers[container]; !ok && container != "" {
+		container = otherContainerLabel
+	}
+	m.mu.RUnlock()
+
+	m.provider.IncAccessDenied(route, container)
+}
+
+func (m *gateMetrics) AddDownloadedBytes(n int) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.AddDownloadedBytes(n)
+}
+
+func (m *gateMetrics) IncDownloadStalls(reason string) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.IncDownloadStalls(reason)
+}
+
+func (m *gateMetrics) IncUploadAborts() {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.IncUploadAborts()
+}
+
+func (m *gateMetrics) AddReclaimed(task string, items int, bytes int64) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.AddReclaimed(task, items, bytes)
+}
+
+func (m *gateMetrics) IncMaintenanceFailures(task string) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.IncMaintenanceFailures(task)
+}
+
 func (m *gateMetrics) Shutdown() {
 	m.mu.Lock()
 	if m.enabled {
@@ -256,8 +1193,10 @@ func (m *gateMetrics) Shutdown() {
 	m.mu.Unlock()
 }
 
-func getNeoFSKey(a *app) (*ecdsa.PrivateKey, error) {
-	walletPath := a.cfg.GetString(cfgWalletPath)
+// getNeoFSKey reads the key from the wallet configured under the given config
+// key prefix, ephemeral key is generated if the wallet isn't set.
+func getNeoFSKey(a *app, prefix string) (*ecdsa.PrivateKey, error) {
+	walletPath := a.cfg.GetString(prefix + cfgWalletPath)
 
 	if len(walletPath) == 0 {
 		a.log.Info("no wallet path specified, creating ephemeral key automatically for this run")
@@ -273,12 +1212,12 @@ func getNeoFSKey(a *app) (*ecdsa.PrivateKey, error) {
 	}
 
 	var password *string
-	if a.cfg.IsSet(cfgWalletPassphrase) {
-		pwd := a.cfg.GetString(cfgWalletPassphrase)
+	if a.cfg.IsSet(prefix + cfgWalletPassphrase) {
+		pwd := a.cfg.GetString(prefix + cfgWalletPassphrase)
 		password = &pwd
 	}
 
-	address := a.cfg.GetString(cfgWalletAddress)
+	address := a.cfg.GetString(prefix + cfgWalletAddress)
 
 	return getKeyFromWallet(w, address, password)
 }
@@ -324,19 +1263,35 @@ func (a *app) Wait() {
 	<-a.webDone // wait for web-server to be stopped
 }
 
+// setHealthStatus sets health metric to 1 if the gate is connected to NeoFS
+// and 0 while it's waiting for the connection.
 func (a *app) setHealthStatus() {
+	if !a.poolConnected.Load() {
+		a.metrics.SetHealth(0)
+		return
+	}
 	a.metrics.SetHealth(1)
 }
 
 func (a *app) Serve(ctx context.Context) {
-	uploadRoutes := uploader.New(ctx, a.AppParams(), a.settings.Uploader, a.signer)
-	downloadRoutes := downloader.New(ctx, a.AppParams(), a.settings.Downloader, a.signer)
+	uploadRoutes := uploader.New(a.opCtx, a.AppParams(), a.settings.Uploader, a.signer)
+	downloadRoutes := downloader.New(a.opCtx, a.AppParams(), a.settings.Downloader, a.signer)
+	for _, n := range a.networks {
+		n.initHandlers(a.opCtx, a.AppParams(), a.settings, a.backend(backend.NewPool(n.pool), n.name),
+			a.resolutionCache(n.name), a.pathFilter(n.name), a.externalCacheInvalidator(n.name))
+	}
 
 	// Configure router.
 	a.configureRouter(uploadRoutes, downloadRoutes)
+	a.accounting = a.accountingHandler(uploadRoutes)
 
 	a.startServices()
+	a.startEpochWatchers(ctx, uploadRoutes)
+	a.startGC(ctx, uploadRoutes)
+	a.startPeersDiscovery(ctx)
+	a.watchTenants(ctx)
 	a.initServers(ctx)
+	a.startPrefetch(ctx)
 
 	for i := range a.servers {
 		go func(i int) {
@@ -357,15 +1312,79 @@ LOOP:
 			break LOOP
 		case <-sigs:
 			a.configReload(ctx)
+		case <-a.tenantsChanged:
+			a.reloadTenants(ctx)
 		}
 	}
 
-	a.log.Info("shutting down web server", zap.Error(a.webServer.Shutdown()))
+	a.shutdown()
+
+	close(a.webDone)
+}
+
+// shutdown stops the gateway. Servers stop accepting requests, in-flight
+// requests and jobs are given shutdown timeout to complete, then their NeoFS
+// operations are canceled. NeoFS connections are closed last.
+func (a *app) shutdown() {
+	a.log.Info("shutting down web server")
+
+	webStopped := make(chan error, 1)
+	go func() {
+		webStopped <- a.webServer.Shutdown()
+	}()
+	jobsStopped := make(chan struct{})
+	go func() {
+		a.jobs.Wait()
+		close(jobsStopped)
+	}()
+
+	drain := time.NewTimer(a.cfg.GetDuration(cfgShutdownTimeout))
+	defer drain.Stop()
+
+	var canceled bool
+LOOP:
+	for webStopped != nil || jobsStopped != nil {
+		select {
+		case err := <-webStopped:
+			a.log.Info("web server stopped", zap.Error(err))
+			webStopped = nil
+		case <-jobsStopped:
+			jobsStopped = nil
+		case <-drain.C:
+			if canceled {
+				a.log.Error("in-flight operations are not stopped after cancellation",
+					zap.Bool("requests", webStopped != nil), zap.Bool("jobs", jobsStopped != nil))
+				break LOOP
+			}
+			a.log.Warn("shutdown timeout is over, canceling in-flight operations",
+				zap.Bool("requests", webStopped != nil), zap.Bool("jobs", jobsStopped != nil))
+			a.cancelOps()
+			canceled = true
+			// canceled operations fail fast, but they're not waited for forever
+			drain.Reset(defaultShutdownTimeout)
+		}
+	}
+	a.cancelOps()
 
 	a.metrics.Shutdown()
 	a.stopServices()
 
-	close(a.webDone)
+	if a.poolConnected.Load() {
+		a.pool.Close()
+	}
+	for _, n := range a.networks {
+		if n.connected.Load() {
+			n.pool.Close()
+		}
+	}
+	for _, p := range a.placements {
+		p.Close()
+	}
+	if a.extCache != nil {
+		_ = a.extCache.Close()
+	}
+	a.log.Info("NeoFS connections are closed")
+	_ = a.log.Sync()
 }
 
 func (a *app) configReload(ctx context.Context) {
@@ -378,41 +1397,141 @@ func (a *app) configReload(ctx context.Context) {
 		a.log.Warn("failed to reload config", zap.Error(err))
 		return
 	}
+	if err := a.loadTenants(); err != nil {
+		a.log.Warn("failed to reload tenants", zap.String("dir", a.tenantsDir), zap.Error(err))
+	}
 	if lvl, err := getLogLevel(a.cfg); err != nil {
 		a.log.Warn("log level won't be updated", zap.Error(err))
 	} else {
 		a.logLevel.SetLevel(lvl)
 	}
 
-	if err := a.resolverContainer.UpdateResolvers(ctx, a.cfg.GetString(cfgRPCEndpoint)); err != nil {
+	if err := a.resolverContainer.UpdateResolvers(ctx, a.resolverConfig()); err != nil {
 		a.log.Warn("failed to update resolvers", zap.Error(err))
 	}
+	a.updateNetworkResolvers(ctx)
+
+	if err := a.clientIP.Update(a.trustedProxies(), a.cfg.GetString(cfgWebClientIPHeader)); err != nil {
+		a.log.Warn("failed to update trusted proxies", zap.Error(err))
+	}
+	if err := a.updateAllowedHosts(); err != nil {
+		a.log.Warn("failed to update allowed hosts", zap.Error(err))
+	}
+	if err := a.updateAccessRules(); err != nil {
+		a.log.Warn("failed to update access rules", zap.Error(err))
+	}
+	if err := a.updateBearerDefaults(); err != nil {
+		a.log.Warn("failed to reload default bearer token, the previous one is used", zap.Error(err))
+	}
+	if err := a.updateImportHosts(); err != nil {
+		a.log.Warn("failed to update import allowed hosts", zap.Error(err))
+	}
 
 	if err := a.updateServers(); err != nil {
 		a.log.Warn("failed to reload server parameters", zap.Error(err))
 	}
 
+	a.streams.SetEnabled(a.cfg.GetBool(cfgInstrumentationEnabled))
+	a.chaos.SetEnabled(a.cfg.GetBool(cfgChaosEnabled))
 	a.stopServices()
 	a.startServices()
 
 	a.updateSettings(ctx)
 
-	a.metrics.SetEnabled(a.cfg.GetBool(cfgPrometheusEnabled))
+	a.metrics.SetEnabled(a.metricsEnabled())
+	a.metrics.SetContainerLabels(a.cfg.GetStringSlice(cfgPrometheusContainerLabels))
+	a.gc.SetInterval(a.cfg.GetDuration(cfgGCInterval))
 	a.setHealthStatus()
 
 	a.log.Info("SIGHUP config reload completed")
 }
 
 func (a *app) updateSettings(ctx context.Context) {
+	a.serverTiming.Store(a.cfg.GetBool(cfgWebServerTiming))
+	a.searchLimits.Store(a.searchLimitsConfig())
 	a.settings.Uploader.SetDefaultTimestamp(a.cfg.GetBool(cfgUploaderHeaderEnableDefaultTimestamp))
+	a.settings.Uploader.SetExpirationFloor(a.expirationFloor())
+	a.settings.Uploader.SetMaxClockSkew(a.cfg.GetDuration(cfgUploaderHeaderExpirationMaxClockSkew))
+	a.settings.Uploader.SetIgnoreClockSkew(a.ignoreClockSkew())
+	a.settings.Uploader.SetMinExpirationLifetime(a.cfg.GetDuration(cfgUploaderHeaderExpirationMinLifetime))
+	a.settings.Uploader.SetMaxExpirationLifetime(a.cfg.GetDuration(cfgUploaderHeaderExpirationMaxLifetime))
+	a.settings.Uploader.SetDefaultExpirationLifetime(a.cfg.GetDuration(cfgUploaderHeaderExpirationDefault))
+	a.settings.Uploader.SetJoinDuplicateAttributes(a.joinDuplicateAttributes())
+	a.settings.Uploader.SetDuplicateAttributesSeparator(a.cfg.GetString(cfgUploaderHeaderAttributeSeparator))
+	attributeCase := a.attributeCase()
+	a.settings.Uploader.SetAttributeCase(attributeCase)
+	a.settings.Downloader.SetAttributeCase(attributeCase)
+	a.settings.Uploader.SetContentBlocklist(uploader.NewContentBlocklist(
+		a.cfg.GetStringSlice(cfgUploadBlocklistContentTypes), a.cfg.GetStringSlice(cfgUploadBlocklistExtensions)))
+	a.settings.Uploader.SetCaptcha(a.captchaVerifier(a.cfg.GetBool(cfgUploadCaptchaEnabled)))
+	a.settings.Uploader.SetAbuseReports(a.abuseReports())
+	a.settings.Uploader.SetSlicing(a.slicing())
+	a.settings.Uploader.SetSlicingHeaderAllowed(a.cfg.GetBool(cfgUploadSlicingAllowHeader))
+	a.settings.Uploader.SetSlicingBufferSize(a.cfg.GetInt64(cfgUploadSlicingBufferSize))
+	a.settings.Uploader.SetSlicingSizeHint(a.cfg.GetBool(cfgUploadSlicingSizeHint))
+	a.settings.Uploader.SetNormalizeFilePath(a.cfg.GetBool(cfgUploadPathNormalize))
+	a.settings.Uploader.SetPathConflict(a.pathConflict())
+	a.settings.Uploader.SetBearerDefaults(a.bearerDefaults.Load())
+	if len(a.streamLimits) > 0 {
+		cfg := a.streamLimitConfig()
+		for _, b := range a.streamLimits {
+			b.SetConfig(cfg)
+		}
+	}
+	a.settings.Uploader.SetParallelWrites(a.cfg.GetInt64(cfgUploadParallelWrites))
+	a.settings.Uploader.SetParallelMinParts(a.cfg.GetInt64(cfgUploadParallelMinParts))
+	a.settings.Uploader.SetParallelBufferSize(a.cfg.GetInt64(cfgUploadParallelBufferSize))
+	a.settings.Uploader.SetSystemAttributes(uploader.NewSystemAttributes(
+		a.cfg.GetStringSlice(cfgUploaderHeaderSystemAllowed), a.cfg.GetStringSlice(cfgUploaderHeaderSystemDenied)))
+	a.settings.Uploader.SetMaxCopiesNumber(a.cfg.GetUint32(cfgUploaderHeaderMaxCopiesNumber))
+	a.settings.Uploader.SetImportEnabled(a.cfg.GetBool(cfgImportEnabled))
+	a.settings.Uploader.SetImportConcurrency(a.cfg.GetInt(cfgImportConcurrency))
+	a.settings.Uploader.SetImportMaxRecords(a.cfg.GetInt(cfgImportMaxRecords))
+	a.settings.Uploader.SetImportTimeout(a.cfg.GetDuration(cfgImportTimeout))
+	a.settings.Uploader.SetDeleteEnabled(a.cfg.GetBool(cfgDeleteEnabled))
+	a.settings.Uploader.SetRenameEnabled(a.cfg.GetBool(cfgRenameEnabled))
+	a.settings.Uploader.SetDeleteByPrefixEnabled(a.cfg.GetBool(cfgDeleteByPrefixEnabled))
+	a.settings.Uploader.SetDeleteByPrefixConcurrency(a.cfg.GetInt(cfgDeleteByPrefixConcurrency))
+	a.settings.Uploader.SetDeleteByPrefixMaxObjects(a.cfg.GetInt(cfgDeleteByPrefixMaxObjects))
 	a.settings.Downloader.SetZipCompression(a.cfg.GetBool(cfgZipCompression))
+	a.settings.Downloader.SetZipManifest(a.cfg.GetBool(cfgZipManifest))
+	a.settings.Downloader.SetZipCachePeers(a.zipCachePeers())
+	a.settings.Downloader.SetStallTimeout(a.cfg.GetDuration(cfgWebStallTimeout))
+	a.settings.Uploader.SetRequestTimeout(a.cfg.GetDuration(cfgWebRequestTimeout))
+	a.settings.Downloader.SetRequestTimeout(a.cfg.GetDuration(cfgWebRequestTimeout))
+	a.settings.Downloader.SetSignHeaders(a.cfg.GetBool(cfgWebSignHeaders))
+	a.settings.Downloader.SetDefaultLanguage(a.cfg.GetString(cfgWebDefaultLanguage))
+	a.settings.Downloader.SetPurgeToken(a.cfg.GetString(cfgPurgeToken))
+	a.settings.Downloader.SetBrowseEnabled(a.cfg.GetBool(cfgBrowseEnabled))
+	a.settings.Downloader.SetBrowseMaxObjects(a.cfg.GetInt(cfgBrowseMaxObjects))
+	a.settings.Downloader.SetPreviewEnabled(a.cfg.GetBool(cfgPreviewEnabled))
+	a.settings.Downloader.SetPreviewMaxSize(a.cfg.GetUint64(cfgPreviewMaxSize))
+	a.settings.Uploader.SetSoftDelete(a.cfg.GetBool(cfgSoftDeleteEnabled))
+	a.settings.Uploader.SetDeleteMarkerRetention(a.cfg.GetDuration(cfgSoftDeleteRetention))
+	a.settings.Uploader.SetDeleteMarkerContainers(a.cfg.GetStringSlice(cfgSoftDeleteContainers))
+	a.settings.Uploader.SetDeleteMarkerCollectInterval(a.cfg.GetDuration(cfgSoftDeleteCollectInterval))
+	a.settings.Downloader.SetTail(a.tailConfig())
+	externalURL := a.externalURL()
+	a.settings.Uploader.SetExternalURL(externalURL)
+	a.settings.Downloader.SetExternalURL(externalURL)
+	a.settings.Downloader.SetCanonicalLinks(a.canonicalLinks(externalURL))
+	a.jobs.SetRetention(a.cfg.GetDuration(cfgJobsRetention))
+	a.usage.SetQuotas(a.quotas())
+	a.updateMaxObjectSize(ctx)
+}
+
+// updateMaxObjectSize sets the maximum object size from NeoFS network info,
+// default size is used if the gate isn't connected to NeoFS.
+func (a *app) updateMaxObjectSize(ctx context.Context) {
 	maxObjectSize := defaultObjectSize
 
-	ni, err := a.pool.NetworkInfo(ctx, client.PrmNetworkInfo{})
-	if err != nil {
-		a.log.Error("get network info", zap.Error(err))
-	} else {
-		maxObjectSize = int64(ni.MaxObjectSize())
+	if a.poolConnected.Load() {
+		ni, err := a.pool.NetworkInfo(ctx, client.PrmNetworkInfo{})
+		if err != nil {
+			a.log.Error("get network info", zap.Error(err))
+		} else {
+			maxObjectSize = int64(ni.MaxObjectSize())
+		}
 	}
 
 	a.settings.Uploader.SetMaxObjectSize(maxObjectSize)
@@ -428,6 +1547,95 @@ func (a *app) startServices() {
 	prometheusService := metrics.NewPrometheusService(a.log, prometheusConfig)
 	a.services = append(a.services, prometheusService)
 	go prometheusService.Start()
+
+	instrumentationConfig := metrics.Config{Enabled: a.cfg.GetBool(cfgInstrumentationEnabled), Address: a.cfg.GetString(cfgInstrumentationAddress)}
+	instrumentationService := metrics.NewInstrumentationService(a.log, instrumentationConfig, a.streams, a.zipBuffers)
+	a.services = append(a.services, instrumentationService)
+	go instrumentationService.Start()
+
+	chaosConfig := metrics.Config{Enabled: a.cfg.GetBool(cfgChaosEnabled), Address: a.cfg.GetString(cfgChaosAddress)}
+	chaosService := metrics.NewChaosService(a.log, chaosConfig, a.chaos)
+	a.services = append(a.services, chaosService)
+	go chaosService.Start()
+
+	usageConfig := metrics.Config{Enabled: a.cfg.GetBool(cfgAccountingEnabled), Address: a.cfg.GetString(cfgAccountingAddress)}
+	usageService := metrics.NewUsageService(a.log, usageConfig, a.accounting)
+	a.services = append(a.services, usageService)
+	go usageService.Start()
+
+	pusher, err := metrics.NewPusher(a.log, a.pushConfig())
+	if err != nil {
+		a.log.Warn("failed to configure metrics push", zap.Error(err))
+	}
+	a.pusher = pusher
+	a.pusher.Start()
+}
+
+// metricsEnabled checks whether metrics are collected, they're either exposed
+// for scraping or pushed.
+func (a *app) metricsEnabled() bool {
+	return a.cfg.GetBool(cfgPrometheusEnabled) || a.cfg.GetBool(cfgMetricsPushEnabled)
+}
+
+func (a *app) pushConfig() metrics.PushConfig {
+	return metrics.PushConfig{
+		Enabled:     a.cfg.GetBool(cfgMetricsPushEnabled),
+		Mode:        a.cfg.GetString(cfgMetricsPushMode),
+		URL:         a.cfg.GetString(cfgMetricsPushURL),
+		Job:         a.cfg.GetString(cfgMetricsPushJob),
+		Interval:    a.cfg.GetDuration(cfgMetricsPushInterval),
+		Timeout:     a.cfg.GetDuration(cfgMetricsPushTimeout),
+		Labels:      a.cfg.GetStringMapString(cfgMetricsPushLabels),
+		Username:    a.cfg.GetString(cfgMetricsPushUsername),
+		Password:    a.cfg.GetString(cfgMetricsPushPassword),
+		BearerToken: a.cfg.GetString(cfgMetricsPushBearerToken),
+	}
+}
+
+// accountingHandler returns the handler of the accounting admin listener
+// serving download usage and NeoFS balances. Balances of additional networks
+// are served with the network name prefix like their public routes.
+func (a *app) accountingHandler(uploadRoutes *uploader.Uploader) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/usage", a.usage.Handler())
+	mux.Handle("/v1/accounting/balance", getHandler(a.logger(a.connected(&a.poolConnected, uploadRoutes.Balance))))
+	for _, n := range a.networks {
+		mux.Handle("/"+n.name+"/v1/accounting/balance", getHandler(a.logger(a.connected(n.connected, n.uploader.Balance))))
+	}
+	return mux
+}
+
+// getHandler adapts the handler of GET requests without body to net/http
+// admin listeners, other methods are rejected.
+func getHandler(h fasthttp.RequestHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req fasthttp.Request
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.URL.RequestURI())
+		req.Header.SetHost(r.Host)
+		for k, vals := range r.Header {
+			for _, v := range vals {
+				req.Header.Add(k, v)
+			}
+		}
+		remote, _ := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+
+		var c fasthttp.RequestCtx
+		c.Init(&req, remote, nil)
+		h(&c)
+
+		c.Response.Header.VisitAll(func(k, v []byte) {
+			w.Header().Add(string(k), string(v))
+		})
+		w.WriteHeader(c.Response.StatusCode())
+		_, _ = w.Write(c.Response.Body())
+	})
 }
 
 func (a *app) stopServices() {
@@ -437,34 +1645,171 @@ func (a *app) stopServices() {
 	for _, svc := range a.services {
 		svc.ShutDown(ctx)
 	}
+	a.pusher.ShutDown(ctx)
+	a.pusher = nil
 }
 
 func (a *app) configureRouter(uploadRoutes *uploader.Uploader, downloadRoutes *downloader.Downloader) {
+	r := newRouter()
+	a.addRoutes(r, a.log, &network{
+		connected:  &a.poolConnected,
+		versions:   a.versions,
+		epochs:     a.epochs,
+		uploader:   uploadRoutes,
+		downloader: downloadRoutes,
+	})
+
+	for _, n := range a.networks {
+		if reservedNetworkName(r, n.name) {
+			a.log.Fatal("network name conflicts with gate routes", zap.String("network", n.name))
+		}
+		a.addRoutes(r.Group("/"+n.name), a.log.With(zap.String("network", n.name), zap.String("prefix", "/"+n.name)), n)
+	}
+
+	hosts := make(map[string]fasthttp.RequestHandler, len(a.networkHosts))
+	for _, n := range a.networks {
+		if len(n.hosts) == 0 {
+			continue
+		}
+		hr := newRouter()
+		a.addRoutes(hr, a.log.With(zap.String("network", n.name), zap.Strings("hosts", n.hosts)), n)
+		for _, host := range n.hosts {
+			hosts[host] = hr.Handler
+		}
+	}
+
+	a.webServer.Handler = r.Handler
+	if len(hosts) != 0 {
+		a.webServer.Handler = func(ctx *fasthttp.RequestCtx) {
+			if h, ok := hosts[hostname(ctx.Host())]; ok {
+				h(ctx)
+				return
+			}
+			r.Handler(ctx)
+		}
+	}
+	a.routes = a.webServer.Handler
+	a.webServer.Handler = a.chaos.Wrap(a.checkHost(a.routes))
+	a.webServer.ContinueHandler = a.continueRequest
+}
+
+func newRouter() *router.Router {
 	r := router.New()
 	r.RedirectTrailingSlash = true
 	r.NotFound = func(r *fasthttp.RequestCtx) {
 		response.Error(r, "Not found", fasthttp.StatusNotFound)
 	}
 	r.MethodNotAllowed = func(r *fasthttp.RequestCtx) {
+		// response.Error resets the response, so Allow header set by the
+		// router has to be restored.
+		allow := string(r.Response.Header.Peek(fasthttp.HeaderAllow))
 		response.Error(r, "Method Not Allowed", fasthttp.StatusMethodNotAllowed)
+		r.Response.Header.Set(fasthttp.HeaderAllow, allow)
+	}
+	// Allow header for OPTIONS requests is set by the router itself.
+	r.GlobalOPTIONS = func(r *fasthttp.RequestCtx) {
+		r.SetStatusCode(fasthttp.StatusNoContent)
 	}
-	r.POST("/upload/{cid}", a.logger(uploadRoutes.Upload))
-	a.log.Info("added path /upload/{cid}")
-	r.GET("/get/{cid}/{oid}", a.logger(downloadRoutes.DownloadByAddress))
-	r.HEAD("/get/{cid}/{oid}", a.logger(downloadRoutes.HeadByAddress))
-	a.log.Info("added path /get/{cid}/{oid}")
-	r.GET("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(downloadRoutes.DownloadByAttribute))
-	r.HEAD("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(downloadRoutes.HeadByAttribute))
-	a.log.Info("added path /get_by_attribute/{cid}/{attr_key}/{attr_val:*}")
-	r.GET("/zip/{cid}/{prefix:*}", a.logger(downloadRoutes.DownloadZipped))
-	a.log.Info("added path /zip/{cid}/{prefix}")
+	return r
+}
 
-	a.webServer.Handler = r.Handler
+// routeRegistrar is a router or a group of routes with common path prefix.
+type routeRegistrar interface {
+	GET(path string, handler fasthttp.RequestHandler)
+	HEAD(path string, handler fasthttp.RequestHandler)
+	POST(path string, handler fasthttp.RequestHandler)
+	DELETE(path string, handler fasthttp.RequestHandler)
+}
+
+// addRoutes registers gate routes served by the handlers of the NeoFS network.
+func (a *app) addRoutes(r routeRegistrar, log *zap.Logger, n *network) {
+	r.POST("/upload/{cid}", a.logger(a.metered("upload", validated(a.connected(n.connected, n.uploader.Upload)))))
+	log.Info("added path /upload/{cid}")
+	r.POST("/raw/{cid}", a.logger(a.metered("upload_raw", validated(a.connected(n.connected, n.uploader.UploadRaw)))))
+	log.Info("added path /raw/{cid}")
+	r.GET("/upload_progress/{upload_id}", a.logger(a.metered("upload_progress", validated(n.uploader.UploadProgress))))
+	log.Info("added path /upload_progress/{upload_id}")
+	r.POST("/lock/{cid}/{oid}", a.logger(a.metered("lock", validated(a.connected(n.connected, n.uploader.Lock)))))
+	r.DELETE("/lock/{cid}/{oid}", a.logger(a.metered("unlock", validated(a.connected(n.connected, n.uploader.Unlock)))))
+	log.Info("added path /lock/{cid}/{oid}")
+	r.POST("/report/{cid}/{oid}", a.logger(a.metered("report", validated(a.connected(n.connected, n.uploader.Report)))))
+	log.Info("added path /report/{cid}/{oid}")
+	r.GET("/v1/expiration", a.logger(a.metered("expiration", validated(a.connected(n.connected, n.uploader.Expiration)))))
+	log.Info("added path /v1/expiration")
+	r.GET("/v1/info", a.logger(a.metered("info", infoHandler(n.versions, n.epochs))))
+	log.Info("added path /v1/info")
+	r.POST("/import/{cid}", a.logger(a.metered("import", validated(a.connected(n.connected, n.uploader.Import)))))
+	log.Info("added path /import/{cid}")
+	r.GET("/import_status/{id}", a.logger(a.metered("import_status", validated(a.jobs.StatusHandler))))
+	log.Info("added path /import_status/{id}")
+	r.POST("/delete_by_prefix/{cid}", a.logger(a.metered("delete_by_prefix", validated(a.connected(n.connected, n.uploader.DeleteByPrefix)))))
+	log.Info("added path /delete_by_prefix/{cid}")
+	r.POST("/rename/{cid}", a.logger(a.metered("rename", validated(a.connected(n.connected, n.uploader.Rename)))))
+	log.Info("added path /rename/{cid}")
+	r.GET("/v1/jobs/{id}", a.logger(a.metered("jobs", validated(a.jobs.StatusHandler))))
+	log.Info("added path /v1/jobs/{id}")
+	r.GET("/get/{cid}/{oid}", a.logger(a.metered("get", validated(a.connected(n.connected, n.downloader.DownloadByAddress)))))
+	r.HEAD("/get/{cid}/{oid}", a.logger(a.metered("head", validated(a.connected(n.connected, n.downloader.HeadByAddress)))))
+	r.DELETE("/get/{cid}/{oid}", a.logger(a.metered("delete", validated(a.connected(n.connected, n.uploader.Delete)))))
+	log.Info("added path /get/{cid}/{oid}")
+	r.GET("/get/{address}", a.logger(a.metered("get", validated(a.connected(n.connected, n.downloader.DownloadByAddressString)))))
+	r.HEAD("/get/{address}", a.logger(a.metered("head", validated(a.connected(n.connected, n.downloader.HeadByAddressString)))))
+	log.Info("added path /get/{address}")
+	r.GET("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(a.metered("get_by_attribute", validated(a.connected(n.connected, n.downloader.DownloadByAttribute)))))
+	r.HEAD("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(a.metered("head_by_attribute", validated(a.connected(n.connected, n.downloader.HeadByAttribute)))))
+	r.DELETE("/get_by_attribute/{cid}/{attr_key}/{attr_val:*}", a.logger(a.metered("delete_by_attribute", validated(a.connected(n.connected, n.uploader.Delete)))))
+	log.Info("added path /get_by_attribute/{cid}/{attr_key}/{attr_val:*}")
+	r.GET("/versions/{cid}/{path:*}", a.logger(a.metered("versions", validated(a.connected(n.connected, n.downloader.Versions)))))
+	if a.cfg.GetBool(cfgSoftDeleteEnabled) {
+		// versions are removed for real only to purge soft-deleted files
+		r.DELETE("/versions/{cid}/{path:*}", a.logger(a.metered("delete_versions", validated(a.connected(n.connected, n.uploader.DeleteVersions)))))
+	}
+	log.Info("added path /versions/{cid}/{path}")
+	r.GET("/range/{cid}/{oid}/{offset}/{length}", a.logger(a.metered("range", validated(a.connected(n.connected, n.downloader.DownloadRange)))))
+	log.Info("added path /range/{cid}/{oid}/{offset}/{length}")
+	r.GET("/range_hash/{cid}/{oid}", a.logger(a.metered("range_hash", validated(a.connected(n.connected, n.downloader.RangeHash)))))
+	log.Info("added path /range_hash/{cid}/{oid}")
+	r.GET("/raw/{cid}/{oid}", a.logger(a.metered("raw", validated(a.connected(n.connected, n.downloader.DownloadRaw)))))
+	log.Info("added path /raw/{cid}/{oid}")
+	r.GET("/zip/{cid}/{prefix:*}", a.logger(a.metered("zip", validated(a.connected(n.connected, n.downloader.DownloadZipped)))))
+	log.Info("added path /zip/{cid}/{prefix}")
+	r.GET("/tombstone/{cid}/{oid}", a.logger(a.metered("tombstone", validated(a.connected(n.connected, n.downloader.Tombstone)))))
+	log.Info("added path /tombstone/{cid}/{oid}")
+	r.POST("/v1/purge/{cid}", a.logger(a.metered("purge", validated(a.connected(n.connected, n.downloader.Purge)))))
+	r.POST("/v1/purge/{cid}/{oid}", a.logger(a.metered("purge", validated(a.connected(n.connected, n.downloader.Purge)))))
+	log.Info("added path /v1/purge/{cid}/{oid}")
+	r.GET("/export/{cid}", a.logger(a.metered("export", validated(a.connected(n.connected, n.downloader.Export)))))
+	log.Info("added path /export/{cid}")
+	r.GET("/browse/{cid}", a.logger(a.metered("browse", validated(a.connected(n.connected, n.downloader.Browse)))))
+	r.GET("/browse/{cid}/{prefix:*}", a.logger(a.metered("browse", validated(a.connected(n.connected, n.downloader.Browse)))))
+	log.Info("added path /browse/{cid}/{prefix}")
+	r.GET("/preview/{cid}/{oid}", a.logger(a.metered("preview", validated(a.connected(n.connected, n.downloader.Preview)))))
+	log.Info("added path /preview/{cid}/{oid}")
+	r.GET("/qr/{cid}/{oid}", a.logger(a.metered("qr", validated(a.connected(n.connected, n.downloader.QR)))))
+	log.Info("added path /qr/{cid}/{oid}")
+	r.GET("/tail/{cid}", a.logger(a.metered("tail", validated(a.connected(n.connected, n.downloader.Tail)))))
+	log.Info("added path /tail/{cid}")
+}
+
+// continueRequest decides whether the body of the request with
+// 'Expect: 100-continue' header is received. Only requests exceeding the body
+// size limit (it's applied only if the body isn't streamed) get '417
+// Expectation Failed', other requests are checked by their handlers.
+func (a *app) continueRequest(h *fasthttp.RequestHeader) bool {
+	limit := a.webServer.MaxRequestBodySize
+	if limit <= 0 {
+		limit = fasthttp.DefaultMaxRequestBodySize
+	}
+	if size := h.ContentLength(); !a.webServer.StreamRequestBody && size > limit {
+		a.log.Info("request body is too large", zap.ByteString("path", h.RequestURI()), zap.Int("size", size), zap.Int("limit", limit))
+		return false
+	}
+	return true
 }
 
 func (a *app) logger(h fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
-		a.log.Info("request", zap.String("remote", ctx.RemoteAddr().String()),
+		a.log.Info("request", zap.Stringer("remote", a.clientIP.Store(ctx)),
 			zap.ByteString("method", ctx.Method()),
 			zap.ByteString("path", ctx.Path()),
 			zap.ByteString("query", ctx.QueryArgs().QueryString()),
@@ -473,24 +1818,79 @@ func (a *app) logger(h fasthttp.RequestHandler) fasthttp.RequestHandler {
 	}
 }
 
+// validated rejects requests with malformed path parameters.
+func validated(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if err := utils.ValidatePathParams(ctx); err != nil {
+			response.ErrorWithCode(ctx, err.Code, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		h(ctx)
+	}
+}
+
+// metered accounts requests handled by h in request metrics under the given
+// route name. Durations of request processing stages are reported in
+// Server-Timing header if it's enabled. Requests rejected by the access rules
+// of the route aren't passed to h.
+func (a *app) metered(route string, h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		timing := a.serverTiming.Load()
+		if timing {
+			servertiming.Enable(ctx)
+		}
+		if tkn := a.defaultBearerToken(ctx); tkn != nil {
+			tokens.StoreDefault(ctx, tkn)
+		}
+		if a.accessAllowed(ctx, route) {
+			if limits := a.searchLimits.Load(); limits != nil {
+				searchlimit.Store(ctx, limits.For(route))
+			}
+			h(ctx)
+		}
+		elapsed := time.Since(start)
+		if timing {
+			servertiming.Write(ctx, elapsed)
+		}
+		cnr, _ := ctx.UserValue("cid").(string)
+		a.metrics.ObserveRequest(route, cnr, ctx.Response.StatusCode(), elapsed)
+		if response.IsStorageAccessDenied(ctx) {
+			a.metrics.IncAccessDenied(route, cnr)
+		}
+	}
+}
+
 func (a *app) AppParams() *utils.AppParams {
 	return &utils.AppParams{
-		Logger:   a.log,
-		Pool:     a.pool,
-		Owner:    a.owner,
-		Resolver: a.resolverContainer,
+		Logger:      a.log,
+		Backend:     a.backend(a.pool, ""),
+		Owner:       a.owner,
+		Resolver:    a.resolverContainer,
+		Jobs:        a.jobs,
+		Metrics:     a.metrics,
+		Versions:    a.versions,
+		Usage:       a.usage,
+		Purgers:     a.purgers,
+		Streams:     a.streams,
+		ZipBuffers:  a.zipBuffers,
+		Transforms:  a.transforms,
+		Resolutions: a.resolutionCache(""),
+		PathFilters: a.pathFilter(""),
+		ExtCache:    a.externalCacheInvalidator(""),
 	}
 }
 
 func (a *app) initServers(ctx context.Context) {
 	serversInfo := fetchServers(a.cfg)
+	keepalive := a.tcpKeepalivePeriod()
 
 	a.servers = make([]Server, len(serversInfo))
 	for i, serverInfo := range serversInfo {
 		a.log.Info("added server",
 			zap.String("address", serverInfo.Address), zap.Bool("tls enabled", serverInfo.TLS.Enabled),
 			zap.String("tls cert", serverInfo.TLS.CertFile), zap.String("tls key", serverInfo.TLS.KeyFile))
-		a.servers[i] = newServer(ctx, serverInfo, a.log)
+		a.servers[i] = newServer(ctx, serverInfo, keepalive, a.log)
 	}
 }
 
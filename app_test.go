@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/searchlimit"
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type observedRequest struct {
+	route, container string
+	status           int
+}
+
+type fakeMetricsProvider struct {
+	requests []observedRequest
+	denials  []observedRequest
+}
+
+func (f *fakeMetricsProvider) SetHealth(int32) {}
+
+func (f *fakeMetricsProvider) ObserveRequest(route, container string, status int, _ time.Duration) {
+	f.requests = append(f.requests, observedRequest{route: route, container: container, status: status})
+}
+
+func (f *fakeMetricsProvider) IncAccessDenied(route, container string) {
+	f.denials = append(f.denials, observedRequest{route: route, container: container})
+}
+
+func (f *fakeMetricsProvider) AddDownloadedBytes(int) {}
+
+func (f *fakeMetricsProvider) IncDownloadStalls(string) {}
+
+func (f *fakeMetricsProvider) IncUploadAborts() {}
+
+func (f *fakeMetricsProvider) AddReclaimed(string, int, int64) {}
+
+func (f *fakeMetricsProvider) IncMaintenanceFailures(string) {}
+
+func (f *fakeMetricsProvider) Unregister() {}
+
+func TestGateMetricsContainerLabels(t *testing.T) {
+	provider := new(fakeMetricsProvider)
+	m := newGateMetrics(zap.NewNop(), provider, true)
+	m.SetEnabled(true)
+	m.SetContainerLabels([]string{"allowed"})
+
+	m.ObserveRequest("get", "allowed", 200, time.Second)
+	m.ObserveRequest("get", "unknown", 404, time.Second)
+	m.ObserveRequest("get", "", 400, time.Second)
+
+	require.Equal(t, []observedRequest{
+		{route: "get", container: "allowed", status: 200},
+		{route: "get", container: otherContainerLabel, status: 404},
+		{route: "get", container: "", status: 400},
+	}, provider.requests)
+
+	m.IncAccessDenied("get", "allowed")
+	m.IncAccessDenied("upload", "unknown")
+	require.Equal(t, []observedRequest{
+		{route: "get", container: "allowed"},
+		{route: "upload", container: otherContainerLabel},
+	}, provider.denials)
+
+	m.SetEnabled(false)
+	m.ObserveRequest("get", "allowed", 200, time.Second)
+	m.IncAccessDenied("get", "allowed")
+	require.Len(t, provider.requests, 3)
+	require.Len(t, provider.denials, 2)
+}
+
+func TestRouterMethods(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{
+			name:   "options on get",
+			method: fasthttp.MethodOptions,
+			path:   "/get/cid/oid",
+			status: fasthttp.StatusNoContent,
+			allow:  "DELETE, GET, HEAD, OPTIONS",
+		},
+		{
+			name:   "options on single segment address",
+			method: fasthttp.MethodOptions,
+			path:   "/get/cid:oid",
+			status: fasthttp.StatusNoContent,
+			allow:  "GET, HEAD, OPTIONS",
+		},
+		{
+			name:   "options on upload",
+			method: fasthttp.MethodOptions,
+			path:   "/upload/cid",
+			status: fasthttp.StatusNoContent,
+			allow:  "OPTIONS, POST",
+		},
+		{
+			name:   "wrong method on get",
+			method: fasthttp.MethodPut,
+			path:   "/get/cid/oid",
+			status: fasthttp.StatusMethodNotAllowed,
+			allow:  "DELETE, GET, HEAD, OPTIONS",
+		},
+		{
+			name:   "wrong method on zip",
+			method: fasthttp.MethodPost,
+			path:   "/zip/cid/prefix",
+			status: fasthttp.StatusMethodNotAllowed,
+			allow:  "GET, OPTIONS",
+		},
+		{
+			name:   "options on browse directory",
+			method: fasthttp.MethodOptions,
+			path:   "/browse/cid/dir/",
+			status: fasthttp.StatusNoContent,
+			allow:  "GET, OPTIONS",
+		},
+		{
+			name:   "options on browse root",
+			method: fasthttp.MethodOptions,
+			path:   "/browse/cid",
+			status: fasthttp.StatusNoContent,
+			allow:  "GET, OPTIONS",
+		},
+		{
+			name:   "options on versions without soft delete",
+			method: fasthttp.MethodOptions,
+			path:   "/versions/cid/dir/file.txt",
+			status: fasthttp.StatusNoContent,
+			allow:  "GET, OPTIONS",
+		},
+		{
+			name:   "unknown path",
+			method: fasthttp.MethodGet,
+			path:   "/unknown",
+			status: fasthttp.StatusNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			ctx.Request.Header.SetMethod(tc.method)
+			ctx.Request.SetRequestURI(tc.path)
+
+			a.webServer.Handler(&ctx)
+
+			require.Equal(t, tc.status, ctx.Response.StatusCode())
+			require.Equal(t, tc.allow, string(ctx.Response.Header.Peek(fasthttp.HeaderAllow)))
+		})
+	}
+
+	t.Run("options on versions with soft delete", func(t *testing.T) {
+		a.cfg.Set(cfgSoftDeleteEnabled, true)
+		a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(fasthttp.MethodOptions)
+		ctx.Request.SetRequestURI("/versions/cid/dir/file.txt")
+		a.webServer.Handler(&ctx)
+		require.Equal(t, "DELETE, GET, OPTIONS", string(ctx.Response.Header.Peek(fasthttp.HeaderAllow)))
+	})
+}
+
+func TestRouterPathValidation(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+	for _, tc := range []struct {
+		name string
+		path string
+		code string
+	}{
+		{
+			name: "long object id",
+			path: "/get/cid/" + strings.Repeat("a", utils.MaxObjectParamLength+1),
+			code: utils.ErrCodeInvalidObjectID,
+		},
+		{
+			name: "container with forbidden characters",
+			path: "/get/c%20id/oid",
+			code: utils.ErrCodeInvalidContainerID,
+		},
+		{
+			name: "control character in attribute",
+			path: "/get_by_attribute/cid/key/val%0A",
+			code: utils.ErrCodeInvalidAttribute,
+		},
+		{
+			name: "invalid job id",
+			path: "/v1/jobs/..%2F..",
+			code: utils.ErrCodeInvalidJobID,
+		},
+		{
+			name: "upload id with forbidden characters",
+			path: "/upload_progress/up%20load",
+			code: utils.ErrCodeInvalidUploadID,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+			ctx.Request.SetRequestURI(tc.path)
+
+			a.webServer.Handler(&ctx)
+
+			require.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+			require.Equal(t, tc.code, string(ctx.Response.Header.Peek(response.HeaderErrorCode)))
+		})
+	}
+}
+
+func TestContinueRequest(t *testing.T) {
+	a := &app{log: zap.NewNop(), webServer: &fasthttp.Server{MaxRequestBodySize: 10}}
+
+	var h fasthttp.RequestHeader
+	h.SetMethod(fasthttp.MethodPost)
+	h.SetRequestURI("/import/cid")
+	h.SetContentLength(11)
+
+	require.False(t, a.continueRequest(&h))
+
+	// streamed body isn't limited
+	a.webServer.StreamRequestBody = true
+	require.True(t, a.continueRequest(&h))
+
+	h.SetContentLength(10)
+	a.webServer.StreamRequestBody = false
+	require.True(t, a.continueRequest(&h))
+}
+
+func TestRouterNotConnected(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.cfg.Set(cfgPoolRedialInterval, 1500*time.Millisecond)
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+
+	request := func(method, path string) *fasthttp.RequestCtx {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(path)
+		a.webServer.Handler(&ctx)
+		return &ctx
+	}
+
+	for _, path := range []string{"/get/cid/oid", "/upload/cid", "/browse/cid"} {
+		method := fasthttp.MethodGet
+		if strings.HasPrefix(path, "/upload/") {
+			method = fasthttp.MethodPost
+		}
+
+		ctx := request(method, path)
+		require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode(), path)
+		require.Equal(t, errCodeStorageUnavailable, string(ctx.Response.Header.Peek(response.HeaderErrorCode)), path)
+		require.Equal(t, "2", string(ctx.Response.Header.Peek(fasthttp.HeaderRetryAfter)), path)
+	}
+
+	// malformed requests are rejected anyway
+	ctx := request(fasthttp.MethodGet, "/get/c%20id/oid")
+	require.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+
+	// requests not involving NeoFS are served
+	ctx = request(fasthttp.MethodGet, "/v1/info")
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	require.Equal(t, 1, retryAfterSeconds(0))
+	require.Equal(t, 1, retryAfterSeconds(time.Millisecond))
+	require.Equal(t, 10, retryAfterSeconds(10*time.Second))
+	require.Equal(t, 11, retryAfterSeconds(10*time.Second+time.Millisecond))
+}
+
+func TestConnectionLimits(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+	}
+	a.cfg.Set(cfgWebConcurrency, 100)
+	a.cfg.Set(cfgWebMaxConnsPerIP, -1)
+	a.cfg.Set(cfgWebMaxRequestsPerConn, 10)
+	a.cfg.Set(cfgWebIdleTimeout, time.Minute)
+	a.setConnectionLimits()
+	require.Equal(t, 100, a.webServer.Concurrency)
+	require.Zero(t, a.webServer.MaxConnsPerIP)
+	require.Equal(t, 10, a.webServer.MaxRequestsPerConn)
+	require.Equal(t, time.Minute, a.webServer.IdleTimeout)
+
+	a.cfg.Set(cfgWebTCPKeepalive, true)
+	a.cfg.Set(cfgWebTCPKeepalivePeriod, 30*time.Second)
+	require.Equal(t, 30*time.Second, a.tcpKeepalivePeriod())
+	a.cfg.Set(cfgWebTCPKeepalivePeriod, 0)
+	require.Equal(t, defaultTCPKeepalivePeriod, a.tcpKeepalivePeriod())
+	a.cfg.Set(cfgWebTCPKeepalive, false)
+	require.Negative(t, a.tcpKeepalivePeriod())
+}
+
+func TestShutdown(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.cfg.Set(cfgShutdownTimeout, 50*time.Millisecond)
+	a.opCtx, a.cancelOps = context.WithCancel(context.Background())
+	a.jobs = jobs.NewManager(a.opCtx, zap.NewNop())
+
+	finished, err := a.jobs.Start("fast", 0, func(context.Context, *jobs.Job) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+	canceled, err := a.jobs.Start("slow", 0, func(ctx context.Context, _ *jobs.Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+
+	a.shutdown()
+	require.Equal(t, jobs.StateCompleted, finished.Status().State)
+	require.Equal(t, jobs.StateCanceled, canceled.Status().State)
+	require.Error(t, a.opCtx.Err())
+}
+
+func TestSearchLimitsConfig(t *testing.T) {
+	a := &app{log: zap.NewNop(), cfg: viper.New()}
+	a.cfg.SetConfigType("yaml")
+	require.NoError(t, a.cfg.ReadConfig(strings.NewReader(`
+search:
+  timeout: 30s
+  routes:
+    - routes: [get_by_attribute, head_by_attribute]
+      timeout: 5s
+    - routes: [zip]
+      max_results: 100
+`)))
+
+	cfg := a.searchLimitsConfig()
+	require.Equal(t, searchlimit.Limits{Timeout: 5 * time.Second}, cfg.For("head_by_attribute"))
+	require.Equal(t, searchlimit.Limits{Timeout: 30 * time.Second, MaxResults: 100}, cfg.For("zip"))
+	require.Equal(t, searchlimit.Limits{Timeout: 30 * time.Second}, cfg.For("browse"))
+}
+
+func TestAccountingHandler(t *testing.T) {
+	a := &app{
+		log:       zap.NewNop(),
+		cfg:       viper.New(),
+		webServer: new(fasthttp.Server),
+		metrics:   newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+	}
+	a.cfg.Set(cfgPoolRedialInterval, time.Second)
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), new(downloader.Downloader))
+	h := a.accountingHandler(new(uploader.Uploader))
+
+	// the balance isn't served on the public listener
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/v1/accounting/balance")
+	a.webServer.Handler(&ctx)
+	require.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/accounting/balance", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, errCodeStorageUnavailable, w.Header().Get(response.HeaderErrorCode))
+	require.Equal(t, "1", w.Header().Get(fasthttp.HeaderRetryAfter))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/accounting/balance", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/usage", nil))
+	require.Equal(t, http.StatusNotFound, w.Code, "accounting is disabled")
+}
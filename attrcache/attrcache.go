@@ -0,0 +1,189 @@
+// Package attrcache caches results of object searches by attribute value, so
+// hot attribute-based and path-based downloads don't wait for a search every
+// time. Objects written and removed through the gateway invalidate entries
+// they can change, changes made by other clients are seen after the TTL.
+package attrcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+type key struct {
+	cnr       cid.ID
+	attr, val string
+}
+
+type entry struct {
+	ids     []oid.ID
+	expires time.Time
+}
+
+// Cache contains IDs of objects found by attribute value. Found objects are
+// cached for the TTL, empty results are cached for the negative TTL.
+type Cache struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	size        int
+
+	mu      sync.Mutex
+	entries map[key]entry
+}
+
+// New is a constructor for the Cache. Size limits the number of cached
+// results, zero negative TTL disables caching of empty results.
+func New(ttl, negativeTTL time.Duration, size int) *Cache {
+	return &Cache{
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		size:        size,
+		entries:     make(map[key]entry),
+	}
+}
+
+// Get returns IDs of objects of the container having the attribute value,
+// they're empty if no objects have been found. False is returned if there is
+// no cached result.
+func (c *Cache) Get(cnrID cid.ID, attr, val string) ([]oid.ID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := key{cnr: cnrID, attr: attr, val: val}
+	e, ok := c.entries[k]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(e.expires) {
+		delete(c.entries, k)
+		return nil, false
+	}
+	return e.ids, true
+}
+
+// Put caches IDs of objects found by the attribute value.
+func (c *Cache) Put(cnrID cid.ID, attr, val string, ids []oid.ID) {
+	ttl := c.ttl
+	if len(ids) == 0 {
+		ttl = c.negativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	e := entry{ids: make([]oid.ID, len(ids)), expires: now.Add(ttl)}
+	copy(e.ids, ids)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	if len(c.entries) < c.size {
+		c.entries[key{cnr: cnrID, attr: attr, val: val}] = e
+	}
+}
+
+// evict removes expired entries, if there are none, the entry expiring
+// first is removed.
+func (c *Cache) evict(now time.Time) {
+	var (
+		oldestKey key
+		oldest    time.Time
+		found     bool
+	)
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+			continue
+		}
+		if !found || e.expires.Before(oldest) {
+			oldestKey, oldest, found = k, e.expires, true
+		}
+	}
+	if len(c.entries) >= c.size && found {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// Invalidate removes results the object with the attributes can be found in.
+func (c *Cache) Invalidate(cnrID cid.ID, attrs []object.Attribute) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, a := range attrs {
+		delete(c.entries, key{cnr: cnrID, attr: a.Key(), val: a.Value()})
+	}
+}
+
+// InvalidateContainer removes all results of the container.
+func (c *Cache) InvalidateContainer(cnrID cid.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.cnr == cnrID {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Backend invalidates cached results changed by objects written and removed
+// via the wrapped backend.
+type Backend struct {
+	backend.Backend
+	cache *Cache
+}
+
+// NewBackend wraps the backend invalidating the cache.
+func NewBackend(b backend.Backend, c *Cache) *Backend {
+	return &Backend{Backend: b, cache: c}
+}
+
+// ObjectPutInit implements backend.Backend. Results the object can be found
+// in are invalidated when it's stored. Objects written by parts are
+// invalidated by attributes of the parent object.
+func (b *Backend) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (backend.ObjectWriter, error) {
+	w, err := b.Backend.ObjectPutInit(ctx, hdr, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	return &invalidatingWriter{ObjectWriter: w, cache: b.cache, hdr: hdr}, nil
+}
+
+// ObjectDelete implements backend.Backend. Attributes of the removed object
+// are unknown, so all results of the container are invalidated.
+func (b *Backend) ObjectDelete(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectDelete) (oid.ID, error) {
+	res, err := b.Backend.ObjectDelete(ctx, containerID, objectID, signer, prm)
+	if err == nil {
+		b.cache.InvalidateContainer(containerID)
+	}
+	return res, err
+}
+
+type invalidatingWriter struct {
+	backend.ObjectWriter
+	cache *Cache
+	hdr   object.Object
+}
+
+func (w *invalidatingWriter) Close() error {
+	if err := w.ObjectWriter.Close(); err != nil {
+		return err
+	}
+	cnrID, ok := w.hdr.ContainerID()
+	if !ok {
+		return nil
+	}
+	w.cache.Invalidate(cnrID, w.hdr.Attributes())
+	if parent := w.hdr.Parent(); parent != nil {
+		w.cache.Invalidate(cnrID, parent.Attributes())
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package attrcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func attribute(key, val string) object.Attribute {
+	var a object.Attribute
+	a.SetKey(key)
+	a.SetValue(val)
+	return a
+}
+
+func TestCache(t *testing.T) {
+	var (
+		cnrID = cid.ID{1}
+		ids   = []oid.ID{oidtest.ID(), oidtest.ID()}
+	)
+
+	c := New(time.Minute, time.Minute, 2)
+	_, ok := c.Get(cnrID, "FileName", "a")
+	require.False(t, ok)
+
+	c.Put(cnrID, "FileName", "a", ids)
+	c.Put(cnrID, "FileName", "b", nil)
+	res, ok := c.Get(cnrID, "FileName", "a")
+	require.True(t, ok)
+	require.Equal(t, ids, res)
+	res, ok = c.Get(cnrID, "FileName", "b")
+	require.True(t, ok)
+	require.Empty(t, res)
+	_, ok = c.Get(cid.ID{2}, "FileName", "a")
+	require.False(t, ok)
+
+	// the entry expiring first is evicted
+	c.Put(cnrID, "FileName", "c", ids[:1])
+	_, ok = c.Get(cnrID, "FileName", "a")
+	require.False(t, ok)
+	require.Len(t, c.entries, 2)
+
+	c.Invalidate(cnrID, []object.Attribute{attribute("FileName", "c")})
+	_, ok = c.Get(cnrID, "FileName", "c")
+	require.False(t, ok)
+	c.InvalidateContainer(cnrID)
+	require.Empty(t, c.entries)
+
+	t.Run("ttl", func(t *testing.T) {
+		c := New(time.Millisecond, 0, 10)
+		c.Put(cnrID, "FileName", "a", ids)
+		c.Put(cnrID, "FileName", "b", nil)
+		require.Len(t, c.entries, 1, "negative caching is disabled")
+		time.Sleep(2 * time.Millisecond)
+		_, ok := c.Get(cnrID, "FileName", "a")
+		require.False(t, ok)
+	})
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	c := New(time.Minute, time.Minute, 10)
+	b := NewBackend(mem, c)
+	c.Put(cnrID, "FileName", "a", nil)
+	c.Put(cnrID, "FileName", "b", nil)
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	hdr.SetAttributes(attribute("FileName", "a"))
+	w, err := b.ObjectPutInit(ctx, hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, ok := c.Get(cnrID, "FileName", "a")
+	require.True(t, ok, "invalidated once the object is stored")
+	require.NoError(t, w.Close())
+	_, ok = c.Get(cnrID, "FileName", "a")
+	require.False(t, ok)
+	_, ok = c.Get(cnrID, "FileName", "b")
+	require.True(t, ok)
+
+	_, err = b.ObjectDelete(ctx, cnrID, w.StoredObjectID(), nil, client.PrmObjectDelete{})
+	require.NoError(t, err)
+	_, ok = c.Get(cnrID, "FileName", "b")
+	require.False(t, ok)
+}
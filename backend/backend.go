@@ -0,0 +1,198 @@
+// Package backend abstracts NeoFS operations used by the gateway handlers, so
+// they can be served by the connection pool or by an alternative
+// implementation, e.g. in-memory one in tests.
+package backend
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// Backend executes NeoFS operations. Methods follow the ones of pool.Pool
+// returning interfaces instead of the SDK stream types, NeoFS failures are
+// returned as apistatus errors.
+type Backend interface {
+	ContainerGet(ctx context.Context, id cid.ID, prm client.PrmContainerGet) (container.Container, error)
+	NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error)
+	// BalanceGet requests the balance of the account, it's passed separately
+	// since it can't be read from prm.
+	BalanceGet(ctx context.Context, account user.ID, prm client.PrmBalanceGet) (accounting.Decimal, error)
+	ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (ObjectWriter, error)
+	ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error)
+	ObjectHead(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectHead) (*object.Object, error)
+	ObjectRangeInit(ctx context.Context, containerID cid.ID, objectID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error)
+	ObjectDelete(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectDelete) (oid.ID, error)
+	// ObjectSearchInit searches for objects matching the filters. Filters
+	// are passed separately since they can't be read from prm, they're set
+	// to it by the backend.
+	ObjectSearchInit(ctx context.Context, containerID cid.ID, signer user.Signer, filters object.SearchFilters, prm client.PrmObjectSearch) (ObjectLister, error)
+	// ObjectHash requests checksums of the payload ranges given as (offset,
+	// length) pairs. Checksum type and ranges are passed separately like
+	// search filters.
+	ObjectHash(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, typ checksum.Type, ranges []uint64, prm client.PrmObjectHash) ([][]byte, error)
+}
+
+// ObjectWriter writes the object payload, the object is stored on Close.
+type ObjectWriter interface {
+	io.WriteCloser
+	// StoredObjectID returns the ID of the object stored by Close.
+	StoredObjectID() oid.ID
+}
+
+// ObjectLister reads IDs of the found objects, see client.ObjectListReader.
+type ObjectLister interface {
+	Read(buf []oid.ID) (int, error)
+	Iterate(f func(oid.ID) bool) error
+	Close() error
+}
+
+// Pool is the Backend executing operations via the connection pool. The pool
+// can be replaced at runtime, e.g. when storage nodes are rediscovered.
+type Pool struct {
+	p atomic.Pointer[pool.Pool]
+}
+
+// NewPool wraps the connection pool.
+func NewPool(p *pool.Pool) *Pool {
+	var b Pool
+	b.p.Store(p)
+	return &b
+}
+
+// Swap replaces the connection pool and returns the previous one. Operations
+// started before are finished by the previous pool, so it must be closed by
+// the caller when they're done.
+func (b *Pool) Swap(p *pool.Pool) *pool.Pool {
+	return b.p.Swap(p)
+}
+
+// Close closes the current connection pool.
+func (b *Pool) Close() {
+	b.p.Load().Close()
+}
+
+// NetMapSnapshot requests the current network map, it isn't a part of Backend
+// since handlers don't need it.
+func (b *Pool) NetMapSnapshot(ctx context.Context, prm client.PrmNetMapSnapshot) (netmap.NetMap, error) {
+	return b.p.Load().NetMapSnapshot(ctx, prm)
+}
+
+// ContainerGet implements Backend.
+func (b *Pool) ContainerGet(ctx context.Context, id cid.ID, prm client.PrmContainerGet) (container.Container, error) {
+	return b.p.Load().ContainerGet(ctx, id, prm)
+}
+
+// NetworkInfo implements Backend.
+func (b *Pool) NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	return b.p.Load().NetworkInfo(ctx, prm)
+}
+
+// BalanceGet implements Backend.
+func (b *Pool) BalanceGet(ctx context.Context, account user.ID, prm client.PrmBalanceGet) (accounting.Decimal, error) {
+	prm.SetAccount(account)
+	return b.p.Load().BalanceGet(ctx, prm)
+}
+
+// ObjectPutInit implements Backend.
+func (b *Pool) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (ObjectWriter, error) {
+	w, err := b.p.Load().ObjectPutInit(ctx, hdr, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	return poolObjectWriter{w}, nil
+}
+
+// ObjectGetInit implements Backend.
+func (b *Pool) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	hdr, r, err := b.p.Load().ObjectGetInit(ctx, containerID, objectID, signer, prm)
+	if err != nil {
+		return hdr, nil, err
+	}
+	return hdr, r, nil
+}
+
+// ObjectHead implements Backend.
+func (b *Pool) ObjectHead(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectHead) (*object.Object, error) {
+	return b.p.Load().ObjectHead(ctx, containerID, objectID, signer, prm)
+}
+
+// ObjectRangeInit implements Backend.
+func (b *Pool) ObjectRangeInit(ctx context.Context, containerID cid.ID, objectID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error) {
+	r, err := b.p.Load().ObjectRangeInit(ctx, containerID, objectID, offset, length, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ObjectDelete implements Backend.
+func (b *Pool) ObjectDelete(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectDelete) (oid.ID, error) {
+	return b.p.Load().ObjectDelete(ctx, containerID, objectID, signer, prm)
+}
+
+// ObjectSearchInit implements Backend.
+func (b *Pool) ObjectSearchInit(ctx context.Context, containerID cid.ID, signer user.Signer, filters object.SearchFilters, prm client.PrmObjectSearch) (ObjectLister, error) {
+	prm.SetFilters(filters)
+	r, err := b.p.Load().ObjectSearchInit(ctx, containerID, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ObjectHash implements Backend.
+func (b *Pool) ObjectHash(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, typ checksum.Type, ranges []uint64, prm client.PrmObjectHash) ([][]byte, error) {
+	if typ == checksum.TZ {
+		prm.TillichZemorAlgo()
+	}
+	prm.SetRangeList(ranges...)
+	return b.p.Load().ObjectHash(ctx, containerID, objectID, signer, prm)
+}
+
+type poolObjectWriter struct {
+	client.ObjectWriter
+}
+
+func (w poolObjectWriter) StoredObjectID() oid.ID {
+	return w.GetResult().StoredObjectID()
+}
+
+// ObjectWriterFor adapts the backend to be used by slicer.
+func ObjectWriterFor(b Backend) slicer.ObjectWriter {
+	return slicerWriter{b}
+}
+
+type slicerWriter struct {
+	b Backend
+}
+
+func (w slicerWriter) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	ow, err := w.b.ObjectPutInit(ctx, hdr, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	return slicerObjectWriter{ow}, nil
+}
+
+// slicerObjectWriter doesn't provide the result, slicer calculates object IDs
+// itself.
+type slicerObjectWriter struct {
+	io.WriteCloser
+}
+
+func (slicerObjectWriter) GetResult() client.ResObjectPut {
+	return client.ResObjectPut{}
+}
@@ -0,0 +1,459 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/tzhash/tz"
+)
+
+// defaultMemoryMaxObjectSize is the maximum object size of the in-memory
+// network unless other network info is set.
+const defaultMemoryMaxObjectSize = 64 << 20
+
+// Memory is the Backend keeping containers and objects in memory, it's
+// intended for tests. Access control isn't done, so bearer tokens and signers
+// are ignored. Objects formed by the gate are identified when they're stored
+// like storage nodes do, objects split by the slicer are assembled when the
+// linking object is stored, so parents can be read as a whole.
+type Memory struct {
+	mu         sync.RWMutex
+	ni         netmap.NetworkInfo
+	containers map[cid.ID]container.Container
+	objects    map[oid.Address]memoryObject
+	removed    map[oid.Address]struct{}
+	balances   map[string]accounting.Decimal
+}
+
+type memoryObject struct {
+	obj object.Object
+	// phy is unset for parents of split objects.
+	phy bool
+}
+
+// NewMemory creates an empty in-memory backend.
+func NewMemory() *Memory {
+	m := &Memory{
+		containers: make(map[cid.ID]container.Container),
+		objects:    make(map[oid.Address]memoryObject),
+		removed:    make(map[oid.Address]struct{}),
+		balances:   make(map[string]accounting.Decimal),
+	}
+	m.ni.SetMaxObjectSize(defaultMemoryMaxObjectSize)
+	return m
+}
+
+// SetNetworkInfo sets the network info returned by the backend.
+func (m *Memory) SetNetworkInfo(ni netmap.NetworkInfo) {
+	m.mu.Lock()
+	m.ni = ni
+	m.mu.Unlock()
+}
+
+// SetBalance sets the balance of the account.
+func (m *Memory) SetBalance(account user.ID, balance accounting.Decimal) {
+	m.mu.Lock()
+	m.balances[account.EncodeToString()] = balance
+	m.mu.Unlock()
+}
+
+// AddContainer stores the container and returns its ID.
+func (m *Memory) AddContainer(cnr container.Container) cid.ID {
+	var id cid.ID
+	cnr.CalculateID(&id)
+
+	m.mu.Lock()
+	m.containers[id] = cnr
+	m.mu.Unlock()
+	return id
+}
+
+// Object returns the stored object with the payload.
+func (m *Memory) Object(addr oid.Address) (object.Object, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var res object.Object
+	o, ok := m.objects[addr]
+	if ok {
+		o.obj.CopyTo(&res)
+	}
+	return res, ok
+}
+
+// ContainerGet implements Backend.
+func (m *Memory) ContainerGet(_ context.Context, id cid.ID, _ client.PrmContainerGet) (container.Container, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cnr, ok := m.containers[id]
+	if !ok {
+		return container.Container{}, apistatus.ErrContainerNotFound
+	}
+	return cnr, nil
+}
+
+// NetworkInfo implements Backend.
+func (m *Memory) NetworkInfo(context.Context, client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ni, nil
+}
+
+// BalanceGet implements Backend. Accounts without balance set have zero
+// balance like in NeoFS.
+func (m *Memory) BalanceGet(_ context.Context, account user.ID, _ client.PrmBalanceGet) (accounting.Decimal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.balances[account.EncodeToString()], nil
+}
+
+// ObjectPutInit implements Backend.
+func (m *Memory) ObjectPutInit(_ context.Context, hdr object.Object, _ user.Signer, _ client.PrmObjectPutInit) (ObjectWriter, error) {
+	w := &memoryWriter{m: m}
+	hdr.CopyTo(&w.hdr)
+	return w, nil
+}
+
+// ObjectGetInit implements Backend.
+func (m *Memory) ObjectGetInit(_ context.Context, containerID cid.ID, objectID oid.ID, _ user.Signer, _ client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	obj, err := m.get(containerID, objectID)
+	if err != nil {
+		return object.Object{}, nil, err
+	}
+
+	payload := obj.Payload()
+	obj.SetPayload(nil)
+	return obj, io.NopCloser(bytes.NewReader(payload)), nil
+}
+
+// ObjectHead implements Backend.
+func (m *Memory) ObjectHead(_ context.Context, containerID cid.ID, objectID oid.ID, _ user.Signer, _ client.PrmObjectHead) (*object.Object, error) {
+	obj, err := m.get(containerID, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.SetPayload(nil)
+	return &obj, nil
+}
+
+// ObjectRangeInit implements Backend.
+func (m *Memory) ObjectRangeInit(_ context.Context, containerID cid.ID, objectID oid.ID, offset, length uint64, _ user.Signer, _ client.PrmObjectRange) (io.ReadCloser, error) {
+	obj, err := m.get(containerID, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := obj.Payload()
+	if length == 0 || offset+length < offset || offset+length > uint64(len(payload)) {
+		return nil, apistatus.ErrObjectOutOfRange
+	}
+	return io.NopCloser(bytes.NewReader(payload[offset : offset+length])), nil
+}
+
+// ObjectHash implements Backend.
+func (m *Memory) ObjectHash(_ context.Context, containerID cid.ID, objectID oid.ID, _ user.Signer, typ checksum.Type, ranges []uint64, _ client.PrmObjectHash) ([][]byte, error) {
+	if len(ranges) == 0 || len(ranges)%2 != 0 {
+		return nil, client.ErrMissingRanges
+	}
+	obj, err := m.get(containerID, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := obj.Payload()
+	res := make([][]byte, 0, len(ranges)/2)
+	for i := 0; i < len(ranges); i += 2 {
+		offset, length := ranges[i], ranges[i+1]
+		if length == 0 || offset+length < offset || offset+length > uint64(len(payload)) {
+			return nil, apistatus.ErrObjectOutOfRange
+		}
+		data := payload[offset : offset+length]
+		switch typ {
+		case checksum.TZ:
+			h := tz.Sum(data)
+			res = append(res, h[:])
+		default:
+			h := sha256.Sum256(data)
+			res = append(res, h[:])
+		}
+	}
+	return res, nil
+}
+
+// ObjectDelete implements Backend. The returned tombstone isn't stored.
+func (m *Memory) ObjectDelete(_ context.Context, containerID cid.ID, objectID oid.ID, _ user.Signer, _ client.PrmObjectDelete) (oid.ID, error) {
+	addr := newAddress(containerID, objectID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.removed[addr]; ok {
+		return oid.ID{}, apistatus.ErrObjectAlreadyRemoved
+	}
+	if _, ok := m.objects[addr]; !ok {
+		return oid.ID{}, apistatus.ErrObjectNotFound
+	}
+	delete(m.objects, addr)
+	m.removed[addr] = struct{}{}
+
+	var tomb oid.ID
+	tomb.SetSHA256(sha256.Sum256([]byte("tombstone:" + addr.EncodeToString())))
+	return tomb, nil
+}
+
+// ObjectSearchInit implements Backend. Object IDs are listed in the order of
+// their string representation.
+func (m *Memory) ObjectSearchInit(_ context.Context, containerID cid.ID, _ user.Signer, filters object.SearchFilters, _ client.PrmObjectSearch) (ObjectLister, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.containers[containerID]; !ok {
+		return nil, apistatus.ErrContainerNotFound
+	}
+
+	var ids []oid.ID
+	for addr, o := range m.objects {
+		if addr.Container() == containerID && o.matches(filters) {
+			ids = append(ids, addr.Object())
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].EncodeToString() < ids[j].EncodeToString()
+	})
+	return &memoryLister{ids: ids}, nil
+}
+
+// get returns the copy of the stored object.
+func (m *Memory) get(containerID cid.ID, objectID oid.ID) (object.Object, error) {
+	addr := newAddress(containerID, objectID)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.removed[addr]; ok {
+		return object.Object{}, apistatus.ErrObjectAlreadyRemoved
+	}
+	o, ok := m.objects[addr]
+	if !ok {
+		return object.Object{}, apistatus.ErrObjectNotFound
+	}
+
+	var res object.Object
+	o.obj.CopyTo(&res)
+	return res, nil
+}
+
+func (m *Memory) put(obj object.Object) (oid.ID, error) {
+	cnrID, ok := obj.ContainerID()
+	if !ok {
+		return oid.ID{}, fmt.Errorf("missing container ID")
+	}
+
+	id, ok := obj.ID()
+	if !ok {
+		// header formed by the gate is completed by the storage node
+		obj.SetPayloadSize(uint64(len(obj.Payload())))
+		obj.CalculateAndSetPayloadChecksum()
+		if err := obj.CalculateAndSetID(); err != nil {
+			return oid.ID{}, fmt.Errorf("calculate ID: %w", err)
+		}
+		id, _ = obj.ID()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok = m.containers[cnrID]; !ok {
+		return oid.ID{}, apistatus.ErrContainerNotFound
+	}
+	m.objects[newAddress(cnrID, id)] = memoryObject{obj: obj, phy: true}
+
+	if children := obj.Children(); len(children) > 0 {
+		if err := m.assemble(cnrID, obj.Parent(), children); err != nil {
+			return oid.ID{}, err
+		}
+	}
+	return id, nil
+}
+
+// assemble stores the parent object of the split one with the payload of all
+// its children.
+func (m *Memory) assemble(cnrID cid.ID, parent *object.Object, children []oid.ID) error {
+	if parent == nil {
+		return fmt.Errorf("linking object without parent header")
+	}
+	parID, ok := parent.ID()
+	if !ok {
+		return fmt.Errorf("linking object without parent ID")
+	}
+
+	var payload []byte
+	for _, child := range children {
+		o, ok := m.objects[newAddress(cnrID, child)]
+		if !ok {
+			return fmt.Errorf("missing child object %s", child)
+		}
+		payload = append(payload, o.obj.Payload()...)
+	}
+
+	parent.SetPayload(payload)
+	m.objects[newAddress(cnrID, parID)] = memoryObject{obj: *parent}
+	return nil
+}
+
+// matches checks whether the object matches all the filters.
+func (o memoryObject) matches(filters object.SearchFilters) bool {
+	for i := range filters {
+		f := &filters[i]
+
+		switch f.Header() {
+		case object.FilterRoot:
+			if o.obj.HasParent() {
+				return false
+			}
+			continue
+		case object.FilterPhysical:
+			if !o.phy {
+				return false
+			}
+			continue
+		}
+
+		val, ok := o.header(f.Header())
+		switch f.Operation() {
+		case object.MatchStringEqual:
+			ok = ok && val == f.Value()
+		case object.MatchStringNotEqual:
+			ok = ok && val != f.Value()
+		case object.MatchNotPresent:
+			ok = !ok
+		case object.MatchCommonPrefix:
+			ok = ok && strings.HasPrefix(val, f.Value())
+		default:
+			ok = false
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// header returns the value of the object header searched by the key.
+func (o memoryObject) header(key string) (string, bool) {
+	obj := &o.obj
+	switch key {
+	case object.FilterID:
+		id, ok := obj.ID()
+		return id.EncodeToString(), ok
+	case object.FilterContainerID:
+		id, ok := obj.ContainerID()
+		return id.EncodeToString(), ok
+	case object.FilterOwnerID:
+		owner := obj.OwnerID()
+		if owner == nil {
+			return "", false
+		}
+		return owner.EncodeToString(), true
+	case object.FilterType:
+		return obj.Type().EncodeToString(), true
+	case object.FilterPayloadSize:
+		return strconv.FormatUint(obj.PayloadSize(), 10), true
+	case object.FilterCreationEpoch:
+		return strconv.FormatUint(obj.CreationEpoch(), 10), true
+	case object.FilterParentID:
+		id, ok := obj.ParentID()
+		return id.EncodeToString(), ok
+	case object.FilterPayloadChecksum:
+		cs, ok := obj.PayloadChecksum()
+		return hex.EncodeToString(cs.Value()), ok
+	}
+
+	for _, attr := range obj.Attributes() {
+		if attr.Key() == key {
+			return attr.Value(), true
+		}
+	}
+	return "", false
+}
+
+type memoryWriter struct {
+	m       *Memory
+	hdr     object.Object
+	payload []byte
+	id      oid.ID
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	w.payload = append(w.payload, p...)
+	return len(p), nil
+}
+
+func (w *memoryWriter) Close() error {
+	w.hdr.SetPayload(w.payload)
+	id, err := w.m.put(w.hdr)
+	if err != nil {
+		return err
+	}
+	w.id = id
+	return nil
+}
+
+func (w *memoryWriter) StoredObjectID() oid.ID {
+	return w.id
+}
+
+type memoryLister struct {
+	ids []oid.ID
+}
+
+func (l *memoryLister) Read(buf []oid.ID) (int, error) {
+	if len(l.ids) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(buf, l.ids)
+	l.ids = l.ids[n:]
+	return n, nil
+}
+
+func (l *memoryLister) Iterate(f func(oid.ID) bool) error {
+	for len(l.ids) > 0 {
+		id := l.ids[0]
+		l.ids = l.ids[1:]
+		if f(id) {
+			break
+		}
+	}
+	return nil
+}
+
+func (l *memoryLister) Close() error {
+	return nil
+}
+
+func newAddress(cnrID cid.ID, objID oid.ID) oid.Address {
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(objID)
+	return addr
+}
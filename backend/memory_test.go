@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func putObject(t *testing.T, b Backend, hdr object.Object, payload []byte) oid.ID {
+	w, err := b.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func search(t *testing.T, b Backend, cnrID cid.ID, filters object.SearchFilters) []oid.ID {
+	res, err := b.ObjectSearchInit(context.Background(), cnrID, nil, filters, client.PrmObjectSearch{})
+	require.NoError(t, err)
+	defer res.Close()
+
+	var ids []oid.ID
+	require.NoError(t, res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	}))
+	return ids
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	cnrID := m.AddContainer(containertest.Container(t))
+
+	_, err := m.ContainerGet(ctx, cidtest.ID(), client.PrmContainerGet{})
+	require.ErrorIs(t, err, apistatus.ErrContainerNotFound)
+
+	newHeader := func(name string) object.Object {
+		var attr object.Attribute
+		attr.SetKey(object.AttributeFileName)
+		attr.SetValue(name)
+
+		var hdr object.Object
+		hdr.SetContainerID(cnrID)
+		hdr.SetAttributes(attr)
+		return hdr
+	}
+
+	cat := putObject(t, m, newHeader("cat.jpg"), []byte("meow"))
+	dog := putObject(t, m, newHeader("dog.jpg"), []byte("woof"))
+	require.NotEqual(t, cat, dog)
+
+	t.Run("get", func(t *testing.T) {
+		hdr, r, err := m.ObjectGetInit(ctx, cnrID, cat, nil, client.PrmObjectGet{})
+		require.NoError(t, err)
+		payload, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, []byte("meow"), payload)
+		require.EqualValues(t, 4, hdr.PayloadSize())
+		id, _ := hdr.ID()
+		require.Equal(t, cat, id)
+
+		_, _, err = m.ObjectGetInit(ctx, cnrID, oid.ID{}, nil, client.PrmObjectGet{})
+		require.ErrorIs(t, err, apistatus.ErrObjectNotFound)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		r, err := m.ObjectRangeInit(ctx, cnrID, dog, 1, 2, nil, client.PrmObjectRange{})
+		require.NoError(t, err)
+		payload, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, []byte("oo"), payload)
+
+		_, err = m.ObjectRangeInit(ctx, cnrID, dog, 3, 2, nil, client.PrmObjectRange{})
+		require.ErrorIs(t, err, apistatus.ErrObjectOutOfRange)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		filters := object.NewSearchFilters()
+		filters.AddRootFilter()
+		filters.AddFilter(object.AttributeFileName, "dog.jpg", object.MatchStringEqual)
+		require.Equal(t, []oid.ID{dog}, search(t, m, cnrID, filters))
+
+		filters = object.NewSearchFilters()
+		filters.AddFilter(object.AttributeFileName, "ca", object.MatchCommonPrefix)
+		require.Equal(t, []oid.ID{cat}, search(t, m, cnrID, filters))
+
+		filters = object.NewSearchFilters()
+		filters.AddFilter(object.AttributeFilePath, "", object.MatchNotPresent)
+		require.Len(t, search(t, m, cnrID, filters), 2)
+
+		_, err := m.ObjectSearchInit(ctx, cidtest.ID(), nil, filters, client.PrmObjectSearch{})
+		require.ErrorIs(t, err, apistatus.ErrContainerNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := m.ObjectDelete(ctx, cnrID, dog, nil, client.PrmObjectDelete{})
+		require.NoError(t, err)
+
+		_, err = m.ObjectHead(ctx, cnrID, dog, nil, client.PrmObjectHead{})
+		require.ErrorIs(t, err, apistatus.ErrObjectAlreadyRemoved)
+		_, err = m.ObjectDelete(ctx, cnrID, dog, nil, client.PrmObjectDelete{})
+		require.ErrorIs(t, err, apistatus.ErrObjectAlreadyRemoved)
+	})
+
+	t.Run("unknown container", func(t *testing.T) {
+		var hdr object.Object
+		hdr.SetContainerID(cidtest.ID())
+
+		w, err := m.ObjectPutInit(ctx, hdr, nil, client.PrmObjectPutInit{})
+		require.NoError(t, err)
+		require.ErrorIs(t, w.Close(), apistatus.ErrContainerNotFound)
+	})
+}
+
+func TestMemorySlicer(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+
+	m := NewMemory()
+	cnrID := m.AddContainer(containertest.Container(t))
+
+	owner := signer.UserID()
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	hdr.SetOwnerID(&owner)
+
+	var opts slicer.Options
+	opts.SetObjectPayloadLimit(10)
+
+	payload := bytes.Repeat([]byte("0123456789"), 5)
+	id, err := slicer.Put(context.Background(), ObjectWriterFor(m), hdr, signer, bytes.NewReader(payload), opts)
+	require.NoError(t, err)
+
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(id)
+	parent, ok := m.Object(addr)
+	require.True(t, ok)
+	require.Equal(t, payload, parent.Payload())
+
+	// parent is the only root object, it's not physically stored
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	require.Equal(t, []oid.ID{id}, search(t, m, cnrID, filters))
+
+	filters = object.NewSearchFilters()
+	filters.AddPhyFilter()
+	require.Len(t, search(t, m, cnrID, filters), 6)
+}
@@ -0,0 +1,300 @@
+// Package bench generates synthetic load against a running gateway and
+// measures its latency and throughput.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxKnownObjects limits the number of uploaded objects reads are done from.
+const maxKnownObjects = 1024
+
+// Config is a load configuration.
+type Config struct {
+	// Endpoint is a base URL of the gateway, e.g. http://localhost:8082.
+	Endpoint string
+	// ContainerID is a container objects are uploaded to.
+	ContainerID string
+	// Sizes are payload sizes of uploaded objects, they're used in turn.
+	Sizes []int64
+	// Concurrency is a number of concurrent workers.
+	Concurrency int
+	// Duration limits the load time, zero means no limit.
+	Duration time.Duration
+	// Requests limits the total number of requests, zero means no limit.
+	Requests int64
+	// ReadRatio is a share of reads in [0, 1], others are uploads. Objects
+	// uploaded during the load are read, so the first requests are always
+	// uploads.
+	ReadRatio float64
+	// Timeout is a timeout of a single request.
+	Timeout time.Duration
+}
+
+// Stats are statistics of a single operation type.
+type Stats struct {
+	Requests int
+	Errors   int
+	Bytes    int64
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+}
+
+// Report is a load result.
+type Report struct {
+	Elapsed time.Duration
+	Write   Stats
+	Read    Stats
+}
+
+// RPS returns the number of successful requests per second.
+func (s Stats) RPS(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Requests-s.Errors) / elapsed.Seconds()
+}
+
+// Throughput returns the number of payload bytes transferred per second.
+func (s Stats) Throughput(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / elapsed.Seconds()
+}
+
+type result struct {
+	latency time.Duration
+	bytes   int64
+	err     error
+}
+
+type runner struct {
+	cfg     Config
+	client  *http.Client
+	payload []byte
+	counter atomic.Int64
+
+	mu      sync.Mutex
+	objects []string
+	writes  []result
+	reads   []result
+}
+
+// Run generates the load until the context is done or limits are reached.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.Endpoint == "" || cfg.ContainerID == "" {
+		return nil, errors.New("endpoint and container ID are required")
+	}
+	if len(cfg.Sizes) == 0 {
+		return nil, errors.New("no object sizes")
+	}
+	if cfg.Duration <= 0 && cfg.Requests <= 0 {
+		return nil, errors.New("either duration or number of requests must be limited")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	var maxSize int64
+	for _, size := range cfg.Sizes {
+		if size < 0 {
+			return nil, fmt.Errorf("invalid object size %d", size)
+		}
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+
+	r := &runner{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		payload: make([]byte, maxSize),
+	}
+	if _, err := rand.Read(r.payload); err != nil {
+		return nil, fmt.Errorf("generate payload: %w", err)
+	}
+
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return &Report{
+		Elapsed: time.Since(start),
+		Write:   newStats(r.writes),
+		Read:    newStats(r.reads),
+	}, nil
+}
+
+func (r *runner) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		n := r.counter.Add(1)
+		if r.cfg.Requests > 0 && n > r.cfg.Requests {
+			return
+		}
+
+		if addr := r.readTarget(); addr != "" {
+			res := r.get(ctx, addr)
+			if ctx.Err() != nil {
+				return // interrupted requests aren't counted
+			}
+			r.mu.Lock()
+			r.reads = append(r.reads, res)
+			r.mu.Unlock()
+			continue
+		}
+
+		size := r.cfg.Sizes[int(n-1)%len(r.cfg.Sizes)]
+		addr, res := r.upload(ctx, n, size)
+		if ctx.Err() != nil {
+			return
+		}
+		r.mu.Lock()
+		r.writes = append(r.writes, res)
+		if res.err == nil && len(r.objects) < maxKnownObjects {
+			r.objects = append(r.objects, addr)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// readTarget returns the object to read if the next request is a read.
+func (r *runner) readTarget() string {
+	if r.cfg.ReadRatio <= 0 || mrand.Float64() >= r.cfg.ReadRatio {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.objects) == 0 {
+		return ""
+	}
+	return r.objects[mrand.Intn(len(r.objects))]
+}
+
+func (r *runner) upload(ctx context.Context, n int64, size int64) (string, result) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "bench-"+strconv.FormatInt(n, 10))
+	if err == nil {
+		_, err = fw.Write(r.payload[:size])
+	}
+	if err == nil {
+		err = mw.Close()
+	}
+	if err != nil {
+		return "", result{err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.Endpoint+"/upload/"+r.cfg.ContainerID, &body)
+	if err != nil {
+		return "", result{err: err}
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", result{latency: time.Since(start), err: err}
+	}
+	defer resp.Body.Close()
+
+	var put struct {
+		ObjectID    string `json:"object_id"`
+		ContainerID string `json:"container_id"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(&put)
+	}
+	res := result{latency: time.Since(start), err: err}
+	if err != nil {
+		return "", res
+	}
+	res.bytes = size
+	return put.ContainerID + "/" + put.ObjectID, res
+}
+
+func (r *runner) get(ctx context.Context, addr string) result {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.Endpoint+"/get/"+addr, nil)
+	if err != nil {
+		return result{err: err}
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return result{latency: time.Since(start), err: err}
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return result{latency: time.Since(start), bytes: n, err: err}
+}
+
+// newStats calculates statistics of the results, latency percentiles are
+// calculated over successful requests only.
+func newStats(results []result) Stats {
+	s := Stats{Requests: len(results)}
+
+	latencies := make([]time.Duration, 0, len(results))
+	for _, res := range results {
+		if res.err != nil {
+			s.Errors++
+			continue
+		}
+		s.Bytes += res.bytes
+		latencies = append(latencies, res.latency)
+	}
+	if len(latencies) == 0 {
+		return s
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	s.P50 = percentile(latencies, 50)
+	s.P90 = percentile(latencies, 90)
+	s.P99 = percentile(latencies, 99)
+	s.Max = latencies[len(latencies)-1]
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,105 @@
+package bench
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGate stores uploaded files in memory.
+type fakeGate struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (g *fakeGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/"):
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(f)
+
+		g.mu.Lock()
+		id := "obj" + strconv.Itoa(len(g.objects))
+		g.objects[id] = data
+		g.mu.Unlock()
+
+		_, _ = io.WriteString(w, `{"object_id":"`+id+`","container_id":"`+strings.TrimPrefix(r.URL.Path, "/upload/")+`"}`)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/get/cnr/"):
+		g.mu.Lock()
+		data, ok := g.objects[strings.TrimPrefix(r.URL.Path, "/get/cnr/")]
+		g.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv := httptest.NewServer(&fakeGate{objects: make(map[string][]byte)})
+	defer srv.Close()
+
+	t.Run("requests", func(t *testing.T) {
+		report, err := Run(context.Background(), Config{
+			Endpoint:    srv.URL,
+			ContainerID: "cnr",
+			Sizes:       []int64{10, 1000},
+			Concurrency: 4,
+			Requests:    100,
+			ReadRatio:   0.5,
+			Timeout:     time.Second,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 100, report.Write.Requests+report.Read.Requests)
+		require.NotZero(t, report.Write.Requests)
+		require.Zero(t, report.Write.Errors)
+		require.Zero(t, report.Read.Errors)
+		require.NotZero(t, report.Write.Bytes)
+		require.LessOrEqual(t, report.Write.P50, report.Write.P99)
+		require.LessOrEqual(t, report.Write.P99, report.Write.Max)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		report, err := Run(context.Background(), Config{
+			Endpoint:    srv.URL,
+			ContainerID: "unknown",
+			Sizes:       []int64{10},
+			Requests:    10,
+			ReadRatio:   1,
+		})
+		require.NoError(t, err)
+		// uploads succeed, but uploaded objects are not found
+		require.Equal(t, 10, report.Write.Requests+report.Read.Requests)
+		require.Equal(t, report.Read.Requests, report.Read.Errors)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := Run(context.Background(), Config{Endpoint: srv.URL, ContainerID: "cnr", Sizes: []int64{10}})
+		require.Error(t, err)
+	})
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i))
+	}
+	require.EqualValues(t, 50, percentile(latencies, 50))
+	require.EqualValues(t, 99, percentile(latencies, 99))
+	require.EqualValues(t, 1, percentile(latencies[:1], 99))
+}
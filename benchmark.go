@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/bench"
+	"github.com/spf13/pflag"
+)
+
+// cmdBench is a subcommand generating load against a running gateway.
+const cmdBench = "bench"
+
+// runBench parses bench subcommand arguments, generates the load and prints
+// the report.
+func runBench(ctx context.Context, args []string) error {
+	flags := pflag.NewFlagSet(cmdBench, pflag.ContinueOnError)
+	flags.SetOutput(os.Stdout)
+	flags.SortFlags = false
+	flags.Usage = func() {
+		fmt.Printf("Usage: neofs-http-gw %s --container <cid> [flags]\n", cmdBench)
+		flags.PrintDefaults()
+	}
+
+	var cfg bench.Config
+	flags.StringVar(&cfg.Endpoint, "endpoint", "http://localhost:8082", "gateway base URL")
+	flags.StringVar(&cfg.ContainerID, "container", "", "container to upload objects to")
+	flags.Int64SliceVar(&cfg.Sizes, "sizes", []int64{1 << 10, 1 << 20}, "payload sizes of uploaded objects in bytes")
+	flags.IntVar(&cfg.Concurrency, "concurrency", 8, "number of concurrent workers")
+	flags.DurationVar(&cfg.Duration, "duration", 30*time.Second, "load duration, 0 means no limit")
+	flags.Int64Var(&cfg.Requests, "requests", 0, "total number of requests, 0 means no limit")
+	flags.Float64Var(&cfg.ReadRatio, "read-ratio", 0.5, "share of reads of uploaded objects in [0, 1]")
+	flags.DurationVar(&cfg.Timeout, "timeout", time.Minute, "timeout of a single request")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if cfg.ReadRatio < 0 || cfg.ReadRatio > 1 {
+		return fmt.Errorf("invalid read ratio %v", cfg.ReadRatio)
+	}
+
+	report, err := bench.Run(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("elapsed: %s\n", report.Elapsed.Round(time.Millisecond))
+	printBenchStats("write", report.Write, report.Elapsed)
+	printBenchStats("read", report.Read, report.Elapsed)
+	return nil
+}
+
+func printBenchStats(name string, s bench.Stats, elapsed time.Duration) {
+	fmt.Printf("%s: %d requests, %d errors, %.1f req/s, %.2f MiB/s\n",
+		name, s.Requests, s.Errors, s.RPS(elapsed), s.Throughput(elapsed)/(1<<20))
+	if s.Requests == s.Errors {
+		return
+	}
+	fmt.Printf("  latency: p50 %s, p90 %s, p99 %s, max %s\n",
+		s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond),
+		s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+}
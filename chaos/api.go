@@ -0,0 +1,42 @@
+package chaos
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler returns the admin API handler managing the injected faults:
+// GET /faults returns them, PUT /faults replaces them with the ones from the
+// JSON request body and DELETE /faults stops the injection.
+func (i *Injector) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/faults", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var f Faults
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&f); err != nil {
+				http.Error(w, "invalid faults: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := i.SetFaults(f); err != nil {
+				http.Error(w, "invalid faults: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			i.faults.Store(new(Faults))
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(i.Faults())
+	})
+	return mux
+}
@@ -0,0 +1,258 @@
+// Package chaos injects faults into gateway responses, so operators can check
+// how clients handle delays, errors and broken streams.
+package chaos
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Kinds of injected faults.
+const (
+	FaultDelay    = "delay"
+	FaultError    = "error"
+	FaultTruncate = "truncate"
+)
+
+// maxTruncateOffset limits the amount of data sent before the stream of
+// unknown length is broken.
+const maxTruncateOffset = 1 << 20
+
+var errTruncated = errors.New("stream truncated")
+
+// Duration is a time.Duration encoded in JSON as a string like "1.5s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Faults describes faults injected into requests, every fault kind affects
+// the given percentage of requests independently of the others.
+type Faults struct {
+	// DelayPercent of requests are delayed for a random time up to MaxDelay.
+	DelayPercent float64  `json:"delay_percent"`
+	MaxDelay     Duration `json:"max_delay"`
+	// ErrorPercent of requests fail with one of ErrorCodes chosen randomly
+	// without being handled, 503 is used if no codes are set.
+	ErrorPercent float64 `json:"error_percent"`
+	ErrorCodes   []int   `json:"error_codes,omitempty"`
+	// TruncatePercent of responses are broken at a random offset, the
+	// connection is closed after that.
+	TruncatePercent float64 `json:"truncate_percent"`
+}
+
+// Validate checks that percentages and status codes are valid.
+func (f Faults) Validate() error {
+	for name, p := range map[string]float64{
+		"delay":    f.DelayPercent,
+		"error":    f.ErrorPercent,
+		"truncate": f.TruncatePercent,
+	} {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("%s percentage %v is out of [0, 100]", name, p)
+		}
+	}
+	if f.MaxDelay < 0 {
+		return fmt.Errorf("negative max delay %s", time.Duration(f.MaxDelay))
+	}
+	for _, code := range f.ErrorCodes {
+		if code < 400 || code > 599 {
+			return fmt.Errorf("invalid error code %d", code)
+		}
+	}
+	return nil
+}
+
+// Injector injects the configured faults into requests while it's enabled,
+// nil Injector injects nothing.
+type Injector struct {
+	log     *zap.Logger
+	enabled atomic.Bool
+	faults  atomic.Pointer[Faults]
+}
+
+// NewInjector creates disabled fault injector.
+func NewInjector(log *zap.Logger) *Injector {
+	i := &Injector{log: log}
+	i.faults.Store(new(Faults))
+	return i
+}
+
+// SetEnabled enables or disables fault injection. Faults are reset when the
+// injector is disabled.
+func (i *Injector) SetEnabled(enabled bool) {
+	if !enabled {
+		i.faults.Store(new(Faults))
+	}
+	i.enabled.Store(enabled)
+}
+
+// Enabled returns whether faults are injected.
+func (i *Injector) Enabled() bool {
+	return i != nil && i.enabled.Load()
+}
+
+// Faults returns the injected faults.
+func (i *Injector) Faults() Faults {
+	return *i.faults.Load()
+}
+
+// SetFaults sets the injected faults.
+func (i *Injector) SetFaults(f Faults) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	i.faults.Store(&f)
+	return nil
+}
+
+// Wrap returns the handler injecting faults into requests handled by h.
+func (i *Injector) Wrap(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(c *fasthttp.RequestCtx) {
+		if !i.Enabled() {
+			h(c)
+			return
+		}
+
+		f := i.faults.Load()
+		if hit(f.DelayPercent) && f.MaxDelay > 0 {
+			delay := time.Duration(rand.Int63n(int64(f.MaxDelay)))
+			i.injected(c, FaultDelay, zap.Duration("delay", delay))
+			time.Sleep(delay)
+		}
+
+		if hit(f.ErrorPercent) {
+			code := fasthttp.StatusServiceUnavailable
+			if len(f.ErrorCodes) > 0 {
+				code = f.ErrorCodes[rand.Intn(len(f.ErrorCodes))]
+			}
+			i.injected(c, FaultError, zap.Int("code", code))
+			if c.Request.Header.ContentLength() != 0 {
+				// the request body isn't read
+				c.SetConnectionClose()
+			}
+			response.Error(c, "injected fault", code)
+			return
+		}
+
+		h(c)
+
+		if hit(f.TruncatePercent) && !c.IsHead() {
+			i.truncate(c)
+		}
+	}
+}
+
+func (i *Injector) injected(c *fasthttp.RequestCtx, kind string, fields ...zap.Field) {
+	i.log.Debug("fault injected", append(fields, zap.String("fault", kind), zap.Uint64("id", c.ID()))...)
+}
+
+// truncate makes the server send the response partially and close the
+// connection.
+func (i *Injector) truncate(c *fasthttp.RequestCtx) {
+	size := c.Response.Header.ContentLength()
+	if !c.Response.IsBodyStream() {
+		// Content-Length of the body set at once is set on write
+		size = len(c.Response.Body())
+		c.Response.Header.SetContentLength(size)
+	}
+
+	var offset int64
+	switch {
+	case size > 0:
+		offset = rand.Int63n(int64(size))
+	case size < 0:
+		offset = rand.Int63n(maxTruncateOffset)
+	default:
+		return
+	}
+
+	i.injected(c, FaultTruncate, zap.Int64("offset", offset))
+	c.SetConnectionClose()
+	c.HijackSetNoResponse(true)
+	c.Hijack(func(conn net.Conn) {
+		bw := bufio.NewWriter(conn)
+		if _, err := c.Response.Header.WriteTo(bw); err != nil {
+			return
+		}
+		var w io.Writer = &limitedWriter{w: bw, left: offset}
+		if size < 0 {
+			w = chunkedWriter{w}
+		}
+		_ = c.Response.BodyWriteTo(w)
+		_ = bw.Flush()
+	})
+}
+
+// hit randomly decides whether the fault affecting the given percentage of
+// requests is injected.
+func hit(percent float64) bool {
+	return percent > 0 && rand.Float64()*100 < percent
+}
+
+// limitedWriter fails after the limit is written.
+type limitedWriter struct {
+	w    io.Writer
+	left int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) <= w.left {
+		n, err := w.w.Write(p)
+		w.left -= int64(n)
+		return n, err
+	}
+	n, err := w.w.Write(p[:w.left])
+	w.left -= int64(n)
+	if err == nil {
+		err = errTruncated
+	}
+	return n, err
+}
+
+// chunkedWriter writes data in chunked transfer encoding, the final chunk
+// isn't written, so the body stays incomplete.
+type chunkedWriter struct {
+	w io.Writer
+}
+
+func (w chunkedWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if _, err := io.WriteString(w.w, strconv.FormatInt(int64(len(p)), 16)+"\r\n"); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	_, err = io.WriteString(w.w, "\r\n")
+	return n, err
+}
@@ -0,0 +1,157 @@
+package chaos
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap"
+)
+
+func TestFaultsValidate(t *testing.T) {
+	require.NoError(t, Faults{}.Validate())
+	require.NoError(t, Faults{ErrorPercent: 100, ErrorCodes: []int{500, 429}}.Validate())
+	require.Error(t, Faults{DelayPercent: 101}.Validate())
+	require.Error(t, Faults{TruncatePercent: -1}.Validate())
+	require.Error(t, Faults{MaxDelay: -1}.Validate())
+	require.Error(t, Faults{ErrorCodes: []int{200}}.Validate())
+}
+
+func TestInjector(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 100)
+	handled := 0
+	h := func(c *fasthttp.RequestCtx) {
+		handled++
+		c.SetBody(body)
+	}
+
+	i := NewInjector(zap.NewNop())
+	require.NoError(t, i.SetFaults(Faults{ErrorPercent: 100, ErrorCodes: []int{429}}))
+
+	t.Run("disabled", func(t *testing.T) {
+		var c fasthttp.RequestCtx
+		i.Wrap(h)(&c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, 1, handled)
+	})
+
+	i.SetEnabled(true)
+	require.NoError(t, i.SetFaults(Faults{ErrorPercent: 100, ErrorCodes: []int{429}}))
+
+	t.Run("error", func(t *testing.T) {
+		var c fasthttp.RequestCtx
+		i.Wrap(h)(&c)
+		require.Equal(t, fasthttp.StatusTooManyRequests, c.Response.StatusCode())
+		require.Equal(t, 1, handled)
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		require.NoError(t, i.SetFaults(Faults{DelayPercent: 100, MaxDelay: Duration(50 * time.Millisecond)}))
+
+		var c fasthttp.RequestCtx
+		i.Wrap(h)(&c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, 2, handled)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		require.NoError(t, i.SetFaults(Faults{TruncatePercent: 100}))
+
+		ln := fasthttputil.NewInmemoryListener()
+		srv := &fasthttp.Server{Handler: i.Wrap(h)}
+		go func() { _ = srv.Serve(ln) }()
+		defer func() { _ = srv.Shutdown() }()
+
+		conn, err := ln.Dial()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: gate\r\n\r\n")
+		require.NoError(t, err)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		// connection is closed after the partial response
+		resp, err := io.ReadAll(conn)
+		require.NoError(t, err)
+
+		head, payload, ok := strings.Cut(string(resp), "\r\n\r\n")
+		require.True(t, ok)
+		require.Contains(t, head, "Content-Length: 1000")
+		require.Less(t, len(payload), len(body))
+		require.Equal(t, string(body[:len(payload)]), payload)
+	})
+
+	t.Run("truncate stream", func(t *testing.T) {
+		stream := bytes.Repeat(body, 2*maxTruncateOffset/len(body))
+
+		ln := fasthttputil.NewInmemoryListener()
+		srv := &fasthttp.Server{Handler: i.Wrap(func(c *fasthttp.RequestCtx) {
+			c.SetBodyStream(bytes.NewReader(stream), -1)
+		})}
+		go func() { _ = srv.Serve(ln) }()
+		defer func() { _ = srv.Shutdown() }()
+
+		conn, err := ln.Dial()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: gate\r\n\r\n")
+		require.NoError(t, err)
+		resp, err := io.ReadAll(conn)
+		require.NoError(t, err)
+
+		head, payload, ok := strings.Cut(string(resp), "\r\n\r\n")
+		require.True(t, ok)
+		require.Contains(t, head, "Transfer-Encoding: chunked")
+		require.Less(t, len(payload), len(stream))
+		require.False(t, strings.HasSuffix(payload, "0\r\n\r\n"))
+	})
+
+	t.Run("reset on disable", func(t *testing.T) {
+		i.SetEnabled(false)
+		require.Equal(t, Faults{}, i.Faults())
+	})
+}
+
+func TestHandler(t *testing.T) {
+	i := NewInjector(zap.NewNop())
+	srv := httptest.NewServer(i.Handler())
+	defer srv.Close()
+
+	do := func(method, body string) (int, string) {
+		req, err := http.NewRequest(method, srv.URL+"/faults", strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	code, _ := do(http.MethodPut, `{"delay_percent": 10, "max_delay": "1.5s", "error_codes": [502]}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, Faults{DelayPercent: 10, MaxDelay: Duration(1500 * time.Millisecond), ErrorCodes: []int{502}}, i.Faults())
+
+	code, body := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, `"max_delay": "1.5s"`)
+
+	code, _ = do(http.MethodPut, `{"error_percent": 200}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPut, `{"unknown": 1}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = do(http.MethodDelete, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, Faults{}, i.Faults())
+
+	code, _ = do(http.MethodPost, "")
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -0,0 +1,252 @@
+package clientip
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	hdrForwarded     = "Forwarded"
+	hdrXForwardedFor = "X-Forwarded-For"
+	hdrXRealIP       = "X-Real-IP"
+
+	// DefaultHeader is the forwarding header used if none is specified.
+	DefaultHeader = hdrXForwardedFor
+
+	clientIPKey = "__context_client_ip_key"
+)
+
+// Extractor determines the real client address of the request. The single
+// forwarding header set by the trusted proxies (X-Forwarded-For by default) is
+// taken into account only when the request comes from one of them. Other
+// forwarding headers are ignored, since proxies pass them from clients as is.
+//
+// Trusted proxies can be updated in runtime, so Extractor is safe for
+// concurrent use.
+type Extractor struct {
+	mu      sync.RWMutex
+	trusted []netip.Prefix
+	header  string
+}
+
+// New creates an Extractor trusting the given list of CIDRs (single IP
+// addresses are accepted too) and reading the client address from the given
+// header. Empty header means DefaultHeader.
+func New(cidrs []string, header string) (*Extractor, error) {
+	var e Extractor
+	if err := e.Update(cidrs, header); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Update replaces the list of trusted proxies and the header they set.
+func (e *Extractor) Update(cidrs []string, header string) error {
+	trusted, err := ParsePrefixes(cidrs)
+	if err != nil {
+		return fmt.Errorf("invalid trusted proxy: %w", err)
+	}
+	header = strings.TrimSpace(header)
+	if header == "" {
+		header = DefaultHeader
+	}
+
+	e.mu.Lock()
+	e.trusted = trusted
+	e.header = header
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Store calculates the client address of the request and stores it in the
+// request context, so it can be retrieved later using Load.
+func (e *Extractor) Store(ctx *fasthttp.RequestCtx) net.IP {
+	ip := e.ClientIP(ctx)
+	ctx.SetUserValue(clientIPKey, ip)
+	return ip
+}
+
+// Load returns client address stored in the context by Store. If there is no
+// stored address, the remote address of the connection is returned.
+func Load(ctx *fasthttp.RequestCtx) net.IP {
+	if ip, ok := ctx.UserValue(clientIPKey).(net.IP); ok && ip != nil {
+		return ip
+	}
+	return ctx.RemoteIP()
+}
+
+// ClientIP returns the client address of the request. If the connection peer
+// isn't a trusted proxy, its address is returned as is. Otherwise, hops of the
+// configured forwarding header are inspected from the closest one to the
+// farthest one and the first untrusted address is returned.
+func (e *Extractor) ClientIP(ctx *fasthttp.RequestCtx) net.IP {
+	remote := ctx.RemoteIP()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if !e.isTrusted(remote) {
+		return remote
+	}
+
+	val := peekHeader(&ctx.Request.Header, e.header)
+	hops := parseXForwardedFor(val)
+	if strings.EqualFold(e.header, hdrForwarded) {
+		hops = parseForwarded(val)
+	}
+	if ip := e.pickClient(hops); ip != nil {
+		return ip
+	}
+
+	return remote
+}
+
+// pickClient walks through the hops from right to left (the rightmost hop is
+// added by the closest proxy) and returns the first untrusted one. If all hops
+// are trusted, the leftmost one is returned.
+func (e *Extractor) pickClient(hops []string) net.IP {
+	var last net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseHop(hops[i])
+		if ip == nil {
+			// malformed chain can't be trusted beyond this point
+			break
+		}
+		if !e.isTrusted(ip) {
+			return ip
+		}
+		last = ip
+	}
+	return last
+}
+
+func (e *Extractor) isTrusted(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, p := range e.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePrefixes parses the list of CIDRs, single IP addresses are converted
+// to prefixes containing only them. Empty strings are skipped.
+func ParsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	res := make([]netip.Prefix, 0, len(cidrs))
+	for _, s := range cidrs {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("'%s': %w", s, err)
+			}
+			addr = addr.Unmap()
+			res = append(res, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %w", s, err)
+		}
+		if p.Addr().Is4In6() {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		res = append(res, p.Masked())
+	}
+	return res, nil
+}
+
+// peekHeader looks for the header ignoring its case, since header names
+// normalizing is disabled for the server. Values of repeated headers are
+// joined with comma.
+func peekHeader(h *fasthttp.RequestHeader, name string) string {
+	var res []string
+	h.VisitAll(func(key, value []byte) {
+		if bytes.EqualFold(key, []byte(name)) {
+			res = append(res, string(value))
+		}
+	})
+	return strings.Join(res, ",")
+}
+
+func parseXForwardedFor(val string) []string {
+	if val == "" {
+		return nil
+	}
+	parts := strings.Split(val, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// IsForwardingHeader checks whether the header is one of the well-known
+// forwarding headers.
+func IsForwardingHeader(name string) bool {
+	return strings.EqualFold(name, hdrForwarded) || strings.EqualFold(name, hdrXForwardedFor) ||
+		strings.EqualFold(name, hdrXRealIP)
+}
+
+// HeaderValue returns the value of the forwarding header passing the single
+// client address, Forwarded header gets RFC 7239 'for' parameter.
+func HeaderValue(header string, ip net.IP) string {
+	if !strings.EqualFold(header, hdrForwarded) {
+		return ip.String()
+	}
+	if ip.To4() == nil {
+		return `for="[` + ip.String() + `]"`
+	}
+	return "for=" + ip.String()
+}
+
+// parseForwarded extracts 'for' parameters from RFC 7239 Forwarded header.
+func parseForwarded(val string) []string {
+	if val == "" {
+		return nil
+	}
+
+	var res []string
+	for _, element := range strings.Split(val, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(k, "for") {
+				continue
+			}
+			res = append(res, strings.Trim(v, `"`))
+		}
+	}
+	return res
+}
+
+// parseHop parses a single hop address which can be a bare IP, IP with port,
+// or bracketed IPv6 with optional port.
+func parseHop(hop string) net.IP {
+	if hop == "" {
+		return nil
+	}
+	if ip := net.ParseIP(hop); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		return net.ParseIP(host)
+	}
+	return net.ParseIP(strings.Trim(hop, "[]"))
+}
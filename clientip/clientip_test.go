@@ -0,0 +1,148 @@
+package clientip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func newRequestCtx(remote string, headers map[string]string) *fasthttp.RequestCtx {
+	var req fasthttp.Request
+	req.Header.DisableNormalizing()
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Init(&req, &net.TCPAddr{IP: net.ParseIP(remote), Port: 1234}, nil)
+	return ctx
+}
+
+func TestClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		remote   string
+		header   string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "untrusted remote ignores headers",
+			remote:   "1.2.3.4",
+			headers:  map[string]string{hdrXForwardedFor: "5.6.7.8"},
+			expected: "1.2.3.4",
+		},
+		{
+			name:     "trusted remote without headers",
+			remote:   "10.0.0.1",
+			expected: "10.0.0.1",
+		},
+		{
+			name:     "x-forwarded-for chain",
+			remote:   "10.0.0.1",
+			headers:  map[string]string{hdrXForwardedFor: "6.6.6.6, 5.6.7.8, 10.1.1.1"},
+			expected: "5.6.7.8",
+		},
+		{
+			name:     "lower case header",
+			remote:   "192.168.1.1",
+			headers:  map[string]string{"x-forwarded-for": "5.6.7.8"},
+			expected: "5.6.7.8",
+		},
+		{
+			name:     "all hops trusted",
+			remote:   "10.0.0.1",
+			headers:  map[string]string{hdrXForwardedFor: "10.2.2.2, 10.1.1.1"},
+			expected: "10.2.2.2",
+		},
+		{
+			name:     "x-real-ip is ignored by default",
+			remote:   "10.0.0.1",
+			headers:  map[string]string{hdrXRealIP: "5.6.7.8"},
+			expected: "10.0.0.1",
+		},
+		{
+			name:     "x-real-ip",
+			remote:   "10.0.0.1",
+			header:   hdrXRealIP,
+			headers:  map[string]string{hdrXRealIP: "5.6.7.8", hdrXForwardedFor: "6.6.6.6"},
+			expected: "5.6.7.8",
+		},
+		{
+			name:   "forged forwarded is ignored",
+			remote: "10.0.0.1",
+			headers: map[string]string{
+				hdrForwarded:     "for=6.6.6.6",
+				hdrXForwardedFor: "5.6.7.8",
+			},
+			expected: "5.6.7.8",
+		},
+		{
+			name:   "forwarded",
+			remote: "10.0.0.1",
+			header: "forwarded",
+			headers: map[string]string{
+				hdrForwarded:     `for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711"`,
+				hdrXForwardedFor: "5.6.7.8",
+			},
+			expected: "2001:db8:cafe::17",
+		},
+		{
+			name:     "malformed header",
+			remote:   "10.0.0.1",
+			headers:  map[string]string{hdrXForwardedFor: "garbage"},
+			expected: "10.0.0.1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := New([]string{"10.0.0.0/8", "192.168.1.1"}, tc.header)
+			require.NoError(t, err)
+
+			ctx := newRequestCtx(tc.remote, tc.headers)
+			require.Equal(t, tc.expected, e.ClientIP(ctx).String())
+		})
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	e, err := New([]string{"10.0.0.0/8"}, "")
+	require.NoError(t, err)
+
+	ctx := newRequestCtx("10.0.0.1", map[string]string{hdrXForwardedFor: "5.6.7.8"})
+	require.Equal(t, "10.0.0.1", Load(ctx).String())
+
+	e.Store(ctx)
+	require.Equal(t, "5.6.7.8", Load(ctx).String())
+}
+
+func TestInvalidTrustedProxies(t *testing.T) {
+	_, err := New([]string{"10.0.0.0/33"}, "")
+	require.Error(t, err)
+
+	_, err = New([]string{"not an ip"}, "")
+	require.Error(t, err)
+}
+
+func TestHeaderValue(t *testing.T) {
+	for _, tc := range []struct {
+		header, ip, val string
+	}{
+		{header: hdrXForwardedFor, ip: "1.2.3.4", val: "1.2.3.4"},
+		{header: hdrXRealIP, ip: "2001:db8::1", val: "2001:db8::1"},
+		{header: "forwarded", ip: "1.2.3.4", val: "for=1.2.3.4"},
+		{header: hdrForwarded, ip: "2001:db8::1", val: `for="[2001:db8::1]"`},
+	} {
+		val := HeaderValue(tc.header, net.ParseIP(tc.ip))
+		require.Equal(t, tc.val, val)
+
+		// the value is read back by the gateway trusting the sender
+		e, err := New([]string{"10.0.0.1"}, tc.header)
+		require.NoError(t, err)
+		require.Equal(t, tc.ip, e.ClientIP(newRequestCtx("10.0.0.1", map[string]string{tc.header: val})).String())
+	}
+
+	require.True(t, IsForwardingHeader("x-forwarded-for"))
+	require.False(t, IsForwardingHeader("X-Zip-Forwarded"))
+}
@@ -0,0 +1,267 @@
+// Package coalesce merges concurrent reads of the same object, so a crowd of
+// clients requesting a cold object at once (e.g. right after a link is
+// posted) makes a single storage request. The payload is streamed to a buffer
+// shared by all waiting responses, every response reads it at its own pace.
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// Default limits of shared payloads.
+const (
+	DefaultMaxPayloadSize = 16 << 20
+	DefaultMaxTotalSize   = 256 << 20
+)
+
+// chunkSize is the size of payload chunks read from storage.
+const chunkSize = 64 << 10
+
+// errTooLarge is returned by the shared read of the object too large to be
+// shared or not fitting the memory budget, waiting readers read it on their
+// own then.
+var errTooLarge = errors.New("payload is too large to be shared")
+
+// flight is a read of the object shared by concurrent requests.
+type flight struct {
+	// ready is closed when the header is received or the read fails.
+	ready chan struct{}
+	hdr   object.Object
+	err   error
+	// refs is the number of requests waiting for the header or reading the
+	// payload, it's protected by the Backend mutex.
+	refs   int
+	cancel context.CancelFunc
+	// reserved is the part of the memory budget taken by the payload, it's
+	// protected by the Backend mutex.
+	reserved uint64
+
+	mu       sync.Mutex
+	buf      []byte
+	finished bool
+	readErr  error
+	// notify is closed and replaced when the payload is appended.
+	notify chan struct{}
+}
+
+// Backend merges concurrent reads of objects via the wrapped backend. Only
+// reads without bearer tokens (marked with extcache.Shareable) are merged.
+type Backend struct {
+	backend.Backend
+	ctx            context.Context
+	maxPayloadSize uint64
+	maxTotalSize   uint64
+
+	mu       sync.Mutex
+	flights  map[oid.Address]*flight
+	reserved uint64
+}
+
+// NewBackend wraps the backend merging reads of objects with payloads up to
+// maxPayloadSize, payloads shared at the same time take up to maxTotalSize,
+// objects not fitting it are read separately by every request. Shared reads
+// are canceled with the given context only, so they don't depend on the
+// request started them, but they keep its values.
+func NewBackend(ctx context.Context, b backend.Backend, maxPayloadSize, maxTotalSize uint64) *Backend {
+	if maxPayloadSize == 0 {
+		maxPayloadSize = DefaultMaxPayloadSize
+	}
+	if maxTotalSize == 0 {
+		maxTotalSize = DefaultMaxTotalSize
+	}
+	return &Backend{
+		Backend:        b,
+		ctx:            ctx,
+		maxPayloadSize: maxPayloadSize,
+		maxTotalSize:   maxTotalSize,
+		flights:        make(map[oid.Address]*flight),
+	}
+}
+
+// detachedContext has values of the request started the shared read and the
+// cancellation of the backend context.
+type detachedContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.values.Value(key)
+}
+
+// ObjectGetInit implements backend.Backend. The shared read is canceled when
+// all the requests reading it are gone.
+func (b *Backend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	if !extcache.IsShareable(ctx) {
+		return b.Backend.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+	}
+
+	var addr oid.Address
+	addr.SetContainer(containerID)
+	addr.SetObject(objectID)
+
+	b.mu.Lock()
+	f, ok := b.flights[addr]
+	if !ok {
+		fetchCtx, cancel := context.WithCancel(detachedContext{Context: b.ctx, values: ctx})
+		f = &flight{ready: make(chan struct{}), cancel: cancel, notify: make(chan struct{})}
+		b.flights[addr] = f
+		go b.fetch(fetchCtx, addr, f, signer, prm)
+	}
+	f.refs++
+	b.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		b.release(addr, f)
+		return object.Object{}, nil, ctx.Err()
+	case <-f.ready:
+	}
+	if f.err != nil {
+		b.release(addr, f)
+		if errors.Is(f.err, errTooLarge) {
+			return b.Backend.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+		}
+		return object.Object{}, nil, f.err
+	}
+	return f.hdr, &reader{ctx: ctx, b: b, addr: addr, f: f}, nil
+}
+
+// fetch reads the object to the flight buffer.
+func (b *Backend) fetch(ctx context.Context, addr oid.Address, f *flight, signer user.Signer, prm client.PrmObjectGet) {
+	defer b.finish(addr, f)
+
+	hdr, r, err := b.Backend.ObjectGetInit(ctx, addr.Container(), addr.Object(), signer, prm)
+	if err == nil && (hdr.PayloadSize() > b.maxPayloadSize || !b.reserve(f, hdr.PayloadSize())) {
+		_ = r.Close()
+		err = errTooLarge
+	}
+	if err != nil {
+		f.err = err
+		close(f.ready)
+		return
+	}
+	defer r.Close()
+
+	f.hdr = hdr
+	f.buf = make([]byte, 0, hdr.PayloadSize())
+	close(f.ready)
+
+	chunk := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(chunk)
+
+		f.mu.Lock()
+		f.buf = append(f.buf, chunk[:n]...)
+		if err != nil {
+			f.finished = true
+			if !errors.Is(err, io.EOF) {
+				f.readErr = err
+			}
+		}
+		close(f.notify)
+		f.notify = make(chan struct{})
+		f.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reserve takes the payload size from the memory budget, it's returned when
+// all the requests reading the payload are gone. False is returned if the
+// budget is exhausted or there are no readers left.
+func (b *Backend) reserve(f *flight, size uint64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if f.refs == 0 || b.reserved+size > b.maxTotalSize {
+		return false
+	}
+	b.reserved += size
+	f.reserved = size
+	return true
+}
+
+// finish removes the completed flight, so later reads are made anew.
+func (b *Backend) finish(addr oid.Address, f *flight) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.flights[addr] == f {
+		delete(b.flights, addr)
+	}
+}
+
+// release drops the reference to the flight, it's canceled when there are
+// no references left.
+func (b *Backend) release(addr oid.Address, f *flight) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f.refs--
+	if f.refs > 0 {
+		return
+	}
+	f.cancel()
+	b.reserved -= f.reserved
+	f.reserved = 0
+	if b.flights[addr] == f {
+		delete(b.flights, addr)
+	}
+}
+
+// reader reads the payload from the flight buffer.
+type reader struct {
+	ctx    context.Context
+	b      *Backend
+	addr   oid.Address
+	f      *flight
+	off    int
+	closed bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for {
+		r.f.mu.Lock()
+		if r.off < len(r.f.buf) {
+			n := copy(p, r.f.buf[r.off:])
+			r.off += n
+			r.f.mu.Unlock()
+			return n, nil
+		}
+		if r.f.finished {
+			err := r.f.readErr
+			r.f.mu.Unlock()
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		notify := r.f.notify
+		r.f.mu.Unlock()
+
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+func (r *reader) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.b.release(r.addr, r.f)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package coalesce
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingBackend counts object reads, they wait for the release channel.
+type blockingBackend struct {
+	*backend.Memory
+	reads   atomic.Int32
+	release chan struct{}
+	ctxErr  chan error
+}
+
+func (b *blockingBackend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	b.reads.Add(1)
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		if b.ctxErr != nil {
+			b.ctxErr <- ctx.Err()
+		}
+		return object.Object{}, nil, ctx.Err()
+	}
+	return b.Memory.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+}
+
+func putObject(t *testing.T, mem *backend.Memory, cnrID cid.ID, payload []byte) oid.ID {
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+	mem := &blockingBackend{Memory: backend.NewMemory(), release: make(chan struct{})}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	payload := bytes.Repeat([]byte("meow"), chunkSize)
+	objID := putObject(t, mem.Memory, cnrID, payload)
+
+	b := NewBackend(ctx, mem, 0, 0)
+	shareable := extcache.Shareable(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hdr, r, err := b.ObjectGetInit(shareable, cnrID, objID, nil, client.PrmObjectGet{})
+			require.NoError(t, err)
+			require.EqualValues(t, len(payload), hdr.PayloadSize())
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			require.Equal(t, payload, data)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		f := b.flights[newAddress(cnrID, objID)]
+		return f != nil && f.refs == 10
+	}, time.Second, time.Millisecond)
+	close(mem.release)
+	wg.Wait()
+	require.EqualValues(t, 1, mem.reads.Load())
+	require.Empty(t, b.flights)
+
+	// the next read is made anew
+	_, r, err := b.ObjectGetInit(shareable, cnrID, objID, nil, client.PrmObjectGet{})
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.EqualValues(t, 2, mem.reads.Load())
+
+	// reads with bearer tokens aren't merged
+	_, r, err = b.ObjectGetInit(ctx, cnrID, objID, nil, client.PrmObjectGet{})
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.EqualValues(t, 3, mem.reads.Load())
+	require.Empty(t, b.flights)
+
+	// failures are returned to all the readers
+	_, _, err = b.ObjectGetInit(shareable, cnrID, oidtest.ID(), nil, client.PrmObjectGet{})
+	require.ErrorIs(t, err, apistatus.ErrObjectNotFound)
+
+	t.Run("too large", func(t *testing.T) {
+		mem.reads.Store(0)
+		b := NewBackend(ctx, mem, 10, 0)
+		hdr, r, err := b.ObjectGetInit(shareable, cnrID, objID, nil, client.PrmObjectGet{})
+		require.NoError(t, err)
+		require.EqualValues(t, len(payload), hdr.PayloadSize())
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, payload, data)
+		require.EqualValues(t, 2, mem.reads.Load(), "the object is read on its own")
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		mem.reads.Store(0)
+		b := NewBackend(ctx, mem, 0, uint64(len(payload)))
+		_, first, err := b.ObjectGetInit(shareable, cnrID, objID, nil, client.PrmObjectGet{})
+		require.NoError(t, err)
+		require.EqualValues(t, len(payload), b.reserved)
+
+		other := putObject(t, mem.Memory, cnrID, []byte("purr"))
+		_, r, err := b.ObjectGetInit(shareable, cnrID, other, nil, client.PrmObjectGet{})
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, []byte("purr"), data)
+		require.NoError(t, r.Close())
+		require.EqualValues(t, 3, mem.reads.Load(), "the object over the budget is read on its own")
+
+		require.NoError(t, first.Close())
+		require.Zero(t, b.reserved, "the budget is returned when readers are gone")
+	})
+}
+
+type valueKey struct{}
+
+// valueBackend reports the value of the read context.
+type valueBackend struct {
+	*backend.Memory
+	values chan any
+}
+
+func (b *valueBackend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	b.values <- ctx.Value(valueKey{})
+	return b.Memory.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+}
+
+func TestBackendContextValues(t *testing.T) {
+	mem := &valueBackend{Memory: backend.NewMemory(), values: make(chan any, 1)}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	objID := putObject(t, mem.Memory, cnrID, []byte("meow"))
+	b := NewBackend(context.Background(), mem, 0, 0)
+
+	reqCtx, cancel := context.WithTimeout(context.WithValue(extcache.Shareable(context.Background()), valueKey{}, "request"), time.Hour)
+	defer cancel()
+	_, r, err := b.ObjectGetInit(reqCtx, cnrID, objID, nil, client.PrmObjectGet{})
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "request", <-mem.values, "the shared read keeps values of the request")
+}
+
+func TestBackendCancel(t *testing.T) {
+	ctx := context.Background()
+	mem := &blockingBackend{Memory: backend.NewMemory(), release: make(chan struct{}), ctxErr: make(chan error, 1)}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	objID := putObject(t, mem.Memory, cnrID, []byte("meow"))
+	b := NewBackend(ctx, mem, 0, 0)
+
+	// the shared read is canceled when the only reader is gone
+	reqCtx, cancel := context.WithCancel(extcache.Shareable(ctx))
+	done := make(chan error)
+	go func() {
+		_, _, err := b.ObjectGetInit(reqCtx, cnrID, objID, nil, client.PrmObjectGet{})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return mem.reads.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.ErrorIs(t, <-mem.ctxErr, context.Canceled)
+	require.Empty(t, b.flights)
+
+	// readers stop waiting for the payload when their context is done
+	close(mem.release)
+	f := &flight{ready: make(chan struct{}), notify: make(chan struct{}), cancel: func() {}, refs: 1}
+	reqCtx, cancel = context.WithCancel(ctx)
+	r := &reader{ctx: reqCtx, b: b, addr: newAddress(cnrID, objID), f: f}
+	cancel()
+	_, err := r.Read(make([]byte, 1))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func newAddress(cnrID cid.ID, objID oid.ID) oid.Address {
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(objID)
+	return addr
+}
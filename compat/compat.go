@@ -0,0 +1,211 @@
+// Package compat detects NeoFS API versions of storage nodes and gateway
+// features available with them.
+package compat
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/version"
+)
+
+// Feature is a gateway feature depending on NeoFS API version.
+type Feature string
+
+// Gateway features.
+const (
+	// FeatureRangeHash is object payload range hashing.
+	FeatureRangeHash Feature = "range_hash"
+	// FeatureLock is object locking.
+	FeatureLock Feature = "lock"
+)
+
+// ErrCodeUnsupported is an error code of responses to requests using features
+// unsupported by connected nodes.
+const ErrCodeUnsupported = "UNSUPPORTED_FEATURE"
+
+// minVersions are the NeoFS API versions features are supported since.
+var minVersions = map[Feature]apiVersion{
+	FeatureRangeHash: {major: 2, minor: 0},
+	FeatureLock:      {major: 2, minor: 12},
+}
+
+type apiVersion struct {
+	major, minor uint32
+}
+
+func newAPIVersion(v version.Version) apiVersion {
+	return apiVersion{major: v.Major(), minor: v.Minor()}
+}
+
+func (v apiVersion) less(other apiVersion) bool {
+	return v.major < other.major || v.major == other.major && v.minor < other.minor
+}
+
+func (v apiVersion) String() string {
+	return fmt.Sprintf("v%d.%d", v.major, v.minor)
+}
+
+// NodeVersion is a result of node API version detection.
+type NodeVersion struct {
+	Address string
+	// Version is nil if the node is unavailable.
+	Version *version.Version
+	Err     error
+}
+
+// baseVersion is the API version assumed when versions of all nodes are
+// unknown, features requiring newer versions are unsupported then.
+var baseVersion = apiVersion{major: 2, minor: 0}
+
+// Versions holds API versions of nodes and the negotiated version which is
+// the lowest one of available nodes. Nil Versions supports all features.
+type Versions struct {
+	nodes      []NodeVersion
+	negotiated *apiVersion
+}
+
+// Probe requests API versions of the nodes with the given addresses.
+func Probe(ctx context.Context, addresses []string, timeout time.Duration) []NodeVersion {
+	res := make([]NodeVersion, len(addresses))
+
+	var wg sync.WaitGroup
+	for i := range addresses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res[i].Address = addresses[i]
+			res[i].Version, res[i].Err = probeNode(ctx, addresses[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+
+	return res
+}
+
+func probeNode(ctx context.Context, address string, timeout time.Duration) (*version.Version, error) {
+	c, err := client.New(client.PrmInit{})
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+
+	var prmDial client.PrmDial
+	prmDial.SetServerURI(address)
+	prmDial.SetContext(ctx)
+	if timeout > 0 {
+		prmDial.SetTimeout(timeout)
+	}
+
+	if err = c.Dial(prmDial); err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+	defer c.Close()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	info, err := c.EndpointInfo(ctx, client.PrmEndpointInfo{})
+	if err != nil {
+		return nil, fmt.Errorf("endpoint info: %w", err)
+	}
+
+	v := info.LatestVersion()
+	return &v, nil
+}
+
+// Negotiate chooses the lowest API version of the available nodes.
+func Negotiate(nodes []NodeVersion) *Versions {
+	res := &Versions{nodes: nodes}
+	for _, n := range nodes {
+		if n.Version == nil {
+			continue
+		}
+		v := newAPIVersion(*n.Version)
+		if res.negotiated == nil || v.less(*res.negotiated) {
+			res.negotiated = &v
+		}
+	}
+	return res
+}
+
+// Nodes returns the detected versions of nodes.
+func (v *Versions) Nodes() []NodeVersion {
+	if v == nil {
+		return nil
+	}
+	return v.nodes
+}
+
+// Negotiated returns the negotiated API version, empty string if it's unknown.
+func (v *Versions) Negotiated() string {
+	if v == nil || v.negotiated == nil {
+		return ""
+	}
+	return v.negotiated.String()
+}
+
+// Mixed checks whether available nodes use different API versions.
+func (v *Versions) Mixed() bool {
+	if v == nil {
+		return false
+	}
+	var first *apiVersion
+	for _, n := range v.nodes {
+		if n.Version == nil {
+			continue
+		}
+		cur := newAPIVersion(*n.Version)
+		if first == nil {
+			first = &cur
+		} else if cur != *first {
+			return true
+		}
+	}
+	return false
+}
+
+// Supports checks whether the feature is supported by the negotiated version.
+// If versions of all nodes are unknown, only features of the base API version
+// are considered supported.
+func (v *Versions) Supports(f Feature) bool {
+	return v.Unsupported(f) == nil
+}
+
+// Unsupported returns an error describing why the feature is unavailable,
+// nil if it's supported.
+func (v *Versions) Unsupported(f Feature) error {
+	if v == nil {
+		return nil
+	}
+	minVersion, ok := minVersions[f]
+	if !ok {
+		return nil
+	}
+	if v.negotiated == nil {
+		if baseVersion.less(minVersion) {
+			return fmt.Errorf("%s is not supported, NeoFS API version of connected nodes is unknown, %s is required",
+				f, minVersion)
+		}
+		return nil
+	}
+	if !v.negotiated.less(minVersion) {
+		return nil
+	}
+	return fmt.Errorf("%s is not supported by NeoFS API %s of connected nodes, %s is required",
+		f, v.negotiated, minVersion)
+}
+
+// Features returns all known features with their availability.
+func (v *Versions) Features() map[Feature]bool {
+	res := make(map[Feature]bool, len(minVersions))
+	for f := range minVersions {
+		res[f] = v.Supports(f)
+	}
+	return res
+}
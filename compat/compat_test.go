@@ -0,0 +1,64 @@
+package compat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/version"
+	"github.com/stretchr/testify/require"
+)
+
+func nodeVersion(address string, major, minor uint32) NodeVersion {
+	var v version.Version
+	v.SetMajor(major)
+	v.SetMinor(minor)
+	return NodeVersion{Address: address, Version: &v}
+}
+
+func TestNegotiate(t *testing.T) {
+	t.Run("lowest version", func(t *testing.T) {
+		v := Negotiate([]NodeVersion{
+			nodeVersion("a", 2, 14),
+			nodeVersion("b", 2, 11),
+			{Address: "c", Err: errors.New("unavailable")},
+		})
+		require.Equal(t, "v2.11", v.Negotiated())
+		require.True(t, v.Mixed())
+		require.Len(t, v.Nodes(), 3)
+
+		require.True(t, v.Supports(FeatureRangeHash))
+		require.False(t, v.Supports(FeatureLock))
+		require.ErrorContains(t, v.Unsupported(FeatureLock), "v2.12 is required")
+
+		require.Equal(t, map[Feature]bool{
+			FeatureRangeHash: true,
+			FeatureLock:      false,
+		}, v.Features())
+	})
+
+	t.Run("same versions", func(t *testing.T) {
+		v := Negotiate([]NodeVersion{nodeVersion("a", 2, 14), nodeVersion("b", 2, 14)})
+		require.Equal(t, "v2.14", v.Negotiated())
+		require.False(t, v.Mixed())
+		require.True(t, v.Supports(FeatureLock))
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		v := Negotiate([]NodeVersion{{Address: "a", Err: errors.New("unavailable")}})
+		require.Empty(t, v.Negotiated())
+		require.True(t, v.Supports(FeatureRangeHash))
+		require.ErrorContains(t, v.Unsupported(FeatureLock), "version of connected nodes is unknown")
+		require.Equal(t, map[Feature]bool{
+			FeatureRangeHash: true,
+			FeatureLock:      false,
+		}, v.Features())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var v *Versions
+		require.Empty(t, v.Negotiated())
+		require.False(t, v.Mixed())
+		require.Nil(t, v.Nodes())
+		require.True(t, v.Supports(FeatureLock))
+	})
+}
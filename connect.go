@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// errCodeStorageUnavailable is an error code of requests rejected because the
+// gate isn't connected to NeoFS yet.
+const errCodeStorageUnavailable = "STORAGE_UNAVAILABLE"
+
+// dialPool connects the pool to NeoFS nodes and sets the connected flag. If no
+// node is reachable, the gate fails in fail_fast start mode, in lazy mode it
+// keeps reconnecting in background and serves requests requiring NeoFS with
+// 503 until connected. Optional onConnect is called after reconnection.
+func (a *app) dialPool(ctx context.Context, log *zap.Logger, p *pool.Pool, connected *atomic.Bool, onConnect func()) {
+	err := p.Dial(ctx)
+	if err == nil {
+		connected.Store(true)
+		return
+	}
+
+	if !a.lazyStart() {
+		log.Fatal("failed to dial pool", zap.Error(err))
+	}
+
+	log.Warn("failed to dial pool, gate starts without connection to NeoFS",
+		zap.Duration("retry", a.redialInterval()), zap.Error(err))
+	go a.redialPool(ctx, log, p, connected, onConnect)
+}
+
+func (a *app) lazyStart() bool {
+	switch mode := a.cfg.GetString(cfgPoolStartMode); mode {
+	case poolStartModeFailFast:
+		return false
+	case poolStartModeLazy:
+		return true
+	default:
+		a.log.Warn("unknown pool start mode, fail_fast is used", zap.String("mode", mode))
+		return false
+	}
+}
+
+func (a *app) redialInterval() time.Duration {
+	interval := a.cfg.GetDuration(cfgPoolRedialInterval)
+	if interval <= 0 {
+		interval = defaultPoolRedialInterval
+	}
+	return interval
+}
+
+// redialPool dials the pool until it's connected or the context is done.
+func (a *app) redialPool(ctx context.Context, log *zap.Logger, p *pool.Pool, connected *atomic.Bool, onConnect func()) {
+	interval := a.redialInterval()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := p.Dial(ctx); err != nil {
+			log.Warn("failed to dial pool", zap.Duration("retry", interval), zap.Error(err))
+			timer.Reset(interval)
+			continue
+		}
+
+		log.Info("connected to NeoFS")
+		connected.Store(true)
+		if onConnect != nil {
+			onConnect()
+		}
+		return
+	}
+}
+
+// connected rejects requests with 503 and Retry-After header while the gate
+// isn't connected to NeoFS network with the given connected flag.
+func (a *app) connected(flag *atomic.Bool, h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !flag.Load() {
+			response.ErrorWithCode(ctx, errCodeStorageUnavailable, "gate isn't connected to NeoFS yet, try again later",
+				fasthttp.StatusServiceUnavailable)
+			ctx.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(a.redialInterval())))
+			return
+		}
+		h(ctx)
+	}
+}
+
+// retryAfterSeconds converts the duration to Retry-After header value which
+// is a positive number of seconds.
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/discovery"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"go.uber.org/zap"
+)
+
+// discoveryDrainTimeout is a time the replaced connection pool is kept open
+// for operations started before the replacement.
+const discoveryDrainTimeout = 5 * time.Minute
+
+// startPeersDiscovery discovers storage nodes of the default network from the
+// network map if it's enabled, so peers from the configuration are used as
+// seed nodes only.
+func (a *app) startPeersDiscovery(ctx context.Context) {
+	if !a.cfg.GetBool(cfgPeersDiscoveryEnabled) {
+		return
+	}
+	go a.runPeersDiscovery(ctx)
+}
+
+// runPeersDiscovery checks the network map with the configured interval until
+// the context is done, discovery is done once if the interval is zero. The
+// network map is requested once the gate is connected to the seed nodes,
+// failed attempts are retried with the pool redial interval.
+func (a *app) runPeersDiscovery(ctx context.Context) {
+	var discovered []string
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if !a.poolConnected.Load() {
+			timer.Reset(a.redialInterval())
+			continue
+		}
+
+		nodes, err := a.discoverPeers(ctx, discovered)
+		interval := a.cfg.GetDuration(cfgPeersDiscoveryInterval)
+		if err != nil {
+			a.log.Warn("failed to discover storage nodes", zap.Duration("retry", a.redialInterval()), zap.Error(err))
+			interval = a.redialInterval()
+		} else {
+			discovered = nodes
+			if interval <= 0 {
+				return
+			}
+		}
+		timer.Reset(interval)
+	}
+}
+
+// discoverPeers replaces the connection pool if storage nodes matching the
+// configured attributes differ from the current ones, nodes in use are
+// returned. Seed nodes are kept in the new pool with lower priority, so the
+// gate falls back to them if discovered nodes are unavailable.
+func (a *app) discoverPeers(ctx context.Context, current []string) ([]string, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, a.cfg.GetDuration(cfgReqTimeout))
+	nm, err := a.pool.NetMapSnapshot(reqCtx, client.PrmNetMapSnapshot{})
+	cancel()
+	if err != nil {
+		return current, fmt.Errorf("get network map: %w", err)
+	}
+
+	candidates := discovery.Nodes(nm, a.cfg.GetStringMapString(cfgPeersDiscoveryAttributes))
+	if len(candidates) == 0 {
+		return current, errors.New("no nodes matching the attributes in the network map")
+	}
+	nodes := discovery.Select(current, candidates, a.cfg.GetInt(cfgPeersDiscoveryMaxNodes))
+	if discovery.Equal(nodes, current) {
+		return current, nil
+	}
+
+	prm := a.poolParameters(a.signer)
+	for _, address := range nodes {
+		prm.AddNode(pool.NewNodeParam(1, address, 1))
+	}
+	for _, seed := range a.peers(cfgPeers) {
+		seed.priority++
+		prm.AddNode(seed.nodeParam())
+	}
+	p, err := pool.NewPool(prm)
+	if err != nil {
+		return current, fmt.Errorf("create connection pool: %w", err)
+	}
+	// the pool is rebalanced in background until the context is done
+	if err = p.Dial(ctx); err != nil {
+		return current, fmt.Errorf("dial discovered nodes: %w", err)
+	}
+
+	old := a.pool.Swap(p)
+	time.AfterFunc(discoveryDrainTimeout, old.Close)
+
+	a.log.Info("storage nodes discovered", zap.Uint64("epoch", nm.Epoch()),
+		zap.Strings("nodes", nodes), zap.Int("available", len(candidates)))
+	return nodes, nil
+}
@@ -0,0 +1,151 @@
+// Package discovery selects storage nodes for the connection pool from the
+// NeoFS network map, so the gateway needs a single seed node in the
+// configuration instead of a static list of peers.
+package discovery
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+)
+
+// DefaultInterval is a default interval between network map checks.
+const DefaultInterval = 10 * time.Minute
+
+// Nodes returns addresses of online nodes of the network map having all the
+// attributes with the given values. Attribute names are case-insensitive
+// (configuration keys are lowercased), values are compared as is. The first
+// valid network endpoint of each node is used, nodes without them are
+// skipped. Addresses are sorted.
+func Nodes(nm netmap.NetMap, attributes map[string]string) []string {
+	var res []string
+	for _, n := range nm.Nodes() {
+		if !n.IsOnline() || !Matches(n, attributes) {
+			continue
+		}
+
+		var address string
+		n.IterateNetworkEndpoints(func(endpoint string) bool {
+			var err error
+			address, err = Address(endpoint)
+			return err == nil
+		})
+		if address != "" {
+			res = append(res, address)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Matches checks whether the node has all the attributes with the given
+// values, attribute names are case-insensitive.
+func Matches(n netmap.NodeInfo, attributes map[string]string) bool {
+	if len(attributes) == 0 {
+		return true
+	}
+	found := make(map[string]string, len(attributes))
+	n.IterateAttributes(func(key, value string) {
+		found[strings.ToLower(key)] = value
+	})
+	for key, value := range attributes {
+		if v, ok := found[strings.ToLower(key)]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Address converts the network endpoint of the node to the address accepted
+// by the connection pool. Network maps contain multiaddresses like
+// /dns4/st1.fs.neo.org/tcp/8080 (or /ip4/, /ip6/ ones with optional /tls
+// suffix), other endpoints are returned as is.
+func Address(endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, "/") {
+		return endpoint, nil
+	}
+
+	var (
+		host, port string
+		tls        bool
+		parts      = strings.Split(strings.TrimPrefix(endpoint, "/"), "/")
+	)
+	for i := 0; i < len(parts); i++ {
+		switch parts[i] {
+		case "ip4", "ip6", "dns", "dns4", "dns6":
+			if i+1 >= len(parts) {
+				return "", fmt.Errorf("missing host in '%s'", endpoint)
+			}
+			i++
+			host = parts[i]
+		case "tcp":
+			if i+1 >= len(parts) {
+				return "", fmt.Errorf("missing port in '%s'", endpoint)
+			}
+			i++
+			port = parts[i]
+		case "tls":
+			tls = true
+		default:
+			return "", fmt.Errorf("unsupported protocol '%s' in '%s'", parts[i], endpoint)
+		}
+	}
+	if host == "" || port == "" {
+		return "", fmt.Errorf("incomplete address '%s'", endpoint)
+	}
+
+	scheme := "grpc://"
+	if tls {
+		scheme = "grpcs://"
+	}
+	return scheme + net.JoinHostPort(host, port), nil
+}
+
+// Select chooses up to limit addresses of the candidates (all of them if
+// limit isn't positive). Currently used addresses are preferred, so the
+// selection is stable while the nodes stay in the network map, others are
+// chosen randomly to spread gateways over the network. The result is sorted.
+func Select(current, candidates []string, limit int) []string {
+	if limit <= 0 || len(candidates) <= limit {
+		return candidates
+	}
+
+	used := make(map[string]struct{}, len(current))
+	for _, address := range current {
+		used[address] = struct{}{}
+	}
+
+	res := make([]string, 0, limit)
+	var others []string
+	for _, address := range candidates {
+		if _, ok := used[address]; ok && len(res) < limit {
+			res = append(res, address)
+		} else {
+			others = append(others, address)
+		}
+	}
+	// global source isn't seeded, all gateways would choose the same nodes
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd.Shuffle(len(others), func(i, j int) { others[i], others[j] = others[j], others[i] })
+	res = append(res, others[:limit-len(res)]...)
+	sort.Strings(res)
+	return res
+}
+
+// Equal checks whether sorted address lists are the same.
+func Equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddress(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint, address string
+	}{
+		{"/dns4/st1.fs.neo.org/tcp/8080", "grpc://st1.fs.neo.org:8080"},
+		{"/ip4/10.0.0.1/tcp/8080/tls", "grpcs://10.0.0.1:8080"},
+		{"/ip6/::1/tcp/8080", "grpc://[::1]:8080"},
+		{"grpcs://st1.fs.neo.org:8082", "grpcs://st1.fs.neo.org:8082"},
+		{"st1.fs.neo.org:8080", "st1.fs.neo.org:8080"},
+	} {
+		address, err := Address(tc.endpoint)
+		require.NoError(t, err, tc.endpoint)
+		require.Equal(t, tc.address, address)
+	}
+
+	for _, endpoint := range []string{"/dns4/st1.fs.neo.org", "/tcp/8080", "/dns4/st1.fs.neo.org/udp/8080", "/ip4"} {
+		_, err := Address(endpoint)
+		require.Error(t, err, endpoint)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	node := func(online bool, continent string, endpoints ...string) netmap.NodeInfo {
+		var n netmap.NodeInfo
+		n.SetNetworkEndpoints(endpoints...)
+		n.SetContinentName(continent)
+		if online {
+			n.SetOnline()
+		} else {
+			n.SetOffline()
+		}
+		return n
+	}
+
+	var nm netmap.NetMap
+	nm.SetNodes([]netmap.NodeInfo{
+		node(true, "Europe", "/dns4/st2.fs.neo.org/tcp/8080"),
+		node(true, "Europe", "/quic/st1", "/dns4/st1.fs.neo.org/tcp/8080"),
+		node(false, "Europe", "/dns4/st3.fs.neo.org/tcp/8080"),
+		node(true, "Asia", "/dns4/st4.fs.neo.org/tcp/8080"),
+		node(true, "Europe", "/quic/st5"),
+	})
+
+	require.Equal(t, []string{"grpc://st1.fs.neo.org:8080", "grpc://st2.fs.neo.org:8080", "grpc://st4.fs.neo.org:8080"},
+		Nodes(nm, nil))
+	require.Equal(t, []string{"grpc://st1.fs.neo.org:8080", "grpc://st2.fs.neo.org:8080"},
+		Nodes(nm, map[string]string{"continent": "Europe"}))
+	require.Empty(t, Nodes(nm, map[string]string{"continent": "Europe", "country": "Germany"}))
+}
+
+func TestSelect(t *testing.T) {
+	candidates := []string{"a", "b", "c", "d", "e"}
+
+	require.Equal(t, candidates, Select(nil, candidates, 0))
+	require.Equal(t, candidates, Select(nil, candidates, 10))
+
+	res := Select(nil, candidates, 3)
+	require.Len(t, res, 3)
+	require.Subset(t, candidates, res)
+	require.IsIncreasing(t, res)
+
+	// nodes in use are kept
+	require.Equal(t, []string{"b", "d"}, Select([]string{"b", "d", "x"}, candidates, 2))
+	res = Select([]string{"b", "x"}, candidates, 2)
+	require.Contains(t, res, "b")
+	require.Len(t, res, 2)
+
+	require.True(t, Equal([]string{"a", "b"}, []string{"a", "b"}))
+	require.False(t, Equal([]string{"a", "b"}, []string{"a"}))
+	require.False(t, Equal([]string{"a", "b"}, []string{"a", "c"}))
+}
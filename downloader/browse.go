@@ -0,0 +1,303 @@
+package downloader
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+//go:embed browse.html
+var browseHTML string
+
+var browseTemplate = template.Must(template.New("browse").Parse(browseHTML))
+
+// browseTemplateSum changes page ETags when the template is changed.
+var browseTemplateSum = func() string {
+	sum := sha256.Sum256([]byte(browseHTML))
+	return hex.EncodeToString(sum[:])
+}()
+
+type (
+	browseLink struct {
+		Name string
+		Href string
+	}
+
+	browseFile struct {
+		Name        string
+		Href        string
+		Size        uint64
+		ContentType string
+		Modified    string
+	}
+
+	browsePage struct {
+		Container   string
+		Prefix      string
+		Nonce       string
+		Breadcrumbs []browseLink
+		ParentHref  string
+		UploadHref  string
+		Dirs        []browseLink
+		Files       []browseFile
+		Truncated   bool
+		Limit       int
+	}
+
+	// browseObject is a listed object, Path is its FilePath, FileName or ID.
+	browseObject struct {
+		ID          oid.ID
+		Path        string
+		Size        uint64
+		ContentType string
+		Modified    time.Time
+	}
+)
+
+// escapePath escapes every segment of the slash-separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+// browseDirPrefix normalizes the requested prefix to be a directory.
+func browseDirPrefix(prefix string) string {
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// browseRoutePrefix returns the part of the browse request path preceding the
+// route (e.g. network prefix), it's prepended to the page links.
+func browseRoutePrefix(path string) string {
+	return utils.RoutePrefix(path, "/browse/")
+}
+
+// newBrowsePage groups objects by the next path segment after the prefix, the
+// ones having it followed by a slash are listed as directories. Links are
+// prefixed with routePrefix.
+func newBrowsePage(routePrefix, scid, prefix string, objs []browseObject) browsePage {
+	root := routePrefix + "/browse/" + url.PathEscape(scid)
+	base := root + "/"
+	page := browsePage{
+		Container:   scid,
+		Prefix:      prefix,
+		Breadcrumbs: []browseLink{{Name: scid, Href: root}},
+		UploadHref:  routePrefix + "/upload/" + url.PathEscape(scid),
+	}
+
+	if prefix != "" {
+		segments := strings.Split(strings.TrimSuffix(prefix, "/"), "/")
+		for i, s := range segments {
+			page.Breadcrumbs = append(page.Breadcrumbs, browseLink{
+				Name: s,
+				Href: base + escapePath(strings.Join(segments[:i+1], "/")) + "/",
+			})
+		}
+		page.ParentHref = page.Breadcrumbs[len(page.Breadcrumbs)-2].Href
+	}
+
+	seen := make(map[string]struct{})
+	for _, obj := range objs {
+		if !strings.HasPrefix(obj.Path, prefix) || len(obj.Path) == len(prefix) {
+			continue
+		}
+		rest := obj.Path[len(prefix):]
+
+		if dir, _, ok := strings.Cut(rest, "/"); ok {
+			if _, ok = seen[dir]; !ok && dir != "" {
+				seen[dir] = struct{}{}
+				page.Dirs = append(page.Dirs, browseLink{Name: dir, Href: base + escapePath(prefix+dir) + "/"})
+			}
+			continue
+		}
+
+		file := browseFile{
+			Name:        rest,
+			Href:        routePrefix + "/get/" + url.PathEscape(scid) + "/" + obj.ID.EncodeToString(),
+			Size:        obj.Size,
+			ContentType: obj.ContentType,
+		}
+		if !obj.Modified.IsZero() {
+			file.Modified = obj.Modified.UTC().Format(time.RFC3339)
+		}
+		page.Files = append(page.Files, file)
+	}
+
+	sort.Slice(page.Dirs, func(i, j int) bool {
+		return page.Dirs[i].Name < page.Dirs[j].Name
+	})
+	sort.SliceStable(page.Files, func(i, j int) bool {
+		return page.Files[i].Name < page.Files[j].Name
+	})
+
+	return page
+}
+
+// Browse handles requests to the HTML listing of container objects by FilePath
+// directories. Objects can be viewed, downloaded and uploaded from the page.
+func (d *Downloader) Browse(c *fasthttp.RequestCtx) {
+	scid, _ := c.UserValue("cid").(string)
+	rawPrefix, _ := c.UserValue("prefix").(string)
+	prefix, _ := url.PathUnescape(rawPrefix)
+	prefix = browseDirPrefix(prefix)
+	log := d.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
+
+	if !d.settings.BrowseEnabled() {
+		response.Error(c, "browsing is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	if prefix != "" {
+		filters.AddFilter(object.AttributeFilePath, prefix, object.MatchCommonPrefix)
+	}
+
+	resSearch, err := d.searchByFilters(c, containerID, filters)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return
+	}
+
+	limit := d.settings.BrowseMaxObjects()
+	var ids []oid.ID
+	err = resSearch.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return limit > 0 && len(ids) > limit
+	})
+	_ = resSearch.Close()
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		if errors.Is(err, apistatus.ErrContainerNotFound) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return
+		}
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return
+	}
+
+	truncated := limit > 0 && len(ids) > limit
+	if truncated {
+		ids = ids[:limit]
+	}
+
+	// listed objects are immutable, so the page changes only with the set of
+	// found objects, they aren't requested if the client has the page
+	if setHTMLValidator(c, listingETag(c.Path(), browseTemplateSum+strconv.Itoa(limit), ids)) {
+		return
+	}
+
+	btoken := bearerToken(c)
+	objs := make([]browseObject, 0, len(ids))
+	for _, id := range ids {
+		obj, err := d.browseObject(*containerID, id, btoken)
+		if err != nil {
+			log.Warn("could not head object", zap.Stringer("oid", id), zap.Error(err))
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	page := newBrowsePage(browseRoutePrefix(string(c.Path())), scid, prefix, objs)
+	page.Truncated, page.Limit = truncated, limit
+
+	nonce := make([]byte, 16)
+	if _, err = rand.Read(nonce); err != nil {
+		log.Error("could not generate nonce", zap.Error(err))
+		response.Error(c, "could not generate nonce: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+	page.Nonce = base64.StdEncoding.EncodeToString(nonce)
+
+	var buf bytes.Buffer
+	if err = browseTemplate.Execute(&buf, page); err != nil {
+		log.Error("could not render page", zap.Error(err))
+		response.Error(c, "could not render page: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	c.Response.Header.SetContentType("text/html; charset=utf-8")
+	c.Response.Header.Set("Content-Security-Policy",
+		"default-src 'self'; script-src 'nonce-"+page.Nonce+"'; style-src 'nonce-"+page.Nonce+"'; frame-ancestors 'none'")
+	c.SetStatusCode(fasthttp.StatusOK)
+	c.SetBody(buf.Bytes())
+}
+
+// browseObject heads the object to get its listing attributes.
+func (d *Downloader) browseObject(cnrID cid.ID, id oid.ID, btoken *bearer.Token) (browseObject, error) {
+	var prm client.PrmObjectHead
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	hdr, err := d.backend.ObjectHead(d.appCtx, cnrID, id, d.signer, prm)
+	if err != nil {
+		return browseObject{}, err
+	}
+
+	res := browseObject{ID: id, Size: hdr.PayloadSize()}
+	var fileName string
+	for _, attr := range hdr.Attributes() {
+		switch attr.Key() {
+		case object.AttributeFilePath:
+			res.Path = attr.Value()
+		case object.AttributeFileName:
+			fileName = attr.Value()
+		case object.AttributeContentType:
+			res.ContentType = attr.Value()
+		case object.AttributeTimestamp:
+			if value, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				res.Modified = time.Unix(value, 0)
+			}
+		}
+	}
+	if res.Path == "" {
+		res.Path = fileName
+	}
+	if res.Path == "" {
+		res.Path = id.EncodeToString()
+	}
+	return res, nil
+}
@@ -0,0 +1,95 @@
+package downloader
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestBrowseDirPrefix(t *testing.T) {
+	for prefix, expected := range map[string]string{
+		"":       "",
+		"/":      "",
+		"dir":    "dir/",
+		"dir/":   "dir/",
+		"/a/b":   "a/b/",
+		"a/b/c/": "a/b/c/",
+	} {
+		require.Equal(t, expected, browseDirPrefix(prefix), prefix)
+	}
+}
+
+func TestNewBrowsePage(t *testing.T) {
+	id := oidtest.ID()
+	objs := []browseObject{
+		{ID: id, Path: "dir/b.txt", Size: 10, ContentType: "text/plain", Modified: time.Unix(1700000000, 0)},
+		{ID: id, Path: "dir/a b.txt"},
+		{ID: id, Path: "dir/sub/c.txt"},
+		{ID: id, Path: "dir/sub/d.txt"},
+		{ID: id, Path: "dir/#x/e.txt"},
+		{ID: id, Path: "dir/"},
+		{ID: id, Path: "other/f.txt"},
+		{ID: id, Path: "dirx"},
+	}
+
+	page := newBrowsePage("", "cnr", "dir/", objs)
+	require.Equal(t, []browseLink{
+		{Name: "cnr", Href: "/browse/cnr"},
+		{Name: "dir", Href: "/browse/cnr/dir/"},
+	}, page.Breadcrumbs)
+	require.Equal(t, "/browse/cnr", page.ParentHref)
+	require.Equal(t, "/upload/cnr", page.UploadHref)
+	require.Equal(t, []browseLink{
+		{Name: "#x", Href: "/browse/cnr/dir/%23x/"},
+		{Name: "sub", Href: "/browse/cnr/dir/sub/"},
+	}, page.Dirs)
+	require.Equal(t, []browseFile{
+		{Name: "a b.txt", Href: "/get/cnr/" + id.EncodeToString()},
+		{Name: "b.txt", Href: "/get/cnr/" + id.EncodeToString(), Size: 10, ContentType: "text/plain", Modified: "2023-11-14T22:13:20Z"},
+	}, page.Files)
+
+	page = newBrowsePage("", "cnr", "", objs)
+	require.Empty(t, page.ParentHref)
+	require.Len(t, page.Breadcrumbs, 1)
+	require.Equal(t, []string{"dir", "other"}, []string{page.Dirs[0].Name, page.Dirs[1].Name})
+	require.Len(t, page.Files, 1)
+	require.Equal(t, "dirx", page.Files[0].Name)
+}
+
+func TestBrowseRoutePrefix(t *testing.T) {
+	require.Empty(t, browseRoutePrefix("/browse/cnr"))
+	require.Empty(t, browseRoutePrefix("/browse/cnr/dir/browse/"))
+	require.Equal(t, "/testnet", browseRoutePrefix("/testnet/browse/cnr/dir/"))
+
+	page := newBrowsePage("/testnet", "cnr", "dir/", []browseObject{{ID: oidtest.ID(), Path: "dir/sub/a"}})
+	require.Equal(t, "/testnet/browse/cnr", page.Breadcrumbs[0].Href)
+	require.Equal(t, "/testnet/upload/cnr", page.UploadHref)
+	require.Equal(t, "/testnet/browse/cnr/dir/sub/", page.Dirs[0].Href)
+}
+
+func TestBrowseTemplate(t *testing.T) {
+	page := newBrowsePage("", "cnr", "dir/", []browseObject{
+		{ID: oidtest.ID(), Path: "dir/<script>.html"},
+	})
+	page.Nonce = "nonce"
+
+	var buf bytes.Buffer
+	require.NoError(t, browseTemplate.Execute(&buf, page))
+	require.NotContains(t, buf.String(), "<script>.html")
+	require.Contains(t, buf.String(), "&lt;script&gt;.html")
+	require.Contains(t, buf.String(), `<script nonce="nonce">`)
+}
+
+func TestBrowseDisabled(t *testing.T) {
+	d := &Downloader{log: zap.NewNop(), settings: &Settings{}}
+
+	var c fasthttp.RequestCtx
+	c.SetUserValue("cid", "cnr")
+	d.Browse(&c)
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+}
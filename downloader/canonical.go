@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+// hdrLink is a response header with links related to the object.
+const hdrLink = "Link"
+
+// canonicalRoutes are the routes serving objects, the request path preceding
+// them is the network prefix kept in canonical links.
+var canonicalRoutes = []string{"/get/", "/get_by_attribute/"}
+
+// CanonicalLinks builds canonical URLs of downloaded objects, so the
+// different URLs pointing to the same object are consolidated by search
+// engines and caches.
+type CanonicalLinks struct {
+	base        string
+	useFilePath bool
+	aliases     map[cid.ID]string
+}
+
+// NewCanonicalLinks creates canonical links builder with the external base
+// URL of the gate. Containers are referred by their aliases if they have
+// ones, objects are referred by FilePath if useFilePath is set.
+func NewCanonicalLinks(baseURL string, useFilePath bool, aliases map[string]string) *CanonicalLinks {
+	l := &CanonicalLinks{
+		base:        strings.TrimSuffix(baseURL, "/"),
+		useFilePath: useFilePath,
+		aliases:     make(map[cid.ID]string, len(aliases)),
+	}
+	for name, val := range aliases {
+		var id cid.ID
+		if id.DecodeString(val) != nil {
+			continue
+		}
+		// the same alias is used for the container with several ones
+		if prev, ok := l.aliases[id]; !ok || name < prev {
+			l.aliases[id] = name
+		}
+	}
+	return l
+}
+
+// URL returns the canonical URL of the object. routePrefix is the path
+// preceding the gate routes, e.g. the network prefix. The object can be
+// referred by its FilePath only if uniquePath is set, i.e. it's the only
+// visible object with this path, other versions and language variants are
+// referred by their IDs, since the path can lead to another object.
+func (l *CanonicalLinks) URL(routePrefix string, obj *object.Object, uniquePath bool) string {
+	cnrID, _ := obj.ContainerID()
+	objID, _ := obj.ID()
+
+	cnr := cnrID.EncodeToString()
+	if alias, ok := l.aliases[cnrID]; ok {
+		cnr = alias
+	}
+	base := l.base + routePrefix
+
+	// attribute values are unescaped after the path is decoded and slashes
+	// are merged, so such paths can't be canonical
+	if filePath := getZipFilePath(obj); l.useFilePath && uniquePath && filePath != "" &&
+		!strings.ContainsAny(filePath, "%+") && !strings.HasPrefix(filePath, "/") && !strings.Contains(filePath, "//") {
+		return base + "/get_by_attribute/" + url.PathEscape(cnr) + "/" + object.AttributeFilePath + "/" + escapePath(filePath)
+	}
+	return base + "/get/" + url.PathEscape(cnr) + "/" + objID.EncodeToString()
+}
+
+// setCanonicalLink sets the Link header with the canonical URL of the object
+// if canonical links are configured.
+func (r request) setCanonicalLink(obj *object.Object) {
+	if r.canonical == nil {
+		return
+	}
+	link := r.canonical.URL(utils.RoutePrefix(string(r.Path()), canonicalRoutes...), obj, r.uniquePath)
+	r.Response.Header.Add(hdrLink, "<"+link+`>; rel="canonical"`)
+}
@@ -0,0 +1,130 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestCanonicalLinksURL(t *testing.T) {
+	cnrID := cidtest.ID()
+	objID := oidtest.ID()
+	cnr, id := cnrID.EncodeToString(), objID.EncodeToString()
+
+	newObject := func(filePath string) *object.Object {
+		var obj object.Object
+		obj.SetContainerID(cnrID)
+		obj.SetID(objID)
+		if filePath != "" {
+			attr := object.NewAttribute()
+			attr.SetKey(object.AttributeFilePath)
+			attr.SetValue(filePath)
+			obj.SetAttributes(*attr)
+		}
+		return &obj
+	}
+
+	l := NewCanonicalLinks("https://gate.example.com/", true, nil)
+	for filePath, expected := range map[string]string{
+		"":               "https://gate.example.com/get/" + cnr + "/" + id,
+		"dir/index.html": "https://gate.example.com/get_by_attribute/" + cnr + "/FilePath/dir/index.html",
+		"my file?.txt":   "https://gate.example.com/get_by_attribute/" + cnr + "/FilePath/my%20file%3F.txt",
+		"/abs/path":      "https://gate.example.com/get/" + cnr + "/" + id,
+		"dir//file":      "https://gate.example.com/get/" + cnr + "/" + id,
+		"100%.txt":       "https://gate.example.com/get/" + cnr + "/" + id,
+		"a+b":            "https://gate.example.com/get/" + cnr + "/" + id,
+	} {
+		require.Equal(t, expected, l.URL("", newObject(filePath), true), filePath)
+	}
+	// versions and language variants can't be referred by path
+	require.Equal(t, "https://gate.example.com/get/"+cnr+"/"+id, l.URL("", newObject("dir/index.html"), false))
+
+	l = NewCanonicalLinks("https://gate.example.com", false, map[string]string{
+		"site":  cnr,
+		"alias": cnr,
+		"other": cidtest.ID().EncodeToString(),
+		"bad":   "not a container",
+	})
+	require.Equal(t, "https://gate.example.com/testnet/get/alias/"+id, l.URL("/testnet", newObject("index.html"), true))
+}
+
+func TestCanonicalRoutePrefix(t *testing.T) {
+	require.Empty(t, utils.RoutePrefix("/get/cid/oid", canonicalRoutes...))
+	require.Empty(t, utils.RoutePrefix("/get_by_attribute/cid/FilePath/a", canonicalRoutes...))
+	require.Equal(t, "/testnet", utils.RoutePrefix("/testnet/get/cid/oid", canonicalRoutes...))
+	require.Equal(t, "/testnet", utils.RoutePrefix("/testnet/get_by_attribute/cid/FilePath/get/a", canonicalRoutes...))
+}
+
+func TestCanonicalLinkHeader(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	put := func(timestamp string) oid.ID {
+		var hdr object.Object
+		hdr.SetContainerID(cnrID)
+		path := object.NewAttribute()
+		path.SetKey(object.AttributeFilePath)
+		path.SetValue("index.html")
+		ts := object.NewAttribute()
+		ts.SetKey(object.AttributeTimestamp)
+		ts.SetValue(timestamp)
+		hdr.SetAttributes(*path, *ts)
+		w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+		require.NoError(t, err)
+		_, err = w.Write([]byte("<html></html>"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return w.StoredObjectID()
+	}
+	objID := put("1")
+
+	settings := &Settings{}
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, settings, nil)
+
+	get := func(h fasthttp.RequestHandler) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/get/" + cnrID.EncodeToString() + "/" + objID.EncodeToString())
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", objID.EncodeToString())
+		h(&c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		return &c
+	}
+	getByPath := func(h fasthttp.RequestHandler) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/get_by_attribute/" + cnrID.EncodeToString() + "/FilePath/index.html")
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "index.html")
+		h(&c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		return &c
+	}
+
+	require.Empty(t, get(d.DownloadByAddress).Response.Header.Peek(hdrLink), "disabled")
+
+	settings.SetCanonicalLinks(NewCanonicalLinks("https://gate.example.com", true, nil))
+	byPath := `<https://gate.example.com/get_by_attribute/` + cnrID.EncodeToString() + `/FilePath/index.html>; rel="canonical"`
+	byID := `<https://gate.example.com/get/` + cnrID.EncodeToString() + `/` + objID.EncodeToString() + `>; rel="canonical"`
+	require.Equal(t, byPath, string(getByPath(d.DownloadByAttribute).Response.Header.Peek(hdrLink)))
+	require.Equal(t, byPath, string(getByPath(d.HeadByAttribute).Response.Header.Peek(hdrLink)))
+	// the object requested by ID can be an older version
+	require.Equal(t, byID, string(get(d.DownloadByAddress).Response.Header.Peek(hdrLink)))
+	require.Equal(t, byID, string(get(d.HeadByAddress).Response.Header.Peek(hdrLink)))
+
+	// the path leads to the newest version only
+	objID = put("2")
+	byID = `<https://gate.example.com/get/` + cnrID.EncodeToString() + `/` + objID.EncodeToString() + `>; rel="canonical"`
+	require.Equal(t, byID, string(getByPath(d.DownloadByAttribute).Response.Header.Peek(hdrLink)))
+}
@@ -1,15 +1,17 @@
 package downloader
 
 import (
-	"archive/zip"
 	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -18,18 +20,31 @@ import (
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/nspcc-dev/neofs-http-gw/attrcache"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/pathfilter"
+	"github.com/nspcc-dev/neofs-http-gw/purge"
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
 	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/searchlimit"
+	"github.com/nspcc-dev/neofs-http-gw/servertiming"
+	"github.com/nspcc-dev/neofs-http-gw/streamlimit"
 	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/transform"
+	"github.com/nspcc-dev/neofs-http-gw/usage"
 	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-http-gw/zipstream"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
 	"github.com/nspcc-dev/neofs-sdk-go/container"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
@@ -37,8 +52,21 @@ import (
 
 type request struct {
 	*fasthttp.RequestCtx
-	appCtx context.Context
-	log    *zap.Logger
+	appCtx        context.Context
+	log           *zap.Logger
+	metrics       utils.Metrics
+	usage         *usage.Tracker
+	streams       *instrument.Streams
+	stallTimeout  time.Duration
+	signHeaders   bool
+	attributeCase string
+	canonical     *CanonicalLinks
+	// uniquePath is set if the object is requested by its FilePath and it's
+	// the only visible object with this path.
+	uniquePath  bool
+	externalURL string
+	timeout     time.Duration
+	transforms  *transform.Hooks
 }
 
 func isValidToken(s string) bool {
@@ -92,12 +120,11 @@ func readContentType(maxSize uint64, rInit func(uint64) (io.Reader, error)) (str
 	return http.DetectContentType(buf), buf, err // to not lose io.EOF
 }
 
-func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer user.Signer) {
+func (r request) receiveFile(clnt backend.Backend, objectAddress oid.Address, signer user.Signer) {
 	var (
-		err      error
-		dis      = "inline"
-		start    = time.Now()
-		filename string
+		err   error
+		dis   = "inline"
+		start = time.Now()
 	)
 	if err = tokens.StoreBearerToken(r.RequestCtx); err != nil {
 		r.log.Error("could not fetch and store bearer token", zap.Error(err))
@@ -106,19 +133,37 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer
 	}
 
 	var prm client.PrmObjectGet
-	if btoken := bearerToken(r.RequestCtx); btoken != nil {
+	btoken := bearerToken(r.RequestCtx)
+	if btoken != nil {
 		prm.WithBearerToken(*btoken)
 	}
 
-	hdr, payloadReader, err := clnt.ObjectGetInit(r.appCtx, objectAddress.Container(), objectAddress.Object(), signer, prm)
+	issuer, ok := enforceQuota(r.RequestCtx, r.log, r.usage, btoken)
+	if !ok {
+		return
+	}
+
+	// payload stream is canceled when the response is sent or the download stalls
+	ctx, cancel := r.requestContext()
+	if btoken == nil {
+		ctx = extcache.Shareable(ctx)
+	}
+
+	headDone := servertiming.Start(r.RequestCtx, servertiming.Head)
+	hdr, payloadReader, err := clnt.ObjectGetInit(ctx, objectAddress.Container(), objectAddress.Object(), signer, prm)
+	headDone()
 	if err != nil {
-		r.handleNeoFSErr(err, start)
+		cancel()
+		r.handleNeoFSErr(eacl.OperationGet, err, start)
 		return
 	}
+	streamDone, cancelStream := r.streams.Open(instrument.StreamGet), cancel
+	cancel = func() {
+		streamDone()
+		cancelStream()
+	}
 	var payload io.ReadCloser = payloadReader
 
-	// we can't close reader in this function, so how to do it?
-
 	if r.Request.URI().QueryArgs().GetBool("download") {
 		dis = "attachment"
 	}
@@ -126,46 +171,23 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer
 	payloadSize := hdr.PayloadSize()
 
 	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(payloadSize, 10))
-	var contentType string
-	for _, attr := range hdr.Attributes() {
-		key := attr.Key()
-		val := attr.Value()
-		if !isValidToken(key) || !isValidValue(val) {
-			continue
-		}
-		if strings.HasPrefix(key, utils.SystemAttributePrefix) {
-			key = systemBackwardTranslator(key)
-		}
-		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, val)
-		switch key {
-		case object.AttributeFileName:
-			filename = val
-		case object.AttributeTimestamp:
-			value, err := strconv.ParseInt(val, 10, 64)
-			if err != nil {
-				r.log.Info("couldn't parse creation date",
-					zap.String("key", key),
-					zap.String("val", val),
-					zap.Error(err))
-				continue
-			}
-			r.Response.Header.Set(fasthttp.HeaderLastModified,
-				time.Unix(value, 0).UTC().Format(http.TimeFormat))
-		case object.AttributeContentType:
-			contentType = val
-		}
-	}
-
+	filename, contentType := r.attributesToResponse(&hdr)
 	idsToResponse(&r.Response, &hdr)
+	r.signResponse(signer)
+	r.setCanonicalLink(&hdr)
 
 	if len(contentType) == 0 {
 		// determine the Content-Type from the payload head
 		var payloadHead []byte
 
+		payloadDone := servertiming.Start(r.RequestCtx, servertiming.Payload)
 		contentType, payloadHead, err = readContentType(payloadSize, func(uint64) (io.Reader, error) {
 			return payload, nil
 		})
+		payloadDone()
 		if err != nil && err != io.EOF {
+			_ = payload.Close()
+			cancel()
 			r.log.Error("could not detect Content-Type from payload", zap.Error(err))
 			response.Error(r.RequestCtx, "could not detect Content-Type from payload: "+err.Error(), fasthttp.StatusBadRequest)
 			return
@@ -184,9 +206,135 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer
 	}
 	r.SetContentType(contentType)
 
-	r.Response.Header.Set(fasthttp.HeaderContentDisposition, dis+"; filename="+path.Base(filename))
+	if filename != "" {
+		dis += "; filename=" + path.Base(filename)
+	}
+	r.Response.Header.Set(fasthttp.HeaderContentDisposition, dis)
+
+	if name := r.Request.URI().QueryArgs().Peek("transform"); len(name) != 0 {
+		r.transformPayload(ctx, string(name), payload, contentType, issuer, cancel)
+		return
+	}
+
+	if isHTML(contentType) && setHTMLValidator(r.RequestCtx, objectETag(objectAddress.Object())) {
+		_ = payload.Close()
+		cancel()
+		return
+	}
+
+	r.Response.Header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+	rng, ok := objectRequestedRange(r.RequestCtx, objectAddress.Object(), payloadSize)
+	if !ok {
+		_ = payload.Close()
+		cancel()
+		return
+	}
+	if rng != nil {
+		// the range is requested separately to not transfer the payload
+		// preceding it
+		_ = payload.Close()
+
+		var prmRange client.PrmObjectRange
+		if btoken != nil {
+			prmRange.WithBearerToken(*btoken)
+		}
+		rangeReader, err := clnt.ObjectRangeInit(ctx, objectAddress.Container(), objectAddress.Object(),
+			rng.start, uint64(rng.len()), signer, prmRange)
+		if err != nil {
+			cancel()
+			r.handleNeoFSErr(eacl.OperationRange, err, start)
+			return
+		}
+		rangeDone, cancelGet := r.streams.Open(instrument.StreamRange), cancel
+		cancel = func() {
+			rangeDone()
+			cancelGet()
+		}
+		payload = rangeReader
+		rng.setHeaders(r.RequestCtx, payloadSize)
+		payloadSize = uint64(rng.len())
+	}
+
+	r.sendPayload(payload, int(payloadSize), issuer, cancel)
+}
+
+// sendPayload streams the payload of the given size to the client accounting
+// it in metrics and usage of the identity (see enforceQuota). The stream is
+// aborted with cancel if it stalls or the identity byte quota runs out,
+// cancel is also called when the response is sent. Payload of negative size
+// is sent chunked.
+func (r request) sendPayload(payload io.ReadCloser, payloadSize int, issuer string, cancel func()) {
+	if issuer != "" && r.usage != nil {
+		r.usage.AddRequest(issuer)
+		payload = readCloser{r.usage.LimitReader(issuer, payload), payload}
+	}
+
+	conn := r.Conn()
+	progress := newProgressReader(payload, r.metrics, r.stallTimeout, func(reason string) {
+		r.log.Warn("download stalled, aborting",
+			zap.String("reason", reason),
+			zap.Duration("timeout", r.stallTimeout))
+		cancel()
+		if conn != nil {
+			_ = conn.Close()
+		}
+	})
 
-	r.Response.SetBodyStream(payload, int(payloadSize))
+	r.Response.SetBodyStream(readCloser{progress, closerFunc(func() error {
+		defer cancel()
+		return progress.Close()
+	})}, payloadSize)
+}
+
+// attributesToResponse sets response headers with the object attributes,
+// returns FileName and Content-Type attributes.
+func (r request) attributesToResponse(obj *object.Object) (filename string, contentType string) {
+	for _, attr := range obj.Attributes() {
+		key := attr.Key()
+		val := attr.Value()
+		if !isValidToken(key) || !isValidValue(val) {
+			continue
+		}
+		key = responseAttributeKey(key, r.attributeCase)
+		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, val)
+		switch key {
+		case object.AttributeFileName:
+			filename = val
+		case object.AttributeTimestamp:
+			value, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				r.log.Info("couldn't parse creation date",
+					zap.String("key", key),
+					zap.String("val", val),
+					zap.Error(err))
+				continue
+			}
+			r.Response.Header.Set(fasthttp.HeaderLastModified,
+				time.Unix(value, 0).UTC().Format(http.TimeFormat))
+		case object.AttributeContentType:
+			contentType = val
+		case attrContentLanguage:
+			r.Response.Header.Set(fasthttp.HeaderContentLanguage, val)
+		}
+	}
+	return filename, contentType
+}
+
+// requestContext returns the context of NeoFS operations made by the request
+// (see utils.RequestContext). It's canceled by the caller when the response is
+// sent.
+func (r request) requestContext() (context.Context, context.CancelFunc) {
+	return utils.RequestContext(r.appCtx, r.RequestCtx, r.timeout)
+}
+
+// responseAttributeKey returns the name of the response header with the
+// object attribute without the attribute prefix. User attribute keys are
+// canonicalized the same way as on upload.
+func responseAttributeKey(key, attributeCase string) string {
+	if strings.HasPrefix(key, utils.SystemAttributePrefix) {
+		return systemBackwardTranslator(key)
+	}
+	return utils.CanonicalAttributeKey(key, attributeCase)
 }
 
 // systemBackwardTranslator is used to convert headers looking like '__NEOFS__ATTR_NAME' to 'Neofs-Attr-Name'.
@@ -226,7 +374,32 @@ func bearerToken(ctx context.Context) *bearer.Token {
 	return nil
 }
 
-func (r *request) handleNeoFSErr(err error, start time.Time) {
+// tokenIssuer returns the bearer token issuer downloads are accounted for,
+// empty string if there is no token.
+func tokenIssuer(btoken *bearer.Token) string {
+	if btoken == nil {
+		return ""
+	}
+	return btoken.ResolveIssuer().EncodeToString()
+}
+
+// enforceQuota returns the identity the request is accounted for (see
+// usage.Tracker.Identity) and checks its quota. The request is responded with
+// false returned if it must not be served.
+func enforceQuota(c *fasthttp.RequestCtx, log *zap.Logger, tracker *usage.Tracker, btoken *bearer.Token) (string, bool) {
+	identity, ok := tracker.Identity(c, tokenIssuer(btoken))
+	if !ok {
+		log.Info("unknown API key")
+		return "", false
+	}
+	if identity != "" && !tracker.Enforce(c, identity) {
+		log.Info("quota exceeded", zap.String("identity", identity))
+		return "", false
+	}
+	return identity, true
+}
+
+func (r *request) handleNeoFSErr(op eacl.Operation, err error, start time.Time) {
 	r.log.Error(
 		"could not receive object",
 		zap.Stringer("elapsed", time.Since(start)),
@@ -239,24 +412,60 @@ func (r *request) handleNeoFSErr(err error, start time.Time) {
 		response.Error(r.RequestCtx, "Not Found", fasthttp.StatusNotFound)
 		return
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.Error(r.RequestCtx, "request timeout exceeded", fasthttp.StatusGatewayTimeout)
+		return
+	}
+	if errors.Is(err, streamlimit.ErrLimited) {
+		response.ErrorWithCode(r.RequestCtx, streamlimit.ErrCodeLimited, err.Error(), fasthttp.StatusTooManyRequests)
+		r.Response.Header.Set(fasthttp.HeaderRetryAfter, "1")
+		return
+	}
 
-	msg := fmt.Sprintf("could not receive object: %v", err)
-	response.Error(r.RequestCtx, msg, fasthttp.StatusBadRequest)
+	response.StorageError(r.RequestCtx, op, "could not receive object", err, fasthttp.StatusBadRequest)
 }
 
 // Downloader is a download request handler.
 type Downloader struct {
 	appCtx            context.Context
 	log               *zap.Logger
-	pool              *pool.Pool
+	backend           backend.Backend
 	containerResolver resolver.Resolver
 	settings          *Settings
 	signer            user.Signer
+	metrics           utils.Metrics
+	versions          *compat.Versions
+	usage             *usage.Tracker
+	purgers           *purge.Purgers
+	extCache          *extcache.Invalidator
+	streams           *instrument.Streams
+	zipBuffers        *instrument.BufferPool
+	zipPeers          *http.Client
+	zipSums           *zipChecksums
+	transforms        *transform.Hooks
+	resolutions       *attrcache.Cache
+	pathFilters       *pathfilter.Filters
 }
 
 // Settings stores reloading parameters, so it has to provide atomic getters and setters.
 type Settings struct {
 	zipCompression atomic.Bool
+	zipManifest    atomic.Bool
+	zipCache       atomic.Pointer[ZipCache]
+	zipCachePeers  atomic.Pointer[ZipCachePeers]
+	stallTimeout   atomic.Int64
+	signHeaders    atomic.Bool
+	purgeToken     atomic.Pointer[string]
+	attributeCase  atomic.Pointer[string]
+	browseEnabled  atomic.Bool
+	browseMax      atomic.Int32
+	previewEnabled atomic.Bool
+	previewMaxSize atomic.Uint64
+	language       atomic.Pointer[string]
+	tail           atomic.Pointer[TailConfig]
+	canonical      atomic.Pointer[CanonicalLinks]
+	externalURL    atomic.Pointer[string]
+	requestTimeout atomic.Int64
 }
 
 func (s *Settings) ZipCompression() bool {
@@ -267,24 +476,227 @@ func (s *Settings) SetZipCompression(val bool) {
 	s.zipCompression.Store(val)
 }
 
+// ZipManifest returns whether archives start with manifest.json entry.
+func (s *Settings) ZipManifest() bool {
+	return s.zipManifest.Load()
+}
+
+func (s *Settings) SetZipManifest(val bool) {
+	s.zipManifest.Store(val)
+}
+
+// ZipCache returns archive cache, nil if caching is disabled.
+func (s *Settings) ZipCache() *ZipCache {
+	return s.zipCache.Load()
+}
+
+func (s *Settings) SetZipCache(c *ZipCache) {
+	s.zipCache.Store(c)
+}
+
+// ZipCachePeers returns gateways sharing the archive cache, nil if the cache
+// isn't shared.
+func (s *Settings) ZipCachePeers() *ZipCachePeers {
+	return s.zipCachePeers.Load()
+}
+
+func (s *Settings) SetZipCachePeers(p *ZipCachePeers) {
+	s.zipCachePeers.Store(p)
+}
+
+// StallTimeout returns the time a download can make no progress before it's
+// aborted, zero means no limit.
+func (s *Settings) StallTimeout() time.Duration {
+	return time.Duration(s.stallTimeout.Load())
+}
+
+func (s *Settings) SetStallTimeout(val time.Duration) {
+	s.stallTimeout.Store(int64(val))
+}
+
+// SignHeaders checks whether object identity headers are signed with the gate key.
+func (s *Settings) SignHeaders() bool {
+	return s.signHeaders.Load()
+}
+
+func (s *Settings) SetSignHeaders(val bool) {
+	s.signHeaders.Store(val)
+}
+
+// PurgeToken returns the token required for purge requests, empty if purge is
+// disabled.
+func (s *Settings) PurgeToken() string {
+	if t := s.purgeToken.Load(); t != nil {
+		return *t
+	}
+	return ""
+}
+
+func (s *Settings) SetPurgeToken(val string) {
+	s.purgeToken.Store(&val)
+}
+
+// AttributeCase returns canonicalization mode of attribute keys in response
+// headers.
+func (s *Settings) AttributeCase() string {
+	if mode := s.attributeCase.Load(); mode != nil {
+		return *mode
+	}
+	return utils.AttributeCasePreserve
+}
+
+func (s *Settings) SetAttributeCase(val string) {
+	s.attributeCase.Store(&val)
+}
+
+// BrowseEnabled checks whether HTML listing of containers is served.
+func (s *Settings) BrowseEnabled() bool {
+	return s.browseEnabled.Load()
+}
+
+func (s *Settings) SetBrowseEnabled(val bool) {
+	s.browseEnabled.Store(val)
+}
+
+// BrowseMaxObjects returns the maximum number of objects listed on a page,
+// zero means no limit.
+func (s *Settings) BrowseMaxObjects() int {
+	return int(s.browseMax.Load())
+}
+
+func (s *Settings) SetBrowseMaxObjects(val int) {
+	s.browseMax.Store(int32(val))
+}
+
+// PreviewEnabled checks whether HTML previews of objects are served.
+func (s *Settings) PreviewEnabled() bool {
+	return s.previewEnabled.Load()
+}
+
+func (s *Settings) SetPreviewEnabled(val bool) {
+	s.previewEnabled.Store(val)
+}
+
+// PreviewMaxSize returns the maximum size of text payloads shown in previews.
+func (s *Settings) PreviewMaxSize() uint64 {
+	return s.previewMaxSize.Load()
+}
+
+func (s *Settings) SetPreviewMaxSize(val uint64) {
+	s.previewMaxSize.Store(val)
+}
+
+// DefaultLanguage returns the language of the document variant served if
+// none of the variants is acceptable for the client, empty if not set.
+func (s *Settings) DefaultLanguage() string {
+	if lang := s.language.Load(); lang != nil {
+		return *lang
+	}
+	return ""
+}
+
+func (s *Settings) SetDefaultLanguage(val string) {
+	s.language.Store(&val)
+}
+
+// Tail returns parameters of log objects tailing, nil if tailing is
+// disabled.
+func (s *Settings) Tail() *TailConfig {
+	return s.tail.Load()
+}
+
+func (s *Settings) SetTail(cfg *TailConfig) {
+	s.tail.Store(cfg)
+}
+
+// CanonicalLinks returns canonical links builder, nil if canonical links
+// aren't set.
+func (s *Settings) CanonicalLinks() *CanonicalLinks {
+	return s.canonical.Load()
+}
+
+func (s *Settings) SetCanonicalLinks(l *CanonicalLinks) {
+	s.canonical.Store(l)
+}
+
+// ExternalURL returns the base URL the gate is reachable at by clients,
+// empty if it's not configured.
+func (s *Settings) ExternalURL() string {
+	if u := s.externalURL.Load(); u != nil {
+		return *u
+	}
+	return ""
+}
+
+func (s *Settings) SetExternalURL(val string) {
+	val = strings.TrimSuffix(val, "/")
+	s.externalURL.Store(&val)
+}
+
+// RequestTimeout returns the maximum duration of object requests including
+// the payload transfer, zero means no limit.
+func (s *Settings) RequestTimeout() time.Duration {
+	return time.Duration(s.requestTimeout.Load())
+}
+
+func (s *Settings) SetRequestTimeout(val time.Duration) {
+	s.requestTimeout.Store(int64(val))
+}
+
 // New creates an instance of Downloader using specified options.
 func New(ctx context.Context, params *utils.AppParams, settings *Settings, signer user.Signer) *Downloader {
 	return &Downloader{
 		appCtx:            ctx,
 		log:               params.Logger,
-		pool:              params.Pool,
+		backend:           params.Backend,
 		settings:          settings,
 		containerResolver: params.Resolver,
 		signer:            signer,
+		metrics:           params.Metrics,
+		versions:          params.Versions,
+		usage:             params.Usage,
+		purgers:           params.Purgers,
+		extCache:          params.ExtCache,
+		streams:           params.Streams,
+		zipBuffers:        params.ZipBuffers,
+		zipPeers:          newZipPeerClient(),
+		zipSums:           newZipChecksums(),
+		transforms:        params.Transforms,
+		resolutions:       params.Resolutions,
+		pathFilters:       params.PathFilters,
+	}
+}
+
+// supports checks whether the feature is supported by connected nodes and
+// responds with 501 Not Implemented otherwise.
+func (d *Downloader) supports(c *fasthttp.RequestCtx, log *zap.Logger, f compat.Feature) bool {
+	if err := d.versions.Unsupported(f); err != nil {
+		log.Error("feature is not supported", zap.Error(err))
+		response.ErrorWithCode(c, compat.ErrCodeUnsupported, err.Error(), fasthttp.StatusNotImplemented)
+		return false
 	}
+	return true
 }
 
 func (d *Downloader) newRequest(ctx *fasthttp.RequestCtx, log *zap.Logger) *request {
-	return &request{
+	r := &request{
 		RequestCtx: ctx,
 		appCtx:     d.appCtx,
 		log:        log,
+		metrics:    d.metrics,
+		usage:      d.usage,
+		streams:    d.streams,
+		transforms: d.transforms,
+	}
+	if d.settings != nil {
+		r.stallTimeout = d.settings.StallTimeout()
+		r.signHeaders = d.settings.SignHeaders()
+		r.attributeCase = d.settings.AttributeCase()
+		r.canonical = d.settings.CanonicalLinks()
+		r.externalURL = d.settings.ExternalURL()
+		r.timeout = d.settings.RequestTimeout()
 	}
+	return r
 }
 
 // DownloadByAddress handles download requests using simple cid/oid format.
@@ -294,22 +706,23 @@ func (d *Downloader) DownloadByAddress(c *fasthttp.RequestCtx) {
 
 // byAddress is a wrapper for function (e.g. request.headObject, request.receiveFile) that
 // prepares request and object address to it.
-func (d *Downloader) byAddress(c *fasthttp.RequestCtx, f func(request, *pool.Pool, oid.Address, user.Signer)) {
+func (d *Downloader) byAddress(c *fasthttp.RequestCtx, f func(request, backend.Backend, oid.Address, user.Signer)) {
 	var (
 		idCnr, _ = c.UserValue("cid").(string)
 		idObj, _ = c.UserValue("oid").(string)
 		log      = d.log.With(zap.String("cid", idCnr), zap.String("oid", idObj))
 	)
 
+	resolveDone := servertiming.Start(c, servertiming.Resolve)
 	cnrID, err := utils.GetContainerID(d.appCtx, idCnr, d.containerResolver)
+	resolveDone()
 	if err != nil {
-		log.Error("wrong container id", zap.Error(err))
-		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		utils.ContainerIDError(c, log, err)
 		return
 	}
 
-	objID := new(oid.ID)
-	if err = objID.DecodeString(idObj); err != nil {
+	objID, err := utils.DecodeObjectID(idObj)
+	if err != nil {
 		log.Error("wrong object id", zap.Error(err))
 		response.Error(c, "wrong object id", fasthttp.StatusBadRequest)
 		return
@@ -317,9 +730,37 @@ func (d *Downloader) byAddress(c *fasthttp.RequestCtx, f func(request, *pool.Poo
 
 	var addr oid.Address
 	addr.SetContainer(*cnrID)
-	addr.SetObject(*objID)
+	addr.SetObject(objID)
+
+	f(*d.newRequest(c, log), d.backend, addr, d.signer)
+}
+
+// DownloadByAddressString handles download requests using single string
+// address format (e.g. cid:oid or neofs://cid/oid).
+func (d *Downloader) DownloadByAddressString(c *fasthttp.RequestCtx) {
+	d.byAddressString(c, request.receiveFile)
+}
+
+// byAddressString is a wrapper similar to byAddress, but it takes an object
+// address from a single route parameter.
+func (d *Downloader) byAddressString(c *fasthttp.RequestCtx, f func(request, backend.Backend, oid.Address, user.Signer)) {
+	rawAddr, _ := c.UserValue("address").(string)
+	addr, err := url.PathUnescape(rawAddr)
+	if err != nil {
+		addr = rawAddr
+	}
+
+	idCnr, idObj, err := utils.SplitAddress(addr)
+	if err != nil {
+		d.log.Error("wrong object address", zap.String("address", addr), zap.Error(err))
+		response.Error(c, "wrong object address", fasthttp.StatusBadRequest)
+		return
+	}
+
+	c.SetUserValue("cid", idCnr)
+	c.SetUserValue("oid", idObj)
 
-	f(*d.newRequest(c, log), d.pool, addr, d.signer)
+	d.byAddress(c, f)
 }
 
 // DownloadByAttribute handles attribute-based download requests.
@@ -328,7 +769,7 @@ func (d *Downloader) DownloadByAttribute(c *fasthttp.RequestCtx) {
 }
 
 // byAttribute is a wrapper similar to byAddress.
-func (d *Downloader) byAttribute(c *fasthttp.RequestCtx, f func(request, *pool.Pool, oid.Address, user.Signer)) {
+func (d *Downloader) byAttribute(c *fasthttp.RequestCtx, f func(request, backend.Backend, oid.Address, user.Signer)) {
 	var (
 		scid, _ = c.UserValue("cid").(string)
 		key, _  = url.QueryUnescape(c.UserValue("attr_key").(string))
@@ -336,80 +777,203 @@ func (d *Downloader) byAttribute(c *fasthttp.RequestCtx, f func(request, *pool.P
 		log     = d.log.With(zap.String("cid", scid), zap.String("attr_key", key), zap.String("attr_val", val))
 	)
 
+	resolveDone := servertiming.Start(c, servertiming.Resolve)
 	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	resolveDone()
 	if err != nil {
-		log.Error("wrong container id", zap.Error(err))
-		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		utils.ContainerIDError(c, log, err)
 		return
 	}
 
-	res, err := d.search(c, containerID, key, val, object.MatchStringEqual)
-	if err != nil {
-		log.Error("could not search for objects", zap.Error(err))
-		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+	if pinned := c.Request.Header.Peek(hdrObjectID); len(pinned) != 0 {
+		objID, ok := d.pinnedObject(c, log, *containerID, key, val, string(pinned))
+		if !ok {
+			return
+		}
+
+		var addrObj oid.Address
+		addrObj.SetContainer(*containerID)
+		addrObj.SetObject(objID)
+
+		f(*d.newRequest(c, log), d.backend, addrObj, d.signer)
 		return
 	}
 
-	defer res.Close()
-
-	buf := make([]oid.ID, 1)
+	// documents with the same path can be versions of the file or variants
+	// in different languages
+	size := 1
+	if key == object.AttributeFilePath {
+		size = maxPathVersions
+	}
 
-	n, _ := res.Read(buf)
-	if n == 0 {
-		err = res.Close()
+	searchDone := servertiming.Start(c, servertiming.Search)
+	ids, ok := d.findByAttribute(c, log, *containerID, key, val, size)
+	searchDone()
+	if !ok {
+		return
+	}
+	if len(ids) == 0 {
+		log.Error("object not found")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return
+	}
 
-		if err == nil || errors.Is(err, io.EOF) {
-			log.Error("object not found", zap.Error(err))
-			response.Error(c, "object not found", fasthttp.StatusNotFound)
+	var (
+		objID      = ids[0]
+		uniquePath bool
+	)
+	if key == object.AttributeFilePath {
+		// even the only object can be a delete marker
+		if len(ids) == size {
+			log.Warn("too many objects with the same path, the newest one can be missed", zap.Int("limit", size))
+		}
+		if objID, uniquePath, ok = d.languageVariant(c, log, *containerID, ids); !ok {
 			return
 		}
-
-		log.Error("read object list failed", zap.Error(err))
-		response.Error(c, "read object list failed: "+err.Error(), fasthttp.StatusBadRequest)
-		return
+		uniquePath = uniquePath && len(ids) < size
 	}
 
 	var addrObj oid.Address
 	addrObj.SetContainer(*containerID)
-	addrObj.SetObject(buf[0])
+	addrObj.SetObject(objID)
 
-	f(*d.newRequest(c, log), d.pool, addrObj, d.signer)
+	r := d.newRequest(c, log)
+	r.uniquePath = uniquePath
+	f(*r, d.backend, addrObj, d.signer)
 }
 
-func (d *Downloader) search(c *fasthttp.RequestCtx, cid *cid.ID, key, val string, op object.SearchMatchType) (*client.ObjectListReader, error) {
+// findByAttribute returns up to size IDs of objects having the attribute
+// value, they're empty if there are no such objects. Results of requests
+// without bearer token are cached, paths missing in the container path filter
+// aren't searched for. Search failures (including the ones after some IDs are
+// read) are responded.
+func (d *Downloader) findByAttribute(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, key, val string, size int) ([]oid.ID, bool) {
+	public := bearerToken(c) == nil
+	if public && d.pathFilters != nil && key == object.AttributeFilePath &&
+		!d.pathFilters.MayContain(cnrID, val, d.filePaths) {
+		log.Debug("path is missing in the container path filter")
+		return nil, true
+	}
+
+	cacheable := d.resolutions != nil && public
+	if cacheable {
+		if ids, ok := d.resolutions.Get(cnrID, key, val); ok {
+			return ids, true
+		}
+	}
+
+	res, err := d.search(c, &cnrID, key, val, object.MatchStringEqual)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return nil, false
+	}
+
+	defer res.Close()
+
+	var (
+		buf     = make([]oid.ID, size)
+		n       int
+		readErr error
+	)
+	for n < len(buf) {
+		var read int
+		read, readErr = res.Read(buf[n:])
+		n += read
+		if readErr != nil || read == 0 {
+			break
+		}
+	}
+	// incomplete results can miss the newest object, so they're neither
+	// served nor cached
+	err = readErr
+	if n == 0 && (err == nil || errors.Is(err, io.EOF)) {
+		err = res.Close()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Error("read object list failed", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "read object list failed", err, searchErrorStatus(err))
+		return nil, false
+	}
+
+	if cacheable {
+		d.resolutions.Put(cnrID, key, val, buf[:n])
+	}
+	return buf[:n], true
+}
+
+func (d *Downloader) search(c *fasthttp.RequestCtx, cid *cid.ID, key, val string, op object.SearchMatchType) (backend.ObjectLister, error) {
 	filters := object.NewSearchFilters()
 	filters.AddRootFilter()
 	filters.AddFilter(key, val, op)
 
+	return d.searchByFilters(c, cid, filters)
+}
+
+func (d *Downloader) searchByFilters(c *fasthttp.RequestCtx, cid *cid.ID, filters object.SearchFilters) (backend.ObjectLister, error) {
 	var prm client.PrmObjectSearch
-	prm.SetFilters(filters)
 	if btoken := bearerToken(c); btoken != nil {
 		prm.WithBearerToken(*btoken)
 	}
 
-	return d.pool.ObjectSearchInit(d.appCtx, *cid, d.signer, prm)
+	return searchlimit.Search(d.appCtx, searchlimit.Load(c), func(ctx context.Context) (backend.ObjectLister, error) {
+		return d.backend.ObjectSearchInit(ctx, *cid, d.signer, filters, prm)
+	})
+}
+
+// searchErrorStatus returns the response status of the failed search,
+// searches interrupted by the search timeout get 504 Gateway Timeout.
+func searchErrorStatus(err error) int {
+	if errors.Is(err, searchlimit.ErrTimeout) {
+		return fasthttp.StatusGatewayTimeout
+	}
+	return fasthttp.StatusBadRequest
+}
+
+// storageErrorStatus returns the response status of the failed storage
+// request, timeouts get 504 Gateway Timeout and other storage failures get
+// 502 Bad Gateway.
+func storageErrorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fasthttp.StatusGatewayTimeout
+	}
+	return fasthttp.StatusBadGateway
 }
 
 func (d *Downloader) getContainer(cnrID cid.ID) (container.Container, error) {
-	return d.pool.ContainerGet(d.appCtx, cnrID, client.PrmContainerGet{})
+	return d.backend.ContainerGet(d.appCtx, cnrID, client.PrmContainerGet{})
 }
 
-func (d *Downloader) addObjectToZip(zw *zip.Writer, obj *object.Object) (io.Writer, error) {
-	method := zip.Store
-	if d.settings.ZipCompression() {
-		method = zip.Deflate
+// zipFileHeader returns the archive entry header of the object. Entries are
+// modified at the object Timestamp to be reproducible, so the archive can be
+// resumed.
+func zipFileHeader(obj *object.Object, compression bool) (zipstream.FileHeader, error) {
+	method := zipstream.Store
+	if compression {
+		method = zipstream.Deflate
+	}
+
+	filePath, err := sanitizeZipPath(getZipFilePath(obj))
+	if err != nil {
+		return zipstream.FileHeader{}, err
 	}
 
-	filePath := getZipFilePath(obj)
-	if len(filePath) == 0 || filePath[len(filePath)-1] == '/' {
-		return nil, fmt.Errorf("invalid filepath '%s'", filePath)
+	var modified time.Time
+	for _, attr := range obj.Attributes() {
+		if attr.Key() == object.AttributeTimestamp {
+			if value, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				modified = time.Unix(value, 0)
+			}
+			break
+		}
 	}
 
-	return zw.CreateHeader(&zip.FileHeader{
+	return zipstream.FileHeader{
 		Name:     filePath,
 		Method:   method,
-		Modified: time.Now(),
-	})
+		Modified: modified,
+		Size:     obj.PayloadSize(),
+	}, nil
 }
 
 // DownloadZipped handles zip by prefix requests.
@@ -420,8 +984,7 @@ func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
 
 	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
 	if err != nil {
-		log.Error("wrong container id", zap.Error(err))
-		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		utils.ContainerIDError(c, log, err)
 		return
 	}
 
@@ -431,6 +994,23 @@ func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
 		return
 	}
 
+	btoken := bearerToken(c)
+	issuer, ok := enforceQuota(c, log, d.usage, btoken)
+	if !ok {
+		return
+	}
+
+	if btoken == nil {
+		if peer, ok := d.zipCacheOwner(c, *containerID, prefix); ok {
+			if err = d.forwardZip(c, d.settings.ZipCachePeers(), peer); err == nil {
+				log.Debug("archive request forwarded to cache owner", zap.String("peer", peer))
+				return
+			}
+			log.Warn("could not forward archive request to cache owner, serving it locally",
+				zap.String("peer", peer), zap.Error(err))
+		}
+	}
+
 	// check if container exists here to be able to return 404 error,
 	// otherwise we get this error only in object iteration step
 	// and client get 200 OK.
@@ -447,82 +1027,355 @@ func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
 	resSearch, err := d.search(c, containerID, object.AttributeFilePath, prefix, object.MatchCommonPrefix)
 	if err != nil {
 		log.Error("could not search for objects", zap.Error(err))
-		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
 		return
 	}
 
-	c.Response.Header.Set(fasthttp.HeaderContentType, "application/zip")
-	c.Response.Header.Set(fasthttp.HeaderContentDisposition, "attachment; filename=\"archive.zip\"")
-	c.Response.SetStatusCode(http.StatusOK)
+	var ids []oid.ID
+	err = resSearch.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	})
+	_ = resSearch.Close()
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return
+	}
 
-	c.SetBodyStreamWriter(func(w *bufio.Writer) {
-		defer resSearch.Close()
+	compression, manifest := d.settings.ZipCompression(), d.settings.ZipManifest()
+	key := zipCacheKey(*containerID, prefix, compression, manifest, ids)
 
-		zipWriter := zip.NewWriter(w)
+	// Compressed archives can't be resumed, since their size is unknown
+	// until they are generated.
+	var rng *byteRange
+	if !compression {
+		var ok bool
+		if rng, ok = zipRequestedRange(c, key); !ok {
+			log.Debug("archive range can't be served", zap.String("key", key))
+			return
+		}
+	}
 
-		var bufZip []byte
-		var addr oid.Address
+	// Archives are cached for public requests only, data available with
+	// bearer token must not be served to other clients.
+	cache := d.settings.ZipCache()
+	if cache != nil && btoken == nil {
+		if f, size, ok := cache.Get(key); ok {
+			log.Debug("serve cached archive", zap.String("key", key))
+			d.serveCachedZip(c, f, size, rng, *containerID, key, compression)
+			return
+		}
+	}
 
-		empty := true
-		called := false
-		btoken := bearerToken(c)
-		addr.SetContainer(*containerID)
+	// Entries are sorted, so the same set of objects always produces the same
+	// archive that can be cached and resumed: by paths if the manifest lists
+	// them, by IDs otherwise. Headers are read in advance only if the manifest
+	// or the archive size is needed, otherwise they come with payloads.
+	sortObjectIDs(ids)
+	entries := zipObjectEntries(ids)
+	if manifest || rng != nil {
+		if entries, err = d.zipEntries(*containerID, ids, btoken, compression, log); err != nil {
+			log.Error("could not get objects headers", zap.Error(err))
+			response.StorageError(c, eacl.OperationHead, "could not get objects headers", err, storageErrorStatus(err))
+			return
+		}
+		if manifest {
+			sortZipEntries(entries)
+		}
+	}
 
-		errIter := resSearch.Iterate(func(id oid.ID) bool {
-			called = true
+	var manifestData []byte
+	if manifest {
+		if manifestData, err = zipManifestData(*containerID, prefix, entries); err != nil {
+			log.Error("could not encode archive manifest", zap.Error(err))
+			response.Error(c, "could not encode archive manifest: "+err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+	}
 
-			if empty {
-				bufZip = make([]byte, 3<<20) // the same as for upload
+	var size uint64
+	if rng != nil {
+		if size, err = zipStoredSize(entries, manifestData); err != nil {
+			log.Error("could not calculate archive size", zap.Error(err))
+			response.Error(c, "could not calculate archive size: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		if !rng.limit(c, size) {
+			return
+		}
+	}
+
+	var cacheWriter *zipCacheWriter
+	if cache != nil && btoken == nil && len(entries) != 0 && rng == nil {
+		if cacheWriter, err = cache.NewWriter(key, *containerID); err != nil {
+			log.Warn("could not create archive cache entry", zap.Error(err))
+		}
+	}
+
+	if issuer != "" {
+		d.usage.AddRequest(issuer)
+	}
+
+	setZipHeaders(c, *containerID)
+	if !compression {
+		setZipResumeHeaders(c, key)
+	}
+
+	countUsage := func(w io.Writer) io.Writer {
+		if issuer == "" {
+			return w
+		}
+		return d.usage.LimitWriter(issuer, w)
+	}
+
+	if rng != nil {
+		log.Debug("resume archive", zap.String("key", key), zap.Uint64("start", rng.start), zap.Uint64("end", rng.end))
+		rng.setHeaders(c, size)
+
+		pr, pw := io.Pipe()
+		go func() {
+			d.writeZip(&rangeWriter{w: countUsage(pw), r: *rng}, rng.start, *containerID, entries, manifestData, btoken, compression, log)
+			_ = pw.Close()
+		}()
+		c.SetBodyStream(pr, rng.len())
+		return
+	}
+
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		out := countUsage(w)
+		if cacheWriter != nil {
+			// cache write failures are reported on commit only, so they
+			// never interrupt the download
+			out = io.MultiWriter(out, cacheWriter)
+		}
+
+		complete := d.writeZip(out, 0, *containerID, entries, manifestData, btoken, compression, log)
+		if cacheWriter == nil {
+			return
+		}
+		// incomplete archive must not be served from cache
+		if !complete {
+			cacheWriter.Abort()
+			return
+		}
+		if err := cacheWriter.Commit(); err != nil {
+			if errors.Is(err, errZipCacheEntryTooBig) {
+				log.Debug("archive is too big to be cached", zap.String("key", key))
+				return
 			}
-			empty = false
+			log.Warn("could not cache archive", zap.Error(err))
+		}
+	})
+}
+
+// writeZip writes the archive of the objects starting with the manifest if it's
+// not nil. Returns false if some objects weren't added or writing was
+// interrupted. If the archive is resumed from the non-zero start offset, out
+// must implement zipstream.Skipper.
+func (d *Downloader) writeZip(out io.Writer, start uint64, cnrID cid.ID, entries []zipEntry, manifest []byte, btoken *bearer.Token, compression bool, log *zap.Logger) bool {
+	if len(entries) == 0 {
+		log.Error("objects not found")
+	}
+
+	zipWriter := zipstream.NewWriter(out)
+	complete := true
 
-			addr.SetObject(id)
-			if err = d.zipObject(zipWriter, addr, btoken, bufZip); err != nil {
-				log.Error("failed to add object to archive", zap.String("oid", id.EncodeToString()), zap.Error(err))
+	if manifest != nil {
+		if err := zipManifestTo(zipWriter, manifest, compression); err != nil {
+			if !errors.Is(err, errRangeWritten) {
+				log.Error("failed to add manifest to archive", zap.Error(err))
 			}
+			return false
+		}
+	}
+
+	var bufZip []byte
+	if len(entries) != 0 {
+		buf := d.getZipBuffer()
+		defer d.putZipBuffer(buf)
+		bufZip = *buf
+	}
+
+	var addr oid.Address
+	addr.SetContainer(cnrID)
 
+	for _, e := range entries {
+		id := e.id
+		addr.SetObject(id)
+		err := d.zipObject(zipWriter, addr, e, btoken, compression, bufZip, start)
+		if errors.Is(err, errRangeWritten) {
 			return false
-		})
-		if errIter != nil {
-			log.Error("iterating over selected objects failed", zap.Error(errIter))
-		} else if !called {
-			log.Error("objects not found")
 		}
+		if errors.Is(err, errZipObjectSkipped) {
+			log.Debug("skip object", zap.String("oid", id.EncodeToString()), zap.Error(err))
+			continue
+		}
+		if errors.Is(err, io.ErrClosedPipe) {
+			log.Debug("client has gone away", zap.Error(err))
+			return false
+		}
+		if err != nil {
+			log.Error("failed to add object to archive", zap.String("oid", id.EncodeToString()), zap.Error(err))
+			complete = false
+		}
+	}
 
-		if err = zipWriter.Close(); err != nil {
+	if err := zipWriter.Close(); err != nil {
+		if !errors.Is(err, errRangeWritten) {
 			log.Error("close zip writer", zap.Error(err))
 		}
-	})
+		return false
+	}
+
+	return complete
 }
 
-func (d *Downloader) zipObject(zipWriter *zip.Writer, addr oid.Address, btoken *bearer.Token, bufZip []byte) error {
-	var prm client.PrmObjectGet
-	if btoken != nil {
-		prm.WithBearerToken(*btoken)
+// serveCachedZip sends the cached archive or its range.
+func (d *Downloader) serveCachedZip(c *fasthttp.RequestCtx, f *os.File, size int64, rng *byteRange, cnrID cid.ID, key string, compression bool) {
+	if rng == nil {
+		setZipHeaders(c, cnrID)
+		if !compression {
+			setZipResumeHeaders(c, key)
+		}
+		c.SetBodyStream(f, int(size))
+		return
+	}
+
+	if !rng.limit(c, uint64(size)) {
+		_ = f.Close()
+		return
+	}
+	if _, err := f.Seek(int64(rng.start), io.SeekStart); err != nil {
+		_ = f.Close()
+		d.log.Error("could not seek cached archive", zap.String("key", key), zap.Error(err))
+		response.Error(c, "could not read cached archive: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	setZipHeaders(c, cnrID)
+	setZipResumeHeaders(c, key)
+	rng.setHeaders(c, uint64(size))
+	c.SetBodyStream(struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, int64(rng.len())), f}, rng.len())
+}
+
+func setZipHeaders(c *fasthttp.RequestCtx, cnrID cid.ID) {
+	surrogateKeysToResponse(&c.Response, cnrID.EncodeToString())
+	c.Response.Header.Set(fasthttp.HeaderContentType, "application/zip")
+	c.Response.Header.Set(fasthttp.HeaderContentDisposition, "attachment; filename=\"archive.zip\"")
+	c.Response.SetStatusCode(http.StatusOK)
+}
+
+// ZipBufferSize is a size of buffers objects are copied to archives with.
+const ZipBufferSize = 3 << 20 // the same as for upload
+
+// getZipBuffer takes the buffer from the pool if it's configured.
+func (d *Downloader) getZipBuffer() *[]byte {
+	if d.zipBuffers == nil {
+		buf := make([]byte, ZipBufferSize)
+		return &buf
+	}
+	return d.zipBuffers.Get()
+}
+
+func (d *Downloader) putZipBuffer(buf *[]byte) {
+	if d.zipBuffers != nil {
+		d.zipBuffers.Put(buf)
+	}
+}
+
+// errZipObjectSkipped is returned when the object read along with its header
+// isn't added to the archive, since it has been removed after search or has
+// invalid FilePath, as zipEntries does.
+var errZipObjectSkipped = errors.New("object skipped")
+
+// zipObject adds the object to the archive. Payload preceding the start
+// offset of the resumed archive isn't read if its checksum is known: objects
+// ending before the start aren't read at all, the object containing it is read
+// from it.
+func (d *Downloader) zipObject(zipWriter *zipstream.Writer, addr oid.Address, e zipEntry, btoken *bearer.Token, compression bool, bufZip []byte, start uint64) error {
+	fh := e.header
+	var skip uint64
+	crc, known := d.zipSums.get(addr)
+	if dataStart := zipWriter.DataOffset(fh); known && fh.Method == zipstream.Store && dataStart < start {
+		skip = start - dataStart
+		if skip > fh.Size {
+			skip = fh.Size
+		}
+	}
+	if skip != 0 && skip == fh.Size {
+		if _, err := zipWriter.CreateAt(fh, crc, skip); err != nil {
+			return fmt.Errorf("zip create header: %w", err)
+		}
+		return nil
 	}
 
-	resGet, payloadReader, err := d.pool.ObjectGetInit(d.appCtx, addr.Container(), addr.Object(), d.signer, prm)
+	var (
+		payloadReader io.ReadCloser
+		err           error
+	)
+	if skip != 0 {
+		var prm client.PrmObjectRange
+		if btoken != nil {
+			prm.WithBearerToken(*btoken)
+		}
+		payloadReader, err = d.backend.ObjectRangeInit(d.appCtx, addr.Container(), addr.Object(), skip, fh.Size-skip, d.signer, prm)
+	} else {
+		var prm client.PrmObjectGet
+		if btoken != nil {
+			prm.WithBearerToken(*btoken)
+		}
+		var hdr object.Object
+		hdr, payloadReader, err = d.backend.ObjectGetInit(d.appCtx, addr.Container(), addr.Object(), d.signer, prm)
+		if err != nil && !e.headed && (errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved)) {
+			return fmt.Errorf("%w: %v", errZipObjectSkipped, err)
+		}
+		if err == nil && !e.headed {
+			if fh, err = zipFileHeader(&hdr, compression); err != nil {
+				_ = payloadReader.Close()
+				return fmt.Errorf("%w: %v", errZipObjectSkipped, err)
+			}
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("get NeoFS object: %v", err)
 	}
+	defer d.streams.Open(instrument.StreamZip)()
 
-	objWriter, err := d.addObjectToZip(zipWriter, &resGet)
+	var objWriter io.Writer
+	if skip != 0 {
+		objWriter, err = zipWriter.CreateAt(fh, crc, skip)
+	} else {
+		objWriter, err = zipWriter.Create(fh)
+	}
 	if err != nil {
-		return fmt.Errorf("zip create header: %v", err)
+		_ = payloadReader.Close()
+		return fmt.Errorf("zip create header: %w", err)
+	}
+
+	// checksum is remembered if the whole payload is written only
+	var sum hash.Hash32
+	if skip == 0 && fh.Method == zipstream.Store {
+		sum = crc32.NewIEEE()
+		objWriter = io.MultiWriter(objWriter, sum)
 	}
 
-	if _, err = io.CopyBuffer(objWriter, payloadReader, bufZip); err != nil {
-		return fmt.Errorf("copy object payload to zip file: %v", err)
+	n, err := io.CopyBuffer(objWriter, payloadReader, bufZip)
+	if err != nil {
+		_ = payloadReader.Close()
+		return fmt.Errorf("copy object payload to zip file: %w", err)
 	}
 
 	if err = payloadReader.Close(); err != nil {
 		return fmt.Errorf("object body close error: %w", err)
 	}
 
-	if err = zipWriter.Flush(); err != nil {
-		return fmt.Errorf("flush zip writer: %v", err)
+	if sum != nil && uint64(n) == fh.Size {
+		d.zipSums.put(addr, sum.Sum32())
 	}
-
 	return nil
 }
 
@@ -535,3 +1388,23 @@ func getZipFilePath(obj *object.Object) string {
 
 	return ""
 }
+
+// sanitizeZipPath returns the archive entry name of FilePath, so it can't be
+// extracted outside of the destination directory (zip slip). Backslashes are
+// treated as separators, '.' and '..' elements are collapsed, drive letters
+// and leading slashes are removed. Directory paths and paths which are empty
+// after sanitizing are rejected.
+func sanitizeZipPath(filePath string) (string, error) {
+	p := strings.ReplaceAll(filePath, `\`, "/")
+	if p == "" || p[len(p)-1] == '/' {
+		return "", fmt.Errorf("invalid filepath '%s'", filePath)
+	}
+	if len(p) >= 2 && p[1] == ':' && ('a' <= p[0] && p[0] <= 'z' || 'A' <= p[0] && p[0] <= 'Z') {
+		p = p[2:]
+	}
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "", fmt.Errorf("invalid filepath '%s'", filePath)
+	}
+	return p, nil
+}
@@ -1,9 +1,28 @@
 package downloader
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"testing"
+	"time"
 
+	"github.com/nspcc-dev/neofs-http-gw/attrcache"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/pathfilter"
+	"github.com/nspcc-dev/neofs-http-gw/searchlimit"
+	"github.com/nspcc-dev/neofs-http-gw/servertiming"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
 )
 
 func TestSystemBackwardTranslator(t *testing.T) {
@@ -21,3 +40,238 @@ func TestSystemBackwardTranslator(t *testing.T) {
 		require.Equal(t, expected[i], res)
 	}
 }
+
+func TestResponseAttributeKey(t *testing.T) {
+	require.Equal(t, "Neofs-Expiration-Epoch", responseAttributeKey("__NEOFS__EXPIRATION_EPOCH", utils.AttributeCaseCanonical))
+	require.Equal(t, "filename", responseAttributeKey("filename", utils.AttributeCasePreserve))
+	require.Equal(t, "FileName", responseAttributeKey("filename", utils.AttributeCaseKnown))
+	require.Equal(t, "My-Tag", responseAttributeKey("my-tag", utils.AttributeCaseCanonical))
+}
+
+func TestDownloadByAttribute(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	var attr object.Attribute
+	attr.SetKey(object.AttributeFileName)
+	attr.SetValue("cat.jpg")
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	hdr.SetAttributes(attr)
+
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte("meow"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+	download := func(name string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFileName)
+		c.SetUserValue("attr_val", name)
+		d.DownloadByAttribute(&c)
+		return &c
+	}
+
+	c := download("cat.jpg")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "meow", string(c.Response.Body()))
+	require.Equal(t, w.StoredObjectID().EncodeToString(), string(c.Response.Header.Peek(hdrObjectID)))
+
+	c = download("dog.jpg")
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+}
+
+func TestDownloadByAttributeCached(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	cache := attrcache.New(time.Minute, time.Minute, 10)
+	b := attrcache.NewBackend(mem, cache)
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: b, Resolutions: cache}, &Settings{}, nil)
+	download := func() *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "index.html")
+		d.DownloadByAttribute(&c)
+		return &c
+	}
+
+	require.Equal(t, fasthttp.StatusNotFound, download().Response.StatusCode())
+
+	// objects written bypassing the gate are found after the cache expires
+	putVersion(t, mem, cnrID, "index.html", 100, "v1")
+	require.Equal(t, fasthttp.StatusNotFound, download().Response.StatusCode())
+	cache.InvalidateContainer(cnrID)
+	require.Equal(t, "v1", string(download().Response.Body()))
+
+	// writes via the gate invalidate results immediately
+	putVersion(t, mem, cnrID, "index.html", 200, "v2")
+	require.Equal(t, "v1", string(download().Response.Body()))
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	var attr object.Attribute
+	attr.SetKey(object.AttributeFilePath)
+	attr.SetValue("index.html")
+	hdr.SetAttributes(attr)
+	w, err := b.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, "v2", string(download().Response.Body()))
+}
+
+func TestDownloadByPathFiltered(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	putVersion(t, mem, cnrID, "index.html", 100, "index")
+
+	filters := pathfilter.New(context.Background(), pathfilter.Config{}, zap.NewNop())
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, PathFilters: filters}, &Settings{}, nil)
+	download := func(path string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", path)
+		d.DownloadByAttribute(&c)
+		return &c
+	}
+
+	paths, err := d.filePaths(context.Background(), cnrID, 10, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"index.html"}, paths)
+	_, err = d.filePaths(context.Background(), cnrID, 0, 2)
+	require.ErrorIs(t, err, errTooManyObjects)
+
+	require.Equal(t, fasthttp.StatusNotFound, download("missing.html").Response.StatusCode())
+	require.Eventually(t, func() bool {
+		return !filters.MayContain(cnrID, "missing.html", d.filePaths)
+	}, time.Second, time.Millisecond)
+
+	// objects written bypassing the gate are missing until the filter is rebuilt
+	putVersion(t, mem, cnrID, "missing.html", 100, "found")
+	require.Equal(t, fasthttp.StatusNotFound, download("missing.html").Response.StatusCode())
+	require.Equal(t, "index", string(download("index.html").Response.Body()))
+}
+
+func TestServerTiming(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeFilePath)
+	attr.SetValue("index.html")
+	hdr.SetAttributes(*attr)
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte("<html></html>"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	for _, tc := range []struct {
+		h      fasthttp.RequestHandler
+		stages string
+	}{
+		{d.DownloadByAttribute, `^resolve;dur=[0-9.]+, search;dur=[0-9.]+, head;dur=[0-9.]+, payload;dur=[0-9.]+, total;dur=1$`},
+		{d.HeadByAttribute, `^resolve;dur=[0-9.]+, search;dur=[0-9.]+, head;dur=[0-9.]+, total;dur=1$`},
+	} {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "index.html")
+		servertiming.Enable(&c)
+		tc.h(&c)
+		servertiming.Write(&c, time.Millisecond)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Regexp(t, tc.stages, string(c.Response.Header.Peek(servertiming.HeaderServerTiming)))
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop()}, &Settings{}, nil)
+
+	var c fasthttp.RequestCtx
+	ctx, cancel := d.newRequest(&c, zap.NewNop()).requestContext()
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+	cancel()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	d.settings.SetRequestTimeout(time.Millisecond)
+	r := d.newRequest(&c, zap.NewNop())
+	ctx, cancel = r.requestContext()
+	defer cancel()
+	<-ctx.Done()
+	r.handleNeoFSErr(eacl.OperationGet, ctx.Err(), time.Now())
+	require.Equal(t, fasthttp.StatusGatewayTimeout, c.Response.StatusCode())
+}
+
+// failingLister returns the IDs and then fails.
+type failingLister struct {
+	ids []oid.ID
+	err error
+}
+
+func (l *failingLister) Read(buf []oid.ID) (int, error) {
+	n := copy(buf, l.ids)
+	l.ids = l.ids[n:]
+	if len(l.ids) == 0 {
+		return n, l.err
+	}
+	return n, nil
+}
+
+func (l *failingLister) Iterate(func(oid.ID) bool) error { return l.err }
+
+func (l *failingLister) Close() error { return nil }
+
+// interruptedSearchBackend fails searches after the first result.
+type interruptedSearchBackend struct {
+	*backend.Memory
+	err error
+}
+
+func (b *interruptedSearchBackend) ObjectSearchInit(ctx context.Context, cnrID cid.ID, signer user.Signer, filters object.SearchFilters, prm client.PrmObjectSearch) (backend.ObjectLister, error) {
+	res, err := b.Memory.ObjectSearchInit(ctx, cnrID, signer, filters, prm)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]oid.ID, 1)
+	n, _ := res.Read(buf)
+	return &failingLister{ids: buf[:n], err: b.err}, nil
+}
+
+func TestDownloadByAttributeInterruptedSearch(t *testing.T) {
+	mem := &interruptedSearchBackend{Memory: backend.NewMemory()}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	putVersion(t, mem.Memory, cnrID, "index.html", 100, "v1")
+
+	cache := attrcache.New(time.Minute, time.Minute, 10)
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Resolutions: cache}, &Settings{}, nil)
+	download := func() *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "index.html")
+		d.DownloadByAttribute(&c)
+		return &c
+	}
+
+	mem.err = fmt.Errorf("read: %w", searchlimit.ErrTimeout)
+	require.Equal(t, fasthttp.StatusGatewayTimeout, download().Response.StatusCode(), "partial results aren't served")
+	_, ok := cache.Get(cnrID, object.AttributeFilePath, "index.html")
+	require.False(t, ok, "partial results aren't cached")
+
+	mem.err = io.EOF
+	c := download()
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "v1", string(c.Response.Body()))
+	_, ok = cache.Get(cnrID, object.AttributeFilePath, "index.html")
+	require.True(t, ok)
+}
@@ -0,0 +1,80 @@
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+)
+
+// htmlCacheControl makes clients revalidate HTML documents on every use, so
+// sites hosted in containers get updates immediately, while unchanged pages
+// aren't transferred again.
+const htmlCacheControl = "no-cache"
+
+// isHTML checks whether the content type is of HTML document.
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// objectETag returns the ETag of the object payload. Objects are immutable, so
+// the ID identifies the content, but the tag is weak since the representation
+// depends on the request, e.g. Content-Disposition.
+func objectETag(id oid.ID) string {
+	return `W/"` + id.EncodeToString() + `"`
+}
+
+// listingETag returns the ETag of the page listing the objects, it's
+// calculated from the page route, the listing parameters and object IDs.
+func listingETag(route []byte, params string, ids []oid.ID) string {
+	h := sha256.New()
+	h.Write(route)
+	h.Write([]byte{0})
+	h.Write([]byte(params))
+	for _, id := range ids {
+		h.Write(id[:])
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches checks whether the ETag matches If-None-Match request header.
+// Weak comparison is used, see RFC 9110, section 13.1.2.
+func etagMatches(h *fasthttp.RequestHeader, etag string) bool {
+	val := h.Peek(fasthttp.HeaderIfNoneMatch)
+	if len(val) == 0 {
+		return false
+	}
+	if string(bytes.TrimSpace(val)) == "*" {
+		return true
+	}
+
+	opaque := bytes.TrimPrefix([]byte(etag), []byte("W/"))
+	for _, tag := range bytes.Split(val, []byte(",")) {
+		if bytes.Equal(bytes.TrimPrefix(bytes.TrimSpace(tag), []byte("W/")), opaque) {
+			return true
+		}
+	}
+	return false
+}
+
+// setHTMLValidator sets the ETag of the HTML document and responds with 304
+// Not Modified if the client has the current version. It returns true if the
+// response is complete.
+func setHTMLValidator(c *fasthttp.RequestCtx, etag string) bool {
+	c.Response.Header.Set(fasthttp.HeaderETag, etag)
+	c.Response.Header.Set(fasthttp.HeaderCacheControl, htmlCacheControl)
+	if !etagMatches(&c.Request.Header, etag) {
+		return false
+	}
+
+	// object headers are kept, they're the same for the cached response
+	c.Response.ResetBody()
+	c.Response.Header.Del(fasthttp.HeaderContentType)
+	c.Response.Header.Del(fasthttp.HeaderContentDisposition)
+	c.SetStatusCode(fasthttp.StatusNotModified)
+	return true
+}
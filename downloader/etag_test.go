@@ -0,0 +1,107 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := `W/"abc"`
+	for val, match := range map[string]bool{
+		``:                  false,
+		`*`:                 true,
+		`"abc"`:             true,
+		`W/"abc"`:           true,
+		`"xyz", W/"abc"`:    true,
+		`"xyz"`:             false,
+		`"abcd"`:            false,
+		`W/"xyz" , "abc" `:  true,
+		`"xyz","abc","def"`: true,
+	} {
+		var h fasthttp.RequestHeader
+		if val != "" {
+			h.Set(fasthttp.HeaderIfNoneMatch, val)
+		}
+		require.Equal(t, match, etagMatches(&h, etag), val)
+	}
+}
+
+func TestListingETag(t *testing.T) {
+	ids := []oid.ID{oidtest.ID(), oidtest.ID()}
+	etag := listingETag([]byte("/browse/cnr/"), "1000", ids)
+	require.Equal(t, etag, listingETag([]byte("/browse/cnr/"), "1000", ids))
+	require.NotEqual(t, etag, listingETag([]byte("/browse/cnr/dir/"), "1000", ids))
+	require.NotEqual(t, etag, listingETag([]byte("/browse/cnr/"), "10", ids))
+	require.NotEqual(t, etag, listingETag([]byte("/browse/cnr/"), "1000", ids[:1]))
+}
+
+func TestHTMLRevalidation(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	put := func(contentType, payload string) oid.ID {
+		var attr object.Attribute
+		attr.SetKey(object.AttributeContentType)
+		attr.SetValue(contentType)
+		var hdr object.Object
+		hdr.SetContainerID(cnrID)
+		hdr.SetAttributes(attr)
+
+		w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(payload))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return w.StoredObjectID()
+	}
+	page := put("text/html; charset=utf-8", "<html></html>")
+	image := put("image/png", "png")
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+	request := func(h fasthttp.RequestHandler, id oid.ID, etag string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		if etag != "" {
+			c.Request.Header.Set(fasthttp.HeaderIfNoneMatch, etag)
+		}
+		h(&c)
+		return &c
+	}
+
+	for name, h := range map[string]fasthttp.RequestHandler{
+		"get":  d.DownloadByAddress,
+		"head": d.HeadByAddress,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := request(h, page, "")
+			require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+			etag := string(c.Response.Header.Peek(fasthttp.HeaderETag))
+			require.Equal(t, `W/"`+page.EncodeToString()+`"`, etag)
+			require.Equal(t, htmlCacheControl, string(c.Response.Header.Peek(fasthttp.HeaderCacheControl)))
+
+			c = request(h, page, etag)
+			require.Equal(t, fasthttp.StatusNotModified, c.Response.StatusCode())
+			require.Empty(t, c.Response.Body())
+			require.Equal(t, etag, string(c.Response.Header.Peek(fasthttp.HeaderETag)))
+
+			c = request(h, page, `W/"outdated"`)
+			require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+			c = request(h, image, "*")
+			require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+			require.Empty(t, c.Response.Header.Peek(fasthttp.HeaderETag))
+		})
+	}
+}
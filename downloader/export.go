@@ -0,0 +1,248 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const jsonHeader = "application/json; charset=UTF-8"
+
+// exportConcurrency limits the number of objects headed at the same time by a
+// single export.
+const exportConcurrency = 8
+
+// exportRecord is a single line of container export.
+type exportRecord struct {
+	ObjectID    string            `json:"object_id"`
+	PayloadSize uint64            `json:"payload_size"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Proto implements response.ProtoValue.
+func (r exportRecord) Proto() proto.Message {
+	return &pb.ExportRecord{
+		ObjectId:    r.ObjectID,
+		PayloadSize: r.PayloadSize,
+		Attributes:  r.Attributes,
+		Error:       r.Error,
+	}
+}
+
+// parseAttributeFilters converts `name=Key=Value` query arguments into
+// search filters matching attribute values exactly.
+func parseAttributeFilters(args *fasthttp.Args, name string) (object.SearchFilters, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+
+	for _, raw := range args.PeekMulti(name) {
+		key, val, ok := strings.Cut(string(raw), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid filter '%s', expected 'Key=Value'", raw)
+		}
+		filters.AddFilter(key, val, object.MatchStringEqual)
+	}
+
+	return filters, nil
+}
+
+// sortedIDsAfter sorts object IDs and drops ones not greater than the cursor,
+// so export order is stable and can be resumed.
+func sortedIDsAfter(ids []oid.ID, cursor *oid.ID) []oid.ID {
+	sort.Slice(ids, func(i, j int) bool {
+		return compareIDs(ids[i], ids[j]) < 0
+	})
+
+	if cursor == nil {
+		return ids
+	}
+
+	i := sort.Search(len(ids), func(i int) bool {
+		return compareIDs(ids[i], *cursor) > 0
+	})
+	return ids[i:]
+}
+
+func compareIDs(a, b oid.ID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// Export handles streaming of container contents as JSON lines.
+func (d *Downloader) Export(c *fasthttp.RequestCtx) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	args := c.QueryArgs()
+
+	filters, err := parseAttributeFilters(args, "filter")
+	if err != nil {
+		log.Error("could not parse filters", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var cursor *oid.ID
+	if rawCursor := args.Peek("cursor"); len(rawCursor) != 0 {
+		value, _ := url.QueryUnescape(string(rawCursor))
+		id, err := utils.DecodeObjectID(value)
+		if err != nil {
+			log.Error("wrong cursor", zap.Error(err))
+			response.Error(c, "wrong cursor", fasthttp.StatusBadRequest)
+			return
+		}
+		cursor = &id
+	}
+
+	limit := args.GetUintOrZero("limit")
+
+	resSearch, err := d.searchByFilters(c, containerID, filters)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return
+	}
+
+	// IDs are collected before streaming, since search results order isn't
+	// guaranteed and the cursor must be meaningful between requests.
+	var ids []oid.ID
+	errIter := resSearch.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	})
+	if errIter != nil {
+		log.Error("iterating over selected objects failed", zap.Error(errIter))
+		if errors.Is(errIter, apistatus.ErrContainerNotFound) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return
+		}
+		response.StorageError(c, eacl.OperationSearch, "iterating over selected objects failed", errIter, searchErrorStatus(errIter))
+		return
+	}
+
+	ids = sortedIDsAfter(ids, cursor)
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	btoken := bearerToken(c)
+	r := d.newRequest(c, log)
+
+	format := response.Negotiate(c)
+
+	c.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAccept)
+	c.Response.Header.Set(fasthttp.HeaderContentType, format.SeqContentType(exportRecord{}))
+	c.SetStatusCode(fasthttp.StatusOK)
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		// heads are interrupted once the client has gone away or the request
+		// timeout expires
+		ctx, cancel := r.requestContext()
+		defer cancel()
+
+		for rec := range d.exportRecords(ctx, *containerID, ids, btoken, log) {
+			if err := response.WriteSeq(w, format, rec); err != nil {
+				log.Error("could not encode export record", zap.Error(err))
+				return
+			}
+			if err := w.Flush(); err != nil {
+				log.Error("could not flush export record", zap.Error(err))
+				return
+			}
+		}
+	})
+}
+
+// exportRecords heads the objects concurrently and returns their records in
+// the order of IDs as soon as they are ready. Heading stops when the context
+// is canceled, the channel is closed then.
+func (d *Downloader) exportRecords(ctx context.Context, cnrID cid.ID, ids []oid.ID, btoken *bearer.Token, log *zap.Logger) <-chan exportRecord {
+	// every pending record has its own channel, so heads run concurrently,
+	// but records are sent in order
+	pending := make(chan chan exportRecord, exportConcurrency)
+	go func() {
+		defer close(pending)
+		for _, id := range ids {
+			res := make(chan exportRecord, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- res:
+			}
+			go func(id oid.ID) {
+				res <- d.exportRecord(ctx, cnrID, id, btoken, log)
+			}(id)
+		}
+	}()
+
+	records := make(chan exportRecord)
+	go func() {
+		defer close(records)
+		for res := range pending {
+			rec := <-res
+			select {
+			case <-ctx.Done():
+				return
+			case records <- rec:
+			}
+		}
+	}()
+	return records
+}
+
+func (d *Downloader) exportRecord(ctx context.Context, cnrID cid.ID, id oid.ID, btoken *bearer.Token, log *zap.Logger) exportRecord {
+	rec := exportRecord{ObjectID: id.EncodeToString()}
+
+	var prm client.PrmObjectHead
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	hdr, err := d.backend.ObjectHead(ctx, cnrID, id, d.signer, prm)
+	if err != nil {
+		log.Error("could not head object", zap.Stringer("oid", id), zap.Error(err))
+		rec.Error = err.Error()
+		return rec
+	}
+
+	rec.PayloadSize = hdr.PayloadSize()
+	if attrs := hdr.Attributes(); len(attrs) != 0 {
+		rec.Attributes = make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			rec.Attributes[attr.Key()] = attr.Value()
+		}
+	}
+
+	return rec
+}
@@ -0,0 +1,91 @@
+package downloader
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestSortedIDsAfter(t *testing.T) {
+	ids := make([]oid.ID, 5)
+	for i := range ids {
+		ids[i][0] = byte(5 - i)
+	}
+
+	sorted := sortedIDsAfter(append([]oid.ID(nil), ids...), nil)
+	require.Len(t, sorted, 5)
+	for i := 1; i < len(sorted); i++ {
+		require.Negative(t, compareIDs(sorted[i-1], sorted[i]))
+	}
+
+	cursor := sorted[2]
+	rest := sortedIDsAfter(append([]oid.ID(nil), ids...), &cursor)
+	require.Equal(t, sorted[3:], rest)
+
+	last := sorted[4]
+	require.Empty(t, sortedIDsAfter(append([]oid.ID(nil), ids...), &last))
+}
+
+func TestParseAttributeFilters(t *testing.T) {
+	var args fasthttp.Args
+	args.Add("filter", "FileName=cat.jpg")
+	args.Add("filter", "Type=image=png")
+
+	filters, err := parseAttributeFilters(&args, "filter")
+	require.NoError(t, err)
+	// root filter + two attribute filters
+	require.Len(t, filters, 3)
+	require.Equal(t, "FileName", filters[1].Header())
+	require.Equal(t, "cat.jpg", filters[1].Value())
+	require.Equal(t, "image=png", filters[2].Value())
+
+	args.Reset()
+	args.Add("filter", "no-separator")
+	_, err = parseAttributeFilters(&args, "filter")
+	require.Error(t, err)
+}
+
+func TestExportRecords(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	ids := make([]oid.ID, 3*exportConcurrency)
+	for i := range ids {
+		ids[i] = putFile(t, mem, cnrID, "file"+strconv.Itoa(i), strconv.Itoa(i))
+	}
+	ids = sortedIDsAfter(ids, nil)
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	t.Run("ordered", func(t *testing.T) {
+		var res []string
+		for rec := range d.exportRecords(context.Background(), cnrID, ids, nil, zap.NewNop()) {
+			require.Empty(t, rec.Error)
+			require.NotZero(t, rec.PayloadSize)
+			res = append(res, rec.ObjectID)
+		}
+		require.Len(t, res, len(ids))
+		for i := range ids {
+			require.Equal(t, ids[i].EncodeToString(), res[i])
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		records := d.exportRecords(ctx, cnrID, ids, nil, zap.NewNop())
+		<-records
+		cancel()
+
+		var n int
+		for range records {
+			n++
+		}
+		require.Less(t, n, len(ids)-1)
+	})
+}
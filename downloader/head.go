@@ -1,19 +1,22 @@
 package downloader
 
 import (
+	"encoding/hex"
 	"io"
-	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
 	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/servertiming"
 	"github.com/nspcc-dev/neofs-http-gw/tokens"
-	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
@@ -28,7 +31,7 @@ const (
 	hdrContainerID = "X-Container-Id"
 )
 
-func (r request) headObject(clnt *pool.Pool, objectAddress oid.Address, signer user.Signer) {
+func (r request) headObject(clnt backend.Backend, objectAddress oid.Address, signer user.Signer) {
 	var start = time.Now()
 	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
 		r.log.Error("could not fetch and store bearer token", zap.Error(err))
@@ -39,65 +42,62 @@ func (r request) headObject(clnt *pool.Pool, objectAddress oid.Address, signer u
 	btoken := bearerToken(r.RequestCtx)
 
 	var prm client.PrmObjectHead
+	ctx, cancel := r.requestContext()
+	defer cancel()
 	if btoken != nil {
 		prm.WithBearerToken(*btoken)
+	} else {
+		ctx = extcache.Shareable(ctx)
 	}
 
-	obj, err := clnt.ObjectHead(r.appCtx, objectAddress.Container(), objectAddress.Object(), signer, prm)
+	headDone := servertiming.Start(r.RequestCtx, servertiming.Head)
+	obj, err := clnt.ObjectHead(ctx, objectAddress.Container(), objectAddress.Object(), signer, prm)
+	headDone()
 	if err != nil {
-		r.handleNeoFSErr(err, start)
+		r.handleNeoFSErr(eacl.OperationHead, err, start)
 		return
 	}
 
 	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(obj.PayloadSize(), 10))
-	var contentType string
-	for _, attr := range obj.Attributes() {
-		key := attr.Key()
-		val := attr.Value()
-		if !isValidToken(key) || !isValidValue(val) {
-			continue
-		}
-		if strings.HasPrefix(key, utils.SystemAttributePrefix) {
-			key = systemBackwardTranslator(key)
-		}
-		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, val)
-		switch key {
-		case object.AttributeTimestamp:
-			value, err := strconv.ParseInt(val, 10, 64)
-			if err != nil {
-				r.log.Info("couldn't parse creation date",
-					zap.String("key", key),
-					zap.String("val", val),
-					zap.Error(err))
-				continue
-			}
-			r.Response.Header.Set(fasthttp.HeaderLastModified, time.Unix(value, 0).UTC().Format(http.TimeFormat))
-		case object.AttributeContentType:
-			contentType = val
-		}
-	}
-
+	r.Response.Header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+	_, contentType := r.attributesToResponse(obj)
 	idsToResponse(&r.Response, obj)
+	r.signResponse(signer)
+	r.setCanonicalLink(obj)
 
 	if len(contentType) == 0 {
+		var (
+			rangeReader io.Closer
+			streamDone  func()
+		)
 		contentType, _, err = readContentType(obj.PayloadSize(), func(sz uint64) (io.Reader, error) {
 			var prmRange client.PrmObjectRange
 			if btoken != nil {
 				prmRange.WithBearerToken(*btoken)
 			}
 
-			resObj, err := clnt.ObjectRangeInit(r.appCtx, objectAddress.Container(), objectAddress.Object(), 0, sz, signer, prmRange)
+			resObj, err := clnt.ObjectRangeInit(ctx, objectAddress.Container(), objectAddress.Object(), 0, sz, signer, prmRange)
 			if err != nil {
 				return nil, err
 			}
+			rangeReader, streamDone = resObj, r.streams.Open(instrument.StreamRange)
 			return resObj, nil
 		})
+		if rangeReader != nil {
+			// stream resources are released on close only
+			_ = rangeReader.Close()
+			streamDone()
+		}
 		if err != nil && err != io.EOF {
-			r.handleNeoFSErr(err, start)
+			r.handleNeoFSErr(eacl.OperationRange, err, start)
 			return
 		}
 	}
 	r.SetContentType(contentType)
+
+	if isHTML(contentType) {
+		setHTMLValidator(r.RequestCtx, objectETag(objectAddress.Object()))
+	}
 }
 
 func idsToResponse(resp *fasthttp.Response, obj *object.Object) {
@@ -106,6 +106,20 @@ func idsToResponse(resp *fasthttp.Response, obj *object.Object) {
 	resp.Header.Set(hdrObjectID, objID.String())
 	resp.Header.Set(hdrOwnerID, obj.OwnerID().String())
 	resp.Header.Set(hdrContainerID, cnrID.String())
+	surrogateKeysToResponse(resp, cnrID.EncodeToString(), objID.EncodeToString())
+	if cs, ok := obj.PayloadChecksum(); ok && cs.Type() == checksum.SHA256 {
+		resp.Header.Set(hdrPayloadChecksum, hex.EncodeToString(cs.Value()))
+	}
+}
+
+// signResponse signs identity headers of the response if it's enabled.
+func (r request) signResponse(signer user.Signer) {
+	if !r.signHeaders {
+		return
+	}
+	if err := signHeaders(&r.Response.Header, signer); err != nil {
+		r.log.Error("could not sign response headers", zap.Error(err))
+	}
 }
 
 // HeadByAddress handles head requests using simple cid/oid format.
@@ -113,6 +127,11 @@ func (d *Downloader) HeadByAddress(c *fasthttp.RequestCtx) {
 	d.byAddress(c, request.headObject)
 }
 
+// HeadByAddressString handles head requests using single string address format.
+func (d *Downloader) HeadByAddressString(c *fasthttp.RequestCtx) {
+	d.byAddressString(c, request.headObject)
+}
+
 // HeadByAttribute handles attribute-based head requests.
 func (d *Downloader) HeadByAttribute(c *fasthttp.RequestCtx) {
 	d.byAttribute(c, request.headObject)
@@ -0,0 +1,140 @@
+package downloader
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// attrContentLanguage is an object attribute with the language of the
+// content, objects with the same FilePath and different languages are
+// variants of the same document.
+const attrContentLanguage = utils.AttributeContentLanguage
+
+// languageRange is a language range of Accept-Language header with its
+// weight.
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage returns acceptable language ranges of Accept-Language
+// header value ordered by preference. Ranges with zero or invalid weight are
+// skipped.
+func parseAcceptLanguage(val string) []languageRange {
+	var res []languageRange
+	for _, part := range strings.Split(val, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range params[1:] {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(k), "q") {
+				continue
+			}
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil || q > 1 {
+				q = 0
+			}
+		}
+		if q > 0 {
+			res = append(res, languageRange{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool { return res[i].q > res[j].q })
+	return res
+}
+
+// matchLanguage checks whether the language tag matches the range, see basic
+// filtering in RFC 4647, section 3.3.1.
+func matchLanguage(rng, tag string) bool {
+	if rng == "*" {
+		return tag != ""
+	}
+	return strings.EqualFold(rng, tag) ||
+		len(tag) > len(rng) && tag[len(rng)] == '-' && strings.EqualFold(rng, tag[:len(rng)])
+}
+
+// selectLanguage returns the index of the language variant the client
+// prefers. If none is acceptable, the variant in the default language is
+// selected, then the one without language and the first one at last.
+func selectLanguage(accept string, langs []string, def string) int {
+	for _, rng := range parseAcceptLanguage(accept) {
+		for i, lang := range langs {
+			if matchLanguage(rng.tag, lang) {
+				return i
+			}
+		}
+	}
+
+	if def != "" {
+		for i, lang := range langs {
+			if matchLanguage(def, lang) {
+				return i
+			}
+		}
+	}
+	for i, lang := range langs {
+		if lang == "" {
+			return i
+		}
+	}
+	return 0
+}
+
+// languageVariant selects the newest object matching Accept-Language header
+// of the request from the objects with the same FilePath. Vary header is set
+// if the objects are variants in different languages. Versions older than
+// the newest delete marker are hidden. The only flag is set if the selected
+// object is the only visible one, so the path refers to it regardless of the
+// request. The request is responded with false returned if there are no
+// visible versions or they can't be headed.
+func (d *Downloader) languageVariant(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, ids []oid.ID) (objID oid.ID, only bool, ok bool) {
+	versions, ok := d.headVersions(c, log, cnrID, ids)
+	if !ok {
+		return oid.ID{}, false, false
+	}
+	if len(versions) == 0 {
+		log.Error("object not found")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return oid.ID{}, false, false
+	}
+	if versions = utils.LiveVersions(versions); len(versions) == 0 {
+		log.Info("object is removed by delete marker")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return oid.ID{}, false, false
+	}
+
+	// versions are sorted from the newest one, so it's selected among the
+	// ones in the same language
+	langs := make([]string, len(versions))
+	for i := range versions {
+		langs[i] = versions[i].Language
+	}
+
+	var def string
+	if d.settings != nil {
+		def = d.settings.DefaultLanguage()
+	}
+	i := selectLanguage(string(c.Request.Header.Peek(fasthttp.HeaderAcceptLanguage)), langs, def)
+
+	for _, lang := range langs[1:] {
+		if lang != langs[0] {
+			c.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAcceptLanguage)
+			break
+		}
+	}
+	return versions[i].ID, len(versions) == 1, true
+}
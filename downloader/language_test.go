@@ -0,0 +1,123 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	require.Empty(t, parseAcceptLanguage(""))
+	require.Equal(t, []languageRange{{"de", 1}, {"en-US", 0.8}, {"en", 0.5}},
+		parseAcceptLanguage("en;q=0.5, de, en-US;q=0.8, fr;q=0, ru;q=abc, , es;q=2"))
+}
+
+func TestMatchLanguage(t *testing.T) {
+	for _, tc := range []struct {
+		rng, tag string
+		match    bool
+	}{
+		{"en", "en", true},
+		{"en", "EN", true},
+		{"en", "en-US", true},
+		{"en-US", "en", false},
+		{"en", "eng", false},
+		{"*", "fr", true},
+		{"*", "", false},
+		{"de", "", false},
+	} {
+		require.Equal(t, tc.match, matchLanguage(tc.rng, tc.tag), tc)
+	}
+}
+
+func TestSelectLanguage(t *testing.T) {
+	langs := []string{"", "en", "de-AT", "fr"}
+	for _, tc := range []struct {
+		accept, def string
+		selected    int
+	}{
+		{"de", "", 2},
+		{"fr;q=0.5, de-AT", "", 2},
+		{"fr, de;q=0.9", "", 3},
+		{"ja", "", 0},
+		{"ja", "en", 1},
+		{"", "fr", 3},
+		{"*", "", 1},
+	} {
+		require.Equal(t, tc.selected, selectLanguage(tc.accept, langs, tc.def), tc)
+	}
+	require.Equal(t, 0, selectLanguage("ja", []string{"en", "fr"}, ""))
+}
+
+func TestLanguageVariants(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	put := func(filePath string, attrs map[string]string) {
+		var hdr object.Object
+		hdr.SetContainerID(cnrID)
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeFilePath)
+		attr.SetValue(filePath)
+		hdr.SetAttributes(*attr)
+		for k, v := range attrs {
+			attr := object.NewAttribute()
+			attr.SetKey(k)
+			attr.SetValue(v)
+			hdr.SetAttributes(append(hdr.Attributes(), *attr)...)
+		}
+
+		w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(attrs[attrContentLanguage]))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	put("index.html", map[string]string{attrContentLanguage: "en"})
+	put("index.html", map[string]string{attrContentLanguage: "de"})
+	put("about.html", map[string]string{attrContentLanguage: "en"})
+
+	settings := &Settings{}
+	settings.SetDefaultLanguage("de")
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, settings, nil)
+
+	get := func(h fasthttp.RequestHandler, filePath, accept string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", filePath)
+		if accept != "" {
+			c.Request.Header.Set(fasthttp.HeaderAcceptLanguage, accept)
+		}
+		h(&c)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		return &c
+	}
+
+	for accept, lang := range map[string]string{
+		"en-US, en;q=0.9": "en",
+		"de":              "de",
+		"fr":              "de",
+		"":                "de",
+	} {
+		c := get(d.DownloadByAttribute, "index.html", accept)
+		require.Equal(t, lang, string(c.Response.Header.Peek(fasthttp.HeaderContentLanguage)), accept)
+		require.Equal(t, fasthttp.HeaderAcceptLanguage, string(c.Response.Header.Peek(fasthttp.HeaderVary)))
+		require.Equal(t, lang, string(c.Response.Body()))
+
+		c = get(d.HeadByAttribute, "index.html", accept)
+		require.Equal(t, lang, string(c.Response.Header.Peek(fasthttp.HeaderContentLanguage)), accept)
+	}
+
+	c := get(d.DownloadByAttribute, "about.html", "de")
+	require.Equal(t, "en", string(c.Response.Header.Peek(fasthttp.HeaderContentLanguage)))
+	require.Empty(t, c.Response.Header.Peek(fasthttp.HeaderVary), "single variant")
+}
@@ -0,0 +1,107 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// filePaths lists FilePath attributes of the container objects to build the
+// path filter, objects are listed without bearer token, so the filter is
+// used for requests without it only. Up to concurrency headers are requested
+// at the same time.
+func (d *Downloader) filePaths(ctx context.Context, cnrID cid.ID, max, concurrency int) ([]string, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(object.AttributeFilePath, "", object.MatchCommonPrefix)
+
+	res, err := d.backend.ObjectSearchInit(ctx, cnrID, d.signer, filters, client.PrmObjectSearch{})
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return len(ids) > max
+	})
+	_ = res.Close()
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	if len(ids) > max {
+		return nil, fmt.Errorf("%w, max is %d", errTooManyObjects, max)
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+		mu       sync.Mutex
+		paths    = make([]string, 0, len(ids))
+		firstErr error
+	)
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(id oid.ID) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				path, err := d.filePath(ctx, cnrID, id)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err != nil && firstErr == nil:
+					firstErr = err
+					cancel()
+				case path != "":
+					paths = append(paths, path)
+				}
+			}(id)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// filePath returns FilePath attribute of the object, it's empty if the object
+// has no path or it's already removed.
+func (d *Downloader) filePath(ctx context.Context, cnrID cid.ID, id oid.ID) (string, error) {
+	hdr, err := d.backend.ObjectHead(ctx, cnrID, id, d.signer, client.PrmObjectHead{})
+	if err != nil {
+		if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+			return "", nil
+		}
+		return "", fmt.Errorf("head object %s: %w", id, err)
+	}
+	for _, attr := range hdr.Attributes() {
+		if attr.Key() == object.AttributeFilePath {
+			return attr.Value(), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,279 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/russross/blackfriday/v2"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+//go:embed preview.html
+var previewHTML string
+
+var previewTemplate = template.Must(template.New("preview").Parse(previewHTML))
+
+// previewTemplateSum changes page ETags when the template is changed.
+var previewTemplateSum = func() string {
+	sum := sha256.Sum256([]byte(previewHTML))
+	return hex.EncodeToString(sum[:])
+}()
+
+// Kinds of object previews.
+const (
+	previewMarkdown = "markdown"
+	previewText     = "text"
+	previewImage    = "image"
+)
+
+// markdownFlags make rendered documents safe to embed: raw HTML is dropped and
+// only safe links are kept.
+const markdownFlags = blackfriday.CommonHTMLFlags | blackfriday.SkipHTML | blackfriday.Safelink |
+	blackfriday.NofollowLinks | blackfriday.NoreferrerLinks
+
+type (
+	previewAttribute struct {
+		Key   string
+		Value string
+	}
+
+	previewPage struct {
+		Name         string
+		Container    string
+		Object       string
+		Owner        string
+		Size         uint64
+		ContentType  string
+		Modified     string
+		Attributes   []previewAttribute
+		Nonce        string
+		Href         string
+		DownloadHref string
+		Kind         string
+		Text         string
+		HTML         template.HTML
+		TooLarge     bool
+		MaxSize      uint64
+	}
+)
+
+// previewKind returns the kind of the object preview by its content type and
+// name, empty kind means the object can't be previewed.
+func previewKind(contentType, name string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+
+	switch ext := strings.ToLower(path.Ext(name)); {
+	case mediaType == "text/markdown", mediaType == "text/x-markdown",
+		(ext == ".md" || ext == ".markdown") && (mediaType == "" || mediaType == "text/plain"):
+		return previewMarkdown
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/json", mediaType == "application/xml":
+		return previewText
+	case strings.HasPrefix(mediaType, "image/"):
+		return previewImage
+	}
+	return ""
+}
+
+// newPreviewPage fills the page with the object metadata. Links are prefixed
+// with routePrefix.
+func newPreviewPage(routePrefix, scid string, hdr *object.Object) previewPage {
+	id, _ := hdr.ID()
+	page := previewPage{
+		Container: scid,
+		Object:    id.EncodeToString(),
+		Owner:     hdr.OwnerID().EncodeToString(),
+		Size:      hdr.PayloadSize(),
+	}
+	page.Href = routePrefix + "/get/" + url.PathEscape(scid) + "/" + page.Object
+	page.DownloadHref = page.Href + "?download=true"
+
+	var filePath string
+	for _, attr := range hdr.Attributes() {
+		page.Attributes = append(page.Attributes, previewAttribute{Key: attr.Key(), Value: attr.Value()})
+		switch attr.Key() {
+		case object.AttributeFileName:
+			page.Name = attr.Value()
+		case object.AttributeFilePath:
+			filePath = attr.Value()
+		case object.AttributeContentType:
+			page.ContentType = attr.Value()
+		case object.AttributeTimestamp:
+			if value, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				page.Modified = time.Unix(value, 0).UTC().Format(time.RFC3339)
+			}
+		}
+	}
+	if page.Name == "" && filePath != "" {
+		page.Name = path.Base(filePath)
+	}
+	if page.Name == "" {
+		page.Name = page.Object
+	}
+	return page
+}
+
+// setPreview renders the payload of the page kind.
+func (p *previewPage) setPreview(payload []byte) {
+	switch p.Kind {
+	case previewMarkdown:
+		p.HTML = template.HTML(blackfriday.Run(payload, blackfriday.WithRenderer(
+			blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{Flags: markdownFlags}))))
+	case previewText:
+		p.Text = strings.ToValidUTF8(string(payload), "�")
+	}
+}
+
+// Preview handles requests to the HTML page with the object metadata and the
+// preview of markdown, text and image payloads.
+func (d *Downloader) Preview(c *fasthttp.RequestCtx) {
+	if !d.settings.PreviewEnabled() {
+		response.Error(c, "preview is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	maxSize := d.settings.PreviewMaxSize()
+	d.byAddress(c, func(r request, clnt backend.Backend, addr oid.Address, signer user.Signer) {
+		r.previewObject(clnt, addr, signer, maxSize)
+	})
+}
+
+// previewObject renders the preview page of the object, text payloads larger
+// than maxSize aren't shown.
+func (r request) previewObject(clnt backend.Backend, addr oid.Address, signer user.Signer, maxSize uint64) {
+	start := time.Now()
+	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
+		r.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(r.RequestCtx, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	btoken := bearerToken(r.RequestCtx)
+	ctx, cancel := r.requestContext()
+	defer cancel()
+
+	var prm client.PrmObjectHead
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+	hdr, err := clnt.ObjectHead(ctx, addr.Container(), addr.Object(), signer, prm)
+	if err != nil {
+		r.handleNeoFSErr(eacl.OperationHead, err, start)
+		return
+	}
+
+	// objects are immutable, so the page changes only with the template and
+	// the size cap
+	if setHTMLValidator(r.RequestCtx, listingETag(r.Path(),
+		previewTemplateSum+strconv.FormatUint(maxSize, 10), []oid.ID{addr.Object()})) {
+		return
+	}
+
+	page := newPreviewPage(utils.RoutePrefix(string(r.Path()), "/preview/"), addr.Container().EncodeToString(), hdr)
+	page.MaxSize = maxSize
+
+	readPayload := func(ln uint64) ([]byte, error) {
+		return r.readPayloadHead(ctx, clnt, addr, signer, btoken, ln)
+	}
+
+	if page.ContentType == "" && page.Size > 0 {
+		ln := page.Size
+		if ln > sizeToDetectType {
+			ln = sizeToDetectType
+		}
+		head, err := readPayload(ln)
+		if err != nil {
+			r.handleNeoFSErr(eacl.OperationRange, err, start)
+			return
+		}
+		page.ContentType = http.DetectContentType(head)
+	}
+
+	page.Kind = previewKind(page.ContentType, page.Name)
+	if page.Kind == previewMarkdown || page.Kind == previewText {
+		if page.Size > maxSize {
+			page.TooLarge = true
+		} else if page.Size > 0 {
+			payload, err := readPayload(page.Size)
+			if err != nil {
+				r.handleNeoFSErr(eacl.OperationRange, err, start)
+				return
+			}
+			page.setPreview(payload)
+		}
+	}
+
+	nonce := make([]byte, 16)
+	if _, err = rand.Read(nonce); err != nil {
+		r.log.Error("could not generate nonce", zap.Error(err))
+		response.Error(r.RequestCtx, "could not generate nonce: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+	page.Nonce = base64.StdEncoding.EncodeToString(nonce)
+
+	var buf bytes.Buffer
+	if err = previewTemplate.Execute(&buf, page); err != nil {
+		r.log.Error("could not render page", zap.Error(err))
+		response.Error(r.RequestCtx, "could not render page: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	r.Response.Header.SetContentType("text/html; charset=utf-8")
+	r.Response.Header.Set("Content-Security-Policy",
+		"default-src 'none'; img-src 'self'; style-src 'nonce-"+page.Nonce+"'; frame-ancestors 'none'")
+	r.SetStatusCode(fasthttp.StatusOK)
+	r.SetBody(buf.Bytes())
+}
+
+// readPayloadHead reads the first ln bytes of the object payload.
+func (r request) readPayloadHead(ctx context.Context, clnt backend.Backend, addr oid.Address, signer user.Signer, btoken *bearer.Token, ln uint64) ([]byte, error) {
+	var prm client.PrmObjectRange
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	rangeReader, err := clnt.ObjectRangeInit(ctx, addr.Container(), addr.Object(), 0, ln, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	streamDone := r.streams.Open(instrument.StreamRange)
+	defer func() {
+		// stream resources are released on close only
+		_ = rangeReader.Close()
+		streamDone()
+	}()
+
+	payload := make([]byte, ln)
+	if _, err = io.ReadFull(rangeReader, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
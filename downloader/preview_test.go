@@ -0,0 +1,139 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func putPreviewObject(t *testing.T, mem *backend.Memory, cnrID cid.ID, name, contentType, payload string) oid.ID {
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	attrs := []object.Attribute{*object.NewAttribute(), *object.NewAttribute()}
+	attrs[0].SetKey(object.AttributeFileName)
+	attrs[0].SetValue(name)
+	attrs[1].SetKey(object.AttributeContentType)
+	attrs[1].SetValue(contentType)
+	if contentType == "" {
+		attrs = attrs[:1]
+	}
+	hdr.SetAttributes(attrs...)
+
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func TestPreviewKind(t *testing.T) {
+	for _, tc := range []struct {
+		contentType, name, kind string
+	}{
+		{"text/markdown; charset=utf-8", "doc", previewMarkdown},
+		{"text/plain", "README.md", previewMarkdown},
+		{"", "notes.markdown", previewMarkdown},
+		{"text/plain; charset=utf-8", "notes.txt", previewText},
+		{"application/json", "data.json", previewText},
+		{"text/html", "index.html", previewText},
+		{"image/png", "cat.png", previewImage},
+		{"application/octet-stream", "cat.md", ""},
+		{"application/zip", "a.zip", ""},
+	} {
+		require.Equal(t, tc.kind, previewKind(tc.contentType, tc.name), tc)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	settings := &Settings{}
+	settings.SetPreviewEnabled(true)
+	settings.SetPreviewMaxSize(64)
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, settings, nil)
+
+	preview := func(path string, id oid.ID, header ...string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI(path)
+		if len(header) != 0 {
+			c.Request.Header.Set(header[0], header[1])
+		}
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		d.Preview(&c)
+		return &c
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		id := putPreviewObject(t, mem, cnrID, "README.md", "text/markdown",
+			"# Title\n\n<script>alert(1)</script>\n[link](javascript:alert(1))")
+		c := preview("/preview/cnr/oid", id)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, "text/html; charset=utf-8", string(c.Response.Header.ContentType()))
+		require.Contains(t, string(c.Response.Header.Peek("Content-Security-Policy")), "default-src 'none'")
+
+		body := string(c.Response.Body())
+		require.Contains(t, body, "<h1>Title</h1>")
+		require.NotContains(t, body, "<script>")
+		require.NotContains(t, body, "javascript:")
+		require.Contains(t, body, id.EncodeToString())
+		require.Contains(t, body, `href="/get/`+cnrID.EncodeToString()+"/"+id.EncodeToString()+`?download=true"`)
+
+		etag := string(c.Response.Header.Peek(fasthttp.HeaderETag))
+		require.NotEmpty(t, etag)
+		c = preview("/preview/cnr/oid", id, fasthttp.HeaderIfNoneMatch, etag)
+		require.Equal(t, fasthttp.StatusNotModified, c.Response.StatusCode())
+		require.Empty(t, c.Response.Body())
+	})
+
+	t.Run("text without content type", func(t *testing.T) {
+		id := putPreviewObject(t, mem, cnrID, "notes", "", "<b>meow</b>")
+		c := preview("/testnet/preview/cnr/oid", id)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		body := string(c.Response.Body())
+		require.Contains(t, body, "<pre>&lt;b&gt;meow&lt;/b&gt;</pre>")
+		require.Contains(t, body, `href="/testnet/get/`)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		id := putPreviewObject(t, mem, cnrID, "big.txt", "text/plain", string(bytes.Repeat([]byte("meow"), 32)))
+		c := preview("/preview/cnr/oid", id)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		body := string(c.Response.Body())
+		require.Contains(t, body, "larger than 64 bytes")
+		require.NotContains(t, body, "meowmeow")
+	})
+
+	t.Run("image", func(t *testing.T) {
+		id := putPreviewObject(t, mem, cnrID, "cat.png", "image/png", "not really a png")
+		c := preview("/preview/cnr/oid", id)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Contains(t, string(c.Response.Body()),
+			`<img src="/get/`+cnrID.EncodeToString()+"/"+id.EncodeToString()+`"`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		c := preview("/preview/cnr/oid", oid.ID{1})
+		require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		settings.SetPreviewEnabled(false)
+		defer settings.SetPreviewEnabled(true)
+		c := preview("/preview/cnr/oid", oid.ID{1})
+		require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+	})
+}
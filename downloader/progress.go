@@ -0,0 +1,111 @@
+package downloader
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+)
+
+// Download stall reasons.
+const (
+	// stallReasonClient means the client doesn't read sent data.
+	stallReasonClient = "client"
+	// stallReasonStorage means the storage node doesn't send object payload.
+	stallReasonStorage = "storage"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// progressReader accounts bytes of the object payload passed to the client
+// and detects stalled downloads. If there is no progress during the timeout,
+// onStall is called once with the stall reason.
+type progressReader struct {
+	r       io.ReadCloser
+	metrics utils.Metrics
+	timeout time.Duration
+	onStall func(reason string)
+
+	// lastProgress is a time of the last successful read in nanoseconds.
+	lastProgress atomic.Int64
+	// inRead is set while waiting for data from the storage.
+	inRead atomic.Bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newProgressReader wraps r. Stall detection is disabled if timeout is zero.
+func newProgressReader(r io.ReadCloser, metrics utils.Metrics, timeout time.Duration, onStall func(string)) *progressReader {
+	p := &progressReader{
+		r:       r,
+		metrics: metrics,
+		timeout: timeout,
+		onStall: onStall,
+		done:    make(chan struct{}),
+	}
+	p.lastProgress.Store(time.Now().UnixNano())
+
+	if timeout > 0 {
+		go p.watch()
+	}
+
+	return p
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	p.inRead.Store(true)
+	n, err := p.r.Read(buf)
+	p.inRead.Store(false)
+
+	if n > 0 {
+		p.lastProgress.Store(time.Now().UnixNano())
+		if p.metrics != nil {
+			p.metrics.AddDownloadedBytes(n)
+		}
+	}
+
+	return n, err
+}
+
+// Close stops stall detection and closes the underlying reader.
+func (p *progressReader) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		close(p.done)
+		err = p.r.Close()
+	})
+	return err
+}
+
+func (p *progressReader) watch() {
+	ticker := time.NewTicker(p.timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			if now.Sub(time.Unix(0, p.lastProgress.Load())) < p.timeout {
+				continue
+			}
+
+			reason := stallReasonClient
+			if p.inRead.Load() {
+				reason = stallReasonStorage
+			}
+			if p.metrics != nil {
+				p.metrics.IncDownloadStalls(reason)
+			}
+			p.onStall(reason)
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package downloader
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testMetrics struct {
+	mu     sync.Mutex
+	bytes  int
+	stalls map[string]int
+}
+
+func (m *testMetrics) AddDownloadedBytes(n int) {
+	m.mu.Lock()
+	m.bytes += n
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) IncDownloadStalls(reason string) {
+	m.mu.Lock()
+	if m.stalls == nil {
+		m.stalls = make(map[string]int)
+	}
+	m.stalls[reason]++
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) IncUploadAborts() {}
+
+// blockingReader blocks reads until closed.
+type blockingReader struct {
+	closed chan struct{}
+}
+
+func (r *blockingReader) Read([]byte) (int, error) {
+	<-r.closed
+	return 0, io.ErrClosedPipe
+}
+
+func (r *blockingReader) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestProgressReader(t *testing.T) {
+	t.Run("bytes", func(t *testing.T) {
+		m := new(testMetrics)
+		p := newProgressReader(io.NopCloser(strings.NewReader("payload")), m, 0, nil)
+
+		data, err := io.ReadAll(p)
+		require.NoError(t, err)
+		require.Equal(t, "payload", string(data))
+		require.NoError(t, p.Close())
+		require.NoError(t, p.Close())
+		require.Equal(t, len("payload"), m.bytes)
+	})
+
+	t.Run("client stall", func(t *testing.T) {
+		m := new(testMetrics)
+		stalled := make(chan string, 1)
+		p := newProgressReader(io.NopCloser(strings.NewReader("payload")), m, 20*time.Millisecond, func(reason string) {
+			stalled <- reason
+		})
+		defer p.Close()
+
+		// read once and stop reading as a slow client does
+		_, err := p.Read(make([]byte, 1))
+		require.NoError(t, err)
+
+		select {
+		case reason := <-stalled:
+			require.Equal(t, stallReasonClient, reason)
+		case <-time.After(time.Second):
+			t.Fatal("stall is not detected")
+		}
+		m.mu.Lock()
+		require.Equal(t, 1, m.stalls[stallReasonClient])
+		m.mu.Unlock()
+	})
+
+	t.Run("storage stall", func(t *testing.T) {
+		m := new(testMetrics)
+		r := &blockingReader{closed: make(chan struct{})}
+		stalled := make(chan string, 1)
+		p := newProgressReader(r, m, 20*time.Millisecond, func(reason string) {
+			stalled <- reason
+		})
+
+		go func() { _, _ = p.Read(make([]byte, 1)) }()
+
+		select {
+		case reason := <-stalled:
+			require.Equal(t, stallReasonStorage, reason)
+		case <-time.After(time.Second):
+			t.Fatal("stall is not detected")
+		}
+		require.NoError(t, p.Close())
+	})
+
+	t.Run("no stall after close", func(t *testing.T) {
+		stalled := make(chan string, 1)
+		p := newProgressReader(io.NopCloser(strings.NewReader("payload")), nil, 20*time.Millisecond, func(reason string) {
+			stalled <- reason
+		})
+		require.NoError(t, p.Close())
+
+		select {
+		case <-stalled:
+			t.Fatal("stall is detected after close")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
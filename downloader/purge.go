@@ -0,0 +1,107 @@
+package downloader
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/purge"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	hdrSurrogateKey = "Surrogate-Key"
+	hdrCacheTag     = "Cache-Tag"
+	hdrPurgeToken   = "X-Purge-Token"
+)
+
+type purgeResponse struct {
+	Keys []string `json:"keys"`
+	// Archives is the number of removed archives cached locally.
+	Archives int            `json:"archives"`
+	CDN      []purge.Result `json:"cdn"`
+}
+
+// surrogateKeysToResponse tags the response with keys it can be purged by:
+// Surrogate-Key header is used by Fastly, Cache-Tag by Cloudflare.
+func surrogateKeysToResponse(resp *fasthttp.Response, keys ...string) {
+	resp.Header.Set(hdrSurrogateKey, strings.Join(keys, " "))
+	resp.Header.Set(hdrCacheTag, strings.Join(keys, ","))
+}
+
+// Purge handles requests to invalidate cached responses of the container or
+// the object. Archives cached locally and entries of the object in the
+// external cache are removed and configured CDNs are requested to purge
+// content tagged with the surrogate key. Entries of container objects in the
+// external cache can't be listed, they expire with their TTL.
+func (d *Downloader) Purge(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		soid, _ = c.UserValue("oid").(string)
+		log     = d.log.With(zap.String("cid", scid), zap.String("oid", soid))
+	)
+
+	token := d.settings.PurgeToken()
+	if token == "" {
+		response.Error(c, "purge is disabled", fasthttp.StatusNotFound)
+		return
+	}
+	if subtle.ConstantTimeCompare(c.Request.Header.Peek(hdrPurgeToken), []byte(token)) != 1 {
+		log.Warn("purge request with invalid token", zap.Stringer("remote", c.RemoteAddr()))
+		response.Error(c, "invalid purge token", fasthttp.StatusForbidden)
+		return
+	}
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	var (
+		key    = containerID.EncodeToString()
+		status = fasthttp.StatusOK
+	)
+	if soid != "" {
+		objID, err := utils.DecodeObjectID(soid)
+		if err != nil {
+			log.Error("wrong object id", zap.Error(err))
+			response.Error(c, "wrong object id", fasthttp.StatusBadRequest)
+			return
+		}
+		key = objID.EncodeToString()
+
+		if err = d.extCache.Invalidate(d.appCtx, *containerID, objID); err != nil {
+			log.Error("could not purge external cache", zap.Error(err))
+			status = fasthttp.StatusBadGateway
+		}
+	}
+
+	resp := purgeResponse{
+		Keys: []string{key},
+		CDN:  []purge.Result{},
+	}
+	// archives may contain the object, so all container archives are removed
+	if cache := d.settings.ZipCache(); cache != nil {
+		resp.Archives = cache.Purge(*containerID)
+	}
+
+	for _, r := range d.purgers.Purge(d.appCtx, resp.Keys) {
+		if r.Error != "" {
+			log.Error("could not purge CDN cache", zap.String("purger", r.Purger), zap.String("error", r.Error))
+			status = fasthttp.StatusBadGateway
+		}
+		resp.CDN = append(resp.CDN, r)
+	}
+
+	log.Info("purged", zap.Strings("keys", resp.Keys), zap.Int("archives", resp.Archives))
+
+	c.Response.SetStatusCode(status)
+	c.Response.Header.SetContentType(jsonHeader)
+	enc := json.NewEncoder(c)
+	enc.SetIndent("", "\t")
+	_ = enc.Encode(resp)
+}
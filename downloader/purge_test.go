@@ -0,0 +1,94 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/purge"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type testPurger struct {
+	keys []string
+	err  error
+}
+
+func (p *testPurger) Purge(_ context.Context, keys []string) error {
+	p.keys = keys
+	return p.err
+}
+
+func TestPurge(t *testing.T) {
+	cnrID, objID := cidtest.ID(), oidtest.ID()
+	cache := newTestZipCache(t, time.Hour, 100, 100)
+	cdn := &testPurger{}
+
+	d := &Downloader{
+		appCtx:   context.Background(),
+		log:      zap.NewNop(),
+		settings: &Settings{},
+		purgers:  purge.NewPurgers(),
+	}
+	d.settings.SetZipCache(cache)
+	d.purgers.Add("cdn", cdn)
+
+	request := func(token string, oid string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.Set(hdrPurgeToken, token)
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		if oid != "" {
+			c.SetUserValue("oid", oid)
+		}
+		d.Purge(&c)
+		return &c
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		require.Equal(t, fasthttp.StatusNotFound, request("", "").Response.StatusCode())
+	})
+
+	d.settings.SetPurgeToken("secret")
+
+	t.Run("invalid token", func(t *testing.T) {
+		require.Equal(t, fasthttp.StatusForbidden, request("invalid", "").Response.StatusCode())
+	})
+
+	t.Run("object", func(t *testing.T) {
+		putTestContainerArchive(t, cache, "key", cnrID, "archive")
+
+		c := request("secret", objID.EncodeToString())
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+		var resp purgeResponse
+		require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+		require.Equal(t, purgeResponse{
+			Keys:     []string{objID.EncodeToString()},
+			Archives: 1,
+			CDN:      []purge.Result{{Purger: "cdn"}},
+		}, resp)
+		require.Equal(t, []string{objID.EncodeToString()}, cdn.keys)
+	})
+
+	t.Run("CDN failure", func(t *testing.T) {
+		cdn.err = errors.New("failure")
+		defer func() { cdn.err = nil }()
+
+		c := request("secret", "")
+		require.Equal(t, fasthttp.StatusBadGateway, c.Response.StatusCode())
+		require.Equal(t, []string{cnrID.EncodeToString()}, cdn.keys)
+	})
+}
+
+func TestSurrogateKeys(t *testing.T) {
+	var resp fasthttp.Response
+	surrogateKeysToResponse(&resp, "cid", "oid")
+	require.Equal(t, "cid oid", string(resp.Header.Peek(hdrSurrogateKey)))
+	require.Equal(t, "cid,oid", string(resp.Header.Peek(hdrCacheTag)))
+}
@@ -0,0 +1,104 @@
+package downloader
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/qr"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Default and maximum sizes of QR code images in pixels.
+const (
+	qrDefaultSize = 256
+	qrMaxSize     = 2048
+)
+
+// QR handles requests to the PNG image of the QR code with the object
+// download URL.
+func (d *Downloader) QR(c *fasthttp.RequestCtx) {
+	d.byAddress(c, request.qrObject)
+}
+
+func (r request) qrObject(clnt backend.Backend, addr oid.Address, signer user.Signer) {
+	start := time.Now()
+	size := qrDefaultSize
+	if arg := r.QueryArgs().Peek("size"); len(arg) != 0 {
+		var err error
+		if size, err = strconv.Atoi(string(arg)); err != nil || size <= 0 || size > qrMaxSize {
+			response.Error(r.RequestCtx, "invalid size, it must be from 1 to "+strconv.Itoa(qrMaxSize), fasthttp.StatusBadRequest)
+			return
+		}
+	}
+
+	if r.canonical == nil && r.externalURL == "" {
+		response.Error(r.RequestCtx, "QR codes are disabled, external URL of the gate isn't configured", fasthttp.StatusNotFound)
+		return
+	}
+
+	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
+		r.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(r.RequestCtx, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var prm client.PrmObjectHead
+	if btoken := bearerToken(r.RequestCtx); btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+	ctx, cancel := r.requestContext()
+	defer cancel()
+
+	// the object is checked to exist, so codes aren't printed for broken links
+	hdr, err := clnt.ObjectHead(ctx, addr.Container(), addr.Object(), signer, prm)
+	if err != nil {
+		r.handleNeoFSErr(eacl.OperationHead, err, start)
+		return
+	}
+
+	code, err := qr.Encode([]byte(r.objectURL(hdr)))
+	if err != nil {
+		r.log.Error("could not encode QR code", zap.Error(err))
+		response.Error(r.RequestCtx, "could not encode QR code: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, code.Image(size/(code.Size+8))); err != nil {
+		r.log.Error("could not encode image", zap.Error(err))
+		response.Error(r.RequestCtx, "could not encode image: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	r.SetContentType("image/png")
+	r.SetStatusCode(fasthttp.StatusOK)
+	r.SetBody(buf.Bytes())
+}
+
+// objectURL returns the public download URL of the object. It's the
+// canonical link if canonical links are configured, otherwise the URL is
+// built from the external URL of the gate. Client Host header isn't trusted,
+// so codes can't be made to point to other sites.
+func (r request) objectURL(obj *object.Object) string {
+	prefix := utils.RoutePrefix(string(r.Path()), "/qr/")
+	if r.canonical != nil {
+		return r.canonical.URL(prefix, obj, false)
+	}
+
+	cnrID, _ := obj.ContainerID()
+	objID, _ := obj.ID()
+	return r.externalURL + prefix + "/get/" + url.PathEscape(cnrID.EncodeToString()) + "/" + objID.EncodeToString()
+}
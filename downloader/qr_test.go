@@ -0,0 +1,80 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestQR(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	id := putFile(t, mem, cnrID, "docs/cat.pdf", "meow")
+
+	settings := &Settings{}
+	settings.SetExternalURL("https://gate.example.com/")
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, settings, nil)
+
+	qr := func(path string, id oid.ID) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI(path)
+		c.Request.Header.SetHost("evil.example.com")
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		d.QR(&c)
+		return &c
+	}
+
+	c := qr("/qr/cnr/oid?size=500", id)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "image/png", string(c.Response.Header.ContentType()))
+	img, err := png.Decode(bytes.NewReader(c.Response.Body()))
+	require.NoError(t, err)
+	require.LessOrEqual(t, img.Bounds().Dx(), 500)
+	require.Greater(t, img.Bounds().Dx(), 400)
+	require.Equal(t, img.Bounds().Dx(), img.Bounds().Dy())
+
+	for _, size := range []string{"0", "-1", "meow", "4096"} {
+		c = qr("/qr/cnr/oid?size="+size, id)
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode(), size)
+	}
+
+	c = qr("/qr/cnr/oid", oid.ID{1})
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+
+	t.Run("no external url", func(t *testing.T) {
+		settings := &Settings{}
+		d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, settings, nil)
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/qr/cnr/oid")
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		d.QR(&c)
+		require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+	})
+
+	t.Run("url", func(t *testing.T) {
+		hdr, err := mem.ObjectHead(context.Background(), cnrID, id, nil, client.PrmObjectHead{})
+		require.NoError(t, err)
+
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/testnet/qr/cnr/oid")
+		c.Request.Header.SetHost("evil.example.com")
+		r := d.newRequest(&c, zap.NewNop())
+		require.Equal(t, "https://gate.example.com/testnet/get/"+cnrID.EncodeToString()+"/"+id.EncodeToString(), r.objectURL(hdr))
+
+		settings.SetCanonicalLinks(NewCanonicalLinks("https://neofs.example.org/", true, nil))
+		r = d.newRequest(&c, zap.NewNop())
+		require.Equal(t, "https://neofs.example.org/testnet/get/"+cnrID.EncodeToString()+"/"+id.EncodeToString(), r.objectURL(hdr))
+	})
+}
@@ -0,0 +1,120 @@
+package downloader
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/servertiming"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// DownloadRange handles requests of the object payload range given by offset
+// and length path parameters. Unlike Range header of GET requests, the range
+// is sent with 200 OK as a standalone body.
+func (d *Downloader) DownloadRange(c *fasthttp.RequestCtx) {
+	offsetParam, _ := c.UserValue("offset").(string)
+	lengthParam, _ := c.UserValue("length").(string)
+
+	offset, err := strconv.ParseUint(offsetParam, 10, 64)
+	if err != nil {
+		response.ErrorWithCode(c, utils.ErrCodeInvalidRange, "invalid range offset", fasthttp.StatusBadRequest)
+		return
+	}
+	length, err := strconv.ParseUint(lengthParam, 10, 64)
+	if err != nil || length == 0 {
+		response.ErrorWithCode(c, utils.ErrCodeInvalidRange, "invalid range length", fasthttp.StatusBadRequest)
+		return
+	}
+
+	d.byAddress(c, func(r request, clnt backend.Backend, addr oid.Address, signer user.Signer) {
+		r.receiveRange(clnt, addr, signer, offset, length)
+	})
+}
+
+// receiveRange sends the payload range with object attributes in headers the
+// same way as receiveFile does. The range must be within the payload,
+// otherwise 416 Range Not Satisfiable is returned.
+func (r request) receiveRange(clnt backend.Backend, objectAddress oid.Address, signer user.Signer, offset, length uint64) {
+	start := time.Now()
+	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
+		r.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(r.RequestCtx, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var prm client.PrmObjectHead
+	btoken := bearerToken(r.RequestCtx)
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	issuer, ok := enforceQuota(r.RequestCtx, r.log, r.usage, btoken)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := r.requestContext()
+	if btoken == nil {
+		ctx = extcache.Shareable(ctx)
+	}
+
+	headDone := servertiming.Start(r.RequestCtx, servertiming.Head)
+	hdr, err := clnt.ObjectHead(ctx, objectAddress.Container(), objectAddress.Object(), signer, prm)
+	headDone()
+	if err != nil {
+		cancel()
+		r.handleNeoFSErr(eacl.OperationHead, err, start)
+		return
+	}
+
+	payloadSize := hdr.PayloadSize()
+	if offset >= payloadSize || length > payloadSize-offset {
+		cancel()
+		r.log.Info("range is out of payload", zap.Uint64("offset", offset),
+			zap.Uint64("length", length), zap.Uint64("size", payloadSize))
+		response.Error(r.RequestCtx, "range is out of payload", fasthttp.StatusRequestedRangeNotSatisfiable)
+		r.Response.Header.Set(fasthttp.HeaderContentRange, "bytes */"+strconv.FormatUint(payloadSize, 10))
+		return
+	}
+
+	_, contentType := r.attributesToResponse(hdr)
+	idsToResponse(&r.Response, hdr)
+	r.signResponse(signer)
+
+	var prmRange client.PrmObjectRange
+	if btoken != nil {
+		prmRange.WithBearerToken(*btoken)
+	}
+	payload, err := clnt.ObjectRangeInit(ctx, objectAddress.Container(), objectAddress.Object(), offset, length, signer, prmRange)
+	if err != nil {
+		cancel()
+		r.handleNeoFSErr(eacl.OperationRange, err, start)
+		return
+	}
+	streamDone, cancelStream := r.streams.Open(instrument.StreamRange), cancel
+	cancel = func() {
+		streamDone()
+		cancelStream()
+	}
+
+	// the range isn't the beginning of the payload, so the type can't be
+	// detected
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	r.SetContentType(contentType)
+	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(length, 10))
+
+	r.sendPayload(payload, int(length), issuer, cancel)
+}
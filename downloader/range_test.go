@@ -0,0 +1,61 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestDownloadRange(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	id := putFile(t, mem, cnrID, "data.bin", "0123456789")
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	get := func(objID, offset, length string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", objID)
+		c.SetUserValue("offset", offset)
+		c.SetUserValue("length", length)
+		d.DownloadRange(&c)
+		return &c
+	}
+
+	c := get(id.EncodeToString(), "2", "3")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "234", string(c.Response.Body()))
+	require.Equal(t, 3, c.Response.Header.ContentLength())
+	require.Equal(t, "application/octet-stream", string(c.Response.Header.ContentType()))
+	require.Equal(t, "data.bin", string(c.Response.Header.Peek(utils.UserAttributeHeaderPrefix+object.AttributeFilePath)))
+	require.Equal(t, id.EncodeToString(), string(c.Response.Header.Peek(hdrObjectID)))
+
+	c = get(id.EncodeToString(), "0", "10")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "0123456789", string(c.Response.Body()))
+
+	for _, rng := range [][2]string{{"10", "1"}, {"5", "6"}, {"1", "18446744073709551615"}} {
+		c = get(id.EncodeToString(), rng[0], rng[1])
+		require.Equal(t, fasthttp.StatusRequestedRangeNotSatisfiable, c.Response.StatusCode(), rng)
+		require.Equal(t, "bytes */10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)), rng)
+	}
+
+	for _, rng := range [][2]string{{"0", "0"}, {"18446744073709551616", "1"}} {
+		c = get(id.EncodeToString(), rng[0], rng[1])
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode(), rng)
+		require.Equal(t, utils.ErrCodeInvalidRange, string(c.Response.Header.Peek(response.HeaderErrorCode)), rng)
+	}
+
+	c = get(oidtest.ID().EncodeToString(), "0", "1")
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+}
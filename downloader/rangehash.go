@@ -0,0 +1,182 @@
+package downloader
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxHashRanges limits the number of ranges hashed by a single request.
+const maxHashRanges = 64
+
+// Range hash types.
+const (
+	hashTypeSHA256 = "sha256"
+	hashTypeTZ     = "tz"
+)
+
+type rangeHash struct {
+	Offset uint64 `json:"offset"`
+	Length uint64 `json:"length"`
+	Hash   string `json:"hash"`
+}
+
+type rangeHashResponse struct {
+	ContainerID string      `json:"container_id"`
+	ObjectID    string      `json:"object_id"`
+	Type        string      `json:"type"`
+	Hashes      []rangeHash `json:"hashes"`
+}
+
+// Proto implements response.ProtoValue.
+func (r rangeHashResponse) Proto() proto.Message {
+	hashes := make([]*pb.RangeHash, len(r.Hashes))
+	for i, h := range r.Hashes {
+		hashes[i] = &pb.RangeHash{Offset: h.Offset, Length: h.Length, Hash: h.Hash}
+	}
+	return &pb.RangeHashes{
+		ContainerId: r.ContainerID,
+		ObjectId:    r.ObjectID,
+		Type:        r.Type,
+		Hashes:      hashes,
+	}
+}
+
+// parseHashType parses the hash type, SHA-256 is used by default.
+func parseHashType(s string) (checksum.Type, error) {
+	switch s {
+	case "", hashTypeSHA256:
+		return checksum.SHA256, nil
+	case hashTypeTZ:
+		return checksum.TZ, nil
+	default:
+		return 0, fmt.Errorf("unknown hash type '%s'", s)
+	}
+}
+
+// parseHashRanges parses comma-separated ranges in 'start-end' form with
+// exclusive end to the list of (offset, length) pairs.
+func parseHashRanges(s string) ([]uint64, error) {
+	if s == "" {
+		return nil, errors.New("no ranges")
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) > maxHashRanges {
+		return nil, fmt.Errorf("too many ranges, max is %d", maxHashRanges)
+	}
+
+	res := make([]uint64, 0, 2*len(parts))
+	for _, part := range parts {
+		startStr, endStr, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid range '%s'", part)
+		}
+		start, err := strconv.ParseUint(startStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range '%s' start: %w", part, err)
+		}
+		end, err := strconv.ParseUint(endStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range '%s' end: %w", part, err)
+		}
+		if end <= start {
+			return nil, fmt.Errorf("empty range '%s'", part)
+		}
+		res = append(res, start, end-start)
+	}
+	return res, nil
+}
+
+// RangeHash handles requests of payload range checksums, so clients can
+// verify ranges without downloading them.
+func (d *Downloader) RangeHash(c *fasthttp.RequestCtx) {
+	args := c.QueryArgs()
+	typ, err := parseHashType(string(args.Peek("type")))
+	if err != nil {
+		d.log.Error("invalid hash type", zap.Error(err))
+		response.Error(c, "invalid hash type: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	ranges, err := parseHashRanges(string(args.Peek("ranges")))
+	if err != nil {
+		d.log.Error("invalid hashed ranges", zap.Error(err))
+		response.Error(c, "invalid ranges: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	if !d.supports(c, d.log, compat.FeatureRangeHash) {
+		return
+	}
+
+	d.byAddress(c, func(r request, clnt backend.Backend, addr oid.Address, signer user.Signer) {
+		r.hashRanges(clnt, addr, signer, typ, ranges)
+	})
+}
+
+// hashRanges responds with checksums of the payload ranges. Ranges out of the
+// payload get 416 Range Not Satisfiable.
+func (r request) hashRanges(clnt backend.Backend, objectAddress oid.Address, signer user.Signer, typ checksum.Type, ranges []uint64) {
+	start := time.Now()
+	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
+		r.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(r.RequestCtx, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var prm client.PrmObjectHash
+	if btoken := bearerToken(r.RequestCtx); btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	ctx, cancel := r.requestContext()
+	defer cancel()
+
+	hashes, err := clnt.ObjectHash(ctx, objectAddress.Container(), objectAddress.Object(), signer, typ, ranges, prm)
+	if errors.Is(err, apistatus.ErrObjectOutOfRange) {
+		r.log.Info("hashed range is out of payload", zap.Error(err))
+		response.Error(r.RequestCtx, "range is out of payload", fasthttp.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if err == nil && len(hashes) != len(ranges)/2 {
+		err = fmt.Errorf("%d hashes received for %d ranges", len(hashes), len(ranges)/2)
+	}
+	if err != nil {
+		r.handleNeoFSErr(eacl.OperationRangeHash, err, start)
+		return
+	}
+
+	resp := rangeHashResponse{
+		ContainerID: objectAddress.Container().EncodeToString(),
+		ObjectID:    objectAddress.Object().EncodeToString(),
+		Type:        hashTypeSHA256,
+		Hashes:      make([]rangeHash, len(hashes)),
+	}
+	if typ == checksum.TZ {
+		resp.Type = hashTypeTZ
+	}
+	for i, h := range hashes {
+		resp.Hashes[i] = rangeHash{
+			Offset: ranges[2*i],
+			Length: ranges[2*i+1],
+			Hash:   hex.EncodeToString(h),
+		}
+	}
+	response.Write(r.RequestCtx, fasthttp.StatusOK, resp)
+}
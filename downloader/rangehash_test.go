@@ -0,0 +1,74 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/tzhash/tz"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestParseHashRanges(t *testing.T) {
+	ranges, err := parseHashRanges("0-1024,2048-4096")
+	require.NoError(t, err)
+	require.Equal(t, []uint64{0, 1024, 2048, 2048}, ranges)
+
+	for _, s := range []string{"", "5", "5-5", "6-5", "a-5", "1-b", "0-1,"} {
+		_, err = parseHashRanges(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestRangeHash(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	id := putFile(t, mem, cnrID, "data.bin", "0123456789")
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	get := func(query string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/range_hash/" + cnrID.EncodeToString() + "/" + id.EncodeToString() + "?" + query)
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		d.RangeHash(&c)
+		return &c
+	}
+
+	c := get("ranges=0-4,5-10")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+	var resp rangeHashResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	first, second := sha256.Sum256([]byte("0123")), sha256.Sum256([]byte("56789"))
+	require.Equal(t, rangeHashResponse{
+		ContainerID: cnrID.EncodeToString(),
+		ObjectID:    id.EncodeToString(),
+		Type:        hashTypeSHA256,
+		Hashes: []rangeHash{
+			{Offset: 0, Length: 4, Hash: hex.EncodeToString(first[:])},
+			{Offset: 5, Length: 5, Hash: hex.EncodeToString(second[:])},
+		},
+	}, resp)
+
+	c = get("ranges=2-4&type=tz")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	h := tz.Sum([]byte("23"))
+	require.Equal(t, hashTypeTZ, resp.Type)
+	require.Equal(t, []rangeHash{{Offset: 2, Length: 2, Hash: hex.EncodeToString(h[:])}}, resp.Hashes)
+
+	c = get("ranges=5-11")
+	require.Equal(t, fasthttp.StatusRequestedRangeNotSatisfiable, c.Response.StatusCode())
+
+	c = get("ranges=0-1&type=md5")
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+}
@@ -0,0 +1,91 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// rawPayloadTag is the protobuf tag of the payload field (4, length-delimited)
+// of the NeoFS object message. The payload is the last field of the object
+// marshaled in the stable format, so it's streamed after the header.
+const rawPayloadTag = 4<<3 | 2
+
+// DownloadRaw handles requests of the complete objects in the NeoFS binary
+// format (protobuf message with the ID, the signature, the header and the
+// payload), so clients can verify them offline or store them elsewhere as is.
+func (d *Downloader) DownloadRaw(c *fasthttp.RequestCtx) {
+	d.byAddress(c, request.receiveRaw)
+}
+
+// rawObjectPrefix returns the marshaled object without the payload followed
+// by the payload field prefix, the object is marshaled completely once the
+// payload is appended.
+func rawObjectPrefix(hdr *object.Object) []byte {
+	hdr.SetPayload(nil)
+	data, _ := hdr.Marshal()
+	if size := hdr.PayloadSize(); size > 0 {
+		data = binary.AppendUvarint(append(data, rawPayloadTag), size)
+	}
+	return data
+}
+
+func (r request) receiveRaw(clnt backend.Backend, addr oid.Address, signer user.Signer) {
+	start := time.Now()
+	if err := tokens.StoreBearerToken(r.RequestCtx); err != nil {
+		r.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(r.RequestCtx, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var prm client.PrmObjectGet
+	btoken := bearerToken(r.RequestCtx)
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	issuer, ok := enforceQuota(r.RequestCtx, r.log, r.usage, btoken)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := r.requestContext()
+	if btoken == nil {
+		ctx = extcache.Shareable(ctx)
+	}
+
+	hdr, payload, err := clnt.ObjectGetInit(ctx, addr.Container(), addr.Object(), signer, prm)
+	if err != nil {
+		cancel()
+		r.handleNeoFSErr(eacl.OperationGet, err, start)
+		return
+	}
+	streamDone, cancelStream := r.streams.Open(instrument.StreamGet), cancel
+	cancel = func() {
+		streamDone()
+		cancelStream()
+	}
+
+	idsToResponse(&r.Response, &hdr)
+	r.signResponse(signer)
+	r.SetContentType("application/octet-stream")
+	r.Response.Header.Set(fasthttp.HeaderContentDisposition, "attachment; filename="+addr.Object().EncodeToString())
+
+	prefix := rawObjectPrefix(&hdr)
+	r.sendPayload(readCloser{io.MultiReader(bytes.NewReader(prefix), payload), payload},
+		len(prefix)+int(hdr.PayloadSize()), issuer, cancel)
+}
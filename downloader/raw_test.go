@@ -0,0 +1,56 @@
+package downloader
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestDownloadRaw(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	get := func(id oid.ID) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		d.DownloadRaw(&c)
+		return &c
+	}
+
+	// payloads longer than 127 bytes have multi-byte length prefix
+	for _, payload := range []string{"", "meow", strings.Repeat("purr", 100)} {
+		id := putFile(t, mem, cnrID, "cat.txt", payload)
+		c := get(id)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, id.EncodeToString(), string(c.Response.Header.Peek(hdrObjectID)))
+
+		var addr oid.Address
+		addr.SetContainer(cnrID)
+		addr.SetObject(id)
+		stored, ok := mem.Object(addr)
+		require.True(t, ok)
+		expected, err := stored.Marshal()
+		require.NoError(t, err)
+		body := c.Response.Body()
+		require.Equal(t, expected, body)
+
+		var obj object.Object
+		require.NoError(t, obj.Unmarshal(body))
+		require.Equal(t, payload, string(obj.Payload()))
+	}
+
+	c := get(oidtest.ID())
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+}
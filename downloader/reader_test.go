@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"bytes"
 	"io"
 	"strings"
 	"testing"
@@ -44,3 +45,18 @@ func TestDetector(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkDetector(b *testing.B) {
+	payload := bytes.Repeat([]byte("<html><body>Some html content.</body></html>"), 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := readContentType(uint64(len(payload)), func(uint64) (io.Reader, error) {
+			return bytes.NewReader(payload), nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package downloader
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// parseObjectRange parses a single byte range of the payload of the given
+// size, unlike archives objects can be requested by suffix ranges.
+func parseObjectRange(s string, size uint64) (byteRange, error) {
+	if !strings.HasPrefix(s, "bytes=-") {
+		return parseByteRange(s)
+	}
+
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "bytes=-"), 10, 64)
+	if err != nil {
+		return byteRange{}, errors.New("invalid suffix range length")
+	}
+	if n == 0 {
+		return byteRange{}, errors.New("empty suffix range")
+	}
+	if n > size {
+		n = size
+	}
+	return byteRange{start: size - n, end: size - 1, hasEnd: true}, nil
+}
+
+// ifRangeMatches checks whether If-Range request header matches the object,
+// the header is either the quoted object ID or its Last-Modified date. Objects
+// are immutable, so both are strong validators.
+func ifRangeMatches(h *fasthttp.RequestHeader, id oid.ID, lastModified []byte) bool {
+	val := h.Peek(fasthttp.HeaderIfRange)
+	if len(val) == 0 {
+		return true
+	}
+	return string(val) == strconv.Quote(id.EncodeToString()) ||
+		len(lastModified) != 0 && string(val) == string(lastModified)
+}
+
+// objectRequestedRange returns the range of the object payload to send. Range
+// is ignored if If-Range header doesn't match the object, so the whole
+// payload is sent. Returns false if the response is already sent.
+func objectRequestedRange(c *fasthttp.RequestCtx, id oid.ID, size uint64) (*byteRange, bool) {
+	header := c.Request.Header.Peek(fasthttp.HeaderRange)
+	if len(header) == 0 ||
+		!ifRangeMatches(&c.Request.Header, id, c.Response.Header.Peek(fasthttp.HeaderLastModified)) {
+		return nil, true
+	}
+
+	r, err := parseObjectRange(string(header), size)
+	if err == nil && size == 0 {
+		err = errors.New("payload is empty")
+	}
+	if err != nil {
+		response.Error(c, "invalid range: "+err.Error(), fasthttp.StatusRequestedRangeNotSatisfiable)
+		c.Response.Header.Set(fasthttp.HeaderContentRange, "bytes */"+strconv.FormatUint(size, 10))
+		return nil, false
+	}
+	if !r.limit(c, size) {
+		return nil, false
+	}
+	return &r, true
+}
+
+// pinnedObject checks that the object pinned by X-Object-Id request header
+// has the attribute, so resumed downloads by attribute get the same object
+// even if another one with the attribute is uploaded. Responds with 412
+// Precondition Failed if it doesn't. Returns false if the response is already
+// sent.
+func (d *Downloader) pinnedObject(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, key, val, pinned string) (oid.ID, bool) {
+	objID, err := utils.DecodeObjectID(pinned)
+	if err != nil {
+		log.Error("wrong pinned object id", zap.Error(err))
+		response.Error(c, "wrong pinned object id", fasthttp.StatusBadRequest)
+		return objID, false
+	}
+
+	var prm client.PrmObjectHead
+	ctx := d.appCtx
+	if btoken := bearerToken(c); btoken != nil {
+		prm.WithBearerToken(*btoken)
+	} else {
+		ctx = extcache.Shareable(ctx)
+	}
+
+	hdr, err := d.backend.ObjectHead(ctx, cnrID, objID, d.signer, prm)
+	if err != nil && !errors.Is(err, apistatus.ErrObjectNotFound) && !errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+		d.newRequest(c, log).handleNeoFSErr(eacl.OperationHead, err, time.Now())
+		return objID, false
+	}
+	if err == nil {
+		for _, attr := range hdr.Attributes() {
+			if attr.Key() == key && attr.Value() == val {
+				return objID, true
+			}
+		}
+	}
+
+	log.Info("pinned object doesn't match the attribute", zap.Stringer("oid", objID), zap.Error(err))
+	response.Error(c, "pinned object doesn't match the attribute", fasthttp.StatusPreconditionFailed)
+	return objID, false
+}
@@ -0,0 +1,161 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestParseObjectRange(t *testing.T) {
+	for s, expected := range map[string]byteRange{
+		"bytes=5-":   {start: 5},
+		"bytes=2-4":  {start: 2, end: 4, hasEnd: true},
+		"bytes=-3":   {start: 7, end: 9, hasEnd: true},
+		"bytes=-100": {start: 0, end: 9, hasEnd: true},
+	} {
+		r, err := parseObjectRange(s, 10)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, r, s)
+	}
+
+	for _, s := range []string{"bytes=-0", "bytes=-x", "bytes=1-2,4-5", "items=1-2", "bytes=5-2"} {
+		_, err := parseObjectRange(s, 10)
+		require.Error(t, err, s)
+	}
+}
+
+func putFile(t *testing.T, mem *backend.Memory, cnrID cid.ID, filePath, payload string) oid.ID {
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeFilePath)
+	attr.SetValue(filePath)
+	contentType := object.NewAttribute()
+	contentType.SetKey(object.AttributeContentType)
+	contentType.SetValue("application/octet-stream")
+	hdr.SetAttributes(*attr, *contentType)
+
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func TestResumeDownload(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	id := putFile(t, mem, cnrID, "data.bin", "0123456789")
+	other := putFile(t, mem, cnrID, "other.bin", "abc")
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	get := func(h fasthttp.RequestHandler, headers map[string]string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "data.bin")
+		for k, v := range headers {
+			c.Request.Header.Set(k, v)
+		}
+		h(&c)
+		return &c
+	}
+
+	t.Run("full", func(t *testing.T) {
+		c := get(d.DownloadByAddress, nil)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, "bytes", string(c.Response.Header.Peek(fasthttp.HeaderAcceptRanges)))
+		require.Equal(t, "0123456789", string(c.Response.Body()))
+
+		c = get(d.HeadByAddress, nil)
+		require.Equal(t, "bytes", string(c.Response.Header.Peek(fasthttp.HeaderAcceptRanges)))
+	})
+
+	t.Run("continue at", func(t *testing.T) {
+		// curl --continue-at and wget --continue
+		c := get(d.DownloadByAddress, map[string]string{fasthttp.HeaderRange: "bytes=4-"})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+		require.Equal(t, "bytes 4-9/10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)))
+		require.Equal(t, "456789", string(c.Response.Body()))
+		require.Equal(t, 6, c.Response.Header.ContentLength())
+	})
+
+	t.Run("ranges", func(t *testing.T) {
+		c := get(d.DownloadByAddress, map[string]string{fasthttp.HeaderRange: "bytes=2-4"})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+		require.Equal(t, "234", string(c.Response.Body()))
+
+		c = get(d.DownloadByAddress, map[string]string{fasthttp.HeaderRange: "bytes=-3"})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+		require.Equal(t, "789", string(c.Response.Body()))
+
+		c = get(d.DownloadByAddress, map[string]string{fasthttp.HeaderRange: "bytes=8-100"})
+		require.Equal(t, "bytes 8-9/10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)))
+		require.Equal(t, "89", string(c.Response.Body()))
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		c := get(d.DownloadByAddress, map[string]string{fasthttp.HeaderRange: "bytes=10-"})
+		require.Equal(t, fasthttp.StatusRequestedRangeNotSatisfiable, c.Response.StatusCode())
+		require.Equal(t, "bytes */10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)))
+
+		c = get(d.DownloadByAddress, map[string]string{fasthttp.HeaderRange: "bytes=1-2,5-6"})
+		require.Equal(t, fasthttp.StatusRequestedRangeNotSatisfiable, c.Response.StatusCode())
+	})
+
+	t.Run("if-range", func(t *testing.T) {
+		c := get(d.DownloadByAddress, map[string]string{
+			fasthttp.HeaderRange:   "bytes=4-",
+			fasthttp.HeaderIfRange: `"` + id.EncodeToString() + `"`,
+		})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+
+		c = get(d.DownloadByAddress, map[string]string{
+			fasthttp.HeaderRange:   "bytes=4-",
+			fasthttp.HeaderIfRange: `"` + other.EncodeToString() + `"`,
+		})
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, "0123456789", string(c.Response.Body()))
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		c := get(d.DownloadByAttribute, map[string]string{
+			fasthttp.HeaderRange: "bytes=4-",
+			hdrObjectID:          id.EncodeToString(),
+		})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+		require.Equal(t, id.EncodeToString(), string(c.Response.Header.Peek(hdrObjectID)))
+		require.Equal(t, "456789", string(c.Response.Body()))
+
+		// the object has been replaced by another one with the same path
+		newID := putFile(t, mem, cnrID, "data.bin", "new data")
+		for _, pinned := range []oid.ID{other, oidtest.ID()} {
+			c = get(d.DownloadByAttribute, map[string]string{
+				fasthttp.HeaderRange: "bytes=4-",
+				hdrObjectID:          pinned.EncodeToString(),
+			})
+			require.Equal(t, fasthttp.StatusPreconditionFailed, c.Response.StatusCode())
+		}
+
+		c = get(d.HeadByAttribute, map[string]string{hdrObjectID: newID.EncodeToString()})
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, newID.EncodeToString(), string(c.Response.Header.Peek(hdrObjectID)))
+
+		c = get(d.DownloadByAttribute, map[string]string{hdrObjectID: "invalid"})
+		require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	})
+}
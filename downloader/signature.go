@@ -0,0 +1,52 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	hdrPayloadChecksum = "X-Payload-Checksum"
+	hdrGateSignature   = "X-Gate-Signature"
+)
+
+// signedHeaders are response headers covered by the gate signature in the
+// order they're signed.
+var signedHeaders = []string{hdrContainerID, hdrObjectID, hdrOwnerID, hdrPayloadChecksum}
+
+// headersSigningData returns data to sign: `<lowercase name>:<value>\n` line
+// for each of signedHeaders, missing headers have empty values.
+func headersSigningData(h *fasthttp.ResponseHeader) []byte {
+	var buf bytes.Buffer
+	for _, name := range signedHeaders {
+		buf.WriteString(strings.ToLower(name))
+		buf.WriteByte(':')
+		buf.Write(h.Peek(name))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// signHeaders signs signedHeaders with the gate key and sets X-Gate-Signature
+// header with the hex-encoded public key, signature scheme, signed headers
+// and base64-encoded signature.
+func signHeaders(h *fasthttp.ResponseHeader, signer neofscrypto.Signer) error {
+	sig, err := signer.Sign(headersSigningData(h))
+	if err != nil {
+		return fmt.Errorf("sign headers: %w", err)
+	}
+
+	h.Set(hdrGateSignature, fmt.Sprintf(`keyId="%s", algorithm="%s", headers="%s", signature="%s"`,
+		hex.EncodeToString(neofscrypto.PublicKeyBytes(signer.Public())),
+		signer.Scheme(),
+		strings.ToLower(strings.Join(signedHeaders, " ")),
+		base64.StdEncoding.EncodeToString(sig),
+	))
+	return nil
+}
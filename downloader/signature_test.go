@@ -0,0 +1,41 @@
+package downloader
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSignHeaders(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+
+	var h fasthttp.ResponseHeader
+	h.Set(hdrContainerID, "container")
+	h.Set(hdrObjectID, "object")
+	h.Set(hdrOwnerID, "owner")
+
+	require.Equal(t, "x-container-id:container\nx-object-id:object\nx-owner-id:owner\nx-payload-checksum:\n",
+		string(headersSigningData(&h)))
+
+	require.NoError(t, signHeaders(&h, signer))
+
+	m := regexp.MustCompile(`^keyId="([0-9a-f]+)", algorithm="ECDSA_RFC6979_SHA256", headers="x-container-id x-object-id x-owner-id x-payload-checksum", signature="(.+)"$`).
+		FindStringSubmatch(string(h.Peek(hdrGateSignature)))
+	require.Len(t, m, 3)
+	require.Equal(t, hex.EncodeToString(key.PublicKey().Bytes()), m[1])
+
+	sig, err := base64.StdEncoding.DecodeString(m[2])
+	require.NoError(t, err)
+	require.True(t, signer.Public().Verify(headersSigningData(&h), sig))
+
+	h.Set(hdrObjectID, "other")
+	require.False(t, signer.Public().Verify(headersSigningData(&h), sig))
+}
@@ -0,0 +1,306 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/searchlimit"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// errTooManyObjects is returned if the tail search matches more objects than
+// allowed.
+var errTooManyObjects = errors.New("too many objects match the filter")
+
+// TailConfig contains parameters of log objects tailing.
+type TailConfig struct {
+	// DefaultBytes is the number of the last bytes of the newest object served
+	// if the request doesn't specify it.
+	DefaultBytes uint64
+	// MaxBytes limits the number of the served last bytes.
+	MaxBytes uint64
+	// MaxObjects limits the number of objects matching the filter, they're
+	// all requested to find the newest one. When following, it limits the
+	// number of new objects found by a single poll.
+	MaxObjects int
+	// PollInterval is the interval to search for new objects when following.
+	PollInterval time.Duration
+	// MaxFollow limits the time new objects are streamed for, zero means no
+	// limit.
+	MaxFollow time.Duration
+}
+
+// sortByTime sorts objects by their timestamps, objects with the same
+// timestamp are sorted by ID.
+func sortByTime(objs []browseObject) {
+	sort.Slice(objs, func(i, j int) bool {
+		if !objs[i].Modified.Equal(objs[j].Modified) {
+			return objs[i].Modified.Before(objs[j].Modified)
+		}
+		return compareIDs(objs[i].ID, objs[j].ID) < 0
+	})
+}
+
+// tailRange returns the range of the last n bytes of the payload.
+func tailRange(size, n uint64) (uint64, uint64) {
+	if n > size {
+		n = size
+	}
+	return size - n, n
+}
+
+// Tail handles requests to the last bytes of the newest object matching the
+// attribute filter. With `follow` query argument the objects matching the
+// filter later are streamed too.
+func (d *Downloader) Tail(c *fasthttp.RequestCtx) {
+	var (
+		start   = time.Now()
+		scid, _ = c.UserValue("cid").(string)
+		log     = d.log.With(zap.String("cid", scid))
+		cfg     = d.settings.Tail()
+	)
+
+	if cfg == nil {
+		response.Error(c, "tail is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	args := c.QueryArgs()
+	filters, err := parseAttributeFilters(args, "attr")
+	if err == nil && len(filters) == 1 {
+		err = errors.New("no attribute filter, expected 'attr=Key=Value'")
+	}
+	if err != nil {
+		log.Error("could not parse filters", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	n := cfg.DefaultBytes
+	if raw := args.Peek("bytes"); len(raw) != 0 {
+		if n, err = strconv.ParseUint(string(raw), 10, 64); err != nil {
+			log.Error("invalid number of bytes", zap.Error(err))
+			response.Error(c, "invalid number of bytes: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+	}
+	if cfg.MaxBytes > 0 && n > cfg.MaxBytes {
+		n = cfg.MaxBytes
+	}
+
+	btoken := bearerToken(c)
+	// the stream writer is called after the handler returns, so the limits
+	// are loaded in advance
+	limits := searchlimit.Load(c)
+	objs, err := d.tailObjects(*containerID, filters, btoken, cfg.MaxObjects, limits, nil)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		switch {
+		case errors.Is(err, apistatus.ErrContainerNotFound):
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		case errors.Is(err, errTooManyObjects):
+			response.Error(c, err.Error(), fasthttp.StatusUnprocessableEntity)
+		default:
+			response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		}
+		return
+	}
+	if len(objs) == 0 {
+		log.Error("object not found")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return
+	}
+
+	seen := make(map[oid.ID]struct{}, len(objs))
+	for _, obj := range objs {
+		seen[obj.ID] = struct{}{}
+	}
+	newest := objs[len(objs)-1]
+
+	var prm client.PrmObjectRange
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+	var payload io.ReadCloser = io.NopCloser(bytes.NewReader(nil))
+	offset, length := tailRange(newest.Size, n)
+	if length > 0 {
+		rng, err := d.backend.ObjectRangeInit(d.appCtx, *containerID, newest.ID, offset, length, d.signer, prm)
+		if err != nil {
+			d.newRequest(c, log).handleNeoFSErr(eacl.OperationRange, err, start)
+			return
+		}
+		streamDone := d.streams.Open(instrument.StreamRange)
+		payload = readCloser{rng, closerFunc(func() error {
+			defer streamDone()
+			return rng.Close()
+		})}
+	}
+
+	contentType := newest.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Response.Header.SetContentType(contentType)
+	c.Response.Header.Set(hdrObjectID, newest.ID.EncodeToString())
+	c.SetStatusCode(fasthttp.StatusOK)
+
+	if !args.GetBool("follow") {
+		c.Response.SetBodyStream(payload, int(length))
+		return
+	}
+
+	log = log.With(zap.Stringer("oid", newest.ID))
+	log.Info("following objects")
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		_, err := io.Copy(w, payload)
+		_ = payload.Close()
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			log.Warn("could not write object tail", zap.Error(err))
+			return
+		}
+		d.follow(w, *containerID, filters, btoken, cfg, limits, seen, log)
+	})
+}
+
+// follow polls for new objects matching the filter and writes their payloads
+// until the client goes away or the time is over.
+func (d *Downloader) follow(w *bufio.Writer, cnrID cid.ID, filters object.SearchFilters, btoken *bearer.Token,
+	cfg *TailConfig, limits searchlimit.Limits, seen map[oid.ID]struct{}, log *zap.Logger) {
+	var deadline <-chan time.Time
+	if cfg.MaxFollow > 0 {
+		timer := time.NewTimer(cfg.MaxFollow)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	var prm client.PrmObjectGet
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	for {
+		select {
+		case <-d.appCtx.Done():
+			return
+		case <-deadline:
+			log.Info("follow time is over")
+			return
+		case <-ticker.C:
+		}
+
+		objs, err := d.tailObjects(cnrID, filters, btoken, cfg.MaxObjects, limits, seen)
+		if err != nil {
+			log.Error("could not search for new objects", zap.Error(err))
+			return
+		}
+
+		for _, obj := range objs {
+			seen[obj.ID] = struct{}{}
+			if err = d.writePayload(w, cnrID, obj.ID, prm); err != nil {
+				log.Warn("could not write object", zap.Stringer("object", obj.ID), zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+func (d *Downloader) writePayload(w *bufio.Writer, cnrID cid.ID, id oid.ID, prm client.PrmObjectGet) error {
+	_, payload, err := d.backend.ObjectGetInit(d.appCtx, cnrID, id, d.signer, prm)
+	if err != nil {
+		return err
+	}
+	defer d.streams.Open(instrument.StreamGet)()
+
+	_, err = io.Copy(w, payload)
+	if closeErr := payload.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// tailObjects finds objects matching the filters except the seen ones and
+// returns them sorted by time. Only the objects not seen yet are limited by
+// max, so a followed log can grow beyond it.
+func (d *Downloader) tailObjects(cnrID cid.ID, filters object.SearchFilters, btoken *bearer.Token, max int,
+	limits searchlimit.Limits, seen map[oid.ID]struct{}) ([]browseObject, error) {
+	var prm client.PrmObjectSearch
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	res, err := searchlimit.Search(d.appCtx, limits, func(ctx context.Context) (backend.ObjectLister, error) {
+		return d.backend.ObjectSearchInit(ctx, cnrID, d.signer, filters, prm)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		if _, ok := seen[id]; !ok {
+			ids = append(ids, id)
+		}
+		return max > 0 && len(ids) > max
+	})
+	_ = res.Close()
+	if err != nil {
+		return nil, err
+	}
+	if max > 0 && len(ids) > max {
+		return nil, fmt.Errorf("%w, max is %d", errTooManyObjects, max)
+	}
+
+	objs := make([]browseObject, 0, len(ids))
+	for _, id := range ids {
+		obj, err := d.browseObject(cnrID, id, btoken)
+		if err != nil {
+			if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+				continue
+			}
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	sortByTime(objs)
+	return objs, nil
+}
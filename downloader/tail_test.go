@@ -0,0 +1,120 @@
+package downloader
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestTailRange(t *testing.T) {
+	for _, tc := range []struct {
+		size, n, offset, length uint64
+	}{
+		{10, 3, 7, 3},
+		{10, 10, 0, 10},
+		{10, 20, 0, 10},
+		{10, 0, 10, 0},
+		{0, 5, 0, 0},
+	} {
+		offset, length := tailRange(tc.size, tc.n)
+		require.Equal(t, tc.offset, offset, tc)
+		require.Equal(t, tc.length, length, tc)
+	}
+}
+
+func putLogObject(t *testing.T, mem *backend.Memory, cnrID cid.ID, log string, ts int64, payload string) oid.ID {
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	attrs := make([]object.Attribute, 0, 2)
+	for k, v := range map[string]string{"Log": log, object.AttributeTimestamp: strconv.FormatInt(ts, 10)} {
+		attr := object.NewAttribute()
+		attr.SetKey(k)
+		attr.SetValue(v)
+		attrs = append(attrs, *attr)
+	}
+	hdr.SetAttributes(attrs...)
+
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func TestTail(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	putLogObject(t, mem, cnrID, "app", 100, "first line\n")
+	newest := putLogObject(t, mem, cnrID, "app", 300, "third line\n")
+	putLogObject(t, mem, cnrID, "app", 200, "second line\n")
+	putLogObject(t, mem, cnrID, "db", 400, "other log\n")
+
+	settings := &Settings{}
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, settings, nil)
+
+	tail := func(query string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/tail/" + cnrID.EncodeToString() + "?" + query)
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		d.Tail(&c)
+		return &c
+	}
+
+	c := tail("attr=Log=app")
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode(), "disabled")
+
+	settings.SetTail(&TailConfig{DefaultBytes: 5, MaxBytes: 8, MaxObjects: 3, PollInterval: 10 * time.Millisecond, MaxFollow: 300 * time.Millisecond})
+
+	c = tail("attr=Log=app")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "line\n", string(c.Response.Body()))
+	require.Equal(t, newest.EncodeToString(), string(c.Response.Header.Peek(hdrObjectID)))
+
+	c = tail("attr=Log=app&bytes=100")
+	require.Equal(t, "rd line\n", string(c.Response.Body()), "limited by max bytes")
+
+	c = tail("attr=Log=app&bytes=0")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Empty(t, c.Response.Body())
+
+	c = tail("")
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+
+	c = tail("attr=Log=web")
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+
+	putLogObject(t, mem, cnrID, "app", 50, "late line\n")
+	c = tail("attr=Log=app")
+	require.Equal(t, fasthttp.StatusUnprocessableEntity, c.Response.StatusCode())
+
+	t.Run("follow", func(t *testing.T) {
+		// the followed log grows beyond the limit
+		settings.SetTail(&TailConfig{DefaultBytes: 5, MaxObjects: 4, PollInterval: 10 * time.Millisecond, MaxFollow: 300 * time.Millisecond})
+
+		c := tail("attr=Log=app&follow=true")
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			putLogObject(t, mem, cnrID, "app", 500, "fourth line\n")
+			putLogObject(t, mem, cnrID, "db", 500, "other log\n")
+		}()
+
+		// body is read until the follow time is over
+		require.Equal(t, "line\nfourth line\n", string(c.Response.Body()))
+	})
+}
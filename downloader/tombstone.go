@@ -0,0 +1,244 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxInspectedTombstones limits the number of tombstones read looking for
+	// the one removing the object.
+	maxInspectedTombstones = 1000
+	// maxTombstonePayloadSize limits the size of tombstone payload to read.
+	maxTombstonePayloadSize = 4 << 20
+)
+
+// Object states reported by tombstone inspection.
+const (
+	objectStateAvailable = "available"
+	objectStateRemoved   = "removed"
+	objectStateNotFound  = "not_found"
+)
+
+type tombstoneInfo struct {
+	ID              string `json:"id"`
+	Owner           string `json:"owner,omitempty"`
+	CreationEpoch   uint64 `json:"creation_epoch"`
+	ExpirationEpoch uint64 `json:"expiration_epoch"`
+	Members         int    `json:"members"`
+}
+
+type tombstoneResponse struct {
+	ContainerID string         `json:"container_id"`
+	ObjectID    string         `json:"object_id"`
+	State       string         `json:"state"`
+	Tombstone   *tombstoneInfo `json:"tombstone,omitempty"`
+	// Truncated is set if not all tombstones of the container were inspected.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Proto implements response.ProtoValue.
+func (r tombstoneResponse) Proto() proto.Message {
+	m := &pb.TombstoneStatus{
+		ContainerId: r.ContainerID,
+		ObjectId:    r.ObjectID,
+		State:       r.State,
+		Truncated:   r.Truncated,
+	}
+	if t := r.Tombstone; t != nil {
+		m.Tombstone = &pb.Tombstone{
+			Id:              t.ID,
+			Owner:           t.Owner,
+			CreationEpoch:   t.CreationEpoch,
+			ExpirationEpoch: t.ExpirationEpoch,
+			Members:         int64(t.Members),
+		}
+	}
+	return m
+}
+
+// Tombstone handles requests to check whether the object is removed and
+// by which tombstone.
+func (d *Downloader) Tombstone(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		soid, _ = c.UserValue("oid").(string)
+		log     = d.log.With(zap.String("cid", scid), zap.String("oid", soid))
+	)
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	objID, err := utils.DecodeObjectID(soid)
+	if err != nil {
+		log.Error("wrong object id", zap.Error(err))
+		response.Error(c, "wrong object id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	btoken := bearerToken(c)
+	resp := tombstoneResponse{
+		ContainerID: containerID.EncodeToString(),
+		ObjectID:    objID.EncodeToString(),
+	}
+
+	var prm client.PrmObjectHead
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	_, err = d.backend.ObjectHead(d.appCtx, *containerID, objID, d.signer, prm)
+	switch {
+	case err == nil:
+		resp.State = objectStateAvailable
+		writeTombstoneResponse(c, &resp)
+		return
+	case errors.Is(err, apistatus.ErrObjectAlreadyRemoved):
+		resp.State = objectStateRemoved
+	case errors.Is(err, apistatus.ErrObjectNotFound):
+		// tombstone can still be there even if the object is collected
+		resp.State = objectStateNotFound
+	case errors.Is(err, apistatus.ErrContainerNotFound):
+		response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		return
+	default:
+		log.Error("could not head object", zap.Error(err))
+		response.StorageError(c, eacl.OperationHead, "could not head object", err, fasthttp.StatusBadRequest)
+		return
+	}
+
+	resp.Tombstone, resp.Truncated, err = d.findTombstone(c, *containerID, objID, btoken)
+	if err != nil {
+		log.Error("could not find tombstone", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not find tombstone", err, searchErrorStatus(err))
+		return
+	}
+	if resp.Tombstone != nil {
+		resp.State = objectStateRemoved
+	}
+
+	writeTombstoneResponse(c, &resp)
+}
+
+// findTombstone looks through the container tombstones for the one removing
+// the object.
+func (d *Downloader) findTombstone(c *fasthttp.RequestCtx, cnrID cid.ID, objID oid.ID, btoken *bearer.Token) (*tombstoneInfo, bool, error) {
+	filters := object.NewSearchFilters()
+	filters.AddTypeFilter(object.MatchStringEqual, object.TypeTombstone)
+
+	resSearch, err := d.searchByFilters(c, &cnrID, filters)
+	if err != nil {
+		return nil, false, fmt.Errorf("search tombstones: %w", err)
+	}
+	defer resSearch.Close()
+
+	var ids []oid.ID
+	truncated := false
+	err = resSearch.Iterate(func(id oid.ID) bool {
+		if len(ids) == maxInspectedTombstones {
+			truncated = true
+			return true
+		}
+		ids = append(ids, id)
+		return false
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("search tombstones: %w", err)
+	}
+
+	for _, id := range ids {
+		info, err := d.readTombstone(cnrID, id, btoken)
+		if err != nil {
+			d.log.Warn("could not read tombstone", zap.Stringer("tombstone", id), zap.Error(err))
+			continue
+		}
+		if info.removes(objID) {
+			return info.tombstoneInfo, false, nil
+		}
+	}
+
+	return nil, truncated, nil
+}
+
+type readTombstoneResult struct {
+	*tombstoneInfo
+	members []oid.ID
+}
+
+func (r readTombstoneResult) removes(id oid.ID) bool {
+	for _, m := range r.members {
+		if m == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Downloader) readTombstone(cnrID cid.ID, id oid.ID, btoken *bearer.Token) (readTombstoneResult, error) {
+	var prm client.PrmObjectGet
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	hdr, payloadReader, err := d.backend.ObjectGetInit(d.appCtx, cnrID, id, d.signer, prm)
+	if err != nil {
+		return readTombstoneResult{}, err
+	}
+	defer payloadReader.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(payloadReader, maxTombstonePayloadSize))
+	if err != nil {
+		return readTombstoneResult{}, fmt.Errorf("read payload: %w", err)
+	}
+
+	return parseTombstone(id, &hdr, payload)
+}
+
+func parseTombstone(id oid.ID, hdr *object.Object, payload []byte) (readTombstoneResult, error) {
+	tomb := object.NewTombstone()
+	if err := tomb.Unmarshal(payload); err != nil {
+		return readTombstoneResult{}, fmt.Errorf("unmarshal tombstone: %w", err)
+	}
+
+	members := tomb.Members()
+	info := &tombstoneInfo{
+		ID:              id.EncodeToString(),
+		CreationEpoch:   hdr.CreationEpoch(),
+		ExpirationEpoch: tomb.ExpirationEpoch(),
+		Members:         len(members),
+	}
+	if owner := hdr.OwnerID(); owner != nil {
+		info.Owner = owner.EncodeToString()
+	}
+
+	return readTombstoneResult{tombstoneInfo: info, members: members}, nil
+}
+
+func writeTombstoneResponse(c *fasthttp.RequestCtx, resp *tombstoneResponse) {
+	response.Write(c, fasthttp.StatusOK, resp)
+}
@@ -0,0 +1,44 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTombstone(t *testing.T) {
+	var (
+		id      = oidtest.ID()
+		removed = oidtest.ID()
+		owner   = usertest.ID(t)
+	)
+
+	tomb := object.NewTombstone()
+	tomb.SetExpirationEpoch(42)
+	tomb.SetMembers([]oid.ID{oidtest.ID(), removed})
+	payload, err := tomb.Marshal()
+	require.NoError(t, err)
+
+	var hdr object.Object
+	hdr.SetCreationEpoch(10)
+	hdr.SetOwnerID(&owner)
+
+	res, err := parseTombstone(id, &hdr, payload)
+	require.NoError(t, err)
+	require.Equal(t, &tombstoneInfo{
+		ID:              id.EncodeToString(),
+		Owner:           owner.EncodeToString(),
+		CreationEpoch:   10,
+		ExpirationEpoch: 42,
+		Members:         2,
+	}, res.tombstoneInfo)
+	require.True(t, res.removes(removed))
+	require.False(t, res.removes(oidtest.ID()))
+
+	_, err = parseTombstone(id, &hdr, []byte("garbage"))
+	require.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package downloader
+
+import (
+	"context"
+	"io"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/transform"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Error codes of transformation requests.
+const (
+	errCodeUnknownTransform     = "UNKNOWN_TRANSFORM"
+	errCodeUnsupportedTransform = "UNSUPPORTED_TRANSFORM"
+	errCodeTransformBusy        = "TRANSFORM_BUSY"
+)
+
+// transformRetryAfter is Retry-After header value in seconds sent with
+// responses of busy transformation hooks.
+const transformRetryAfter = "1"
+
+// transformPayload sends the payload converted by the transformation hook
+// with the given name. The length of the result is unknown, so it's sent
+// chunked and byte ranges aren't supported. The transformation is canceled
+// with ctx. Requests to hooks running the maximum number of transformations
+// are rejected with 503.
+func (r request) transformPayload(ctx context.Context, name string, payload io.ReadCloser, contentType, issuer string, cancel func()) {
+	hook, ok := r.transforms.Get(name)
+	if !ok || !hook.Matches(contentType) {
+		_ = payload.Close()
+		cancel()
+		if !ok {
+			r.log.Info("unknown transformation", zap.String("transform", name))
+			response.ErrorWithCode(r.RequestCtx, errCodeUnknownTransform, "unknown transformation '"+name+"'", fasthttp.StatusBadRequest)
+			return
+		}
+		r.log.Info("transformation doesn't support content type", zap.String("transform", name),
+			zap.String("content_type", contentType))
+		response.ErrorWithCode(r.RequestCtx, errCodeUnsupportedTransform,
+			"transformation '"+name+"' doesn't support content type '"+contentType+"'", fasthttp.StatusUnsupportedMediaType)
+		return
+	}
+
+	release, ok := r.transforms.Acquire(name)
+	if !ok {
+		_ = payload.Close()
+		cancel()
+		r.log.Warn("transformation hook is busy", zap.String("transform", name))
+		response.ErrorWithCode(r.RequestCtx, errCodeTransformBusy, "transformation '"+name+"' is busy, try again later",
+			fasthttp.StatusServiceUnavailable)
+		r.Response.Header.Set(fasthttp.HeaderRetryAfter, transformRetryAfter)
+		return
+	}
+	t := transform.Func(func(ctx context.Context, w io.Writer, payload io.Reader) error {
+		defer release()
+		return hook.Transformer.Transform(ctx, w, payload)
+	})
+
+	r.Response.Header.Del(fasthttp.HeaderContentLength)
+	if hook.ContentType != "" {
+		contentType = hook.ContentType
+	}
+	r.SetContentType(contentType)
+	r.sendPayload(transform.NewReader(ctx, t, payload), -1, issuer, cancel)
+}
@@ -0,0 +1,76 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/transform"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestTransformPayload(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	id := putFile(t, mem, cnrID, "data.bin", "meow")
+
+	hooks := transform.NewHooks()
+	upper := transform.Func(func(_ context.Context, w io.Writer, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes.ToUpper(data))
+		return err
+	})
+	require.NoError(t, hooks.Register(transform.Hook{Name: "upper", Types: []string{"application/*"}, ContentType: "text/plain", Concurrency: 1, Transformer: upper}))
+	require.NoError(t, hooks.Register(transform.Hook{Name: "markdown", Types: []string{"text/markdown"}, Transformer: upper}))
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Transforms: hooks}, &Settings{}, nil)
+
+	get := func(name string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/get/" + cnrID.EncodeToString() + "/" + id.EncodeToString() + "?transform=" + name)
+		c.Request.Header.Set(fasthttp.HeaderRange, "bytes=1-2")
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		d.DownloadByAddress(&c)
+		return &c
+	}
+
+	// ranges are ignored
+	c := get("upper")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "text/plain", string(c.Response.Header.ContentType()))
+	require.Equal(t, "MEOW", string(c.Response.Body()))
+
+	c = get("markdown")
+	require.Equal(t, fasthttp.StatusUnsupportedMediaType, c.Response.StatusCode())
+	require.Equal(t, errCodeUnsupportedTransform, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+
+	c = get("unknown")
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	require.Equal(t, errCodeUnknownTransform, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+
+	// the slot is freed by finished transformations
+	c = get("upper")
+	require.Equal(t, "MEOW", string(c.Response.Body()))
+
+	release, ok := hooks.Acquire("upper")
+	require.True(t, ok)
+	c = get("upper")
+	require.Equal(t, fasthttp.StatusServiceUnavailable, c.Response.StatusCode())
+	require.Equal(t, errCodeTransformBusy, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+	require.Equal(t, transformRetryAfter, string(c.Response.Header.Peek(fasthttp.HeaderRetryAfter)))
+
+	release()
+	c = get("upper")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+}
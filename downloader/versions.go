@@ -0,0 +1,224 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/nspcc-dev/neofs-http-gw/servertiming"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxPathVersions limits the number of objects with the same FilePath
+// considered to select the served one and listed by versions requests.
+const maxPathVersions = 100
+
+// versionsConcurrency limits the number of object versions headed at the
+// same time by a single request.
+const versionsConcurrency = 8
+
+// headVersions heads the objects with the same FilePath and returns their
+// versions from the newest one, missing and removed objects are skipped.
+// Other failures are responded with false returned, so the newest version
+// isn't missed silently.
+func (d *Downloader) headVersions(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, ids []oid.ID) ([]utils.ObjectVersion, bool) {
+	var prm client.PrmObjectHead
+	ctx := d.appCtx
+	if btoken := bearerToken(c); btoken != nil {
+		prm.WithBearerToken(*btoken)
+	} else {
+		ctx = extcache.Shareable(ctx)
+	}
+
+	defer servertiming.Start(c, servertiming.Head)()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, versionsConcurrency)
+		mu       sync.Mutex
+		versions = make([]utils.ObjectVersion, 0, len(ids))
+		firstErr error
+	)
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(id oid.ID) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				hdr, err := d.backend.ObjectHead(ctx, cnrID, id, d.signer, prm)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved):
+					log.Debug("object version is missing", zap.Stringer("oid", id), zap.Error(err))
+				case err != nil:
+					if firstErr == nil {
+						firstErr = fmt.Errorf("head %s: %w", id, err)
+						cancel()
+					}
+				default:
+					versions = append(versions, utils.NewObjectVersion(id, hdr))
+				}
+			}(id)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		log.Error("could not head object version", zap.Error(firstErr))
+		response.StorageError(c, eacl.OperationHead, "could not head object version", firstErr, storageErrorStatus(firstErr))
+		return nil, false
+	}
+
+	utils.SortVersions(versions)
+	return versions, true
+}
+
+type versionInfo struct {
+	ObjectID        string `json:"object_id"`
+	Timestamp       int64  `json:"timestamp,omitempty"`
+	CreationEpoch   uint64 `json:"creation_epoch"`
+	Size            uint64 `json:"size"`
+	ContentType     string `json:"content_type,omitempty"`
+	ContentLanguage string `json:"content_language,omitempty"`
+	DeleteMarker    bool   `json:"delete_marker,omitempty"`
+}
+
+type versionsResponse struct {
+	ContainerID string        `json:"container_id"`
+	FilePath    string        `json:"file_path"`
+	Versions    []versionInfo `json:"versions"`
+	// Truncated is set if the path has more than maxPathVersions objects,
+	// the listed ones are an arbitrary subset then.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Proto implements response.ProtoValue.
+func (r versionsResponse) Proto() proto.Message {
+	versions := make([]*pb.Version, len(r.Versions))
+	for i, v := range r.Versions {
+		versions[i] = &pb.Version{
+			ObjectId:        v.ObjectID,
+			Timestamp:       v.Timestamp,
+			CreationEpoch:   v.CreationEpoch,
+			Size:            v.Size,
+			ContentType:     v.ContentType,
+			ContentLanguage: v.ContentLanguage,
+			DeleteMarker:    v.DeleteMarker,
+		}
+	}
+	return &pb.Versions{
+		ContainerId: r.ContainerID,
+		FilePath:    r.FilePath,
+		Versions:    versions,
+		Truncated:   r.Truncated,
+	}
+}
+
+// Versions handles requests to list the objects with the same FilePath from
+// the newest one including delete markers, the newest version is served by
+// path-based downloads unless it's hidden by a marker.
+func (d *Downloader) Versions(c *fasthttp.RequestCtx) {
+	var (
+		scid, _    = c.UserValue("cid").(string)
+		rawPath, _ = c.UserValue("path").(string)
+		filePath   string
+		err        error
+	)
+	if filePath, err = url.PathUnescape(rawPath); err != nil {
+		filePath = rawPath
+	}
+	log := d.log.With(zap.String("cid", scid), zap.String("path", filePath))
+
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	res, err := d.search(c, containerID, object.AttributeFilePath, filePath, object.MatchStringEqual)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return
+	}
+
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return len(ids) > maxPathVersions
+	})
+	_ = res.Close()
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		if errors.Is(err, apistatus.ErrContainerNotFound) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return
+		}
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, searchErrorStatus(err))
+		return
+	}
+
+	resp := versionsResponse{
+		ContainerID: containerID.EncodeToString(),
+		FilePath:    filePath,
+		Truncated:   len(ids) > maxPathVersions,
+	}
+	if resp.Truncated {
+		ids = ids[:maxPathVersions]
+	}
+
+	versions, ok := d.headVersions(c, log, *containerID, ids)
+	if !ok {
+		return
+	}
+	if len(versions) == 0 {
+		log.Error("object not found")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return
+	}
+
+	resp.Versions = make([]versionInfo, len(versions))
+	for i, v := range versions {
+		resp.Versions[i] = versionInfo{
+			ObjectID:        v.ID.EncodeToString(),
+			Timestamp:       v.Timestamp,
+			CreationEpoch:   v.CreationEpoch,
+			Size:            v.Size,
+			ContentType:     v.ContentType,
+			ContentLanguage: v.Language,
+			DeleteMarker:    v.DeleteMarker,
+		}
+	}
+	response.Write(c, fasthttp.StatusOK, resp)
+}
@@ -0,0 +1,185 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/searchlimit"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func putVersion(t *testing.T, mem *backend.Memory, cnrID cid.ID, filePath string, timestamp int64, payload string, extra ...object.Attribute) oid.ID {
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	attrs := make([]object.Attribute, 2)
+	attrs[0].SetKey(object.AttributeFilePath)
+	attrs[0].SetValue(filePath)
+	attrs[1].SetKey(object.AttributeTimestamp)
+	attrs[1].SetValue(strconv.FormatInt(timestamp, 10))
+	hdr.SetAttributes(append(attrs, extra...)...)
+
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return w.StoredObjectID()
+}
+
+func TestVersions(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	ids := []oid.ID{
+		putVersion(t, mem, cnrID, "docs/readme.md", 200, "v2"),
+		putVersion(t, mem, cnrID, "docs/readme.md", 300, "v3"),
+		putVersion(t, mem, cnrID, "docs/readme.md", 100, "v1"),
+	}
+	putVersion(t, mem, cnrID, "docs/other.md", 400, "other")
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	var c fasthttp.RequestCtx
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	c.SetUserValue("path", "docs%2Freadme.md")
+	d.Versions(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+	var resp versionsResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	require.Equal(t, "docs/readme.md", resp.FilePath)
+	require.False(t, resp.Truncated)
+	require.Len(t, resp.Versions, 3)
+	for i, id := range []oid.ID{ids[1], ids[0], ids[2]} {
+		require.Equal(t, id.EncodeToString(), resp.Versions[i].ObjectID)
+		require.EqualValues(t, 2, resp.Versions[i].Size)
+	}
+	require.EqualValues(t, 300, resp.Versions[0].Timestamp)
+
+	// path-based downloads serve the newest version
+	c = fasthttp.RequestCtx{}
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	c.SetUserValue("attr_key", object.AttributeFilePath)
+	c.SetUserValue("attr_val", "docs/readme.md")
+	d.DownloadByAttribute(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "v3", string(c.Response.Body()))
+
+	c = fasthttp.RequestCtx{}
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	c.SetUserValue("path", "missing.md")
+	d.Versions(&c)
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+
+	// search stops after the first matches
+	c = fasthttp.RequestCtx{}
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	c.SetUserValue("path", "docs%2Freadme.md")
+	searchlimit.Store(&c, searchlimit.Limits{MaxResults: 2})
+	d.Versions(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+	resp = versionsResponse{}
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	require.Len(t, resp.Versions, 2)
+}
+
+func TestDeleteMarker(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+
+	get := func(h fasthttp.RequestHandler) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "cat.txt")
+		c.SetUserValue("path", "cat.txt")
+		h(&c)
+		return &c
+	}
+
+	var marker object.Attribute
+	marker.SetKey(utils.AttributeDeleteMarker)
+	marker.SetValue("true")
+
+	putVersion(t, mem, cnrID, "cat.txt", 100, "meow")
+	markerID := putVersion(t, mem, cnrID, "cat.txt", 200, "", marker)
+
+	c := get(d.DownloadByAttribute)
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+	c = get(d.HeadByAttribute)
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+
+	c = get(d.Versions)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	var resp versionsResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	require.Len(t, resp.Versions, 2)
+	require.Equal(t, markerID.EncodeToString(), resp.Versions[0].ObjectID)
+	require.True(t, resp.Versions[0].DeleteMarker)
+	require.False(t, resp.Versions[1].DeleteMarker)
+
+	// the file uploaded again is visible
+	putVersion(t, mem, cnrID, "cat.txt", 300, "purr")
+	c = get(d.DownloadByAttribute)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "purr", string(c.Response.Body()))
+}
+
+// failingHeadBackend fails heads of the given object.
+type failingHeadBackend struct {
+	*backend.Memory
+	id  oid.ID
+	err error
+}
+
+func (b *failingHeadBackend) ObjectHead(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectHead) (*object.Object, error) {
+	if objID == b.id {
+		return nil, b.err
+	}
+	return b.Memory.ObjectHead(ctx, cnrID, objID, signer, prm)
+}
+
+func TestVersionsHeadFailure(t *testing.T) {
+	mem := &failingHeadBackend{Memory: backend.NewMemory()}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	putVersion(t, mem.Memory, cnrID, "cat.txt", 100, "meow")
+	mem.id = putVersion(t, mem.Memory, cnrID, "cat.txt", 200, "purr")
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+	get := func(h fasthttp.RequestHandler) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", "cat.txt")
+		c.SetUserValue("path", "cat.txt")
+		h(&c)
+		return &c
+	}
+
+	// the newest version mustn't be missed silently
+	mem.err = errors.New("node is unavailable")
+	require.Equal(t, fasthttp.StatusBadGateway, get(d.Versions).Response.StatusCode())
+	require.Equal(t, fasthttp.StatusBadGateway, get(d.DownloadByAttribute).Response.StatusCode())
+
+	// removed objects are skipped
+	mem.err = apistatus.ErrObjectAlreadyRemoved
+	c := get(d.DownloadByAttribute)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "meow", string(c.Response.Body()))
+}
@@ -0,0 +1,286 @@
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+const zipCacheFileSuffix = ".zip"
+
+// errZipCacheEntryTooBig is returned by zipCacheWriter.Commit when archive
+// exceeds the maximum entry size.
+var errZipCacheEntryTooBig = errors.New("archive is too big to be cached")
+
+// ZipCacheConfig contains parameters of the archive cache.
+type ZipCacheConfig struct {
+	// Dir is a directory to store cached archives in. Existing archives are
+	// removed on cache creation.
+	Dir string
+	// TTL is a time cached archive can be served.
+	TTL time.Duration
+	// MaxSize is a maximum total size of cached archives.
+	MaxSize int64
+	// MaxEntrySize is a maximum size of single cached archive.
+	MaxEntrySize int64
+}
+
+type zipCacheEntry struct {
+	cnrID    cid.ID
+	path     string
+	size     int64
+	created  time.Time
+	lastUsed time.Time
+}
+
+// ZipCache stores generated archives on local disk, so repeated requests for
+// the same set of objects don't rebuild the archive.
+type ZipCache struct {
+	cfg ZipCacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*zipCacheEntry
+	total   int64
+}
+
+// NewZipCache creates archive cache in the configured directory.
+func NewZipCache(cfg ZipCacheConfig) (*ZipCache, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("empty cache directory")
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+
+	// Cache index is in memory only, so files left from previous runs are
+	// useless.
+	old, err := filepath.Glob(filepath.Join(cfg.Dir, "*"+zipCacheFileSuffix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range old {
+		_ = os.Remove(f)
+	}
+
+	return &ZipCache{
+		cfg:     cfg,
+		entries: make(map[string]*zipCacheEntry),
+	}, nil
+}
+
+// zipCacheKey calculates cache key of the archive. Object IDs are included, so
+// new matching objects invalidate the archive.
+func zipCacheKey(cnrID cid.ID, prefix string, compression, manifest bool, ids []oid.ID) string {
+	sorted := append([]oid.ID(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	h := sha256.New()
+	h.Write(cnrID[:])
+	h.Write([]byte(prefix))
+	h.Write([]byte{0})
+	for _, flag := range []bool{compression, manifest} {
+		if flag {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	}
+	for _, id := range sorted {
+		h.Write(id[:])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get opens cached archive by its key. The caller must close the file.
+func (c *ZipCache) Get(key string) (*os.File, int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+
+	if time.Since(e.created) > c.cfg.TTL {
+		c.removeEntry(key, e)
+		return nil, 0, false
+	}
+
+	f, err := os.Open(e.path)
+	if err != nil {
+		c.removeEntry(key, e)
+		return nil, 0, false
+	}
+
+	e.lastUsed = time.Now()
+	return f, e.size, true
+}
+
+// NewWriter creates a writer for the new archive of the container objects.
+// Archive becomes available only after successful Commit.
+func (c *ZipCache) NewWriter(key string, cnrID cid.ID) (*zipCacheWriter, error) {
+	f, err := os.CreateTemp(c.cfg.Dir, key+zipCacheFileSuffix+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &zipCacheWriter{cache: c, key: key, cnrID: cnrID, file: f}, nil
+}
+
+// Purge removes archives of the container objects and returns the number of
+// removed archives.
+func (c *ZipCache) Purge(cnrID cid.ID) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int
+	for key, e := range c.entries {
+		if e.cnrID == cnrID {
+			c.removeEntry(key, e)
+			n++
+		}
+	}
+	return n
+}
+
+func (c *ZipCache) put(key string, cnrID cid.ID, path string, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.entries[key]; ok {
+		c.removeEntry(key, old)
+	}
+
+	now := time.Now()
+	c.entries[key] = &zipCacheEntry{cnrID: cnrID, path: path, size: size, created: now, lastUsed: now}
+	c.total += size
+
+	c.evict()
+}
+
+// CollectExpired removes expired archives and returns their number and total
+// size. Expired archives aren't served, but they're kept on disk until the
+// next archive is cached otherwise.
+func (c *ZipCache) CollectExpired() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.removeExpired()
+}
+
+func (c *ZipCache) removeExpired() (int, int64) {
+	var (
+		n    int
+		size int64
+	)
+	for key, e := range c.entries {
+		if time.Since(e.created) > c.cfg.TTL {
+			c.removeEntry(key, e)
+			n++
+			size += e.size
+		}
+	}
+	return n, size
+}
+
+// evict removes expired entries and then the least recently used ones until
+// total size fits the limit.
+func (c *ZipCache) evict() {
+	c.removeExpired()
+
+	for c.total > c.cfg.MaxSize && len(c.entries) > 0 {
+		var (
+			oldestKey string
+			oldest    *zipCacheEntry
+		)
+		for key, e := range c.entries {
+			if oldest == nil || e.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = key, e
+			}
+		}
+		c.removeEntry(oldestKey, oldest)
+	}
+}
+
+func (c *ZipCache) removeEntry(key string, e *zipCacheEntry) {
+	delete(c.entries, key)
+	c.total -= e.size
+	// opened files are still readable after removal
+	_ = os.Remove(e.path)
+}
+
+// zipCacheWriter writes the archive into temporary file. It's written along
+// with the client response, so write failures never fail: the writer
+// remembers the error, drops written data, ignores further writes and returns
+// the error on Commit.
+type zipCacheWriter struct {
+	cache *ZipCache
+	key   string
+	cnrID cid.ID
+	file  *os.File
+	size  int64
+	err   error
+}
+
+func (w *zipCacheWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return len(p), nil
+	}
+	if w.size+int64(len(p)) > w.cache.cfg.MaxEntrySize {
+		w.fail(errZipCacheEntryTooBig)
+		return len(p), nil
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		w.fail(err)
+	}
+	return len(p), nil
+}
+
+func (w *zipCacheWriter) fail(err error) {
+	w.err = err
+	w.Abort()
+}
+
+// Commit makes written archive available in cache. It returns the first
+// write error if any, nothing is cached then.
+func (w *zipCacheWriter) Commit() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+
+	path := strings.TrimSuffix(w.file.Name(), ".tmp")
+	if err := os.Rename(w.file.Name(), path); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+
+	w.cache.put(w.key, w.cnrID, path, w.size)
+	return nil
+}
+
+// Abort drops written data.
+func (w *zipCacheWriter) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
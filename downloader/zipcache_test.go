@@ -0,0 +1,200 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func newTestZipCache(t *testing.T, ttl time.Duration, maxSize, maxEntrySize int64) *ZipCache {
+	cache, err := NewZipCache(ZipCacheConfig{
+		Dir:          t.TempDir(),
+		TTL:          ttl,
+		MaxSize:      maxSize,
+		MaxEntrySize: maxEntrySize,
+	})
+	require.NoError(t, err)
+	return cache
+}
+
+func putTestArchive(t *testing.T, cache *ZipCache, key, data string) {
+	putTestContainerArchive(t, cache, key, cid.ID{}, data)
+}
+
+func putTestContainerArchive(t *testing.T, cache *ZipCache, key string, cnrID cid.ID, data string) {
+	w, err := cache.NewWriter(key, cnrID)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Commit())
+}
+
+func readTestArchive(t *testing.T, cache *ZipCache, key string) (string, bool) {
+	f, _, ok := cache.Get(key)
+	if !ok {
+		return "", false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(data), true
+}
+
+func TestZipCacheKey(t *testing.T) {
+	cnrID := cidtest.ID()
+	a, b, c := oidtest.ID(), oidtest.ID(), oidtest.ID()
+
+	key := zipCacheKey(cnrID, "dir/", false, false, []oid.ID{a, b})
+	require.Equal(t, key, zipCacheKey(cnrID, "dir/", false, false, []oid.ID{b, a}))
+	require.NotEqual(t, key, zipCacheKey(cnrID, "dir/", true, false, []oid.ID{a, b}))
+	require.NotEqual(t, key, zipCacheKey(cnrID, "dir/", false, true, []oid.ID{a, b}))
+	require.NotEqual(t, key, zipCacheKey(cnrID, "dir", false, false, []oid.ID{a, b}))
+	require.NotEqual(t, key, zipCacheKey(cidtest.ID(), "dir/", false, false, []oid.ID{a, b}))
+	require.NotEqual(t, key, zipCacheKey(cnrID, "dir/", false, false, []oid.ID{a, b, c}))
+}
+
+func TestZipCache(t *testing.T) {
+	t.Run("put and get", func(t *testing.T) {
+		cache := newTestZipCache(t, time.Hour, 100, 100)
+
+		_, ok := readTestArchive(t, cache, "key")
+		require.False(t, ok)
+
+		putTestArchive(t, cache, "key", "archive")
+		data, ok := readTestArchive(t, cache, "key")
+		require.True(t, ok)
+		require.Equal(t, "archive", data)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		cache := newTestZipCache(t, time.Nanosecond, 100, 100)
+
+		putTestArchive(t, cache, "key", "archive")
+		time.Sleep(time.Millisecond)
+		_, ok := readTestArchive(t, cache, "key")
+		require.False(t, ok)
+	})
+
+	t.Run("collect expired", func(t *testing.T) {
+		cache := newTestZipCache(t, 10*time.Millisecond, 100, 100)
+
+		putTestArchive(t, cache, "key", "archive")
+		time.Sleep(20 * time.Millisecond)
+		n, size := cache.CollectExpired()
+		require.Equal(t, 1, n)
+		require.EqualValues(t, len("archive"), size)
+
+		files, err := filepath.Glob(filepath.Join(cache.cfg.Dir, "*"))
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		cache := newTestZipCache(t, time.Hour, 10, 10)
+
+		putTestArchive(t, cache, "first", "12345")
+		putTestArchive(t, cache, "second", "12345")
+		_, ok := readTestArchive(t, cache, "first")
+		require.True(t, ok)
+
+		putTestArchive(t, cache, "third", "12345")
+		_, ok = readTestArchive(t, cache, "second")
+		require.False(t, ok)
+		_, ok = readTestArchive(t, cache, "first")
+		require.True(t, ok)
+		_, ok = readTestArchive(t, cache, "third")
+		require.True(t, ok)
+	})
+
+	t.Run("too big entry", func(t *testing.T) {
+		cache := newTestZipCache(t, time.Hour, 100, 4)
+
+		w, err := cache.NewWriter("key", cidtest.ID())
+		require.NoError(t, err)
+		n, err := w.Write([]byte("archive"))
+		require.NoError(t, err, "cache failures mustn't interrupt the response")
+		require.Equal(t, len("archive"), n)
+		_, err = w.Write([]byte("more"))
+		require.NoError(t, err)
+		require.ErrorIs(t, w.Commit(), errZipCacheEntryTooBig)
+		_, ok := readTestArchive(t, cache, "key")
+		require.False(t, ok)
+
+		files, err := filepath.Glob(filepath.Join(cache.cfg.Dir, "*"))
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		cache := newTestZipCache(t, time.Hour, 100, 100)
+		a, b := cidtest.ID(), cidtest.ID()
+
+		putTestContainerArchive(t, cache, "a1", a, "archive")
+		putTestContainerArchive(t, cache, "a2", a, "archive")
+		putTestContainerArchive(t, cache, "b", b, "archive")
+
+		require.Equal(t, 2, cache.Purge(a))
+		_, ok := readTestArchive(t, cache, "a1")
+		require.False(t, ok)
+		_, ok = readTestArchive(t, cache, "a2")
+		require.False(t, ok)
+		_, ok = readTestArchive(t, cache, "b")
+		require.True(t, ok)
+		require.Zero(t, cache.Purge(a))
+	})
+
+	t.Run("old files removed", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := filepath.Join(dir, "key"+zipCacheFileSuffix)
+		require.NoError(t, os.WriteFile(stale, []byte("archive"), 0o600))
+
+		_, err := NewZipCache(ZipCacheConfig{Dir: dir, TTL: time.Hour, MaxSize: 100, MaxEntrySize: 100})
+		require.NoError(t, err)
+		require.NoFileExists(t, stale)
+	})
+}
+
+func TestDownloadZippedTooBigToCache(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	putVersion(t, mem, cnrID, "dir/a.txt", 100, strings.Repeat("a", 100))
+	putVersion(t, mem, cnrID, "dir/b.txt", 100, strings.Repeat("b", 100))
+
+	cache := newTestZipCache(t, time.Hour, 1000, 64)
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+	d.settings.SetZipCache(cache)
+
+	var c fasthttp.RequestCtx
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	c.SetUserValue("prefix", "dir/")
+	d.DownloadZipped(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+	body := c.Response.Body()
+	require.Greater(t, len(body), 64)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err, "the archive exceeding the cache entry limit is sent completely")
+	require.Len(t, zr.File, 2)
+
+	files, err := filepath.Glob(filepath.Join(cache.cfg.Dir, "*"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
@@ -0,0 +1,158 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nspcc-dev/neofs-http-gw/zipstream"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// zipManifestName is a name of the manifest entry in the archive.
+const zipManifestName = "manifest.json"
+
+// zipEntry is an object to add to the archive.
+type zipEntry struct {
+	id oid.ID
+	// header is set if the object header has been read before the archive is
+	// written, otherwise it's made of the GET response.
+	header zipstream.FileHeader
+	headed bool
+	// checksum is SHA256 checksum of the object payload, nil if the object
+	// has no such checksum.
+	checksum []byte
+}
+
+// zipObjectEntries returns archive entries of the objects without reading
+// their headers.
+func zipObjectEntries(ids []oid.ID) []zipEntry {
+	entries := make([]zipEntry, len(ids))
+	for i := range ids {
+		entries[i].id = ids[i]
+	}
+	return entries
+}
+
+// zipEntries reads headers of the objects and returns archive entries in the
+// same order. Objects with invalid FilePath and objects removed after search
+// are skipped.
+func (d *Downloader) zipEntries(cnrID cid.ID, ids []oid.ID, btoken *bearer.Token, compression bool, log *zap.Logger) ([]zipEntry, error) {
+	var prm client.PrmObjectHead
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	entries := make([]zipEntry, 0, len(ids))
+	for _, id := range ids {
+		obj, err := d.backend.ObjectHead(d.appCtx, cnrID, id, d.signer, prm)
+		if err != nil {
+			if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+				log.Debug("skip removed object", zap.Stringer("oid", id), zap.Error(err))
+				continue
+			}
+			return nil, fmt.Errorf("head object %s: %w", id, err)
+		}
+
+		fh, err := zipFileHeader(obj, compression)
+		if err != nil {
+			log.Error("skip object with invalid path", zap.Stringer("oid", id), zap.Error(err))
+			continue
+		}
+
+		e := zipEntry{id: id, header: fh, headed: true}
+		if cs, ok := obj.PayloadChecksum(); ok && cs.Type() == checksum.SHA256 {
+			e.checksum = cs.Value()
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// sortObjectIDs sorts object IDs in ascending order.
+func sortObjectIDs(ids []oid.ID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+}
+
+// sortZipEntries sorts entries by their paths, objects with the same path are
+// sorted by IDs.
+func sortZipEntries(entries []zipEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].header.Name != entries[j].header.Name {
+			return entries[i].header.Name < entries[j].header.Name
+		}
+		return bytes.Compare(entries[i].id[:], entries[j].id[:]) < 0
+	})
+}
+
+type (
+	zipManifest struct {
+		ContainerID string             `json:"container_id"`
+		Prefix      string             `json:"prefix"`
+		Files       []zipManifestEntry `json:"files"`
+	}
+
+	zipManifestEntry struct {
+		Path     string `json:"path"`
+		ObjectID string `json:"object_id"`
+		Size     uint64 `json:"size"`
+		SHA256   string `json:"sha256,omitempty"`
+	}
+)
+
+// zipManifestData returns manifest.json content listing the archive entries.
+func zipManifestData(cnrID cid.ID, prefix string, entries []zipEntry) ([]byte, error) {
+	m := zipManifest{
+		ContainerID: cnrID.EncodeToString(),
+		Prefix:      prefix,
+		Files:       make([]zipManifestEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		m.Files = append(m.Files, zipManifestEntry{
+			Path:     e.header.Name,
+			ObjectID: e.id.EncodeToString(),
+			Size:     e.header.Size,
+			SHA256:   hex.EncodeToString(e.checksum),
+		})
+	}
+
+	return json.MarshalIndent(m, "", "\t")
+}
+
+// zipManifestHeader returns the archive entry header of the manifest. It has
+// no modification time to keep the archive reproducible.
+func zipManifestHeader(manifest []byte, compression bool) zipstream.FileHeader {
+	method := zipstream.Store
+	if compression {
+		method = zipstream.Deflate
+	}
+	return zipstream.FileHeader{
+		Name:   zipManifestName,
+		Method: method,
+		Size:   uint64(len(manifest)),
+	}
+}
+
+// zipManifestTo adds the manifest entry to the archive.
+func zipManifestTo(zw *zipstream.Writer, manifest []byte, compression bool) error {
+	w, err := zw.Create(zipManifestHeader(manifest, compression))
+	if err != nil {
+		return fmt.Errorf("zip create header: %w", err)
+	}
+	if _, err = w.Write(manifest); err != nil {
+		return fmt.Errorf("write manifest to zip file: %w", err)
+	}
+	return nil
+}
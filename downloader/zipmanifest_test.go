@@ -0,0 +1,220 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-http-gw/zipstream"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestSortZipEntries(t *testing.T) {
+	entry := func(name string, idByte byte) zipEntry {
+		var id oid.ID
+		id[0] = idByte
+		return zipEntry{id: id, header: zipstream.FileHeader{Name: name}}
+	}
+
+	entries := []zipEntry{
+		entry("b/file", 1),
+		entry("a", 3),
+		entry("b", 2),
+		entry("a", 1),
+		entry("a/file", 0),
+	}
+	sortZipEntries(entries)
+
+	require.Equal(t, []zipEntry{
+		entry("a", 1),
+		entry("a", 3),
+		entry("a/file", 0),
+		entry("b", 2),
+		entry("b/file", 1),
+	}, entries)
+}
+
+func TestZipManifest(t *testing.T) {
+	cnrID := cidtest.ID()
+	files := map[string]string{
+		"dir/a": "first",
+		"dir/b": "second file",
+	}
+
+	var entries []zipEntry
+	for _, name := range []string{"dir/a", "dir/b"} {
+		var id oid.ID
+		id[0] = byte(len(entries))
+		entries = append(entries, zipEntry{
+			id:       id,
+			header:   zipstream.FileHeader{Name: name, Size: uint64(len(files[name]))},
+			checksum: []byte{byte(len(entries)), 0xff},
+		})
+	}
+
+	manifest, err := zipManifestData(cnrID, "dir/", entries)
+	require.NoError(t, err)
+
+	var m zipManifest
+	require.NoError(t, json.Unmarshal(manifest, &m))
+	require.Equal(t, cnrID.EncodeToString(), m.ContainerID)
+	require.Equal(t, "dir/", m.Prefix)
+	require.Equal(t, []zipManifestEntry{
+		{Path: "dir/a", ObjectID: entries[0].id.EncodeToString(), Size: 5, SHA256: "00ff"},
+		{Path: "dir/b", ObjectID: entries[1].id.EncodeToString(), Size: 11, SHA256: "01ff"},
+	}, m.Files)
+
+	var buf bytes.Buffer
+	zw := zipstream.NewWriter(&buf)
+	require.NoError(t, zipManifestTo(zw, manifest, false))
+	for _, e := range entries {
+		w, err := zw.Create(e.header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(files[e.header.Name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	size, err := zipStoredSize(entries, manifest)
+	require.NoError(t, err)
+	require.EqualValues(t, buf.Len(), size)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 3)
+	require.Equal(t, zipManifestName, zr.File[0].Name)
+
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, manifest, data)
+}
+
+func TestSanitizeZipPath(t *testing.T) {
+	for _, tc := range []struct {
+		val, res string
+	}{
+		{val: "dir/file.txt", res: "dir/file.txt"},
+		{val: "/etc/passwd", res: "etc/passwd"},
+		{val: "../../etc/passwd", res: "etc/passwd"},
+		{val: "dir/../../../etc/passwd", res: "etc/passwd"},
+		{val: `..\..\windows\system32\evil.dll`, res: "windows/system32/evil.dll"},
+		{val: `C:\Windows\evil.dll`, res: "Windows/evil.dll"},
+		{val: "c:evil.dll", res: "evil.dll"},
+		{val: "//server/share/file.txt", res: "server/share/file.txt"},
+		{val: "./dir/./file.txt", res: "dir/file.txt"},
+		{val: "dir//file.txt", res: "dir/file.txt"},
+	} {
+		res, err := sanitizeZipPath(tc.val)
+		require.NoError(t, err, tc.val)
+		require.Equal(t, tc.res, res, tc.val)
+	}
+
+	for _, val := range []string{"", "dir/", `dir\`, "/", "..", "../..", `C:\`, "dir/.."} {
+		_, err := sanitizeZipPath(val)
+		require.Error(t, err, val)
+	}
+}
+
+func TestZipHostilePaths(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	var ids []oid.ID
+	for _, p := range []string{"../../evil.sh", `..\..\evil.bat`, "/etc/cron.d/evil", "dir/", "ok/file.txt"} {
+		var attr object.Attribute
+		attr.SetKey(object.AttributeFilePath)
+		attr.SetValue(p)
+		var hdr object.Object
+		hdr.SetContainerID(cnrID)
+		hdr.SetAttributes(attr)
+
+		w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(p))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		ids = append(ids, w.StoredObjectID())
+	}
+
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+	entries, err := d.zipEntries(cnrID, ids, nil, false, zap.NewNop())
+	require.NoError(t, err)
+	sortZipEntries(entries)
+
+	var buf bytes.Buffer
+	require.True(t, d.writeZip(&buf, 0, cnrID, entries, nil, nil, false, zap.NewNop()))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		require.False(t, strings.HasPrefix(f.Name, "/"), f.Name)
+		require.NotContains(t, f.Name, "..", f.Name)
+		require.NotContains(t, f.Name, `\`, f.Name)
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"etc/cron.d/evil", "evil.bat", "evil.sh", "ok/file.txt"}, names)
+}
+
+func TestZipObjectHeads(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	ids := []oid.ID{
+		putFile(t, mem, cnrID, "dir/a.txt", "a"),
+		putFile(t, mem, cnrID, "dir/b.txt", "b"),
+		putFile(t, mem, cnrID, "dir/", "invalid"),
+	}
+	// heads of the first object fail, so the archive is sent only if the
+	// headers aren't read in advance
+	b := &failingHeadBackend{Memory: mem, id: ids[0], err: errors.New("node is unavailable")}
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: b}, &Settings{}, nil)
+
+	download := func() *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("prefix", "dir/")
+		d.DownloadZipped(&c)
+		return &c
+	}
+
+	t.Run("without manifest", func(t *testing.T) {
+		c := download()
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+		body := c.Response.Body()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		require.Len(t, zr.File, 2)
+
+		// archive is the same as the one written with headers read in advance
+		sortObjectIDs(ids)
+		entries, err := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil).
+			zipEntries(cnrID, ids, nil, false, zap.NewNop())
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.True(t, d.writeZip(&buf, 0, cnrID, entries, nil, nil, false, zap.NewNop()))
+		require.Equal(t, buf.Bytes(), body)
+	})
+
+	t.Run("with manifest", func(t *testing.T) {
+		d.settings.SetZipManifest(true)
+		c := download()
+		require.Equal(t, fasthttp.StatusBadGateway, c.Response.StatusCode())
+	})
+}
@@ -0,0 +1,169 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/hashring"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// hdrZipForwarded marks archive requests forwarded by another gateway,
+	// they're always served locally to prevent forwarding loops.
+	hdrZipForwarded = "X-Zip-Forwarded"
+	// DefaultZipPeerTimeout is the default time of connecting to the cache
+	// owner and waiting for its response headers. The owner searches for
+	// objects and heads them before it responds.
+	DefaultZipPeerTimeout = 30 * time.Second
+)
+
+// ZipCachePeers shards archive cache between gateways sharing it. Every
+// archive is cached by a single peer only, other peers forward requests for it
+// to the owner.
+type ZipCachePeers struct {
+	// Ring contains base URLs of all the peers including this gateway.
+	Ring *hashring.Ring
+	// Self is the base URL of this gateway on the ring.
+	Self string
+	// ClientIPHeader is the forwarding header client addresses are passed
+	// to the owner in, peers must trust each other as proxies.
+	ClientIPHeader string
+	// Timeout limits connecting to the owner and waiting for its response
+	// headers, DefaultZipPeerTimeout is used if it's zero.
+	Timeout time.Duration
+}
+
+// hopHeaders aren't forwarded between the client and the cache owner.
+var hopHeaders = map[string]struct{}{
+	fasthttp.HeaderConnection:       {},
+	fasthttp.HeaderKeepAlive:        {},
+	fasthttp.HeaderTE:               {},
+	fasthttp.HeaderTrailer:          {},
+	fasthttp.HeaderTransferEncoding: {},
+	fasthttp.HeaderUpgrade:          {},
+	fasthttp.HeaderContentLength:    {},
+	fasthttp.HeaderHost:             {},
+}
+
+// isHopHeader checks whether the header is a hop-by-hop one, header names
+// aren't normalized by the server, so their case is ignored.
+func isHopHeader(name string) bool {
+	for h := range hopHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// newZipPeerClient creates the client forwarding requests to cache owners.
+func newZipPeerClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+		},
+		// redirects are returned to the client as is
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// zipCacheOwner returns the peer caching archives of the prefix in the
+// container if it's another gateway.
+func (d *Downloader) zipCacheOwner(c *fasthttp.RequestCtx, cnrID cid.ID, prefix string) (string, bool) {
+	peers := d.settings.ZipCachePeers()
+	if peers == nil || d.settings.ZipCache() == nil || len(c.Request.Header.Peek(hdrZipForwarded)) != 0 {
+		return "", false
+	}
+
+	// the archive key depends on found objects, so requests are sharded by
+	// the prefix to be forwarded before the search
+	owner := peers.Ring.Owner(cnrID.EncodeToString() + "/" + prefix)
+	return owner, owner != "" && owner != peers.Self
+}
+
+// forwardZip streams the response of the peer to the archive request. The
+// request isn't responded if an error is returned, so it can be served locally.
+// Archives are streamed, so only the time to get response headers is limited.
+// The client address is passed in the forwarding header, forwarding headers
+// of the client are dropped, so they can't be spoofed.
+func (d *Downloader) forwardZip(c *fasthttp.RequestCtx, peers *ZipCachePeers, peer string) error {
+	timeout, ipHeader := DefaultZipPeerTimeout, clientip.DefaultHeader
+	if peers != nil {
+		if peers.Timeout > 0 {
+			timeout = peers.Timeout
+		}
+		if peers.ClientIPHeader != "" {
+			ipHeader = peers.ClientIPHeader
+		}
+	}
+
+	ctx, cancel := context.WithCancel(d.appCtx)
+	req, err := http.NewRequestWithContext(ctx, string(c.Method()), strings.TrimSuffix(peer, "/")+string(c.RequestURI()), nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	c.Request.Header.VisitAll(func(key, val []byte) {
+		name := string(key)
+		if isHopHeader(name) || clientip.IsForwardingHeader(name) || strings.EqualFold(name, ipHeader) {
+			return
+		}
+		req.Header.Add(name, string(val))
+	})
+	// routes of additional networks can be bound to host names
+	req.Host = string(c.Host())
+	req.Header.Set(hdrZipForwarded, "true")
+	req.Header.Set(ipHeader, clientip.HeaderValue(ipHeader, clientip.Load(c)))
+
+	timer := time.AfterFunc(timeout, cancel)
+	resp, err := d.zipPeers.Do(req)
+	if !timer.Stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		err = fmt.Errorf("no response in %s: %w", timeout, context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+	if resp.StatusCode >= fasthttp.StatusInternalServerError {
+		_ = resp.Body.Close()
+		cancel()
+		return fmt.Errorf("peer responded with %s", resp.Status)
+	}
+
+	c.SetStatusCode(resp.StatusCode)
+	for key, vals := range resp.Header {
+		if isHopHeader(key) {
+			continue
+		}
+		for _, val := range vals {
+			c.Response.Header.Add(key, val)
+		}
+	}
+	// the body is closed by fasthttp after it's sent
+	c.Response.SetBodyStream(&cancelingReadCloser{ReadCloser: resp.Body, cancel: cancel}, int(resp.ContentLength))
+	return nil
+}
+
+// cancelingReadCloser cancels the request context when the body is closed.
+type cancelingReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelingReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
@@ -0,0 +1,99 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/hashring"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestZipCacheOwner(t *testing.T) {
+	d := &Downloader{settings: &Settings{}}
+	cnrID := cidtest.ID()
+
+	var c fasthttp.RequestCtx
+	_, ok := d.zipCacheOwner(&c, cnrID, "dir/")
+	require.False(t, ok)
+
+	d.settings.SetZipCache(&ZipCache{})
+	peers := []string{"http://gw1", "http://gw2"}
+	d.settings.SetZipCachePeers(&ZipCachePeers{Ring: hashring.New(peers, 0), Self: "http://gw1"})
+
+	// prefixes are spread between peers, own ones are served locally
+	var forwarded, local int
+	for _, prefix := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		if owner, ok := d.zipCacheOwner(&c, cnrID, prefix); ok {
+			require.Equal(t, "http://gw2", owner)
+			forwarded++
+		} else {
+			local++
+		}
+	}
+	require.NotZero(t, forwarded)
+	require.NotZero(t, local)
+
+	c.Request.Header.Set(hdrZipForwarded, "true")
+	for _, prefix := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		_, ok = d.zipCacheOwner(&c, cnrID, prefix)
+		require.False(t, ok)
+	}
+}
+
+func TestForwardZip(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(hdrZipForwarded) == "" || r.URL.Path != "/zip/cnr/dir" || r.Host != "gate" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// client forwarding headers are replaced with the client address
+		if r.Header.Get("X-Forwarded-For") != "203.0.113.7" || r.Header.Get("X-Real-IP") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Header.Get("Range") {
+		case "fail":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set(hdrZipContinuation, "token")
+		_, _ = io.WriteString(w, "archive")
+	}))
+	defer peer.Close()
+
+	d := &Downloader{appCtx: context.Background(), zipPeers: newZipPeerClient()}
+	peers := &ZipCachePeers{Timeout: 50 * time.Millisecond}
+
+	request := func(rng string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetRemoteAddr(&net.TCPAddr{IP: net.IPv4(203, 0, 113, 7)})
+		c.Request.SetRequestURI("/zip/cnr/dir")
+		c.Request.SetHost("gate")
+		c.Request.Header.Set("X-Forwarded-For", "192.0.2.1")
+		c.Request.Header.Set("X-Real-IP", "192.0.2.1")
+		if rng != "" {
+			c.Request.Header.Set("Range", rng)
+		}
+		return &c
+	}
+
+	c := request("")
+	require.NoError(t, d.forwardZip(c, peers, peer.URL+"/"))
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+	require.Equal(t, "application/zip", string(c.Response.Header.ContentType()))
+	require.Equal(t, "token", string(c.Response.Header.Peek(hdrZipContinuation)))
+	require.Equal(t, "archive", string(c.Response.Body()))
+
+	require.Error(t, d.forwardZip(request("fail"), peers, peer.URL))
+	require.ErrorIs(t, d.forwardZip(request("slow"), peers, peer.URL), context.DeadlineExceeded)
+}
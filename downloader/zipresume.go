@@ -0,0 +1,236 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/zipstream"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// hdrZipContinuation is a response header with the token to resume
+	// the archive download with.
+	hdrZipContinuation = "X-Zip-Continuation"
+	// queryZipContinuation is a query parameter with the token of
+	// the resumed archive download.
+	queryZipContinuation = "continuation"
+)
+
+// errRangeWritten is returned by rangeWriter when the whole range is written.
+var errRangeWritten = errors.New("range is written")
+
+// byteRange is a requested range of the archive, end is inclusive.
+type byteRange struct {
+	start  uint64
+	end    uint64
+	hasEnd bool
+}
+
+// parseByteRange parses a single `bytes=<start>-[<end>]` range, suffix ranges
+// aren't supported since archives are resumed from the offset.
+func parseByteRange(s string) (byteRange, error) {
+	var r byteRange
+
+	if !strings.HasPrefix(s, "bytes=") {
+		return r, errors.New("unsupported range unit")
+	}
+	s = strings.TrimPrefix(s, "bytes=")
+	if strings.Contains(s, ",") {
+		return r, errors.New("multiple ranges aren't supported")
+	}
+
+	start, end, ok := strings.Cut(s, "-")
+	if !ok || start == "" {
+		return r, errors.New("only ranges with start are supported")
+	}
+
+	var err error
+	if r.start, err = strconv.ParseUint(start, 10, 64); err != nil {
+		return r, fmt.Errorf("invalid range start: %w", err)
+	}
+	if end != "" {
+		if r.end, err = strconv.ParseUint(end, 10, 64); err != nil {
+			return r, fmt.Errorf("invalid range end: %w", err)
+		}
+		if r.end < r.start {
+			return r, errors.New("range end is less than start")
+		}
+		r.hasEnd = true
+	}
+
+	return r, nil
+}
+
+// zipRequestedRange returns the range of the archive with the given
+// continuation token to send. Range is ignored if the request has neither
+// If-Range header nor continuation query parameter matching the token. Returns
+// false if the response is already sent.
+func zipRequestedRange(c *fasthttp.RequestCtx, token string) (*byteRange, bool) {
+	header := c.Request.Header.Peek(fasthttp.HeaderRange)
+	if len(header) == 0 {
+		return nil, true
+	}
+
+	if continuation := c.QueryArgs().Peek(queryZipContinuation); len(continuation) != 0 {
+		if string(continuation) != token {
+			response.Error(c, "archive has changed, continuation token doesn't match", fasthttp.StatusPreconditionFailed)
+			return nil, false
+		}
+	} else if string(c.Request.Header.Peek(fasthttp.HeaderIfRange)) != strconv.Quote(token) {
+		// the archive may have changed, so the whole one is sent
+		return nil, true
+	}
+
+	r, err := parseByteRange(string(header))
+	if err != nil {
+		response.Error(c, "invalid range: "+err.Error(), fasthttp.StatusRequestedRangeNotSatisfiable)
+		return nil, false
+	}
+	return &r, true
+}
+
+// limit checks the range against the content size and sets the range end if
+// it's not specified or exceeds the size. Responds with 416 Range Not
+// Satisfiable if the range starts after the content.
+func (r *byteRange) limit(c *fasthttp.RequestCtx, size uint64) bool {
+	if r.start >= size {
+		response.Error(c, "range starts after the content end", fasthttp.StatusRequestedRangeNotSatisfiable)
+		c.Response.Header.Set(fasthttp.HeaderContentRange, "bytes */"+strconv.FormatUint(size, 10))
+		return false
+	}
+	if !r.hasEnd || r.end >= size {
+		r.end = size - 1
+	}
+	return true
+}
+
+// setHeaders sets headers of the partial response with the range of the
+// content of the given size.
+func (r byteRange) setHeaders(c *fasthttp.RequestCtx, size uint64) {
+	c.Response.Header.Set(fasthttp.HeaderContentRange,
+		fmt.Sprintf("bytes %d-%d/%d", r.start, r.end, size))
+	c.Response.SetStatusCode(fasthttp.StatusPartialContent)
+}
+
+// len returns the number of bytes in the range.
+func (r byteRange) len() int {
+	return int(r.end - r.start + 1)
+}
+
+// rangeWriter passes only bytes of the range of the written stream,
+// errRangeWritten is returned after the range is written.
+type rangeWriter struct {
+	w     io.Writer
+	r     byteRange
+	pos   uint64
+	wrote bool
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	if w.wrote {
+		return 0, errRangeWritten
+	}
+
+	from, to := w.pos, w.pos+uint64(len(p))
+	w.pos = to
+	if to <= w.r.start {
+		return len(p), nil
+	}
+
+	var begin, end = uint64(0), uint64(len(p))
+	if w.r.start > from {
+		begin = w.r.start - from
+	}
+	if w.r.end+1 < to {
+		end = w.r.end + 1 - from
+	}
+	if _, err := w.w.Write(p[begin:end]); err != nil {
+		return 0, err
+	}
+	if to > w.r.end {
+		w.wrote = true
+		return int(end), errRangeWritten
+	}
+	return len(p), nil
+}
+
+// Skip implements zipstream.Skipper, only bytes preceding the range can be
+// skipped.
+func (w *rangeWriter) Skip(n uint64) error {
+	if w.pos+n > w.r.start {
+		return errors.New("skipped bytes overlap the range")
+	}
+	w.pos += n
+	return nil
+}
+
+// zipChecksumsSize is the maximum number of remembered object checksums.
+const zipChecksumsSize = 1 << 16
+
+// zipChecksums remembers CRC-32 of payloads of objects added to uncompressed
+// archives, so resumed downloads don't read objects preceding the range.
+// Objects are immutable, so their checksums never change.
+type zipChecksums struct {
+	mu   sync.Mutex
+	sums map[oid.Address]uint32
+}
+
+func newZipChecksums() *zipChecksums {
+	return &zipChecksums{sums: make(map[oid.Address]uint32)}
+}
+
+// get returns the checksum of the object payload, false if it's unknown.
+func (z *zipChecksums) get(addr oid.Address) (uint32, bool) {
+	if z == nil {
+		return 0, false
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	sum, ok := z.sums[addr]
+	return sum, ok
+}
+
+// put remembers the checksum of the object payload, some other checksum is
+// forgotten if there are too many of them.
+func (z *zipChecksums) put(addr oid.Address, sum uint32) {
+	if z == nil {
+		return
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if _, ok := z.sums[addr]; !ok && len(z.sums) >= zipChecksumsSize {
+		for k := range z.sums {
+			delete(z.sums, k)
+			break
+		}
+	}
+	z.sums[addr] = sum
+}
+
+// zipStoredSize calculates the size of the uncompressed archive of the entries
+// starting with the manifest if it's not nil.
+func zipStoredSize(entries []zipEntry, manifest []byte) (uint64, error) {
+	headers := make([]zipstream.FileHeader, 0, len(entries)+1)
+	if manifest != nil {
+		headers = append(headers, zipManifestHeader(manifest, false))
+	}
+	for _, e := range entries {
+		headers = append(headers, e.header)
+	}
+
+	return zipstream.StoredSize(headers)
+}
+
+// setZipResumeHeaders sets headers allowing to resume the archive download.
+func setZipResumeHeaders(c *fasthttp.RequestCtx, key string) {
+	c.Response.Header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+	c.Response.Header.Set(fasthttp.HeaderETag, strconv.Quote(key))
+	c.Response.Header.Set(hdrZipContinuation, key)
+}
@@ -0,0 +1,253 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestParseByteRange(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		r      byteRange
+	}{
+		{header: "bytes=0-", r: byteRange{}},
+		{header: "bytes=10-", r: byteRange{start: 10}},
+		{header: "bytes=10-20", r: byteRange{start: 10, end: 20, hasEnd: true}},
+		{header: "bytes=5-5", r: byteRange{start: 5, end: 5, hasEnd: true}},
+	} {
+		r, err := parseByteRange(tc.header)
+		require.NoError(t, err, tc.header)
+		require.Equal(t, tc.r, r, tc.header)
+	}
+
+	for _, header := range []string{
+		"",
+		"items=0-",
+		"bytes=-10",
+		"bytes=0-1,5-6",
+		"bytes=20-10",
+		"bytes=a-",
+		"bytes=0-b",
+		"bytes=10",
+	} {
+		_, err := parseByteRange(header)
+		require.Error(t, err, header)
+	}
+}
+
+func TestRangeWriter(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	for _, r := range []byteRange{
+		{start: 0, end: 99},
+		{start: 0, end: 0},
+		{start: 10, end: 99},
+		{start: 10, end: 10},
+		{start: 15, end: 42},
+		{start: 99, end: 99},
+	} {
+		for _, chunk := range []int{1, 7, 10, 100} {
+			var buf bytes.Buffer
+			w := &rangeWriter{w: &buf, r: r}
+
+			var err error
+			for off := 0; off < len(data) && err == nil; off += chunk {
+				end := off + chunk
+				if end > len(data) {
+					end = len(data)
+				}
+				_, err = w.Write(data[off:end])
+			}
+			require.ErrorIs(t, err, errRangeWritten)
+			require.Equal(t, data[r.start:r.end+1], buf.Bytes(), "range %d-%d, chunk %d", r.start, r.end, chunk)
+
+			_, err = w.Write(data)
+			require.ErrorIs(t, err, errRangeWritten)
+		}
+	}
+}
+
+func TestZipRequestedRange(t *testing.T) {
+	const token = "token"
+
+	request := func(rng, ifRange, continuation string) (*fasthttp.RequestCtx, *byteRange, bool) {
+		var c fasthttp.RequestCtx
+		if rng != "" {
+			c.Request.Header.Set(fasthttp.HeaderRange, rng)
+		}
+		if ifRange != "" {
+			c.Request.Header.Set(fasthttp.HeaderIfRange, ifRange)
+		}
+		if continuation != "" {
+			c.Request.SetRequestURI("/zip/cid/prefix?" + queryZipContinuation + "=" + continuation)
+		}
+		r, ok := zipRequestedRange(&c, token)
+		return &c, r, ok
+	}
+
+	t.Run("no range", func(t *testing.T) {
+		_, r, ok := request("", strconv.Quote(token), token)
+		require.True(t, ok)
+		require.Nil(t, r)
+	})
+
+	t.Run("if-range", func(t *testing.T) {
+		_, r, ok := request("bytes=10-", strconv.Quote(token), "")
+		require.True(t, ok)
+		require.Equal(t, &byteRange{start: 10}, r)
+	})
+
+	t.Run("if-range mismatch", func(t *testing.T) {
+		_, r, ok := request("bytes=10-", strconv.Quote("other"), "")
+		require.True(t, ok)
+		require.Nil(t, r)
+
+		_, r, ok = request("bytes=10-", "", "")
+		require.True(t, ok)
+		require.Nil(t, r)
+	})
+
+	t.Run("continuation", func(t *testing.T) {
+		_, r, ok := request("bytes=10-20", "", token)
+		require.True(t, ok)
+		require.Equal(t, &byteRange{start: 10, end: 20, hasEnd: true}, r)
+	})
+
+	t.Run("continuation mismatch", func(t *testing.T) {
+		c, _, ok := request("bytes=10-", "", "other")
+		require.False(t, ok)
+		require.Equal(t, fasthttp.StatusPreconditionFailed, c.Response.StatusCode())
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		c, _, ok := request("bytes=-10", "", token)
+		require.False(t, ok)
+		require.Equal(t, fasthttp.StatusRequestedRangeNotSatisfiable, c.Response.StatusCode())
+	})
+}
+
+func TestServeCachedZipRange(t *testing.T) {
+	const (
+		key  = "key"
+		data = "0123456789"
+	)
+	cnrID := cidtest.ID()
+	cache := newTestZipCache(t, time.Hour, 100, 100)
+	putTestContainerArchive(t, cache, key, cnrID, data)
+
+	d := &Downloader{log: zap.NewNop(), settings: &Settings{}}
+
+	serve := func(rng *byteRange) *fasthttp.RequestCtx {
+		f, size, ok := cache.Get(key)
+		require.True(t, ok)
+
+		var c fasthttp.RequestCtx
+		d.serveCachedZip(&c, f, size, rng, cnrID, key, false)
+		return &c
+	}
+
+	t.Run("full", func(t *testing.T) {
+		c := serve(nil)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+		require.Equal(t, data, string(c.Response.Body()))
+		require.Equal(t, strconv.Quote(key), string(c.Response.Header.Peek(fasthttp.HeaderETag)))
+		require.Equal(t, key, string(c.Response.Header.Peek(hdrZipContinuation)))
+	})
+
+	t.Run("range", func(t *testing.T) {
+		c := serve(&byteRange{start: 3, end: 5, hasEnd: true})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+		require.Equal(t, "345", string(c.Response.Body()))
+		require.Equal(t, "bytes 3-5/10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)))
+	})
+
+	t.Run("open range", func(t *testing.T) {
+		c := serve(&byteRange{start: 7})
+		require.Equal(t, fasthttp.StatusPartialContent, c.Response.StatusCode())
+		require.Equal(t, "789", string(c.Response.Body()))
+		require.Equal(t, "bytes 7-9/10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)))
+	})
+
+	t.Run("not satisfiable", func(t *testing.T) {
+		c := serve(&byteRange{start: 10})
+		require.Equal(t, fasthttp.StatusRequestedRangeNotSatisfiable, c.Response.StatusCode())
+		require.Equal(t, "bytes */10", string(c.Response.Header.Peek(fasthttp.HeaderContentRange)))
+	})
+}
+
+// readCountingBackend counts object reads.
+type readCountingBackend struct {
+	*backend.Memory
+	gets, ranges map[oid.ID]int
+}
+
+func (b *readCountingBackend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	b.gets[objectID]++
+	return b.Memory.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+}
+
+func (b *readCountingBackend) ObjectRangeInit(ctx context.Context, containerID cid.ID, objectID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error) {
+	b.ranges[objectID]++
+	return b.Memory.ObjectRangeInit(ctx, containerID, objectID, offset, length, signer, prm)
+}
+
+func TestZipResumeSkipsObjects(t *testing.T) {
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	ids := []oid.ID{
+		putFile(t, mem, cnrID, "a.txt", strings.Repeat("a", 100)),
+		putFile(t, mem, cnrID, "b.txt", strings.Repeat("b", 100)),
+		putFile(t, mem, cnrID, "c.txt", strings.Repeat("c", 100)),
+	}
+	b := &readCountingBackend{Memory: mem, gets: make(map[oid.ID]int), ranges: make(map[oid.ID]int)}
+	d := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: b}, &Settings{}, nil)
+	entries, err := d.zipEntries(cnrID, ids, nil, false, zap.NewNop())
+	require.NoError(t, err)
+
+	resume := func(start uint64) []byte {
+		var buf bytes.Buffer
+		d.writeZip(&rangeWriter{w: &buf, r: byteRange{start: start, end: math.MaxUint64 - 1}}, start, cnrID, entries, nil, nil, false, zap.NewNop())
+		return buf.Bytes()
+	}
+
+	var full bytes.Buffer
+	other := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem}, &Settings{}, nil)
+	require.True(t, other.writeZip(&full, 0, cnrID, entries, nil, nil, false, zap.NewNop()))
+	// the range starts in the middle of the second object
+	start := uint64(bytes.Index(full.Bytes(), []byte("bbbb")) + 50)
+
+	// checksums are unknown, so all objects are read
+	require.Equal(t, full.Bytes()[start:], resume(start))
+	for _, e := range entries {
+		require.Equal(t, 1, b.gets[e.id])
+	}
+
+	// the first object precedes the range, the second one is read from it
+	b.gets, b.ranges = make(map[oid.ID]int), make(map[oid.ID]int)
+	require.Equal(t, full.Bytes()[start:], resume(start))
+	require.Zero(t, b.gets[entries[0].id]+b.ranges[entries[0].id])
+	require.Equal(t, 1, b.ranges[entries[1].id])
+	require.Equal(t, 1, b.gets[entries[2].id])
+}
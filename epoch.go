@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"go.uber.org/zap"
+)
+
+// networkInfoSource provides NeoFS network info, it's either a connection pool
+// or a backend.
+type networkInfoSource interface {
+	NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error)
+}
+
+// epochWatcher polls NeoFS network info and notifies subscribers about epoch
+// changes, so epoch-dependent state is updated without waiting for requests.
+type epochWatcher struct {
+	log       *zap.Logger
+	pool      networkInfoSource
+	connected *atomic.Bool
+	epoch     uint64
+	handlers  []func(netmap.NetworkInfo)
+	last      atomic.Pointer[netmap.NetworkInfo]
+}
+
+func newEpochWatcher(log *zap.Logger, p networkInfoSource, connected *atomic.Bool) *epochWatcher {
+	return &epochWatcher{log: log, pool: p, connected: connected}
+}
+
+// subscribe registers the handler called with the network info on every
+// epoch change including the first poll. It must be called before run.
+func (w *epochWatcher) subscribe(h func(netmap.NetworkInfo)) {
+	w.handlers = append(w.handlers, h)
+}
+
+// run polls network info with the given interval until the context is done.
+func (w *epochWatcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *epochWatcher) poll(ctx context.Context, timeout time.Duration) {
+	if !w.connected.Load() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ni, err := w.pool.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		w.log.Warn("could not get network info", zap.Error(err))
+		return
+	}
+
+	w.notify(ni)
+}
+
+// networkInfo returns the last polled network info, nil if it's unknown.
+func (w *epochWatcher) networkInfo() *netmap.NetworkInfo {
+	if w == nil {
+		return nil
+	}
+	return w.last.Load()
+}
+
+// notify remembers the network info and calls handlers if its epoch differs
+// from the previously seen one.
+func (w *epochWatcher) notify(ni netmap.NetworkInfo) {
+	w.last.Store(&ni)
+
+	epoch := ni.CurrentEpoch()
+	if epoch == w.epoch {
+		return
+	}
+	if w.epoch != 0 {
+		w.log.Info("new epoch", zap.Uint64("epoch", epoch))
+	}
+	w.epoch = epoch
+
+	for _, h := range w.handlers {
+		h(ni)
+	}
+}
+
+// startEpochWatchers starts polling epochs of all the networks, maximum object
+// sizes of the networks are updated and cached network info is dropped on
+// epoch change.
+func (a *app) startEpochWatchers(ctx context.Context, uploadRoutes *uploader.Uploader) {
+	interval := a.cfg.GetDuration(cfgEpochPollInterval)
+	if interval <= 0 {
+		return
+	}
+
+	cache := a.metaCaches[""]
+	a.epochs.subscribe(func(ni netmap.NetworkInfo) {
+		uploadRoutes.ObserveEpoch(ni.CurrentEpoch())
+		a.settings.Uploader.SetMaxObjectSize(int64(ni.MaxObjectSize()))
+		if cache != nil {
+			cache.InvalidateNetworkInfo()
+		}
+	})
+	go a.epochs.run(ctx, interval)
+
+	for _, n := range a.networks {
+		u := n.uploader
+		cache := a.metaCaches[n.name]
+		n.epochs.subscribe(func(ni netmap.NetworkInfo) {
+			u.ObserveEpoch(ni.CurrentEpoch())
+			u.SetMaxObjectSize(int64(ni.MaxObjectSize()))
+			if cache != nil {
+				cache.InvalidateNetworkInfo()
+			}
+		})
+		go n.epochs.run(ctx, interval)
+	}
+}
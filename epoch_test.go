@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEpochWatcherNotify(t *testing.T) {
+	w := newEpochWatcher(zap.NewNop(), nil, nil)
+
+	var epochs []uint64
+	w.subscribe(func(ni netmap.NetworkInfo) {
+		epochs = append(epochs, ni.CurrentEpoch())
+	})
+
+	var ni netmap.NetworkInfo
+	for _, epoch := range []uint64{10, 10, 11, 11, 11, 12} {
+		ni.SetCurrentEpoch(epoch)
+		w.notify(ni)
+	}
+
+	require.Equal(t, []uint64{10, 11, 12}, epochs)
+	require.EqualValues(t, 12, w.networkInfo().CurrentEpoch())
+
+	w = nil
+	require.Nil(t, w.networkInfo())
+}
+
+func TestInfoResponseNetwork(t *testing.T) {
+	require.Nil(t, newInfoResponse(new(compat.Versions), nil).Network)
+
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(10)
+	ni.SetMaxObjectSize(1 << 26)
+	ni.DisableHomomorphicHashing()
+
+	require.Equal(t, &networkConfig{
+		CurrentEpoch:               10,
+		MaxObjectSize:              1 << 26,
+		HomomorphicHashingDisabled: true,
+	}, newInfoResponse(new(compat.Versions), &ni).Network)
+}
+
+func TestInfoResponseCapabilities(t *testing.T) {
+	caps := newInfoResponse(new(compat.Versions), nil).Capabilities
+	for _, c := range []string{"byte_ranges", "suffix_ranges", "if_range", "object_pinning"} {
+		require.True(t, caps[c], c)
+	}
+}
@@ -0,0 +1,298 @@
+// Package extcache caches object headers and small payloads in an external
+// store (Redis or memcached) shared by gateway replicas, so hot objects are
+// read from storage nodes once per TTL for the whole deployment.
+package extcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// Supported store types.
+const (
+	TypeRedis     = "redis"
+	TypeMemcached = "memcached"
+)
+
+// Serialization formats of cached objects.
+const (
+	// FormatProtobuf stores objects in NeoFS API binary format.
+	FormatProtobuf = "protobuf"
+	// FormatJSON stores objects in NeoFS API JSON format, it's larger, but
+	// cached entries can be inspected with store tools.
+	FormatJSON = "json"
+)
+
+// keyPrefix starts all the keys written by the gateway.
+const keyPrefix = "neofs-http-gw:"
+
+// Kinds of cached entries.
+const (
+	kindHeader = "h"
+	kindObject = "o"
+)
+
+// ErrMiss is returned by Store when there is no value for the key.
+var ErrMiss = errors.New("cache miss")
+
+// Store is an external key-value store.
+type Store interface {
+	// Get returns the value stored for the key, ErrMiss if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value for the key for the given time.
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration) error
+	// Delete removes the value of the key, missing keys aren't an error.
+	Delete(ctx context.Context, key string) error
+	// Close releases store connections.
+	Close() error
+}
+
+// StoreConfig contains parameters of the store connections.
+type StoreConfig struct {
+	// Addresses of the store servers. Memcached keys are sharded between
+	// them. Several Redis servers are cluster nodes used to discover the
+	// cluster, a single one is a standalone server.
+	Addresses []string
+	// Password used to authenticate in Redis.
+	Password string
+	// Timeout limits dialing and a single operation with the store.
+	Timeout time.Duration
+	// PoolSize is the number of idle connections kept per server.
+	PoolSize int
+}
+
+// NewStore creates the store of the given type.
+func NewStore(typ string, cfg StoreConfig) (Store, error) {
+	if len(cfg.Addresses) == 0 {
+		return nil, errors.New("no store addresses")
+	}
+	switch typ {
+	case TypeRedis:
+		return NewRedis(cfg), nil
+	case TypeMemcached:
+		return NewMemcached(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown store type '%s'", typ)
+	}
+}
+
+// Config contains caching parameters.
+type Config struct {
+	// Namespace separates entries of different NeoFS networks in the store.
+	Namespace string
+	// Format is the serialization format of cached objects.
+	Format string
+	// HeadTTL is the time object headers are cached for.
+	HeadTTL time.Duration
+	// PayloadTTL is the time objects with payload are cached for.
+	PayloadTTL time.Duration
+	// MaxPayloadSize is the maximum size of cached payloads.
+	MaxPayloadSize uint64
+}
+
+type shareableKey struct{}
+
+// Shareable marks operations with the context as the ones whose results can
+// be shared with any client, i.e. they're executed without bearer token. Other
+// operations aren't cached.
+func Shareable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shareableKey{}, true)
+}
+
+// IsShareable checks whether the context is marked with Shareable.
+func IsShareable(ctx context.Context) bool {
+	v, _ := ctx.Value(shareableKey{}).(bool)
+	return v
+}
+
+// Backend caches headers and small payloads of objects read via the wrapped
+// backend. Only shareable operations are cached, store failures are logged
+// and the wrapped backend is used instead.
+type Backend struct {
+	backend.Backend
+	store Store
+	cfg   Config
+	log   *zap.Logger
+}
+
+// NewBackend wraps the backend caching objects in the store.
+func NewBackend(b backend.Backend, store Store, cfg Config, log *zap.Logger) *Backend {
+	return &Backend{Backend: b, store: store, cfg: cfg, log: log}
+}
+
+// ObjectHead implements backend.Backend.
+func (b *Backend) ObjectHead(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectHead) (*object.Object, error) {
+	if !IsShareable(ctx) {
+		return b.Backend.ObjectHead(ctx, containerID, objectID, signer, prm)
+	}
+
+	key := b.key(kindHeader, containerID, objectID)
+	if obj := b.load(ctx, key); obj != nil {
+		return obj, nil
+	}
+
+	obj, err := b.Backend.ObjectHead(ctx, containerID, objectID, signer, prm)
+	if err == nil {
+		b.save(ctx, key, obj, b.cfg.HeadTTL)
+	}
+	return obj, err
+}
+
+// ObjectGetInit implements backend.Backend. Payloads of cached objects are
+// read from storage at once.
+func (b *Backend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	if !IsShareable(ctx) || b.cfg.MaxPayloadSize == 0 || b.cfg.PayloadTTL <= 0 {
+		return b.Backend.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+	}
+
+	key := b.key(kindObject, containerID, objectID)
+	if obj := b.load(ctx, key); obj != nil {
+		return *obj.CutPayload(), io.NopCloser(bytes.NewReader(obj.Payload())), nil
+	}
+
+	hdr, r, err := b.Backend.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+	if err != nil || hdr.PayloadSize() > b.cfg.MaxPayloadSize {
+		return hdr, r, err
+	}
+
+	payload, err := io.ReadAll(r)
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return hdr, nil, err
+	}
+
+	if uint64(len(payload)) == hdr.PayloadSize() {
+		obj := hdr
+		obj.SetPayload(payload)
+		b.save(ctx, key, &obj, b.cfg.PayloadTTL)
+		b.save(ctx, b.key(kindHeader, containerID, objectID), &hdr, b.cfg.HeadTTL)
+	}
+	return hdr, io.NopCloser(bytes.NewReader(payload)), nil
+}
+
+// ObjectRangeInit implements backend.Backend. Ranges are cut from cached
+// objects, they aren't cached themselves.
+func (b *Backend) ObjectRangeInit(ctx context.Context, containerID cid.ID, objectID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error) {
+	if IsShareable(ctx) && b.cfg.MaxPayloadSize != 0 {
+		if obj := b.load(ctx, b.key(kindObject, containerID, objectID)); obj != nil {
+			// out of range requests are left for storage to fail them
+			if payload := obj.Payload(); offset+length >= offset && offset+length <= uint64(len(payload)) {
+				return io.NopCloser(bytes.NewReader(payload[offset : offset+length])), nil
+			}
+		}
+	}
+	return b.Backend.ObjectRangeInit(ctx, containerID, objectID, offset, length, signer, prm)
+}
+
+// ObjectDelete implements backend.Backend. Cached entries of the removed
+// object are dropped, so replicas stop serving it before the TTL expires.
+func (b *Backend) ObjectDelete(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectDelete) (oid.ID, error) {
+	res, err := b.Backend.ObjectDelete(ctx, containerID, objectID, signer, prm)
+	if err == nil {
+		if err := b.invalidator().Invalidate(ctx, containerID, objectID); err != nil {
+			b.log.Warn("couldn't remove deleted object from external cache", zap.Stringer("cid", containerID),
+				zap.Stringer("oid", objectID), zap.Error(err))
+		}
+	}
+	return res, err
+}
+
+func (b *Backend) invalidator() *Invalidator {
+	return &Invalidator{store: b.store, cfg: b.cfg}
+}
+
+func (b *Backend) key(kind string, containerID cid.ID, objectID oid.ID) string {
+	return objectKey(b.cfg, kind, containerID, objectID)
+}
+
+// Invalidator removes cached objects from the store.
+type Invalidator struct {
+	store Store
+	cfg   Config
+}
+
+// NewInvalidator creates Invalidator of objects cached with the config.
+func NewInvalidator(store Store, cfg Config) *Invalidator {
+	return &Invalidator{store: store, cfg: cfg}
+}
+
+// Invalidate removes the cached header and payload of the object. Nil
+// Invalidator does nothing.
+func (i *Invalidator) Invalidate(ctx context.Context, containerID cid.ID, objectID oid.ID) error {
+	if i == nil {
+		return nil
+	}
+	for _, kind := range []string{kindHeader, kindObject} {
+		if err := i.store.Delete(ctx, objectKey(i.cfg, kind, containerID, objectID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func objectKey(cfg Config, kind string, containerID cid.ID, objectID oid.ID) string {
+	key := keyPrefix
+	if cfg.Namespace != "" {
+		key += cfg.Namespace + ":"
+	}
+	return key + cfg.Format + ":" + kind + ":" + containerID.EncodeToString() + "/" + objectID.EncodeToString()
+}
+
+// load returns the object cached under the key, nil if there is none.
+func (b *Backend) load(ctx context.Context, key string) *object.Object {
+	data, err := b.store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrMiss) {
+			b.log.Debug("couldn't get object from external cache", zap.String("key", key), zap.Error(err))
+		}
+		return nil
+	}
+
+	obj := new(object.Object)
+	if b.cfg.Format == FormatJSON {
+		err = obj.UnmarshalJSON(data)
+	} else {
+		err = obj.Unmarshal(data)
+	}
+	if err != nil {
+		b.log.Warn("invalid object in external cache", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return obj
+}
+
+func (b *Backend) save(ctx context.Context, key string, obj *object.Object, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if b.cfg.Format == FormatJSON {
+		data, err = obj.MarshalJSON()
+	} else {
+		data, err = obj.Marshal()
+	}
+	if err == nil {
+		err = b.store.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		b.log.Debug("couldn't put object to external cache", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,182 @@
+package extcache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mapStore is in-memory Store ignoring TTLs.
+type mapStore struct {
+	mu   sync.Mutex
+	vals map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{vals: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (s *mapStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	val, ok := s.vals[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	return val, nil
+}
+
+func (s *mapStore) Set(_ context.Context, key string, val []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vals[key] = val
+	s.ttls[key] = ttl
+	return nil
+}
+
+func (s *mapStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vals, key)
+	delete(s.ttls, key)
+	return nil
+}
+
+func (s *mapStore) Close() error { return nil }
+
+func putObject(t *testing.T, b backend.Backend, hdr object.Object, payload []byte) oid.ID {
+	w, err := b.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func TestBackend(t *testing.T) {
+	for _, format := range []string{FormatProtobuf, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			testBackend(t, format)
+		})
+	}
+}
+
+func testBackend(t *testing.T, format string) {
+	shared := Shareable(context.Background())
+	m := backend.NewMemory()
+	cnrID := m.AddContainer(containertest.Container(t))
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	small := putObject(t, m, hdr, []byte("meow"))
+	large := putObject(t, m, hdr, bytes.Repeat([]byte("woof"), 10))
+	private := putObject(t, m, hdr, []byte("purr"))
+
+	store := newMapStore()
+	b := NewBackend(m, store, Config{
+		Namespace:      "main",
+		Format:         format,
+		HeadTTL:        time.Minute,
+		PayloadTTL:     time.Hour,
+		MaxPayloadSize: 16,
+	}, zap.NewNop())
+
+	get := func(ctx context.Context, id oid.ID) ([]byte, error) {
+		obj, r, err := b.ObjectGetInit(ctx, cnrID, id, nil, client.PrmObjectGet{})
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		payload, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.EqualValues(t, len(payload), obj.PayloadSize())
+		return payload, nil
+	}
+
+	payload, err := get(shared, small)
+	require.NoError(t, err)
+	require.Equal(t, []byte("meow"), payload)
+	_, err = get(shared, large)
+	require.NoError(t, err)
+	_, err = get(context.Background(), private)
+	require.NoError(t, err)
+
+	key := keyPrefix + "main:" + format + ":o:" + cnrID.EncodeToString() + "/" + small.EncodeToString()
+	require.Contains(t, store.vals, key)
+	require.Equal(t, time.Hour, store.ttls[key])
+	require.Len(t, store.vals, 2, "only small object and its header are cached")
+
+	// cached objects are served after removal from storage
+	for _, id := range []oid.ID{small, large, private} {
+		_, err = m.ObjectDelete(context.Background(), cnrID, id, nil, client.PrmObjectDelete{})
+		require.NoError(t, err)
+	}
+
+	payload, err = get(shared, small)
+	require.NoError(t, err)
+	require.Equal(t, []byte("meow"), payload)
+	_, err = get(shared, large)
+	require.ErrorIs(t, err, apistatus.ErrObjectAlreadyRemoved)
+	_, err = get(context.Background(), small)
+	require.ErrorIs(t, err, apistatus.ErrObjectAlreadyRemoved)
+
+	obj, err := b.ObjectHead(shared, cnrID, small, nil, client.PrmObjectHead{})
+	require.NoError(t, err)
+	require.EqualValues(t, 4, obj.PayloadSize())
+	require.Empty(t, obj.Payload())
+
+	r, err := b.ObjectRangeInit(shared, cnrID, small, 1, 2, nil, client.PrmObjectRange{})
+	require.NoError(t, err)
+	payload, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte("eo"), payload)
+
+	_, err = b.ObjectRangeInit(shared, cnrID, small, 3, 2, nil, client.PrmObjectRange{})
+	require.Error(t, err)
+}
+
+func TestInvalidation(t *testing.T) {
+	shared := Shareable(context.Background())
+	m := backend.NewMemory()
+	cnrID := m.AddContainer(containertest.Container(t))
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	deleted := putObject(t, m, hdr, []byte("meow"))
+	purged := putObject(t, m, hdr, []byte("woof"))
+
+	store := newMapStore()
+	cfg := Config{Format: FormatProtobuf, HeadTTL: time.Minute, PayloadTTL: time.Hour, MaxPayloadSize: 16}
+	b := NewBackend(m, store, cfg, zap.NewNop())
+	for _, id := range []oid.ID{deleted, purged} {
+		_, r, err := b.ObjectGetInit(shared, cnrID, id, nil, client.PrmObjectGet{})
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+	}
+	require.Len(t, store.vals, 4)
+
+	_, err := b.ObjectDelete(context.Background(), cnrID, deleted, nil, client.PrmObjectDelete{})
+	require.NoError(t, err)
+	require.Len(t, store.vals, 2, "entries of the deleted object are removed")
+	_, err = b.ObjectHead(shared, cnrID, deleted, nil, client.PrmObjectHead{})
+	require.ErrorIs(t, err, apistatus.ErrObjectAlreadyRemoved)
+
+	require.NoError(t, NewInvalidator(store, cfg).Invalidate(context.Background(), cnrID, purged))
+	require.Empty(t, store.vals)
+
+	var nilInvalidator *Invalidator
+	require.NoError(t, nilInvalidator.Invalidate(context.Background(), cnrID, purged))
+}
@@ -0,0 +1,98 @@
+package extcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/nspcc-dev/neofs-http-gw/hashring"
+)
+
+// maxRelativeExpiration is the maximum expiration memcached treats as
+// relative, larger ones are Unix timestamps.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// Memcached is the Store using memcached servers. Keys are sharded between
+// servers with consistent hashing, so adding a server moves a small part of
+// them only.
+type Memcached struct {
+	client *memcache.Client
+}
+
+// NewMemcached creates memcached store, connections are established on
+// demand.
+func NewMemcached(cfg StoreConfig) *Memcached {
+	c := memcache.NewFromSelector(ringSelector{hashring.New(cfg.Addresses, hashring.DefaultReplicas)})
+	c.Timeout = cfg.Timeout
+	c.MaxIdleConns = cfg.PoolSize
+	return &Memcached{client: c}
+}
+
+// Get implements Store. Operations are limited by the store timeout, the
+// context isn't used.
+func (m *Memcached) Get(_ context.Context, key string) ([]byte, error) {
+	item, err := m.client.Get(key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return nil, ErrMiss
+		}
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+// Set implements Store.
+func (m *Memcached) Set(_ context.Context, key string, val []byte, ttl time.Duration) error {
+	exp := int64((ttl + time.Second - 1) / time.Second)
+	if ttl > maxRelativeExpiration {
+		exp = time.Now().Add(ttl).Unix()
+	}
+	return m.client.Set(&memcache.Item{Key: key, Value: val, Expiration: int32(exp)})
+}
+
+// Delete implements Store.
+func (m *Memcached) Delete(_ context.Context, key string) error {
+	err := m.client.Delete(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil
+	}
+	return err
+}
+
+// Close implements Store.
+func (m *Memcached) Close() error {
+	return m.client.Close()
+}
+
+// ringSelector selects memcached servers by the consistent hash ring.
+type ringSelector struct {
+	ring *hashring.Ring
+}
+
+// PickServer implements memcache.ServerSelector.
+func (s ringSelector) PickServer(key string) (net.Addr, error) {
+	addr := s.ring.Owner(key)
+	if addr == "" {
+		return nil, memcache.ErrNoServers
+	}
+	return serverAddr(addr), nil
+}
+
+// Each implements memcache.ServerSelector.
+func (s ringSelector) Each(f func(net.Addr) error) error {
+	for _, addr := range s.ring.Peers() {
+		if err := f(serverAddr(addr)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serverAddr is the server address resolved on dialing.
+type serverAddr string
+
+func (a serverAddr) Network() string { return "tcp" }
+
+func (a serverAddr) String() string { return string(a) }
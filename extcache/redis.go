@@ -0,0 +1,55 @@
+package extcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is the Store using Redis standalone server or cluster. Several
+// addresses are cluster nodes, a single one is a standalone server.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+// NewRedis creates Redis store, connections are established on demand.
+func NewRedis(cfg StoreConfig) *Redis {
+	return &Redis{client: redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:        cfg.Addresses,
+		Password:     cfg.Password,
+		DialTimeout:  cfg.Timeout,
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+		MaxIdleConns: cfg.PoolSize,
+	})}
+}
+
+// Get implements Store.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return val, err
+}
+
+// Set implements Store.
+func (r *Redis) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	// zero expiration means no expiration for Redis
+	if ttl < time.Millisecond {
+		ttl = time.Millisecond
+	}
+	return r.client.Set(ctx, key, val, ttl).Err()
+}
+
+// Delete implements Store.
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, key).Err()
+}
+
+// Close implements Store.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,245 @@
+package extcache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer serves connections with the handler until the test ends.
+func fakeServer(t *testing.T, handle func(r *bufio.Reader, w *bufio.Writer) error) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				r, w := bufio.NewReader(c), bufio.NewWriter(c)
+				for handle(r, w) == nil && w.Flush() == nil {
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// fakeMemcached handles gets, set and delete commands of the text protocol.
+func fakeMemcached(t *testing.T, vals *sync.Map) string {
+	return fakeServer(t, func(r *bufio.Reader, w *bufio.Writer) error {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "gets":
+			for _, key := range fields[1:] {
+				if val, ok := vals.Load(key); ok {
+					fmt.Fprintf(w, "VALUE %s 0 %d 1\r\n%s\r\n", key, len(val.([]byte)), val)
+				}
+			}
+			_, err = w.WriteString("END\r\n")
+		case "set":
+			size, _ := strconv.Atoi(fields[4])
+			val := make([]byte, size+2)
+			if _, err = io.ReadFull(r, val); err != nil {
+				return err
+			}
+			vals.Store(fields[1], val[:size])
+			_, err = w.WriteString("STORED\r\n")
+		case "delete":
+			if _, ok := vals.LoadAndDelete(fields[1]); ok {
+				_, err = w.WriteString("DELETED\r\n")
+			} else {
+				_, err = w.WriteString("NOT_FOUND\r\n")
+			}
+		default:
+			_, err = w.WriteString("ERROR\r\n")
+		}
+		return err
+	})
+}
+
+func TestMemcached(t *testing.T) {
+	var vals1, vals2 sync.Map
+	m := NewMemcached(StoreConfig{
+		Addresses: []string{fakeMemcached(t, &vals1), fakeMemcached(t, &vals2)},
+		Timeout:   time.Second,
+	})
+	defer m.Close()
+	ctx := context.Background()
+
+	_, err := m.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrMiss)
+	require.NoError(t, m.Delete(ctx, "missing"))
+
+	for i := 0; i < 20; i++ {
+		key := "key" + strconv.Itoa(i)
+		require.NoError(t, m.Set(ctx, key, []byte("val\r\n"+key), time.Minute))
+		val, err := m.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, "val\r\n"+key, string(val))
+	}
+
+	var n1, n2 int
+	vals1.Range(func(any, any) bool { n1++; return true })
+	vals2.Range(func(any, any) bool { n2++; return true })
+	require.Equal(t, 20, n1+n2)
+	require.NotZero(t, n1, "keys are sharded")
+	require.NotZero(t, n2, "keys are sharded")
+
+	require.NoError(t, m.Delete(ctx, "key0"))
+	_, err = m.Get(ctx, "key0")
+	require.ErrorIs(t, err, ErrMiss)
+}
+
+// fakeRedis handles GET, SET, DEL and AUTH commands, HELLO is unknown like
+// in Redis before 6.0. CLUSTER SLOTS is answered with the slots if they're
+// given.
+func fakeRedis(t *testing.T, vals *sync.Map, slots func() string) string {
+	return fakeServer(t, func(r *bufio.Reader, w *bufio.Writer) error {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			if line, err = r.ReadString('\n'); err != nil {
+				return err
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			arg := make([]byte, size+2)
+			if _, err = io.ReadFull(r, arg); err != nil {
+				return err
+			}
+			args[i] = string(arg[:size])
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[1] != "secret" {
+				_, err = w.WriteString("-WRONGPASS invalid password\r\n")
+				return err
+			}
+			_, err = w.WriteString("+OK\r\n")
+		case "CLUSTER":
+			if slots == nil {
+				_, err = w.WriteString("-ERR This instance has cluster support disabled\r\n")
+				return err
+			}
+			_, err = w.WriteString(slots())
+		case "GET":
+			if val, ok := vals.Load(args[1]); ok {
+				_, err = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(val.(string)), val)
+			} else {
+				_, err = w.WriteString("$-1\r\n")
+			}
+		case "SET":
+			vals.Store(args[1], args[2])
+			_, err = w.WriteString("+OK\r\n")
+		case "DEL":
+			var deleted int
+			for _, key := range args[1:] {
+				if _, ok := vals.LoadAndDelete(key); ok {
+					deleted++
+				}
+			}
+			_, err = fmt.Fprintf(w, ":%d\r\n", deleted)
+		default:
+			_, err = fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+		}
+		return err
+	})
+}
+
+// clusterSlots returns CLUSTER SLOTS reply splitting slots between the nodes
+// in halves.
+func clusterSlots(addr1, addr2 string) string {
+	node := func(start, end int, addr string) string {
+		host, port, _ := net.SplitHostPort(addr)
+		return fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n*2\r\n$%d\r\n%s\r\n:%s\r\n", start, end, len(host), host, port)
+	}
+	return "*2\r\n" + node(0, 8191, addr1) + node(8192, 16383, addr2)
+}
+
+func testRedisStore(t *testing.T, r *Redis) {
+	ctx := context.Background()
+
+	_, err := r.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrMiss)
+	require.NoError(t, r.Delete(ctx, "missing"))
+
+	for i := 0; i < 20; i++ {
+		key := "key" + strconv.Itoa(i)
+		require.NoError(t, r.Set(ctx, key, []byte("val\r\n"+key), time.Minute))
+		val, err := r.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, "val\r\n"+key, string(val))
+	}
+
+	require.NoError(t, r.Delete(ctx, "key0"))
+	_, err = r.Get(ctx, "key0")
+	require.ErrorIs(t, err, ErrMiss)
+}
+
+func TestRedis(t *testing.T) {
+	t.Run("standalone", func(t *testing.T) {
+		var vals sync.Map
+		r := NewRedis(StoreConfig{
+			Addresses: []string{fakeRedis(t, &vals, nil)},
+			Password:  "secret",
+			Timeout:   time.Second,
+		})
+		defer r.Close()
+		testRedisStore(t, r)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		var (
+			vals1, vals2 sync.Map
+			addr1, addr2 string
+			slots        = func() string { return clusterSlots(addr1, addr2) }
+		)
+		addr1 = fakeRedis(t, &vals1, slots)
+		addr2 = fakeRedis(t, &vals2, slots)
+
+		r := NewRedis(StoreConfig{
+			Addresses: []string{"127.0.0.1:1", addr1, addr2},
+			Password:  "secret",
+			Timeout:   time.Second,
+		})
+		defer r.Close()
+		testRedisStore(t, r)
+
+		var n1, n2 int
+		vals1.Range(func(any, any) bool { n1++; return true })
+		vals2.Range(func(any, any) bool { n2++; return true })
+		require.Equal(t, 19, n1+n2)
+		require.NotZero(t, n1, "keys are spread between nodes")
+		require.NotZero(t, n2, "keys are spread between nodes")
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		var vals sync.Map
+		r := NewRedis(StoreConfig{Addresses: []string{fakeRedis(t, &vals, nil)}, Password: "wrong", Timeout: time.Second})
+		defer r.Close()
+		_, err := r.Get(context.Background(), "key")
+		require.ErrorContains(t, err, "WRONGPASS")
+	})
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/nspcc-dev/neofs-http-gw/maintenance"
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+)
+
+// Names of the garbage collection tasks used as metric labels.
+const (
+	gcTaskJobs          = "jobs"
+	gcTaskZipCache      = "zip_cache"
+	gcTaskDeleteMarkers = "delete_markers"
+)
+
+func (a *app) initGC() {
+	a.gc = maintenance.NewWorker(a.log, a.metrics)
+	a.gc.SetInterval(a.cfg.GetDuration(cfgGCInterval))
+}
+
+// startGC registers the garbage collection tasks and runs them until the
+// context is done. Delete markers are collected in the default network only.
+func (a *app) startGC(ctx context.Context, uploadRoutes *uploader.Uploader) {
+	a.gc.Register(gcTaskJobs, func(context.Context) (maintenance.Result, error) {
+		return maintenance.Result{Items: a.jobs.CollectGarbage()}, nil
+	})
+	a.gc.Register(gcTaskZipCache, func(context.Context) (maintenance.Result, error) {
+		var res maintenance.Result
+		if cache := a.settings.Downloader.ZipCache(); cache != nil {
+			res.Items, res.Bytes = cache.CollectExpired()
+		}
+		return res, nil
+	})
+	a.gc.RegisterEvery(gcTaskDeleteMarkers, a.settings.Uploader.DeleteMarkerCollectInterval, func(ctx context.Context) (maintenance.Result, error) {
+		var (
+			res maintenance.Result
+			err error
+		)
+		res.Items, res.Bytes, err = uploadRoutes.CollectDeleteMarkers(ctx)
+		return res, err
+	})
+
+	go a.gc.Run(ctx)
+}
@@ -0,0 +1,82 @@
+// Package hashring maps keys to peers with consistent hashing.
+package hashring
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// DefaultReplicas is a number of points of every peer on the ring.
+const DefaultReplicas = 128
+
+// Ring maps keys to peers, so every key is owned by a single peer and adding
+// or removing a peer moves only keys owned by it.
+type Ring struct {
+	peers  []string
+	points []point
+}
+
+type point struct {
+	hash uint64
+	peer string
+}
+
+// New creates the ring of unique peers, every peer is placed on the ring the
+// given number of times to spread keys evenly.
+func New(peers []string, replicas int) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultReplicas
+	}
+
+	r := &Ring{peers: make([]string, 0, len(peers))}
+	seen := make(map[string]struct{}, len(peers))
+	for _, peer := range peers {
+		if _, ok := seen[peer]; ok {
+			continue
+		}
+		seen[peer] = struct{}{}
+		r.peers = append(r.peers, peer)
+
+		for i := 0; i < replicas; i++ {
+			r.points = append(r.points, point{hash: hash(peer + "#" + strconv.Itoa(i)), peer: peer})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i].hash < r.points[j].hash })
+	return r
+}
+
+// Peers returns peers of the ring.
+func (r *Ring) Peers() []string {
+	return r.peers
+}
+
+// Has checks whether the peer is on the ring.
+func (r *Ring) Has(peer string) bool {
+	for _, p := range r.peers {
+		if p == peer {
+			return true
+		}
+	}
+	return false
+}
+
+// Owner returns the peer owning the key, empty string if the ring is empty.
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].peer
+}
+
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,40 @@
+package hashring
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	require.Empty(t, New(nil, 0).Owner("key"))
+
+	peers := []string{"http://gw1", "http://gw2", "http://gw3", "http://gw1"}
+	r := New(peers, 0)
+	require.Equal(t, peers[:3], r.Peers())
+	require.True(t, r.Has("http://gw2"))
+	require.False(t, r.Has("http://gw4"))
+
+	const keys = 3000
+	owned := make(map[string]int)
+	owners := make([]string, keys)
+	for i := range owners {
+		owners[i] = r.Owner(strconv.Itoa(i))
+		owned[owners[i]]++
+	}
+	for _, peer := range r.Peers() {
+		require.InDelta(t, keys/3, owned[peer], keys/10, peer)
+	}
+
+	// the same ring built on another gateway maps keys the same way
+	require.Equal(t, owners[0], New([]string{"http://gw3", "http://gw2", "http://gw1"}, 0).Owner("0"))
+
+	// only keys of the added peer are moved
+	r = New(append(peers, "http://gw4"), 0)
+	for i, owner := range owners {
+		if newOwner := r.Owner(strconv.Itoa(i)); newOwner != owner {
+			require.Equal(t, "http://gw4", newOwner)
+		}
+	}
+}
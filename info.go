@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/valyala/fasthttp"
+)
+
+const jsonHeader = "application/json; charset=UTF-8"
+
+type nodeInfo struct {
+	Address    string `json:"address"`
+	APIVersion string `json:"api_version,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// networkConfig is NeoFS network configuration affecting uploads.
+type networkConfig struct {
+	CurrentEpoch               uint64 `json:"current_epoch"`
+	MaxObjectSize              uint64 `json:"max_object_size"`
+	HomomorphicHashingDisabled bool   `json:"homomorphic_hashing_disabled"`
+}
+
+// gateCapabilities are download capabilities of the gateway independent of
+// NeoFS API version, clients check them to resume partial downloads.
+var gateCapabilities = map[string]bool{
+	"byte_ranges":    true,
+	"suffix_ranges":  true,
+	"if_range":       true,
+	"object_pinning": true,
+}
+
+type infoResponse struct {
+	Version      string                  `json:"version"`
+	APIVersion   string                  `json:"api_version,omitempty"`
+	Mixed        bool                    `json:"mixed_api_versions,omitempty"`
+	Nodes        []nodeInfo              `json:"nodes"`
+	Features     map[compat.Feature]bool `json:"features"`
+	Capabilities map[string]bool         `json:"capabilities"`
+	Network      *networkConfig          `json:"network,omitempty"`
+}
+
+func newInfoResponse(versions *compat.Versions, ni *netmap.NetworkInfo) *infoResponse {
+	resp := &infoResponse{
+		Version:      Version,
+		APIVersion:   versions.Negotiated(),
+		Mixed:        versions.Mixed(),
+		Nodes:        []nodeInfo{},
+		Features:     versions.Features(),
+		Capabilities: gateCapabilities,
+	}
+
+	for _, n := range versions.Nodes() {
+		node := nodeInfo{Address: n.Address}
+		if n.Err != nil {
+			node.Error = n.Err.Error()
+		} else {
+			node.APIVersion = n.Version.String()
+		}
+		resp.Nodes = append(resp.Nodes, node)
+	}
+
+	if ni != nil {
+		resp.Network = &networkConfig{
+			CurrentEpoch:               ni.CurrentEpoch(),
+			MaxObjectSize:              ni.MaxObjectSize(),
+			HomomorphicHashingDisabled: ni.HomomorphicHashingDisabled(),
+		}
+	}
+
+	return resp
+}
+
+// infoHandler handles requests for the gateway version, NeoFS API versions of
+// connected nodes, features available with them and the network configuration
+// polled by the epoch watcher.
+func infoHandler(versions *compat.Versions, epochs *epochWatcher) fasthttp.RequestHandler {
+	return func(c *fasthttp.RequestCtx) {
+		c.Response.SetStatusCode(fasthttp.StatusOK)
+		c.Response.Header.SetContentType(jsonHeader)
+		enc := json.NewEncoder(c)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(newInfoResponse(versions, epochs.networkInfo()))
+	}
+}
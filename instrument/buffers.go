@@ -0,0 +1,62 @@
+package instrument
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// BufferPool is a pool of fixed-size buffers counting allocations, so buffers
+// never returned to the pool are noticed.
+type BufferPool struct {
+	name string
+	size int
+	pool sync.Pool
+
+	allocated atomic.Uint64
+	gets      atomic.Uint64
+	inUse     atomic.Int64
+}
+
+// BufferPoolStats are statistics of the buffer pool.
+type BufferPoolStats struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	Allocated uint64 `json:"allocated"`
+	Gets      uint64 `json:"gets"`
+	InUse     int64  `json:"in_use"`
+}
+
+// NewBufferPool creates a pool of buffers of the given size.
+func NewBufferPool(name string, size int) *BufferPool {
+	p := &BufferPool{name: name, size: size}
+	p.pool.New = func() any {
+		p.allocated.Add(1)
+		buf := make([]byte, size)
+		return &buf
+	}
+	return p
+}
+
+// Get returns a buffer from the pool, it must be returned with Put.
+func (p *BufferPool) Get() *[]byte {
+	p.gets.Add(1)
+	p.inUse.Add(1)
+	return p.pool.Get().(*[]byte)
+}
+
+// Put returns the buffer to the pool.
+func (p *BufferPool) Put(buf *[]byte) {
+	p.inUse.Add(-1)
+	p.pool.Put(buf)
+}
+
+// Stats returns statistics of the pool.
+func (p *BufferPool) Stats() BufferPoolStats {
+	return BufferPoolStats{
+		Name:      p.name,
+		Size:      p.size,
+		Allocated: p.allocated.Load(),
+		Gets:      p.gets.Load(),
+		InUse:     p.inUse.Load(),
+	}
+}
@@ -0,0 +1,114 @@
+// Package instrument tracks runtime resources of the gateway to find leaks on
+// long-running instances.
+package instrument
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kinds of tracked NeoFS streams.
+const (
+	StreamGet   = "get"
+	StreamPut   = "put"
+	StreamRange = "range"
+	StreamZip   = "zip"
+)
+
+// Streams tracks NeoFS object streams opened by the gateway. Tracking is done
+// only if it's enabled, nil Streams tracks nothing.
+type Streams struct {
+	enabled atomic.Bool
+
+	mu     sync.Mutex
+	nextID uint64
+	open   map[uint64]openStream
+	opened map[string]uint64
+}
+
+type openStream struct {
+	kind  string
+	since time.Time
+}
+
+// StreamStats are statistics of streams of a single kind.
+type StreamStats struct {
+	Kind   string        `json:"kind"`
+	Open   int           `json:"open"`
+	Opened uint64        `json:"opened"`
+	Oldest time.Duration `json:"oldest"`
+}
+
+// NewStreams creates stream tracker.
+func NewStreams() *Streams {
+	return &Streams{
+		open:   make(map[uint64]openStream),
+		opened: make(map[string]uint64),
+	}
+}
+
+// SetEnabled enables or disables tracking, streams opened while tracking is
+// disabled aren't tracked.
+func (s *Streams) SetEnabled(enabled bool) {
+	s.enabled.Store(enabled)
+}
+
+// Enabled returns whether streams are tracked.
+func (s *Streams) Enabled() bool {
+	return s != nil && s.enabled.Load()
+}
+
+// Open registers the stream of the given kind and returns the function to be
+// called when the stream is closed, it can be called several times.
+func (s *Streams) Open(kind string) func() {
+	if !s.Enabled() {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.open[id] = openStream{kind: kind, since: time.Now()}
+	s.opened[kind]++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.open, id)
+			s.mu.Unlock()
+		})
+	}
+}
+
+// Stats returns statistics of the streams sorted by kind.
+func (s *Streams) Stats() []StreamStats {
+	if s == nil {
+		return nil
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	stats := make(map[string]*StreamStats, len(s.opened))
+	for kind, opened := range s.opened {
+		stats[kind] = &StreamStats{Kind: kind, Opened: opened}
+	}
+	for _, stream := range s.open {
+		st := stats[stream.kind]
+		st.Open++
+		if age := now.Sub(stream.since); age > st.Oldest {
+			st.Oldest = age
+		}
+	}
+	s.mu.Unlock()
+
+	res := make([]StreamStats, 0, len(stats))
+	for _, st := range stats {
+		res = append(res, *st)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Kind < res[j].Kind })
+	return res
+}
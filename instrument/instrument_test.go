@@ -0,0 +1,58 @@
+package instrument
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreams(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var s *Streams
+		s.Open(StreamGet)()
+		require.False(t, s.Enabled())
+		require.Empty(t, s.Stats())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := NewStreams()
+		s.Open(StreamGet)()
+		require.Empty(t, s.Stats())
+	})
+
+	s := NewStreams()
+	s.SetEnabled(true)
+
+	getDone := s.Open(StreamGet)
+	putDone := s.Open(StreamPut)
+	s.Open(StreamGet)()
+
+	stats := s.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, StreamGet, stats[0].Kind)
+	require.Equal(t, 1, stats[0].Open)
+	require.EqualValues(t, 2, stats[0].Opened)
+	require.Equal(t, StreamPut, stats[1].Kind)
+	require.Equal(t, 1, stats[1].Open)
+
+	getDone()
+	getDone()
+	putDone()
+	for _, st := range s.Stats() {
+		require.Zero(t, st.Open, st.Kind)
+		require.Zero(t, st.Oldest, st.Kind)
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	p := NewBufferPool("test", 16)
+
+	buf := p.Get()
+	require.Len(t, *buf, 16)
+	require.Equal(t, BufferPoolStats{Name: "test", Size: 16, Allocated: 1, Gets: 1, InUse: 1}, p.Stats())
+
+	p.Put(buf)
+	stats := p.Stats()
+	require.EqualValues(t, 1, stats.Gets)
+	require.Zero(t, stats.InUse)
+}
@@ -4,6 +4,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -65,6 +66,8 @@ func TestIntegration(t *testing.T) {
 
 		t.Run("simple put "+image, func(t *testing.T) { simplePut(ctx, t, clientPool, CID, signer) })
 		t.Run("put with duplicate keys "+image, func(t *testing.T) { putWithDuplicateKeys(t, CID) })
+		t.Run("put signed "+image, func(t *testing.T) { putSigned(ctx, t, clientPool, CID, signer, false) })
+		t.Run("put raw "+image, func(t *testing.T) { putSigned(ctx, t, clientPool, CID, signer, true) })
 		t.Run("simple get "+image, func(t *testing.T) { simpleGet(ctx, t, clientPool, ownerID, CID, signer) })
 		t.Run("get by attribute "+image, func(t *testing.T) { getByAttr(ctx, t, clientPool, ownerID, CID, signer) })
 		t.Run("get by attribute, not found "+image, func(t *testing.T) { getByAttrNotFound(t) })
@@ -161,6 +164,55 @@ func makePutRequestAndCheck(ctx context.Context, t *testing.T, p *pool.Pool, cnr
 	}
 }
 
+// putSigned uploads the object signed by another user with the signed header
+// or in binary format and checks it's stored unchanged.
+func putSigned(ctx context.Context, t *testing.T, p *pool.Pool, cnrID cid.ID, signer user.Signer, raw bool) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	clientSigner := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	clientID := clientSigner.UserID()
+
+	payload := []byte("signed by client")
+	var obj object.Object
+	obj.SetContainerID(cnrID)
+	obj.SetOwnerID(&clientID)
+	obj.SetPayload(payload)
+	obj.SetPayloadSize(uint64(len(payload)))
+	require.NoError(t, obj.SetVerificationFields(clientSigner))
+	expectedID, _ := obj.ID()
+
+	var request *http.Request
+	if raw {
+		data, err := obj.Marshal()
+		require.NoError(t, err)
+		request, err = http.NewRequest(http.MethodPost, testHost+"/raw/"+testContainerName, bytes.NewReader(data))
+		require.NoError(t, err)
+	} else {
+		hdr, err := obj.CutPayload().Marshal()
+		require.NoError(t, err)
+		request, err = http.NewRequest(http.MethodPost, testHost+"/upload/"+testContainerName, bytes.NewReader(payload))
+		require.NoError(t, err)
+		request.Header.Set("X-Neofs-Object-Header", base64.StdEncoding.EncodeToString(hdr))
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var addr putResponse
+	require.NoError(t, json.Unmarshal(body, &addr))
+	require.Equal(t, expectedID.EncodeToString(), addr.OID)
+
+	stored, err := p.ObjectHead(ctx, cnrID, expectedID, signer, client.PrmObjectHead{})
+	require.NoError(t, err)
+	require.Equal(t, clientID, *stored.OwnerID())
+	require.Equal(t, obj.Signature(), stored.Signature())
+	require.NoError(t, stored.CheckHeaderVerificationFields())
+}
+
 func putWithDuplicateKeys(t *testing.T, CID cid.ID) {
 	url := testHost + "/upload/" + CID.String()
 
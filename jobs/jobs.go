@@ -0,0 +1,344 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// State is a state of the job.
+type State string
+
+// Job states.
+const (
+	StatePending   State = "pending"
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+	StateCanceled  State = "canceled"
+)
+
+// DefaultRetention is a default time finished jobs are kept for status requests.
+const DefaultRetention = time.Hour
+
+// ErrShutdown is returned on attempt to start a job when the manager is stopped.
+var ErrShutdown = errors.New("jobs manager is shut down")
+
+// Func is a job body. It's executed in a separate goroutine, progress and
+// result should be reported using the Job given. The context is canceled
+// on the gateway shutdown.
+type Func func(ctx context.Context, j *Job) error
+
+// Job is a long-running background operation.
+type Job struct {
+	mu       sync.Mutex
+	id       string
+	kind     string
+	state    State
+	created  time.Time
+	started  time.Time
+	finished time.Time
+	total    int
+	done     int
+	failed   int
+	err      string
+	result   func() any
+}
+
+// Status is a snapshot of the job state.
+type Status struct {
+	ID       string     `json:"job_id"`
+	Kind     string     `json:"kind"`
+	State    State      `json:"state"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Total    int        `json:"total"`
+	Done     int        `json:"done"`
+	Failed   int        `json:"failed"`
+	Error    string     `json:"error,omitempty"`
+	Result   any        `json:"result,omitempty"`
+}
+
+// Proto implements response.ProtoValue. The result is packed if it has
+// protobuf encoding and omitted otherwise.
+func (s Status) Proto() proto.Message {
+	m := &pb.Job{
+		JobId:   s.ID,
+		Kind:    s.Kind,
+		State:   string(s.State),
+		Created: timestamppb.New(s.Created),
+		Total:   int64(s.Total),
+		Done:    int64(s.Done),
+		Failed:  int64(s.Failed),
+		Error:   s.Error,
+	}
+	if s.Started != nil {
+		m.Started = timestamppb.New(*s.Started)
+	}
+	if s.Finished != nil {
+		m.Finished = timestamppb.New(*s.Finished)
+	}
+	if res, ok := s.Result.(response.ProtoValue); ok {
+		// Only generated messages are packed, so it can't fail.
+		m.Result, _ = anypb.New(res.Proto())
+	}
+	return m
+}
+
+// ID returns job identifier.
+func (j *Job) ID() string {
+	return j.id
+}
+
+// SetTotal sets the total number of job steps.
+func (j *Job) SetTotal(n int) {
+	j.mu.Lock()
+	j.total = n
+	j.mu.Unlock()
+}
+
+// AddDone increments the number of successfully completed steps.
+func (j *Job) AddDone() {
+	j.mu.Lock()
+	j.done++
+	j.mu.Unlock()
+}
+
+// AddFailed increments the number of failed steps.
+func (j *Job) AddFailed() {
+	j.mu.Lock()
+	j.failed++
+	j.mu.Unlock()
+}
+
+// SetResultFunc sets a function returning the job result for status
+// responses. It's called on every status request, so it must be safe
+// for concurrent use with the job itself.
+func (j *Job) SetResultFunc(f func() any) {
+	j.mu.Lock()
+	j.result = f
+	j.mu.Unlock()
+}
+
+// Status returns current job status.
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	st := Status{
+		ID:      j.id,
+		Kind:    j.kind,
+		State:   j.state,
+		Created: j.created,
+		Total:   j.total,
+		Done:    j.done,
+		Failed:  j.failed,
+		Error:   j.err,
+	}
+	if !j.started.IsZero() {
+		started := j.started
+		st.Started = &started
+	}
+	if !j.finished.IsZero() {
+		finished := j.finished
+		st.Finished = &finished
+	}
+	result := j.result
+	j.mu.Unlock()
+
+	if result != nil {
+		st.Result = result()
+	}
+	return st
+}
+
+func (j *Job) finishedBefore(t time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return !j.finished.IsZero() && j.finished.Before(t)
+}
+
+func (j *Job) isFinished() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return !j.finished.IsZero()
+}
+
+// Manager runs jobs and keeps their statuses during the retention period.
+type Manager struct {
+	ctx       context.Context
+	log       *zap.Logger
+	retention atomic.Int64
+	wg        sync.WaitGroup
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+// NewManager creates a Manager. Jobs are canceled when ctx is done, finished
+// ones are removed by CollectGarbage.
+func NewManager(ctx context.Context, log *zap.Logger) *Manager {
+	m := &Manager{
+		ctx:  ctx,
+		log:  log,
+		jobs: make(map[string]*Job),
+	}
+	m.SetRetention(DefaultRetention)
+	return m
+}
+
+// SetRetention sets the time finished jobs are kept for status requests.
+func (m *Manager) SetRetention(d time.Duration) {
+	if d <= 0 {
+		d = DefaultRetention
+	}
+	m.retention.Store(int64(d))
+}
+
+// Start creates a new job of the given kind and runs it in the background.
+// Total is the number of job steps if it's known in advance (zero otherwise).
+func (m *Manager) Start(kind string, total int, f Func) (*Job, error) {
+	if m.ctx.Err() != nil {
+		return nil, ErrShutdown
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Job{
+		id:      id,
+		kind:    kind,
+		state:   StatePending,
+		created: time.Now(),
+		total:   total,
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.run(j, f)
+
+	return j, nil
+}
+
+func (m *Manager) run(j *Job, f Func) {
+	defer m.wg.Done()
+
+	log := m.log.With(zap.String("job", j.id), zap.String("kind", j.kind))
+	log.Info("job started")
+
+	j.mu.Lock()
+	j.state = StateRunning
+	j.started = time.Now()
+	j.mu.Unlock()
+
+	err := f(m.ctx, j)
+
+	j.mu.Lock()
+	switch {
+	case err == nil:
+		j.state = StateCompleted
+	case m.ctx.Err() != nil:
+		j.state = StateCanceled
+		j.err = err.Error()
+	default:
+		j.state = StateFailed
+		j.err = err.Error()
+	}
+	j.finished = time.Now()
+	state := j.state
+	j.mu.Unlock()
+
+	log.Info("job finished", zap.String("state", string(state)), zap.Error(err))
+}
+
+// Get returns the job by its ID.
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	return j, ok
+}
+
+// Wait blocks until all running jobs are finished.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Running returns the number of jobs that aren't finished yet.
+func (m *Manager) Running() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int
+	for _, j := range m.jobs {
+		if !j.isFinished() {
+			n++
+		}
+	}
+	return n
+}
+
+// CollectGarbage removes jobs finished before the retention period and
+// returns the number of removed ones. It's called periodically by the
+// gateway maintenance worker.
+func (m *Manager) CollectGarbage() int {
+	return m.removeOutdated(time.Now().Add(-time.Duration(m.retention.Load())))
+}
+
+func (m *Manager) removeOutdated(threshold time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int
+	for id, j := range m.jobs {
+		if j.finishedBefore(threshold) {
+			delete(m.jobs, id)
+			n++
+		}
+	}
+	return n
+}
+
+// StatusHandler handles job status requests.
+func (m *Manager) StatusHandler(c *fasthttp.RequestCtx) {
+	id, _ := c.UserValue("id").(string)
+
+	j, ok := m.Get(id)
+	if !ok {
+		response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		return
+	}
+
+	WriteStatus(c, fasthttp.StatusOK, j.Status())
+}
+
+// WriteStatus writes job status encoded in the format negotiated by Accept
+// header (JSON by default).
+func WriteStatus(c *fasthttp.RequestCtx, code int, st Status) {
+	response.Write(c, code, st)
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestManager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewManager(ctx, zap.NewNop())
+
+	release := make(chan struct{})
+	j, err := m.Start("test", 2, func(_ context.Context, j *Job) error {
+		<-release
+		j.AddDone()
+		j.AddFailed()
+		j.SetResultFunc(func() any { return "result" })
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, m.Running())
+
+	st := j.Status()
+	require.Equal(t, "test", st.Kind)
+	require.Equal(t, 2, st.Total)
+	require.Nil(t, st.Finished)
+
+	close(release)
+	m.Wait()
+
+	st = j.Status()
+	require.Equal(t, StateCompleted, st.State)
+	require.Equal(t, 1, st.Done)
+	require.Equal(t, 1, st.Failed)
+	require.Equal(t, "result", st.Result)
+	require.NotNil(t, st.Finished)
+	require.Zero(t, m.Running())
+
+	failed, err := m.Start("test", 0, func(context.Context, *Job) error {
+		return errors.New("some error")
+	})
+	require.NoError(t, err)
+	m.Wait()
+	require.Equal(t, StateFailed, failed.Status().State)
+	require.Equal(t, "some error", failed.Status().Error)
+
+	// outdated jobs are removed
+	require.Equal(t, 2, m.removeOutdated(time.Now().Add(time.Minute)))
+	_, ok := m.Get(j.ID())
+	require.False(t, ok)
+
+	cancel()
+	_, err = m.Start("test", 0, func(context.Context, *Job) error { return nil })
+	require.ErrorIs(t, err, ErrShutdown)
+}
+
+func TestManagerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewManager(ctx, zap.NewNop())
+
+	j, err := m.Start("test", 0, func(ctx context.Context, _ *Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+
+	cancel()
+	m.Wait()
+	require.Equal(t, StateCanceled, j.Status().State)
+}
+
+func TestStatusHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewManager(ctx, zap.NewNop())
+	j, err := m.Start("test", 0, func(context.Context, *Job) error { return nil })
+	require.NoError(t, err)
+	m.Wait()
+
+	var c fasthttp.RequestCtx
+	c.SetUserValue("id", j.ID())
+	m.StatusHandler(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+	var st Status
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &st))
+	require.Equal(t, j.ID(), st.ID)
+	require.Equal(t, StateCompleted, st.State)
+
+	c.Response.Reset()
+	c.SetUserValue("id", "unknown")
+	m.StatusHandler(&c)
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+}
+
+type testResult []string
+
+func (r testResult) Proto() proto.Message {
+	return &pb.ImportResult{Records: []*pb.ImportRecordStatus{{Url: r[0]}}}
+}
+
+func TestStatusProto(t *testing.T) {
+	finished := time.Unix(2, 0)
+	st := Status{
+		ID:       "id",
+		Kind:     "test",
+		State:    StateCompleted,
+		Created:  time.Unix(1, 0),
+		Finished: &finished,
+		Done:     1,
+		Result:   testResult{"http://example.com"},
+	}
+
+	m := st.Proto().(*pb.Job)
+	require.Equal(t, "id", m.GetJobId())
+	require.Equal(t, string(StateCompleted), m.GetState())
+	require.EqualValues(t, 1, m.GetCreated().GetSeconds())
+	require.Nil(t, m.GetStarted())
+	require.EqualValues(t, 2, m.GetFinished().GetSeconds())
+	require.EqualValues(t, 1, m.GetDone())
+
+	var res pb.ImportResult
+	require.NoError(t, m.GetResult().UnmarshalTo(&res))
+	require.Equal(t, "http://example.com", res.GetRecords()[0].GetUrl())
+
+	st.Result = []string{"no protobuf encoding"}
+	require.Nil(t, st.Proto().(*pb.Job).GetResult())
+}
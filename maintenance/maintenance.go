@@ -0,0 +1,155 @@
+// Package maintenance runs periodic tasks reclaiming resources left by the
+// gateway, e.g. expired cache entries, outdated job records or files removed
+// with delete markers.
+package maintenance
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultInterval is a default interval between task runs.
+const DefaultInterval = time.Minute
+
+// Result describes the resources reclaimed by the task run.
+type Result struct {
+	// Items is the number of removed entries (objects, files, records).
+	Items int
+	// Bytes is the size of the removed entries if it's known.
+	Bytes int64
+}
+
+// Task reclaims resources. Tasks are run one by one, so they must respect
+// the context to not block others.
+type Task func(ctx context.Context) (Result, error)
+
+// Metrics collects statistics of the reclaimed resources.
+type Metrics interface {
+	AddReclaimed(task string, items int, bytes int64)
+	IncMaintenanceFailures(task string)
+}
+
+type namedTask struct {
+	name  string
+	run   Task
+	every func() time.Duration
+	last  time.Time
+}
+
+// Worker runs registered tasks periodically.
+type Worker struct {
+	log      *zap.Logger
+	metrics  Metrics
+	interval atomic.Int64
+
+	mu    sync.Mutex
+	tasks []*namedTask
+}
+
+// NewWorker is a constructor for the Worker. Metrics can be nil.
+func NewWorker(log *zap.Logger, metrics Metrics) *Worker {
+	w := &Worker{log: log, metrics: metrics}
+	w.SetInterval(DefaultInterval)
+	return w
+}
+
+// SetInterval sets the interval between task runs, it's applied after the
+// current one.
+func (w *Worker) SetInterval(d time.Duration) {
+	if d <= 0 {
+		d = DefaultInterval
+	}
+	w.interval.Store(int64(d))
+}
+
+// Register adds the task run by the worker.
+func (w *Worker) Register(name string, t Task) {
+	w.RegisterEvery(name, nil, t)
+}
+
+// RegisterEvery adds the task run by the worker not more often than once per
+// the interval returned by every, so expensive tasks can be run less often
+// than the others. The interval is requested before each run, nil or
+// non-positive one means every worker run.
+func (w *Worker) RegisterEvery(name string, every func() time.Duration, t Task) {
+	w.mu.Lock()
+	w.tasks = append(w.tasks, &namedTask{name: name, run: t, every: every})
+	w.mu.Unlock()
+}
+
+// Run runs the tasks until the context is done. Up to a tenth of the interval
+// is randomly added to each pause, so gates started together don't run
+// their tasks at the same time.
+func (w *Worker) Run(ctx context.Context) {
+	timer := time.NewTimer(w.nextPause())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		w.RunOnce(ctx)
+		timer.Reset(w.nextPause())
+	}
+}
+
+func (w *Worker) nextPause() time.Duration {
+	d := w.interval.Load()
+	if jitter := d / 10; jitter > 0 {
+		d += rand.Int63n(jitter)
+	}
+	return time.Duration(d)
+}
+
+// RunOnce runs all the due tasks. Failed tasks are logged and don't stop
+// others. It must not be called concurrently.
+func (w *Worker) RunOnce(ctx context.Context) {
+	w.mu.Lock()
+	tasks := w.tasks
+	w.mu.Unlock()
+
+	for _, t := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		if !t.due(start) {
+			continue
+		}
+		res, err := t.run(ctx)
+		if w.metrics != nil && (res.Items > 0 || res.Bytes > 0) {
+			w.metrics.AddReclaimed(t.name, res.Items, res.Bytes)
+		}
+		if err != nil {
+			w.log.Warn("maintenance task failed", zap.String("task", t.name),
+				zap.Int("items", res.Items), zap.Int64("bytes", res.Bytes), zap.Error(err))
+			if w.metrics != nil {
+				w.metrics.IncMaintenanceFailures(t.name)
+			}
+			continue
+		}
+		if res.Items > 0 {
+			w.log.Info("maintenance task reclaimed resources", zap.String("task", t.name),
+				zap.Int("items", res.Items), zap.Int64("bytes", res.Bytes), zap.Duration("duration", time.Since(start)))
+		}
+	}
+}
+
+// due checks whether the task should be run now and remembers the run.
+func (t *namedTask) due(now time.Time) bool {
+	if t.every != nil && !t.last.IsZero() {
+		if every := t.every(); every > 0 && now.Sub(t.last) < every {
+			return false
+		}
+	}
+	t.last = now
+	return true
+}
@@ -0,0 +1,79 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testMetrics struct {
+	items    map[string]int
+	bytes    map[string]int64
+	failures map[string]int
+}
+
+func (m *testMetrics) AddReclaimed(task string, items int, bytes int64) {
+	m.items[task] += items
+	m.bytes[task] += bytes
+}
+
+func (m *testMetrics) IncMaintenanceFailures(task string) {
+	m.failures[task]++
+}
+
+func TestWorker(t *testing.T) {
+	metrics := &testMetrics{items: make(map[string]int), bytes: make(map[string]int64), failures: make(map[string]int)}
+	w := NewWorker(zap.NewNop(), metrics)
+
+	var runs int
+	w.Register("failing", func(context.Context) (Result, error) {
+		runs++
+		return Result{Items: 1}, errors.New("some error")
+	})
+	w.Register("cache", func(context.Context) (Result, error) {
+		runs++
+		return Result{Items: 2, Bytes: 100}, nil
+	})
+
+	w.RunOnce(context.Background())
+	require.Equal(t, 2, runs, "failed task doesn't stop others")
+	require.Equal(t, map[string]int{"failing": 1, "cache": 2}, metrics.items)
+	require.Equal(t, map[string]int64{"failing": 0, "cache": 100}, metrics.bytes)
+	require.Equal(t, map[string]int{"failing": 1}, metrics.failures)
+
+	var expensiveRuns int
+	every := time.Hour
+	w.RegisterEvery("expensive", func() time.Duration { return every }, func(context.Context) (Result, error) {
+		expensiveRuns++
+		return Result{}, nil
+	})
+	w.RunOnce(context.Background())
+	w.RunOnce(context.Background())
+	require.Equal(t, 1, expensiveRuns, "task is run once per its interval")
+	every = 0
+	w.RunOnce(context.Background())
+	require.Equal(t, 2, expensiveRuns)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan struct{}, 1)
+	w.Register("signal", func(context.Context) (Result, error) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+		return Result{}, nil
+	})
+	w.SetInterval(time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	<-ran
+	cancel()
+	<-done
+}
@@ -0,0 +1,187 @@
+// Package metacache caches container and network info requested by handlers,
+// so upload preflight checks, container existence checks and placement
+// reads don't make a NeoFS request every time. Concurrent requests of the
+// same uncached value are merged into a single one.
+package metacache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"golang.org/x/sync/singleflight"
+)
+
+// networkInfoKey is the singleflight key of network info requests, container
+// requests are keyed by container IDs which are never empty.
+const networkInfoKey = ""
+
+// Config contains cache parameters.
+type Config struct {
+	// ContainerTTL is the time containers are cached for, zero disables
+	// caching of containers.
+	ContainerTTL time.Duration
+	// NetworkInfoTTL is the time network info is cached for, zero disables
+	// caching of network info.
+	NetworkInfoTTL time.Duration
+	// Size is the maximum number of cached containers.
+	Size int
+}
+
+type containerEntry struct {
+	cnr     container.Container
+	expires time.Time
+}
+
+type networkInfoEntry struct {
+	ni      netmap.NetworkInfo
+	expires time.Time
+}
+
+// Backend caches results of ContainerGet and NetworkInfo of the wrapped
+// backend. Failures aren't cached.
+type Backend struct {
+	backend.Backend
+	ctx   context.Context
+	cfg   Config
+	group singleflight.Group
+
+	mu          sync.Mutex
+	containers  map[cid.ID]containerEntry
+	networkInfo *networkInfoEntry
+}
+
+// NewBackend wraps the backend caching container and network info. Cached
+// values are requested with the given context, so a request canceled by one
+// handler doesn't fail the others waiting for it.
+func NewBackend(ctx context.Context, b backend.Backend, cfg Config) *Backend {
+	return &Backend{
+		Backend:    b,
+		ctx:        ctx,
+		cfg:        cfg,
+		containers: make(map[cid.ID]containerEntry),
+	}
+}
+
+// ContainerGet implements backend.Backend.
+func (b *Backend) ContainerGet(ctx context.Context, id cid.ID, prm client.PrmContainerGet) (container.Container, error) {
+	if b.cfg.ContainerTTL <= 0 {
+		return b.Backend.ContainerGet(ctx, id, prm)
+	}
+
+	b.mu.Lock()
+	e, ok := b.containers[id]
+	b.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.cnr, nil
+	}
+
+	res, err := b.do(ctx, id.EncodeToString(), func() (any, error) {
+		cnr, err := b.Backend.ContainerGet(b.ctx, id, prm)
+		if err != nil {
+			return nil, err
+		}
+		b.putContainer(id, cnr)
+		return cnr, nil
+	})
+	if err != nil {
+		return container.Container{}, err
+	}
+	return res.(container.Container), nil
+}
+
+// CachedContainer returns the container if it's cached, the container isn't
+// requested otherwise.
+func (b *Backend) CachedContainer(id cid.ID) (container.Container, bool) {
+	b.mu.Lock()
+	e, ok := b.containers[id]
+	b.mu.Unlock()
+	if !ok || !time.Now().Before(e.expires) {
+		return container.Container{}, false
+	}
+	return e.cnr, true
+}
+
+// NetworkInfo implements backend.Backend.
+func (b *Backend) NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	if b.cfg.NetworkInfoTTL <= 0 {
+		return b.Backend.NetworkInfo(ctx, prm)
+	}
+
+	b.mu.Lock()
+	e := b.networkInfo
+	b.mu.Unlock()
+	if e != nil && time.Now().Before(e.expires) {
+		return e.ni, nil
+	}
+
+	res, err := b.do(ctx, networkInfoKey, func() (any, error) {
+		ni, err := b.Backend.NetworkInfo(b.ctx, prm)
+		if err != nil {
+			return nil, err
+		}
+		b.mu.Lock()
+		b.networkInfo = &networkInfoEntry{ni: ni, expires: time.Now().Add(b.cfg.NetworkInfoTTL)}
+		b.mu.Unlock()
+		return ni, nil
+	})
+	if err != nil {
+		return netmap.NetworkInfo{}, err
+	}
+	return res.(netmap.NetworkInfo), nil
+}
+
+// InvalidateNetworkInfo removes cached network info, e.g. on epoch change.
+func (b *Backend) InvalidateNetworkInfo() {
+	b.mu.Lock()
+	b.networkInfo = nil
+	b.mu.Unlock()
+}
+
+// do executes the function once for concurrent calls with the same key, the
+// caller stops waiting for the result when its context is done.
+func (b *Backend) do(ctx context.Context, key string, f func() (any, error)) (any, error) {
+	ch := b.group.DoChan(key, f)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		return res.Val, res.Err
+	}
+}
+
+func (b *Backend) putContainer(id cid.ID, cnr container.Container) {
+	if b.cfg.Size <= 0 {
+		return
+	}
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.containers) >= b.cfg.Size {
+		for k, e := range b.containers {
+			if !now.Before(e.expires) {
+				delete(b.containers, k)
+			}
+		}
+	}
+	if len(b.containers) >= b.cfg.Size {
+		// all entries are fresh, the one expiring first is evicted
+		var (
+			oldestKey cid.ID
+			oldest    time.Time
+		)
+		for k, e := range b.containers {
+			if oldest.IsZero() || e.expires.Before(oldest) {
+				oldestKey, oldest = k, e.expires
+			}
+		}
+		delete(b.containers, oldestKey)
+	}
+	b.containers[id] = containerEntry{cnr: cnr, expires: now.Add(b.cfg.ContainerTTL)}
+}
@@ -0,0 +1,149 @@
+package metacache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/stretchr/testify/require"
+)
+
+type countingBackend struct {
+	*backend.Memory
+	containers  atomic.Int32
+	networkInfo atomic.Int32
+	release     chan struct{}
+}
+
+func (b *countingBackend) ContainerGet(ctx context.Context, id cid.ID, prm client.PrmContainerGet) (container.Container, error) {
+	b.containers.Add(1)
+	if b.release != nil {
+		<-b.release
+	}
+	return b.Memory.ContainerGet(ctx, id, prm)
+}
+
+func (b *countingBackend) NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	b.networkInfo.Add(1)
+	return b.Memory.NetworkInfo(ctx, prm)
+}
+
+func TestContainerCache(t *testing.T) {
+	ctx := context.Background()
+	mem := &countingBackend{Memory: backend.NewMemory()}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	other := mem.AddContainer(containertest.Container(t))
+
+	b := NewBackend(ctx, mem, Config{ContainerTTL: time.Minute, Size: 1})
+	for i := 0; i < 3; i++ {
+		_, err := b.ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 1, mem.containers.Load())
+	_, ok := b.CachedContainer(cnrID)
+	require.True(t, ok)
+
+	// failures aren't cached
+	for i := 0; i < 2; i++ {
+		_, err := b.ContainerGet(ctx, cid.ID{1}, client.PrmContainerGet{})
+		require.ErrorIs(t, err, apistatus.ErrContainerNotFound)
+	}
+	require.EqualValues(t, 3, mem.containers.Load())
+	_, ok = b.CachedContainer(cid.ID{1})
+	require.False(t, ok)
+	require.EqualValues(t, 3, mem.containers.Load())
+
+	// the cache is limited
+	_, err := b.ContainerGet(ctx, other, client.PrmContainerGet{})
+	require.NoError(t, err)
+	require.Len(t, b.containers, 1)
+	_, err = b.ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, mem.containers.Load())
+
+	t.Run("ttl", func(t *testing.T) {
+		mem.containers.Store(0)
+		b := NewBackend(ctx, mem, Config{ContainerTTL: time.Millisecond, Size: 10})
+		_, err := b.ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+		_, err = b.ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, mem.containers.Load())
+	})
+}
+
+func TestContainerSingleflight(t *testing.T) {
+	ctx := context.Background()
+	mem := &countingBackend{Memory: backend.NewMemory(), release: make(chan struct{})}
+	cnrID := mem.AddContainer(containertest.Container(t))
+	b := NewBackend(ctx, mem, Config{ContainerTTL: time.Minute, Size: 10})
+
+	// the caller stops waiting once its context is done
+	canceled, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		_, err := b.ContainerGet(canceled, cnrID, client.PrmContainerGet{})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return mem.containers.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+			require.NoError(t, err)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(mem.release)
+	wg.Wait()
+	require.EqualValues(t, 1, mem.containers.Load(), "the request isn't repeated")
+}
+
+func TestNetworkInfoCache(t *testing.T) {
+	ctx := context.Background()
+	mem := &countingBackend{Memory: backend.NewMemory()}
+
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(10)
+	mem.SetNetworkInfo(ni)
+
+	b := NewBackend(ctx, mem, Config{NetworkInfoTTL: time.Minute})
+	res, err := b.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	require.NoError(t, err)
+	require.EqualValues(t, 10, res.CurrentEpoch())
+
+	ni.SetCurrentEpoch(11)
+	mem.SetNetworkInfo(ni)
+	res, err = b.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	require.NoError(t, err)
+	require.EqualValues(t, 10, res.CurrentEpoch())
+	require.EqualValues(t, 1, mem.networkInfo.Load())
+
+	b.InvalidateNetworkInfo()
+	res, err = b.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	require.NoError(t, err)
+	require.EqualValues(t, 11, res.CurrentEpoch())
+
+	// containers aren't cached with zero TTL
+	cnrID := mem.AddContainer(containertest.Container(t))
+	for i := 0; i < 2; i++ {
+		_, err = b.ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 2, mem.containers.Load())
+}
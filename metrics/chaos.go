@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-http-gw/chaos"
+	"go.uber.org/zap"
+)
+
+// NewChaosService creates a new service with the admin API managing faults
+// injected by the gateway.
+func NewChaosService(l *zap.Logger, cfg Config, injector *chaos.Injector) *Service {
+	return &Service{
+		Server: &http.Server{
+			Addr:    cfg.Address,
+			Handler: injector.Handler(),
+		},
+		enabled:     cfg.Enabled,
+		serviceType: "Chaos",
+		log:         l.With(zap.String("service", "Chaos")),
+	}
+}
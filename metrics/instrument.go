@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const instrumentSubsystem = "instrumentation"
+
+type instrumentMetricsCollector struct {
+	streams       *instrument.Streams
+	pools         []*instrument.BufferPool
+	goroutines    *prometheus.Desc
+	openStreams   *prometheus.Desc
+	openedStreams *prometheus.Desc
+	oldestStream  *prometheus.Desc
+	poolAllocated *prometheus.Desc
+	poolGets      *prometheus.Desc
+	poolInUse     *prometheus.Desc
+}
+
+func newInstrumentMetricsCollector(streams *instrument.Streams, pools []*instrument.BufferPool) *instrumentMetricsCollector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, instrumentSubsystem, name), help, labels, nil)
+	}
+	return &instrumentMetricsCollector{
+		streams:       streams,
+		pools:         pools,
+		goroutines:    desc("goroutines", "Number of goroutines"),
+		openStreams:   desc("open_streams", "Number of open NeoFS object streams", "kind"),
+		openedStreams: desc("opened_streams_total", "Total number of opened NeoFS object streams", "kind"),
+		oldestStream:  desc("oldest_stream_seconds", "Age of the oldest open NeoFS object stream", "kind"),
+		poolAllocated: desc("buffer_pool_allocated_total", "Total number of buffers allocated by the pool", "pool"),
+		poolGets:      desc("buffer_pool_gets_total", "Total number of buffers taken from the pool", "pool"),
+		poolInUse:     desc("buffer_pool_in_use", "Number of buffers taken from the pool and not returned", "pool"),
+	}
+}
+
+// Collect implements prometheus.Collector, metrics are collected only in
+// instrumentation mode.
+func (m *instrumentMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	if !m.streams.Enabled() {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(m.goroutines, prometheus.GaugeValue, float64(runtime.NumGoroutine()))
+	for _, s := range m.streams.Stats() {
+		ch <- prometheus.MustNewConstMetric(m.openStreams, prometheus.GaugeValue, float64(s.Open), s.Kind)
+		ch <- prometheus.MustNewConstMetric(m.openedStreams, prometheus.CounterValue, float64(s.Opened), s.Kind)
+		ch <- prometheus.MustNewConstMetric(m.oldestStream, prometheus.GaugeValue, s.Oldest.Seconds(), s.Kind)
+	}
+	for _, p := range m.pools {
+		s := p.Stats()
+		ch <- prometheus.MustNewConstMetric(m.poolAllocated, prometheus.CounterValue, float64(s.Allocated), s.Name)
+		ch <- prometheus.MustNewConstMetric(m.poolGets, prometheus.CounterValue, float64(s.Gets), s.Name)
+		ch <- prometheus.MustNewConstMetric(m.poolInUse, prometheus.GaugeValue, float64(s.InUse), s.Name)
+	}
+}
+
+func (m *instrumentMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- m.goroutines
+	descs <- m.openStreams
+	descs <- m.openedStreams
+	descs <- m.oldestStream
+	descs <- m.poolAllocated
+	descs <- m.poolGets
+	descs <- m.poolInUse
+}
+
+func (m *instrumentMetricsCollector) register() {
+	prometheus.MustRegister(m)
+}
+
+type instrumentState struct {
+	Goroutines  int                          `json:"goroutines"`
+	Streams     []instrument.StreamStats     `json:"streams"`
+	BufferPools []instrument.BufferPoolStats `json:"buffer_pools"`
+}
+
+// NewInstrumentationService creates a new service with admin endpoints
+// dumping goroutine stacks (/debug/goroutines) and tracked resources
+// (/debug/resources).
+func NewInstrumentationService(l *zap.Logger, cfg Config, streams *instrument.Streams, pools ...*instrument.BufferPool) *Service {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	handler.HandleFunc("/debug/resources", func(w http.ResponseWriter, _ *http.Request) {
+		state := instrumentState{
+			Goroutines:  runtime.NumGoroutine(),
+			Streams:     streams.Stats(),
+			BufferPools: make([]instrument.BufferPoolStats, 0, len(pools)),
+		}
+		for _, p := range pools {
+			state.BufferPools = append(state.BufferPools, p.Stats())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(state)
+	})
+
+	return &Service{
+		Server: &http.Server{
+			Addr:    cfg.Address,
+			Handler: handler,
+		},
+		enabled:     cfg.Enabled,
+		serviceType: "Instrumentation",
+		log:         l.With(zap.String("service", "Instrumentation")),
+	}
+}
@@ -2,8 +2,11 @@ package metrics
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/usage"
 	"github.com/nspcc-dev/neofs-sdk-go/stat"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -11,9 +14,14 @@ import (
 )
 
 const (
-	namespace      = "neofs_http_gw"
-	stateSubsystem = "state"
-	poolSubsystem  = "pool"
+	namespace         = "neofs_http_gw"
+	stateSubsystem    = "state"
+	poolSubsystem     = "pool"
+	requestSubsystem  = "request"
+	downloadSubsystem = "download"
+	uploadSubsystem   = "upload"
+	usageSubsystem    = "usage"
+	gcSubsystem       = "gc"
 
 	methodGetBalance       = "get_balance"
 	methodPutContainer     = "put_container"
@@ -35,6 +43,12 @@ const (
 type GateMetrics struct {
 	stateMetrics
 	poolMetricsCollector
+	requestMetrics
+	downloadMetrics
+	uploadMetrics
+	gcMetrics
+	usageMetricsCollector
+	instrumentMetricsCollector
 }
 
 type stateMetrics struct {
@@ -42,8 +56,34 @@ type stateMetrics struct {
 	gwVersion   *prometheus.GaugeVec
 }
 
+type requestMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	denials  *prometheus.CounterVec
+}
+
+type downloadMetrics struct {
+	sentBytes prometheus.Counter
+	stalls    *prometheus.CounterVec
+}
+
+type uploadMetrics struct {
+	aborts prometheus.Counter
+}
+
+type gcMetrics struct {
+	reclaimedItems *prometheus.CounterVec
+	reclaimedBytes *prometheus.CounterVec
+	failures       *prometheus.CounterVec
+}
+
+type usageMetricsCollector struct {
+	usage    *usage.Tracker
+	requests *prometheus.Desc
+	bytes    *prometheus.Desc
+}
+
 type poolMetricsCollector struct {
-	pool                *pool.Pool
 	statistic           *stat.PoolStat
 	overallErrors       prometheus.Gauge
 	overallNodeErrors   *prometheus.GaugeVec
@@ -52,23 +92,55 @@ type poolMetricsCollector struct {
 	requestDuration     *prometheus.GaugeVec
 }
 
-// NewGateMetrics creates new metrics for http gate.
-func NewGateMetrics(p *pool.Pool, statistic *stat.PoolStat) *GateMetrics {
+// NewGateMetrics creates new metrics for http gate. Tracker can be nil if
+// accounting is disabled. Streams and buffer pools are reported in
+// instrumentation mode only.
+func NewGateMetrics(statistic *stat.PoolStat, tracker *usage.Tracker, streams *instrument.Streams, pools ...*instrument.BufferPool) *GateMetrics {
 	stateMetric := newStateMetrics()
 	stateMetric.register()
 
-	poolMetric := newPoolMetricsCollector(p, statistic)
+	poolMetric := newPoolMetricsCollector(statistic)
 	poolMetric.register()
 
+	requestMetric := newRequestMetrics()
+	requestMetric.register()
+
+	downloadMetric := newDownloadMetrics()
+	downloadMetric.register()
+
+	uploadMetric := newUploadMetrics()
+	uploadMetric.register()
+
+	gcMetric := newGCMetrics()
+	gcMetric.register()
+
+	usageMetric := newUsageMetricsCollector(tracker)
+	usageMetric.register()
+
+	instrumentMetric := newInstrumentMetricsCollector(streams, pools)
+	instrumentMetric.register()
+
 	return &GateMetrics{
-		stateMetrics:         *stateMetric,
-		poolMetricsCollector: *poolMetric,
+		stateMetrics:               *stateMetric,
+		poolMetricsCollector:       *poolMetric,
+		requestMetrics:             *requestMetric,
+		downloadMetrics:            *downloadMetric,
+		uploadMetrics:              *uploadMetric,
+		gcMetrics:                  *gcMetric,
+		usageMetricsCollector:      *usageMetric,
+		instrumentMetricsCollector: *instrumentMetric,
 	}
 }
 
 func (g *GateMetrics) Unregister() {
 	g.stateMetrics.unregister()
 	prometheus.Unregister(&g.poolMetricsCollector)
+	g.requestMetrics.unregister()
+	g.downloadMetrics.unregister()
+	g.uploadMetrics.unregister()
+	g.gcMetrics.unregister()
+	prometheus.Unregister(&g.usageMetricsCollector)
+	prometheus.Unregister(&g.instrumentMetricsCollector)
 }
 
 func newStateMetrics() *stateMetrics {
@@ -104,7 +176,222 @@ func (m stateMetrics) SetHealth(s int32) {
 	m.healthCheck.Set(float64(s))
 }
 
-func newPoolMetricsCollector(p *pool.Pool, statistic *stat.PoolStat) *poolMetricsCollector {
+func newRequestMetrics() *requestMetrics {
+	return &requestMetrics{
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: requestSubsystem,
+				Name:      "total",
+				Help:      "Total number of processed requests",
+			},
+			[]string{"route", "container", "status"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: requestSubsystem,
+				Name:      "duration_seconds",
+				Help:      "Request processing duration (in seconds)",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"route", "container"},
+		),
+		denials: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: requestSubsystem,
+				Name:      "access_denied_total",
+				Help:      "Total number of requests denied by storage nodes",
+			},
+			[]string{"route", "container"},
+		),
+	}
+}
+
+func (m requestMetrics) register() {
+	prometheus.MustRegister(m.requests)
+	prometheus.MustRegister(m.duration)
+	prometheus.MustRegister(m.denials)
+}
+
+func (m requestMetrics) unregister() {
+	prometheus.Unregister(m.requests)
+	prometheus.Unregister(m.duration)
+	prometheus.Unregister(m.denials)
+}
+
+// ObserveRequest records the processed request. The container label must be
+// already normalized by the caller to keep metric cardinality bounded.
+func (m requestMetrics) ObserveRequest(route, container string, status int, dur time.Duration) {
+	m.requests.WithLabelValues(route, container, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(route, container).Observe(dur.Seconds())
+}
+
+// IncAccessDenied records the request denied by storage nodes. The container
+// label must be already normalized by the caller.
+func (m requestMetrics) IncAccessDenied(route, container string) {
+	m.denials.WithLabelValues(route, container).Inc()
+}
+
+func newDownloadMetrics() *downloadMetrics {
+	return &downloadMetrics{
+		sentBytes: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: downloadSubsystem,
+				Name:      "sent_bytes_total",
+				Help:      "Total number of object payload bytes sent to clients",
+			},
+		),
+		stalls: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: downloadSubsystem,
+				Name:      "stalls_total",
+				Help:      "Total number of downloads aborted because of no progress",
+			},
+			[]string{"reason"},
+		),
+	}
+}
+
+func (m downloadMetrics) register() {
+	prometheus.MustRegister(m.sentBytes)
+	prometheus.MustRegister(m.stalls)
+}
+
+func (m downloadMetrics) unregister() {
+	prometheus.Unregister(m.sentBytes)
+	prometheus.Unregister(m.stalls)
+}
+
+// AddDownloadedBytes records the number of payload bytes sent to the client.
+func (m downloadMetrics) AddDownloadedBytes(n int) {
+	m.sentBytes.Add(float64(n))
+}
+
+// IncDownloadStalls records the aborted stalled download. Reason is either
+// "client" (client doesn't read data) or "storage" (storage node doesn't send it).
+func (m downloadMetrics) IncDownloadStalls(reason string) {
+	m.stalls.WithLabelValues(reason).Inc()
+}
+
+func newUploadMetrics() *uploadMetrics {
+	return &uploadMetrics{
+		aborts: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: uploadSubsystem,
+				Name:      "aborts_total",
+				Help:      "Total number of uploads aborted because the client closed the connection",
+			},
+		),
+	}
+}
+
+func (m uploadMetrics) register() {
+	prometheus.MustRegister(m.aborts)
+}
+
+func (m uploadMetrics) unregister() {
+	prometheus.Unregister(m.aborts)
+}
+
+// IncUploadAborts records the upload aborted by the client before the request
+// body is received.
+func (m uploadMetrics) IncUploadAborts() {
+	m.aborts.Inc()
+}
+
+func newGCMetrics() *gcMetrics {
+	return &gcMetrics{
+		reclaimedItems: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: gcSubsystem,
+				Name:      "reclaimed_items_total",
+				Help:      "Total number of entries removed by maintenance tasks",
+			},
+			[]string{"task"},
+		),
+		reclaimedBytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: gcSubsystem,
+				Name:      "reclaimed_bytes_total",
+				Help:      "Total size of entries removed by maintenance tasks",
+			},
+			[]string{"task"},
+		),
+		failures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: gcSubsystem,
+				Name:      "failures_total",
+				Help:      "Total number of failed maintenance task runs",
+			},
+			[]string{"task"},
+		),
+	}
+}
+
+func (m gcMetrics) register() {
+	prometheus.MustRegister(m.reclaimedItems)
+	prometheus.MustRegister(m.reclaimedBytes)
+	prometheus.MustRegister(m.failures)
+}
+
+func (m gcMetrics) unregister() {
+	prometheus.Unregister(m.reclaimedItems)
+	prometheus.Unregister(m.reclaimedBytes)
+	prometheus.Unregister(m.failures)
+}
+
+// AddReclaimed records the entries removed by the maintenance task.
+func (m gcMetrics) AddReclaimed(task string, items int, bytes int64) {
+	m.reclaimedItems.WithLabelValues(task).Add(float64(items))
+	m.reclaimedBytes.WithLabelValues(task).Add(float64(bytes))
+}
+
+// IncMaintenanceFailures records the failed maintenance task run.
+func (m gcMetrics) IncMaintenanceFailures(task string) {
+	m.failures.WithLabelValues(task).Inc()
+}
+
+func newUsageMetricsCollector(tracker *usage.Tracker) *usageMetricsCollector {
+	return &usageMetricsCollector{
+		usage: tracker,
+		requests: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, usageSubsystem, "requests_total"),
+			"Total number of download requests per bearer token issuer",
+			[]string{"issuer"}, nil,
+		),
+		bytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, usageSubsystem, "sent_bytes_total"),
+			"Total number of bytes sent per bearer token issuer",
+			[]string{"issuer"}, nil,
+		),
+	}
+}
+
+func (m *usageMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, u := range m.usage.Snapshot() {
+		ch <- prometheus.MustNewConstMetric(m.requests, prometheus.CounterValue, float64(u.Requests), u.Issuer)
+		ch <- prometheus.MustNewConstMetric(m.bytes, prometheus.CounterValue, float64(u.Bytes), u.Issuer)
+	}
+}
+
+func (m *usageMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- m.requests
+	descs <- m.bytes
+}
+
+func (m *usageMetricsCollector) register() {
+	prometheus.MustRegister(m)
+}
+
+func newPoolMetricsCollector(statistic *stat.PoolStat) *poolMetricsCollector {
 	overallErrors := prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -164,7 +451,6 @@ func newPoolMetricsCollector(p *pool.Pool, statistic *stat.PoolStat) *poolMetric
 	)
 
 	return &poolMetricsCollector{
-		pool:                p,
 		statistic:           statistic,
 		overallErrors:       overallErrors,
 		overallNodeErrors:   overallNodeErrors,
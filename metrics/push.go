@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.uber.org/zap"
+)
+
+// Push modes.
+const (
+	PushModePushgateway = "pushgateway"
+	PushModeRemoteWrite = "remote_write"
+)
+
+// Default push parameters.
+const (
+	DefaultPushInterval = 15 * time.Second
+	DefaultPushTimeout  = 10 * time.Second
+	DefaultPushJob      = "neofs-http-gw"
+)
+
+// PushConfig is a configuration of periodic metrics push for environments
+// where the gate can't be scraped.
+type PushConfig struct {
+	Enabled bool
+	// Mode is either PushModePushgateway or PushModeRemoteWrite.
+	Mode string
+	// URL is the Pushgateway base URL or the remote write endpoint.
+	URL      string
+	Job      string
+	Interval time.Duration
+	Timeout  time.Duration
+	// Labels are added to all pushed metrics, they're grouping labels in
+	// Pushgateway.
+	Labels map[string]string
+	// Username and Password are used for basic authentication, BearerToken
+	// is sent in Authorization header otherwise if it's set.
+	Username    string
+	Password    string
+	BearerToken string
+}
+
+// Pusher periodically pushes metrics of the gatherer.
+type Pusher struct {
+	cfg      PushConfig
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	log      *zap.Logger
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewPusher creates a Pusher of the metrics registered in the default
+// registry, it's nil if the push is disabled.
+func NewPusher(l *zap.Logger, cfg PushConfig) (*Pusher, error) {
+	return newPusher(l, cfg, prometheus.DefaultGatherer)
+}
+
+func newPusher(l *zap.Logger, cfg PushConfig, g prometheus.Gatherer) (*Pusher, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.URL == "" {
+		return nil, errors.New("empty push URL")
+	}
+	switch cfg.Mode {
+	case PushModePushgateway, PushModeRemoteWrite:
+	default:
+		return nil, fmt.Errorf("unknown push mode '%s'", cfg.Mode)
+	}
+	if cfg.Job == "" {
+		cfg.Job = DefaultPushJob
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPushInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultPushTimeout
+	}
+
+	return &Pusher{
+		cfg:      cfg,
+		gatherer: g,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		log:      l.With(zap.String("service", "Push"), zap.String("mode", cfg.Mode)),
+	}, nil
+}
+
+// Start pushes metrics periodically until ShutDown is called.
+func (p *Pusher) Start() {
+	if p == nil {
+		return
+	}
+	var ctx context.Context
+	ctx, p.cancel = context.WithCancel(context.Background())
+	p.done = make(chan struct{})
+
+	p.log.Info("metrics push is running", zap.String("url", p.cfg.URL), zap.Duration("interval", p.cfg.Interval))
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := p.Push(ctx); err != nil && ctx.Err() == nil {
+				p.log.Warn("failed to push metrics", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// ShutDown stops the push, the last metrics are pushed before that.
+func (p *Pusher) ShutDown(ctx context.Context) {
+	if p == nil || p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+
+	if err := p.Push(ctx); err != nil {
+		p.log.Warn("failed to push metrics", zap.Error(err))
+	}
+	p.log.Info("metrics push is stopped")
+}
+
+// Push sends the current metrics.
+func (p *Pusher) Push(ctx context.Context) error {
+	if p.cfg.Mode == PushModeRemoteWrite {
+		return p.remoteWrite(ctx)
+	}
+
+	pusher := push.New(p.cfg.URL, p.cfg.Job).Gatherer(p.gatherer).Client(p)
+	for k, v := range p.cfg.Labels {
+		pusher = pusher.Grouping(k, v)
+	}
+	if p.cfg.Username != "" {
+		pusher = pusher.BasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+	return pusher.PushContext(ctx)
+}
+
+// Do sends the request with the configured bearer token, so Pusher is used
+// as an HTTP client of Pushgateway pushes.
+func (p *Pusher) Do(req *http.Request) (*http.Response, error) {
+	if p.cfg.Username == "" && p.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.BearerToken)
+	}
+	return p.client.Do(req)
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPusher(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_requests_total", Help: "test"})
+	hist := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds", Help: "test", Buckets: []float64{1}})
+	reg.MustRegister(counter, hist)
+	counter.Add(3)
+	hist.Observe(0.5)
+
+	type request struct {
+		method, path, auth, encoding string
+		body                         []byte
+	}
+	var last request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		last = request{r.Method, r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Encoding"), body}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("disabled", func(t *testing.T) {
+		p, err := newPusher(zap.NewNop(), PushConfig{URL: srv.URL}, reg)
+		require.NoError(t, err)
+		require.Nil(t, p)
+		p.Start()
+		p.ShutDown(context.Background())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newPusher(zap.NewNop(), PushConfig{Enabled: true, Mode: PushModePushgateway}, reg)
+		require.Error(t, err)
+		_, err = newPusher(zap.NewNop(), PushConfig{Enabled: true, Mode: "unknown", URL: srv.URL}, reg)
+		require.Error(t, err)
+	})
+
+	t.Run("pushgateway", func(t *testing.T) {
+		p, err := newPusher(zap.NewNop(), PushConfig{
+			Enabled:     true,
+			Mode:        PushModePushgateway,
+			URL:         srv.URL,
+			Labels:      map[string]string{"instance": "gw1"},
+			BearerToken: "token",
+		}, reg)
+		require.NoError(t, err)
+
+		require.NoError(t, p.Push(context.Background()))
+		require.Equal(t, http.MethodPut, last.method)
+		require.Equal(t, "/metrics/job/"+DefaultPushJob+"/instance/gw1", last.path)
+		require.Equal(t, "Bearer token", last.auth)
+		require.Contains(t, string(last.body), "test_requests_total")
+	})
+
+	t.Run("remote write", func(t *testing.T) {
+		p, err := newPusher(zap.NewNop(), PushConfig{
+			Enabled:  true,
+			Mode:     PushModeRemoteWrite,
+			URL:      srv.URL + "/api/v1/write",
+			Labels:   map[string]string{"instance": "gw1"},
+			Username: "user",
+			Password: "pass",
+		}, reg)
+		require.NoError(t, err)
+
+		require.NoError(t, p.Push(context.Background()))
+		require.Equal(t, http.MethodPost, last.method)
+		require.Equal(t, "/api/v1/write", last.path)
+		require.Equal(t, "snappy", last.encoding)
+		require.Equal(t, "Basic dXNlcjpwYXNz", last.auth)
+
+		body, err := s2.Decode(nil, last.body)
+		require.NoError(t, err)
+		for _, s := range []string{"test_requests_total", "test_duration_seconds_bucket", "+Inf",
+			"test_duration_seconds_count", "instance", "gw1", DefaultPushJob} {
+			require.Contains(t, string(body), s)
+		}
+	})
+}
+
+func TestToSeries(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"}, []string{"job", "node"})
+	reg.MustRegister(gauge)
+	gauge.WithLabelValues("own", "n1").Set(2)
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+
+	ss := toSeries(mfs, []label{{"job", "gw"}, {"instance", "gw1"}})
+	require.Equal(t, []series{{
+		labels: []label{{"__name__", "test_gauge"}, {"instance", "gw1"}, {"job", "own"}, {"node", "n1"}},
+		value:  2,
+	}}, ss)
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type (
+	label struct {
+		name, value string
+	}
+
+	// series is a time series of Prometheus remote write protocol with a
+	// single sample.
+	series struct {
+		labels []label
+		value  float64
+	}
+)
+
+// remoteWrite sends the current metrics using Prometheus remote write
+// protocol (snappy compressed protobuf WriteRequest).
+func (p *Pusher) remoteWrite(ctx context.Context) error {
+	mfs, err := p.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+
+	extra := make([]label, 0, len(p.cfg.Labels)+1)
+	extra = append(extra, label{"job", p.cfg.Job})
+	for k, v := range p.cfg.Labels {
+		extra = append(extra, label{k, v})
+	}
+	body := s2.EncodeSnappy(nil, encodeWriteRequest(toSeries(mfs, extra), time.Now().UnixMilli()))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if p.cfg.Username != "" {
+		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+
+	resp, err := p.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d while pushing to %s: %s", resp.StatusCode, p.cfg.URL, msg)
+	}
+	return nil
+}
+
+// toSeries converts metric families to time series the way Prometheus does
+// on scraping: summaries and histograms are split into quantile (bucket),
+// sum and count series. Extra labels are added to all series unless metrics
+// have the same labels.
+func toSeries(mfs []*dto.MetricFamily, extra []label) []series {
+	var res []series
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			add := func(suffix string, value float64, ls ...label) {
+				labels := make([]label, 0, len(m.GetLabel())+len(extra)+len(ls)+1)
+				labels = append(labels, label{"__name__", name + suffix})
+				for _, l := range m.GetLabel() {
+					labels = append(labels, label{l.GetName(), l.GetValue()})
+				}
+				labels = append(labels, ls...)
+				labels = withExtraLabels(labels, extra)
+				res = append(res, series{labels: labels, value: value})
+			}
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", m.GetGauge().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add("", q.GetValue(), label{"quantile", formatFloat(q.GetQuantile())})
+				}
+				add("_sum", s.GetSampleSum())
+				add("_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				var infSeen bool
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						infSeen = true
+					}
+					add("_bucket", float64(b.GetCumulativeCount()), label{"le", formatFloat(b.GetUpperBound())})
+				}
+				if !infSeen {
+					add("_bucket", float64(h.GetSampleCount()), label{"le", "+Inf"})
+				}
+				add("_sum", h.GetSampleSum())
+				add("_count", float64(h.GetSampleCount()))
+			default:
+				add("", m.GetUntyped().GetValue())
+			}
+		}
+	}
+	return res
+}
+
+// withExtraLabels adds extra labels missing in the list and sorts it by name
+// as remote write protocol requires.
+func withExtraLabels(labels, extra []label) []label {
+	for _, e := range extra {
+		var found bool
+		for _, l := range labels {
+			if l.name == e.name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			labels = append(labels, e)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return labels
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest encodes prometheus.WriteRequest message:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(ss []series, timestamp int64) []byte {
+	var res, ts, buf []byte
+	for _, s := range ss {
+		ts = ts[:0]
+		for _, l := range s.labels {
+			buf = buf[:0]
+			buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+			buf = protowire.AppendString(buf, l.name)
+			buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+			buf = protowire.AppendString(buf, l.value)
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, buf)
+		}
+
+		buf = buf[:0]
+		buf = protowire.AppendTag(buf, 1, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, math.Float64bits(s.value))
+		buf = protowire.AppendTag(buf, 2, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, uint64(timestamp))
+
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, buf)
+
+		res = protowire.AppendTag(res, 1, protowire.BytesType)
+		res = protowire.AppendBytes(res, ts)
+	}
+	return res
+}
@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// NewUsageService creates a new service with the admin API exposing download
+// usage per bearer token issuer and NeoFS balances.
+func NewUsageService(l *zap.Logger, cfg Config, handler http.Handler) *Service {
+	return &Service{
+		Server: &http.Server{
+			Addr:    cfg.Address,
+			Handler: handler,
+		},
+		enabled:     cfg.Enabled,
+		serviceType: "Usage",
+		log:         l.With(zap.String("service", "Usage")),
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fasthttp/router"
+	"github.com/nspcc-dev/neofs-http-gw/attrcache"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/pathfilter"
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// network is a NeoFS network served by the gate. Additional networks are
+// served under the path prefix with the network name and on their virtual
+// hosts with separate pools and credentials.
+type network struct {
+	name       string
+	hosts      []string
+	pool       *pool.Pool
+	owner      *user.ID
+	signer     user.Signer
+	connected  *atomic.Bool
+	versions   *compat.Versions
+	epochs     *epochWatcher
+	resolver   *resolver.Container
+	uploader   *uploader.Uploader
+	downloader *downloader.Downloader
+}
+
+func (a *app) initNetworks(ctx context.Context) {
+	a.networkHosts = make(map[string]*network)
+	names := make(map[string]struct{})
+
+	for i := 0; ; i++ {
+		key := cfgNetworks + "." + strconv.Itoa(i)
+		name := a.cfg.GetString(key + ".name")
+		if name == "" {
+			break
+		}
+		if !validNetworkName(name) {
+			a.log.Fatal("invalid network name", zap.String("network", name))
+		}
+		if _, ok := names[name]; ok {
+			a.log.Fatal("duplicated network name", zap.String("network", name))
+		}
+		names[name] = struct{}{}
+
+		n := a.initNetwork(ctx, key, name)
+		for _, host := range n.hosts {
+			if _, ok := a.networkHosts[host]; ok {
+				a.log.Fatal("host is used by several networks", zap.String("host", host))
+			}
+			a.networkHosts[host] = n
+		}
+		a.networks = append(a.networks, n)
+	}
+}
+
+func (a *app) initNetwork(ctx context.Context, key, name string) *network {
+	log := a.log.With(zap.String("network", name))
+
+	pk, err := getNeoFSKey(a, key+".")
+	if err != nil {
+		log.Fatal("failed to get neofs credentials", zap.Error(err))
+	}
+
+	signer := user.NewAutoIDSignerRFC6979(*pk)
+	owner := signer.UserID()
+
+	n := &network{
+		name:      name,
+		owner:     &owner,
+		signer:    signer,
+		connected: new(atomic.Bool),
+	}
+	for _, host := range a.cfg.GetStringSlice(key + ".hosts") {
+		n.hosts = append(n.hosts, strings.ToLower(host))
+	}
+
+	var addresses []string
+	n.pool, addresses = a.newPool(log, signer, key+"."+cfgPeers)
+	a.dialPool(ctx, log, n.pool, n.connected, nil)
+	n.versions = a.detectVersions(ctx, log, addresses)
+	n.epochs = newEpochWatcher(log, n.pool, n.connected)
+
+	n.resolver, err = resolver.NewContainer(ctx, a.networkResolverConfig(key))
+	if err != nil {
+		log.Fatal("failed to create resolver", zap.Error(err))
+	}
+
+	log.Info("network added", zap.Strings("hosts", n.hosts))
+	return n
+}
+
+// networkResolverConfig returns resolver configuration of the network, it
+// differs from the default one by NNS endpoint and aliases.
+func (a *app) networkResolverConfig(key string) resolver.Config {
+	cfg := a.resolverConfig()
+	cfg.RPCEndpoint = a.cfg.GetString(key + "." + cfgRPCEndpoint)
+	cfg.Aliases = a.cfg.GetStringMapString(key + "." + cfgAliases)
+	return cfg
+}
+
+func (a *app) updateNetworkResolvers(ctx context.Context) {
+	for i, n := range a.networks {
+		key := cfgNetworks + "." + strconv.Itoa(i)
+		if err := n.resolver.UpdateResolvers(ctx, a.networkResolverConfig(key)); err != nil {
+			a.log.Warn("failed to update resolvers", zap.String("network", n.name), zap.Error(err))
+		}
+	}
+}
+
+// initHandlers creates request handlers of the network using the backend of
+// the network pool, common parameters are replaced with the network ones.
+func (n *network) initHandlers(ctx context.Context, params *utils.AppParams, settings *appSettings, b backend.Backend,
+	resolutions *attrcache.Cache, pathFilters *pathfilter.Filters, extCache *extcache.Invalidator) {
+	params.Logger = params.Logger.With(zap.String("network", n.name))
+	params.Backend = b
+	params.Resolutions = resolutions
+	params.PathFilters = pathFilters
+	params.ExtCache = extCache
+	params.Owner = n.owner
+	params.Resolver = n.resolver
+	params.Versions = n.versions
+
+	n.uploader = uploader.New(ctx, params, settings.Uploader, n.signer)
+	n.downloader = downloader.New(ctx, params, settings.Downloader, n.signer)
+}
+
+// networkOf returns the additional network the request is sent to by its
+// virtual host or path prefix, request URI is returned without the prefix.
+// Nil is returned for requests to the default network.
+func (a *app) networkOf(host, uri []byte) (*network, []byte) {
+	if n, ok := a.networkHosts[hostname(host)]; ok {
+		return n, uri
+	}
+
+	for _, n := range a.networks {
+		if len(uri) <= len(n.name) || uri[0] != '/' || !bytes.HasPrefix(uri[1:], []byte(n.name)) {
+			continue
+		}
+		rest := uri[1+len(n.name):]
+		if len(rest) == 0 || rest[0] == '/' || rest[0] == '?' {
+			return n, rest
+		}
+	}
+
+	return nil, uri
+}
+
+// hostname returns lower-cased host without port.
+func hostname(host []byte) string {
+	h := string(host)
+	if name, _, err := net.SplitHostPort(h); err == nil {
+		h = name
+	}
+	return strings.ToLower(strings.Trim(h, "[]"))
+}
+
+// updateAllowedHosts sets the allowlist of request hosts, virtual hosts of
+// the networks are always allowed. Hosts aren't checked if the allowlist
+// isn't configured.
+func (a *app) updateAllowedHosts() error {
+	patterns := a.cfg.GetStringSlice(cfgWebAllowedHosts)
+	if len(patterns) == 0 {
+		a.allowedHosts.Store(nil)
+		return nil
+	}
+	for host := range a.networkHosts {
+		patterns = append(patterns, host)
+	}
+	l, err := utils.NewHostAllowlist(patterns)
+	if err != nil {
+		return err
+	}
+	a.allowedHosts.Store(l)
+	return nil
+}
+
+// checkHost wraps the handler rejecting requests to the hosts not allowed
+// with 421 Misdirected Request.
+func (a *app) checkHost(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if l := a.allowedHosts.Load(); l != nil && !l.Allowed(hostname(ctx.Host())) {
+			a.log.Debug("request to unknown host", zap.ByteString("host", ctx.Host()))
+			response.Error(ctx, "Misdirected Request", fasthttp.StatusMisdirectedRequest)
+			return
+		}
+		h(ctx)
+	}
+}
+
+// validNetworkName checks that the network name can be used as a path
+// segment without escaping.
+func validNetworkName(name string) bool {
+	return strings.IndexFunc(name, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '_' || r == '.')
+	}) < 0
+}
+
+// reservedNetworkName checks whether the network name is the first segment of
+// the routes registered in the router, so its path prefix conflicts with them.
+func reservedNetworkName(r *router.Router, name string) bool {
+	for _, paths := range r.List() {
+		for _, path := range paths {
+			first, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+			if first == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestHostname(t *testing.T) {
+	for host, expected := range map[string]string{
+		"":                    "",
+		"Example.com":         "example.com",
+		"example.com:8080":    "example.com",
+		"[::1]:8080":          "::1",
+		"[::1]":               "::1",
+		"127.0.0.1":           "127.0.0.1",
+		"testnet.example.com": "testnet.example.com",
+	} {
+		require.Equal(t, expected, hostname([]byte(host)), host)
+	}
+}
+
+func TestValidNetworkName(t *testing.T) {
+	require.True(t, validNetworkName("testnet"))
+	require.True(t, validNetworkName("private-1.net_2"))
+	require.False(t, validNetworkName("test/net"))
+	require.False(t, validNetworkName("test net"))
+	require.False(t, validNetworkName("тест"))
+}
+
+func TestNetworkOf(t *testing.T) {
+	testnet := &network{name: "testnet"}
+	a := &app{
+		networks:     []*network{testnet, {name: "test"}},
+		networkHosts: map[string]*network{"testnet.example.com": testnet},
+	}
+
+	for _, tc := range []struct {
+		host, uri string
+		network   *network
+		rest      string
+	}{
+		{host: "gate.example.com", uri: "/get/cid/oid", rest: "/get/cid/oid"},
+		{host: "gate.example.com", uri: "/testnet/get/cid/oid", network: testnet, rest: "/get/cid/oid"},
+		{host: "gate.example.com", uri: "/testnet?x=1", network: testnet, rest: "?x=1"},
+		{host: "gate.example.com", uri: "/testnetwork/get", rest: "/testnetwork/get"},
+		{host: "TestNet.example.com:8080", uri: "/get/cid/oid", network: testnet, rest: "/get/cid/oid"},
+	} {
+		n, rest := a.networkOf([]byte(tc.host), []byte(tc.uri))
+		require.Equal(t, tc.network, n, tc.uri)
+		require.Equal(t, tc.rest, string(rest), tc.uri)
+	}
+}
+
+func TestRouterNetworks(t *testing.T) {
+	newDownloader := func() *downloader.Downloader {
+		return downloader.New(context.Background(), &utils.AppParams{Logger: zap.NewNop()}, &downloader.Settings{}, nil)
+	}
+
+	testnet := &network{
+		name:       "testnet",
+		hosts:      []string{"testnet.example.com"},
+		connected:  new(atomic.Bool),
+		uploader:   new(uploader.Uploader),
+		downloader: newDownloader(),
+	}
+	testnet.connected.Store(true)
+
+	a := &app{
+		log:          zap.NewNop(),
+		cfg:          viper.New(),
+		webServer:    new(fasthttp.Server),
+		metrics:      newGateMetrics(zap.NewNop(), new(fakeMetricsProvider), false),
+		networks:     []*network{testnet},
+		networkHosts: map[string]*network{"testnet.example.com": testnet},
+	}
+	a.clientIP, _ = clientip.New(nil, "")
+	a.configureRouter(new(uploader.Uploader), newDownloader())
+
+	request := func(method, host, path string) *fasthttp.RequestCtx {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(path)
+		ctx.Request.Header.SetHost(host)
+		a.webServer.Handler(&ctx)
+		return &ctx
+	}
+
+	// the default network isn't connected, the testnet one is, browsing is
+	// disabled everywhere
+	require.Equal(t, fasthttp.StatusServiceUnavailable, request(fasthttp.MethodGet, "gate.example.com", "/browse/cid").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusForbidden, request(fasthttp.MethodGet, "gate.example.com", "/testnet/browse/cid").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusForbidden, request(fasthttp.MethodGet, "testnet.example.com:8080", "/browse/cid").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusNotFound, request(fasthttp.MethodGet, "testnet.example.com", "/testnet/browse/cid").Response.StatusCode())
+
+	ctx := request(fasthttp.MethodOptions, "gate.example.com", "/testnet/get/cid/oid")
+	require.Equal(t, fasthttp.StatusNoContent, ctx.Response.StatusCode())
+	require.Equal(t, "DELETE, GET, HEAD, OPTIONS", string(ctx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	a.cfg.Set(cfgWebAllowedHosts, []string{"gate.example.com"})
+	require.NoError(t, a.updateAllowedHosts())
+	require.Equal(t, fasthttp.StatusForbidden, request(fasthttp.MethodGet, "gate.example.com", "/testnet/browse/cid").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusForbidden, request(fasthttp.MethodGet, "testnet.example.com", "/browse/cid").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusMisdirectedRequest, request(fasthttp.MethodGet, "evil.example.com", "/testnet/browse/cid").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusMisdirectedRequest, request(fasthttp.MethodGet, "", "/testnet/browse/cid").Response.StatusCode())
+}
+
+func TestReservedNetworkName(t *testing.T) {
+	r := newRouter()
+	r.GET("/get/{cid}/{oid}", func(*fasthttp.RequestCtx) {})
+	r.GET("/v1/info", func(*fasthttp.RequestCtx) {})
+
+	require.True(t, reservedNetworkName(r, "get"))
+	require.True(t, reservedNetworkName(r, "v1"))
+	require.False(t, reservedNetworkName(r, "testnet"))
+}
@@ -0,0 +1,57 @@
+package pathfilter
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// bloom is a Bloom filter of strings.
+type bloom struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// newBloom creates the filter for n elements with the false positive rate p.
+func newBloom(n int, p float64) *bloom {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloom{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// hashes returns two hashes of the string, the rest are derived from them.
+func hashes(s string) (uint64, uint64) {
+	h := fnv.New128a()
+	_, _ = h.Write([]byte(s))
+	sum := h.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:])
+}
+
+func (b *bloom) add(s string) {
+	h1, h2 := hashes(s)
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+func (b *bloom) has(s string) bool {
+	h1, h2 := hashes(s)
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
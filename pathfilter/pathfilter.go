@@ -0,0 +1,251 @@
+// Package pathfilter keeps Bloom filters of FilePath attributes of container
+// objects, so downloads of paths that obviously don't exist are rejected
+// without a search. Filters are built from container listings when paths of
+// the container are requested first and are rebuilt periodically, paths of
+// objects written through the gateway are added at once. Filters are local to
+// the gateway instance: paths of objects written through other instances or
+// directly to NeoFS are missing until the next rebuild.
+package pathfilter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// Default filter parameters.
+const (
+	DefaultRefreshInterval   = 10 * time.Minute
+	DefaultMaxObjects        = 10000
+	DefaultMaxContainers     = 100
+	DefaultFalsePositiveRate = 0.01
+	DefaultConcurrency       = 8
+)
+
+// ListFunc returns FilePath attributes of all container objects, it fails if
+// there are more than max objects. Up to concurrency requests are made at the
+// same time.
+type ListFunc func(ctx context.Context, cnrID cid.ID, max, concurrency int) ([]string, error)
+
+// Config contains filter parameters.
+type Config struct {
+	// RefreshInterval is the time filters are rebuilt after, failed builds
+	// are retried after it too.
+	RefreshInterval time.Duration
+	// MaxObjects is the maximum number of listed container objects, larger
+	// containers aren't filtered.
+	MaxObjects int
+	// MaxContainers is the maximum number of filtered containers.
+	MaxContainers int
+	// FalsePositiveRate is the rate of missing paths passing the filter.
+	FalsePositiveRate float64
+	// Concurrency is the maximum number of simultaneous requests of a
+	// filter build, filters are built one at a time.
+	Concurrency int
+}
+
+type state struct {
+	filter   *bloom
+	built    time.Time
+	used     time.Time
+	building bool
+	// added are paths of objects written while the filter is being built
+	added []string
+}
+
+// Filters contains filters of containers.
+type Filters struct {
+	ctx context.Context
+	cfg Config
+	log *zap.Logger
+
+	// builds limits the number of simultaneous filter builds
+	builds chan struct{}
+
+	mu         sync.Mutex
+	containers map[cid.ID]*state
+}
+
+// New creates container filters, they're built until the context is done.
+func New(ctx context.Context, cfg Config, log *zap.Logger) *Filters {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	if cfg.MaxObjects <= 0 {
+		cfg.MaxObjects = DefaultMaxObjects
+	}
+	if cfg.MaxContainers <= 0 {
+		cfg.MaxContainers = DefaultMaxContainers
+	}
+	if cfg.FalsePositiveRate <= 0 || cfg.FalsePositiveRate >= 1 {
+		cfg.FalsePositiveRate = DefaultFalsePositiveRate
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	return &Filters{
+		ctx:        ctx,
+		cfg:        cfg,
+		log:        log,
+		builds:     make(chan struct{}, 1),
+		containers: make(map[cid.ID]*state),
+	}
+}
+
+// MayContain checks whether the container can have an object with the path.
+// True is returned if the container filter isn't built yet, the filter is
+// built in background with the list function if it's missing or outdated.
+// If there are filters of MaxContainers containers already, a failed or idle
+// one is dropped for the new container, otherwise it isn't filtered.
+func (f *Filters) MayContain(cnrID cid.ID, path string, list ListFunc) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.containers[cnrID]
+	if !ok {
+		if len(f.containers) >= f.cfg.MaxContainers && !f.evict() {
+			return true
+		}
+		s = new(state)
+		f.containers[cnrID] = s
+	}
+	s.used = time.Now()
+	if !s.building && (s.built.IsZero() || time.Since(s.built) > f.cfg.RefreshInterval) {
+		s.building = true
+		go f.build(cnrID, list)
+	}
+	return s.filter == nil || s.filter.has(path)
+}
+
+// Add adds the path of the object written to the container to its filter.
+func (f *Filters) Add(cnrID cid.ID, path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.containers[cnrID]
+	if !ok {
+		return
+	}
+	if s.filter != nil {
+		s.filter.add(path)
+	}
+	if s.building {
+		s.added = append(s.added, path)
+	}
+}
+
+// evict drops a filter that failed to build or a filter that hasn't been used
+// for the refresh interval, the least recently used one is preferred. Filters
+// being built aren't dropped. False is returned if there is none to drop.
+func (f *Filters) evict() bool {
+	var (
+		victim cid.ID
+		oldest *state
+	)
+	for id, s := range f.containers {
+		if s.building {
+			continue
+		}
+		if s.filter == nil {
+			delete(f.containers, id)
+			return true
+		}
+		if time.Since(s.used) > f.cfg.RefreshInterval && (oldest == nil || s.used.Before(oldest.used)) {
+			victim, oldest = id, s
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(f.containers, victim)
+	return true
+}
+
+func (f *Filters) build(cnrID cid.ID, list ListFunc) {
+	select {
+	case f.builds <- struct{}{}:
+		defer func() { <-f.builds }()
+	case <-f.ctx.Done():
+	}
+	paths, err := list(f.ctx, cnrID, f.cfg.MaxObjects, f.cfg.Concurrency)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.containers[cnrID]
+	s.building = false
+	s.built = time.Now()
+	if err != nil {
+		// the container isn't filtered until the next try
+		f.log.Debug("could not build path filter", zap.Stringer("cid", cnrID), zap.Error(err))
+		s.filter = nil
+		s.added = nil
+		return
+	}
+
+	// paths can be added before the next build
+	filter := newBloom(len(paths)+len(paths)/2+64, f.cfg.FalsePositiveRate)
+	for _, p := range paths {
+		filter.add(p)
+	}
+	for _, p := range s.added {
+		filter.add(p)
+	}
+	s.filter = filter
+	s.added = nil
+	f.log.Debug("path filter built", zap.Stringer("cid", cnrID), zap.Int("paths", len(paths)))
+}
+
+// Backend adds paths of objects written via the wrapped backend to filters.
+type Backend struct {
+	backend.Backend
+	filters *Filters
+}
+
+// NewBackend wraps the backend adding paths to the filters.
+func NewBackend(b backend.Backend, f *Filters) *Backend {
+	return &Backend{Backend: b, filters: f}
+}
+
+// ObjectPutInit implements backend.Backend. The path of the object is added
+// when it's stored, objects written by parts are added by the path of the
+// parent object.
+func (b *Backend) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (backend.ObjectWriter, error) {
+	w, err := b.Backend.ObjectPutInit(ctx, hdr, signer, prm)
+	if err != nil {
+		return nil, err
+	}
+	return &addingWriter{ObjectWriter: w, filters: b.filters, hdr: hdr}, nil
+}
+
+type addingWriter struct {
+	backend.ObjectWriter
+	filters *Filters
+	hdr     object.Object
+}
+
+func (w *addingWriter) Close() error {
+	if err := w.ObjectWriter.Close(); err != nil {
+		return err
+	}
+	cnrID, ok := w.hdr.ContainerID()
+	if !ok {
+		return nil
+	}
+	attrs := w.hdr.Attributes()
+	if parent := w.hdr.Parent(); parent != nil {
+		attrs = append(attrs, parent.Attributes()...)
+	}
+	for _, a := range attrs {
+		if a.Key() == object.AttributeFilePath {
+			w.filters.Add(cnrID, a.Value())
+		}
+	}
+	return nil
+}
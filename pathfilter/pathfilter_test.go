@@ -0,0 +1,157 @@
+package pathfilter
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBloom(t *testing.T) {
+	const n = 10000
+	b := newBloom(n, 0.01)
+	for i := 0; i < n; i++ {
+		b.add("present/" + strconv.Itoa(i))
+	}
+	var positives int
+	for i := 0; i < n; i++ {
+		require.True(t, b.has("present/"+strconv.Itoa(i)))
+		if b.has("missing/" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+	require.Less(t, positives, n*3/100)
+}
+
+func TestFilters(t *testing.T) {
+	var (
+		cnrID   = cid.ID{1}
+		calls   = make(chan struct{}, 10)
+		release = make(chan struct{})
+		listErr error
+	)
+	list := func(_ context.Context, _ cid.ID, max, concurrency int) ([]string, error) {
+		require.Equal(t, 5, max)
+		require.Equal(t, DefaultConcurrency, concurrency)
+		calls <- struct{}{}
+		<-release
+		return []string{"index.html", "css/main.css"}, listErr
+	}
+	f := New(context.Background(), Config{MaxObjects: 5, MaxContainers: 1}, zap.NewNop())
+	built := func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		s := f.containers[cnrID]
+		return !s.building && !s.built.IsZero()
+	}
+
+	// everything passes until the filter is built
+	require.True(t, f.MayContain(cnrID, "missing.html", list))
+	<-calls
+	require.True(t, f.MayContain(cnrID, "missing.html", list))
+	f.Add(cnrID, "new.html")
+	release <- struct{}{}
+	require.Eventually(t, built, time.Second, time.Millisecond)
+	require.Empty(t, calls, "the filter is built once")
+
+	require.True(t, f.MayContain(cnrID, "index.html", list))
+	require.True(t, f.MayContain(cnrID, "new.html", list), "added during the build")
+	require.False(t, f.MayContain(cnrID, "missing.html", list))
+	f.Add(cnrID, "missing.html")
+	require.True(t, f.MayContain(cnrID, "missing.html", list))
+
+	// containers over the limit aren't filtered
+	require.True(t, f.MayContain(cid.ID{2}, "missing.html", list))
+	require.Len(t, f.containers, 1)
+
+	// the filter is dropped if it can't be rebuilt
+	f.mu.Lock()
+	f.containers[cnrID].built = time.Now().Add(-time.Hour)
+	f.mu.Unlock()
+	listErr = errors.New("too many objects")
+	require.False(t, f.MayContain(cnrID, "other.html", list))
+	<-calls
+	release <- struct{}{}
+	require.Eventually(t, built, time.Second, time.Millisecond)
+	require.True(t, f.MayContain(cnrID, "other.html", list))
+}
+
+func TestFiltersEviction(t *testing.T) {
+	var (
+		listed  = make(chan cid.ID, 10)
+		failing = cid.ID{2}
+	)
+	list := func(_ context.Context, cnrID cid.ID, _, _ int) ([]string, error) {
+		listed <- cnrID
+		if cnrID == failing {
+			return nil, errors.New("container not found")
+		}
+		return []string{"index.html"}, nil
+	}
+	f := New(context.Background(), Config{MaxContainers: 2, RefreshInterval: time.Hour}, zap.NewNop())
+	built := func(cnrID cid.ID) func() bool {
+		return func() bool {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			s, ok := f.containers[cnrID]
+			return ok && !s.building && !s.built.IsZero()
+		}
+	}
+
+	f.MayContain(cid.ID{1}, "index.html", list)
+	require.Eventually(t, built(cid.ID{1}), time.Second, time.Millisecond)
+	f.MayContain(failing, "index.html", list)
+	require.Eventually(t, built(failing), time.Second, time.Millisecond)
+
+	// failed filters are dropped first
+	f.MayContain(cid.ID{3}, "index.html", list)
+	require.Eventually(t, built(cid.ID{3}), time.Second, time.Millisecond)
+	require.NotContains(t, f.containers, failing)
+
+	// filters in use are kept
+	require.True(t, f.MayContain(cid.ID{4}, "missing.html", list))
+	require.NotContains(t, f.containers, cid.ID{4})
+	require.False(t, f.MayContain(cid.ID{1}, "missing.html", list))
+
+	// idle filters are dropped
+	f.mu.Lock()
+	f.containers[cid.ID{3}].used = time.Now().Add(-2 * time.Hour)
+	f.mu.Unlock()
+	f.MayContain(cid.ID{4}, "index.html", list)
+	require.Eventually(t, built(cid.ID{4}), time.Second, time.Millisecond)
+	require.NotContains(t, f.containers, cid.ID{3})
+	require.Contains(t, f.containers, cid.ID{1})
+	require.Len(t, listed, 4)
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	f := New(ctx, Config{}, zap.NewNop())
+	f.containers[cnrID] = &state{filter: newBloom(10, 0.01), built: time.Now()}
+	require.False(t, f.MayContain(cnrID, "index.html", nil))
+
+	var attr object.Attribute
+	attr.SetKey(object.AttributeFilePath)
+	attr.SetValue("index.html")
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	hdr.SetAttributes(attr)
+
+	w, err := NewBackend(mem, f).ObjectPutInit(ctx, hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	require.False(t, f.MayContain(cnrID, "index.html", nil), "added once the object is stored")
+	require.NoError(t, w.Close())
+	require.True(t, f.MayContain(cnrID, "index.html", nil))
+}
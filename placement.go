@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/placement"
+	"go.uber.org/zap"
+)
+
+// placementBackend returns the backend reading objects directly from nodes
+// holding them, it's created once per network and shared by its handlers,
+// so node connections are reused. Network maps are requested from the pool.
+func (a *app) placementBackend(next backend.Backend, p *backend.Pool, namespace string) *placement.Backend {
+	if b, ok := a.placements[namespace]; ok {
+		return b
+	}
+
+	b := placement.NewBackend(next, p, placement.Config{
+		MaxNodes:       a.cfg.GetInt(cfgPlacementReadsMaxNodes),
+		NetmapTTL:      a.cfg.GetDuration(cfgPlacementReadsNetmapTTL),
+		DialTimeout:    a.cfg.GetDuration(cfgConTimeout),
+		StreamTimeout:  a.cfg.GetDuration(cfgStreamTimeout),
+		DialBackoff:    a.cfg.GetDuration(cfgPlacementReadsDialBackoff),
+		MaxDialBackoff: a.cfg.GetDuration(cfgPlacementReadsMaxDialBackoff),
+		Preference:     a.nodePreference(),
+	}, a.log)
+	if a.placements == nil {
+		a.placements = make(map[string]*placement.Backend)
+	}
+	a.placements[namespace] = b
+	// nodes are probed until in-flight operations are canceled on shutdown
+	go b.Run(a.opCtx)
+	return b
+}
+
+// nodePreference returns the order nodes holding objects are tried in, nodes
+// are ordered by placement if the policy is unknown.
+func (a *app) nodePreference() placement.Preference {
+	policy := a.cfg.GetString(cfgPlacementReadsPrefer)
+	if err := placement.CheckPolicy(policy); err != nil {
+		a.log.Warn("invalid node preference, placement order is used", zap.Error(err))
+		policy = placement.PreferNone
+	}
+	return placement.Preference{
+		Policy:        policy,
+		Attributes:    a.cfg.GetStringMapString(cfgPlacementReadsPreferAttributes),
+		ProbeInterval: a.cfg.GetDuration(cfgPlacementReadsProbeInterval),
+		ProbeTimeout:  a.cfg.GetDuration(cfgPlacementReadsProbeTimeout),
+	}
+}
@@ -0,0 +1,392 @@
+// Package placement reads objects directly from storage nodes holding them
+// according to the container placement policy instead of any pool node
+// acting as a router, so large payloads don't pass an extra network hop.
+package placement
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/discovery"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// Default parameters of direct reads.
+const (
+	DefaultMaxNodes       = 2
+	DefaultNetmapTTL      = time.Minute
+	DefaultDialBackoff    = 5 * time.Second
+	DefaultMaxDialBackoff = 5 * time.Minute
+)
+
+// NetMapSource provides the current network map.
+type NetMapSource interface {
+	NetMapSnapshot(ctx context.Context, prm client.PrmNetMapSnapshot) (netmap.NetMap, error)
+}
+
+// Config contains parameters of direct reads.
+type Config struct {
+	// MaxNodes is the number of nodes holding the object tried before the
+	// wrapped backend is used.
+	MaxNodes int
+	// NetmapTTL is the time the network map and container nodes are cached.
+	NetmapTTL time.Duration
+	// DialTimeout and StreamTimeout are timeouts of node connections.
+	DialTimeout   time.Duration
+	StreamTimeout time.Duration
+	// DialBackoff is the time nodes that can't be dialed are skipped for,
+	// it's doubled on every next failure up to MaxDialBackoff.
+	DialBackoff    time.Duration
+	MaxDialBackoff time.Duration
+	// Preference is the order nodes holding the object are tried in.
+	Preference Preference
+}
+
+// nodeClient reads objects from a single node.
+type nodeClient interface {
+	ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error)
+	Close() error
+}
+
+type sdkClient struct {
+	*client.Client
+}
+
+func (c sdkClient) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	hdr, r, err := c.Client.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+	if err != nil {
+		return hdr, nil, err
+	}
+	return hdr, r, nil
+}
+
+// Backend gets objects from nodes selected by the placement policy of their
+// containers, failed reads are retried via the wrapped backend. Other
+// operations are executed by the wrapped backend.
+type Backend struct {
+	backend.Backend
+	src   NetMapSource
+	cfg   Config
+	log   *zap.Logger
+	dial  func(ctx context.Context, address string) (nodeClient, error)
+	probe func(ctx context.Context, address string) (time.Duration, error)
+	now   func() time.Time
+
+	mu      sync.Mutex
+	netmap  *netmap.NetMap
+	updated time.Time
+	// nodes are container nodes of the cached network map
+	nodes   map[cid.ID][][]netmap.NodeInfo
+	clients map[string]nodeClient
+	// rtt is a smoothed round-trip time to nodes
+	rtt map[string]time.Duration
+	// failures are nodes that couldn't be dialed recently
+	failures map[string]dialFailure
+}
+
+// dialFailure is the time the node is skipped until and the current backoff.
+type dialFailure struct {
+	until   time.Time
+	backoff time.Duration
+}
+
+// NewBackend wraps the backend reading objects from their nodes directly.
+func NewBackend(b backend.Backend, src NetMapSource, cfg Config, log *zap.Logger) *Backend {
+	if cfg.MaxNodes <= 0 {
+		cfg.MaxNodes = DefaultMaxNodes
+	}
+	if cfg.NetmapTTL <= 0 {
+		cfg.NetmapTTL = DefaultNetmapTTL
+	}
+	if cfg.DialBackoff <= 0 {
+		cfg.DialBackoff = DefaultDialBackoff
+	}
+	if cfg.MaxDialBackoff <= 0 {
+		cfg.MaxDialBackoff = DefaultMaxDialBackoff
+	}
+	if cfg.MaxDialBackoff < cfg.DialBackoff {
+		cfg.MaxDialBackoff = cfg.DialBackoff
+	}
+	if cfg.Preference.ProbeInterval <= 0 {
+		cfg.Preference.ProbeInterval = DefaultProbeInterval
+	}
+	if cfg.Preference.ProbeTimeout <= 0 {
+		cfg.Preference.ProbeTimeout = DefaultProbeTimeout
+	}
+	res := &Backend{
+		Backend:  b,
+		src:      src,
+		cfg:      cfg,
+		log:      log,
+		nodes:    make(map[cid.ID][][]netmap.NodeInfo),
+		clients:  make(map[string]nodeClient),
+		rtt:      make(map[string]time.Duration),
+		failures: make(map[string]dialFailure),
+	}
+	res.dial = res.dialNode
+	res.probe = res.probeTCP
+	res.now = time.Now
+	return res
+}
+
+// ObjectGetInit implements backend.Backend.
+func (b *Backend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	addresses, err := b.objectNodes(ctx, containerID, objectID)
+	if err != nil {
+		b.log.Debug("failed to get object placement", zap.Stringer("cid", containerID),
+			zap.Stringer("oid", objectID), zap.Error(err))
+	}
+
+	for _, address := range addresses {
+		c, err := b.client(ctx, address)
+		if err != nil {
+			b.log.Debug("failed to connect to object node", zap.String("address", address), zap.Error(err))
+			continue
+		}
+		hdr, r, err := c.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+		if err == nil {
+			return hdr, r, nil
+		}
+		if ctx.Err() != nil {
+			return hdr, nil, err
+		}
+		b.log.Debug("failed to get object from its node", zap.String("address", address),
+			zap.Stringer("cid", containerID), zap.Stringer("oid", objectID), zap.Error(err))
+	}
+
+	return b.Backend.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+}
+
+// objectNodes returns addresses of up to MaxNodes nodes holding the object in
+// the preferred order. Nodes with the same preference are in the order of the
+// placement vectors: the first nodes of all replicas go first. Nodes that
+// failed to be dialed are skipped until their backoff expires.
+func (b *Backend) objectNodes(ctx context.Context, containerID cid.ID, objectID oid.ID) ([]string, error) {
+	nm, vectors, err := b.containerNodes(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err = nm.PlacementVectors(vectors, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("placement vectors: %w", err)
+	}
+
+	var (
+		candidates []candidate
+		seen       = make(map[string]struct{})
+	)
+	for i := 0; ; i++ {
+		var found bool
+		for _, vector := range vectors {
+			if i >= len(vector) {
+				continue
+			}
+			found = true
+			address := nodeAddress(vector[i])
+			if _, ok := seen[address]; ok || address == "" {
+				continue
+			}
+			seen[address] = struct{}{}
+			candidates = append(candidates, candidate{address: address, node: vector[i]})
+		}
+		if !found {
+			break
+		}
+	}
+
+	candidates = b.dialable(candidates)
+	b.prefer(candidates)
+	if len(candidates) > b.cfg.MaxNodes {
+		candidates = candidates[:b.cfg.MaxNodes]
+	}
+	res := make([]string, len(candidates))
+	for i := range candidates {
+		res[i] = candidates[i].address
+	}
+	return res, nil
+}
+
+func nodeAddress(n netmap.NodeInfo) string {
+	var res string
+	n.IterateNetworkEndpoints(func(endpoint string) bool {
+		var err error
+		res, err = discovery.Address(endpoint)
+		return err == nil
+	})
+	return res
+}
+
+// containerNodes returns the cached network map and container nodes, they're
+// updated when the cache is outdated (see networkMap).
+func (b *Backend) containerNodes(ctx context.Context, containerID cid.ID) (netmap.NetMap, [][]netmap.NodeInfo, error) {
+	nm, err := b.networkMap(ctx)
+	if err != nil {
+		return nm, nil, err
+	}
+	b.mu.Lock()
+	// container nodes are reset when the network map is changed
+	vectors, ok := b.nodes[containerID]
+	ok = ok && b.netmap.Epoch() == nm.Epoch()
+	b.mu.Unlock()
+	if ok {
+		return nm, vectors, nil
+	}
+
+	cnr, err := b.Backend.ContainerGet(ctx, containerID, client.PrmContainerGet{})
+	if err != nil {
+		return nm, nil, fmt.Errorf("get container: %w", err)
+	}
+	vectors, err = nm.ContainerNodes(cnr.PlacementPolicy(), containerID)
+	if err != nil {
+		return nm, nil, fmt.Errorf("container nodes: %w", err)
+	}
+
+	b.mu.Lock()
+	if b.netmap.Epoch() == nm.Epoch() {
+		b.nodes[containerID] = vectors
+	}
+	b.mu.Unlock()
+	return nm, vectors, nil
+}
+
+// setNetmap caches the network map, closes connections to nodes which aren't
+// in it anymore and forgets their RTT. It must be called under the lock.
+func (b *Backend) setNetmap(nm netmap.NetMap) {
+	if b.netmap == nil || b.netmap.Epoch() != nm.Epoch() {
+		b.nodes = make(map[cid.ID][][]netmap.NodeInfo)
+
+		actual := make(map[string]struct{})
+		for _, n := range nm.Nodes() {
+			actual[nodeAddress(n)] = struct{}{}
+		}
+		for address, c := range b.clients {
+			if _, ok := actual[address]; !ok {
+				_ = c.Close()
+				delete(b.clients, address)
+			}
+		}
+		for address := range b.rtt {
+			if _, ok := actual[address]; !ok {
+				delete(b.rtt, address)
+			}
+		}
+		for address := range b.failures {
+			if _, ok := actual[address]; !ok {
+				delete(b.failures, address)
+			}
+		}
+	}
+	b.netmap = &nm
+	b.updated = time.Now()
+}
+
+// dialable returns the candidates except the ones skipped after dial failures.
+func (b *Backend) dialable(candidates []candidate) []candidate {
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := candidates[:0]
+	for _, c := range candidates {
+		if f, ok := b.failures[c.address]; ok && now.Before(f.until) {
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// client returns the connection to the node, it's dialed on the first use.
+// Nodes that can't be dialed aren't dialed again until their backoff expires.
+func (b *Backend) client(ctx context.Context, address string) (nodeClient, error) {
+	b.mu.Lock()
+	c, ok := b.clients[address]
+	f, failed := b.failures[address]
+	b.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	if failed && b.now().Before(f.until) {
+		return nil, fmt.Errorf("node is skipped after dial failure until %s", f.until.Format(time.RFC3339))
+	}
+
+	c, err := b.dial(ctx, address)
+	if err != nil {
+		// canceled requests say nothing about the node
+		if ctx.Err() == nil {
+			b.dialFailed(address)
+		}
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, address)
+	if other, ok := b.clients[address]; ok {
+		_ = c.Close()
+		return other, nil
+	}
+	b.clients[address] = c
+	return c, nil
+}
+
+// dialFailed skips the node for the backoff doubled on every next failure.
+func (b *Backend) dialFailed(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, ok := b.failures[address]
+	switch {
+	case !ok:
+		f.backoff = b.cfg.DialBackoff
+	case f.backoff < b.cfg.MaxDialBackoff:
+		f.backoff *= 2
+		if f.backoff > b.cfg.MaxDialBackoff {
+			f.backoff = b.cfg.MaxDialBackoff
+		}
+	}
+	f.until = b.now().Add(f.backoff)
+	b.failures[address] = f
+	b.log.Debug("node is skipped after dial failure", zap.String("address", address), zap.Duration("backoff", f.backoff))
+}
+
+func (b *Backend) dialNode(ctx context.Context, address string) (nodeClient, error) {
+	c, err := client.New(client.PrmInit{})
+	if err != nil {
+		return nil, err
+	}
+
+	var prm client.PrmDial
+	prm.SetServerURI(address)
+	if b.cfg.DialTimeout > 0 {
+		prm.SetTimeout(b.cfg.DialTimeout)
+	}
+	if b.cfg.StreamTimeout > 0 {
+		prm.SetStreamTimeout(b.cfg.StreamTimeout)
+	}
+	prm.SetContext(ctx)
+	if err = c.Dial(prm); err != nil {
+		return nil, err
+	}
+	return sdkClient{c}, nil
+}
+
+// Close closes node connections.
+func (b *Backend) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for address, c := range b.clients {
+		_ = c.Close()
+		delete(b.clients, address)
+	}
+}
@@ -0,0 +1,239 @@
+package placement
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testNetmap struct {
+	nm    netmap.NetMap
+	calls int
+}
+
+func (s *testNetmap) NetMapSnapshot(context.Context, client.PrmNetMapSnapshot) (netmap.NetMap, error) {
+	s.calls++
+	return s.nm, nil
+}
+
+type testNode struct {
+	address string
+	fail    bool
+	reads   *[]string
+	closed  bool
+}
+
+func (n *testNode) ObjectGetInit(_ context.Context, _ cid.ID, _ oid.ID, _ user.Signer, _ client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	*n.reads = append(*n.reads, n.address)
+	if n.fail {
+		return object.Object{}, nil, errors.New("node failure")
+	}
+	return object.Object{}, io.NopCloser(bytes.NewReader([]byte(n.address))), nil
+}
+
+func (n *testNode) Close() error {
+	n.closed = true
+	return nil
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+
+	var nodes []netmap.NodeInfo
+	for i := 0; i < 4; i++ {
+		var n netmap.NodeInfo
+		n.SetPublicKey([]byte{byte(i)})
+		n.SetNetworkEndpoints("/dns4/node" + strconv.Itoa(i) + "/tcp/8080")
+		n.SetOnline()
+		nodes = append(nodes, n)
+	}
+	src := &testNetmap{}
+	src.nm.SetEpoch(1)
+	src.nm.SetNodes(nodes)
+
+	var policy netmap.PlacementPolicy
+	require.NoError(t, policy.DecodeString("REP 2"))
+	cnr := containertest.Container(t)
+	cnr.SetPlacementPolicy(policy)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	w, err := mem.ObjectPutInit(ctx, hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte("stored"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	objID := w.StoredObjectID()
+
+	var (
+		reads   []string
+		clients = make(map[string]*testNode)
+		failing = make(map[string]bool)
+	)
+	b := NewBackend(mem, src, Config{}, zap.NewNop())
+	b.dial = func(_ context.Context, address string) (nodeClient, error) {
+		n := &testNode{address: address, fail: failing[address], reads: &reads}
+		clients[address] = n
+		return n, nil
+	}
+
+	read := func() string {
+		_, r, err := b.ObjectGetInit(ctx, cnrID, objID, nil, client.PrmObjectGet{})
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("placement node", func(t *testing.T) {
+		res := read()
+		require.Len(t, reads, 1)
+		require.Equal(t, reads[0], res)
+		require.Len(t, clients, 1)
+
+		// the same node is used
+		require.Equal(t, res, read())
+		require.Len(t, clients, 1)
+		require.Equal(t, 1, src.calls)
+	})
+
+	t.Run("next node", func(t *testing.T) {
+		first := reads[0]
+		clients[first].fail = true
+		reads = reads[:0]
+
+		res := read()
+		require.Len(t, reads, 2)
+		require.Equal(t, first, reads[0])
+		require.Equal(t, reads[1], res)
+		require.NotEqual(t, first, res)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		for _, n := range clients {
+			n.fail = true
+		}
+		reads = reads[:0]
+
+		require.Equal(t, "stored", read())
+		require.Len(t, reads, DefaultMaxNodes)
+	})
+
+	t.Run("netmap change", func(t *testing.T) {
+		used := make(map[string]*testNode, len(clients))
+		for address, n := range clients {
+			used[address] = n
+		}
+
+		var nm netmap.NetMap
+		nm.SetEpoch(2)
+		nm.SetNodes(nodes[:1])
+		b.mu.Lock()
+		b.setNetmap(nm)
+		b.mu.Unlock()
+
+		for address, n := range used {
+			require.Equal(t, address != "grpc://node0:8080", n.closed, address)
+		}
+		require.Empty(t, b.nodes)
+	})
+}
+
+func TestDialBackoff(t *testing.T) {
+	ctx := context.Background()
+
+	var nodes []netmap.NodeInfo
+	for i := 0; i < 2; i++ {
+		var n netmap.NodeInfo
+		n.SetPublicKey([]byte{byte(i)})
+		n.SetNetworkEndpoints("/dns4/node" + strconv.Itoa(i) + "/tcp/8080")
+		n.SetOnline()
+		nodes = append(nodes, n)
+	}
+	src := &testNetmap{}
+	src.nm.SetEpoch(1)
+	src.nm.SetNodes(nodes)
+
+	var policy netmap.PlacementPolicy
+	require.NoError(t, policy.DecodeString("REP 2"))
+	cnr := containertest.Container(t)
+	cnr.SetPlacementPolicy(policy)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	var (
+		now   = time.Now()
+		dials []string
+		reads []string
+		down  = make(map[string]bool)
+	)
+	b := NewBackend(mem, src, Config{MaxNodes: 1, DialBackoff: time.Second, MaxDialBackoff: 3 * time.Second}, zap.NewNop())
+	b.now = func() time.Time { return now }
+	b.dial = func(_ context.Context, address string) (nodeClient, error) {
+		dials = append(dials, address)
+		if down[address] {
+			return nil, errors.New("connection refused")
+		}
+		return &testNode{address: address, reads: &reads}, nil
+	}
+
+	objID := oidtest.ID()
+	addresses, err := b.objectNodes(ctx, cnrID, objID)
+	require.NoError(t, err)
+	require.Len(t, addresses, 1)
+	first := addresses[0]
+	down[first] = true
+
+	read := func() {
+		_, _, _ = b.ObjectGetInit(ctx, cnrID, objID, nil, client.PrmObjectGet{})
+	}
+
+	read()
+	require.Equal(t, []string{first}, dials)
+
+	// the failed node is skipped, so the next one is tried instead
+	dials, reads = nil, nil
+	read()
+	require.Len(t, dials, 1)
+	require.NotEqual(t, first, dials[0])
+	require.Equal(t, dials, reads)
+
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		b.mu.Lock()
+		require.Equal(t, backoff, b.failures[first].backoff)
+		b.mu.Unlock()
+
+		// the node is dialed again when the backoff expires
+		now = now.Add(backoff)
+		dials = nil
+		read()
+		require.Equal(t, []string{first}, dials)
+	}
+
+	delete(down, first)
+	now = now.Add(time.Hour)
+	reads = nil
+	read()
+	require.Equal(t, []string{first}, reads)
+	require.Empty(t, b.failures)
+}
@@ -0,0 +1,247 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/discovery"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"go.uber.org/zap"
+)
+
+// Node preference policies.
+const (
+	// PreferNone keeps the placement order of nodes.
+	PreferNone = "none"
+	// PreferLowestRTT orders nodes by the round-trip time measured by probes.
+	PreferLowestRTT = "lowest_rtt"
+	// PreferAttributes puts nodes having the preferred attributes first.
+	PreferAttributes = "attributes"
+)
+
+// Default parameters of latency probes.
+const (
+	DefaultProbeInterval = 30 * time.Second
+	DefaultProbeTimeout  = 2 * time.Second
+)
+
+// probeConcurrency limits the number of nodes probed at once.
+const probeConcurrency = 16
+
+// rttSmoothing is a weight of the new probe result in the smoothed RTT.
+const rttSmoothing = 0.3
+
+// Preference describes the order of nodes holding the object reads are tried
+// in.
+type Preference struct {
+	// Policy is one of PreferNone, PreferLowestRTT or PreferAttributes.
+	Policy string
+	// Attributes are preferred node attributes for PreferAttributes policy,
+	// nodes with the same preference are ordered by RTT if it's known.
+	Attributes map[string]string
+	// ProbeInterval and ProbeTimeout are parameters of latency probes.
+	ProbeInterval time.Duration
+	ProbeTimeout  time.Duration
+}
+
+// CheckPolicy checks whether the preference policy is known.
+func CheckPolicy(policy string) error {
+	switch policy {
+	case PreferNone, PreferLowestRTT, PreferAttributes:
+		return nil
+	default:
+		return fmt.Errorf("unknown node preference policy '%s'", policy)
+	}
+}
+
+// candidate is a node holding the object.
+type candidate struct {
+	address string
+	node    netmap.NodeInfo
+}
+
+// prefer sorts the candidates according to the preference, the placement
+// order is kept for nodes with the same preference.
+func (b *Backend) prefer(candidates []candidate) {
+	pref := b.cfg.Preference
+	if pref.Policy == "" || pref.Policy == PreferNone {
+		return
+	}
+
+	b.mu.Lock()
+	rtt := make([]time.Duration, len(candidates))
+	known := make([]bool, len(candidates))
+	for i := range candidates {
+		rtt[i], known[i] = b.rtt[candidates[i].address]
+	}
+	b.mu.Unlock()
+
+	preferred := make([]bool, len(candidates))
+	if pref.Policy == PreferAttributes {
+		for i := range candidates {
+			preferred[i] = discovery.Matches(candidates[i].node, pref.Attributes)
+		}
+	}
+
+	idx := make([]int, len(candidates))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		x, y := idx[i], idx[j]
+		if preferred[x] != preferred[y] {
+			return preferred[x]
+		}
+		if known[x] != known[y] {
+			return known[x]
+		}
+		return known[x] && rtt[x] < rtt[y]
+	})
+
+	sorted := make([]candidate, len(candidates))
+	for i, k := range idx {
+		sorted[i] = candidates[k]
+	}
+	copy(candidates, sorted)
+}
+
+// RTT returns the smoothed round-trip time to the node if it's been probed.
+func (b *Backend) RTT(address string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rtt, ok := b.rtt[address]
+	return rtt, ok
+}
+
+// Run probes nodes of the network map with the configured interval until the
+// context is done. Nodes are probed only for PreferLowestRTT and
+// PreferAttributes policies.
+func (b *Backend) Run(ctx context.Context) {
+	pref := b.cfg.Preference
+	if pref.Policy != PreferLowestRTT && pref.Policy != PreferAttributes {
+		return
+	}
+
+	ticker := time.NewTicker(pref.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		b.probeNodes(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeNodes measures RTT to all nodes of the network map, unreachable nodes
+// get the probe timeout as RTT, so they're tried last.
+func (b *Backend) probeNodes(ctx context.Context) {
+	nm, err := b.networkMap(ctx)
+	if err != nil {
+		b.log.Debug("failed to get network map for node probes", zap.Error(err))
+		return
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, probeConcurrency)
+	)
+	for _, n := range nm.Nodes() {
+		address := nodeAddress(n)
+		if address == "" {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			rtt, err := b.probe(ctx, address)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				b.log.Debug("node probe failed", zap.String("address", address), zap.Error(err))
+				rtt = b.cfg.Preference.ProbeTimeout
+			}
+			b.setRTT(address, rtt)
+		}()
+	}
+	wg.Wait()
+}
+
+func (b *Backend) setRTT(address string, rtt time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if old, ok := b.rtt[address]; ok {
+		rtt = time.Duration(float64(old)*(1-rttSmoothing) + float64(rtt)*rttSmoothing)
+	}
+	b.rtt[address] = rtt
+}
+
+// probeTCP measures the time of TCP connection establishment to the node.
+func (b *Backend) probeTCP(ctx context.Context, address string) (time.Duration, error) {
+	host, err := hostPort(address)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Preference.ProbeTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	start := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	_ = conn.Close()
+	return rtt, nil
+}
+
+// hostPort returns host:port of the node address, see discovery.Address.
+func hostPort(address string) (string, error) {
+	u, err := url.Parse(address)
+	if err == nil && u.Host != "" {
+		return u.Host, nil
+	}
+	if _, _, err = net.SplitHostPort(address); err != nil {
+		return "", fmt.Errorf("invalid node address '%s': %w", address, err)
+	}
+	return address, nil
+}
+
+// networkMap returns the cached network map, it's updated when the cache is
+// outdated.
+func (b *Backend) networkMap(ctx context.Context) (netmap.NetMap, error) {
+	b.mu.Lock()
+	if b.netmap != nil && time.Since(b.updated) <= b.cfg.NetmapTTL {
+		nm := *b.netmap
+		b.mu.Unlock()
+		return nm, nil
+	}
+	b.mu.Unlock()
+
+	nm, err := b.src.NetMapSnapshot(ctx, client.PrmNetMapSnapshot{})
+	if err != nil {
+		return netmap.NetMap{}, fmt.Errorf("get network map: %w", err)
+	}
+	b.mu.Lock()
+	b.setNetmap(nm)
+	b.mu.Unlock()
+	return nm, nil
+}
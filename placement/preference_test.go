@@ -0,0 +1,108 @@
+package placement
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPreference(t *testing.T) {
+	var (
+		nodes      []netmap.NodeInfo
+		candidates []candidate
+	)
+	for i, region := range []string{"EU", "US", "EU", "ASIA"} {
+		var n netmap.NodeInfo
+		n.SetPublicKey([]byte{byte(i)})
+		n.SetNetworkEndpoints("/dns4/node" + strconv.Itoa(i) + "/tcp/8080")
+		n.SetAttribute("Region", region)
+		n.SetOnline()
+		nodes = append(nodes, n)
+		candidates = append(candidates, candidate{address: nodeAddress(n), node: n})
+	}
+	src := &testNetmap{}
+	src.nm.SetNodes(nodes)
+
+	rtt := map[string]time.Duration{
+		"grpc://node0:8080": 30 * time.Millisecond,
+		"grpc://node1:8080": 10 * time.Millisecond,
+		"grpc://node2:8080": 20 * time.Millisecond,
+	}
+	newBackend := func(pref Preference) *Backend {
+		b := NewBackend(nil, src, Config{Preference: pref}, zap.NewNop())
+		b.probe = func(_ context.Context, address string) (time.Duration, error) {
+			if d, ok := rtt[address]; ok {
+				return d, nil
+			}
+			return 0, errors.New("unreachable")
+		}
+		return b
+	}
+	order := func(b *Backend) []string {
+		cs := make([]candidate, len(candidates))
+		copy(cs, candidates)
+		b.prefer(cs)
+		res := make([]string, len(cs))
+		for i := range cs {
+			res[i] = cs[i].address
+		}
+		return res
+	}
+
+	t.Run("none", func(t *testing.T) {
+		b := newBackend(Preference{Policy: PreferNone})
+		b.Run(context.Background()) // returns immediately
+		require.Empty(t, b.rtt)
+		require.Equal(t, []string{"grpc://node0:8080", "grpc://node1:8080", "grpc://node2:8080", "grpc://node3:8080"}, order(b))
+	})
+
+	t.Run("lowest rtt", func(t *testing.T) {
+		b := newBackend(Preference{Policy: PreferLowestRTT, ProbeTimeout: time.Second})
+		require.Equal(t, []string{"grpc://node0:8080", "grpc://node1:8080", "grpc://node2:8080", "grpc://node3:8080"}, order(b))
+
+		b.probeNodes(context.Background())
+		d, ok := b.RTT("grpc://node3:8080")
+		require.True(t, ok)
+		require.Equal(t, time.Second, d)
+		require.Equal(t, []string{"grpc://node1:8080", "grpc://node2:8080", "grpc://node0:8080", "grpc://node3:8080"}, order(b))
+
+		// RTT is smoothed
+		rtt["grpc://node1:8080"] = 110 * time.Millisecond
+		b.probeNodes(context.Background())
+		d, _ = b.RTT("grpc://node1:8080")
+		require.Equal(t, 40*time.Millisecond, d)
+		require.Equal(t, []string{"grpc://node2:8080", "grpc://node0:8080", "grpc://node1:8080", "grpc://node3:8080"}, order(b))
+		rtt["grpc://node1:8080"] = 10 * time.Millisecond
+	})
+
+	t.Run("attributes", func(t *testing.T) {
+		b := newBackend(Preference{Policy: PreferAttributes, Attributes: map[string]string{"region": "EU"}})
+		require.Equal(t, []string{"grpc://node0:8080", "grpc://node2:8080", "grpc://node1:8080", "grpc://node3:8080"}, order(b))
+
+		b.probeNodes(context.Background())
+		require.Equal(t, []string{"grpc://node2:8080", "grpc://node0:8080", "grpc://node1:8080", "grpc://node3:8080"}, order(b))
+	})
+
+	require.Error(t, CheckPolicy("fastest"))
+	require.NoError(t, CheckPolicy(PreferLowestRTT))
+}
+
+func TestHostPort(t *testing.T) {
+	for address, host := range map[string]string{
+		"grpc://node0:8080":  "node0:8080",
+		"grpcs://[::1]:8082": "[::1]:8082",
+		"node0:8080":         "node0:8080",
+	} {
+		res, err := hostPort(address)
+		require.NoError(t, err)
+		require.Equal(t, host, res)
+	}
+	_, err := hostPort("node0")
+	require.Error(t, err)
+}
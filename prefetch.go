@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	// prefetchUserAgent is the User-Agent of prefetch requests, it tells them
+	// from client ones in logs.
+	prefetchUserAgent = "neofs-http-gw-prefetch"
+
+	defaultPrefetchConcurrency = 4
+	prefetchConnectionCheck    = time.Second
+)
+
+// startPrefetch requests the configured paths in the background once the gate
+// is connected to NeoFS, so the caches are warm before the first visitors
+// come. Requests are served by gate routes like the client ones, so
+// everything they touch (containers, attribute search results, payloads) is
+// cached.
+func (a *app) startPrefetch(ctx context.Context) {
+	paths := a.cfg.GetStringSlice(cfgPrefetchPaths)
+	if len(paths) == 0 {
+		return
+	}
+	concurrency := a.cfg.GetInt(cfgPrefetchConcurrency)
+	if concurrency <= 0 {
+		concurrency = defaultPrefetchConcurrency
+	}
+
+	go func() {
+		ticker := time.NewTicker(prefetchConnectionCheck)
+		defer ticker.Stop()
+		for !a.poolConnected.Load() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+
+		start := time.Now()
+		ok := prefetch(ctx, a.log, a.routes, paths, concurrency)
+		a.log.Info("prefetch completed", zap.Int("paths", len(paths)), zap.Int("succeeded", ok),
+			zap.Duration("took", time.Since(start)))
+	}()
+}
+
+// prefetch executes GET requests of the paths with the handler reading the
+// responses to the end, it returns the number of successful requests. Paths
+// may be absolute URLs, their hosts select the networks served by them.
+func prefetch(ctx context.Context, log *zap.Logger, h fasthttp.RequestHandler, paths []string, concurrency int) int {
+	var (
+		wg  sync.WaitGroup
+		ok  atomic.Int32
+		sem = make(chan struct{}, concurrency)
+	)
+	for _, p := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(p string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if prefetchPath(log, h, p) {
+				ok.Add(1)
+			}
+		}(p)
+	}
+	wg.Wait()
+	return int(ok.Load())
+}
+
+func prefetchPath(log *zap.Logger, h fasthttp.RequestHandler, p string) bool {
+	var req fasthttp.Request
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.Header.SetUserAgent(prefetchUserAgent)
+	req.SetRequestURI(p)
+
+	var c fasthttp.RequestCtx
+	c.Init(&req, nil, nil)
+	h(&c)
+
+	// payloads are read to the end, so caches filled while streaming get them
+	// too
+	err := c.Response.BodyWriteTo(io.Discard)
+	status := c.Response.StatusCode()
+	c.Response.Reset()
+
+	if err != nil || status < fasthttp.StatusOK || status >= fasthttp.StatusMultipleChoices {
+		log.Warn("could not prefetch path", zap.String("path", p), zap.Int("status", status), zap.Error(err))
+		return false
+	}
+	log.Debug("path prefetched", zap.String("path", p))
+	return true
+}
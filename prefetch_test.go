@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestPrefetch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		streams []*closeRecorder
+		hosts   = make(map[string]string)
+	)
+	h := func(c *fasthttp.RequestCtx) {
+		require.Equal(t, prefetchUserAgent, string(c.UserAgent()))
+		mu.Lock()
+		defer mu.Unlock()
+		hosts[string(c.Path())] = string(c.Host())
+		if string(c.Path()) == "/missing" {
+			c.SetStatusCode(fasthttp.StatusNotFound)
+			return
+		}
+		r := &closeRecorder{Reader: strings.NewReader("payload")}
+		streams = append(streams, r)
+		c.SetBodyStream(r, -1)
+	}
+
+	ok := prefetch(context.Background(), zap.NewNop(), h,
+		[]string{"/get/index.html", "/missing", "https://site.example/style.css"}, 2)
+	require.Equal(t, 2, ok)
+	require.Equal(t, map[string]string{
+		"/get/index.html": "",
+		"/missing":        "",
+		"/style.css":      "site.example",
+	}, hosts)
+	for _, r := range streams {
+		require.True(t, r.closed)
+		require.Zero(t, r.Len(), "payload is read")
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Zero(t, prefetch(canceled, zap.NewNop(), h, []string{"/get/index.html"}, 1))
+}
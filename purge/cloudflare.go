@@ -0,0 +1,73 @@
+package purge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const cloudflareEndpoint = "https://api.cloudflare.com/client/v4"
+
+// Cloudflare purges content by cache tags via Cloudflare API.
+type Cloudflare struct {
+	endpoint string
+	zoneID   string
+	token    string
+	client   *http.Client
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewCloudflare is a constructor for the Cloudflare purger of the zone using
+// the API token.
+func NewCloudflare(zoneID, token string, timeout time.Duration) *Cloudflare {
+	return &Cloudflare{
+		endpoint: cloudflareEndpoint,
+		zoneID:   zoneID,
+		token:    token,
+		client:   newHTTPClient(timeout),
+	}
+}
+
+// Purge purges content tagged with any of the keys.
+func (c *Cloudflare) Purge(ctx context.Context, keys []string) error {
+	body, err := json.Marshal(map[string][]string{"tags": keys})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.endpoint+"/zones/"+url.PathEscape(c.zoneID)+"/purge_cache", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("purge request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res cloudflareResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("unexpected status %d: decode response: %w", resp.StatusCode, err)
+	}
+	if !res.Success {
+		if len(res.Errors) != 0 {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, res.Errors[0].Message)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
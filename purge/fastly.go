@@ -0,0 +1,56 @@
+package purge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const fastlyEndpoint = "https://api.fastly.com"
+
+// Fastly purges content by surrogate keys via Fastly API.
+type Fastly struct {
+	endpoint  string
+	serviceID string
+	token     string
+	client    *http.Client
+}
+
+// NewFastly is a constructor for the Fastly purger of the service using
+// the API token.
+func NewFastly(serviceID, token string, timeout time.Duration) *Fastly {
+	return &Fastly{
+		endpoint:  fastlyEndpoint,
+		serviceID: serviceID,
+		token:     token,
+		client:    newHTTPClient(timeout),
+	}
+}
+
+// Purge purges content tagged with any of the keys.
+func (f *Fastly) Purge(ctx context.Context, keys []string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		f.endpoint+"/service/"+url.PathEscape(f.serviceID)+"/purge", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Fastly-Key", f.token)
+	req.Header.Set("Surrogate-Key", strings.Join(keys, " "))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("purge request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package purge invalidates gateway responses cached by CDNs.
+package purge
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Names of supported CDN purgers.
+const (
+	FastlyPurgerName     = "fastly"
+	CloudflarePurgerName = "cloudflare"
+)
+
+// DefaultTimeout is a default timeout of purge requests to CDN APIs.
+const DefaultTimeout = 10 * time.Second
+
+// Purger invalidates cached responses tagged with the surrogate keys.
+type Purger interface {
+	Purge(ctx context.Context, keys []string) error
+}
+
+// Result is a result of purging by the named purger.
+type Result struct {
+	Purger string `json:"purger"`
+	Error  string `json:"error,omitempty"`
+}
+
+type namedPurger struct {
+	name string
+	Purger
+}
+
+// Purgers is a list of purgers applied one by one. Nil Purgers purge nothing.
+type Purgers struct {
+	purgers []namedPurger
+}
+
+// NewPurgers is a constructor for the Purgers.
+func NewPurgers() *Purgers {
+	return &Purgers{}
+}
+
+// Add appends the named purger to the list.
+func (p *Purgers) Add(name string, pr Purger) {
+	p.purgers = append(p.purgers, namedPurger{name: name, Purger: pr})
+}
+
+// Len returns the number of purgers in the list.
+func (p *Purgers) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.purgers)
+}
+
+// Purge invalidates keys with all purgers and returns their results, failure
+// of one purger doesn't prevent others from being called.
+func (p *Purgers) Purge(ctx context.Context, keys []string) []Result {
+	if p == nil {
+		return nil
+	}
+
+	res := make([]Result, 0, len(p.purgers))
+	for _, pr := range p.purgers {
+		r := Result{Purger: pr.name}
+		if err := pr.Purge(ctx, keys); err != nil {
+			r.Error = err.Error()
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
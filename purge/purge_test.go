@@ -0,0 +1,88 @@
+package purge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPurger struct {
+	keys []string
+	err  error
+}
+
+func (p *testPurger) Purge(_ context.Context, keys []string) error {
+	p.keys = keys
+	return p.err
+}
+
+func TestPurgers(t *testing.T) {
+	ok, failed := &testPurger{}, &testPurger{err: errors.New("failure")}
+
+	p := NewPurgers()
+	p.Add("failed", failed)
+	p.Add("ok", ok)
+	require.Equal(t, 2, p.Len())
+
+	res := p.Purge(context.Background(), []string{"a", "b"})
+	require.Equal(t, []Result{{Purger: "failed", Error: "failure"}, {Purger: "ok"}}, res)
+	require.Equal(t, []string{"a", "b"}, ok.keys)
+
+	var nilPurgers *Purgers
+	require.Zero(t, nilPurgers.Len())
+	require.Nil(t, nilPurgers.Purge(context.Background(), []string{"a"}))
+}
+
+func TestFastly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/service/service/purge", r.URL.Path)
+		require.Equal(t, "a b", r.Header.Get("Surrogate-Key"))
+		if r.Header.Get("Fastly-Key") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"msg":"Provided credentials are missing or invalid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	f := NewFastly("service", "token", 0)
+	f.endpoint = srv.URL
+	require.NoError(t, f.Purge(context.Background(), []string{"a", "b"}))
+
+	f = NewFastly("service", "invalid", 0)
+	f.endpoint = srv.URL
+	require.ErrorContains(t, f.Purge(context.Background(), []string{"a", "b"}), "unexpected status 401")
+}
+
+func TestCloudflare(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/zones/zone/purge_cache", r.URL.Path)
+
+		var req map[string][]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, []string{"a", "b"}, req["tags"])
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"success":false,"errors":[{"message":"Authentication error"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"errors":[]}`))
+	}))
+	defer srv.Close()
+
+	c := NewCloudflare("zone", "token", 0)
+	c.endpoint = srv.URL
+	require.NoError(t, c.Purge(context.Background(), []string{"a", "b"}))
+
+	c = NewCloudflare("zone", "invalid", 0)
+	c.endpoint = srv.URL
+	require.EqualError(t, c.Purge(context.Background(), []string{"a", "b"}), "unexpected status 403: Authentication error")
+}
@@ -0,0 +1,64 @@
+// Package qr renders QR codes (ISO/IEC 18004) encoded by rsc.io/qr, it's used
+// to share object links.
+package qr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	"rsc.io/qr"
+)
+
+// Code is the encoded QR code.
+type Code struct {
+	// Size is the number of modules on the side of the code.
+	Size int
+	code *qr.Code
+}
+
+// Black checks whether the module at the given coordinates is dark.
+func (c *Code) Black(x, y int) bool {
+	return c.code.Black(x, y)
+}
+
+// quietZone is the width of the light border around the code in modules.
+const quietZone = 4
+
+// Image returns the image of the code with the quiet zone, every module is
+// scale pixels wide.
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	side := (c.Size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Black(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[((y+quietZone)*scale+dy)*img.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[(x+quietZone)*scale+dx] = 1
+				}
+			}
+		}
+	}
+	return img
+}
+
+// ErrTooLong is returned if the data doesn't fit in the largest code.
+var ErrTooLong = errors.New("data is too long for QR code")
+
+// Encode encodes data using the smallest code fitting it. Medium error
+// correction level is used, it restores ~15% of the code.
+func Encode(data []byte) (*Code, error) {
+	code, err := qr.Encode(string(data), qr.M)
+	if err != nil {
+		// the level is valid, so the data doesn't fit only
+		return nil, ErrTooLong
+	}
+	return &Code{Size: code.Size, code: code}, nil
+}
@@ -0,0 +1,54 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		size int
+	}{
+		{data: "", size: 21},
+		{data: "meow", size: 21},
+		{data: "https://gate.example.com/get/2m8uEnYAvwm8cYmtbeTvS6aJbDVYBKBm8VAqPQTxKuCC/8M4XhGdpz8gadcrBVLmFs1LNyKTfY9PJzAnLsWfN7fW4", size: 45},
+		{data: strings.Repeat("a", 100), size: 41},
+		{data: strings.Repeat("z", 2331), size: 177},
+	} {
+		c, err := Encode([]byte(tc.data))
+		require.NoError(t, err)
+		require.Equal(t, tc.size, c.Size, tc.data)
+
+		// finder pattern corners
+		for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+			require.True(t, c.Black(corner[0], corner[1]))
+			require.True(t, c.Black(corner[0]+6, corner[1]+6))
+			require.False(t, c.Black(corner[0]+1, corner[1]+1))
+			require.True(t, c.Black(corner[0]+3, corner[1]+3))
+		}
+	}
+
+	_, err := Encode(make([]byte, 2332))
+	require.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestCodeImage(t *testing.T) {
+	c, err := Encode([]byte("meow"))
+	require.NoError(t, err)
+
+	img := c.Image(3)
+	require.Equal(t, (21+8)*3, img.Bounds().Dx())
+	// finder pattern corner after the quiet zone
+	r, _, _, _ := img.At(4*3, 4*3).RGBA()
+	require.Zero(t, r)
+	r, _, _, _ = img.At(4*3-1, 4*3-1).RGBA()
+	require.NotZero(t, r)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+}
@@ -0,0 +1,165 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+// Resolver names used in the resolving order.
+const (
+	NNSResolverName = "nns"
+	DNSResolverName = "dns"
+)
+
+// aliasesResolverName is a name of static aliases resolver in the chain.
+const aliasesResolverName = "aliases"
+
+type namedResolver struct {
+	name string
+	Resolver
+}
+
+// Chain tries resolvers one by one until the name is resolved.
+type Chain struct {
+	resolvers []namedResolver
+}
+
+// NewChain is a constructor for the Chain.
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Add appends the resolver to the end of the chain. Errors of the resolver
+// are prefixed with its name if it's not empty.
+func (c *Chain) Add(name string, r Resolver) {
+	c.resolvers = append(c.resolvers, namedResolver{name: name, Resolver: r})
+}
+
+// Len returns the number of resolvers in the chain.
+func (c *Chain) Len() int {
+	return len(c.resolvers)
+}
+
+// Resolve returns the result of the first resolver which knows the name.
+// [ErrNotFound] is returned only if all resolvers don't know the name,
+// otherwise the first resolver failure matching [ErrUnavailable] is returned.
+func (c *Chain) Resolve(ctx context.Context, name string) (cid.ID, error) {
+	var firstErr error
+
+	for _, r := range c.resolvers {
+		res, err := r.Resolve(ctx, name)
+		if err == nil {
+			return res, nil
+		}
+		if firstErr == nil && !errors.Is(err, ErrNotFound) {
+			firstErr = err
+			if r.name != "" {
+				firstErr = fmt.Errorf("%s: %w", r.name, err)
+			}
+		}
+	}
+
+	if firstErr != nil {
+		if errors.Is(firstErr, ErrUnavailable) {
+			return cid.ID{}, firstErr
+		}
+		return cid.ID{}, unavailableError{err: firstErr}
+	}
+
+	return cid.ID{}, ErrNotFound
+}
+
+type cacheEntry struct {
+	id       cid.ID
+	notFound bool
+	expires  time.Time
+}
+
+// CachingResolver caches results of successful resolving for the given time.
+// Unknown names are cached for the negative TTL, resolver failures aren't
+// cached.
+type CachingResolver struct {
+	resolver    Resolver
+	ttl         time.Duration
+	negativeTTL time.Duration
+	size        int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCachingResolver is a constructor for the CachingResolver. Size limits
+// the number of cached names, zero negative TTL disables caching of unknown
+// names.
+func NewCachingResolver(r Resolver, ttl, negativeTTL time.Duration, size int) *CachingResolver {
+	return &CachingResolver{
+		resolver:    r,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		size:        size,
+		entries:     make(map[string]cacheEntry),
+	}
+}
+
+// Resolve returns cached container ID or resolves the name.
+func (r *CachingResolver) Resolve(ctx context.Context, name string) (cid.ID, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	entry, ok := r.entries[name]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		if entry.notFound {
+			return cid.ID{}, ErrNotFound
+		}
+		return entry.id, nil
+	}
+
+	id, err := r.resolver.Resolve(ctx, name)
+	switch {
+	case err == nil:
+		r.store(name, cacheEntry{id: id, expires: now.Add(r.ttl)}, now)
+	case errors.Is(err, ErrNotFound) && r.negativeTTL > 0:
+		r.store(name, cacheEntry{notFound: true, expires: now.Add(r.negativeTTL)}, now)
+	}
+
+	return id, err
+}
+
+func (r *CachingResolver) store(name string, entry cacheEntry, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) >= r.size {
+		r.evict(now)
+	}
+	if len(r.entries) < r.size {
+		r.entries[name] = entry
+	}
+}
+
+// evict removes expired entries, if there are none, the entry expiring
+// first is removed.
+func (r *CachingResolver) evict(now time.Time) {
+	var (
+		oldestName string
+		oldest     time.Time
+	)
+	for name, e := range r.entries {
+		if !now.Before(e.expires) {
+			delete(r.entries, name)
+			continue
+		}
+		if oldestName == "" || e.expires.Before(oldest) {
+			oldestName, oldest = name, e.expires
+		}
+	}
+	if len(r.entries) >= r.size && oldestName != "" {
+		delete(r.entries, oldestName)
+	}
+}
@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+// dnsRecordPrefix is a prefix of TXT records with container ID.
+const dnsRecordPrefix = "cid="
+
+// DNSResolver allows to resolve container id by its name via DNS TXT records
+// like `container.example.com TXT "cid=<container ID>"`.
+type DNSResolver struct {
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+}
+
+// NewDNSResolver is a constructor for the DNSResolver. If server is not empty,
+// DNS requests are sent to it instead of the system resolver.
+func NewDNSResolver(server string) *DNSResolver {
+	r := net.DefaultResolver
+	if server != "" {
+		var d net.Dialer
+		r = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return &DNSResolver{lookupTXT: r.LookupTXT}
+}
+
+// Resolve looks up the container id by its name via DNS TXT records.
+func (r *DNSResolver) Resolve(ctx context.Context, name string) (cid.ID, error) {
+	var result cid.ID
+
+	records, err := r.lookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return result, ErrNotFound
+		}
+		return result, fmt.Errorf("dns lookup: %w", err)
+	}
+
+	return parseDNSRecords(records)
+}
+
+// parseDNSRecords returns container ID from the first TXT record with it.
+func parseDNSRecords(records []string) (cid.ID, error) {
+	var result cid.ID
+
+	for _, rec := range records {
+		rec = strings.TrimSpace(rec)
+		if !strings.HasPrefix(rec, dnsRecordPrefix) {
+			continue
+		}
+		if err := result.DecodeString(strings.TrimPrefix(rec, dnsRecordPrefix)); err != nil {
+			return result, fmt.Errorf("id: %w", err)
+		}
+		return result, nil
+	}
+
+	return result, ErrNotFound
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/invoker"
@@ -16,6 +17,26 @@ const (
 	nnsContract = int32(1)
 )
 
+// Config is a configuration of container name resolvers.
+type Config struct {
+	// RPCEndpoint is a Neo RPC node address used by NNS resolver.
+	RPCEndpoint string
+	// Order is a list of resolvers names ("nns", "dns") to try one by one.
+	Order []string
+	// DNSServer is a DNS server address, system resolver is used if empty.
+	DNSServer string
+	// CacheTTL is a time resolved names are cached for, zero disables caching.
+	CacheTTL time.Duration
+	// NegativeCacheTTL is a time unknown names are cached for, zero disables
+	// caching of unknown names.
+	NegativeCacheTTL time.Duration
+	// CacheSize limits the number of cached names.
+	CacheSize int
+	// Aliases map names to container IDs, they are resolved before other
+	// resolvers and aren't cached.
+	Aliases map[string]string
+}
+
 // Container is a wrapper for the [Resolver]. It allows to update resolvers in runtime, without service restarting.
 //
 // The Container should be used like regular [Resolver].
@@ -34,8 +55,8 @@ func (r *Container) Resolve(ctx context.Context, name string) (cid.ID, error) {
 }
 
 // UpdateResolvers allows to update resolver in runtime. Resolvers will be created from scratch.
-func (r *Container) UpdateResolvers(ctx context.Context, endpoint string) error {
-	newResolver, err := NewResolver(ctx, endpoint)
+func (r *Container) UpdateResolvers(ctx context.Context, cfg Config) error {
+	newResolver, err := NewResolver(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("resolver reinit: %w", err)
 	}
@@ -48,8 +69,8 @@ func (r *Container) UpdateResolvers(ctx context.Context, endpoint string) error
 }
 
 // NewContainer is a constructor for the [Container].
-func NewContainer(ctx context.Context, endpoint string) (*Container, error) {
-	newResolver, err := NewResolver(ctx, endpoint)
+func NewContainer(ctx context.Context, cfg Config) (*Container, error) {
+	newResolver, err := NewResolver(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("resolver reinit: %w", err)
 	}
@@ -59,14 +80,67 @@ func NewContainer(ctx context.Context, endpoint string) (*Container, error) {
 	}, nil
 }
 
-// NewResolver returns resolver depending on corresponding endpoint.
+// NewResolver returns a chain of resolvers in the configured order preceded
+// by static aliases. NNS resolver is skipped if RPC endpoint is empty.
 //
-// If endpoint is empty, [NoOpResolver] will be returned.
-func NewResolver(ctx context.Context, endpoint string) (Resolver, error) {
-	if endpoint == "" {
+// If there are no resolvers, [NoOpResolver] will be returned.
+func NewResolver(ctx context.Context, cfg Config) (Resolver, error) {
+	dynamic, err := newDynamicResolver(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Aliases) == 0 {
+		return dynamic, nil
+	}
+
+	static, err := NewStaticResolver(cfg.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := NewChain()
+	chain.Add(aliasesResolverName, static)
+	if _, ok := dynamic.(*NoOpResolver); !ok {
+		chain.Add("", dynamic)
+	}
+
+	return chain, nil
+}
+
+func newDynamicResolver(ctx context.Context, cfg Config) (Resolver, error) {
+	chain := NewChain()
+
+	for _, name := range cfg.Order {
+		switch name {
+		case NNSResolverName:
+			if cfg.RPCEndpoint == "" {
+				continue
+			}
+			nns, err := newNNSResolver(ctx, cfg.RPCEndpoint)
+			if err != nil {
+				return nil, err
+			}
+			chain.Add(name, nns)
+		case DNSResolverName:
+			chain.Add(name, NewDNSResolver(cfg.DNSServer))
+		default:
+			return nil, fmt.Errorf("unknown resolver '%s'", name)
+		}
+	}
+
+	if chain.Len() == 0 {
 		return NewNoOpResolver(), nil
 	}
 
+	if cfg.CacheTTL > 0 && cfg.CacheSize > 0 {
+		return NewCachingResolver(chain, cfg.CacheTTL, cfg.NegativeCacheTTL, cfg.CacheSize), nil
+	}
+
+	return chain, nil
+}
+
+func newNNSResolver(ctx context.Context, endpoint string) (*NNSResolver, error) {
 	cl, err := rpcClient(ctx, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("rpcclient: %w", err)
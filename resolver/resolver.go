@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	rpcNNS "github.com/nspcc-dev/neofs-contract/rpc/nns"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
@@ -14,8 +15,28 @@ const defaultZone = "container"
 var (
 	// ErrNotFound shows the container not found by name.
 	ErrNotFound = errors.New("not found")
+	// ErrUnavailable shows the name can't be resolved because of resolver
+	// failure, the request can be retried later.
+	ErrUnavailable = errors.New("resolver unavailable")
 )
 
+// unavailableError wraps resolver failure, it matches [ErrUnavailable].
+type unavailableError struct {
+	err error
+}
+
+func (e unavailableError) Error() string {
+	return ErrUnavailable.Error() + ": " + e.err.Error()
+}
+
+func (e unavailableError) Unwrap() error {
+	return e.err
+}
+
+func (e unavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
 // Resolver allows to map container ID by container name.
 type Resolver interface {
 	Resolve(ctx context.Context, containerName string) (cid.ID, error)
@@ -37,6 +58,9 @@ func (r *NNSResolver) Resolve(_ context.Context, name string) (cid.ID, error) {
 
 	items, err := r.reader.GetRecords(nnsContainerDomain(name), rpcNNS.TXT)
 	if err != nil {
+		if isNNSNotFound(err) {
+			return result, ErrNotFound
+		}
 		return result, fmt.Errorf("nns get: %w", err)
 	}
 
@@ -51,6 +75,22 @@ func (r *NNSResolver) Resolve(_ context.Context, name string) (cid.ID, error) {
 	return result, nil
 }
 
+// nnsNotFoundFaults are NNS contract exceptions thrown for names that aren't
+// registered or have expired.
+var nnsNotFoundFaults = []string{"token not found", "parent domain has expired"}
+
+// isNNSNotFound checks whether the invocation failed because the name is
+// unknown to NNS rather than because of RPC or contract failure.
+func isNNSNotFound(err error) bool {
+	msg := err.Error()
+	for _, fault := range nnsNotFoundFaults {
+		if strings.Contains(msg, fault) {
+			return true
+		}
+	}
+	return false
+}
+
 func nnsContainerDomain(name string) string {
 	return fmt.Sprintf("%s.%s", name, defaultZone)
 }
@@ -68,3 +108,34 @@ func NewNoOpResolver() *NoOpResolver {
 func (r *NoOpResolver) Resolve(_ context.Context, _ string) (cid.ID, error) {
 	return cid.ID{}, ErrNotFound
 }
+
+// StaticResolver resolves container names using the predefined aliases.
+// Names are case-insensitive.
+type StaticResolver struct {
+	aliases map[string]cid.ID
+}
+
+// NewStaticResolver is a constructor for the StaticResolver. Aliases map
+// names to encoded container IDs.
+func NewStaticResolver(aliases map[string]string) (*StaticResolver, error) {
+	r := &StaticResolver{aliases: make(map[string]cid.ID, len(aliases))}
+
+	for name, val := range aliases {
+		var id cid.ID
+		if err := id.DecodeString(val); err != nil {
+			return nil, fmt.Errorf("alias '%s': invalid container id: %w", name, err)
+		}
+		r.aliases[strings.ToLower(name)] = id
+	}
+
+	return r, nil
+}
+
+// Resolve looks up the container id by its alias.
+func (r *StaticResolver) Resolve(_ context.Context, name string) (cid.ID, error) {
+	id, ok := r.aliases[strings.ToLower(name)]
+	if !ok {
+		return cid.ID{}, ErrNotFound
+	}
+	return id, nil
+}
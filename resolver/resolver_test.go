@@ -0,0 +1,297 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/neorpc/result"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/nep11"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/nspcc-dev/neo-go/pkg/vm/vmstate"
+	rpcNNS "github.com/nspcc-dev/neofs-contract/rpc/nns"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+type testResolver struct {
+	names map[string]cid.ID
+	err   error
+	calls int
+}
+
+func (r *testResolver) Resolve(_ context.Context, name string) (cid.ID, error) {
+	r.calls++
+	if r.err != nil {
+		return cid.ID{}, r.err
+	}
+	id, ok := r.names[name]
+	if !ok {
+		return cid.ID{}, ErrNotFound
+	}
+	return id, nil
+}
+
+func TestChain(t *testing.T) {
+	first, second := cidtest.ID(), cidtest.ID()
+	errFailure := errors.New("failure")
+
+	t.Run("order", func(t *testing.T) {
+		chain := NewChain()
+		chain.Add("first", &testResolver{names: map[string]cid.ID{"a": first}})
+		chain.Add("second", &testResolver{names: map[string]cid.ID{"a": second, "b": second}})
+
+		id, err := chain.Resolve(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, first, id)
+
+		id, err = chain.Resolve(context.Background(), "b")
+		require.NoError(t, err)
+		require.Equal(t, second, id)
+
+		_, err = chain.Resolve(context.Background(), "c")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		chain := NewChain()
+		chain.Add("first", &testResolver{err: errFailure})
+		chain.Add("second", &testResolver{names: map[string]cid.ID{"a": second}})
+
+		id, err := chain.Resolve(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, second, id)
+
+		_, err = chain.Resolve(context.Background(), "b")
+		require.ErrorIs(t, err, errFailure)
+		require.ErrorIs(t, err, ErrUnavailable)
+		require.NotErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		inner := NewChain()
+		inner.Add("inner", &testResolver{err: errFailure})
+		chain := NewChain()
+		chain.Add("", inner)
+
+		_, err := chain.Resolve(context.Background(), "a")
+		require.ErrorIs(t, err, errFailure)
+		require.ErrorIs(t, err, ErrUnavailable)
+		require.Equal(t, "resolver unavailable: inner: failure", err.Error())
+	})
+}
+
+func TestCachingResolver(t *testing.T) {
+	a, b := cidtest.ID(), cidtest.ID()
+
+	t.Run("cached", func(t *testing.T) {
+		inner := &testResolver{names: map[string]cid.ID{"a": a}}
+		r := NewCachingResolver(inner, time.Hour, 0, 10)
+
+		for i := 0; i < 2; i++ {
+			id, err := r.Resolve(context.Background(), "a")
+			require.NoError(t, err)
+			require.Equal(t, a, id)
+		}
+		require.Equal(t, 1, inner.calls)
+
+		_, err := r.Resolve(context.Background(), "b")
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = r.Resolve(context.Background(), "b")
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Equal(t, 3, inner.calls)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		inner := &testResolver{names: map[string]cid.ID{}}
+		r := NewCachingResolver(inner, time.Hour, time.Hour, 10)
+
+		for i := 0; i < 2; i++ {
+			_, err := r.Resolve(context.Background(), "a")
+			require.ErrorIs(t, err, ErrNotFound)
+		}
+		require.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("failure not cached", func(t *testing.T) {
+		inner := &testResolver{err: unavailableError{err: errors.New("failure")}}
+		r := NewCachingResolver(inner, time.Hour, time.Hour, 10)
+
+		for i := 0; i < 2; i++ {
+			_, err := r.Resolve(context.Background(), "a")
+			require.ErrorIs(t, err, ErrUnavailable)
+		}
+		require.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		inner := &testResolver{names: map[string]cid.ID{"a": a}}
+		r := NewCachingResolver(inner, time.Nanosecond, 0, 10)
+
+		_, err := r.Resolve(context.Background(), "a")
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		_, err = r.Resolve(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("size", func(t *testing.T) {
+		inner := &testResolver{names: map[string]cid.ID{"a": a, "b": b}}
+		r := NewCachingResolver(inner, time.Hour, 0, 1)
+
+		_, err := r.Resolve(context.Background(), "a")
+		require.NoError(t, err)
+		_, err = r.Resolve(context.Background(), "b")
+		require.NoError(t, err)
+		require.Len(t, r.entries, 1)
+		require.Contains(t, r.entries, "b")
+	})
+}
+
+func TestDNSResolver(t *testing.T) {
+	id := cidtest.ID()
+
+	for _, tc := range []struct {
+		name    string
+		records []string
+		err     error
+		res     *cid.ID
+		notFnd  bool
+		failure bool
+	}{
+		{name: "valid", records: []string{"v=spf1 -all", "cid=" + id.EncodeToString()}, res: &id},
+		{name: "spaces", records: []string{" cid=" + id.EncodeToString() + " "}, res: &id},
+		{name: "no record", records: []string{"v=spf1 -all"}, notFnd: true},
+		{name: "invalid id", records: []string{"cid=invalid"}, failure: true},
+		{name: "no domain", err: &net.DNSError{Err: "no such host", IsNotFound: true}, notFnd: true},
+		{name: "lookup failure", err: &net.DNSError{Err: "timeout", IsTimeout: true}, failure: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &DNSResolver{lookupTXT: func(context.Context, string) ([]string, error) {
+				return tc.records, tc.err
+			}}
+
+			res, err := r.Resolve(context.Background(), "container.example.com")
+			switch {
+			case tc.notFnd:
+				require.ErrorIs(t, err, ErrNotFound)
+			case tc.failure:
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrNotFound)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, *tc.res, res)
+			}
+		})
+	}
+}
+
+func TestStaticResolver(t *testing.T) {
+	id := cidtest.ID()
+
+	r, err := NewStaticResolver(map[string]string{"Photos": id.EncodeToString()})
+	require.NoError(t, err)
+
+	res, err := r.Resolve(context.Background(), "photos")
+	require.NoError(t, err)
+	require.Equal(t, id, res)
+
+	res, err = r.Resolve(context.Background(), "PHOTOS")
+	require.NoError(t, err)
+	require.Equal(t, id, res)
+
+	_, err = r.Resolve(context.Background(), "docs")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewStaticResolver(map[string]string{"docs": "invalid"})
+	require.Error(t, err)
+}
+
+func TestNewResolver(t *testing.T) {
+	r, err := NewResolver(context.Background(), Config{Order: []string{NNSResolverName}})
+	require.NoError(t, err)
+	require.IsType(t, &NoOpResolver{}, r)
+
+	r, err = NewResolver(context.Background(), Config{Order: []string{NNSResolverName, DNSResolverName}})
+	require.NoError(t, err)
+	require.IsType(t, &Chain{}, r)
+
+	r, err = NewResolver(context.Background(), Config{Order: []string{DNSResolverName}, CacheTTL: time.Minute, CacheSize: 10})
+	require.NoError(t, err)
+	require.IsType(t, &CachingResolver{}, r)
+
+	_, err = NewResolver(context.Background(), Config{Order: []string{"unknown"}})
+	require.Error(t, err)
+
+	id := cidtest.ID()
+	r, err = NewResolver(context.Background(), Config{
+		Order:   []string{NNSResolverName},
+		Aliases: map[string]string{"photos": id.EncodeToString()},
+	})
+	require.NoError(t, err)
+	res, err := r.Resolve(context.Background(), "photos")
+	require.NoError(t, err)
+	require.Equal(t, id, res)
+	_, err = r.Resolve(context.Background(), "docs")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewResolver(context.Background(), Config{Aliases: map[string]string{"photos": "invalid"}})
+	require.Error(t, err)
+}
+
+type testInvoker struct {
+	nep11.Invoker
+	res *result.Invoke
+	err error
+}
+
+func (i testInvoker) Call(util.Uint160, string, ...any) (*result.Invoke, error) {
+	return i.res, i.err
+}
+
+func TestNNSResolver(t *testing.T) {
+	id := cidtest.ID()
+
+	for _, tc := range []struct {
+		name    string
+		res     *result.Invoke
+		err     error
+		id      *cid.ID
+		notFnd  bool
+		failure bool
+	}{
+		{name: "valid", res: &result.Invoke{State: vmstate.Halt.String(), Stack: []stackitem.Item{
+			stackitem.NewArray([]stackitem.Item{stackitem.Make(id.EncodeToString())}),
+		}}, id: &id},
+		{name: "no records", res: &result.Invoke{State: vmstate.Halt.String(), Stack: []stackitem.Item{
+			stackitem.NewArray(nil),
+		}}, notFnd: true},
+		{name: "unknown name", res: &result.Invoke{State: vmstate.Fault.String(),
+			FaultException: `at instruction 1105 (THROW): unhandled exception: "token not found"`}, notFnd: true},
+		{name: "expired name", res: &result.Invoke{State: vmstate.Fault.String(),
+			FaultException: `at instruction 1105 (THROW): unhandled exception: "parent domain has expired"`}, notFnd: true},
+		{name: "other fault", res: &result.Invoke{State: vmstate.Fault.String(),
+			FaultException: "gas limit exceeded"}, failure: true},
+		{name: "rpc failure", err: errors.New("connection refused"), failure: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewNNSResolver(rpcNNS.NewReader(testInvoker{res: tc.res, err: tc.err}, util.Uint160{}))
+			res, err := r.Resolve(context.Background(), "name")
+			switch {
+			case tc.notFnd:
+				require.ErrorIs(t, err, ErrNotFound)
+			case tc.failure:
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrNotFound)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, *tc.id, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborEncMode encodes values in the core deterministic encoding (RFC 8949,
+// section 4.2.1), so map keys are sorted and the result is reproducible.
+var cborEncMode = func() cbor.EncMode {
+	em, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}()
+
+// marshalCBOR encodes the JSON value decoded with json.Decoder.UseNumber.
+func marshalCBOR(v any) ([]byte, error) {
+	v, err := cborNumbers(v)
+	if err != nil {
+		return nil, err
+	}
+	return cborEncMode.Marshal(v)
+}
+
+// cborNumbers converts json.Number values to integers if they fit, to floats
+// otherwise, so they aren't encoded as strings.
+func cborNumbers(v any) (any, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u, nil
+		}
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s': %w", v, err)
+		}
+		return f, nil
+	case []any:
+		for i := range v {
+			var err error
+			if v[i], err = cborNumbers(v[i]); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]any:
+		for k := range v {
+			var err error
+			if v[k], err = cborNumbers(v[k]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
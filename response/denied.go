@@ -0,0 +1,89 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/valyala/fasthttp"
+)
+
+// ErrCodeStorageAccessDenied is the error code of requests denied by storage
+// nodes, i.e. by container basic ACL, eACL or bearer token rules.
+const ErrCodeStorageAccessDenied = "STORAGE_ACCESS_DENIED"
+
+// Remediation hints of access denials.
+const (
+	hintBearer = "check that the bearer token is issued by the container owner, isn't expired, " +
+		"is bound to the gate (or to no one) and its eACL allows the operation"
+	hintAnonymous = "the container ACL denies the operation to anonymous users, " +
+		"allow it in the container eACL or send a bearer token granting it"
+)
+
+// AccessDenial describes the operation denied by storage nodes.
+type AccessDenial struct {
+	Error       string `json:"error"`
+	Operation   string `json:"operation"`
+	Container   string `json:"container,omitempty"`
+	BearerToken bool   `json:"bearer_token"`
+	Issuer      string `json:"issuer,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	Hint        string `json:"hint"`
+}
+
+// StorageError writes the response to the request failed because of the
+// storage error. Access denials are answered with 403 and JSON description of
+// the denial (see AccessDenial), other errors with the message followed by the
+// error and the status given.
+func StorageError(c *fasthttp.RequestCtx, op eacl.Operation, msg string, err error, status int) {
+	if !errors.Is(err, apistatus.ErrObjectAccessDenied) {
+		Error(c, msg+": "+err.Error(), status)
+		return
+	}
+
+	d := AccessDenial{
+		Error:     msg + ": access denied",
+		Operation: op.EncodeToString(),
+		Reason:    denialReason(err),
+		Hint:      hintAnonymous,
+	}
+	d.Container, _ = c.UserValue("cid").(string)
+	if btoken, err := tokens.LoadBearerToken(c); err == nil {
+		d.BearerToken = true
+		d.Issuer = btoken.ResolveIssuer().EncodeToString()
+		d.Hint = hintBearer
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		Error(c, msg+": access denied", fasthttp.StatusForbidden)
+		return
+	}
+	c.Response.Reset()
+	c.Response.Header.SetContentType("application/json")
+	c.Response.Header.Set(HeaderErrorCode, ErrCodeStorageAccessDenied)
+	c.Response.SetStatusCode(fasthttp.StatusForbidden)
+	c.Response.SetBodyRaw(append(data, '\n'))
+}
+
+// denialReason returns the reason of the access denial sent by storage node.
+// Statuses received from nodes are pointers, the ones made locally are values.
+func denialReason(err error) string {
+	var p *apistatus.ObjectAccessDenied
+	if errors.As(err, &p) && p != nil {
+		return p.Reason()
+	}
+	var v apistatus.ObjectAccessDenied
+	if errors.As(err, &v) {
+		return v.Reason()
+	}
+	return ""
+}
+
+// IsStorageAccessDenied checks whether the response is written by StorageError
+// for the access denial.
+func IsStorageAccessDenied(c *fasthttp.RequestCtx) bool {
+	return string(c.Response.Header.Peek(HeaderErrorCode)) == ErrCodeStorageAccessDenied
+}
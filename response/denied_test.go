@@ -0,0 +1,68 @@
+package response
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStorageError(t *testing.T) {
+	denied := new(apistatus.ObjectAccessDenied)
+	denied.WriteReason("eACL deny rule")
+	wrapped := fmt.Errorf("init reading: %w", denied)
+
+	write := func(err error, btoken *bearer.Token) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("cid", "container")
+		if btoken != nil {
+			c.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+base64.StdEncoding.EncodeToString(btoken.Marshal()))
+		}
+		require.NoError(t, tokens.StoreBearerToken(&c))
+		StorageError(&c, eacl.OperationGet, "could not receive object", err, fasthttp.StatusBadRequest)
+		return &c
+	}
+
+	c := write(errors.New("oops"), nil)
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	require.Equal(t, "could not receive object: oops\n", string(c.Response.Body()))
+	require.False(t, IsStorageAccessDenied(c))
+
+	c = write(wrapped, nil)
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+	require.True(t, IsStorageAccessDenied(c))
+	var d AccessDenial
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &d))
+	require.Equal(t, AccessDenial{
+		Error:     "could not receive object: access denied",
+		Operation: "GET",
+		Container: "container",
+		Reason:    "eACL deny rule",
+		Hint:      hintAnonymous,
+	}, d)
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	issuer := user.NewAutoIDSigner(key.PrivateKey)
+	var btoken bearer.Token
+	require.NoError(t, btoken.Sign(issuer))
+
+	c = write(apistatus.ErrObjectAccessDenied, &btoken)
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+	d = AccessDenial{}
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &d))
+	require.True(t, d.BearerToken)
+	require.Equal(t, issuer.UserID().EncodeToString(), d.Issuer)
+	require.Equal(t, hintBearer, d.Hint)
+	require.Empty(t, d.Reason)
+}
@@ -0,0 +1,203 @@
+package response
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Format is an encoding of metadata responses negotiated by Accept header.
+type Format int
+
+// Supported formats, JSON is used if no other format is acceptable.
+const (
+	FormatJSON Format = iota
+	FormatCBOR
+	FormatProtobuf
+)
+
+// Media types of the supported formats.
+const (
+	MIMEJSON     = "application/json"
+	MIMECBOR     = "application/cbor"
+	MIMEProtobuf = "application/x-protobuf"
+
+	mimeProtobufAlias = "application/protobuf"
+)
+
+// ProtoValue is a response value having protobuf encoding.
+type ProtoValue interface {
+	// Proto returns the protobuf message of the value.
+	Proto() proto.Message
+}
+
+// ContentType returns Content-Type of the single value encoded in the format,
+// the value defines protobuf message type.
+func (f Format) ContentType(v any) string {
+	switch f {
+	case FormatCBOR:
+		return MIMECBOR
+	case FormatProtobuf:
+		return MIMEProtobuf + "; messageType=" + protoMessageName(v)
+	default:
+		return MIMEJSON + "; charset=UTF-8"
+	}
+}
+
+// SeqContentType returns Content-Type of the stream of values like the given
+// one encoded in the format: NDJSON, CBOR sequence or length-delimited
+// protobuf messages.
+func (f Format) SeqContentType(v any) string {
+	switch f {
+	case FormatCBOR:
+		return "application/cbor-seq"
+	case FormatProtobuf:
+		return MIMEProtobuf + "; messageType=" + protoMessageName(v) + "; delimited=true"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+func protoMessageName(v any) string {
+	if pv, ok := v.(ProtoValue); ok {
+		return string(proto.MessageName(pv.Proto()))
+	}
+	return ""
+}
+
+// Negotiate returns the most preferred supported format from Accept header.
+func Negotiate(c *fasthttp.RequestCtx) Format {
+	return negotiate(string(c.Request.Header.Peek(fasthttp.HeaderAccept)))
+}
+
+func negotiate(accept string) Format {
+	type candidate struct {
+		format Format
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		q := 1.0
+		for _, p := range params[1:] {
+			if k, v, ok := strings.Cut(strings.TrimSpace(p), "="); ok && k == "q" {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(params[0])) {
+		case MIMECBOR:
+			candidates = append(candidates, candidate{format: FormatCBOR, q: q})
+		case MIMEProtobuf, mimeProtobufAlias:
+			candidates = append(candidates, candidate{format: FormatProtobuf, q: q})
+		case MIMEJSON, "application/*", "*/*":
+			candidates = append(candidates, candidate{format: FormatJSON, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return FormatJSON
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].format
+}
+
+// Marshal encodes the value in the format. CBOR encoding has the same
+// structure as JSON one, protobuf encoding is available for ProtoValue only.
+func Marshal(f Format, v any) ([]byte, error) {
+	if f == FormatJSON {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	if f == FormatProtobuf {
+		pv, ok := v.(ProtoValue)
+		if !ok {
+			return nil, fmt.Errorf("no protobuf encoding of %T", v)
+		}
+		return proto.MarshalOptions{Deterministic: true}.Marshal(pv.Proto())
+	}
+
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return marshalCBOR(generic)
+}
+
+// toGeneric converts the value to the generic JSON representation, so it's
+// encoded with the same field names in every format.
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var res any
+	if err = dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Write writes the value encoded in the format negotiated by Accept header.
+func Write(c *fasthttp.RequestCtx, code int, v any) {
+	f := Negotiate(c)
+	data, err := Marshal(f, v)
+	if err != nil {
+		Error(c, "could not encode response: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	c.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAccept)
+	c.Response.Header.SetContentType(f.ContentType(v))
+	c.Response.SetStatusCode(code)
+	c.Response.SetBodyRaw(data)
+}
+
+// WriteSeq writes the value as an element of the stream in the format.
+func WriteSeq(w io.Writer, f Format, v any) error {
+	data, err := Marshal(f, v)
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case FormatJSON:
+		// NDJSON requires the value on a single line
+		var buf bytes.Buffer
+		if err = json.Compact(&buf, data); err != nil {
+			return err
+		}
+		data = append(buf.Bytes(), '\n')
+	case FormatProtobuf:
+		data = append(binary.AppendUvarint(nil, uint64(len(data))), data...)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
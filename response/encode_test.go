@@ -0,0 +1,139 @@
+package response
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNegotiate(t *testing.T) {
+	for accept, expected := range map[string]Format{
+		"":                                   FormatJSON,
+		"*/*":                                FormatJSON,
+		"text/html":                          FormatJSON,
+		"application/json":                   FormatJSON,
+		"application/cbor":                   FormatCBOR,
+		"Application/CBOR":                   FormatCBOR,
+		"application/x-protobuf":             FormatProtobuf,
+		"application/protobuf":               FormatProtobuf,
+		"application/cbor, application/json": FormatCBOR,
+		"application/json, application/cbor": FormatJSON,
+		"application/json;q=0.5, application/cbor":           FormatCBOR,
+		"application/cbor;q=0, */*":                          FormatJSON,
+		"text/html, application/x-protobuf;q=0.9, */*;q=0.1": FormatProtobuf,
+	} {
+		require.Equal(t, expected, negotiate(accept), accept)
+	}
+}
+
+func TestCBOR(t *testing.T) {
+	// test vectors from RFC 8949, Appendix A
+	for data, expected := range map[string]string{
+		`0`:                    "00",
+		`10`:                   "0a",
+		`24`:                   "1818",
+		`1000`:                 "1903e8",
+		`1000000`:              "1a000f4240",
+		`18446744073709551615`: "1bffffffffffffffff",
+		`-1`:                   "20",
+		`-1000`:                "3903e7",
+		`1.1`:                  "fb3ff199999999999a",
+		`false`:                "f4",
+		`true`:                 "f5",
+		`null`:                 "f6",
+		`""`:                   "60",
+		`"IETF"`:               "6449455446",
+		`"ü"`:                  "62c3bc",
+		`[]`:                   "80",
+		`[1,[2,3],[4,5]]`:      "8301820203820405",
+		`{}`:                   "a0",
+		`{"a":1,"b":[2,3]}`:    "a26161016162820203",
+		`{"b":1,"a":2,"aa":3}`: "a3616102616201626161" + "03",
+	} {
+		dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+		dec.UseNumber()
+		var v any
+		require.NoError(t, dec.Decode(&v))
+
+		res, err := marshalCBOR(v)
+		require.NoError(t, err, data)
+		require.Equal(t, expected, hex.EncodeToString(res), data)
+	}
+}
+
+type testResponse struct {
+	ID    string   `json:"id"`
+	Size  uint64   `json:"size"`
+	Items []string `json:"items,omitempty"`
+}
+
+func (r testResponse) Proto() proto.Message {
+	return &pb.ExportRecord{ObjectId: r.ID, PayloadSize: r.Size}
+}
+
+func TestMarshal(t *testing.T) {
+	v := testResponse{ID: "obj", Size: 1024, Items: []string{"a", "b"}}
+
+	data, err := Marshal(FormatJSON, v)
+	require.NoError(t, err)
+	require.Equal(t, "{\n\t\"id\": \"obj\",\n\t\"size\": 1024,\n\t\"items\": [\n\t\t\"a\",\n\t\t\"b\"\n\t]\n}\n", string(data))
+
+	data, err = Marshal(FormatCBOR, v)
+	require.NoError(t, err)
+	require.Equal(t, "a3"+"626964"+"636f626a"+"6473697a65"+"190400"+"656974656d73"+"82"+"6161"+"6162", hex.EncodeToString(data))
+
+	v.Size = math.MaxUint64
+	data, err = Marshal(FormatProtobuf, v)
+	require.NoError(t, err)
+
+	var rec pb.ExportRecord
+	require.NoError(t, proto.Unmarshal(data, &rec))
+	require.Equal(t, "obj", rec.GetObjectId())
+	require.Equal(t, uint64(math.MaxUint64), rec.GetPayloadSize())
+
+	_, err = Marshal(FormatProtobuf, struct{}{})
+	require.Error(t, err)
+}
+
+func TestContentType(t *testing.T) {
+	require.Equal(t, "application/x-protobuf; messageType=neofs.http.ExportRecord", FormatProtobuf.ContentType(testResponse{}))
+	require.Equal(t, "application/x-protobuf; messageType=neofs.http.ExportRecord; delimited=true", FormatProtobuf.SeqContentType(testResponse{}))
+}
+
+func TestWriteSeq(t *testing.T) {
+	v := testResponse{ID: "obj", Size: 1}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSeq(&buf, FormatJSON, v))
+	require.NoError(t, WriteSeq(&buf, FormatJSON, v))
+	require.Equal(t, "{\"id\":\"obj\",\"size\":1}\n{\"id\":\"obj\",\"size\":1}\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteSeq(&buf, FormatProtobuf, v))
+	size, n := binary.Uvarint(buf.Bytes())
+	require.Positive(t, n)
+	require.EqualValues(t, buf.Len()-n, size)
+
+	var rec pb.ExportRecord
+	require.NoError(t, proto.Unmarshal(buf.Bytes()[n:], &rec))
+	require.Equal(t, "obj", rec.GetObjectId())
+}
+
+func TestWrite(t *testing.T) {
+	var c fasthttp.RequestCtx
+	c.Request.Header.Set(fasthttp.HeaderAccept, MIMECBOR)
+
+	Write(&c, fasthttp.StatusAccepted, testResponse{ID: "obj"})
+	require.Equal(t, fasthttp.StatusAccepted, c.Response.StatusCode())
+	require.Equal(t, MIMECBOR, string(c.Response.Header.ContentType()))
+	require.Equal(t, fasthttp.HeaderAccept, string(c.Response.Header.Peek(fasthttp.HeaderVary)))
+	require.Equal(t, "a2"+"626964"+"636f626a"+"6473697a65"+"00", hex.EncodeToString(c.Response.Body()))
+}
@@ -0,0 +1,4 @@
+// Package pb contains protobuf messages of the gate responses.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative responses.proto
@@ -2,6 +2,15 @@ package response
 
 import "github.com/valyala/fasthttp"
 
+// HeaderErrorCode is a response header with machine-readable error code.
+const HeaderErrorCode = "X-Error-Code"
+
 func Error(r *fasthttp.RequestCtx, msg string, code int) {
 	r.Error(msg+"\n", code)
 }
+
+// ErrorWithCode is like Error, but also sets the error code header.
+func ErrorWithCode(r *fasthttp.RequestCtx, errCode, msg string, code int) {
+	Error(r, msg, code)
+	r.Response.Header.Set(HeaderErrorCode, errCode)
+}
@@ -0,0 +1,143 @@
+// Package searchlimit bounds object searches made by requests: searches are
+// interrupted when their timeout expires and can stop after the first
+// matches, limits are set per route.
+package searchlimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+)
+
+// ErrTimeout is returned by searches interrupted by the search timeout.
+var ErrTimeout = errors.New("search timeout exceeded")
+
+const limitsKey = "__context_search_limits_key"
+
+// Limits are limits of a single search.
+type Limits struct {
+	// Timeout is the maximum duration of the search including reading of the
+	// results, zero means no limit.
+	Timeout time.Duration
+	// MaxResults is the number of matches the search stops after, zero means
+	// no limit.
+	MaxResults int
+}
+
+// IsZero checks whether searches aren't limited.
+func (l Limits) IsZero() bool {
+	return l.Timeout <= 0 && l.MaxResults <= 0
+}
+
+// Config contains default search limits and limits of routes.
+type Config struct {
+	Default Limits
+	// Routes are limits of routes by their names, unset (zero) values are
+	// taken from the default limits.
+	Routes map[string]Limits
+}
+
+// For returns search limits of the route.
+func (c *Config) For(route string) Limits {
+	res := c.Default
+	if l, ok := c.Routes[route]; ok {
+		if l.Timeout > 0 {
+			res.Timeout = l.Timeout
+		}
+		if l.MaxResults > 0 {
+			res.MaxResults = l.MaxResults
+		}
+	}
+	return res
+}
+
+// Store sets search limits of the request.
+func Store(c *fasthttp.RequestCtx, l Limits) {
+	c.SetUserValue(limitsKey, l)
+}
+
+// Load returns search limits of the request, searches aren't limited if they
+// haven't been stored.
+func Load(c *fasthttp.RequestCtx) Limits {
+	l, _ := c.UserValue(limitsKey).(Limits)
+	return l
+}
+
+// Search starts the search with the limits. The search gets the context
+// expiring after the timeout, the context is canceled when the returned
+// lister is closed, so it must always be closed. The lister reports the end
+// of the results after MaxResults matches.
+func Search(ctx context.Context, l Limits, search func(context.Context) (backend.ObjectLister, error)) (backend.ObjectLister, error) {
+	if l.IsZero() {
+		return search(ctx)
+	}
+
+	var cancel context.CancelFunc
+	if l.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, l.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	res := &lister{ctx: ctx, cancel: cancel, limits: l, left: l.MaxResults}
+	var err error
+	if res.ObjectLister, err = search(ctx); err != nil {
+		cancel()
+		return nil, res.wrap(err)
+	}
+	return res, nil
+}
+
+// lister limits the search results, it cancels the search context on close.
+type lister struct {
+	backend.ObjectLister
+	ctx    context.Context
+	cancel context.CancelFunc
+	limits Limits
+	left   int
+}
+
+func (l *lister) Read(buf []oid.ID) (int, error) {
+	if l.limits.MaxResults > 0 {
+		if l.left == 0 {
+			return 0, io.EOF
+		}
+		if len(buf) > l.left {
+			buf = buf[:l.left]
+		}
+	}
+	n, err := l.ObjectLister.Read(buf)
+	l.left -= n
+	return n, l.wrap(err)
+}
+
+func (l *lister) Iterate(f func(oid.ID) bool) error {
+	if l.limits.MaxResults > 0 && l.left == 0 {
+		return nil
+	}
+	err := l.ObjectLister.Iterate(func(id oid.ID) bool {
+		l.left--
+		return f(id) || l.limits.MaxResults > 0 && l.left == 0
+	})
+	return l.wrap(err)
+}
+
+func (l *lister) Close() error {
+	err := l.ObjectLister.Close()
+	l.cancel()
+	return l.wrap(err)
+}
+
+// wrap marks errors caused by the expired search timeout with ErrTimeout.
+func (l *lister) wrap(err error) error {
+	if err == nil || errors.Is(err, io.EOF) || !errors.Is(l.ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %s: %v", ErrTimeout, l.limits.Timeout, err)
+}
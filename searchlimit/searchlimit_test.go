@@ -0,0 +1,121 @@
+package searchlimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+type testLister struct {
+	ctx context.Context
+	ids []oid.ID
+}
+
+func (l *testLister) Read(buf []oid.ID) (int, error) {
+	if len(l.ids) == 0 {
+		<-l.ctx.Done()
+		return 0, l.ctx.Err()
+	}
+	n := copy(buf, l.ids)
+	l.ids = l.ids[n:]
+	return n, nil
+}
+
+func (l *testLister) Iterate(f func(oid.ID) bool) error {
+	for _, id := range l.ids {
+		if f(id) {
+			return nil
+		}
+	}
+	<-l.ctx.Done()
+	return l.ctx.Err()
+}
+
+func (l *testLister) Close() error {
+	return nil
+}
+
+func TestSearch(t *testing.T) {
+	ids := []oid.ID{oidtest.ID(), oidtest.ID(), oidtest.ID()}
+	search := func(ctx context.Context) (backend.ObjectLister, error) {
+		return &testLister{ctx: ctx, ids: ids}, nil
+	}
+
+	t.Run("max results", func(t *testing.T) {
+		res, err := Search(context.Background(), Limits{MaxResults: 2}, search)
+		require.NoError(t, err)
+		var found []oid.ID
+		require.NoError(t, res.Iterate(func(id oid.ID) bool {
+			found = append(found, id)
+			return false
+		}))
+		require.Equal(t, ids[:2], found)
+		require.NoError(t, res.Close())
+
+		res, err = Search(context.Background(), Limits{MaxResults: 2}, search)
+		require.NoError(t, err)
+		buf := make([]oid.ID, 3)
+		n, err := res.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		_, err = res.Read(buf)
+		require.ErrorIs(t, err, io.EOF)
+		require.NoError(t, res.Close())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		res, err := Search(context.Background(), Limits{Timeout: 10 * time.Millisecond}, search)
+		require.NoError(t, err)
+		var found int
+		err = res.Iterate(func(oid.ID) bool {
+			found++
+			return false
+		})
+		require.ErrorIs(t, err, ErrTimeout)
+		require.Equal(t, len(ids), found)
+		require.NoError(t, res.Close())
+
+		_, err = Search(context.Background(), Limits{Timeout: time.Millisecond}, func(ctx context.Context) (backend.ObjectLister, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		require.ErrorIs(t, err, ErrTimeout)
+	})
+
+	t.Run("canceled on close", func(t *testing.T) {
+		var lister *testLister
+		res, err := Search(context.Background(), Limits{MaxResults: 1}, func(ctx context.Context) (backend.ObjectLister, error) {
+			lister = &testLister{ctx: ctx}
+			return lister, nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Close())
+		require.True(t, errors.Is(lister.ctx.Err(), context.Canceled))
+	})
+}
+
+func TestConfig(t *testing.T) {
+	cfg := Config{
+		Default: Limits{Timeout: time.Minute},
+		Routes: map[string]Limits{
+			"zip":              {MaxResults: 100},
+			"get_by_attribute": {Timeout: time.Second},
+		},
+	}
+	require.Equal(t, Limits{Timeout: time.Minute, MaxResults: 100}, cfg.For("zip"))
+	require.Equal(t, Limits{Timeout: time.Second}, cfg.For("get_by_attribute"))
+	require.Equal(t, Limits{Timeout: time.Minute}, cfg.For("browse"))
+
+	var c fasthttp.RequestCtx
+	require.True(t, Load(&c).IsZero())
+	Store(&c, cfg.For("zip"))
+	require.Equal(t, Limits{Timeout: time.Minute, MaxResults: 100}, Load(&c))
+}
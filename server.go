@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -57,8 +58,11 @@ func (s *server) UpdateCert(certFile, keyFile string) error {
 	return s.tlsProvider.UpdateCert(certFile, keyFile)
 }
 
-func newServer(ctx context.Context, serverInfo ServerInfo, logger *zap.Logger) *server {
-	var lic net.ListenConfig
+// newServer starts listening the server address. Accepted TCP connections
+// have keep-alive probes sent with the given period, negative one disables
+// them.
+func newServer(ctx context.Context, serverInfo ServerInfo, keepalive time.Duration, logger *zap.Logger) *server {
+	lic := net.ListenConfig{KeepAlive: keepalive}
 	ln, err := lic.Listen(ctx, "tcp", serverInfo.Address)
 	if err != nil {
 		logger.Fatal("could not prepare listener", zap.String("address", serverInfo.Address), zap.Error(err))
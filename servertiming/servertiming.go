@@ -0,0 +1,80 @@
+// Package servertiming collects durations of request processing stages and
+// reports them in Server-Timing response header, so clients can see where
+// the latency accrues.
+package servertiming
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// HeaderServerTiming is a response header with durations of request
+// processing stages.
+const HeaderServerTiming = "Server-Timing"
+
+// Names of request processing stages.
+const (
+	Resolve = "resolve"
+	Search  = "search"
+	Head    = "head"
+	Payload = "payload"
+	Total   = "total"
+)
+
+const timingsKey = "__context_server_timing_key"
+
+// timings are durations of request processing stages in the order of their
+// first occurrence.
+type timings struct {
+	names []string
+	durs  map[string]time.Duration
+}
+
+// Enable starts collecting durations of the request processing stages.
+func Enable(c *fasthttp.RequestCtx) {
+	c.SetUserValue(timingsKey, &timings{durs: make(map[string]time.Duration)})
+}
+
+// Start starts the stage of request processing and returns the function to
+// be called when the stage is over. Durations of the stage are summed if it's
+// started several times. Nothing is collected if timings aren't enabled for
+// the request.
+func Start(c *fasthttp.RequestCtx, name string) func() {
+	t, ok := c.UserValue(timingsKey).(*timings)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		if _, ok := t.durs[name]; !ok {
+			t.names = append(t.names, name)
+		}
+		t.durs[name] += time.Since(start)
+	}
+}
+
+// Write sets Server-Timing header with the collected durations and the total
+// duration of the request if timings are enabled for the request.
+func Write(c *fasthttp.RequestCtx, total time.Duration) {
+	t, ok := c.UserValue(timingsKey).(*timings)
+	if !ok {
+		return
+	}
+	var sb strings.Builder
+	for _, name := range t.names {
+		writeMetric(&sb, name, t.durs[name])
+		sb.WriteString(", ")
+	}
+	writeMetric(&sb, Total, total)
+	c.Response.Header.Set(HeaderServerTiming, sb.String())
+}
+
+// writeMetric writes the metric with the duration in milliseconds.
+func writeMetric(sb *strings.Builder, name string, dur time.Duration) {
+	sb.WriteString(name)
+	sb.WriteString(";dur=")
+	sb.WriteString(strconv.FormatFloat(float64(dur.Microseconds())/1000, 'f', -1, 64))
+}
@@ -0,0 +1,28 @@
+package servertiming
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestServerTiming(t *testing.T) {
+	var c fasthttp.RequestCtx
+	Start(&c, Resolve)()
+	Write(&c, time.Second)
+	require.Empty(t, c.Response.Header.Peek(HeaderServerTiming), "disabled")
+
+	Enable(&c)
+	Start(&c, Resolve)()
+	done := Start(&c, Head)
+	Start(&c, Search)()
+	done()
+	Start(&c, Resolve)()
+	Write(&c, 1500*time.Microsecond)
+
+	require.Regexp(t, regexp.MustCompile(`^resolve;dur=[0-9.]+, search;dur=[0-9.]+, head;dur=[0-9.]+, total;dur=1\.5$`),
+		string(c.Response.Header.Peek(HeaderServerTiming)))
+}
@@ -3,12 +3,29 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/coalesce"
+	"github.com/nspcc-dev/neofs-http-gw/discovery"
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
+	"github.com/nspcc-dev/neofs-http-gw/maintenance"
+	"github.com/nspcc-dev/neofs-http-gw/metrics"
+	"github.com/nspcc-dev/neofs-http-gw/pathfilter"
+	"github.com/nspcc-dev/neofs-http-gw/placement"
+	"github.com/nspcc-dev/neofs-http-gw/purge"
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/streamlimit"
+	"github.com/nspcc-dev/neofs-http-gw/uploader"
+	"github.com/nspcc-dev/neofs-http-gw/usage"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/valyala/fasthttp"
@@ -16,6 +33,30 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// Expiration epoch rounding modes.
+const (
+	expirationRoundingCeil  = "ceil"
+	expirationRoundingFloor = "floor"
+)
+
+// Handling modes of client Date header exceeding the maximum clock skew.
+const (
+	clockSkewActionReject = "reject"
+	clockSkewActionIgnore = "ignore"
+)
+
+// Duplicated attribute headers handling modes.
+const (
+	attributeDuplicatesReject = "reject"
+	attributeDuplicatesJoin   = "join"
+)
+
+// Pool start modes.
+const (
+	poolStartModeFailFast = "fail_fast"
+	poolStartModeLazy     = "lazy"
+)
+
 const (
 	defaultRebalanceTimer = 60 * time.Second
 	defaultRequestTimeout = 15 * time.Second
@@ -24,8 +65,22 @@ const (
 
 	defaultShutdownTimeout = 15 * time.Second
 
+	defaultTCPKeepalivePeriod = 15 * time.Second
+
 	defaultPoolErrorThreshold uint32 = 100
 
+	defaultPoolRedialInterval = 10 * time.Second
+
+	defaultPoolSessionLifetime uint64 = 100
+
+	defaultEpochPollInterval = 15 * time.Second
+
+	defaultSlicingBufferSize = 1 << 20
+
+	defaultParallelMinParts = 4
+
+	defaultParallelBufferSize = 256 << 20
+
 	cfgServer      = "server"
 	cfgTLSEnabled  = "tls.enabled"
 	cfgTLSCertFile = "tls.cert_file"
@@ -38,6 +93,34 @@ const (
 	cfgWebWriteTimeout       = "web.write_timeout"
 	cfgWebStreamRequestBody  = "web.stream_request_body"
 	cfgWebMaxRequestBodySize = "web.max_request_body_size"
+	cfgWebTrustedProxies     = "web.trusted_proxies"
+	cfgWebClientIPHeader     = "web.client_ip_header"
+	cfgWebStallTimeout       = "web.download_stall_timeout"
+	cfgWebSignHeaders        = "web.sign_headers"
+	cfgWebDefaultLanguage    = "web.default_language"
+	cfgWebAllowedHosts       = "web.allowed_hosts"
+	cfgWebServerTiming       = "web.server_timing"
+	cfgWebRequestTimeout     = "web.request_timeout"
+
+	// Tenants.
+	cfgTenantsDir            = "tenants.dir"
+	cfgTenantsReloadInterval = "tenants.reload_interval"
+
+	// Access control by client addresses.
+	cfgAccessControl = "access_control"
+
+	// Search limits.
+	cfgSearchTimeout    = "search.timeout"
+	cfgSearchMaxResults = "search.max_results"
+	cfgSearchRoutes     = "search.routes"
+
+	// Web connection limits.
+	cfgWebConcurrency        = "web.concurrency"
+	cfgWebMaxConnsPerIP      = "web.max_conns_per_ip"
+	cfgWebMaxRequestsPerConn = "web.max_requests_per_conn"
+	cfgWebIdleTimeout        = "web.idle_timeout"
+	cfgWebTCPKeepalive       = "web.tcp_keepalive"
+	cfgWebTCPKeepalivePeriod = "web.tcp_keepalive_period"
 
 	// Metrics / Profiler.
 	cfgPrometheusEnabled = "prometheus.enabled"
@@ -45,12 +128,40 @@ const (
 	cfgPprofEnabled      = "pprof.enabled"
 	cfgPprofAddress      = "pprof.address"
 
+	cfgInstrumentationEnabled = "instrumentation.enabled"
+	cfgInstrumentationAddress = "instrumentation.address"
+
+	cfgChaosEnabled = "chaos.enabled"
+	cfgChaosAddress = "chaos.address"
+
+	cfgPrometheusContainerLabels = "prometheus.container_labels"
+
+	// Metrics push.
+	cfgMetricsPushEnabled     = "metrics_push.enabled"
+	cfgMetricsPushMode        = "metrics_push.mode"
+	cfgMetricsPushURL         = "metrics_push.url"
+	cfgMetricsPushJob         = "metrics_push.job"
+	cfgMetricsPushInterval    = "metrics_push.interval"
+	cfgMetricsPushTimeout     = "metrics_push.timeout"
+	cfgMetricsPushLabels      = "metrics_push.labels"
+	cfgMetricsPushUsername    = "metrics_push.username"
+	cfgMetricsPushPassword    = "metrics_push.password"
+	cfgMetricsPushBearerToken = "metrics_push.bearer_token"
+
 	// Pool config.
-	cfgConTimeout         = "connect_timeout"
-	cfgStreamTimeout      = "stream_timeout"
-	cfgReqTimeout         = "request_timeout"
-	cfgRebalance          = "rebalance_timer"
-	cfgPoolErrorThreshold = "pool_error_threshold"
+	cfgConTimeout          = "connect_timeout"
+	cfgStreamTimeout       = "stream_timeout"
+	cfgReqTimeout          = "request_timeout"
+	cfgRebalance           = "rebalance_timer"
+	cfgPoolErrorThreshold  = "pool_error_threshold"
+	cfgPoolStartMode       = "pool_start_mode"
+	cfgPoolRedialInterval  = "pool_redial_interval"
+	cfgPoolSessionLifetime = "pool_session_lifetime"
+	cfgEpochPollInterval   = "epoch_poll_interval"
+	cfgShutdownTimeout     = "shutdown_timeout"
+
+	// Networks.
+	cfgNetworks = "networks"
 
 	// Logger.
 	cfgLoggerLevel = "logger.level"
@@ -62,6 +173,178 @@ const (
 
 	// Uploader Header.
 	cfgUploaderHeaderEnableDefaultTimestamp = "upload_header.use_default_timestamp"
+	cfgUploaderHeaderExpirationRounding     = "upload_header.expiration.rounding"
+	cfgUploaderHeaderExpirationMaxClockSkew = "upload_header.expiration.max_clock_skew"
+	cfgUploaderHeaderExpirationSkewAction   = "upload_header.expiration.clock_skew_action"
+	cfgUploaderHeaderExpirationMinLifetime  = "upload_header.expiration.min_lifetime"
+	cfgUploaderHeaderExpirationMaxLifetime  = "upload_header.expiration.max_lifetime"
+	cfgUploaderHeaderExpirationDefault      = "upload_header.expiration.default_lifetime"
+	cfgUploaderHeaderAttributeDuplicates    = "upload_header.attribute_duplicates"
+	cfgUploaderHeaderAttributeSeparator     = "upload_header.attribute_separator"
+	cfgUploaderHeaderAttributeCase          = "upload_header.attribute_case"
+	cfgUploaderHeaderSystemAllowed          = "upload_header.system_attributes.allowed"
+	cfgUploaderHeaderSystemDenied           = "upload_header.system_attributes.denied"
+	cfgUploaderHeaderMaxCopiesNumber        = "upload_header.max_copies_number"
+
+	// Upload slicing.
+	cfgUploadSlicingMode        = "upload_slicing.mode"
+	cfgUploadSlicingAllowHeader = "upload_slicing.allow_header"
+	cfgUploadSlicingBufferSize  = "upload_slicing.buffer_size"
+	cfgUploadSlicingSizeHint    = "upload_slicing.use_size_hint"
+	cfgUploadParallelWrites     = "upload_slicing.parallel_writes"
+	cfgUploadParallelMinParts   = "upload_slicing.parallel_min_parts"
+	cfgUploadParallelBufferSize = "upload_slicing.parallel_buffer_size"
+
+	// Upload paths.
+	cfgUploadPathNormalize = "upload_path.normalize"
+	cfgUploadPathConflict  = "upload_path.conflict"
+
+	// Upload blocklist.
+	cfgUploadBlocklistContentTypes = "upload_blocklist.content_types"
+	cfgUploadBlocklistExtensions   = "upload_blocklist.extensions"
+
+	// Challenge verification of anonymous uploads.
+	cfgUploadCaptchaEnabled   = "upload_captcha.enabled"
+	cfgUploadCaptchaVerifyURL = "upload_captcha.verify_url"
+	cfgUploadCaptchaSecret    = "upload_captcha.secret"
+	cfgUploadCaptchaTimeout   = "upload_captcha.timeout"
+
+	// Abuse reports.
+	cfgAbuseReportEnabled        = "abuse_report.enabled"
+	cfgAbuseReportContainer      = "abuse_report.container"
+	cfgAbuseReportWebhookURL     = "abuse_report.webhook_url"
+	cfgAbuseReportWebhookTimeout = "abuse_report.webhook_timeout"
+	cfgAbuseReportRateLimit      = "abuse_report.rate_limit"
+	cfgAbuseReportRateWindow     = "abuse_report.rate_window"
+	cfgAbuseReportCaptcha        = "abuse_report.captcha"
+
+	// Import.
+	cfgImportEnabled      = "import.enabled"
+	cfgImportConcurrency  = "import.concurrency"
+	cfgImportMaxRecords   = "import.max_records"
+	cfgImportTimeout      = "import.timeout"
+	cfgImportAllowedHosts = "import.allowed_hosts"
+
+	// Delete.
+	cfgDeleteEnabled = "delete.enabled"
+
+	// Rename.
+	cfgRenameEnabled = "rename.enabled"
+
+	// Delete by prefix.
+	cfgDeleteByPrefixEnabled     = "delete_by_prefix.enabled"
+	cfgDeleteByPrefixConcurrency = "delete_by_prefix.concurrency"
+	cfgDeleteByPrefixMaxObjects  = "delete_by_prefix.max_objects"
+
+	// Browse.
+	cfgBrowseEnabled    = "browse.enabled"
+	cfgBrowseMaxObjects = "browse.max_objects"
+
+	// Preview.
+	cfgPreviewEnabled = "preview.enabled"
+	cfgPreviewMaxSize = "preview.max_size"
+
+	// Soft delete.
+	cfgSoftDeleteEnabled         = "soft_delete.enabled"
+	cfgSoftDeleteRetention       = "soft_delete.retention"
+	cfgSoftDeleteContainers      = "soft_delete.containers"
+	cfgSoftDeleteCollectInterval = "soft_delete.collect_interval"
+
+	// Tail.
+	cfgTailEnabled      = "tail.enabled"
+	cfgTailDefaultBytes = "tail.default_bytes"
+	cfgTailMaxBytes     = "tail.max_bytes"
+	cfgTailMaxObjects   = "tail.max_objects"
+	cfgTailPollInterval = "tail.poll_interval"
+	cfgTailMaxFollow    = "tail.max_follow"
+
+	// Canonical links.
+	cfgCanonicalLinkEnabled     = "canonical_link.enabled"
+	cfgCanonicalLinkBaseURL     = "canonical_link.base_url"
+	cfgCanonicalLinkUseFilePath = "canonical_link.use_file_path"
+
+	// Jobs.
+	cfgJobsRetention = "jobs.retention"
+
+	// Garbage collection.
+	cfgGCInterval = "gc.interval"
+
+	// Coalescing of object reads.
+	cfgCoalescingEnabled        = "coalescing.enabled"
+	cfgCoalescingMaxPayloadSize = "coalescing.max_payload_size"
+	cfgCoalescingMaxTotalSize   = "coalescing.max_total_size"
+
+	// Stream limits.
+	cfgStreamLimitsPerObject    = "stream_limits.per_object"
+	cfgStreamLimitsPerContainer = "stream_limits.per_container"
+	cfgStreamLimitsOverflow     = "stream_limits.overflow"
+	cfgStreamLimitsQueueTimeout = "stream_limits.queue_timeout"
+
+	// Default bearer token.
+	cfgDefaultBearerPath       = "default_bearer.path"
+	cfgDefaultBearerContainers = "default_bearer.containers"
+	cfgDefaultBearerClients    = "default_bearer.clients"
+
+	// Cold-start prefetch.
+	cfgPrefetchPaths       = "prefetch.paths"
+	cfgPrefetchConcurrency = "prefetch.concurrency"
+
+	// Metadata cache.
+	cfgMetadataCacheContainerTTL   = "metadata_cache.container_ttl"
+	cfgMetadataCacheNetworkInfoTTL = "metadata_cache.network_info_ttl"
+	cfgMetadataCacheSize           = "metadata_cache.size"
+
+	// Placement reads.
+	cfgPlacementReadsEnabled   = "placement_reads.enabled"
+	cfgPlacementReadsMaxNodes  = "placement_reads.max_nodes"
+	cfgPlacementReadsNetmapTTL = "placement_reads.netmap_ttl"
+	// Nodes that can't be dialed are skipped with exponential backoff.
+	cfgPlacementReadsDialBackoff    = "placement_reads.dial_backoff"
+	cfgPlacementReadsMaxDialBackoff = "placement_reads.max_dial_backoff"
+
+	cfgPlacementReadsPrefer           = "placement_reads.prefer"
+	cfgPlacementReadsPreferAttributes = "placement_reads.prefer_attributes"
+	cfgPlacementReadsProbeInterval    = "placement_reads.probe_interval"
+	cfgPlacementReadsProbeTimeout     = "placement_reads.probe_timeout"
+
+	// Attribute search results caching.
+	cfgAttributeCacheTTL         = "attribute_cache.ttl"
+	cfgAttributeCacheNegativeTTL = "attribute_cache.negative_ttl"
+	cfgAttributeCacheSize        = "attribute_cache.size"
+
+	// Path filters.
+	cfgPathFilterEnabled           = "path_filter.enabled"
+	cfgPathFilterRefreshInterval   = "path_filter.refresh_interval"
+	cfgPathFilterMaxObjects        = "path_filter.max_objects"
+	cfgPathFilterMaxContainers     = "path_filter.max_containers"
+	cfgPathFilterFalsePositiveRate = "path_filter.false_positive_rate"
+	cfgPathFilterConcurrency       = "path_filter.concurrency"
+
+	// Peers discovery.
+	cfgPeersDiscoveryEnabled    = "peers_discovery.enabled"
+	cfgPeersDiscoveryInterval   = "peers_discovery.interval"
+	cfgPeersDiscoveryAttributes = "peers_discovery.attributes"
+	cfgPeersDiscoveryMaxNodes   = "peers_discovery.max_nodes"
+
+	// Usage accounting.
+	cfgAccountingEnabled        = "accounting.enabled"
+	cfgAccountingMaxIssuers     = "accounting.max_issuers"
+	cfgAccountingAddress        = "accounting.address"
+	cfgAccountingQuota          = "accounting.quota"
+	cfgAccountingAnonymousQuota = "accounting.anonymous_quota"
+	cfgAccountingQuotas         = "accounting.quotas"
+	cfgAccountingAPIKeys        = "accounting.api_keys"
+
+	// Purge.
+	cfgPurgeToken            = "purge.token"
+	cfgPurgeTimeout          = "purge.timeout"
+	cfgPurgeFastlyServiceID  = "purge.fastly.service_id"
+	cfgPurgeFastlyToken      = "purge.fastly.token"
+	cfgPurgeCloudflareZoneID = "purge.cloudflare.zone_id"
+	cfgPurgeCloudflareToken  = "purge.cloudflare.token"
+
+	// Transformation hooks.
+	cfgTransform = "transform"
 
 	// Peers.
 	cfgPeers = "peers"
@@ -69,8 +352,43 @@ const (
 	// NeoGo.
 	cfgRPCEndpoint = "rpc_endpoint"
 
-	// Zip compression.
+	// Container name resolving.
+	cfgResolveOrder             = "resolve_order"
+	cfgResolverDNSServer        = "resolver.dns_server"
+	cfgResolverCacheTTL         = "resolver.cache_ttl"
+	cfgResolverCacheSize        = "resolver.cache_size"
+	cfgResolverNegativeCacheTTL = "resolver.negative_cache_ttl"
+
+	// Static container aliases.
+	cfgAliases = "aliases"
+
+	// External base URL of the gate.
+	cfgExternalURL = "external_url"
+
+	// Zip compression and manifest.
 	cfgZipCompression = "zip.compression"
+	cfgZipManifest    = "zip.manifest"
+
+	// Zip archives cache.
+	cfgZipCacheEnabled      = "zip.cache.enabled"
+	cfgZipCacheDir          = "zip.cache.dir"
+	cfgZipCacheTTL          = "zip.cache.ttl"
+	cfgZipCacheMaxSize      = "zip.cache.max_size"
+	cfgZipCacheMaxEntrySize = "zip.cache.max_entry_size"
+	cfgZipCachePeers        = "zip.cache.peers"
+	cfgZipCacheSelf         = "zip.cache.self"
+	cfgZipCachePeerTimeout  = "zip.cache.peer_timeout"
+
+	// External objects cache.
+	cfgExternalCacheType           = "external_cache.type"
+	cfgExternalCacheAddresses      = "external_cache.addresses"
+	cfgExternalCachePassword       = "external_cache.password"
+	cfgExternalCacheFormat         = "external_cache.format"
+	cfgExternalCacheHeadTTL        = "external_cache.head_ttl"
+	cfgExternalCachePayloadTTL     = "external_cache.payload_ttl"
+	cfgExternalCacheMaxPayloadSize = "external_cache.max_payload_size"
+	cfgExternalCacheTimeout        = "external_cache.timeout"
+	cfgExternalCachePoolSize       = "external_cache.pool_size"
 
 	// Command line args.
 	cmdHelp          = "help"
@@ -128,6 +446,19 @@ func settings() *viper.Viper {
 
 	// pool:
 	v.SetDefault(cfgPoolErrorThreshold, defaultPoolErrorThreshold)
+	v.SetDefault(cfgPoolSessionLifetime, defaultPoolSessionLifetime)
+	v.SetDefault(cfgUploadSlicingMode, uploader.SlicingNode)
+	v.SetDefault(cfgUploadSlicingBufferSize, defaultSlicingBufferSize)
+	v.SetDefault(cfgUploadSlicingSizeHint, true)
+	v.SetDefault(cfgUploadParallelMinParts, defaultParallelMinParts)
+	v.SetDefault(cfgUploadParallelBufferSize, defaultParallelBufferSize)
+	v.SetDefault(cfgUploadPathNormalize, false)
+	v.SetDefault(cfgUploadPathConflict, uploader.PathConflictVersion)
+	v.SetDefault(cfgEpochPollInterval, defaultEpochPollInterval)
+	v.SetDefault(cfgShutdownTimeout, defaultShutdownTimeout)
+	v.SetDefault(cfgExternalURL, "")
+	v.SetDefault(cfgPoolStartMode, poolStartModeFailFast)
+	v.SetDefault(cfgPoolRedialInterval, defaultPoolRedialInterval)
 
 	// web-server:
 	v.SetDefault(cfgWebReadBufferSize, 4096)
@@ -136,16 +467,187 @@ func settings() *viper.Viper {
 	v.SetDefault(cfgWebWriteTimeout, time.Minute*5)
 	v.SetDefault(cfgWebStreamRequestBody, true)
 	v.SetDefault(cfgWebMaxRequestBodySize, fasthttp.DefaultMaxRequestBodySize)
+	v.SetDefault(cfgWebStallTimeout, time.Minute)
+	v.SetDefault(cfgWebClientIPHeader, clientip.DefaultHeader)
+	v.SetDefault(cfgWebSignHeaders, false)
+	v.SetDefault(cfgWebDefaultLanguage, "")
+	v.SetDefault(cfgWebServerTiming, false)
+	v.SetDefault(cfgWebRequestTimeout, time.Duration(0))
+	v.SetDefault(cfgSearchTimeout, time.Duration(0))
+	v.SetDefault(cfgSearchMaxResults, 0)
+	v.SetDefault(cfgWebConcurrency, fasthttp.DefaultConcurrency)
+	v.SetDefault(cfgWebMaxConnsPerIP, 0)
+	v.SetDefault(cfgWebMaxRequestsPerConn, 0)
+	v.SetDefault(cfgWebIdleTimeout, time.Duration(0))
+	v.SetDefault(cfgWebTCPKeepalive, true)
+	v.SetDefault(cfgWebTCPKeepalivePeriod, defaultTCPKeepalivePeriod)
 
 	// upload header
 	v.SetDefault(cfgUploaderHeaderEnableDefaultTimestamp, false)
+	v.SetDefault(cfgUploaderHeaderExpirationRounding, expirationRoundingCeil)
+	v.SetDefault(cfgUploaderHeaderExpirationMaxClockSkew, time.Hour)
+	v.SetDefault(cfgUploaderHeaderExpirationSkewAction, clockSkewActionReject)
+	v.SetDefault(cfgUploaderHeaderAttributeDuplicates, attributeDuplicatesReject)
+	v.SetDefault(cfgUploaderHeaderAttributeSeparator, ",")
+	v.SetDefault(cfgUploaderHeaderAttributeCase, utils.AttributeCasePreserve)
+	v.SetDefault(cfgUploaderHeaderMaxCopiesNumber, 0)
+	v.SetDefault(cfgUploaderHeaderSystemAllowed, []string{"EXPIRATION_EPOCH", "EXPIRATION_DURATION", "EXPIRATION_TIMESTAMP", "EXPIRATION_RFC3339"})
 
 	// zip:
 	v.SetDefault(cfgZipCompression, false)
+	v.SetDefault(cfgZipManifest, false)
+	v.SetDefault(cfgZipCacheEnabled, false)
+	v.SetDefault(cfgZipCacheDir, filepath.Join(os.TempDir(), "neofs-http-gw-zip"))
+	v.SetDefault(cfgZipCacheTTL, 10*time.Minute)
+	v.SetDefault(cfgZipCacheMaxSize, 1<<30)
+	v.SetDefault(cfgZipCacheMaxEntrySize, 256<<20)
+	v.SetDefault(cfgZipCachePeerTimeout, downloader.DefaultZipPeerTimeout)
+
+	// upload captcha
+	v.SetDefault(cfgUploadCaptchaEnabled, false)
+	v.SetDefault(cfgUploadCaptchaVerifyURL, "https://challenges.cloudflare.com/turnstile/v0/siteverify")
+	v.SetDefault(cfgUploadCaptchaTimeout, 5*time.Second)
+
+	// abuse reports
+	v.SetDefault(cfgAbuseReportEnabled, false)
+	v.SetDefault(cfgAbuseReportWebhookTimeout, 10*time.Second)
+	v.SetDefault(cfgAbuseReportRateLimit, 5)
+	v.SetDefault(cfgAbuseReportRateWindow, time.Hour)
+	v.SetDefault(cfgAbuseReportCaptcha, false)
+
+	// external cache
+	v.SetDefault(cfgExternalCacheFormat, extcache.FormatProtobuf)
+	v.SetDefault(cfgExternalCacheHeadTTL, time.Minute)
+	v.SetDefault(cfgExternalCachePayloadTTL, 5*time.Minute)
+	v.SetDefault(cfgExternalCacheMaxPayloadSize, 128<<10)
+	v.SetDefault(cfgExternalCacheTimeout, 200*time.Millisecond)
+	v.SetDefault(cfgExternalCachePoolSize, 16)
+
+	// resolver:
+	v.SetDefault(cfgResolveOrder, []string{resolver.NNSResolverName})
+	v.SetDefault(cfgResolverCacheTTL, time.Minute)
+	v.SetDefault(cfgResolverCacheSize, 1000)
+	v.SetDefault(cfgResolverNegativeCacheTTL, 10*time.Second)
+
+	// jobs:
+	v.SetDefault(cfgJobsRetention, jobs.DefaultRetention)
+
+	// tenants:
+	v.SetDefault(cfgTenantsReloadInterval, 10*time.Second)
+
+	// gc:
+	v.SetDefault(cfgGCInterval, maintenance.DefaultInterval)
+
+	// coalescing
+	v.SetDefault(cfgCoalescingEnabled, false)
+	v.SetDefault(cfgCoalescingMaxPayloadSize, coalesce.DefaultMaxPayloadSize)
+	v.SetDefault(cfgCoalescingMaxTotalSize, coalesce.DefaultMaxTotalSize)
+
+	// stream limits
+	v.SetDefault(cfgStreamLimitsPerObject, 0)
+	v.SetDefault(cfgStreamLimitsPerContainer, 0)
+	v.SetDefault(cfgStreamLimitsOverflow, streamlimit.OverflowReject)
+	v.SetDefault(cfgStreamLimitsQueueTimeout, streamlimit.DefaultQueueTimeout)
+
+	// prefetch
+	v.SetDefault(cfgPrefetchConcurrency, defaultPrefetchConcurrency)
+
+	// metadata cache
+	v.SetDefault(cfgMetadataCacheContainerTTL, time.Duration(0))
+	v.SetDefault(cfgMetadataCacheNetworkInfoTTL, time.Duration(0))
+	v.SetDefault(cfgMetadataCacheSize, 1000)
+
+	// placement_reads:
+	v.SetDefault(cfgPlacementReadsEnabled, false)
+	v.SetDefault(cfgPlacementReadsMaxNodes, placement.DefaultMaxNodes)
+	v.SetDefault(cfgPlacementReadsNetmapTTL, placement.DefaultNetmapTTL)
+	v.SetDefault(cfgPlacementReadsDialBackoff, placement.DefaultDialBackoff)
+	v.SetDefault(cfgPlacementReadsMaxDialBackoff, placement.DefaultMaxDialBackoff)
+	v.SetDefault(cfgPlacementReadsPrefer, placement.PreferNone)
+	v.SetDefault(cfgPlacementReadsProbeInterval, placement.DefaultProbeInterval)
+	v.SetDefault(cfgPlacementReadsProbeTimeout, placement.DefaultProbeTimeout)
+
+	// attribute cache
+	v.SetDefault(cfgAttributeCacheTTL, time.Duration(0))
+	v.SetDefault(cfgAttributeCacheNegativeTTL, time.Duration(0))
+	v.SetDefault(cfgAttributeCacheSize, 10000)
+
+	// path filter
+	v.SetDefault(cfgPathFilterEnabled, false)
+	v.SetDefault(cfgPathFilterRefreshInterval, pathfilter.DefaultRefreshInterval)
+	v.SetDefault(cfgPathFilterMaxObjects, pathfilter.DefaultMaxObjects)
+	v.SetDefault(cfgPathFilterMaxContainers, pathfilter.DefaultMaxContainers)
+	v.SetDefault(cfgPathFilterFalsePositiveRate, pathfilter.DefaultFalsePositiveRate)
+	v.SetDefault(cfgPathFilterConcurrency, pathfilter.DefaultConcurrency)
+
+	// peers_discovery:
+	v.SetDefault(cfgPeersDiscoveryEnabled, false)
+	v.SetDefault(cfgPeersDiscoveryInterval, discovery.DefaultInterval)
+	v.SetDefault(cfgPeersDiscoveryMaxNodes, 0)
+
+	// accounting:
+	v.SetDefault(cfgAccountingEnabled, false)
+	v.SetDefault(cfgAccountingMaxIssuers, usage.DefaultMaxIssuers)
+
+	// purge:
+	v.SetDefault(cfgPurgeTimeout, purge.DefaultTimeout)
+
+	// import:
+	v.SetDefault(cfgImportEnabled, false)
+	v.SetDefault(cfgImportConcurrency, 4)
+	v.SetDefault(cfgImportMaxRecords, 1000)
+	v.SetDefault(cfgImportTimeout, 10*time.Minute)
+
+	// delete:
+	v.SetDefault(cfgDeleteEnabled, false)
+
+	// rename:
+	v.SetDefault(cfgRenameEnabled, false)
+
+	// delete_by_prefix:
+	v.SetDefault(cfgDeleteByPrefixEnabled, false)
+	v.SetDefault(cfgDeleteByPrefixConcurrency, 4)
+	v.SetDefault(cfgDeleteByPrefixMaxObjects, 1000)
+
+	// browse:
+	v.SetDefault(cfgBrowseEnabled, false)
+	v.SetDefault(cfgBrowseMaxObjects, 1000)
+
+	// preview:
+	v.SetDefault(cfgPreviewEnabled, false)
+	v.SetDefault(cfgPreviewMaxSize, 1<<20)
+
+	// soft_delete:
+	v.SetDefault(cfgSoftDeleteEnabled, false)
+	v.SetDefault(cfgSoftDeleteRetention, 0)
+	v.SetDefault(cfgSoftDeleteCollectInterval, time.Hour)
+
+	// tail
+	v.SetDefault(cfgTailEnabled, false)
+	v.SetDefault(cfgTailDefaultBytes, 4096)
+	v.SetDefault(cfgTailMaxBytes, 1<<20)
+	v.SetDefault(cfgTailMaxObjects, 1000)
+	v.SetDefault(cfgTailPollInterval, 2*time.Second)
+	v.SetDefault(cfgTailMaxFollow, 4*time.Minute)
+
+	// canonical links
+	v.SetDefault(cfgCanonicalLinkEnabled, false)
+	v.SetDefault(cfgCanonicalLinkBaseURL, "")
+	v.SetDefault(cfgCanonicalLinkUseFilePath, true)
 
 	// metrics
 	v.SetDefault(cfgPprofAddress, "localhost:8083")
 	v.SetDefault(cfgPrometheusAddress, "localhost:8084")
+	v.SetDefault(cfgInstrumentationAddress, "localhost:8085")
+	v.SetDefault(cfgChaosAddress, "localhost:8086")
+	v.SetDefault(cfgAccountingAddress, "localhost:8087")
+
+	// metrics_push
+	v.SetDefault(cfgMetricsPushEnabled, false)
+	v.SetDefault(cfgMetricsPushMode, metrics.PushModePushgateway)
+	v.SetDefault(cfgMetricsPushJob, metrics.DefaultPushJob)
+	v.SetDefault(cfgMetricsPushInterval, metrics.DefaultPushInterval)
+	v.SetDefault(cfgMetricsPushTimeout, metrics.DefaultPushTimeout)
 
 	// Binding flags
 	if err := v.BindPFlag(cfgPprofEnabled, flags.Lookup(cmdPprof)); err != nil {
@@ -217,6 +719,29 @@ func settings() *viper.Viper {
 		fmt.Printf("%s_%s_[N]_ADDRESS = string\n", Prefix, strings.ToUpper(cfgPeers))
 		fmt.Printf("%s_%s_[N]_WEIGHT = float\n", Prefix, strings.ToUpper(cfgPeers))
 
+		fmt.Println()
+		fmt.Println("Quotas preset:")
+		fmt.Println()
+
+		quotas := strings.ToUpper(strings.Replace(cfgAccountingQuotas, ".", "_", -1))
+		fmt.Printf("%s_%s_[N]_ISSUER = string\n", Prefix, quotas)
+		fmt.Printf("%s_%s_[N]_DAILY_REQUESTS = uint\n", Prefix, quotas)
+		fmt.Printf("%s_%s_[N]_DAILY_BYTES = uint\n", Prefix, quotas)
+		fmt.Printf("%s_%s_[N]_MONTHLY_REQUESTS = uint\n", Prefix, quotas)
+		fmt.Printf("%s_%s_[N]_MONTHLY_BYTES = uint\n", Prefix, quotas)
+
+		fmt.Println()
+		fmt.Println("API keys preset:")
+		fmt.Println()
+
+		apiKeys := strings.ToUpper(strings.Replace(cfgAccountingAPIKeys, ".", "_", -1))
+		fmt.Printf("%s_%s_[N]_KEY = string\n", Prefix, apiKeys)
+		fmt.Printf("%s_%s_[N]_NAME = string\n", Prefix, apiKeys)
+		fmt.Printf("%s_%s_[N]_DAILY_REQUESTS = uint\n", Prefix, apiKeys)
+		fmt.Printf("%s_%s_[N]_DAILY_BYTES = uint\n", Prefix, apiKeys)
+		fmt.Printf("%s_%s_[N]_MONTHLY_REQUESTS = uint\n", Prefix, apiKeys)
+		fmt.Printf("%s_%s_[N]_MONTHLY_BYTES = uint\n", Prefix, apiKeys)
+
 		os.Exit(0)
 	case version != nil && *version:
 		fmt.Printf("NeoFS HTTP Gateway\nVersion: %s\nGoVersion: %s\n", Version, runtime.Version())
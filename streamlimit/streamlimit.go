@@ -0,0 +1,189 @@
+// Package streamlimit caps the number of simultaneous payload streams of the
+// same object and of the same container, so a single viral object doesn't
+// overload storage nodes holding it. Streams over the limit either wait for
+// a free slot or are rejected at once.
+package streamlimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// Overflow handling modes.
+const (
+	// OverflowReject fails streams over the limit with ErrLimited at once.
+	OverflowReject = "reject"
+	// OverflowQueue makes streams over the limit wait for a free slot up to
+	// the queue timeout.
+	OverflowQueue = "queue"
+)
+
+// DefaultQueueTimeout is the default time streams wait for a free slot.
+const DefaultQueueTimeout = 5 * time.Second
+
+// ErrLimited is returned when the stream can't be opened because of the
+// limits.
+var ErrLimited = errors.New("too many simultaneous streams")
+
+// ErrCodeLimited is the error code of requests rejected with ErrLimited.
+const ErrCodeLimited = "TOO_MANY_STREAMS"
+
+// Config contains stream limits.
+type Config struct {
+	// PerObject is the maximum number of streams of the same object, zero
+	// means unlimited.
+	PerObject int
+	// PerContainer is the maximum number of streams of the container
+	// objects, zero means unlimited.
+	PerContainer int
+	// Overflow is the handling mode of streams over the limit.
+	Overflow string
+	// QueueTimeout is the maximum time streams wait for a free slot in
+	// OverflowQueue mode.
+	QueueTimeout time.Duration
+}
+
+// Backend limits payload streams of the wrapped backend, a stream holds its
+// slot until the payload reader is closed.
+type Backend struct {
+	backend.Backend
+	cfg atomic.Pointer[Config]
+
+	mu         sync.Mutex
+	objects    map[oid.Address]int
+	containers map[cid.ID]int
+	// released is closed and replaced when a slot is released.
+	released chan struct{}
+}
+
+// NewBackend wraps the backend limiting its streams.
+func NewBackend(b backend.Backend, cfg Config) *Backend {
+	res := &Backend{
+		Backend:    b,
+		objects:    make(map[oid.Address]int),
+		containers: make(map[cid.ID]int),
+		released:   make(chan struct{}),
+	}
+	res.SetConfig(cfg)
+	return res
+}
+
+// SetConfig updates the limits, streams opened already keep their slots.
+func (b *Backend) SetConfig(cfg Config) {
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = DefaultQueueTimeout
+	}
+	b.cfg.Store(&cfg)
+}
+
+// ObjectGetInit implements backend.Backend.
+func (b *Backend) ObjectGetInit(ctx context.Context, containerID cid.ID, objectID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	release, err := b.acquire(ctx, containerID, objectID)
+	if err != nil {
+		return object.Object{}, nil, err
+	}
+	hdr, r, err := b.Backend.ObjectGetInit(ctx, containerID, objectID, signer, prm)
+	if err != nil {
+		release()
+		return hdr, r, err
+	}
+	return hdr, &releasingReader{ReadCloser: r, release: release}, nil
+}
+
+// ObjectRangeInit implements backend.Backend.
+func (b *Backend) ObjectRangeInit(ctx context.Context, containerID cid.ID, objectID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error) {
+	release, err := b.acquire(ctx, containerID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := b.Backend.ObjectRangeInit(ctx, containerID, objectID, offset, length, signer, prm)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releasingReader{ReadCloser: r, release: release}, nil
+}
+
+// acquire takes slots of the object and its container, the returned function
+// releases them.
+func (b *Backend) acquire(ctx context.Context, containerID cid.ID, objectID oid.ID) (func(), error) {
+	cfg := b.cfg.Load()
+	if cfg.PerObject <= 0 && cfg.PerContainer <= 0 {
+		return func() {}, nil
+	}
+
+	var addr oid.Address
+	addr.SetContainer(containerID)
+	addr.SetObject(objectID)
+
+	var timeout <-chan time.Time
+	for {
+		b.mu.Lock()
+		if (cfg.PerObject <= 0 || b.objects[addr] < cfg.PerObject) &&
+			(cfg.PerContainer <= 0 || b.containers[containerID] < cfg.PerContainer) {
+			b.objects[addr]++
+			b.containers[containerID]++
+			b.mu.Unlock()
+			return b.releaseFunc(addr), nil
+		}
+		released := b.released
+		b.mu.Unlock()
+
+		if cfg.Overflow != OverflowQueue {
+			return nil, ErrLimited
+		}
+		if timeout == nil {
+			t := time.NewTimer(cfg.QueueTimeout)
+			defer t.Stop()
+			timeout = t.C
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, ErrLimited
+		case <-released:
+		}
+	}
+}
+
+func (b *Backend) releaseFunc(addr oid.Address) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.objects[addr]--; b.objects[addr] == 0 {
+				delete(b.objects, addr)
+			}
+			cnrID := addr.Container()
+			if b.containers[cnrID]--; b.containers[cnrID] == 0 {
+				delete(b.containers, cnrID)
+			}
+			close(b.released)
+			b.released = make(chan struct{})
+		})
+	}
+}
+
+type releasingReader struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releasingReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
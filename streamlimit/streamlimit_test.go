@@ -0,0 +1,121 @@
+package streamlimit
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func putObject(t *testing.T, mem *backend.Memory, cnrID cid.ID, payload string) oid.ID {
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	_, err = w.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.StoredObjectID()
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	first, second, third := putObject(t, mem, cnrID, "1"), putObject(t, mem, cnrID, "2"), putObject(t, mem, cnrID, "3")
+
+	b := NewBackend(mem, Config{PerObject: 1, PerContainer: 2, Overflow: OverflowReject})
+	get := func(id oid.ID) (io.ReadCloser, error) {
+		_, r, err := b.ObjectGetInit(ctx, cnrID, id, nil, client.PrmObjectGet{})
+		return r, err
+	}
+
+	r1, err := get(first)
+	require.NoError(t, err)
+	_, err = get(first)
+	require.ErrorIs(t, err, ErrLimited, "object limit")
+	_, err = b.ObjectRangeInit(ctx, cnrID, first, 0, 1, nil, client.PrmObjectRange{})
+	require.ErrorIs(t, err, ErrLimited, "ranges are limited too")
+
+	r2, err := b.ObjectRangeInit(ctx, cnrID, second, 0, 1, nil, client.PrmObjectRange{})
+	require.NoError(t, err)
+	_, err = get(third)
+	require.ErrorIs(t, err, ErrLimited, "container limit")
+
+	// slots are released on close, twice closed readers release them once
+	require.NoError(t, r1.Close())
+	require.NoError(t, r1.Close())
+	r3, err := get(third)
+	require.NoError(t, err)
+	_, err = get(first)
+	require.ErrorIs(t, err, ErrLimited)
+	require.NoError(t, r2.Close())
+	require.NoError(t, r3.Close())
+	require.Empty(t, b.objects)
+	require.Empty(t, b.containers)
+
+	// failed streams don't hold slots
+	_, err = get(oidtest.ID())
+	require.ErrorIs(t, err, apistatus.ErrObjectNotFound)
+	require.Empty(t, b.objects)
+
+	// other containers aren't affected
+	other := mem.AddContainer(containertest.Container(t))
+	r1, err = get(first)
+	require.NoError(t, err)
+	_, r, err := b.ObjectGetInit(ctx, other, putObject(t, mem, other, "1"), nil, client.PrmObjectGet{})
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.NoError(t, r1.Close())
+
+	b.SetConfig(Config{})
+	for i := 0; i < 3; i++ {
+		_, err = get(first)
+		require.NoError(t, err, "unlimited")
+	}
+}
+
+func TestBackendQueue(t *testing.T) {
+	ctx := context.Background()
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	id := putObject(t, mem, cnrID, "meow")
+
+	b := NewBackend(mem, Config{PerObject: 1, Overflow: OverflowQueue, QueueTimeout: 10 * time.Millisecond})
+	_, r, err := b.ObjectGetInit(ctx, cnrID, id, nil, client.PrmObjectGet{})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, _, err = b.ObjectGetInit(ctx, cnrID, id, nil, client.PrmObjectGet{})
+	require.ErrorIs(t, err, ErrLimited)
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = b.ObjectGetInit(canceled, cnrID, id, nil, client.PrmObjectGet{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the queued stream gets the released slot
+	b.SetConfig(Config{PerObject: 1, Overflow: OverflowQueue, QueueTimeout: time.Second})
+	done := make(chan error)
+	go func() {
+		_, r, err := b.ObjectGetInit(ctx, cnrID, id, nil, client.PrmObjectGet{})
+		if err == nil {
+			err = r.Close()
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, r.Close())
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/tenants"
+	"go.uber.org/zap"
+)
+
+// initTenants loads tenant configuration fragments on start, the directory
+// is watched for changes if the reload interval is set.
+func (a *app) initTenants() {
+	a.tenantsDir = a.cfg.GetString(cfgTenantsDir)
+	a.tenantsChanged = make(chan struct{}, 1)
+	if err := a.loadTenants(); err != nil {
+		a.log.Fatal("failed to load tenants", zap.String("dir", a.tenantsDir), zap.Error(err))
+	}
+}
+
+// loadTenants reads tenant configuration fragments, previously loaded tenants
+// are kept on failure.
+func (a *app) loadTenants() error {
+	if a.tenantsDir == "" {
+		return nil
+	}
+	res, err := tenants.Load(a.tenantsDir)
+	if err != nil {
+		return err
+	}
+
+	global := a.cfg.GetStringMapString(cfgAliases)
+	for _, t := range res {
+		for alias := range t.Aliases {
+			if _, ok := global[alias]; ok {
+				a.log.Warn("tenant alias is overridden by the gate configuration",
+					zap.String("tenant", t.Name), zap.String("alias", alias))
+			}
+		}
+	}
+	a.tenants = res
+	a.log.Info("tenants loaded", zap.String("dir", a.tenantsDir), zap.Int("count", len(res)))
+	return nil
+}
+
+// watchTenants notifies the serving loop about changes of tenant fragments
+// until the context is done.
+func (a *app) watchTenants(ctx context.Context) {
+	interval := a.cfg.GetDuration(cfgTenantsReloadInterval)
+	if a.tenantsDir == "" || interval <= 0 {
+		return
+	}
+	go tenants.Watch(ctx, a.tenantsDir, interval, func() {
+		select {
+		case a.tenantsChanged <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// reloadTenants applies changed tenant fragments: container aliases, quotas
+// and access rules.
+func (a *app) reloadTenants(ctx context.Context) {
+	if err := a.loadTenants(); err != nil {
+		a.log.Warn("failed to reload tenants", zap.String("dir", a.tenantsDir), zap.Error(err))
+		return
+	}
+	if err := a.resolverContainer.UpdateResolvers(ctx, a.resolverConfig()); err != nil {
+		a.log.Warn("failed to update resolvers", zap.Error(err))
+	}
+	if err := a.updateAccessRules(); err != nil {
+		a.log.Warn("failed to update access rules", zap.Error(err))
+	}
+	a.usage.SetQuotas(a.quotas())
+	a.settings.Downloader.SetCanonicalLinks(a.canonicalLinks(a.externalURL()))
+}
+
+// aliases returns static container aliases of the gate and its tenants,
+// aliases of the gate configuration take precedence.
+func (a *app) aliases() map[string]string {
+	res := a.cfg.GetStringMapString(cfgAliases)
+	for _, t := range a.tenants {
+		for alias, cnr := range t.Aliases {
+			alias = strings.ToLower(alias)
+			if _, ok := res[alias]; !ok {
+				res[alias] = cnr
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/usage"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTenants(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme.yaml"), []byte(`
+aliases:
+  photos: B7GfbnhQGnx3W8sUCYdzaYQUUUeW8wUK8nFmWzrHBwxR
+  docs: 6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ
+quotas:
+  - issuer: NbUgTSFvPmsRxmGeWpuuGeJUoRoi6PErcM
+    daily_bytes: 1024
+  - issuer: NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP
+    daily_bytes: 1024
+access_control:
+  - routes: [upload]
+    allow: [10.0.0.0/8]
+`), 0o600))
+
+	a := &app{log: zap.NewNop(), cfg: viper.New()}
+	a.cfg.Set(cfgTenantsDir, dir)
+	a.cfg.Set(cfgAliases, map[string]string{"docs": "BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K"})
+	a.cfg.Set(cfgAccountingQuotas+".0.issuer", "NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP")
+	a.cfg.Set(cfgAccountingQuotas+".0.daily_bytes", 1)
+	a.initTenants()
+
+	// the gate configuration takes precedence
+	require.Equal(t, map[string]string{
+		"photos": "B7GfbnhQGnx3W8sUCYdzaYQUUUeW8wUK8nFmWzrHBwxR",
+		"docs":   "BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K",
+	}, a.aliases())
+	require.Equal(t, map[string]usage.Quota{
+		"NbUgTSFvPmsRxmGeWpuuGeJUoRoi6PErcM": {Daily: usage.Limits{Bytes: 1024}},
+		"NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP": {Daily: usage.Limits{Bytes: 1}},
+	}, a.quotas().Issuers)
+
+	require.NoError(t, a.updateAccessRules())
+	rules := *a.accessRules.Load()
+	res, err := resolver.NewStaticResolver(a.aliases())
+	require.NoError(t, err)
+	var photos cid.ID
+	require.NoError(t, photos.DecodeString("B7GfbnhQGnx3W8sUCYdzaYQUUUeW8wUK8nFmWzrHBwxR"))
+	other := cidtest.ID()
+	_, allowed := rules.check(context.Background(), "upload", &photos, net.ParseIP("203.0.113.1"), res)
+	require.False(t, allowed)
+	_, allowed = rules.check(context.Background(), "upload", &other, net.ParseIP("203.0.113.1"), res)
+	require.True(t, allowed)
+
+	// broken fragments don't replace loaded tenants
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("aliases: ["), 0o600))
+	require.Error(t, a.loadTenants())
+	require.Len(t, a.tenants, 1)
+}
@@ -0,0 +1,200 @@
+// Package tenants loads per-tenant configuration fragments from a directory,
+// so operators manage tenants (their container aliases, quotas and access
+// rules) in separate files without touching the gate configuration.
+package tenants
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/usage"
+	"github.com/spf13/viper"
+)
+
+// AccessRule is a rule of route access by client addresses, see the gate
+// access_control configuration section.
+type AccessRule struct {
+	Routes []string
+	Allow  []string
+	Deny   []string
+}
+
+// Tenant is a configuration fragment of a single tenant.
+type Tenant struct {
+	// Name is set in the file or is the file name without extension.
+	Name string
+	// Containers are IDs or names of the tenant containers in addition to
+	// the aliased ones.
+	Containers []string
+	// Aliases are static container aliases (lowercase) of the tenant.
+	Aliases map[string]string
+	// Quotas are usage quotas of issuers of the tenant.
+	Quotas map[string]usage.Quota
+	// AccessRules are added to the gate access rules, they're applied to
+	// requests to the tenant containers only.
+	AccessRules []AccessRule
+}
+
+// ContainerNames returns containers of the tenant as they're used in request
+// paths: listed ones, aliases and aliased container IDs.
+func (t Tenant) ContainerNames() []string {
+	res := make([]string, 0, len(t.Containers)+2*len(t.Aliases))
+	res = append(res, t.Containers...)
+	for alias, cnr := range t.Aliases {
+		res = append(res, alias, cnr)
+	}
+	return res
+}
+
+// isFragment checks whether the file is a tenant configuration fragment.
+func isFragment(name string) bool {
+	ext := filepath.Ext(name)
+	return !strings.HasPrefix(name, ".") && (ext == ".yaml" || ext == ".yml")
+}
+
+// Load reads tenants from YAML files (*.yaml, *.yml) of the directory in the
+// order of file names. Tenant names and aliases must be unique.
+func Load(dir string) ([]Tenant, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res     []Tenant
+		names   = make(map[string]string)
+		aliases = make(map[string]string)
+	)
+	for _, e := range entries {
+		if e.IsDir() || !isFragment(e.Name()) {
+			continue
+		}
+		t, err := loadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		if other, ok := names[t.Name]; ok {
+			return nil, fmt.Errorf("%s: tenant '%s' is already defined in %s", e.Name(), t.Name, other)
+		}
+		names[t.Name] = e.Name()
+		for alias := range t.Aliases {
+			if other, ok := aliases[alias]; ok {
+				return nil, fmt.Errorf("%s: alias '%s' is already defined by tenant '%s'", e.Name(), alias, other)
+			}
+			aliases[alias] = t.Name
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+func loadFile(path string) (Tenant, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Tenant{}, err
+	}
+	defer f.Close()
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err = v.ReadConfig(f); err != nil {
+		return Tenant{}, err
+	}
+
+	t := Tenant{
+		Name:       v.GetString("name"),
+		Containers: v.GetStringSlice("containers"),
+		Aliases:    v.GetStringMapString("aliases"),
+		Quotas:     make(map[string]usage.Quota),
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	for i := 0; ; i++ {
+		key := "quotas." + strconv.Itoa(i) + "."
+		issuer := v.GetString(key + "issuer")
+		if issuer == "" {
+			break
+		}
+		t.Quotas[issuer] = usage.Quota{
+			Daily: usage.Limits{
+				Requests: v.GetUint64(key + "daily_requests"),
+				Bytes:    v.GetUint64(key + "daily_bytes"),
+			},
+			Monthly: usage.Limits{
+				Requests: v.GetUint64(key + "monthly_requests"),
+				Bytes:    v.GetUint64(key + "monthly_bytes"),
+			},
+		}
+	}
+
+	for i := 0; ; i++ {
+		key := "access_control." + strconv.Itoa(i) + "."
+		routes := v.GetStringSlice(key + "routes")
+		if len(routes) == 0 {
+			break
+		}
+		t.AccessRules = append(t.AccessRules, AccessRule{
+			Routes: routes,
+			Allow:  v.GetStringSlice(key + "allow"),
+			Deny:   v.GetStringSlice(key + "deny"),
+		})
+	}
+
+	return t, nil
+}
+
+// snapshot describes the state of the directory fragments, it's changed when
+// fragments are added, removed or modified.
+func snapshot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, e := range entries {
+		if e.IsDir() || !isFragment(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, e.Name()+":"+strconv.FormatInt(info.Size(), 10)+":"+
+			strconv.FormatInt(info.ModTime().UnixNano(), 10))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n"), nil
+}
+
+// Watch polls the directory with the given interval and calls onChange when
+// its fragments are changed until the context is done. Polling errors are
+// ignored, the directory is checked again on the next tick.
+func Watch(ctx context.Context, dir string, interval time.Duration, onChange func()) {
+	last, _ := snapshot(dir)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		cur, err := snapshot(dir)
+		if err != nil || cur == last {
+			continue
+		}
+		last = cur
+		onChange()
+	}
+}
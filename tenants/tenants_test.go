@@ -0,0 +1,123 @@
+package tenants
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/usage"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, data string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "acme.yaml", `
+containers:
+  - 6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ
+aliases:
+  Photos: B7GfbnhQGnx3W8sUCYdzaYQUUUeW8wUK8nFmWzrHBwxR
+quotas:
+  - issuer: NbUgTSFvPmsRxmGeWpuuGeJUoRoi6PErcM
+    daily_bytes: 1024
+    monthly_requests: 10
+access_control:
+  - routes: [upload]
+    allow: [10.0.0.0/8]
+`)
+	writeFile(t, dir, "b.yml", `
+name: beta
+aliases:
+  docs: 6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ
+`)
+	writeFile(t, dir, "notes.txt", "not a tenant")
+	writeFile(t, dir, ".hidden.yaml", "name: hidden")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.yaml"), 0o700))
+
+	res, err := Load(dir)
+	require.NoError(t, err)
+	require.Equal(t, []Tenant{
+		{
+			Name:       "acme",
+			Containers: []string{"6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ"},
+			Aliases:    map[string]string{"photos": "B7GfbnhQGnx3W8sUCYdzaYQUUUeW8wUK8nFmWzrHBwxR"},
+			Quotas: map[string]usage.Quota{
+				"NbUgTSFvPmsRxmGeWpuuGeJUoRoi6PErcM": {
+					Daily:   usage.Limits{Bytes: 1024},
+					Monthly: usage.Limits{Requests: 10},
+				},
+			},
+			AccessRules: []AccessRule{{Routes: []string{"upload"}, Allow: []string{"10.0.0.0/8"}}},
+		},
+		{
+			Name:    "beta",
+			Aliases: map[string]string{"docs": "6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ"},
+			Quotas:  map[string]usage.Quota{},
+		},
+	}, res)
+
+	t.Run("duplicate alias", func(t *testing.T) {
+		writeFile(t, dir, "c.yaml", "aliases:\n  photos: 6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ\n")
+		_, err := Load(dir)
+		require.ErrorContains(t, err, "alias 'photos'")
+		require.NoError(t, os.Remove(filepath.Join(dir, "c.yaml")))
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		writeFile(t, dir, "c.yaml", "name: acme\n")
+		_, err := Load(dir)
+		require.ErrorContains(t, err, "tenant 'acme'")
+		require.NoError(t, os.Remove(filepath.Join(dir, "c.yaml")))
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		writeFile(t, dir, "c.yaml", "aliases: [")
+		_, err := Load(dir)
+		require.ErrorContains(t, err, "c.yaml")
+		require.NoError(t, os.Remove(filepath.Join(dir, "c.yaml")))
+	})
+
+	require.ElementsMatch(t, []string{
+		"6Hd7DBSzMfY3d5E1VAwpL3U3mzXpHAXVmd8qZYtvfFwQ",
+		"photos", "B7GfbnhQGnx3W8sUCYdzaYQUUUeW8wUK8nFmWzrHBwxR",
+	}, res[0].ContainerNames())
+
+	_, err = Load(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "acme.yaml", "name: acme\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	go Watch(ctx, dir, time.Millisecond, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	// files not being fragments are ignored
+	writeFile(t, dir, "notes.txt", "note")
+	select {
+	case <-changed:
+		t.Fatal("unexpected change")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	writeFile(t, dir, "beta.yaml", "name: beta\n")
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change isn't detected")
+	}
+}
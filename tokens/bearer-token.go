@@ -49,12 +49,16 @@ func BearerTokenFromCookie(h *fasthttp.RequestHeader) []byte {
 }
 
 // StoreBearerToken extracts a bearer token from the header or cookie and stores
-// it in the request context.
+// it in the request context. Requests without tokens get the default one if
+// it's configured for their container (see StoreDefaults).
 func StoreBearerToken(ctx *fasthttp.RequestCtx) error {
 	tkn, err := fetchBearerToken(ctx)
 	if err != nil {
 		return err
 	}
+	if tkn == nil && ctx != nil {
+		tkn = defaultToken(ctx)
+	}
 	// This is an analog of context.WithValue.
 	ctx.SetUserValue(bearerTokenKey, tkn)
 	return nil
@@ -74,6 +78,13 @@ func fetchBearerToken(ctx *fasthttp.RequestCtx) (*bearer.Token, error) {
 	if ctx == nil {
 		return nil, nil
 	}
+	return ParseBearerToken(&ctx.Request.Header)
+}
+
+// ParseBearerToken parses a bearer token from the header or cookie. It's used
+// when the token is needed before the request is handled, e.g. to check
+// permissions before reading the body. Nil is returned if there is no token.
+func ParseBearerToken(h *fasthttp.RequestHeader) (*bearer.Token, error) {
 	var (
 		lastErr error
 
@@ -81,7 +92,7 @@ func fetchBearerToken(ctx *fasthttp.RequestCtx) (*bearer.Token, error) {
 		tkn = new(bearer.Token)
 	)
 	for _, parse := range []fromHandler{BearerTokenFromHeader, BearerTokenFromCookie} {
-		if buf = parse(&ctx.Request.Header); buf == nil {
+		if buf = parse(h); buf == nil {
 			continue
 		} else if data, err := base64.StdEncoding.DecodeString(string(buf)); err != nil {
 			lastErr = fmt.Errorf("can't base64-decode bearer token: %w", err)
@@ -0,0 +1,119 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultsKey = "__context_bearer_defaults_key"
+
+// Defaults is a bearer token attached to requests without their own tokens
+// sent by trusted clients to the configured containers, it lets internal
+// clients access private containers via the gate without handling tokens.
+type Defaults struct {
+	token      *bearer.Token
+	containers []string
+	clients    []netip.Prefix
+}
+
+// NewDefaults creates defaults attaching the token to requests of the clients
+// from the networks given to the containers (IDs or names).
+func NewDefaults(tkn *bearer.Token, containers []string, clients []netip.Prefix) *Defaults {
+	return &Defaults{token: tkn, containers: containers, clients: clients}
+}
+
+// ReadDefaults reads the signed bearer token from the file in binary, JSON or
+// base64 format and creates defaults for the containers and the clients.
+func ReadDefaults(path string, containers []string, clients []netip.Prefix) (*Defaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read bearer token file: %w", err)
+	}
+
+	tkn, err := decodeToken(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode bearer token file: %w", err)
+	}
+	if !tkn.VerifySignature() {
+		return nil, errors.New("invalid bearer token signature")
+	}
+	return NewDefaults(tkn, containers, clients), nil
+}
+
+func decodeToken(data []byte) (*bearer.Token, error) {
+	var tkn bearer.Token
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return &tkn, tkn.UnmarshalJSON(trimmed)
+	}
+	if raw, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil && tkn.Unmarshal(raw) == nil {
+		return &tkn, nil
+	}
+	return &tkn, tkn.Unmarshal(data)
+}
+
+// Token returns the default token, nil if defaults aren't configured.
+func (d *Defaults) Token() *bearer.Token {
+	if d == nil {
+		return nil
+	}
+	return d.token
+}
+
+// Trusts checks whether the token can be attached to requests of the client.
+func (d *Defaults) Trusts(addr netip.Addr) bool {
+	if d == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range d.clients {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasContainer checks whether the token can be attached to requests to the
+// container. Configured names are resolved with res, so a container matches
+// whether it's requested by ID or by name.
+func (d *Defaults) HasContainer(ctx context.Context, cnr cid.ID, res resolver.Resolver) bool {
+	if d == nil {
+		return false
+	}
+	for _, name := range d.containers {
+		var id cid.ID
+		if id.DecodeString(name) != nil {
+			var err error
+			if id, err = res.Resolve(ctx, name); err != nil {
+				continue
+			}
+		}
+		if id.Equals(cnr) {
+			return true
+		}
+	}
+	return false
+}
+
+// StoreDefault stores the default token for the request in the context,
+// StoreBearerToken uses it if the request has no token of its own.
+func StoreDefault(ctx *fasthttp.RequestCtx, tkn *bearer.Token) {
+	ctx.SetUserValue(defaultsKey, tkn)
+}
+
+// defaultToken returns the default token stored for the request.
+func defaultToken(ctx *fasthttp.RequestCtx) *bearer.Token {
+	tkn, _ := ctx.UserValue(defaultsKey).(*bearer.Token)
+	return tkn
+}
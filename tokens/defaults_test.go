@@ -0,0 +1,101 @@
+package tokens
+
+import (
+	"context"
+	"encoding/base64"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func signedToken(t *testing.T) *bearer.Token {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	tkn := new(bearer.Token)
+	require.NoError(t, tkn.Sign(user.NewAutoIDSigner(key.PrivateKey)))
+	return tkn
+}
+
+func TestReadDefaults(t *testing.T) {
+	tkn := signedToken(t)
+	jsonData, err := tkn.MarshalJSON()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"binary": tkn.Marshal(),
+		"json":   jsonData,
+		"base64": []byte(base64.StdEncoding.EncodeToString(tkn.Marshal()) + "\n"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, data, 0o600))
+			d, err := ReadDefaults(path, []string{"private"}, nil)
+			require.NoError(t, err)
+			require.Equal(t, tkn.Marshal(), d.Token().Marshal())
+		})
+	}
+
+	_, err = ReadDefaults(filepath.Join(dir, "missing"), nil, nil)
+	require.Error(t, err)
+
+	var unsigned bearer.Token
+	path := filepath.Join(dir, "unsigned")
+	require.NoError(t, os.WriteFile(path, unsigned.Marshal(), 0o600))
+	_, err = ReadDefaults(path, nil, nil)
+	require.Error(t, err)
+
+	var d *Defaults
+	require.Nil(t, d.Token())
+	require.False(t, d.Trusts(netip.MustParseAddr("127.0.0.1")))
+	require.False(t, d.HasContainer(context.Background(), cidtest.ID(), nil))
+}
+
+func TestDefaultsMatch(t *testing.T) {
+	private, public := cidtest.ID(), cidtest.ID()
+	res, err := resolver.NewStaticResolver(map[string]string{"private": private.EncodeToString()})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	d := NewDefaults(signedToken(t), []string{"private", "unknown"}, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	require.True(t, d.HasContainer(ctx, private, res), "names are compared by resolved IDs")
+	require.False(t, d.HasContainer(ctx, public, res))
+
+	d = NewDefaults(signedToken(t), []string{private.EncodeToString()}, nil)
+	require.True(t, d.HasContainer(ctx, private, res))
+
+	d = NewDefaults(signedToken(t), nil, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	require.True(t, d.Trusts(netip.MustParseAddr("10.1.2.3")))
+	require.True(t, d.Trusts(netip.MustParseAddr("::ffff:10.1.2.3")))
+	require.False(t, d.Trusts(netip.MustParseAddr("192.168.1.1")))
+}
+
+func TestStoreBearerTokenDefaults(t *testing.T) {
+	def, own := signedToken(t), signedToken(t)
+
+	load := func(def, tkn *bearer.Token) *bearer.Token {
+		var c fasthttp.RequestCtx
+		if tkn != nil {
+			c.Request.Header.Set(fasthttp.HeaderAuthorization, bearerTokenHdr+" "+base64.StdEncoding.EncodeToString(tkn.Marshal()))
+		}
+		if def != nil {
+			StoreDefault(&c, def)
+		}
+		require.NoError(t, StoreBearerToken(&c))
+		res, _ := LoadBearerToken(&c)
+		return res
+	}
+
+	require.Equal(t, def.Marshal(), load(def, nil).Marshal())
+	require.Equal(t, own.Marshal(), load(def, own).Marshal(), "own token is preferred")
+	require.Nil(t, load(nil, nil))
+}
@@ -0,0 +1,66 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxStderr limits the command error output kept for error messages.
+const maxStderr = 1024
+
+// DefaultConcurrency is the default number of commands run by the hook at the
+// same time.
+const DefaultConcurrency = 4
+
+// Exec is the transformer running the external command, the payload is
+// written to its standard input and the result is read from its standard
+// output. The command is killed if it doesn't finish within the timeout
+// (zero means no limit) or the transformation is canceled.
+type Exec struct {
+	Path    string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Transform runs the command.
+func (e Exec) Transform(ctx context.Context, w io.Writer, r io.Reader) error {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	var stderr limitedBuffer
+	cmd := exec.CommandContext(ctx, e.Path, e.Args...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", e.Path, err, msg)
+		}
+		return fmt.Errorf("%s: %w", e.Path, err)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first maxStderr bytes written to it.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if left := maxStderr - b.Len(); left > 0 {
+		if len(p) > left {
+			b.Buffer.Write(p[:left])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
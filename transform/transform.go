@@ -0,0 +1,173 @@
+// Package transform converts object payloads sent to clients on the fly, e.g.
+// to render previews of documents.
+package transform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Transformer converts the payload read from r writing the result to w. The
+// payload is streamed, so transformers must not expect it to fit in memory.
+type Transformer interface {
+	Transform(ctx context.Context, w io.Writer, r io.Reader) error
+}
+
+// Func is an adapter to use ordinary functions as transformers.
+type Func func(ctx context.Context, w io.Writer, r io.Reader) error
+
+// Transform calls f(ctx, w, r).
+func (f Func) Transform(ctx context.Context, w io.Writer, r io.Reader) error {
+	return f(ctx, w, r)
+}
+
+// Hook is the named transformer applied to payloads of the given content
+// types.
+type Hook struct {
+	// Name is used to select the hook in requests.
+	Name string
+	// Types are media types of transformed payloads, 'text/*' matches all
+	// subtypes. Empty list matches any type.
+	Types []string
+	// ContentType is the type of the transformation result, empty type
+	// keeps the payload one.
+	ContentType string
+	// Concurrency limits the number of transformations by the hook running
+	// at the same time, zero means no limit.
+	Concurrency int
+	Transformer Transformer
+}
+
+// Matches checks whether the hook transforms payloads of the content type.
+func (h Hook) Matches(contentType string) bool {
+	if len(h.Types) == 0 {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, t := range h.Types {
+		t = strings.ToLower(t)
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrDuplicateHook is returned on registration of the hook with the name
+// already registered.
+var ErrDuplicateHook = errors.New("duplicate transformation hook")
+
+// Hooks is a registry of transformation hooks. Nil Hooks has no hooks.
+type Hooks struct {
+	mu    sync.RWMutex
+	hooks map[string]Hook
+	// slots are semaphores of hooks with limited concurrency
+	slots map[string]chan struct{}
+}
+
+// NewHooks is a constructor for the Hooks.
+func NewHooks() *Hooks {
+	return &Hooks{hooks: make(map[string]Hook), slots: make(map[string]chan struct{})}
+}
+
+// Register adds the hook to the registry, hooks are registered before the
+// gateway starts serving requests, e.g. by applications embedding it.
+func (h *Hooks) Register(hook Hook) error {
+	if hook.Name == "" {
+		return errors.New("empty transformation hook name")
+	}
+	if hook.Transformer == nil {
+		return fmt.Errorf("transformation hook '%s' has no transformer", hook.Name)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.hooks[hook.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateHook, hook.Name)
+	}
+	h.hooks[hook.Name] = hook
+	if hook.Concurrency > 0 {
+		h.slots[hook.Name] = make(chan struct{}, hook.Concurrency)
+	}
+	return nil
+}
+
+// Acquire takes the slot for the transformation by the hook with the given
+// name, false is returned if the hook runs the maximum number of
+// transformations already. The slot is freed by the returned function, it's
+// taken before the response is started, so busy hooks can be reported.
+func (h *Hooks) Acquire(name string) (func(), bool) {
+	if h == nil {
+		return func() {}, true
+	}
+	h.mu.RLock()
+	slots, ok := h.slots[name]
+	h.mu.RUnlock()
+	if !ok {
+		return func() {}, true
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	default:
+		return nil, false
+	}
+}
+
+// Get returns the hook with the given name.
+func (h *Hooks) Get(name string) (Hook, bool) {
+	if h == nil {
+		return Hook{}, false
+	}
+	h.mu.RLock()
+	hook, ok := h.hooks[name]
+	h.mu.RUnlock()
+	return hook, ok
+}
+
+// Names returns sorted names of the registered hooks.
+func (h *Hooks) Names() []string {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	names := make([]string, 0, len(h.hooks))
+	for name := range h.hooks {
+		names = append(names, name)
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// NewReader returns the reader of the payload transformed in a separate
+// goroutine, the payload is closed when the transformation is finished.
+// Closing the reader stops the transformation.
+func NewReader(ctx context.Context, t Transformer, payload io.ReadCloser) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	go func() {
+		err := t.Transform(ctx, pw, payload)
+		_ = payload.Close()
+		// nil error makes the reader return io.EOF
+		_ = pw.CloseWithError(err)
+	}()
+	return &reader{PipeReader: pr, cancel: cancel}
+}
+
+type reader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *reader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
@@ -0,0 +1,133 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var upper = Func(func(_ context.Context, w io.Writer, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes.ToUpper(data))
+	return err
+})
+
+func TestHookMatches(t *testing.T) {
+	h := Hook{Types: []string{"text/markdown", "image/*"}}
+	for _, typ := range []string{"text/markdown", "Text/Markdown; charset=utf-8", "image/png", "image/svg+xml"} {
+		require.True(t, h.Matches(typ), typ)
+	}
+	for _, typ := range []string{"text/plain", "", "application/image"} {
+		require.False(t, h.Matches(typ), typ)
+	}
+	require.True(t, Hook{}.Matches("application/octet-stream"))
+}
+
+func TestHooks(t *testing.T) {
+	var nilHooks *Hooks
+	_, ok := nilHooks.Get("upper")
+	require.False(t, ok)
+	require.Empty(t, nilHooks.Names())
+
+	hooks := NewHooks()
+	require.NoError(t, hooks.Register(Hook{Name: "upper", Transformer: upper}))
+	require.NoError(t, hooks.Register(Hook{Name: "csv", Transformer: upper}))
+	require.ErrorIs(t, hooks.Register(Hook{Name: "upper", Transformer: upper}), ErrDuplicateHook)
+	require.Error(t, hooks.Register(Hook{Name: "", Transformer: upper}))
+	require.Error(t, hooks.Register(Hook{Name: "none"}))
+
+	h, ok := hooks.Get("upper")
+	require.True(t, ok)
+	require.Equal(t, "upper", h.Name)
+	require.Equal(t, []string{"csv", "upper"}, hooks.Names())
+}
+
+func TestHooksAcquire(t *testing.T) {
+	hooks := NewHooks()
+	require.NoError(t, hooks.Register(Hook{Name: "upper", Concurrency: 2, Transformer: upper}))
+	require.NoError(t, hooks.Register(Hook{Name: "csv", Transformer: upper}))
+
+	first, ok := hooks.Acquire("upper")
+	require.True(t, ok)
+	_, ok = hooks.Acquire("upper")
+	require.True(t, ok)
+	_, ok = hooks.Acquire("upper")
+	require.False(t, ok, "all slots are taken")
+
+	first()
+	_, ok = hooks.Acquire("upper")
+	require.True(t, ok)
+
+	for i := 0; i < 10; i++ {
+		_, ok = hooks.Acquire("csv")
+		require.True(t, ok, "no limit")
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (c closeTracker) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestNewReader(t *testing.T) {
+	payload := closeTracker{Reader: strings.NewReader("meow"), closed: make(chan struct{})}
+	r := NewReader(context.Background(), upper, payload)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "MEOW", string(data))
+	require.NoError(t, r.Close())
+	<-payload.closed
+
+	failed := Func(func(context.Context, io.Writer, io.Reader) error { return errors.New("broken") })
+	r = NewReader(context.Background(), failed, io.NopCloser(strings.NewReader("meow")))
+	_, err = io.ReadAll(r)
+	require.EqualError(t, err, "broken")
+
+	// closing the reader cancels the transformation
+	canceled := make(chan struct{})
+	blocked := Func(func(ctx context.Context, _ io.Writer, _ io.Reader) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	})
+	r = NewReader(context.Background(), blocked, io.NopCloser(strings.NewReader("meow")))
+	require.NoError(t, r.Close())
+	<-canceled
+}
+
+func TestExec(t *testing.T) {
+	path, err := exec.LookPath("tr")
+	if err != nil {
+		t.Skip("tr isn't available")
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Exec{Path: path, Args: []string{"a-z", "A-Z"}}.Transform(context.Background(), &out, strings.NewReader("meow")))
+	require.Equal(t, "MEOW", out.String())
+
+	err = Exec{Path: path}.Transform(context.Background(), &out, strings.NewReader("meow"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), path)
+
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		return
+	}
+	err = Exec{Path: sleep, Args: []string{"10"}, Timeout: 10 * time.Millisecond}.Transform(context.Background(), &out, strings.NewReader(""))
+	require.Error(t, err)
+}
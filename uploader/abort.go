@@ -0,0 +1,57 @@
+package uploader
+
+import (
+	"errors"
+	"io"
+)
+
+// errClientAborted is matched by errors of request body reads failed because
+// the client closed the connection.
+var errClientAborted = errors.New("client aborted upload")
+
+type clientAbortError struct {
+	err error
+}
+
+func (e clientAbortError) Error() string {
+	return errClientAborted.Error() + ": " + e.err.Error()
+}
+
+func (e clientAbortError) Unwrap() error {
+	return e.err
+}
+
+func (e clientAbortError) Is(target error) bool {
+	return target == errClientAborted
+}
+
+// abortReader detects client disconnects while the request body is read. The
+// body is incomplete if it ends before Content-Length bytes are received
+// (length is negative if it's unknown) or the connection fails, abort is
+// called then right away, so the object stream is canceled before the read
+// error reaches the put.
+type abortReader struct {
+	r       io.Reader
+	left    int64
+	abort   func()
+	aborted bool
+}
+
+func newAbortReader(r io.Reader, length int64, abort func()) *abortReader {
+	return &abortReader{r: r, left: length, abort: abort}
+}
+
+func (a *abortReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if a.left >= 0 {
+		a.left -= int64(n)
+	}
+	if err == nil || errors.Is(err, io.EOF) && a.left <= 0 {
+		return n, err
+	}
+	if !a.aborted {
+		a.aborted = true
+		a.abort()
+	}
+	return n, clientAbortError{err: err}
+}
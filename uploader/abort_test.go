@@ -0,0 +1,111 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type abortMetrics struct {
+	aborts int
+}
+
+func (m *abortMetrics) AddDownloadedBytes(int) {}
+
+func (m *abortMetrics) IncDownloadStalls(string) {}
+
+func (m *abortMetrics) IncUploadAborts() {
+	m.aborts++
+}
+
+func TestAbortReader(t *testing.T) {
+	read := func(body string, length int64) (int, error) {
+		var aborts int
+		r := newAbortReader(strings.NewReader(body), length, func() { aborts++ })
+		_, err := io.ReadAll(r)
+		if err == nil {
+			// subsequent reads don't abort again
+			_, _ = r.Read(make([]byte, 1))
+		}
+		return aborts, err
+	}
+
+	for _, length := range []int64{4, -1} {
+		aborts, err := read("meow", length)
+		require.NoError(t, err)
+		require.Zero(t, aborts)
+	}
+
+	aborts, err := read("me", 4)
+	require.ErrorIs(t, err, errClientAborted)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 1, aborts)
+
+	var broken bool
+	r := newAbortReader(io.MultiReader(strings.NewReader("me"), resetReader{}), -1, func() { broken = true })
+	_, err = io.ReadAll(r)
+	require.ErrorIs(t, err, errClientAborted)
+	require.True(t, broken)
+}
+
+type resetReader struct{}
+
+func (resetReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestUploadClientAbort(t *testing.T) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	metrics := new(abortMetrics)
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner, Metrics: metrics}, settings, nil)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "cat.jpg")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte("meow"), 1024))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	var c fasthttp.RequestCtx
+	c.Request.Header.SetMethod(fasthttp.MethodPost)
+	c.Request.Header.SetContentType(mw.FormDataContentType())
+	// the connection is closed in the middle of the file
+	c.Request.SetBodyStream(bytes.NewReader(body.Bytes()[:body.Len()/2]), body.Len())
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	u.Upload(&c)
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	require.Equal(t, 1, metrics.aborts)
+
+	lister, err := mem.ObjectSearchInit(context.Background(), cnrID, nil, object.SearchFilters{}, client.PrmObjectSearch{})
+	require.NoError(t, err)
+	require.NoError(t, lister.Iterate(func(id oid.ID) bool {
+		t.Errorf("incomplete object %s is stored", id)
+		return false
+	}))
+}
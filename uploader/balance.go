@@ -0,0 +1,94 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type balanceResponse struct {
+	Owner     string `json:"owner"`
+	Balance   string `json:"balance"`
+	Value     int64  `json:"value"`
+	Precision uint32 `json:"precision"`
+}
+
+// formatBalance formats the balance as a decimal number, e.g. 1.5 for value
+// 150 and precision 2.
+func formatBalance(d accounting.Decimal) string {
+	val := d.Value()
+	neg := val < 0
+	if neg {
+		val = -val
+	}
+	s := strconv.FormatInt(val, 10)
+	if prec := int(d.Precision()); prec > 0 {
+		if len(s) <= prec {
+			s = strings.Repeat("0", prec-len(s)+1) + s
+		}
+		s = strings.TrimRight(s[:len(s)-prec]+"."+s[len(s)-prec:], "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// balanceErrorStatus returns the response status of the failed balance
+// request: 503 if the request timed out and 502 on other storage failures.
+func balanceErrorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return fasthttp.StatusServiceUnavailable
+	}
+	return fasthttp.StatusBadGateway
+}
+
+// Balance responds with the NeoFS balance of the gate owner, so operators can
+// monitor the storage payment balance. Requests with bearer token get the
+// balance of the token issuer, so the token must be signed by it.
+func (u *Uploader) Balance(c *fasthttp.RequestCtx) {
+	if err := tokens.StoreBearerToken(c); err != nil {
+		u.log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var owner user.ID
+	if btoken, err := tokens.LoadBearerToken(c); err == nil {
+		if !btoken.VerifySignature() {
+			u.log.Error("bearer token is not signed")
+			response.Error(c, "bearer token is not signed by its issuer", fasthttp.StatusUnauthorized)
+			return
+		}
+		owner = btoken.ResolveIssuer()
+	} else if u.ownerID != nil {
+		owner = *u.ownerID
+	}
+
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	balance, err := u.backend.BalanceGet(ctx, owner, client.PrmBalanceGet{})
+	if err != nil {
+		u.log.Error("could not get balance", zap.Stringer("owner", owner), zap.Error(err))
+		response.Error(c, "could not get balance: "+err.Error(), balanceErrorStatus(err))
+		return
+	}
+
+	writeJSON(c, fasthttp.StatusOK, balanceResponse{
+		Owner:     owner.EncodeToString(),
+		Balance:   formatBalance(balance),
+		Value:     balance.Value(),
+		Precision: balance.Precision(),
+	})
+}
@@ -0,0 +1,93 @@
+package uploader
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func decimal(value int64, precision uint32) accounting.Decimal {
+	var d accounting.Decimal
+	d.SetValue(value)
+	d.SetPrecision(precision)
+	return d
+}
+
+func TestFormatBalance(t *testing.T) {
+	for expected, d := range map[string]accounting.Decimal{
+		"0":          decimal(0, 8),
+		"1.5":        decimal(150, 2),
+		"123":        decimal(12300000000, 8),
+		"0.00000001": decimal(1, 8),
+		"-0.25":      decimal(-25, 2),
+		"42":         decimal(42, 0),
+	} {
+		require.Equal(t, expected, formatBalance(d))
+	}
+}
+
+func TestBalance(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	issuer := user.NewAutoIDSigner(key.PrivateKey)
+	owner := usertest.ID(t)
+
+	mem := backend.NewMemory()
+	mem.SetBalance(owner, decimal(150, 2))
+	mem.SetBalance(issuer.UserID(), decimal(7, 1))
+
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, &Settings{}, nil)
+
+	request := func(btoken *bearer.Token) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		if btoken != nil {
+			c.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+base64.StdEncoding.EncodeToString(btoken.Marshal()))
+		}
+		u.Balance(&c)
+		return &c
+	}
+	get := func(btoken *bearer.Token) balanceResponse {
+		c := request(btoken)
+		require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+		var resp balanceResponse
+		require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+		return resp
+	}
+
+	require.Equal(t, balanceResponse{Owner: owner.EncodeToString(), Balance: "1.5", Value: 150, Precision: 2}, get(nil))
+
+	var btoken bearer.Token
+	require.NoError(t, btoken.Sign(issuer))
+	require.Equal(t, balanceResponse{Owner: issuer.UserID().EncodeToString(), Balance: "0.7", Value: 7, Precision: 1}, get(&btoken))
+
+	// issuer of unsigned tokens is unknown
+	var unsigned bearer.Token
+	unsigned.SetExp(100)
+	require.Equal(t, fasthttp.StatusUnauthorized, request(&unsigned).Response.StatusCode())
+
+	u.backend = &failingBalanceBackend{Memory: mem}
+	require.Equal(t, fasthttp.StatusBadGateway, request(nil).Response.StatusCode())
+}
+
+type failingBalanceBackend struct {
+	*backend.Memory
+}
+
+func (b *failingBalanceBackend) BalanceGet(context.Context, user.ID, client.PrmBalanceGet) (accounting.Decimal, error) {
+	return accounting.Decimal{}, errors.New("node is unavailable")
+}
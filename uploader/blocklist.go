@@ -0,0 +1,159 @@
+package uploader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// errCodeContentBlocked is an error code of responses to uploads of
+	// blocked content types or file extensions.
+	errCodeContentBlocked = "CONTENT_BLOCKED"
+
+	// sniffLen is the number of payload bytes used to detect content type,
+	// it's the same as http.DetectContentType considers.
+	sniffLen = 512
+)
+
+// errContentBlocked is returned if the uploaded content is blocked.
+var errContentBlocked = errors.New("content is not allowed")
+
+// executableSignatures are magic numbers of executables not recognized by
+// http.DetectContentType.
+var executableSignatures = []struct {
+	sig         []byte
+	contentType string
+}{
+	{sig: []byte("MZ"), contentType: "application/x-msdownload"},
+	{sig: []byte("\x7fELF"), contentType: "application/x-executable"},
+	{sig: []byte("\xfe\xed\xfa\xce"), contentType: "application/x-mach-binary"},
+	{sig: []byte("\xfe\xed\xfa\xcf"), contentType: "application/x-mach-binary"},
+	{sig: []byte("\xce\xfa\xed\xfe"), contentType: "application/x-mach-binary"},
+	{sig: []byte("\xcf\xfa\xed\xfe"), contentType: "application/x-mach-binary"},
+}
+
+// ContentBlocklist is a set of content types and file extensions not allowed
+// to be uploaded.
+type ContentBlocklist struct {
+	types      map[string]struct{}
+	prefixes   []string
+	extensions map[string]struct{}
+}
+
+// NewContentBlocklist creates a blocklist of media types and file extensions.
+// Types can be given as 'type/*' to block the whole type, extensions are
+// accepted with or without the leading dot. Both are case-insensitive.
+func NewContentBlocklist(types, extensions []string) *ContentBlocklist {
+	b := &ContentBlocklist{
+		types:      make(map[string]struct{}, len(types)),
+		extensions: make(map[string]struct{}, len(extensions)),
+	}
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == "":
+		case strings.HasSuffix(t, "/*"):
+			b.prefixes = append(b.prefixes, strings.TrimSuffix(t, "*"))
+		default:
+			b.types[t] = struct{}{}
+		}
+	}
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		b.extensions[ext] = struct{}{}
+	}
+	return b
+}
+
+// Empty checks whether nothing is blocked.
+func (b *ContentBlocklist) Empty() bool {
+	return b == nil || len(b.types) == 0 && len(b.prefixes) == 0 && len(b.extensions) == 0
+}
+
+func (b *ContentBlocklist) blockedType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	if _, ok := b.types[mediaType]; ok {
+		return true
+	}
+	for _, p := range b.prefixes {
+		if strings.HasPrefix(mediaType, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *ContentBlocklist) blockedName(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return false
+	}
+	_, ok := b.extensions[ext]
+	return ok
+}
+
+// check checks file names, declared content types and the content type
+// detected from the beginning of the payload. The returned reader contains the
+// whole payload.
+func (b *ContentBlocklist) check(r io.Reader, names, types []string) (io.Reader, error) {
+	if b.Empty() {
+		return r, nil
+	}
+
+	for _, name := range names {
+		if b.blockedName(name) {
+			return r, fmt.Errorf("%w: file extension of '%s'", errContentBlocked, name)
+		}
+	}
+	for _, t := range types {
+		if b.blockedType(t) {
+			return r, fmt.Errorf("%w: content type '%s'", errContentBlocked, t)
+		}
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return r, fmt.Errorf("read payload: %w", err)
+	}
+	head = head[:n]
+	r = io.MultiReader(bytes.NewReader(head), r)
+
+	if n == 0 {
+		return r, nil
+	}
+	if detected := sniffContentType(head); b.blockedType(detected) {
+		return r, fmt.Errorf("%w: detected content type '%s'", errContentBlocked, detected)
+	}
+	return r, nil
+}
+
+// sniffContentType detects content type of the data, executables are
+// recognized in addition to the types known to http.DetectContentType.
+func sniffContentType(data []byte) string {
+	for _, s := range executableSignatures {
+		if bytes.HasPrefix(data, s.sig) {
+			return s.contentType
+		}
+	}
+	return http.DetectContentType(data)
+}
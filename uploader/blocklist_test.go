@@ -0,0 +1,70 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentBlocklist(t *testing.T) {
+	b := NewContentBlocklist([]string{"Text/HTML", "image/*", " "}, []string{".EXE", "sh", ""})
+
+	for _, ct := range []string{"text/html", "TEXT/html; charset=utf-8", "image/svg+xml", "image/png"} {
+		require.True(t, b.blockedType(ct), ct)
+	}
+	for _, ct := range []string{"", "text/plain", "application/octet-stream", "text/htmlx"} {
+		require.False(t, b.blockedType(ct), ct)
+	}
+
+	for _, name := range []string{"a.exe", "dir/setup.Exe", "run.sh"} {
+		require.True(t, b.blockedName(name), name)
+	}
+	for _, name := range []string{"", "exe", "a.exe.txt", "a.bash"} {
+		require.False(t, b.blockedName(name), name)
+	}
+
+	var empty *ContentBlocklist
+	require.True(t, empty.Empty())
+	require.True(t, NewContentBlocklist(nil, []string{""}).Empty())
+	require.False(t, b.Empty())
+}
+
+func TestContentBlocklistCheck(t *testing.T) {
+	b := NewContentBlocklist([]string{"text/html", "application/x-msdownload"}, []string{".exe"})
+
+	check := func(payload string, names, types []string) error {
+		r, err := b.check(strings.NewReader(payload), names, types)
+		if err == nil {
+			// payload is preserved after sniffing
+			data, rErr := io.ReadAll(r)
+			require.NoError(t, rErr)
+			require.Equal(t, payload, string(data))
+		}
+		return err
+	}
+
+	require.NoError(t, check("", []string{"a.txt"}, []string{""}))
+	require.NoError(t, check("hello", []string{"a.txt"}, []string{"text/plain"}))
+	require.NoError(t, check(strings.Repeat("a", 2*sniffLen), nil, nil))
+
+	require.ErrorIs(t, check("hello", []string{"a.txt", "setup.exe"}, nil), errContentBlocked)
+	require.ErrorIs(t, check("hello", nil, []string{"", "text/html; charset=utf-8"}), errContentBlocked)
+	require.ErrorIs(t, check("<!DOCTYPE html><p>hi", []string{"a.txt"}, []string{"text/plain"}), errContentBlocked)
+	require.ErrorIs(t, check("MZ\x90\x00\x03", []string{"a.bin"}, nil), errContentBlocked)
+
+	// nothing is read if nothing is blocked
+	r := bytes.NewReader([]byte("<html>"))
+	res, err := (*ContentBlocklist)(nil).check(r, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, r, res)
+}
+
+func TestSniffContentType(t *testing.T) {
+	require.Equal(t, "application/x-msdownload", sniffContentType([]byte("MZ\x90\x00")))
+	require.Equal(t, "application/x-executable", sniffContentType([]byte("\x7fELF\x02\x01")))
+	require.Equal(t, "application/x-mach-binary", sniffContentType([]byte("\xcf\xfa\xed\xfe\x07")))
+	require.Equal(t, "text/html; charset=utf-8", sniffContentType([]byte("<html><body>")))
+}
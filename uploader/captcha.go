@@ -0,0 +1,93 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// hdrCaptchaToken is a request header with the token issued to the client
+	// by the challenge service.
+	hdrCaptchaToken = "X-Captcha-Token"
+
+	// errCodeCaptchaRequired is an error code of responses to anonymous
+	// uploads without or with an invalid challenge token.
+	errCodeCaptchaRequired = "CAPTCHA_REQUIRED"
+)
+
+// errCaptchaRequired is returned if the challenge token is missing or it's
+// rejected by the challenge service.
+var errCaptchaRequired = errors.New("captcha verification required")
+
+// CaptchaVerifier verifies tokens of challenge services like Cloudflare
+// Turnstile or hCaptcha via their siteverify API. Tokens are single-use, so
+// they're verified by request handlers only, where the client address is
+// known.
+type CaptchaVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// captchaResponse is a siteverify API response, it's the same for Turnstile
+// and hCaptcha.
+type captchaResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// NewCaptchaVerifier creates the verifier sending tokens to the verification
+// URL with the secret key.
+func NewCaptchaVerifier(verifyURL, secret string, timeout time.Duration) *CaptchaVerifier {
+	return &CaptchaVerifier{
+		url:    verifyURL,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// verify checks the token of the request with the service. errCaptchaRequired
+// is returned if the token is missing or invalid, other errors mean the
+// verification failed.
+func (v *CaptchaVerifier) verify(ctx context.Context, h *fasthttp.RequestHeader, remoteIP string) error {
+	token := string(h.Peek(hdrCaptchaToken))
+	if token == "" {
+		return fmt.Errorf("%w: no %s header", errCaptchaRequired, hdrCaptchaToken)
+	}
+
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("verification service responded with %s", resp.Status)
+	}
+
+	var res captchaResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("decode verification response: %w", err)
+	}
+	if !res.Success {
+		return fmt.Errorf("%w: token is rejected %v", errCaptchaRequired, res.ErrorCodes)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// newSiteverify emulates the siteverify API accepting the given tokens once.
+func newSiteverify(t *testing.T, secret string, tokens ...string) *httptest.Server {
+	var mu sync.Mutex
+	valid := make(map[string]bool)
+	for _, token := range tokens {
+		valid[token] = true
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res captchaResponse
+		mu.Lock()
+		switch {
+		case r.PostFormValue("secret") != secret:
+			res.ErrorCodes = []string{"invalid-input-secret"}
+		case !valid[r.PostFormValue("response")]:
+			res.ErrorCodes = []string{"invalid-input-response"}
+		default:
+			res.Success = true
+			delete(valid, r.PostFormValue("response"))
+		}
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCaptchaVerifier(t *testing.T) {
+	srv := newSiteverify(t, "secret", "good")
+	v := NewCaptchaVerifier(srv.URL, "secret", time.Second)
+	ctx := context.Background()
+
+	header := func(token string) *fasthttp.RequestHeader {
+		var h fasthttp.RequestHeader
+		if token != "" {
+			h.Set(hdrCaptchaToken, token)
+		}
+		return &h
+	}
+
+	require.ErrorIs(t, v.verify(ctx, header(""), ""), errCaptchaRequired)
+	require.ErrorIs(t, v.verify(ctx, header("bad"), ""), errCaptchaRequired)
+	require.NoError(t, v.verify(ctx, header("good"), "10.0.0.1"))
+	require.ErrorIs(t, v.verify(ctx, header("good"), ""), errCaptchaRequired, "tokens are single-use")
+
+	t.Run("wrong secret", func(t *testing.T) {
+		v := NewCaptchaVerifier(srv.URL, "wrong", time.Second)
+		require.ErrorIs(t, v.verify(ctx, header("good"), ""), errCaptchaRequired)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		v := NewCaptchaVerifier("http://127.0.0.1:1", "secret", time.Second)
+		err := v.verify(ctx, header("good"), "")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, errCaptchaRequired)
+	})
+}
+
+func TestUploadCaptcha(t *testing.T) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	settings.SetCaptcha(NewCaptchaVerifier(newSiteverify(t, "secret", "good").URL, "secret", time.Second))
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil)
+
+	upload := func(token string) *fasthttp.RequestCtx {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile("file", "cat.jpg")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("meow"))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.Header.SetContentType(mw.FormDataContentType())
+		if token != "" {
+			c.Request.Header.Set(hdrCaptchaToken, token)
+		}
+		c.Request.SetBodyStream(bytes.NewReader(body.Bytes()), body.Len())
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.Upload(&c)
+		return &c
+	}
+
+	c := upload("")
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+	require.Equal(t, errCodeCaptchaRequired, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+
+	c = upload("good")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+	c = upload("good")
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+}
@@ -0,0 +1,158 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// hdrIfNoneMatchAttribute is a header with the name of the attribute which
+// value must be unique in the container. Upload is rejected if there is an
+// object with the same attribute value already.
+const hdrIfNoneMatchAttribute = "X-If-None-Match-Attribute"
+
+// hdrOverwriteAttribute is a header with the name of the attribute identifying
+// objects replaced by the upload. Objects with the same attribute value are
+// removed once the new one is stored.
+const hdrOverwriteAttribute = "X-Overwrite-Attribute"
+
+// errCodePreconditionFailed is the error code of requests rejected since the
+// current object doesn't match If-Match header.
+const errCodePreconditionFailed = "PRECONDITION_FAILED"
+
+// replacedObject is the object removed after the upload replacing it.
+type replacedObject struct {
+	ObjectID string `json:"object_id"`
+	// Removed is false if the object can't be removed, Error contains the
+	// reason then.
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ifMatch checks whether If-Match header value matches the current objects.
+// '*' matches any existing object, entity tags match the only current object
+// if its ID is the tag. Object ETags of downloads are weak, so weak tags are
+// accepted too.
+func ifMatch(val []byte, current []oid.ID) bool {
+	if string(bytes.TrimSpace(val)) == "*" {
+		return len(current) != 0
+	}
+	if len(current) != 1 {
+		return false
+	}
+
+	id := current[0].EncodeToString()
+	for _, tag := range bytes.Split(val, []byte(",")) {
+		tag = bytes.TrimPrefix(bytes.TrimSpace(tag), []byte("W/"))
+		if string(bytes.Trim(tag, `"`)) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeValue returns the value of the attribute with the given key.
+func attributeValue(attrs []object.Attribute, key string) (string, bool) {
+	for i := range attrs {
+		if attrs[i].Key() == key {
+			return attrs[i].Value(), true
+		}
+	}
+	return "", false
+}
+
+// findByAttribute searches for a root object with the given attribute value.
+// Nil is returned if there is no such object.
+func (u *Uploader) findByAttribute(ctx context.Context, cnrID cid.ID, key, val string, bt *bearer.Token) (*oid.ID, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(key, val, object.MatchStringEqual)
+
+	var prm client.PrmObjectSearch
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	res, err := u.backend.ObjectSearchInit(ctx, cnrID, u.signer, filters, prm)
+	if err != nil {
+		return nil, fmt.Errorf("init searching: %w", err)
+	}
+	defer res.Close()
+
+	var found *oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		found = &id
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return found, nil
+}
+
+// findAllByAttribute searches for all root objects with the given attribute
+// value.
+func (u *Uploader) findAllByAttribute(ctx context.Context, cnrID cid.ID, key, val string, bt *bearer.Token) ([]oid.ID, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(key, val, object.MatchStringEqual)
+
+	var prm client.PrmObjectSearch
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	res, err := u.backend.ObjectSearchInit(ctx, cnrID, u.signer, filters, prm)
+	if err != nil {
+		return nil, fmt.Errorf("init searching: %w", err)
+	}
+	defer res.Close()
+
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return ids, nil
+}
+
+// canOverwrite checks objects can be replaced by the upload: removal must be
+// enabled and the request must have a bearer token to remove them with.
+func (u *Uploader) canOverwrite(c *fasthttp.RequestCtx, bt *bearer.Token) bool {
+	if !u.settings.DeleteEnabled() {
+		response.Error(c, "overwrite is disabled since delete is disabled", fasthttp.StatusForbidden)
+		return false
+	}
+	return requireBearerToken(c, bt)
+}
+
+// removeReplaced removes the object replaced by the upload. Failures don't
+// fail the upload since the new object is stored already, they're reported
+// in the response.
+func (u *Uploader) removeReplaced(ctx context.Context, log *zap.Logger, cnrID cid.ID, id oid.ID, bt *bearer.Token) replacedObject {
+	var prm client.PrmObjectDelete
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	res := replacedObject{ObjectID: id.EncodeToString(), Removed: true}
+	if _, err := u.backend.ObjectDelete(ctx, cnrID, id, u.signer, prm); err != nil {
+		log.Warn("could not remove replaced object", zap.Stringer("oid", id), zap.Error(err))
+		res.Removed, res.Error = false, err.Error()
+	}
+	return res
+}
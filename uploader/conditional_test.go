@@ -0,0 +1,50 @@
+package uploader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeValue(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+	attrs := u.buildAttributes(map[string]string{"My-Tag": "tag"}, "cat.jpg", "image/jpeg")
+
+	val, ok := attributeValue(attrs, object.AttributeFileName)
+	require.True(t, ok)
+	require.Equal(t, "cat.jpg", val)
+
+	val, ok = attributeValue(attrs, "My-Tag")
+	require.True(t, ok)
+	require.Equal(t, "tag", val)
+
+	_, ok = attributeValue(attrs, "my-tag")
+	require.False(t, ok)
+}
+
+func TestIfMatch(t *testing.T) {
+	id, other := oidtest.ID(), oidtest.ID()
+	sid := id.EncodeToString()
+
+	for _, tc := range []struct {
+		val     string
+		current []oid.ID
+		match   bool
+	}{
+		{val: "*", current: []oid.ID{id}, match: true},
+		{val: "*", current: []oid.ID{id, other}, match: true},
+		{val: "*"},
+		{val: `"` + sid + `"`, current: []oid.ID{id}, match: true},
+		{val: `W/"` + sid + `"`, current: []oid.ID{id}, match: true},
+		{val: sid, current: []oid.ID{id}, match: true},
+		{val: `"xyz", "` + sid + `"`, current: []oid.ID{id}, match: true},
+		{val: `"` + sid + `"`, current: []oid.ID{other}},
+		{val: `"` + sid + `"`, current: []oid.ID{id, other}},
+		{val: `"` + sid + `"`},
+	} {
+		require.Equal(t, tc.match, ifMatch([]byte(tc.val), tc.current), "%s %v", tc.val, tc.current)
+	}
+}
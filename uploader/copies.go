@@ -0,0 +1,62 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// hdrCopiesNumber is an upload header with the number of object copies to be
+// stored before the upload succeeds, the rest are made by storage nodes
+// according to the container placement policy later.
+const hdrCopiesNumber = "X-Neofs-Copies-Number"
+
+// errCodeCopiesNumber is an error code of uploads with copies number not
+// allowed by the gate.
+const errCodeCopiesNumber = "COPIES_NUMBER_NOT_ALLOWED"
+
+// errCopiesNumber is returned if the copies number exceeds the configured
+// maximum or copies number hints are disabled.
+var errCopiesNumber = errors.New("copies number is not allowed")
+
+// parseCopiesNumber parses the copies number hint, it must be positive and
+// not exceed the maximum, zero maximum disables hints. Zero is returned if the
+// hint isn't set.
+func parseCopiesNumber(val string, max uint32) (uint32, error) {
+	if val == "" {
+		return 0, nil
+	}
+	if max == 0 {
+		return 0, fmt.Errorf("%w: hints are disabled", errCopiesNumber)
+	}
+
+	copies, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid copies number '%s': %w", val, err)
+	}
+	if copies == 0 {
+		return 0, errors.New("copies number must be positive")
+	}
+	if copies > uint64(max) {
+		return 0, fmt.Errorf("%w: %d exceeds maximum %d", errCopiesNumber, copies, max)
+	}
+	return uint32(copies), nil
+}
+
+// copiesWriter sets the copies number of objects formed by the slicer.
+type copiesWriter struct {
+	slicer.ObjectWriter
+	copies uint32
+}
+
+// ObjectPutInit implements slicer.ObjectWriter.
+func (w copiesWriter) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	prm.SetCopiesNumber(w.copies)
+	return w.ObjectWriter.ObjectPutInit(ctx, hdr, signer, prm)
+}
@@ -0,0 +1,58 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCopiesNumber(t *testing.T) {
+	copies, err := parseCopiesNumber("", 0)
+	require.NoError(t, err)
+	require.Zero(t, copies)
+
+	_, err = parseCopiesNumber("2", 0)
+	require.ErrorIs(t, err, errCopiesNumber)
+
+	copies, err = parseCopiesNumber("2", 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, copies)
+
+	copies, err = parseCopiesNumber("3", 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, copies)
+
+	_, err = parseCopiesNumber("4", 3)
+	require.ErrorIs(t, err, errCopiesNumber)
+
+	for _, val := range []string{"0", "-1", "two", "4294967296"} {
+		_, err = parseCopiesNumber(val, 3)
+		require.Error(t, err, val)
+		require.False(t, errors.Is(err, errCopiesNumber), val)
+	}
+}
+
+// prmObjectWriter captures parameters of object writes.
+type prmObjectWriter struct {
+	prm client.PrmObjectPutInit
+}
+
+func (w *prmObjectWriter) ObjectPutInit(_ context.Context, _ object.Object, _ user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	w.prm = prm
+	return nil, nil
+}
+
+func TestCopiesWriter(t *testing.T) {
+	var w prmObjectWriter
+	_, err := copiesWriter{ObjectWriter: &w, copies: 2}.ObjectPutInit(context.Background(), object.Object{}, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+
+	var expected client.PrmObjectPutInit
+	expected.SetCopiesNumber(2)
+	require.Equal(t, expected, w.prm)
+}
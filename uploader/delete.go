@@ -0,0 +1,368 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// deleteJobKind is a kind of delete by prefix jobs.
+const deleteJobKind = "delete_by_prefix"
+
+// Delete object states.
+const (
+	deleteStatePending   = "pending"
+	deleteStateCompleted = "completed"
+	deleteStateFailed    = "failed"
+)
+
+// errTooManyObjects is returned if the prefix matches more objects than
+// allowed to be removed at once.
+var errTooManyObjects = errors.New("too many objects")
+
+type (
+	deleteDryRunResponse struct {
+		ContainerID string               `json:"container_id"`
+		Attribute   string               `json:"attribute"`
+		Prefix      string               `json:"prefix"`
+		Objects     []deleteDryRunObject `json:"objects"`
+		Confirm     string               `json:"confirm"`
+	}
+
+	deleteDryRunObject struct {
+		ObjectID string `json:"object_id"`
+		Value    string `json:"value,omitempty"`
+	}
+)
+
+// Proto implements response.ProtoValue.
+func (r deleteDryRunResponse) Proto() proto.Message {
+	objects := make([]*pb.DeleteDryRunObject, len(r.Objects))
+	for i, o := range r.Objects {
+		objects[i] = &pb.DeleteDryRunObject{ObjectId: o.ObjectID, Value: o.Value}
+	}
+	return &pb.DeleteDryRun{
+		ContainerId: r.ContainerID,
+		Attribute:   r.Attribute,
+		Prefix:      r.Prefix,
+		Objects:     objects,
+		Confirm:     r.Confirm,
+	}
+}
+
+// deleteObjectStatus is a status of single object removal.
+type deleteObjectStatus struct {
+	ObjectID  string `json:"object_id"`
+	State     string `json:"state"`
+	Tombstone string `json:"tombstone,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// deleteJobResult is a result of the delete job.
+type deleteJobResult []deleteObjectStatus
+
+// Proto implements response.ProtoValue.
+func (r deleteJobResult) Proto() proto.Message {
+	objects := make([]*pb.DeleteObjectStatus, len(r))
+	for i, o := range r {
+		objects[i] = &pb.DeleteObjectStatus{
+			ObjectId:  o.ObjectID,
+			State:     o.State,
+			Tombstone: o.Tombstone,
+			Error:     o.Error,
+		}
+	}
+	return &pb.DeleteResult{Objects: objects}
+}
+
+type deleteJob struct {
+	container cid.ID
+	btoken    *bearer.Token
+	ids       []oid.ID
+
+	mu      sync.Mutex
+	objects []deleteObjectStatus
+}
+
+func (j *deleteJob) setObject(i int, st deleteObjectStatus) {
+	j.mu.Lock()
+	j.objects[i] = st
+	j.mu.Unlock()
+}
+
+// result returns a snapshot of objects statuses, it's used as a job result.
+func (j *deleteJob) result() any {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append(deleteJobResult(nil), j.objects...)
+}
+
+// parsePathAttribute checks the attribute naming objects, FilePath is used by
+// default.
+func parsePathAttribute(val string) (string, error) {
+	switch val {
+	case "", object.AttributeFilePath:
+		return object.AttributeFilePath, nil
+	case object.AttributeFileName:
+		return object.AttributeFileName, nil
+	default:
+		return "", fmt.Errorf("unsupported attribute '%s', only %s and %s are allowed",
+			val, object.AttributeFilePath, object.AttributeFileName)
+	}
+}
+
+// deleteConfirmation calculates the token confirming removal of the objects
+// listed by dry run. It changes if the set of matching objects changes.
+func deleteConfirmation(cnrID cid.ID, attr, prefix string, ids []oid.ID) string {
+	sorted := append([]oid.ID(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	h := sha256.New()
+	h.Write(cnrID[:])
+	h.Write([]byte(attr))
+	h.Write([]byte{0})
+	h.Write([]byte(prefix))
+	h.Write([]byte{0})
+	for _, id := range sorted {
+		h.Write(id[:])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DeleteByPrefix handles requests to remove objects with FilePath or FileName
+// attribute starting with the prefix. Without confirm parameter it's a dry run
+// listing matching objects, they're removed in the background only if the
+// confirmation from the dry run is given and the objects haven't changed.
+func (u *Uploader) DeleteByPrefix(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		prefix  = string(c.QueryArgs().Peek("prefix"))
+		confirm = string(c.QueryArgs().Peek("confirm"))
+		log     = u.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
+	)
+
+	if !u.settings.DeleteByPrefixEnabled() {
+		response.Error(c, "delete by prefix is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	if prefix == "" {
+		response.Error(c, "prefix must not be empty", fasthttp.StatusBadRequest)
+		return
+	}
+
+	attr, err := parsePathAttribute(string(c.QueryArgs().Peek("attribute")))
+	if err != nil {
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	cnrID, err := utils.GetContainerID(ctx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	_, bt := u.fetchOwnerAndBearerToken(c)
+
+	ids, err := u.searchByPrefix(ctx, *cnrID, attr, prefix, u.settings.DeleteByPrefixMaxObjects(), bt)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, statusFromError(err))
+		return
+	}
+
+	token := deleteConfirmation(*cnrID, attr, prefix, ids)
+
+	if confirm == "" {
+		resp := deleteDryRunResponse{
+			ContainerID: cnrID.EncodeToString(),
+			Attribute:   attr,
+			Prefix:      prefix,
+			Objects:     make([]deleteDryRunObject, 0, len(ids)),
+			Confirm:     token,
+		}
+		for _, id := range ids {
+			resp.Objects = append(resp.Objects, deleteDryRunObject{
+				ObjectID: id.EncodeToString(),
+				Value:    u.attributeByID(*cnrID, id, attr, bt),
+			})
+		}
+
+		log.Info("delete by prefix dry run", zap.Int("objects", len(ids)))
+		response.Write(c, fasthttp.StatusOK, &resp)
+		return
+	}
+
+	if confirm != token {
+		response.Error(c, "matching objects have changed, repeat dry run", fasthttp.StatusPreconditionFailed)
+		return
+	}
+
+	del := &deleteJob{
+		container: *cnrID,
+		btoken:    bt,
+		ids:       ids,
+		objects:   make([]deleteObjectStatus, len(ids)),
+	}
+	for i := range ids {
+		del.objects[i] = deleteObjectStatus{ObjectID: ids[i].EncodeToString(), State: deleteStatePending}
+	}
+
+	job, err := u.jobs.Start(deleteJobKind, len(ids), func(ctx context.Context, j *jobs.Job) error {
+		j.SetResultFunc(del.result)
+		return u.runDelete(ctx, j, del)
+	})
+	if err != nil {
+		log.Error("could not start delete job", zap.Error(err))
+		response.Error(c, "could not start delete job: "+err.Error(), fasthttp.StatusServiceUnavailable)
+		return
+	}
+
+	log.Info("delete by prefix job started", zap.String("job", job.ID()), zap.Int("objects", len(ids)))
+
+	jobs.WriteStatus(c, fasthttp.StatusAccepted, job.Status())
+}
+
+// searchByPrefix searches for root objects with the attribute value starting
+// with the prefix. Returns errTooManyObjects if there are more than max objects.
+func (u *Uploader) searchByPrefix(ctx context.Context, cnrID cid.ID, attr, prefix string, max int, bt *bearer.Token) ([]oid.ID, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(attr, prefix, object.MatchCommonPrefix)
+
+	return u.searchObjects(ctx, cnrID, filters, max, bt)
+}
+
+// searchObjects searches for objects matching the filters. Returns
+// errTooManyObjects if there are more than max objects.
+func (u *Uploader) searchObjects(ctx context.Context, cnrID cid.ID, filters object.SearchFilters, max int, bt *bearer.Token) ([]oid.ID, error) {
+	var prm client.PrmObjectSearch
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	res, err := u.backend.ObjectSearchInit(ctx, cnrID, u.signer, filters, prm)
+	if err != nil {
+		return nil, fmt.Errorf("init searching: %w", err)
+	}
+	defer res.Close()
+
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return max > 0 && len(ids) > max
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	if max > 0 && len(ids) > max {
+		return nil, fmt.Errorf("%w, max is %d", errTooManyObjects, max)
+	}
+
+	return ids, nil
+}
+
+// attributeByID returns the attribute value of the object, empty string is
+// returned if the object header can't be read.
+func (u *Uploader) attributeByID(cnrID cid.ID, id oid.ID, attr string, bt *bearer.Token) string {
+	var prm client.PrmObjectHead
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	obj, err := u.backend.ObjectHead(u.appCtx, cnrID, id, u.signer, prm)
+	if err != nil {
+		u.log.Debug("could not get object header", zap.Stringer("oid", id), zap.Error(err))
+		return ""
+	}
+
+	val, _ := attributeValue(obj.Attributes(), attr)
+	return val
+}
+
+func (u *Uploader) runDelete(ctx context.Context, j *jobs.Job, del *deleteJob) error {
+	log := u.log.With(zap.String("job", j.ID()))
+
+	concurrency := u.settings.DeleteByPrefixConcurrency()
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for i := range del.ids {
+		select {
+		case <-ctx.Done():
+			del.setObject(i, deleteObjectStatus{ObjectID: del.ids[i].EncodeToString(), State: deleteStateFailed, Error: ctx.Err().Error()})
+			j.AddFailed()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			id := del.ids[i]
+
+			var prm client.PrmObjectDelete
+			if del.btoken != nil {
+				prm.WithBearerToken(*del.btoken)
+			}
+
+			tomb, err := u.backend.ObjectDelete(ctx, del.container, id, u.signer, prm)
+			if err != nil {
+				log.Error("could not delete object", zap.Stringer("oid", id), zap.Error(err))
+				del.setObject(i, deleteObjectStatus{ObjectID: id.EncodeToString(), State: deleteStateFailed, Error: err.Error()})
+				j.AddFailed()
+				return
+			}
+
+			del.setObject(i, deleteObjectStatus{ObjectID: id.EncodeToString(), State: deleteStateCompleted, Tombstone: tomb.EncodeToString()})
+			j.AddDone()
+		}(i)
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
@@ -0,0 +1,77 @@
+package uploader
+
+import (
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestParsePathAttribute(t *testing.T) {
+	for val, expected := range map[string]string{
+		"":                       object.AttributeFilePath,
+		object.AttributeFilePath: object.AttributeFilePath,
+		object.AttributeFileName: object.AttributeFileName,
+	} {
+		attr, err := parsePathAttribute(val)
+		require.NoError(t, err)
+		require.Equal(t, expected, attr)
+	}
+
+	for _, val := range []string{"filepath", "Owner", object.AttributeContentType} {
+		_, err := parsePathAttribute(val)
+		require.Error(t, err, val)
+	}
+}
+
+func TestDeleteConfirmation(t *testing.T) {
+	cnrID := cidtest.ID()
+	a, b, c := oidtest.ID(), oidtest.ID(), oidtest.ID()
+
+	token := deleteConfirmation(cnrID, object.AttributeFilePath, "dir/", []oid.ID{a, b})
+	require.Equal(t, token, deleteConfirmation(cnrID, object.AttributeFilePath, "dir/", []oid.ID{b, a}))
+	require.NotEqual(t, token, deleteConfirmation(cnrID, object.AttributeFilePath, "dir/", []oid.ID{a, b, c}))
+	require.NotEqual(t, token, deleteConfirmation(cnrID, object.AttributeFilePath, "dir/", []oid.ID{a}))
+	require.NotEqual(t, token, deleteConfirmation(cnrID, object.AttributeFileName, "dir/", []oid.ID{a, b}))
+	require.NotEqual(t, token, deleteConfirmation(cnrID, object.AttributeFilePath, "dir", []oid.ID{a, b}))
+	require.NotEqual(t, token, deleteConfirmation(cidtest.ID(), object.AttributeFilePath, "dir/", []oid.ID{a, b}))
+}
+
+func TestDeleteJobResult(t *testing.T) {
+	job := &deleteJob{objects: make([]deleteObjectStatus, 2)}
+	job.setObject(0, deleteObjectStatus{ObjectID: "a", State: deleteStateCompleted, Tombstone: "t"})
+	job.setObject(1, deleteObjectStatus{ObjectID: "b", State: deleteStateFailed, Error: "err"})
+
+	res := job.result().(deleteJobResult)
+	require.Equal(t, deleteJobResult{
+		{ObjectID: "a", State: deleteStateCompleted, Tombstone: "t"},
+		{ObjectID: "b", State: deleteStateFailed, Error: "err"},
+	}, res)
+
+	// result is a snapshot
+	job.setObject(0, deleteObjectStatus{ObjectID: "a", State: deleteStatePending})
+	require.Equal(t, deleteStateCompleted, res[0].State)
+}
+
+func TestDeleteByPrefixValidation(t *testing.T) {
+	u := &Uploader{log: zap.NewNop(), settings: &Settings{}}
+
+	request := func(query string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/delete_by_prefix/cid?" + query)
+		c.SetUserValue("cid", cidtest.ID().EncodeToString())
+		u.DeleteByPrefix(&c)
+		return &c
+	}
+
+	require.Equal(t, fasthttp.StatusForbidden, request("prefix=dir/").Response.StatusCode())
+
+	u.settings.SetDeleteByPrefixEnabled(true)
+	require.Equal(t, fasthttp.StatusBadRequest, request("").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusBadRequest, request("prefix=dir/&attribute=Owner").Response.StatusCode())
+}
@@ -0,0 +1,95 @@
+package uploader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/valyala/fasthttp"
+)
+
+// hdrContentSHA256 is a header (or trailer) with hex-encoded SHA-256 digest
+// of the uploaded file.
+const hdrContentSHA256 = "X-Content-SHA256"
+
+var (
+	// errDigestMismatch is returned when the uploaded payload doesn't match
+	// the digest provided by the client.
+	errDigestMismatch = errors.New("payload digest mismatch")
+	// errDigestInvalid is returned when the digest is missing or malformed.
+	errDigestInvalid = errors.New("invalid payload digest")
+)
+
+// digestReader calculates SHA-256 of the data read and verifies it when the
+// underlying reader is exhausted. Verification error is returned instead of
+// io.EOF, so the payload is not stored.
+type digestReader struct {
+	r      io.Reader
+	hash   hash.Hash
+	verify func(sum []byte) error
+}
+
+func newDigestReader(r io.Reader, verify func(sum []byte) error) *digestReader {
+	return &digestReader{r: r, hash: sha256.New(), verify: verify}
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		if verr := d.verify(d.hash.Sum(nil)); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// expectsContentDigest checks whether the client provides payload digest in
+// the header or announces it in the Trailer header.
+func expectsContentDigest(h *fasthttp.RequestHeader) bool {
+	if len(peekHeader(h, hdrContentSHA256)) != 0 {
+		return true
+	}
+
+	for _, name := range bytes.Split(peekHeader(h, fasthttp.HeaderTrailer), []byte(",")) {
+		if bytes.EqualFold(bytes.TrimSpace(name), []byte(hdrContentSHA256)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkContentDigest compares the digest from the request with the calculated
+// one. Trailers are available in the request header after the body is read.
+func checkContentDigest(h *fasthttp.RequestHeader, sum []byte) error {
+	val := peekHeader(h, hdrContentSHA256)
+	if len(val) == 0 {
+		return fmt.Errorf("%w: %s is announced but not provided", errDigestInvalid, hdrContentSHA256)
+	}
+
+	expected, err := hex.DecodeString(string(bytes.TrimSpace(val)))
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("%w: malformed %s value '%s'", errDigestInvalid, hdrContentSHA256, val)
+	}
+
+	if !bytes.Equal(expected, sum) {
+		return fmt.Errorf("%w: expected %x, calculated %x", errDigestMismatch, expected, sum)
+	}
+	return nil
+}
+
+// peekHeader looks for the header ignoring its case, since header names
+// normalizing is disabled for the server.
+func peekHeader(h *fasthttp.RequestHeader, name string) []byte {
+	var res []byte
+	h.VisitAll(func(key, value []byte) {
+		if res == nil && bytes.EqualFold(key, []byte(name)) {
+			res = value
+		}
+	})
+	return res
+}
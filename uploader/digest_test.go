@@ -0,0 +1,76 @@
+package uploader
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContentDigestTrailer(t *testing.T) {
+	payload := []byte("payload")
+	sum := sha256.Sum256(payload)
+
+	raw := "POST /upload/cid HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Transfer-Encoding: chunked\r\n" +
+		"Trailer: X-Content-SHA256\r\n" +
+		"\r\n" +
+		"7\r\npayload\r\n" +
+		"0\r\n" +
+		"x-content-sha256: " + hex.EncodeToString(sum[:]) + "\r\n" +
+		"\r\n"
+
+	var req fasthttp.Request
+	req.Header.DisableNormalizing()
+	require.NoError(t, req.Read(bufio.NewReader(strings.NewReader(raw))))
+
+	require.True(t, expectsContentDigest(&req.Header))
+	require.NoError(t, checkContentDigest(&req.Header, sum[:]))
+
+	other := sha256.Sum256([]byte("other"))
+	require.ErrorIs(t, checkContentDigest(&req.Header, other[:]), errDigestMismatch)
+}
+
+func TestContentDigestHeader(t *testing.T) {
+	var h fasthttp.RequestHeader
+	h.DisableNormalizing()
+	require.False(t, expectsContentDigest(&h))
+
+	h.Set(fasthttp.HeaderTrailer, "X-Other")
+	require.False(t, expectsContentDigest(&h))
+
+	h.Set(fasthttp.HeaderTrailer, "X-Other, X-Content-SHA256")
+	require.True(t, expectsContentDigest(&h))
+	require.ErrorIs(t, checkContentDigest(&h, nil), errDigestInvalid)
+
+	h.Set(hdrContentSHA256, "not hex")
+	require.ErrorIs(t, checkContentDigest(&h, nil), errDigestInvalid)
+}
+
+func TestDigestReader(t *testing.T) {
+	payload := []byte("payload")
+	sum := sha256.Sum256(payload)
+
+	var calculated []byte
+	r := newDigestReader(bytes.NewReader(payload), func(s []byte) error {
+		calculated = s
+		return nil
+	})
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, payload, data)
+	require.Equal(t, sum[:], calculated)
+
+	r = newDigestReader(bytes.NewReader(payload), func([]byte) error {
+		return errDigestMismatch
+	})
+	_, err = io.ReadAll(r)
+	require.ErrorIs(t, err, errDigestMismatch)
+}
@@ -0,0 +1,265 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// epochDuration returns approximate duration of a single epoch.
+func (d *epochDurations) epochDuration() time.Duration {
+	return time.Duration(uint64(d.msPerBlock)*d.blockPerEpoch) * time.Millisecond
+}
+
+// elapsed returns the time passed since the current epoch start. If it's
+// unknown, the worst case for the rounding is taken: the current epoch is
+// considered to be finishing when rounding up and to be just started when
+// rounding down.
+func (d *epochDurations) elapsed() time.Duration {
+	epochDuration := d.epochDuration()
+	if !d.offsetKnown {
+		if d.roundDown {
+			return 0
+		}
+		return epochDuration
+	}
+	if d.epochOffset > epochDuration {
+		return epochDuration
+	}
+	return d.epochOffset
+}
+
+// expirationEpoch returns the expiration epoch for the object which should
+// live for the given duration. Object is available until the end of the
+// expiration epoch, so the result is the epoch when the duration passes
+// (rounded up or down depending on settings).
+func (d *epochDurations) expirationEpoch(expDuration time.Duration) uint64 {
+	epochDuration := uint64(d.msPerBlock) * d.blockPerEpoch
+	// lifetime counted from the current epoch start
+	total := uint64(expDuration.Milliseconds()) + uint64(d.elapsed().Milliseconds())
+	numEpoch := total / epochDuration
+
+	if !d.roundDown && total%epochDuration != 0 {
+		numEpoch++
+	}
+
+	// the current epoch is included into the count
+	if numEpoch > 0 {
+		numEpoch--
+	}
+
+	if numEpoch < math.MaxUint64-d.currentEpoch {
+		return d.currentEpoch + numEpoch
+	}
+	return math.MaxUint64
+}
+
+// epochTime returns approximate wall-clock time of the epoch end, i.e. the
+// time the object with such expiration epoch is removed. Zero time is
+// returned if the result overflows.
+func (d *epochDurations) epochTime(epoch uint64, now time.Time) time.Time {
+	if epoch < d.currentEpoch {
+		return now
+	}
+
+	num := epoch - d.currentEpoch + 1
+	epochDuration := d.epochDuration()
+	if epochDuration <= 0 || num > uint64(math.MaxInt64/int64(epochDuration)) {
+		return time.Time{}
+	}
+
+	return now.Add(time.Duration(num)*epochDuration - d.elapsed())
+}
+
+// epochTracker remembers the epochs observed by the gate to estimate the time
+// passed since the current epoch start. Network info doesn't provide it, so
+// the estimation is available only if the gate has seen the previous epoch.
+// The epoch has started between the last observation of the previous epoch
+// and the first observation of the current one, so the tracker returns both
+// bounds of the offset: the maximum one makes objects live at least as long
+// as requested, the minimum one makes them live not longer.
+type epochTracker struct {
+	mu       sync.Mutex
+	observed bool
+	epoch    uint64
+	// last is the time of the last observation of the epoch
+	last time.Time
+	// earliest and latest are the bounds of the epoch start
+	earliest, latest time.Time
+}
+
+// observe registers the current epoch and returns the minimum and the maximum
+// time passed since its start if it's known.
+func (t *epochTracker) observe(epoch uint64, now time.Time) (time.Duration, time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.observed && epoch == t.epoch:
+	case t.observed && epoch == t.epoch+1:
+		t.epoch, t.earliest, t.latest = epoch, t.last, now
+	default:
+		// first observation or unexpected jump, epoch start is unknown
+		t.observed, t.epoch, t.earliest, t.latest = true, epoch, time.Time{}, time.Time{}
+	}
+	t.last = now
+
+	if t.earliest.IsZero() {
+		return 0, 0, false
+	}
+	return now.Sub(t.latest), now.Sub(t.earliest), true
+}
+
+// checkClockSkew checks that client time doesn't differ from the server one
+// more than allowed. Zero maxSkew disables the check.
+func checkClockSkew(client, server time.Time, maxSkew time.Duration) error {
+	if maxSkew <= 0 {
+		return nil
+	}
+
+	skew := client.Sub(server)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return fmt.Errorf("clock skew %s exceeds the limit %s", skew.Round(time.Second), maxSkew)
+	}
+	return nil
+}
+
+// errCodeExpirationPolicy is an error code of uploads with expiration not
+// allowed by the gate policy.
+const errCodeExpirationPolicy = "EXPIRATION_POLICY"
+
+// errExpirationPolicy is returned if the object expiration is out of the
+// allowed range.
+var errExpirationPolicy = errors.New("expiration is not allowed")
+
+// expirationPolicy limits object lifetime. Zero values disable limits, the
+// default lifetime is applied to objects without expiration, it's the
+// maximum one if not set, so objects always expire when the maximum is set.
+type expirationPolicy struct {
+	min, max, def time.Duration
+}
+
+// defaultLifetime returns the lifetime of objects without expiration, zero
+// means they don't expire.
+func (p expirationPolicy) defaultLifetime() time.Duration {
+	if p.def <= 0 || p.max > 0 && p.def > p.max {
+		return p.max
+	}
+	return p.def
+}
+
+// check checks that the expiration epoch is within the allowed lifetime range.
+func (p expirationPolicy) check(d *epochDurations, epoch uint64) error {
+	if p.min > 0 {
+		if minEpoch := d.expirationEpoch(p.min); epoch < minEpoch {
+			return fmt.Errorf("%w: expiration epoch %d is less than %d corresponding to minimum lifetime %s",
+				errExpirationPolicy, epoch, minEpoch, p.min)
+		}
+	}
+	if p.max > 0 {
+		if maxEpoch := d.expirationEpoch(p.max); epoch > maxEpoch {
+			return fmt.Errorf("%w: expiration epoch %d is greater than %d corresponding to maximum lifetime %s",
+				errExpirationPolicy, epoch, maxEpoch, p.max)
+		}
+	}
+	return nil
+}
+
+// clockSkewWarning returns Warning header value for the ignored client Date
+// header, the warn-text is a quoted-string (RFC 7234, section 5.5).
+func clockSkewWarning(err error) string {
+	return `299 - ` + quotedString("Date header is ignored, server time is used: "+err.Error())
+}
+
+// quotedString formats s as HTTP quoted-string (RFC 7230, section 3.2.6).
+// Quotes and backslashes are escaped, control characters which can't be
+// sent in headers are replaced with spaces.
+func quotedString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' && c != '\t' || c == 0x7f:
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// expirationInfo describes the expiration of the object.
+type expirationInfo struct {
+	CurrentEpoch    uint64 `json:"current_epoch"`
+	EpochDuration   string `json:"epoch_duration"`
+	ExpirationEpoch uint64 `json:"expiration_epoch"`
+	ExpirationTime  string `json:"expiration_time,omitempty"`
+}
+
+func newExpirationInfo(d *epochDurations, epoch uint64, now time.Time) *expirationInfo {
+	res := &expirationInfo{
+		CurrentEpoch:    d.currentEpoch,
+		EpochDuration:   d.epochDuration().String(),
+		ExpirationEpoch: epoch,
+	}
+	if t := d.epochTime(epoch, now); !t.IsZero() {
+		res.ExpirationTime = t.UTC().Format(time.RFC3339)
+	}
+	return res
+}
+
+// Expiration converts expiration duration to epoch and vice versa using
+// current network parameters. Exactly one of 'duration' and 'epoch' query
+// arguments is expected.
+func (u *Uploader) Expiration(c *fasthttp.RequestCtx) {
+	var (
+		args     = c.QueryArgs()
+		duration = string(args.Peek("duration"))
+		epoch    = string(args.Peek("epoch"))
+	)
+
+	if (duration == "") == (epoch == "") {
+		response.Error(c, "exactly one of 'duration' and 'epoch' query arguments is expected", fasthttp.StatusBadRequest)
+		return
+	}
+
+	durations, err := u.getEpochDurations(c)
+	if err != nil {
+		u.log.Error("could not get epoch durations from network info", zap.Error(err))
+		response.Error(c, "could not get epoch durations from network info: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var expEpoch uint64
+	if duration != "" {
+		expDuration, err := time.ParseDuration(duration)
+		if err != nil || expDuration <= 0 {
+			response.Error(c, "invalid duration: "+duration, fasthttp.StatusBadRequest)
+			return
+		}
+		expEpoch = durations.expirationEpoch(expDuration)
+	} else {
+		if expEpoch, err = strconv.ParseUint(epoch, 10, 64); err != nil {
+			response.Error(c, "invalid epoch: "+epoch, fasthttp.StatusBadRequest)
+			return
+		}
+	}
+
+	writeJSON(c, fasthttp.StatusOK, newExpirationInfo(durations, expEpoch, time.Now()))
+}
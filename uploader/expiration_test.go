@@ -0,0 +1,129 @@
+package uploader
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochDurations(t *testing.T) {
+	d := &epochDurations{
+		currentEpoch:  10,
+		msPerBlock:    1000,
+		blockPerEpoch: 3600,
+	}
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, time.Hour, d.epochDuration())
+
+	require.EqualValues(t, 11, d.expirationEpoch(time.Hour))
+	require.EqualValues(t, 12, d.expirationEpoch(time.Hour+time.Second))
+	require.EqualValues(t, 11, d.expirationEpoch(time.Millisecond))
+
+	require.Equal(t, now.Add(2*time.Hour), d.epochTime(12, now))
+	require.Equal(t, now, d.epochTime(5, now))
+	require.True(t, d.epochTime(math.MaxUint64, now).IsZero())
+
+	info := newExpirationInfo(d, 12, now)
+	require.EqualValues(t, 10, info.CurrentEpoch)
+	require.EqualValues(t, 12, info.ExpirationEpoch)
+	require.Equal(t, "1h0m0s", info.EpochDuration)
+	require.Equal(t, "2023-10-10T12:00:00Z", info.ExpirationTime)
+}
+
+func TestExpirationEpochOffset(t *testing.T) {
+	d := &epochDurations{
+		currentEpoch:  10,
+		msPerBlock:    1000,
+		blockPerEpoch: 3600,
+	}
+
+	// unknown offset, the current epoch may finish any moment
+	require.EqualValues(t, 12, d.expirationEpoch(90*time.Minute))
+
+	d.epochOffset, d.offsetKnown = 10*time.Minute, true
+	require.EqualValues(t, 11, d.expirationEpoch(90*time.Minute))
+	require.EqualValues(t, 11, d.expirationEpoch(110*time.Minute))
+	require.EqualValues(t, 12, d.expirationEpoch(111*time.Minute))
+	require.EqualValues(t, 10, d.expirationEpoch(time.Minute))
+
+	d.roundDown = true
+	require.EqualValues(t, 10, d.expirationEpoch(90*time.Minute))
+	require.EqualValues(t, 11, d.expirationEpoch(111*time.Minute))
+	require.EqualValues(t, 10, d.expirationEpoch(time.Minute))
+
+	now := time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC)
+	require.Equal(t, now.Add(110*time.Minute), d.epochTime(11, now))
+
+	// unknown offset, the current epoch may have just started
+	d.offsetKnown = false
+	require.EqualValues(t, 10, d.expirationEpoch(90*time.Minute))
+	require.EqualValues(t, 11, d.expirationEpoch(120*time.Minute))
+}
+
+func TestEpochTracker(t *testing.T) {
+	var (
+		tr  epochTracker
+		now = time.Now()
+	)
+
+	_, _, ok := tr.observe(10, now)
+	require.False(t, ok)
+	_, _, ok = tr.observe(10, now.Add(time.Minute))
+	require.False(t, ok)
+
+	// the epoch has changed somewhere between the observations
+	minOffset, maxOffset, ok := tr.observe(11, now.Add(3*time.Minute))
+	require.True(t, ok)
+	require.Zero(t, minOffset)
+	require.Equal(t, 2*time.Minute, maxOffset)
+
+	minOffset, maxOffset, ok = tr.observe(11, now.Add(5*time.Minute))
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, minOffset)
+	require.Equal(t, 4*time.Minute, maxOffset)
+
+	_, _, ok = tr.observe(15, now.Add(6*time.Minute))
+	require.False(t, ok)
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.Now()
+
+	require.NoError(t, checkClockSkew(now.Add(time.Minute), now, time.Hour))
+	require.NoError(t, checkClockSkew(now.Add(-time.Minute), now, time.Hour))
+	require.Error(t, checkClockSkew(now.Add(2*time.Hour), now, time.Hour))
+	require.Error(t, checkClockSkew(now.Add(-2*time.Hour), now, time.Hour))
+	require.NoError(t, checkClockSkew(now.Add(100*time.Hour), now, 0))
+
+	err := checkClockSkew(now.Add(2*time.Hour), now, time.Hour)
+	require.Equal(t, `299 - "Date header is ignored, server time is used: clock skew 2h0m0s exceeds the limit 1h0m0s"`,
+		clockSkewWarning(err))
+
+	require.Equal(t, `299 - "Date header is ignored, server time is used: bad \\ \"date\"  "`,
+		clockSkewWarning(errors.New("bad \\ \"date\"\r\n")))
+}
+
+func TestExpirationPolicy(t *testing.T) {
+	d := &epochDurations{
+		currentEpoch:  10,
+		msPerBlock:    1000,
+		blockPerEpoch: 3600,
+	}
+
+	require.Zero(t, expirationPolicy{}.defaultLifetime())
+	require.Equal(t, time.Hour, expirationPolicy{def: time.Hour}.defaultLifetime())
+	require.Equal(t, 24*time.Hour, expirationPolicy{max: 24 * time.Hour}.defaultLifetime())
+	require.Equal(t, 24*time.Hour, expirationPolicy{def: 48 * time.Hour, max: 24 * time.Hour}.defaultLifetime())
+
+	p := expirationPolicy{min: time.Hour, max: 24 * time.Hour}
+	require.ErrorIs(t, p.check(d, 10), errExpirationPolicy)
+	require.NoError(t, p.check(d, 11))
+	require.NoError(t, p.check(d, 34))
+	require.ErrorIs(t, p.check(d, 35), errExpirationPolicy)
+
+	require.NoError(t, expirationPolicy{}.check(d, math.MaxUint64))
+}
@@ -0,0 +1,80 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+)
+
+// FilePath conflict policies, they define what happens when an object is
+// uploaded with the FilePath of an existing object.
+const (
+	// PathConflictVersion stores the object as a new version of the path,
+	// the latest version is returned by path.
+	PathConflictVersion = "version"
+	// PathConflictReject rejects the upload like X-If-None-Match-Attribute
+	// header does.
+	PathConflictReject = "reject"
+	// PathConflictReplace removes the existing objects once the new one is
+	// stored like X-Overwrite-Attribute header does.
+	PathConflictReplace = "replace"
+)
+
+// errCodeInvalidFilePath is the error code of uploads with FilePath which
+// can't be normalized.
+const errCodeInvalidFilePath = "INVALID_FILE_PATH"
+
+var errInvalidFilePath = errors.New("invalid FilePath")
+
+// normalizeFilePath converts backslashes to forward slashes, collapses
+// duplicate slashes, '.' and '..' elements and removes leading and trailing
+// slashes, so the path can't point outside of the container root.
+func normalizeFilePath(p string) (string, error) {
+	p = strings.ReplaceAll(p, `\`, "/")
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "", fmt.Errorf("%w: the path is empty", errInvalidFilePath)
+	}
+	return p, nil
+}
+
+// normalizeFilePathAttribute normalizes FilePath attribute of the object if
+// it's enabled by settings.
+func (u *Uploader) normalizeFilePathAttribute(attributes map[string]string) error {
+	val, ok := attributes[object.AttributeFilePath]
+	if !ok || !u.settings.NormalizeFilePath() {
+		return nil
+	}
+	normalized, err := normalizeFilePath(val)
+	if err != nil {
+		return err
+	}
+	attributes[object.AttributeFilePath] = normalized
+	return nil
+}
+
+// conflictAttributes returns attributes from X-If-None-Match-Attribute and
+// X-Overwrite-Attribute headers. If neither is set, FilePath conflict policy
+// is applied to objects with FilePath.
+func (u *Uploader) conflictAttributes(h *fasthttp.RequestHeader, attributes map[string]string) (ifNoneMatch, overwrite string) {
+	ifNoneMatch = string(h.Peek(hdrIfNoneMatchAttribute))
+	overwrite = string(h.Peek(hdrOverwriteAttribute))
+	if ifNoneMatch != "" || overwrite != "" {
+		return ifNoneMatch, overwrite
+	}
+	if _, ok := attributes[object.AttributeFilePath]; !ok {
+		return "", ""
+	}
+	switch u.settings.PathConflict() {
+	case PathConflictReject:
+		return object.AttributeFilePath, ""
+	case PathConflictReplace:
+		return "", object.AttributeFilePath
+	default:
+		return "", ""
+	}
+}
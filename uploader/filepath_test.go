@@ -0,0 +1,103 @@
+package uploader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNormalizeFilePath(t *testing.T) {
+	for _, tc := range []struct {
+		val, res string
+	}{
+		{val: "dir/file.txt", res: "dir/file.txt"},
+		{val: "/dir//sub/./file.txt/", res: "dir/sub/file.txt"},
+		{val: `dir\sub\file.txt`, res: "dir/sub/file.txt"},
+		{val: "dir/../other/file.txt", res: "other/file.txt"},
+		{val: "../../etc/passwd", res: "etc/passwd"},
+		{val: `..\..\file.txt`, res: "file.txt"},
+	} {
+		res, err := normalizeFilePath(tc.val)
+		require.NoError(t, err, tc.val)
+		require.Equal(t, tc.res, res, tc.val)
+	}
+
+	for _, val := range []string{"", "/", "dir/..", "./"} {
+		_, err := normalizeFilePath(val)
+		require.ErrorIs(t, err, errInvalidFilePath, val)
+	}
+
+	u := &Uploader{settings: &Settings{}}
+	attrs := map[string]string{object.AttributeFilePath: "a//b"}
+	require.NoError(t, u.normalizeFilePathAttribute(attrs))
+	require.Equal(t, "a//b", attrs[object.AttributeFilePath], "disabled by default")
+
+	u.settings.SetNormalizeFilePath(true)
+	require.NoError(t, u.normalizeFilePathAttribute(attrs))
+	require.Equal(t, "a/b", attrs[object.AttributeFilePath])
+	require.ErrorIs(t, u.normalizeFilePathAttribute(map[string]string{object.AttributeFilePath: "/"}), errInvalidFilePath)
+	require.NoError(t, u.normalizeFilePathAttribute(map[string]string{}))
+}
+
+func TestConflictAttributes(t *testing.T) {
+	var (
+		u        = &Uploader{settings: &Settings{}}
+		h        fasthttp.RequestHeader
+		withPath = map[string]string{object.AttributeFilePath: "a/b"}
+	)
+
+	ifNoneMatch, overwrite := u.conflictAttributes(&h, withPath)
+	require.Empty(t, ifNoneMatch)
+	require.Empty(t, overwrite)
+
+	u.settings.SetPathConflict(PathConflictReject)
+	ifNoneMatch, overwrite = u.conflictAttributes(&h, withPath)
+	require.Equal(t, object.AttributeFilePath, ifNoneMatch)
+	require.Empty(t, overwrite)
+
+	ifNoneMatch, overwrite = u.conflictAttributes(&h, map[string]string{})
+	require.Empty(t, ifNoneMatch, "objects without FilePath aren't affected")
+	require.Empty(t, overwrite)
+
+	u.settings.SetPathConflict(PathConflictReplace)
+	ifNoneMatch, overwrite = u.conflictAttributes(&h, withPath)
+	require.Empty(t, ifNoneMatch)
+	require.Equal(t, object.AttributeFilePath, overwrite)
+
+	// headers take precedence over the policy
+	h.Set(hdrIfNoneMatchAttribute, object.AttributeFileName)
+	ifNoneMatch, overwrite = u.conflictAttributes(&h, withPath)
+	require.Equal(t, object.AttributeFileName, ifNoneMatch)
+	require.Empty(t, overwrite)
+}
+
+func TestUploadPathConflict(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	u.settings.SetNormalizeFilePath(true)
+	u.settings.SetPathConflict(PathConflictReject)
+
+	const hdrFilePath = "X-Attribute-FilePath"
+	first := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow", hdrFilePath, "/cats//cat.txt"))
+	obj, ok := mem.Object(newAddress(cnrID, first))
+	require.True(t, ok)
+	val, _ := attributeValue(obj.Attributes(), object.AttributeFilePath)
+	require.Equal(t, "cats/cat.txt", val)
+
+	c := uploadTagged(t, u, cnrID, "cat", "purr", hdrFilePath, "cats/./cat.txt")
+	require.Equal(t, fasthttp.StatusConflict, c.Response.StatusCode())
+	c = uploadTagged(t, u, cnrID, "cat", "purr", hdrFilePath, "..")
+	requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeInvalidFilePath)
+
+	u.settings.SetPathConflict(PathConflictReplace)
+	authKey, authVal := authHeader(t)
+	second := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "purr", hdrFilePath, `cats\cat.txt`, authKey, authVal))
+	_, ok = mem.Object(newAddress(cnrID, first))
+	require.False(t, ok)
+
+	u.settings.SetPathConflict(PathConflictVersion)
+	uploadedID(t, uploadTagged(t, u, cnrID, "cat", "mrrr", hdrFilePath, "cats/cat.txt"))
+	_, ok = mem.Object(newAddress(cnrID, second))
+	require.True(t, ok)
+}
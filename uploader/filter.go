@@ -2,9 +2,10 @@ package uploader
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nspcc-dev/neofs-http-gw/utils"
@@ -13,7 +14,26 @@ import (
 	"go.uber.org/zap"
 )
 
-var neofsAttributeHeaderPrefixes = [...][]byte{[]byte("Neofs-"), []byte("NEOFS-"), []byte("neofs-")}
+// neofsAttributeHeaderPrefix is a prefix of system attribute headers after
+// the attribute prefix, both prefixes are case-insensitive.
+var neofsAttributeHeaderPrefix = []byte("Neofs-")
+
+// errCodeDuplicateAttribute is an error code of uploads with conflicting
+// duplicated attribute headers.
+const errCodeDuplicateAttribute = "DUPLICATE_ATTRIBUTE"
+
+// errDuplicateAttribute is returned if the attribute is set by several headers
+// with different values which can't be joined.
+var errDuplicateAttribute = errors.New("key duplication error")
+
+// duplicateAttributes defines handling of the attribute set by several
+// headers. Identical values are always merged, different values of user
+// attributes are joined with the separator if join is set, otherwise they're
+// rejected. Different values of system attributes are always rejected.
+type duplicateAttributes struct {
+	join      bool
+	separator string
+}
 
 func systemTranslator(key, prefix []byte) []byte {
 	// replace the specified prefix with `__NEOFS__`
@@ -26,31 +46,37 @@ func systemTranslator(key, prefix []byte) []byte {
 	return bytes.ToUpper(key)
 }
 
-func filterHeaders(l *zap.Logger, header *fasthttp.RequestHeader) (map[string]string, error) {
+// hasPrefixFold checks whether s begins with prefix ignoring case.
+func hasPrefixFold(s, prefix []byte) bool {
+	return len(s) >= len(prefix) && bytes.EqualFold(s[:len(prefix)], prefix)
+}
+
+// filterHeaders collects object attributes from the request headers. User
+// attribute keys are canonicalized according to keyCase mode (see
+// utils.CanonicalAttributeKey) before duplicates are checked.
+func filterHeaders(l *zap.Logger, header *fasthttp.RequestHeader, dup duplicateAttributes, keyCase string) (map[string]string, error) {
 	var err error
-	result := make(map[string]string)
+	values := make(map[string][]string)
 	prefix := []byte(utils.UserAttributeHeaderPrefix)
 
 	header.VisitAll(func(key, val []byte) {
-		// checks that the key and the val not empty
-		if len(key) == 0 || len(val) == 0 {
+		// checks that the key and the val not empty, the first error is returned
+		if len(key) == 0 || len(val) == 0 || err != nil {
 			return
 		}
 
 		// checks that the key has attribute prefix
-		if !bytes.HasPrefix(key, prefix) {
+		if !hasPrefixFold(key, prefix) {
 			return
 		}
 
 		// removing attribute prefix
-		clearKey := bytes.TrimPrefix(key, prefix)
+		clearKey := key[len(prefix):]
 
 		// checks that it's a system NeoFS header
-		for _, system := range neofsAttributeHeaderPrefixes {
-			if bytes.HasPrefix(clearKey, system) {
-				clearKey = systemTranslator(clearKey, system)
-				break
-			}
+		system := hasPrefixFold(clearKey, neofsAttributeHeaderPrefix)
+		if system {
+			clearKey = systemTranslator(clearKey, clearKey[:len(neofsAttributeHeaderPrefix)])
 		}
 
 		// checks that the attribute key is not empty
@@ -58,27 +84,45 @@ func filterHeaders(l *zap.Logger, header *fasthttp.RequestHeader) (map[string]st
 			return
 		}
 
-		// check if key gets duplicated
+		// make string representation of key / val
+		k, v := string(clearKey), string(val)
+		if !system {
+			k = utils.CanonicalAttributeKey(k, keyCase)
+		}
+
+		// check if key gets duplicated, identical values are kept once,
 		// return error containing full key name (with prefix)
-		if _, ok := result[string(clearKey)]; ok {
-			err = fmt.Errorf("key duplication error: %s", string(key))
+		for _, prev := range values[k] {
+			if prev == v {
+				return
+			}
+		}
+		if len(values[k]) != 0 && (system || !dup.join) {
+			err = fmt.Errorf("%w: %s has different values", errDuplicateAttribute, string(key))
 			return
 		}
 
-		// make string representation of key / val
-		k, v := string(clearKey), string(val)
-
-		result[k] = v
+		values[k] = append(values[k], v)
 
 		l.Debug("add attribute to result object",
 			zap.String("key", k),
 			zap.String("val", v))
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(values))
+	for k, vals := range values {
+		result[k] = strings.Join(vals, dup.separator)
+	}
 
-	return result, err
+	return result, nil
 }
 
-func prepareExpirationHeader(headers map[string]string, epochDurations *epochDurations, now time.Time) error {
+// prepareExpirationHeader converts all forms of expiration to the expiration
+// epoch attribute and applies the expiration policy.
+func prepareExpirationHeader(headers map[string]string, epochDurations *epochDurations, now time.Time, policy expirationPolicy) error {
 	expirationInEpoch := headers[object.AttributeExpirationEpoch]
 
 	if timeRFC3339, ok := headers[utils.ExpirationRFC3339Attr]; ok {
@@ -124,22 +168,24 @@ func prepareExpirationHeader(headers map[string]string, epochDurations *epochDur
 		headers[object.AttributeExpirationEpoch] = expirationInEpoch
 	}
 
-	return nil
-}
-
-func updateExpirationHeader(headers map[string]string, durations *epochDurations, expDuration time.Duration) {
-	epochDuration := uint64(durations.msPerBlock) * durations.blockPerEpoch
-	currentEpoch := durations.currentEpoch
-	numEpoch := uint64(expDuration.Milliseconds()) / epochDuration
-
-	if uint64(expDuration.Milliseconds())%epochDuration != 0 {
-		numEpoch++
+	expirationInEpoch, ok := headers[object.AttributeExpirationEpoch]
+	if !ok {
+		if lifetime := policy.defaultLifetime(); lifetime > 0 {
+			updateExpirationHeader(headers, epochDurations, lifetime)
+		}
+		return nil
 	}
 
-	expirationEpoch := uint64(math.MaxUint64)
-	if numEpoch < math.MaxUint64-currentEpoch {
-		expirationEpoch = currentEpoch + numEpoch
+	if policy.min <= 0 && policy.max <= 0 {
+		return nil
 	}
+	epoch, err := strconv.ParseUint(expirationInEpoch, 10, 64)
+	if err != nil {
+		return fmt.Errorf("couldn't parse value %s of header %s", expirationInEpoch, object.AttributeExpirationEpoch)
+	}
+	return policy.check(epochDurations, epoch)
+}
 
-	headers[object.AttributeExpirationEpoch] = strconv.FormatUint(expirationEpoch, 10)
+func updateExpirationHeader(headers map[string]string, durations *epochDurations, expDuration time.Duration) {
+	headers[object.AttributeExpirationEpoch] = strconv.FormatUint(durations.expirationEpoch(expDuration), 10)
 }
@@ -21,7 +21,7 @@ func TestFilter(t *testing.T) {
 		req.DisableNormalizing()
 		req.Add("X-Attribute-DupKey", "first-value")
 		req.Add("X-Attribute-DupKey", "second-value")
-		_, err := filterHeaders(log, req)
+		_, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCasePreserve)
 		require.Error(t, err)
 	})
 
@@ -30,10 +30,66 @@ func TestFilter(t *testing.T) {
 		req.DisableNormalizing()
 		req.Add("X-Attribute-Neofs-DupKey", "first-value")
 		req.Add("X-Attribute-Neofs-DupKey", "second-value")
-		_, err := filterHeaders(log, req)
+		_, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCasePreserve)
 		require.Error(t, err)
 	})
 
+	t.Run("identical duplicates", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("X-Attribute-DupKey", "value")
+		req.Add("X-Attribute-DupKey", "value")
+		result, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCasePreserve)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"DupKey": "value"}, result)
+	})
+
+	t.Run("join duplicates", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("X-Attribute-Tag", "a")
+		req.Add("X-Attribute-Tag", "b")
+		req.Add("X-Attribute-Tag", "a")
+		req.Add("X-Attribute-Tag", "c")
+		result, err := filterHeaders(log, req, duplicateAttributes{join: true, separator: ","}, utils.AttributeCasePreserve)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"Tag": "a,b,c"}, result)
+	})
+
+	t.Run("system duplicates aren't joined", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("X-Attribute-Neofs-Expiration-Epoch", "101")
+		req.Add("X-Attribute-NEOFS-Expiration-Epoch", "102")
+		_, err := filterHeaders(log, req, duplicateAttributes{join: true, separator: ","}, utils.AttributeCasePreserve)
+		require.ErrorIs(t, err, errDuplicateAttribute)
+	})
+
+	t.Run("case-insensitive prefixes", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("x-attribute-filename", "a.txt")
+		req.Add("X-ATTRIBUTE-NeoFS-Expiration-Epoch", "100")
+		result, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCasePreserve)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"filename": "a.txt", object.AttributeExpirationEpoch: "100"}, result)
+	})
+
+	t.Run("canonical keys", func(t *testing.T) {
+		req := &fasthttp.RequestHeader{}
+		req.DisableNormalizing()
+		req.Add("x-attribute-filename", "a.txt")
+		req.Add("x-attribute-my-tag", "tag")
+		result, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCaseCanonical)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{object.AttributeFileName: "a.txt", "My-Tag": "tag"}, result)
+
+		// differently cased headers set the same attribute
+		req.Add("X-Attribute-FileName", "b.txt")
+		_, err = filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCaseKnown)
+		require.ErrorIs(t, err, errDuplicateAttribute)
+	})
+
 	req := &fasthttp.RequestHeader{}
 	req.DisableNormalizing()
 
@@ -49,7 +105,7 @@ func TestFilter(t *testing.T) {
 		"__NEOFS__EXPIRATION_EPOCH2": "102",
 	}
 
-	result, err := filterHeaders(log, req)
+	result, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCasePreserve)
 	require.NoError(t, err)
 
 	require.Equal(t, expected, result)
@@ -85,6 +141,7 @@ func TestPrepareExpirationHeader(t *testing.T) {
 		name      string
 		headers   map[string]string
 		durations *epochDurations
+		policy    expirationPolicy
 		err       bool
 		expected  map[string]string
 	}{
@@ -196,9 +253,44 @@ func TestPrepareExpirationHeader(t *testing.T) {
 			headers: map[string]string{utils.ExpirationRFC3339Attr: time.RFC3339},
 			err:     true,
 		},
+		{
+			name:      "default lifetime",
+			headers:   map[string]string{},
+			durations: defaultDurations,
+			policy:    expirationPolicy{def: 24 * time.Hour},
+			expected:  map[string]string{object.AttributeExpirationEpoch: defaultExpEpoch},
+		},
+		{
+			name:      "maximum lifetime as default",
+			headers:   map[string]string{},
+			durations: defaultDurations,
+			policy:    expirationPolicy{max: 24 * time.Hour},
+			expected:  map[string]string{object.AttributeExpirationEpoch: defaultExpEpoch},
+		},
+		{
+			name:      "lifetime within limits",
+			headers:   map[string]string{utils.ExpirationDurationAttr: duration},
+			durations: defaultDurations,
+			policy:    expirationPolicy{min: time.Hour, max: 24 * time.Hour},
+			expected:  map[string]string{object.AttributeExpirationEpoch: defaultExpEpoch},
+		},
+		{
+			name:      "lifetime exceeds maximum",
+			headers:   map[string]string{object.AttributeExpirationEpoch: "100000"},
+			durations: defaultDurations,
+			policy:    expirationPolicy{max: 24 * time.Hour},
+			err:       true,
+		},
+		{
+			name:      "lifetime less than minimum",
+			headers:   map[string]string{utils.ExpirationDurationAttr: "1m"},
+			durations: defaultDurations,
+			policy:    expirationPolicy{min: 24 * time.Hour},
+			err:       true,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			err := prepareExpirationHeader(tc.headers, tc.durations, time.Now())
+			err := prepareExpirationHeader(tc.headers, tc.durations, time.Now(), tc.policy)
 			if tc.err {
 				require.Error(t, err)
 			} else {
@@ -208,3 +300,25 @@ func TestPrepareExpirationHeader(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkFilterHeaders(b *testing.B) {
+	log := zap.NewNop()
+	req := &fasthttp.RequestHeader{}
+	req.DisableNormalizing()
+	req.Set("Content-Type", "multipart/form-data; boundary=xxx")
+	req.Set("X-Attribute-FileName", "cat.jpg")
+	req.Set("X-Attribute-FilePath", "/cats/cat.jpg")
+	req.Set("X-Attribute-Neofs-Expiration-Duration", "1h")
+	for i := 0; i < 10; i++ {
+		req.Set("X-Attribute-Key-"+strconv.Itoa(i), "value")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := filterHeaders(log, req, duplicateAttributes{}, utils.AttributeCasePreserve)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
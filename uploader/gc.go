@@ -0,0 +1,129 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"go.uber.org/zap"
+)
+
+// CollectDeleteMarkers removes delete markers older than the retention period
+// together with the versions hidden by them from the configured containers,
+// so soft-deleted files don't occupy the storage forever. Versions uploaded
+// after the marker are kept. It returns the number of removed objects and
+// their total payload size. A failure in one container doesn't stop the
+// collection in others, it's logged and the last one is returned.
+func (u *Uploader) CollectDeleteMarkers(ctx context.Context) (int, int64, error) {
+	retention := u.settings.DeleteMarkerRetention()
+	if retention <= 0 {
+		return 0, 0, nil
+	}
+	threshold := time.Now().Add(-retention).Unix()
+
+	var (
+		items   int
+		size    int64
+		failed  int
+		lastErr error
+	)
+	containers := u.settings.DeleteMarkerContainers()
+	for _, name := range containers {
+		if ctx.Err() != nil {
+			return items, size, ctx.Err()
+		}
+		n, s, err := u.collectContainerDeleteMarkers(ctx, name, threshold)
+		items += n
+		size += s
+		if err != nil {
+			u.log.Warn("could not collect delete markers", zap.String("container", name), zap.Error(err))
+			failed++
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return items, size, fmt.Errorf("%d of %d containers failed, last: %w", failed, len(containers), lastErr)
+	}
+	return items, size, nil
+}
+
+func (u *Uploader) collectContainerDeleteMarkers(ctx context.Context, name string, threshold int64) (int, int64, error) {
+	cnrID, err := utils.GetContainerID(ctx, name, u.containerResolver)
+	if err != nil {
+		return 0, 0, fmt.Errorf("resolve container '%s': %w", name, err)
+	}
+	n, s, err := u.collectDeleteMarkers(ctx, *cnrID, threshold)
+	if err != nil {
+		return n, s, fmt.Errorf("container %s: %w", cnrID, err)
+	}
+	return n, s, nil
+}
+
+// collectDeleteMarkers removes markers made before the threshold (Unix time)
+// and all older versions of their files from the container.
+func (u *Uploader) collectDeleteMarkers(ctx context.Context, cnrID cid.ID, threshold int64) (int, int64, error) {
+	markers, err := u.findAllByAttribute(ctx, cnrID, utils.AttributeDeleteMarker, "true", nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("search delete markers: %w", err)
+	}
+
+	var (
+		items int
+		size  int64
+		paths = make(map[string]struct{})
+	)
+	for _, id := range markers {
+		hdr, err := u.backend.ObjectHead(ctx, cnrID, id, u.signer, client.PrmObjectHead{})
+		if err != nil {
+			if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+				continue
+			}
+			return items, size, fmt.Errorf("head %s: %w", id, err)
+		}
+		if utils.NewObjectVersion(id, hdr).Timestamp > threshold {
+			continue
+		}
+		filePath, ok := attributeValue(hdr.Attributes(), object.AttributeFilePath)
+		if !ok {
+			continue
+		}
+		if _, ok = paths[filePath]; ok {
+			continue
+		}
+		paths[filePath] = struct{}{}
+
+		ids, err := u.findAllByAttribute(ctx, cnrID, object.AttributeFilePath, filePath, nil)
+		if err != nil {
+			return items, size, fmt.Errorf("search versions of '%s': %w", filePath, err)
+		}
+		versions, err := u.headVersions(ctx, cnrID, ids, nil)
+		if err != nil {
+			return items, size, fmt.Errorf("head versions of '%s': %w", filePath, err)
+		}
+
+		// the newest outdated marker hides all the versions after it
+		start := len(versions)
+		for i := range versions {
+			if versions[i].DeleteMarker && versions[i].Timestamp <= threshold {
+				start = i
+				break
+			}
+		}
+		for _, v := range versions[start:] {
+			if _, err = u.backend.ObjectDelete(ctx, cnrID, v.ID, u.signer, client.PrmObjectDelete{}); err != nil {
+				return items, size, fmt.Errorf("remove %s: %w", v.ID, err)
+			}
+			items++
+			size += int64(v.Size)
+		}
+		u.log.Debug("outdated file versions removed", zap.Stringer("cid", cnrID),
+			zap.String("path", filePath), zap.Int("count", len(versions)-start))
+	}
+	return items, size, nil
+}
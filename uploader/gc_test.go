@@ -0,0 +1,75 @@
+package uploader
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectDeleteMarkers(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	u.settings.SetAttributeCase(utils.AttributeCaseKnown)
+	ctx := context.Background()
+
+	upload := func(filePath string, timestamp int64, payload string) oid.ID {
+		return uploadedID(t, uploadTagged(t, u, cnrID, "cat", payload,
+			"X-Attribute-FilePath", filePath, "X-Attribute-Timestamp", strconv.FormatInt(timestamp, 10)))
+	}
+	mark := func(filePath string, timestamp int64) oid.ID {
+		id, err := u.putDeleteMarker(ctx, cnrID, u.ownerID, filePath, timestamp, nil)
+		require.NoError(t, err)
+		return id
+	}
+	exists := func(id oid.ID) bool {
+		_, ok := mem.Object(newAddress(cnrID, id))
+		return ok
+	}
+
+	old := time.Now().Add(-2 * time.Hour).Unix()
+	first := upload("docs/cat.txt", old-2, "meow")
+	firstMarker := mark("docs/cat.txt", old-1)
+	second := upload("docs/cat.txt", old, "purr")
+	marker := mark("docs/cat.txt", old+1)
+	// the file is uploaded again after removal
+	reuploaded := upload("docs/cat.txt", old+2, "hiss")
+
+	recent := upload("docs/dog.txt", old, "woof")
+	recentMarker := mark("docs/dog.txt", time.Now().Unix())
+
+	// collection is disabled by default
+	items, size, err := u.CollectDeleteMarkers(ctx)
+	require.NoError(t, err)
+	require.Zero(t, items)
+	require.Zero(t, size)
+
+	u.settings.SetDeleteMarkerRetention(time.Hour)
+	// missing container doesn't stop the collection in others
+	u.settings.SetDeleteMarkerContainers([]string{cidtest.ID().EncodeToString(), cnrID.EncodeToString()})
+	items, size, err = u.CollectDeleteMarkers(ctx)
+	require.ErrorContains(t, err, "1 of 2 containers failed")
+	require.Equal(t, 4, items)
+	require.EqualValues(t, 8, size)
+
+	for _, id := range []oid.ID{first, firstMarker, second, marker} {
+		require.False(t, exists(id))
+	}
+	for _, id := range []oid.ID{reuploaded, recent, recentMarker} {
+		require.True(t, exists(id))
+	}
+
+	ids, err := u.findAllByAttribute(ctx, cnrID, object.AttributeFilePath, "docs/cat.txt", nil)
+	require.NoError(t, err)
+	require.Equal(t, []oid.ID{reuploaded}, ids)
+
+	u.settings.SetDeleteMarkerContainers([]string{cnrID.EncodeToString()})
+	items, _, err = u.CollectDeleteMarkers(ctx)
+	require.NoError(t, err)
+	require.Zero(t, items)
+}
@@ -0,0 +1,446 @@
+package uploader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"golang.org/x/net/http/httpproxy"
+	"google.golang.org/protobuf/proto"
+)
+
+// importJobKind is a kind of import jobs.
+const importJobKind = "import"
+
+const (
+	// importConnectTimeout limits the time of connecting to the import source
+	// and waiting for its response headers, payload is streamed for the
+	// import timeout.
+	importConnectTimeout = 30 * time.Second
+	// maxImportRedirects is the maximum number of redirects followed to fetch
+	// a single record.
+	maxImportRedirects = 10
+)
+
+// errImportURLForbidden is returned if the import URL or the one it redirects
+// to isn't allowed.
+var errImportURLForbidden = errors.New("url is not allowed")
+
+// Import record states.
+const (
+	importStatePending   = "pending"
+	importStateRunning   = "running"
+	importStateCompleted = "completed"
+	importStateFailed    = "failed"
+)
+
+// importManifestRecord is a single line of import manifest.
+type importManifestRecord struct {
+	URL        string            `json:"url"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// importRecordStatus is a status of single manifest record processing.
+type importRecordStatus struct {
+	URL      string `json:"url"`
+	State    string `json:"state"`
+	ObjectID string `json:"object_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// importJobResult is a result of the import job.
+type importJobResult []importRecordStatus
+
+// Proto implements response.ProtoValue.
+func (r importJobResult) Proto() proto.Message {
+	records := make([]*pb.ImportRecordStatus, len(r))
+	for i, rec := range r {
+		records[i] = &pb.ImportRecordStatus{
+			Url:      rec.URL,
+			State:    rec.State,
+			ObjectId: rec.ObjectID,
+			Error:    rec.Error,
+		}
+	}
+	return &pb.ImportResult{Records: records}
+}
+
+type importJob struct {
+	container cid.ID
+	owner     user.ID
+	btoken    *bearer.Token
+	manifest  []importManifestRecord
+
+	mu      sync.Mutex
+	records []importRecordStatus
+}
+
+func (j *importJob) setRecord(i int, st importRecordStatus) {
+	j.mu.Lock()
+	j.records[i] = st
+	j.mu.Unlock()
+}
+
+// result returns a snapshot of records statuses, it's used as a job result.
+func (j *importJob) result() any {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append(importJobResult(nil), j.records...)
+}
+
+// checkImportURL checks that the URL can be fetched by the import: only HTTP
+// and HTTPS are supported and the host must match the allowlist if it's set.
+// Without the allowlist, addresses of internal networks are forbidden.
+func checkImportURL(u *url.URL, hosts *utils.HostAllowlist) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported url scheme '%s'", errImportURLForbidden, u.Scheme)
+	}
+	if hosts != nil {
+		if !hosts.Allowed(strings.ToLower(u.Hostname())) {
+			return fmt.Errorf("%w: host '%s' is not allowed", errImportURLForbidden, u.Hostname())
+		}
+		return nil
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("%w: address '%s' is not public", errImportURLForbidden, ip)
+	}
+	return nil
+}
+
+// isPublicIP checks that the address doesn't belong to loopback, private,
+// link-local (including cloud metadata services), shared or multicast
+// networks.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	// carrier-grade NAT shared address space
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xC0 == 64 {
+		return false
+	}
+	return true
+}
+
+// importProxies returns addresses of proxies configured in the environment,
+// connections to them aren't checked, target hosts are checked by URL only
+// then.
+func importProxies() map[string]struct{} {
+	res := make(map[string]struct{})
+	cfg := httpproxy.FromEnvironment()
+	for _, proxy := range []string{cfg.HTTPProxy, cfg.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			// proxies without scheme are handled as HTTP ones
+			if u, err = url.Parse("http://" + proxy); err != nil {
+				continue
+			}
+		}
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+		res[net.JoinHostPort(u.Hostname(), port)] = struct{}{}
+	}
+	return res
+}
+
+// newImportClient creates the client fetching import records. Redirects are
+// followed only to the URLs passing the same checks as the ones from the
+// manifest. Without the host allowlist, connections to addresses of internal
+// networks are refused, so host names resolving to them are forbidden too.
+func newImportClient(s *Settings) *http.Client {
+	proxies := importProxies()
+	proxyDialer := &net.Dialer{Timeout: importConnectTimeout}
+	dialer := &net.Dialer{
+		Timeout: importConnectTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if s.ImportAllowedHosts() != nil {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: address '%s' is not public", errImportURLForbidden, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if _, ok := proxies[addr]; ok {
+					return proxyDialer.DialContext(ctx, network, addr)
+				}
+				return dialer.DialContext(ctx, network, addr)
+			},
+			ResponseHeaderTimeout: importConnectTimeout,
+			TLSHandshakeTimeout:   importConnectTimeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxImportRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxImportRedirects)
+			}
+			return checkImportURL(req.URL, s.ImportAllowedHosts())
+		},
+	}
+}
+
+// parseImportManifest reads NDJSON manifest, empty lines are skipped. URLs of
+// records are checked against the host allowlist if it's set.
+func parseImportManifest(r io.Reader, maxRecords int, hosts *utils.HostAllowlist) ([]importManifestRecord, error) {
+	var res []importManifestRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var rec importManifestRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		u, err := url.Parse(rec.URL)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid url: %w", line, err)
+		}
+		if err = checkImportURL(u, hosts); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		res = append(res, rec)
+		if maxRecords > 0 && len(res) > maxRecords {
+			return nil, fmt.Errorf("too many records, max is %d", maxRecords)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errors.New("empty manifest")
+	}
+
+	return res, nil
+}
+
+// Import handles bulk import requests. Request body is an NDJSON manifest,
+// every record of it is fetched and stored in the background.
+func (u *Uploader) Import(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		log     = u.log.With(zap.String("cid", scid))
+	)
+
+	if !u.settings.ImportEnabled() {
+		response.Error(c, "import is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	if err := tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	idCnr, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	var body io.Reader = c.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.PostBody())
+	}
+
+	manifest, err := parseImportManifest(body, u.settings.ImportMaxRecords(), u.settings.ImportAllowedHosts())
+	if err != nil {
+		log.Error("could not parse import manifest", zap.Error(err))
+		response.Error(c, "could not parse import manifest: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	owner, bt := u.fetchOwnerAndBearerToken(c)
+
+	imp := &importJob{
+		container: *idCnr,
+		owner:     *owner,
+		btoken:    bt,
+		manifest:  manifest,
+		records:   make([]importRecordStatus, len(manifest)),
+	}
+	for i := range manifest {
+		imp.records[i] = importRecordStatus{URL: manifest[i].URL, State: importStatePending}
+	}
+
+	job, err := u.jobs.Start(importJobKind, len(imp.manifest), func(ctx context.Context, j *jobs.Job) error {
+		j.SetResultFunc(imp.result)
+		return u.runImport(ctx, j, imp)
+	})
+	if err != nil {
+		log.Error("could not start import job", zap.Error(err))
+		response.Error(c, "could not start import job: "+err.Error(), fasthttp.StatusServiceUnavailable)
+		return
+	}
+
+	log.Info("import job started", zap.String("job", job.ID()), zap.Int("records", len(manifest)))
+
+	jobs.WriteStatus(c, fasthttp.StatusAccepted, job.Status())
+}
+
+func (u *Uploader) runImport(ctx context.Context, j *jobs.Job, imp *importJob) error {
+	log := u.log.With(zap.String("job", j.ID()))
+
+	concurrency := u.settings.ImportConcurrency()
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for i := range imp.manifest {
+		select {
+		case <-ctx.Done():
+			imp.setRecord(i, importRecordStatus{URL: imp.manifest[i].URL, State: importStateFailed, Error: ctx.Err().Error()})
+			j.AddFailed()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			rec := imp.manifest[i]
+			imp.setRecord(i, importRecordStatus{URL: rec.URL, State: importStateRunning})
+
+			id, err := u.importRecord(ctx, imp, rec)
+			if err != nil {
+				log.Error("could not import record", zap.String("url", rec.URL), zap.Error(err))
+				imp.setRecord(i, importRecordStatus{URL: rec.URL, State: importStateFailed, Error: err.Error()})
+				j.AddFailed()
+				return
+			}
+
+			imp.setRecord(i, importRecordStatus{URL: rec.URL, State: importStateCompleted, ObjectID: id})
+			j.AddDone()
+		}(i)
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (u *Uploader) importRecord(ctx context.Context, job *importJob, rec importManifestRecord) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.settings.ImportTimeout())
+	defer cancel()
+
+	if err := u.settings.SystemAttributes().check(rec.Attributes); err != nil {
+		return "", err
+	}
+
+	attributes := make(map[string]string, len(rec.Attributes))
+	for k, v := range rec.Attributes {
+		attributes[k] = v
+	}
+	if err := u.normalizeFilePathAttribute(attributes); err != nil {
+		return "", err
+	}
+	if policy := u.settings.expirationPolicy(); needParseExpiration(attributes) || policy.defaultLifetime() > 0 {
+		durations, err := u.getEpochDurations(ctx)
+		if err != nil {
+			return "", fmt.Errorf("get epoch durations: %w", err)
+		}
+		if err = prepareExpirationHeader(attributes, durations, time.Now(), policy); err != nil {
+			return "", err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rec.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	// the allowlist could be changed since the manifest is checked
+	if err = checkImportURL(req.URL, u.settings.ImportAllowedHosts()); err != nil {
+		return "", err
+	}
+
+	resp, err := u.importClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch: unexpected status %s", resp.Status)
+	}
+
+	fileName := path.Base(req.URL.Path)
+	if fileName == "/" || fileName == "." {
+		fileName = req.URL.Host
+	}
+
+	payload, err := u.settings.ContentBlocklist().check(resp.Body,
+		[]string{fileName, attributes[object.AttributeFileName], attributes[object.AttributeFilePath]},
+		[]string{resp.Header.Get("Content-Type"), attributes[object.AttributeContentType]},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	var obj object.Object
+	obj.SetContainerID(job.container)
+	obj.SetOwnerID(&job.owner)
+	obj.SetAttributes(u.buildAttributes(attributes, fileName, resp.Header.Get("Content-Type"))...)
+
+	id, err := u.putObject(ctx, obj, payload, job.btoken, resp.ContentLength, 0)
+	if err != nil {
+		return "", err
+	}
+
+	return id.EncodeToString(), nil
+}
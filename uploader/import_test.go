@@ -0,0 +1,144 @@
+package uploader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportManifest(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		manifest := `{"url":"http://example.com/a.txt","attributes":{"FileName":"a.txt"}}
+
+{"url":"https://example.com/b.txt"}
+`
+		res, err := parseImportManifest(strings.NewReader(manifest), 10, nil)
+		require.NoError(t, err)
+		require.Equal(t, []importManifestRecord{
+			{URL: "http://example.com/a.txt", Attributes: map[string]string{"FileName": "a.txt"}},
+			{URL: "https://example.com/b.txt"},
+		}, res)
+	})
+
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		max      int
+		hosts    []string
+	}{
+		{name: "empty", manifest: "\n\n"},
+		{name: "invalid json", manifest: `{"url":`},
+		{name: "unsupported scheme", manifest: `{"url":"file:///etc/passwd"}`},
+		{name: "too many records", manifest: "{\"url\":\"http://a\"}\n{\"url\":\"http://b\"}", max: 1},
+		{name: "host not allowed", manifest: `{"url":"http://localhost/a.txt"}`, hosts: []string{"*.example.com"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var hosts *utils.HostAllowlist
+			if len(tc.hosts) > 0 {
+				var err error
+				hosts, err = utils.NewHostAllowlist(tc.hosts)
+				require.NoError(t, err)
+			}
+			_, err := parseImportManifest(strings.NewReader(tc.manifest), tc.max, hosts)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestImportClient(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer target.Close()
+
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/scheme":
+			http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+		}
+	}))
+	defer source.Close()
+
+	settings := &Settings{}
+	client := newImportClient(settings)
+
+	get := func(path string) error {
+		resp, err := client.Get(source.URL + path)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// internal addresses are forbidden without the allowlist
+	require.ErrorIs(t, get("/"), errImportURLForbidden)
+
+	hosts, err := utils.NewHostAllowlist([]string{"127.0.0.1", "localhost"})
+	require.NoError(t, err)
+	settings.SetImportAllowedHosts(hosts)
+
+	require.NoError(t, get("/"))
+	require.ErrorIs(t, get("/scheme"), errImportURLForbidden)
+	require.ErrorContains(t, get("/loop"), "redirects")
+
+	hosts, err = utils.NewHostAllowlist([]string{"127.0.0.1"})
+	require.NoError(t, err)
+	settings.SetImportAllowedHosts(hosts)
+
+	require.ErrorIs(t, get("/"), errImportURLForbidden)
+}
+
+func TestCheckImportURL(t *testing.T) {
+	for _, s := range []string{
+		"http://127.0.0.1/file",
+		"http://[::1]/file",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/file",
+		"http://192.168.1.1/file",
+		"http://100.64.0.1/file",
+		"http://[fd00::1]/file",
+		"http://0.0.0.0/file",
+	} {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		require.ErrorIs(t, checkImportURL(u, nil), errImportURLForbidden, s)
+	}
+
+	for _, s := range []string{"https://files.example.com/file", "http://203.0.113.1/file", "http://[2001:db8::1]/file"} {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		require.NoError(t, checkImportURL(u, nil), s)
+	}
+
+	// the allowlist can allow internal hosts explicitly
+	hosts, err := utils.NewHostAllowlist([]string{"10.0.0.1"})
+	require.NoError(t, err)
+	u, err := url.Parse("http://10.0.0.1/file")
+	require.NoError(t, err)
+	require.NoError(t, checkImportURL(u, hosts))
+}
+
+func TestImportJobResult(t *testing.T) {
+	job := &importJob{records: make([]importRecordStatus, 2)}
+	job.setRecord(0, importRecordStatus{URL: "a", State: importStateCompleted, ObjectID: "oid"})
+	job.setRecord(1, importRecordStatus{URL: "b", State: importStateFailed, Error: "err"})
+
+	res := job.result().(importJobResult)
+	require.Equal(t, importJobResult{
+		{URL: "a", State: importStateCompleted, ObjectID: "oid"},
+		{URL: "b", State: importStateFailed, Error: "err"},
+	}, res)
+
+	// result is a snapshot
+	job.setRecord(0, importRecordStatus{URL: "a", State: importStateRunning})
+	require.Equal(t, importStateCompleted, res[0].State)
+}
@@ -0,0 +1,197 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// hdrLockUntilEpoch is an upload header making the gate lock the uploaded
+// object until the specified epoch (inclusive).
+const hdrLockUntilEpoch = "X-Neofs-Lock-Until-Epoch"
+
+type lockResponse struct {
+	LockID      string `json:"lock_id"`
+	ContainerID string `json:"container_id"`
+	ObjectID    string `json:"object_id"`
+	UntilEpoch  uint64 `json:"until_epoch"`
+}
+
+// parseLockEpoch parses lock expiration epoch, it must be positive.
+func parseLockEpoch(val string) (uint64, error) {
+	epoch, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lock epoch '%s': %w", val, err)
+	}
+	if epoch == 0 {
+		return 0, errors.New("lock epoch must be positive")
+	}
+	return epoch, nil
+}
+
+// putLock stores a lock object protecting the given objects from removal
+// until the specified epoch.
+func (u *Uploader) putLock(ctx context.Context, cnrID cid.ID, owner *user.ID, members []oid.ID, untilEpoch uint64, bt *bearer.Token) (oid.ID, error) {
+	var lock object.Lock
+	lock.WriteMembers(members)
+
+	exp := object.NewAttribute()
+	exp.SetKey(object.AttributeExpirationEpoch)
+	exp.SetValue(strconv.FormatUint(untilEpoch, 10))
+
+	var obj object.Object
+	obj.SetContainerID(cnrID)
+	obj.SetOwnerID(owner)
+	obj.SetType(object.TypeLock)
+	obj.SetAttributes(*exp)
+
+	payload := lock.Marshal()
+	return u.putObject(ctx, obj, bytes.NewReader(payload), bt, int64(len(payload)), 0)
+}
+
+// Lock handles requests to lock existing objects.
+func (u *Uploader) Lock(c *fasthttp.RequestCtx) {
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	cnrID, objID, log, ok := u.parseLockRequest(c)
+	if !ok || !u.supports(c, log, compat.FeatureLock) {
+		return
+	}
+
+	untilEpoch, err := parseLockEpoch(string(c.QueryArgs().Peek("until_epoch")))
+	if err != nil {
+		log.Error("could not parse lock epoch", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	owner, bt := u.fetchOwnerAndBearerToken(c)
+
+	lockID, err := u.putLock(ctx, cnrID, owner, []oid.ID{objID}, untilEpoch, bt)
+	if err != nil {
+		log.Error("could not put lock", zap.Error(err))
+		response.StorageError(c, eacl.OperationPut, "could not put lock", err, statusFromError(err))
+		return
+	}
+
+	log.Info("object locked", zap.Stringer("lock", lockID), zap.Uint64("until_epoch", untilEpoch))
+
+	writeJSON(c, fasthttp.StatusOK, &lockResponse{
+		LockID:      lockID.EncodeToString(),
+		ContainerID: cnrID.EncodeToString(),
+		ObjectID:    objID.EncodeToString(),
+		UntilEpoch:  untilEpoch,
+	})
+}
+
+// Unlock handles requests to remove lock objects, other objects are rejected,
+// so the route can't be used to remove them. Note that the network usually
+// rejects removal of locks before their expiration.
+func (u *Uploader) Unlock(c *fasthttp.RequestCtx) {
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	cnrID, lockID, log, ok := u.parseLockRequest(c)
+	if !ok {
+		return
+	}
+
+	_, bt := u.fetchOwnerAndBearerToken(c)
+
+	var prmHead client.PrmObjectHead
+	if bt != nil {
+		prmHead.WithBearerToken(*bt)
+	}
+
+	hdr, err := u.backend.ObjectHead(ctx, cnrID, lockID, u.signer, prmHead)
+	if err != nil {
+		log.Error("could not head lock", zap.Error(err))
+		response.StorageError(c, eacl.OperationHead, "could not head lock", err, statusFromError(err))
+		return
+	}
+	if hdr.Type() != object.TypeLock {
+		log.Info("object to unlock is not a lock", zap.Stringer("type", hdr.Type()))
+		response.Error(c, "object is not a lock", fasthttp.StatusBadRequest)
+		return
+	}
+
+	var prm client.PrmObjectDelete
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	if _, err = u.backend.ObjectDelete(ctx, cnrID, lockID, u.signer, prm); err != nil {
+		log.Error("could not remove lock", zap.Error(err))
+		response.StorageError(c, eacl.OperationDelete, "could not remove lock", err, statusFromError(err))
+		return
+	}
+
+	log.Info("lock removed")
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+func (u *Uploader) parseLockRequest(c *fasthttp.RequestCtx) (cid.ID, oid.ID, *zap.Logger, bool) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		soid, _ = c.UserValue("oid").(string)
+		log     = u.log.With(zap.String("cid", scid), zap.String("oid", soid))
+		objID   oid.ID
+	)
+
+	if err := tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return cid.ID{}, objID, nil, false
+	}
+
+	cnrID, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return cid.ID{}, objID, nil, false
+	}
+
+	if objID, err = utils.DecodeObjectID(soid); err != nil {
+		log.Error("wrong object id", zap.Error(err))
+		response.Error(c, "wrong object id", fasthttp.StatusBadRequest)
+		return cid.ID{}, objID, nil, false
+	}
+
+	return *cnrID, objID, log, true
+}
+
+// statusFromError maps NeoFS status errors to HTTP status codes.
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, apistatus.ErrObjectNotFound),
+		errors.Is(err, apistatus.ErrContainerNotFound),
+		errors.Is(err, apistatus.ErrObjectAlreadyRemoved):
+		return fasthttp.StatusNotFound
+	case errors.Is(err, apistatus.ErrObjectAccessDenied):
+		return fasthttp.StatusForbidden
+	case errors.Is(err, apistatus.ErrObjectLocked),
+		errors.Is(err, apistatus.ErrLockNonRegularObject):
+		return fasthttp.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return fasthttp.StatusGatewayTimeout
+	default:
+		return fasthttp.StatusBadRequest
+	}
+}
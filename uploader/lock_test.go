@@ -0,0 +1,37 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestUnlock(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	objID := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow"))
+
+	unlock := func(id oid.ID) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodDelete)
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		u.Unlock(&c)
+		return &c
+	}
+
+	c := unlock(objID)
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode(), "regular objects aren't removed")
+	_, err := mem.ObjectHead(context.Background(), cnrID, objID, nil, client.PrmObjectHead{})
+	require.NoError(t, err)
+
+	require.Equal(t, fasthttp.StatusNotFound, unlock(oid.ID{1}).Response.StatusCode())
+
+	lockID, err := u.putLock(context.Background(), cnrID, u.ownerID, []oid.ID{objID}, 100, nil)
+	require.NoError(t, err)
+	c = unlock(lockID)
+	require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+}
@@ -1,6 +1,7 @@
 package uploader
 
 import (
+	"errors"
 	"io"
 
 	"github.com/nspcc-dev/neofs-http-gw/uploader/multipart"
@@ -15,6 +16,21 @@ type MultipartFile interface {
 	FileName() string
 }
 
+// errNoFile is returned if the form has no part with a file.
+var errNoFile = errors.New("no file in multipart/form")
+
+// emptyFile is the payload of uploads without a body, the object has
+// attributes from headers only.
+type emptyFile struct{}
+
+func (emptyFile) Read([]byte) (int, error) { return 0, io.EOF }
+
+func (emptyFile) Close() error { return nil }
+
+func (emptyFile) ContentType() string { return "" }
+
+func (emptyFile) FileName() string { return "" }
+
 func fetchMultipartFile(l *zap.Logger, r io.Reader, boundary string) (MultipartFile, error) {
 	// To have a custom buffer (3mb) the custom multipart reader is used.
 	// https://github.com/nspcc-dev/neofs-http-gw/issues/148
@@ -23,6 +39,9 @@ func fetchMultipartFile(l *zap.Logger, r io.Reader, boundary string) (MultipartF
 	for {
 		part, err := reader.NextPart()
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errNoFile
+			}
 			return nil, err
 		}
 
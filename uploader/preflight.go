@@ -0,0 +1,62 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// errUploadDenied is returned if the upload is not allowed by container ACL or
+// the bearer token.
+var errUploadDenied = errors.New("upload is denied")
+
+// checkPutAllowed checks whether objects can be put into the container by the
+// gate with the optional bearer token. Only checks not requiring network
+// requests are done, so extended ACL can still deny the upload.
+func checkPutAllowed(cnr container.Container, cnrID cid.ID, gate user.ID, bt *bearer.Token) error {
+	role := acl.RoleOthers
+	if cnr.Owner().Equals(gate) {
+		role = acl.RoleOwner
+	}
+	if !cnr.BasicACL().IsOpAllowed(acl.OpObjectPut, role) {
+		return fmt.Errorf("%w: container basic ACL doesn't allow PUT for %s", errUploadDenied, role)
+	}
+
+	if bt == nil {
+		return nil
+	}
+	if !bt.AssertContainer(cnrID) {
+		return fmt.Errorf("%w: bearer token is issued for another container", errUploadDenied)
+	}
+	if !bt.AssertUser(gate) {
+		return fmt.Errorf("%w: bearer token is issued for another user", errUploadDenied)
+	}
+	return nil
+}
+
+// containerCache is implemented by backends caching containers, see
+// metacache.Backend.
+type containerCache interface {
+	CachedContainer(id cid.ID) (container.Container, bool)
+}
+
+// checkContainer checks that the container accepts uploads from the gate with
+// the bearer token. The container isn't requested for the check, so it's done
+// only if the backend has the container cached, otherwise storage nodes reject
+// the upload when the object is put.
+func (u *Uploader) checkContainer(cnrID cid.ID, bt *bearer.Token) error {
+	cache, ok := u.backend.(containerCache)
+	if !ok {
+		return nil
+	}
+	cnr, ok := cache.CachedContainer(cnrID)
+	if !ok {
+		return nil
+	}
+	return checkPutAllowed(cnr, cnrID, *u.ownerID, bt)
+}
@@ -0,0 +1,87 @@
+package uploader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPutAllowed(t *testing.T) {
+	var (
+		cnrID = cidtest.ID()
+		gate  = usertest.ID(t)
+		other = usertest.ID(t)
+	)
+
+	newContainer := func(owner user.ID, basic acl.Basic) container.Container {
+		var cnr container.Container
+		cnr.Init()
+		cnr.SetOwner(owner)
+		cnr.SetBasicACL(basic)
+		return cnr
+	}
+
+	require.NoError(t, checkPutAllowed(newContainer(other, acl.PublicRW), cnrID, gate, nil))
+	require.NoError(t, checkPutAllowed(newContainer(gate, acl.Private), cnrID, gate, nil))
+	require.ErrorIs(t, checkPutAllowed(newContainer(other, acl.Private), cnrID, gate, nil), errUploadDenied)
+	require.ErrorIs(t, checkPutAllowed(newContainer(other, acl.PublicRO), cnrID, gate, nil), errUploadDenied)
+
+	cnr := newContainer(other, acl.PublicRW)
+
+	var bt bearer.Token
+	require.NoError(t, checkPutAllowed(cnr, cnrID, gate, &bt))
+
+	bt.SetEACLTable(*eacl.CreateTable(cidtest.ID()))
+	require.ErrorIs(t, checkPutAllowed(cnr, cnrID, gate, &bt), errUploadDenied)
+
+	bt.SetEACLTable(*eacl.CreateTable(cnrID))
+	require.NoError(t, checkPutAllowed(cnr, cnrID, gate, &bt))
+
+	bt.ForUser(other)
+	require.ErrorIs(t, checkPutAllowed(cnr, cnrID, gate, &bt), errUploadDenied)
+
+	bt.ForUser(gate)
+	require.NoError(t, checkPutAllowed(cnr, cnrID, gate, &bt))
+}
+
+type cachingBackend struct {
+	*backend.Memory
+	cached map[cid.ID]container.Container
+}
+
+func (b cachingBackend) CachedContainer(id cid.ID) (container.Container, bool) {
+	cnr, ok := b.cached[id]
+	return cnr, ok
+}
+
+func TestCheckContainer(t *testing.T) {
+	gate := usertest.ID(t)
+
+	var cnr container.Container
+	cnr.Init()
+	cnr.SetOwner(usertest.ID(t))
+	cnr.SetBasicACL(acl.PublicRO)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	// containers aren't requested for the check
+	u := &Uploader{backend: mem, ownerID: &gate}
+	require.NoError(t, u.checkContainer(cnrID, nil))
+
+	b := cachingBackend{Memory: mem, cached: make(map[cid.ID]container.Container)}
+	u.backend = b
+	require.NoError(t, u.checkContainer(cnrID, nil))
+
+	b.cached[cnrID] = cnr
+	require.ErrorIs(t, u.checkContainer(cnrID, nil), errUploadDenied)
+}
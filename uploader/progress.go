@@ -0,0 +1,251 @@
+package uploader
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/response/pb"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// hdrUploadID is a header with client-generated ID of the upload to
+	// track its progress.
+	hdrUploadID = "X-Upload-ID"
+
+	// uploadProgressRetention is a time finished uploads are kept for
+	// progress requests.
+	uploadProgressRetention = time.Minute
+
+	// uploadProgressMaxAge is a time in-flight uploads are tracked for, longer
+	// uploads aren't available for progress requests.
+	uploadProgressMaxAge = 24 * time.Hour
+
+	// uploadProgressLimit is the maximum number of tracked uploads.
+	uploadProgressLimit = 10000
+)
+
+// Upload states.
+const (
+	uploadStateReceiving = "receiving"
+	uploadStateCompleted = "completed"
+	uploadStateFailed    = "failed"
+)
+
+// errUploadInProgress is returned on attempt to track an upload with the ID of
+// the in-flight one.
+var errUploadInProgress = errors.New("upload with the same ID is in progress")
+
+// errTooManyUploads is returned on attempt to track an upload when the
+// tracker is full of in-flight uploads.
+var errTooManyUploads = errors.New("too many tracked uploads")
+
+// uploadProgressStatus is a snapshot of the upload progress.
+type uploadProgressStatus struct {
+	UploadID string     `json:"upload_id"`
+	State    string     `json:"state"`
+	Received int64      `json:"received"`
+	Total    int64      `json:"total,omitempty"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+	ObjectID string     `json:"object_id,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Proto implements response.ProtoValue.
+func (s uploadProgressStatus) Proto() proto.Message {
+	m := &pb.UploadProgress{
+		UploadId: s.UploadID,
+		State:    s.State,
+		Received: s.Received,
+		Total:    s.Total,
+		Started:  timestamppb.New(s.Started),
+		ObjectId: s.ObjectID,
+		Error:    s.Error,
+	}
+	if s.Finished != nil {
+		m.Finished = timestamppb.New(*s.Finished)
+	}
+	return m
+}
+
+// uploadProgress is a progress of a single upload. Received bytes are updated
+// without locking, since they change on every read.
+type uploadProgress struct {
+	received atomic.Int64
+	total    int64
+	started  time.Time
+
+	mu       sync.Mutex
+	state    string
+	finished time.Time
+	objectID string
+	err      string
+}
+
+func (p *uploadProgress) finish(objectID, errMsg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.finished = time.Now()
+	p.objectID = objectID
+	p.err = errMsg
+	p.state = uploadStateCompleted
+	if errMsg != "" {
+		p.state = uploadStateFailed
+	}
+}
+
+func (p *uploadProgress) status(id string) uploadProgressStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := uploadProgressStatus{
+		UploadID: id,
+		State:    p.state,
+		Received: p.received.Load(),
+		Total:    p.total,
+		Started:  p.started,
+		ObjectID: p.objectID,
+		Error:    p.err,
+	}
+	if !p.finished.IsZero() {
+		finished := p.finished
+		res.Finished = &finished
+	}
+	return res
+}
+
+func (p *uploadProgress) expired(now time.Time, retention, maxAge time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished.IsZero() {
+		return now.Sub(p.started) > maxAge
+	}
+	return now.Sub(p.finished) > retention
+}
+
+func (p *uploadProgress) finishedAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.finished
+}
+
+// countingReader accounts bytes read from r in the upload progress.
+type countingReader struct {
+	r        io.Reader
+	progress *uploadProgress
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.progress.received.Add(int64(n))
+	return n, err
+}
+
+// uploadTracker keeps progress of in-flight uploads and recently finished ones.
+// Finished uploads are kept for the retention time, in-flight ones for maxAge,
+// the number of tracked uploads is limited.
+type uploadTracker struct {
+	mu        sync.Mutex
+	uploads   map[string]*uploadProgress
+	retention time.Duration
+	maxAge    time.Duration
+	limit     int
+}
+
+func newUploadTracker(retention, maxAge time.Duration, limit int) *uploadTracker {
+	return &uploadTracker{
+		uploads:   make(map[string]*uploadProgress),
+		retention: retention,
+		maxAge:    maxAge,
+		limit:     limit,
+	}
+}
+
+// start starts tracking of the upload with the expected body size, total is
+// negative if the size is unknown. Finished upload with the same ID is
+// replaced. If the tracker is full, expired uploads are removed first, then
+// the earliest finished one.
+func (t *uploadTracker) start(id string, total int64) (*uploadProgress, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if p, ok := t.uploads[id]; ok {
+		if p.finishedAt().IsZero() && !p.expired(now, t.retention, t.maxAge) {
+			return nil, errUploadInProgress
+		}
+		delete(t.uploads, id)
+	}
+
+	if len(t.uploads) >= t.limit && !t.evict(now) {
+		return nil, errTooManyUploads
+	}
+
+	if total < 0 {
+		total = 0
+	}
+	p := &uploadProgress{total: total, started: now, state: uploadStateReceiving}
+	t.uploads[id] = p
+	return p, nil
+}
+
+// evict removes expired uploads or the earliest finished one if there are no
+// expired uploads. It returns false if nothing is removed.
+func (t *uploadTracker) evict(now time.Time) bool {
+	var (
+		earliest   string
+		earliestAt time.Time
+		n          = len(t.uploads)
+	)
+	for k, p := range t.uploads {
+		if p.expired(now, t.retention, t.maxAge) {
+			delete(t.uploads, k)
+			continue
+		}
+		if at := p.finishedAt(); !at.IsZero() && (earliestAt.IsZero() || at.Before(earliestAt)) {
+			earliest, earliestAt = k, at
+		}
+	}
+	if len(t.uploads) < n {
+		return true
+	}
+	if earliestAt.IsZero() {
+		return false
+	}
+	delete(t.uploads, earliest)
+	return true
+}
+
+func (t *uploadTracker) get(id string) (*uploadProgress, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.uploads[id]
+	if !ok || p.expired(time.Now(), t.retention, t.maxAge) {
+		return nil, false
+	}
+	return p, true
+}
+
+// UploadProgress handles requests for the progress of uploads with X-Upload-ID
+// header.
+func (u *Uploader) UploadProgress(c *fasthttp.RequestCtx) {
+	id, _ := c.UserValue("upload_id").(string)
+
+	p, ok := u.uploads.get(id)
+	if !ok {
+		response.Error(c, "upload not found", fasthttp.StatusNotFound)
+		return
+	}
+
+	c.Response.Header.Set(fasthttp.HeaderCacheControl, "no-store")
+	response.Write(c, fasthttp.StatusOK, p.status(id))
+}
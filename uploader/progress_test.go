@@ -0,0 +1,136 @@
+package uploader
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestUploadTracker(t *testing.T) {
+	tr := newUploadTracker(time.Minute, time.Hour, 10)
+
+	p, err := tr.start("up", 10)
+	require.NoError(t, err)
+
+	_, err = tr.start("up", 10)
+	require.ErrorIs(t, err, errUploadInProgress)
+
+	data, err := io.ReadAll(countingReader{r: strings.NewReader("hello"), progress: p})
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(data))
+
+	got, ok := tr.get("up")
+	require.True(t, ok)
+	st := got.status("up")
+	require.Equal(t, uploadStateReceiving, st.State)
+	require.EqualValues(t, 5, st.Received)
+	require.EqualValues(t, 10, st.Total)
+	require.Nil(t, st.Finished)
+
+	p.finish("oid", "")
+	st = p.status("up")
+	require.Equal(t, uploadStateCompleted, st.State)
+	require.Equal(t, "oid", st.ObjectID)
+	require.NotNil(t, st.Finished)
+
+	// finished upload can be replaced
+	p, err = tr.start("up", -1)
+	require.NoError(t, err)
+	p.finish("", "access denied")
+	st = p.status("up")
+	require.Equal(t, uploadStateFailed, st.State)
+	require.Equal(t, "access denied", st.Error)
+	require.Zero(t, st.Total)
+
+	_, ok = tr.get("unknown")
+	require.False(t, ok)
+}
+
+func TestUploadTrackerRetention(t *testing.T) {
+	tr := newUploadTracker(0, time.Hour, 1)
+
+	p, err := tr.start("a", 1)
+	require.NoError(t, err)
+	p.finish("oid", "")
+	time.Sleep(time.Millisecond)
+
+	_, ok := tr.get("a")
+	require.False(t, ok)
+
+	// expired uploads are removed when the tracker is full
+	_, err = tr.start("b", 1)
+	require.NoError(t, err)
+	require.Len(t, tr.uploads, 1)
+
+	t.Run("max age", func(t *testing.T) {
+		tr := newUploadTracker(time.Hour, 0, 1)
+
+		_, err := tr.start("a", 1)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+
+		_, ok := tr.get("a")
+		require.False(t, ok)
+
+		// stale in-flight upload doesn't block the ID
+		_, err = tr.start("a", 1)
+		require.NoError(t, err)
+	})
+}
+
+func TestUploadTrackerLimit(t *testing.T) {
+	tr := newUploadTracker(time.Hour, time.Hour, 2)
+
+	a, err := tr.start("a", 1)
+	require.NoError(t, err)
+	b, err := tr.start("b", 1)
+	require.NoError(t, err)
+
+	_, err = tr.start("c", 1)
+	require.ErrorIs(t, err, errTooManyUploads)
+
+	// the earliest finished upload is evicted
+	a.finish("oid", "")
+	time.Sleep(time.Millisecond)
+	b.finish("oid", "")
+	_, err = tr.start("c", 1)
+	require.NoError(t, err)
+
+	_, ok := tr.get("a")
+	require.False(t, ok)
+	_, ok = tr.get("b")
+	require.True(t, ok)
+}
+
+func TestUploadProgressHandler(t *testing.T) {
+	u := &Uploader{log: zap.NewNop(), uploads: newUploadTracker(time.Minute, time.Hour, 10)}
+
+	request := func(id string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.SetUserValue("upload_id", id)
+		u.UploadProgress(&c)
+		return &c
+	}
+
+	require.Equal(t, fasthttp.StatusNotFound, request("up").Response.StatusCode())
+
+	p, err := u.uploads.start("up", 100)
+	require.NoError(t, err)
+	p.received.Store(40)
+
+	c := request("up")
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+
+	var st uploadProgressStatus
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &st))
+	require.Equal(t, "up", st.UploadID)
+	require.Equal(t, uploadStateReceiving, st.State)
+	require.EqualValues(t, 40, st.Received)
+	require.EqualValues(t, 100, st.Total)
+}
@@ -0,0 +1,116 @@
+package uploader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+)
+
+// maxRawHeaderSize is the maximum size of the object fields preceding the
+// payload in raw uploads, they're buffered to decode the header.
+const maxRawHeaderSize = 4 << 20
+
+// Fields of the NeoFS object protobuf message, all of them are
+// length-delimited.
+const (
+	rawFieldID = iota + 1
+	rawFieldSignature
+	rawFieldHeader
+	rawFieldPayload
+
+	protoWireBytes = 2
+)
+
+// UploadRaw handles uploads of objects in NeoFS binary format (protobuf
+// message with the ID, the signature, the header and the payload). Objects are
+// relayed to storage nodes as is, so they keep their owners and signatures.
+func (u *Uploader) UploadRaw(c *fasthttp.RequestCtx) {
+	u.upload(c, true)
+}
+
+// readRawObject reads the object in NeoFS binary format from r. The fields
+// preceding the payload are decoded as the object header, the returned reader
+// streams the payload. The payload must be the last field as it's in the
+// stable format objects are signed and transferred in.
+func readRawObject(r io.Reader) (*object.Object, io.Reader, error) {
+	var (
+		br          = bufio.NewReader(r)
+		hdr         []byte
+		payloadSize uint64
+		hasPayload  bool
+	)
+	for !hasPayload {
+		tag, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			// the object has no payload
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: read field tag: %v", errInvalidObjectHeader, err)
+		}
+		field, wire := tag>>3, tag&7
+		if field < rawFieldID || field > rawFieldPayload || wire != protoWireBytes {
+			return nil, nil, fmt.Errorf("%w: unexpected field %d of wire type %d", errInvalidObjectHeader, field, wire)
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: read field %d length: %v", errInvalidObjectHeader, field, err)
+		}
+		if field == rawFieldPayload {
+			payloadSize, hasPayload = size, true
+			break
+		}
+		if size > maxRawHeaderSize || uint64(len(hdr))+size > maxRawHeaderSize {
+			return nil, nil, fmt.Errorf("%w: header exceeds %d bytes", errInvalidObjectHeader, maxRawHeaderSize)
+		}
+
+		hdr = binary.AppendUvarint(binary.AppendUvarint(hdr, tag), size)
+		l := len(hdr)
+		hdr = append(hdr, make([]byte, size)...)
+		if _, err = io.ReadFull(br, hdr[l:]); err != nil {
+			return nil, nil, fmt.Errorf("%w: read field %d: %v", errInvalidObjectHeader, field, err)
+		}
+	}
+
+	var obj object.Object
+	if err := obj.Unmarshal(hdr); err != nil {
+		return nil, nil, fmt.Errorf("%w: decode: %v", errInvalidObjectHeader, err)
+	}
+	if !hasPayload {
+		return &obj, bytes.NewReader(nil), nil
+	}
+	if payloadSize != obj.PayloadSize() {
+		return nil, nil, fmt.Errorf("%w: payload field length %d doesn't match payload size %d",
+			errInvalidObjectHeader, payloadSize, obj.PayloadSize())
+	}
+	return &obj, &rawPayloadReader{r: io.LimitReader(br, int64(payloadSize)), rest: br, left: payloadSize}, nil
+}
+
+// rawPayloadReader reads the payload field of the raw object and checks that
+// it's complete and nothing follows it.
+type rawPayloadReader struct {
+	r    io.Reader
+	rest io.Reader
+	left uint64
+}
+
+func (p *rawPayloadReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.left -= uint64(n)
+	if errors.Is(err, io.EOF) {
+		if p.left != 0 {
+			return n, fmt.Errorf("%w: payload is truncated", errInvalidObjectHeader)
+		}
+		var one [1]byte
+		if m, _ := io.ReadFull(p.rest, one[:]); m != 0 {
+			return n, fmt.Errorf("%w: unexpected data after the payload", errInvalidObjectHeader)
+		}
+	}
+	return n, err
+}
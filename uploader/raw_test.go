@@ -0,0 +1,107 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadRawObject(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSigner(key.PrivateKey)
+
+	read := func(data []byte) (*object.Object, []byte, error) {
+		obj, r, err := readRawObject(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		payload, err := io.ReadAll(r)
+		return obj, payload, err
+	}
+
+	for _, payload := range [][]byte{nil, []byte("meow"), bytes.Repeat([]byte("purr"), 100)} {
+		hdr := signedHeader(t, signer, cidtest.ID(), payload)
+		full := *hdr
+		full.SetPayload(payload)
+
+		obj, res, err := read(marshalHeader(t, &full))
+		require.NoError(t, err)
+		require.Equal(t, marshalHeader(t, hdr), marshalHeader(t, obj))
+		require.Equal(t, len(payload), len(res))
+		require.True(t, bytes.Equal(payload, res))
+	}
+
+	hdr := signedHeader(t, signer, cidtest.ID(), []byte("meow"))
+	full := *hdr
+	full.SetPayload([]byte("meow"))
+	data := marshalHeader(t, &full)
+
+	for name, data := range map[string][]byte{
+		"trailing data":   append(data[:len(data):len(data)], 0x1a, 0),
+		"truncated":       data[:len(data)-2],
+		"unknown field":   append([]byte{5<<3 | 2, 0}, data...),
+		"wire type":       append([]byte{3 << 3, 0}, data...),
+		"length mismatch": append(marshalHeader(t, hdr), rawFieldPayload<<3|2, 3, 'm', 'e', 'o'),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := read(data)
+			require.ErrorIs(t, err, errInvalidObjectHeader)
+		})
+	}
+}
+
+func TestUploadRaw(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSigner(key.PrivateKey)
+
+	upload := func(body []byte) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.SetBodyStream(bytes.NewReader(body), len(body))
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.UploadRaw(&c)
+		return &c
+	}
+
+	payload := []byte("meow")
+	hdr := signedHeader(t, signer, cnrID, payload)
+	full := *hdr
+	full.SetPayload(payload)
+	data := marshalHeader(t, &full)
+
+	id := uploadedID(t, upload(data))
+	expected, _ := hdr.ID()
+	require.Equal(t, expected, id)
+
+	obj, ok := mem.Object(newAddress(cnrID, id))
+	require.True(t, ok)
+	require.Equal(t, data, marshalHeader(t, &obj))
+
+	full.SetPayload([]byte("purr"))
+	requireErrorCode(t, upload(marshalHeader(t, &full)), fasthttp.StatusBadRequest, errCodeInvalidObjectHeader)
+
+	other := *signedHeader(t, signer, cidtest.ID(), payload)
+	other.SetPayload(payload)
+	requireErrorCode(t, upload(marshalHeader(t, &other)), fasthttp.StatusBadRequest, errCodeInvalidObjectHeader)
+
+	// raw objects are subject to the gate policies as signed headers are
+	setNetworkInfo(mem, 10)
+	u.settings.SetMaxExpirationLifetime(time.Hour)
+	unlimited := *signedHeader(t, signer, cnrID, []byte("purr"))
+	unlimited.SetPayload([]byte("purr"))
+	requireErrorCode(t, upload(marshalHeader(t, &unlimited)), fasthttp.StatusBadRequest, errCodeExpirationPolicy)
+	unlimitedID, _ := unlimited.ID()
+	_, ok = mem.Object(newAddress(cnrID, unlimitedID))
+	require.False(t, ok)
+}
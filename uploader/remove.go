@@ -0,0 +1,324 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// hdrDeleteMarkerID is a response header with the ID of the delete marker
+// stored by the soft deletion.
+const hdrDeleteMarkerID = "X-Delete-Marker-Id"
+
+// errCodeBearerTokenRequired is the error code of requests rejected since
+// they remove objects without a bearer token.
+const errCodeBearerTokenRequired = "BEARER_TOKEN_REQUIRED"
+
+// Delete handles requests to remove the object by ID or by attribute. With
+// If-Match header the object is removed only if it's still the current one,
+// so clients treating attribute paths as mutable files don't remove the
+// object replaced by someone else. Files requested by FilePath are hidden by
+// delete markers instead if soft deletion is enabled. Removal must be enabled
+// and requests must have bearer tokens.
+func (u *Uploader) Delete(c *fasthttp.RequestCtx) {
+	var (
+		scid, _    = c.UserValue("cid").(string)
+		soid, byID = c.UserValue("oid").(string)
+		rawKey, _  = c.UserValue("attr_key").(string)
+		rawVal, _  = c.UserValue("attr_val").(string)
+		ifMatchVal = c.Request.Header.Peek(fasthttp.HeaderIfMatch)
+		log        = u.log.With(zap.String("cid", scid))
+	)
+	key, _ := url.QueryUnescape(rawKey)
+	val, _ := url.QueryUnescape(rawVal)
+	if byID {
+		log = log.With(zap.String("oid", soid))
+	} else {
+		log = log.With(zap.String("attr_key", key), zap.String("attr_val", val))
+	}
+
+	if !u.settings.DeleteEnabled() {
+		response.Error(c, "delete is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	if err := tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	cnrID, err := utils.GetContainerID(ctx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	owner, bt := u.fetchOwnerAndBearerToken(c)
+	if !requireBearerToken(c, bt) {
+		return
+	}
+
+	if !byID && key == object.AttributeFilePath && u.settings.SoftDelete() {
+		u.softDelete(ctx, c, log, *cnrID, val, owner, bt)
+		return
+	}
+
+	var current []oid.ID
+	if byID {
+		objID, err := utils.DecodeObjectID(soid)
+		if err != nil {
+			log.Error("wrong object id", zap.Error(err))
+			response.Error(c, "wrong object id", fasthttp.StatusBadRequest)
+			return
+		}
+		current = []oid.ID{objID}
+
+		if len(ifMatchVal) != 0 {
+			// removal of missing objects succeeds, but the precondition
+			// requires the object to exist
+			var prm client.PrmObjectHead
+			if bt != nil {
+				prm.WithBearerToken(*bt)
+			}
+			if _, err = u.backend.ObjectHead(ctx, *cnrID, objID, u.signer, prm); err != nil {
+				if !errors.Is(err, apistatus.ErrObjectNotFound) && !errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+					log.Error("could not head object", zap.Error(err))
+					response.StorageError(c, eacl.OperationHead, "could not head object", err, statusFromError(err))
+					return
+				}
+				current = nil
+			}
+		}
+	} else {
+		if current, err = u.findAllByAttribute(ctx, *cnrID, key, val, bt); err != nil {
+			log.Error("could not search for object", zap.Error(err))
+			response.StorageError(c, eacl.OperationSearch, "could not search for object", err, statusFromError(err))
+			return
+		}
+		if len(current) == 0 && len(ifMatchVal) == 0 {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return
+		}
+		if len(current) > 1 {
+			response.Error(c, "could not search for object: "+errAmbiguousName.Error(), fasthttp.StatusConflict)
+			return
+		}
+	}
+
+	if len(ifMatchVal) != 0 && !ifMatch(ifMatchVal, current) {
+		log.Info("current object doesn't match If-Match header", zap.ByteString("if_match", ifMatchVal))
+		response.ErrorWithCode(c, errCodePreconditionFailed, "current object doesn't match If-Match header", fasthttp.StatusPreconditionFailed)
+		return
+	}
+
+	var prm client.PrmObjectDelete
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+	for _, id := range current {
+		if _, err = u.backend.ObjectDelete(ctx, *cnrID, id, u.signer, prm); err != nil {
+			log.Error("could not remove object", zap.Stringer("oid", id), zap.Error(err))
+			response.StorageError(c, eacl.OperationDelete, "could not remove object", err, statusFromError(err))
+			return
+		}
+		log.Info("object removed", zap.Stringer("oid", id))
+	}
+
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// softDelete hides the file with the given path storing a delete marker,
+// its versions are kept, so the removal is undone by removing the marker.
+// If-Match header is checked against the newest visible version.
+func (u *Uploader) softDelete(ctx context.Context, c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, filePath string, owner *user.ID, bt *bearer.Token) {
+	ids, err := u.findAllByAttribute(ctx, cnrID, object.AttributeFilePath, filePath, bt)
+	if err != nil {
+		log.Error("could not search for object", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for object", err, statusFromError(err))
+		return
+	}
+	versions, err := u.headVersions(ctx, cnrID, ids, bt)
+	if err != nil {
+		log.Error("could not head object versions", zap.Error(err))
+		response.StorageError(c, eacl.OperationHead, "could not head object versions", err, statusFromError(err))
+		return
+	}
+
+	var current []oid.ID
+	live := utils.LiveVersions(versions)
+	if len(live) != 0 {
+		current = []oid.ID{live[0].ID}
+	}
+	if ifMatchVal := c.Request.Header.Peek(fasthttp.HeaderIfMatch); len(ifMatchVal) != 0 {
+		if !ifMatch(ifMatchVal, current) {
+			log.Info("current object doesn't match If-Match header", zap.ByteString("if_match", ifMatchVal))
+			response.ErrorWithCode(c, errCodePreconditionFailed, "current object doesn't match If-Match header", fasthttp.StatusPreconditionFailed)
+			return
+		}
+	} else if len(live) == 0 {
+		response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		return
+	}
+
+	// the marker must be newer than the current version even if it's made
+	// in the same second
+	timestamp := time.Now().Unix()
+	if live[0].Timestamp >= timestamp {
+		timestamp = live[0].Timestamp + 1
+	}
+
+	marker, err := u.putDeleteMarker(ctx, cnrID, owner, filePath, timestamp, bt)
+	if err != nil {
+		log.Error("could not put delete marker", zap.Error(err))
+		response.StorageError(c, eacl.OperationPut, "could not put delete marker", err, statusFromError(err))
+		return
+	}
+
+	log.Info("object hidden by delete marker", zap.Stringer("marker", marker))
+	c.Response.Header.Set(hdrDeleteMarkerID, marker.EncodeToString())
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// putDeleteMarker stores an empty object hiding versions of the file with the
+// given path made before the timestamp.
+func (u *Uploader) putDeleteMarker(ctx context.Context, cnrID cid.ID, owner *user.ID, filePath string, timestamp int64, bt *bearer.Token) (oid.ID, error) {
+	attrs := make([]object.Attribute, 0, 3)
+	for _, kv := range [][2]string{
+		{object.AttributeFilePath, filePath},
+		{object.AttributeTimestamp, strconv.FormatInt(timestamp, 10)},
+		{utils.AttributeDeleteMarker, "true"},
+	} {
+		attr := object.NewAttribute()
+		attr.SetKey(kv[0])
+		attr.SetValue(kv[1])
+		attrs = append(attrs, *attr)
+	}
+
+	var obj object.Object
+	obj.SetContainerID(cnrID)
+	obj.SetOwnerID(owner)
+	obj.SetAttributes(attrs...)
+
+	return u.putObject(ctx, obj, bytes.NewReader(nil), bt, 0, 0)
+}
+
+// headVersions heads the objects with the same FilePath and returns their
+// versions from the newest one, removed objects are skipped.
+func (u *Uploader) headVersions(ctx context.Context, cnrID cid.ID, ids []oid.ID, bt *bearer.Token) ([]utils.ObjectVersion, error) {
+	var prm client.PrmObjectHead
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	versions := make([]utils.ObjectVersion, 0, len(ids))
+	for _, id := range ids {
+		hdr, err := u.backend.ObjectHead(ctx, cnrID, id, u.signer, prm)
+		if err != nil {
+			if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+				continue
+			}
+			return nil, fmt.Errorf("head %s: %w", id, err)
+		}
+		versions = append(versions, utils.NewObjectVersion(id, hdr))
+	}
+	utils.SortVersions(versions)
+	return versions, nil
+}
+
+// maxDeletedVersions limits the number of objects removed by a single request
+// to remove file versions.
+const maxDeletedVersions = 100
+
+// DeleteVersions handles requests to remove all versions of the file with the
+// given FilePath including delete markers, so soft-deleted files are removed
+// for real. It's available only with soft deletion and removal enabled and
+// requires a bearer token.
+func (u *Uploader) DeleteVersions(c *fasthttp.RequestCtx) {
+	var (
+		scid, _    = c.UserValue("cid").(string)
+		rawPath, _ = c.UserValue("path").(string)
+		filePath   string
+		err        error
+	)
+	if filePath, err = url.PathUnescape(rawPath); err != nil {
+		filePath = rawPath
+	}
+	log := u.log.With(zap.String("cid", scid), zap.String("path", filePath))
+
+	if !u.settings.SoftDelete() || !u.settings.DeleteEnabled() {
+		response.Error(c, "delete of versions is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	cnrID, err := utils.GetContainerID(ctx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	_, bt := u.fetchOwnerAndBearerToken(c)
+	if !requireBearerToken(c, bt) {
+		return
+	}
+
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(object.AttributeFilePath, filePath, object.MatchStringEqual)
+
+	ids, err := u.searchObjects(ctx, *cnrID, filters, maxDeletedVersions, bt)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for objects", err, statusFromError(err))
+		return
+	}
+	if len(ids) == 0 {
+		response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		return
+	}
+
+	var prm client.PrmObjectDelete
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+	for _, id := range ids {
+		if _, err = u.backend.ObjectDelete(ctx, *cnrID, id, u.signer, prm); err != nil {
+			log.Error("could not remove object", zap.Stringer("oid", id), zap.Error(err))
+			response.StorageError(c, eacl.OperationDelete, "could not remove object "+id.EncodeToString(), err, statusFromError(err))
+			return
+		}
+	}
+
+	log.Info("object versions removed", zap.Int("count", len(ids)))
+	c.Response.SetStatusCode(fasthttp.StatusNoContent)
+}
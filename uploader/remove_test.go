@@ -0,0 +1,332 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"strconv"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func newConditionalUploader(t *testing.T) (*Uploader, *backend.Memory, cid.ID) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	settings.SetDeleteEnabled(true)
+	return New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil), mem, cnrID
+}
+
+// uploadTagged uploads the file with Tag attribute, headers are the extra
+// request headers.
+func uploadTagged(t *testing.T, u *Uploader, cnrID cid.ID, tag, payload string, headers ...string) *fasthttp.RequestCtx {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "cat.txt")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	var c fasthttp.RequestCtx
+	// the gate server doesn't normalize header names, so attribute headers
+	// like X-Attribute-FilePath must keep their case as in requests
+	c.Request.Header.DisableNormalizing()
+	c.Request.Header.SetMethod(fasthttp.MethodPost)
+	c.Request.Header.SetContentType(mw.FormDataContentType())
+	c.Request.Header.Set("X-Attribute-Tag", tag)
+	for i := 0; i < len(headers); i += 2 {
+		c.Request.Header.Set(headers[i], headers[i+1])
+	}
+	c.Request.SetBodyStream(bytes.NewReader(body.Bytes()), body.Len())
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	u.Upload(&c)
+	return &c
+}
+
+// authHeader returns Authorization header with a signed bearer token as
+// header name and value, removals require bearer tokens.
+func authHeader(t *testing.T) (string, string) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	var btoken bearer.Token
+	require.NoError(t, btoken.Sign(user.NewAutoIDSigner(key.PrivateKey)))
+	return fasthttp.HeaderAuthorization, "Bearer " + base64.StdEncoding.EncodeToString(btoken.Marshal())
+}
+
+func uploadedID(t *testing.T, c *fasthttp.RequestCtx) oid.ID {
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+	var resp putResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	var id oid.ID
+	require.NoError(t, id.DecodeString(resp.ObjectID))
+	return id
+}
+
+func requireErrorCode(t *testing.T, c *fasthttp.RequestCtx, status int, code string) {
+	require.Equal(t, status, c.Response.StatusCode(), string(c.Response.Body()))
+	require.Equal(t, code, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+}
+
+func TestDelete(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	authKey, authVal := authHeader(t)
+
+	remove := func(id, key, val, ifMatch string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodDelete)
+		c.Request.Header.Set(authKey, authVal)
+		if ifMatch != "" {
+			c.Request.Header.Set(fasthttp.HeaderIfMatch, ifMatch)
+		}
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		if id != "" {
+			c.SetUserValue("oid", id)
+		} else {
+			c.SetUserValue("attr_key", key)
+			c.SetUserValue("attr_val", val)
+		}
+		u.Delete(&c)
+		return &c
+	}
+	exists := func(id oid.ID) bool {
+		_, ok := mem.Object(newAddress(cnrID, id))
+		return ok
+	}
+
+	t.Run("by id", func(t *testing.T) {
+		id := uploadedID(t, uploadTagged(t, u, cnrID, "by-id", "meow"))
+		other := uploadedID(t, uploadTagged(t, u, cnrID, "by-id-other", "purr"))
+
+		c := remove(id.EncodeToString(), "", "", `"`+other.EncodeToString()+`"`)
+		requireErrorCode(t, c, fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+		require.True(t, exists(id))
+
+		c = remove(id.EncodeToString(), "", "", `W/"`+id.EncodeToString()+`"`)
+		require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+		require.False(t, exists(id))
+
+		// the removed object doesn't match any tag
+		c = remove(id.EncodeToString(), "", "", "*")
+		requireErrorCode(t, c, fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+	})
+
+	t.Run("by attribute", func(t *testing.T) {
+		id := uploadedID(t, uploadTagged(t, u, cnrID, "by-attr", "meow"))
+
+		c := remove("", "Tag", "by-attr", `"`+oidtest.ID().EncodeToString()+`"`)
+		requireErrorCode(t, c, fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+		require.True(t, exists(id))
+
+		c = remove("", "Tag", "by-attr", `"`+id.EncodeToString()+`"`)
+		require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+		require.False(t, exists(id))
+
+		c = remove("", "Tag", "by-attr", "")
+		require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+		c = remove("", "Tag", "by-attr", "*")
+		requireErrorCode(t, c, fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+
+		uploadedID(t, uploadTagged(t, u, cnrID, "dup", "meow"))
+		uploadedID(t, uploadTagged(t, u, cnrID, "dup", "purr"))
+		c = remove("", "Tag", "dup", "")
+		require.Equal(t, fasthttp.StatusConflict, c.Response.StatusCode())
+	})
+}
+
+func TestDeleteRestrictions(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	authKey, authVal := authHeader(t)
+	id := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow"))
+
+	remove := func(headers ...string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodDelete)
+		for i := 0; i < len(headers); i += 2 {
+			c.Request.Header.Set(headers[i], headers[i+1])
+		}
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		u.Delete(&c)
+		return &c
+	}
+
+	// the gate key is never used to remove objects
+	requireErrorCode(t, remove(), fasthttp.StatusUnauthorized, errCodeBearerTokenRequired)
+	c := uploadTagged(t, u, cnrID, "cat", "purr", hdrOverwriteAttribute, "Tag")
+	requireErrorCode(t, c, fasthttp.StatusUnauthorized, errCodeBearerTokenRequired)
+
+	u.settings.SetDeleteEnabled(false)
+	require.Equal(t, fasthttp.StatusForbidden, remove(authKey, authVal).Response.StatusCode())
+	c = uploadTagged(t, u, cnrID, "cat", "purr", hdrOverwriteAttribute, "Tag", authKey, authVal)
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+
+	_, ok := mem.Object(newAddress(cnrID, id))
+	require.True(t, ok)
+}
+
+func TestUploadOverwrite(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	authKey, authVal := authHeader(t)
+
+	first := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow", hdrOverwriteAttribute, "Tag", authKey, authVal))
+
+	// someone else has replaced the object already
+	c := uploadTagged(t, u, cnrID, "cat", "purr", hdrOverwriteAttribute, "Tag", authKey, authVal,
+		fasthttp.HeaderIfMatch, `"`+oidtest.ID().EncodeToString()+`"`)
+	requireErrorCode(t, c, fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+
+	c = uploadTagged(t, u, cnrID, "cat", "purr", hdrOverwriteAttribute, "Tag", authKey, authVal,
+		fasthttp.HeaderIfMatch, `"`+first.EncodeToString()+`"`)
+	second := uploadedID(t, c)
+
+	var resp putResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	require.Equal(t, []replacedObject{{ObjectID: first.EncodeToString(), Removed: true}}, resp.Replaced)
+
+	_, ok := mem.Object(newAddress(cnrID, first))
+	require.False(t, ok)
+	_, ok = mem.Object(newAddress(cnrID, second))
+	require.True(t, ok)
+
+	c = uploadTagged(t, u, cnrID, "dog", "woof", fasthttp.HeaderIfMatch, "*")
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	c = uploadTagged(t, u, cnrID, "dog", "woof", hdrOverwriteAttribute, "Tag", hdrIfNoneMatchAttribute, "Tag")
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	c = uploadTagged(t, u, cnrID, "dog", "woof", hdrOverwriteAttribute, "Name", authKey, authVal)
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+}
+
+func newAddress(cnrID cid.ID, id oid.ID) oid.Address {
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(id)
+	return addr
+}
+
+func TestSoftDelete(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	u.settings.SetSoftDelete(true)
+	u.settings.SetAttributeCase(utils.AttributeCaseKnown)
+	authKey, authVal := authHeader(t)
+
+	remove := func(h fasthttp.RequestHandler, filePath, ifMatch string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodDelete)
+		c.Request.Header.Set(authKey, authVal)
+		if ifMatch != "" {
+			c.Request.Header.Set(fasthttp.HeaderIfMatch, ifMatch)
+		}
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("attr_key", object.AttributeFilePath)
+		c.SetUserValue("attr_val", filePath)
+		c.SetUserValue("path", filePath)
+		h(&c)
+		return &c
+	}
+	versions := func() []utils.ObjectVersion {
+		ids, err := u.findAllByAttribute(context.Background(), cnrID, object.AttributeFilePath, "docs/cat.txt", nil)
+		require.NoError(t, err)
+		res, err := u.headVersions(context.Background(), cnrID, ids, nil)
+		require.NoError(t, err)
+		return res
+	}
+
+	first := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow", "X-Attribute-FilePath", "docs/cat.txt"))
+	second := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "purr", "X-Attribute-FilePath", "docs/cat.txt"))
+
+	// the newest version is the current one
+	vs := versions()
+	require.Len(t, vs, 2)
+	current := vs[0].ID
+	require.Contains(t, []oid.ID{first, second}, current)
+
+	c := remove(u.Delete, "docs/cat.txt", `"`+vs[1].ID.EncodeToString()+`"`)
+	requireErrorCode(t, c, fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+
+	c = remove(u.Delete, "docs/cat.txt", `"`+current.EncodeToString()+`"`)
+	require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+	var marker oid.ID
+	require.NoError(t, marker.DecodeString(string(c.Response.Header.Peek(hdrDeleteMarkerID))))
+
+	// versions are kept, but hidden
+	vs = versions()
+	require.Len(t, vs, 3)
+	require.Equal(t, marker, vs[0].ID)
+	require.True(t, vs[0].DeleteMarker)
+	require.Empty(t, utils.LiveVersions(vs))
+
+	require.Equal(t, fasthttp.StatusNotFound, remove(u.Delete, "docs/cat.txt", "").Response.StatusCode())
+	requireErrorCode(t, remove(u.Delete, "docs/cat.txt", "*"), fasthttp.StatusPreconditionFailed, errCodePreconditionFailed)
+
+	// purge removes all versions with the marker
+	c = remove(u.DeleteVersions, "docs%2Fcat.txt", "")
+	require.Equal(t, fasthttp.StatusNoContent, c.Response.StatusCode(), string(c.Response.Body()))
+	require.Empty(t, versions())
+	for _, id := range []oid.ID{first, second, marker} {
+		_, ok := mem.Object(newAddress(cnrID, id))
+		require.False(t, ok)
+	}
+	require.Equal(t, fasthttp.StatusNotFound, remove(u.DeleteVersions, "docs/cat.txt", "").Response.StatusCode())
+}
+
+func TestDeleteVersionsRestrictions(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	u.settings.SetAttributeCase(utils.AttributeCaseKnown)
+	authKey, authVal := authHeader(t)
+
+	remove := func(filePath string, headers ...string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodDelete)
+		for i := 0; i < len(headers); i += 2 {
+			c.Request.Header.Set(headers[i], headers[i+1])
+		}
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("path", filePath)
+		u.DeleteVersions(&c)
+		return &c
+	}
+
+	id := uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow", "X-Attribute-FilePath", "cat.txt"))
+	require.Equal(t, fasthttp.StatusForbidden, remove("cat.txt", authKey, authVal).Response.StatusCode())
+
+	u.settings.SetSoftDelete(true)
+	requireErrorCode(t, remove("cat.txt"), fasthttp.StatusUnauthorized, errCodeBearerTokenRequired)
+	_, ok := mem.Object(newAddress(cnrID, id))
+	require.True(t, ok)
+
+	for i := 0; i < maxDeletedVersions+1; i++ {
+		uploadedID(t, uploadTagged(t, u, cnrID, "dog", strconv.Itoa(i), "X-Attribute-FilePath", "dog.txt"))
+	}
+	require.Equal(t, fasthttp.StatusBadRequest, remove("dog.txt", authKey, authVal).Response.StatusCode())
+	ids, err := u.findAllByAttribute(context.Background(), cnrID, object.AttributeFilePath, "dog.txt", nil)
+	require.NoError(t, err)
+	require.Len(t, ids, maxDeletedVersions+1)
+}
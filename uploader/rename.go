@@ -0,0 +1,231 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// errAmbiguousName is returned if several objects have the same name.
+var errAmbiguousName = errors.New("several objects have the same name")
+
+type renameResponse struct {
+	ContainerID string `json:"container_id"`
+	ObjectID    string `json:"object_id"`
+	OldObjectID string `json:"old_object_id"`
+	// Removed is false if the new object is stored, but the old one can't be
+	// removed, Error contains the reason then.
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// renameAttributes returns a copy of the attributes with the value of the key
+// replaced. FileName follows the new FilePath, so downloads get the new name.
+func renameAttributes(attrs []object.Attribute, key, val string) []object.Attribute {
+	values := map[string]string{key: val}
+	if key == object.AttributeFilePath {
+		values[object.AttributeFileName] = path.Base(val)
+	}
+
+	res := make([]object.Attribute, 0, len(attrs)+1)
+	for _, attr := range attrs {
+		if v, ok := values[attr.Key()]; ok {
+			k := attr.Key()
+			delete(values, k)
+			attr = *object.NewAttribute()
+			attr.SetKey(k)
+			attr.SetValue(v)
+		}
+		res = append(res, attr)
+	}
+	if v, ok := values[object.AttributeFileName]; ok {
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeFileName)
+		attr.SetValue(v)
+		res = append(res, *attr)
+	}
+	return res
+}
+
+// Rename handles requests to rename objects by FilePath or FileName attribute.
+// Objects are immutable, so the object is copied with the new attribute value
+// and removed then, the other attributes and the payload are preserved.
+// Renaming removes objects, so it must be enabled and requires bearer token.
+func (u *Uploader) Rename(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		from    = string(c.QueryArgs().Peek("from"))
+		to      = string(c.QueryArgs().Peek("to"))
+		log     = u.log.With(zap.String("cid", scid), zap.String("from", from), zap.String("to", to))
+	)
+
+	if !u.settings.RenameEnabled() {
+		response.Error(c, "rename is disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	if from == "" || to == "" {
+		response.Error(c, "from and to must not be empty", fasthttp.StatusBadRequest)
+		return
+	}
+
+	attr, err := parsePathAttribute(string(c.QueryArgs().Peek("attribute")))
+	if err != nil {
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if attr == object.AttributeFilePath && u.settings.NormalizeFilePath() {
+		if to, err = normalizeFilePath(to); err != nil {
+			response.ErrorWithCode(c, errCodeInvalidFilePath, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch bearer token", zap.Error(err))
+		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
+	cnrID, err := utils.GetContainerID(ctx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	owner, bt := u.fetchOwnerAndBearerToken(c)
+	if !requireBearerToken(c, bt) {
+		return
+	}
+
+	oldID, err := u.findUniqueByAttribute(ctx, *cnrID, attr, from, bt)
+	if err != nil {
+		log.Error("could not search for object", zap.Error(err))
+		status := statusFromError(err)
+		if errors.Is(err, errAmbiguousName) {
+			status = fasthttp.StatusConflict
+		}
+		response.StorageError(c, eacl.OperationSearch, "could not search for object", err, status)
+		return
+	}
+	if oldID == nil {
+		response.Error(c, "Not Found", fasthttp.StatusNotFound)
+		return
+	}
+
+	existing, err := u.findByAttribute(ctx, *cnrID, attr, to, bt)
+	if err != nil {
+		log.Error("could not search for existing object", zap.Error(err))
+		response.StorageError(c, eacl.OperationSearch, "could not search for existing object", err, statusFromError(err))
+		return
+	}
+	if existing != nil {
+		log.Info("object with the new name already exists", zap.Stringer("oid", existing))
+		response.Error(c, "object "+existing.EncodeToString()+" already has the name", fasthttp.StatusConflict)
+		return
+	}
+
+	newID, err := u.copyRenamed(ctx, *cnrID, *oldID, attr, to, owner, bt)
+	if err != nil {
+		log.Error("could not copy object", zap.Stringer("oid", oldID), zap.Error(err))
+		response.StorageError(c, eacl.OperationPut, "could not copy object", err, statusFromError(err))
+		return
+	}
+
+	resp := renameResponse{
+		ContainerID: cnrID.EncodeToString(),
+		ObjectID:    newID.EncodeToString(),
+		OldObjectID: oldID.EncodeToString(),
+		Removed:     true,
+	}
+
+	var prm client.PrmObjectDelete
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+	if _, err = u.backend.ObjectDelete(ctx, *cnrID, *oldID, u.signer, prm); err != nil {
+		log.Warn("could not remove renamed object", zap.Stringer("oid", oldID), zap.Error(err))
+		resp.Removed = false
+		resp.Error = err.Error()
+	}
+
+	log.Info("object renamed", zap.Stringer("old", oldID), zap.Stringer("new", newID))
+	writeJSON(c, fasthttp.StatusOK, &resp)
+}
+
+// findUniqueByAttribute searches for a root object with the given attribute
+// value. Nil is returned if there is no such object, errAmbiguousName if there
+// are several ones.
+func (u *Uploader) findUniqueByAttribute(ctx context.Context, cnrID cid.ID, key, val string, bt *bearer.Token) (*oid.ID, error) {
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	filters.AddFilter(key, val, object.MatchStringEqual)
+
+	var prm client.PrmObjectSearch
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	res, err := u.backend.ObjectSearchInit(ctx, cnrID, u.signer, filters, prm)
+	if err != nil {
+		return nil, fmt.Errorf("init searching: %w", err)
+	}
+	defer res.Close()
+
+	var ids []oid.ID
+	err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return len(ids) > 1
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &ids[0], nil
+	default:
+		return nil, errAmbiguousName
+	}
+}
+
+// copyRenamed stores a copy of the object with the new attribute value.
+func (u *Uploader) copyRenamed(ctx context.Context, cnrID cid.ID, id oid.ID, key, val string, owner *user.ID, bt *bearer.Token) (oid.ID, error) {
+	var prm client.PrmObjectGet
+	if bt != nil {
+		prm.WithBearerToken(*bt)
+	}
+
+	hdr, payload, err := u.backend.ObjectGetInit(ctx, cnrID, id, u.signer, prm)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("get object: %w", err)
+	}
+	defer payload.Close()
+
+	var obj object.Object
+	obj.SetContainerID(cnrID)
+	obj.SetOwnerID(owner)
+	obj.SetAttributes(renameAttributes(hdr.Attributes(), key, val)...)
+
+	return u.putObject(ctx, obj, payload, bt, 0, 0)
+}
@@ -0,0 +1,94 @@
+package uploader
+
+import (
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestRenameAttributes(t *testing.T) {
+	attr := func(key, val string) object.Attribute {
+		a := object.NewAttribute()
+		a.SetKey(key)
+		a.SetValue(val)
+		return *a
+	}
+
+	attrs := []object.Attribute{
+		attr(object.AttributeFileName, "cat.jpg"),
+		attr(object.AttributeFilePath, "dir/cat.jpg"),
+		attr(object.AttributeContentType, "image/jpeg"),
+	}
+
+	res := renameAttributes(attrs, object.AttributeFilePath, "new/dog.jpg")
+	require.Equal(t, []object.Attribute{
+		attr(object.AttributeFileName, "dog.jpg"),
+		attr(object.AttributeFilePath, "new/dog.jpg"),
+		attr(object.AttributeContentType, "image/jpeg"),
+	}, res, "FileName follows FilePath")
+
+	// original attributes are kept
+	require.Equal(t, "cat.jpg", attrs[0].Value())
+	require.Equal(t, "dir/cat.jpg", attrs[1].Value())
+
+	res = renameAttributes(attrs, object.AttributeFileName, "dog.jpg")
+	require.Equal(t, []object.Attribute{
+		attr(object.AttributeFileName, "dog.jpg"),
+		attr(object.AttributeFilePath, "dir/cat.jpg"),
+		attr(object.AttributeContentType, "image/jpeg"),
+	}, res)
+
+	res = renameAttributes(attrs[1:], object.AttributeFilePath, "new/dog.jpg")
+	require.Equal(t, []object.Attribute{
+		attr(object.AttributeFilePath, "new/dog.jpg"),
+		attr(object.AttributeContentType, "image/jpeg"),
+		attr(object.AttributeFileName, "dog.jpg"),
+	}, res)
+}
+
+func TestRenameValidation(t *testing.T) {
+	u := &Uploader{log: zap.NewNop(), settings: &Settings{}}
+	u.settings.SetRenameEnabled(true)
+
+	request := func(query string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/rename/cid?" + query)
+		c.SetUserValue("cid", cidtest.ID().EncodeToString())
+		u.Rename(&c)
+		return &c
+	}
+
+	require.Equal(t, fasthttp.StatusBadRequest, request("to=b").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusBadRequest, request("from=a").Response.StatusCode())
+	require.Equal(t, fasthttp.StatusBadRequest, request("from=a&to=b&attribute=Owner").Response.StatusCode())
+}
+
+func TestRenameRestrictions(t *testing.T) {
+	u, _, cnrID := newConditionalUploader(t)
+	uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow", "X-Attribute-FilePath", "dir/cat.txt"))
+
+	rename := func(headers ...string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.SetRequestURI("/rename/cid?from=dir/cat.txt&to=new/dog.txt")
+		for i := 0; i < len(headers); i += 2 {
+			c.Request.Header.Set(headers[i], headers[i+1])
+		}
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.Rename(&c)
+		return &c
+	}
+
+	authKey, authVal := authHeader(t)
+	require.Equal(t, fasthttp.StatusForbidden, rename(authKey, authVal).Response.StatusCode(), "disabled by default")
+
+	u.settings.SetRenameEnabled(true)
+	requireErrorCode(t, rename(), fasthttp.StatusUnauthorized, errCodeBearerTokenRequired)
+
+	c := rename(authKey, authVal)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+}
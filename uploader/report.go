@@ -0,0 +1,329 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxReportSize limits the size of the report request body.
+	maxReportSize = 16 << 10
+
+	// errCodeReportRateLimited is an error code of reports rejected because
+	// the client sent too many of them.
+	errCodeReportRateLimited = "REPORT_RATE_LIMITED"
+)
+
+// Attributes of stored abuse reports identifying the reported object.
+const (
+	attrReportedContainer = "Reported-Container"
+	attrReportedObject    = "Reported-Object"
+	attrReportReason      = "Report-Reason"
+)
+
+// reportReasons are the reasons the object can be reported for.
+var reportReasons = map[string]struct{}{
+	"spam":      {},
+	"phishing":  {},
+	"malware":   {},
+	"copyright": {},
+	"illegal":   {},
+	"other":     {},
+}
+
+// AbuseReportConfig contains parameters of abuse reports intake.
+type AbuseReportConfig struct {
+	// Container is the ID or the NNS name of the container reports are
+	// stored in.
+	Container string
+	// WebhookURL is the URL reports are posted to after they're stored, no
+	// webhook is called if it's empty.
+	WebhookURL string
+	// WebhookTimeout limits a single webhook request.
+	WebhookTimeout time.Duration
+	// RateLimit is the number of reports a client can send per RateWindow,
+	// zero means unlimited.
+	RateLimit int
+	// RateWindow is the period the rate limit is applied to.
+	RateWindow time.Duration
+	// Captcha verifies the challenge tokens of reports, nil if no challenge
+	// is required.
+	Captcha *CaptchaVerifier
+}
+
+// AbuseReports accepts abuse reports limiting the rate of reports per
+// client address.
+type AbuseReports struct {
+	cfg    AbuseReportConfig
+	client *http.Client
+
+	mu      sync.Mutex
+	windows map[string]*reportWindow
+}
+
+type reportWindow struct {
+	start time.Time
+	count int
+}
+
+// abuseReport is the report sent by the client, it's stored as is in the
+// report object with the report metadata added.
+type abuseReport struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+	Contact string `json:"contact,omitempty"`
+}
+
+// storedReport is the payload of the report object and the webhook request
+// body.
+type storedReport struct {
+	ReportID    string `json:"report_id,omitempty"`
+	ContainerID string `json:"container_id"`
+	ObjectID    string `json:"object_id"`
+	abuseReport
+	ClientIP   string    `json:"client_ip,omitempty"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+type reportResponse struct {
+	ReportID string `json:"report_id"`
+}
+
+// NewAbuseReports creates the abuse reports intake.
+func NewAbuseReports(cfg AbuseReportConfig) *AbuseReports {
+	return &AbuseReports{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.WebhookTimeout},
+		windows: make(map[string]*reportWindow),
+	}
+}
+
+// allow counts the report from the client and checks whether it fits the
+// rate limit. If it doesn't, the time after which the client can retry is
+// returned.
+func (r *AbuseReports) allow(client string, now time.Time) (bool, time.Duration) {
+	if r.cfg.RateLimit <= 0 {
+		return true, 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, w := range r.windows {
+		if now.Sub(w.start) >= r.cfg.RateWindow {
+			delete(r.windows, key)
+		}
+	}
+
+	w, ok := r.windows[client]
+	if !ok {
+		w = &reportWindow{start: now}
+		r.windows[client] = w
+	}
+	if w.count >= r.cfg.RateLimit {
+		return false, w.start.Add(r.cfg.RateWindow).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// parseAbuseReport parses and validates the report request body.
+func parseAbuseReport(body io.Reader) (abuseReport, error) {
+	var rep abuseReport
+
+	data, err := io.ReadAll(io.LimitReader(body, maxReportSize+1))
+	if err != nil {
+		return rep, err
+	}
+	if len(data) > maxReportSize {
+		return rep, fmt.Errorf("report is larger than %d bytes", maxReportSize)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&rep); err != nil {
+		return rep, fmt.Errorf("invalid report: %w", err)
+	}
+	if _, ok := reportReasons[rep.Reason]; !ok {
+		return rep, fmt.Errorf("unknown report reason '%s'", rep.Reason)
+	}
+	return rep, nil
+}
+
+// Report handles abuse reports of objects. Reports are stored in the
+// container configured by the operator and posted to the webhook.
+func (u *Uploader) Report(c *fasthttp.RequestCtx) {
+	var (
+		scid, _ = c.UserValue("cid").(string)
+		soid, _ = c.UserValue("oid").(string)
+		log     = u.log.With(zap.String("cid", scid), zap.String("oid", soid))
+		reports = u.settings.AbuseReports()
+	)
+
+	if reports == nil {
+		response.Error(c, "abuse reports are disabled", fasthttp.StatusForbidden)
+		return
+	}
+
+	cnrID, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+	objID, err := utils.DecodeObjectID(soid)
+	if err != nil {
+		log.Error("wrong object id", zap.Error(err))
+		response.Error(c, "wrong object id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	var remoteIP string
+	if ip := clientip.Load(c); ip != nil {
+		remoteIP = ip.String()
+	}
+
+	if ok, retryAfter := reports.allow(remoteIP, time.Now()); !ok {
+		log.Warn("abuse report rate limit exceeded", zap.String("client", remoteIP))
+		response.ErrorWithCode(c, errCodeReportRateLimited, "too many reports", fasthttp.StatusTooManyRequests)
+		c.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(int(retryAfter.Seconds())+1))
+		return
+	}
+
+	if v := reports.cfg.Captcha; v != nil {
+		if err = v.verify(u.appCtx, &c.Request.Header, remoteIP); err != nil {
+			log.Error("abuse report rejected", zap.Error(err))
+			if errors.Is(err, errCaptchaRequired) {
+				response.ErrorWithCode(c, errCodeCaptchaRequired, err.Error(), fasthttp.StatusForbidden)
+			} else {
+				response.Error(c, "could not verify captcha: "+err.Error(), fasthttp.StatusServiceUnavailable)
+			}
+			return
+		}
+	}
+
+	var body io.Reader = c.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.PostBody())
+	}
+	rep, err := parseAbuseReport(body)
+	if err != nil {
+		log.Error("could not parse abuse report", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	// reports of objects the gate can't find are useless for takedowns, other
+	// failures, e.g. denied access, don't prevent the report
+	if _, err = u.backend.ObjectHead(u.appCtx, *cnrID, objID, u.signer, client.PrmObjectHead{}); err != nil {
+		if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrContainerNotFound) ||
+			errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+			log.Error("reported object not found", zap.Error(err))
+			response.Error(c, "reported object not found", fasthttp.StatusNotFound)
+			return
+		}
+		log.Warn("could not check reported object", zap.Error(err))
+	}
+
+	stored := storedReport{
+		ContainerID: cnrID.EncodeToString(),
+		ObjectID:    objID.EncodeToString(),
+		abuseReport: rep,
+		ClientIP:    remoteIP,
+		ReportedAt:  time.Now().UTC(),
+	}
+	reportID, err := u.putReport(reports.cfg.Container, &stored)
+	if err != nil {
+		log.Error("could not store abuse report", zap.Error(err))
+		response.Error(c, "could not store abuse report", fasthttp.StatusServiceUnavailable)
+		return
+	}
+	stored.ReportID = reportID.EncodeToString()
+
+	log.Info("abuse report stored", zap.String("report", stored.ReportID), zap.String("reason", rep.Reason))
+
+	if reports.cfg.WebhookURL != "" {
+		go reports.notify(u.appCtx, u.log, &stored)
+	}
+
+	writeJSON(c, fasthttp.StatusAccepted, &reportResponse{ReportID: stored.ReportID})
+}
+
+// putReport stores the report in the reports container on behalf of the gate.
+func (u *Uploader) putReport(container string, rep *storedReport) (oid.ID, error) {
+	cnrID, err := utils.GetContainerID(u.appCtx, container, u.containerResolver)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("reports container '%s': %w", container, err)
+	}
+
+	payload, err := json.Marshal(rep)
+	if err != nil {
+		return oid.ID{}, err
+	}
+
+	attrs := make([]object.Attribute, 0, 6)
+	for _, kv := range [][2]string{
+		{object.AttributeContentType, "application/json"},
+		{object.AttributeTimestamp, strconv.FormatInt(rep.ReportedAt.Unix(), 10)},
+		{object.AttributeFileName, "report-" + rep.ObjectID + ".json"},
+		{attrReportedContainer, rep.ContainerID},
+		{attrReportedObject, rep.ObjectID},
+		{attrReportReason, rep.Reason},
+	} {
+		attr := object.NewAttribute()
+		attr.SetKey(kv[0])
+		attr.SetValue(kv[1])
+		attrs = append(attrs, *attr)
+	}
+
+	var obj object.Object
+	obj.SetContainerID(*cnrID)
+	obj.SetOwnerID(u.ownerID)
+	obj.SetAttributes(attrs...)
+
+	return u.putObject(u.appCtx, obj, bytes.NewReader(payload), nil, int64(len(payload)), 0)
+}
+
+// notify posts the stored report to the webhook, failures are only logged
+// since the report is already in the container.
+func (r *AbuseReports) notify(ctx context.Context, log *zap.Logger, rep *storedReport) {
+	log = log.With(zap.String("report", rep.ReportID))
+
+	body, err := json.Marshal(rep)
+	if err != nil {
+		log.Error("could not encode abuse report", zap.Error(err))
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		log.Error("could not create abuse report webhook request", zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		log.Error("abuse report webhook failed", zap.Error(err))
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Error("abuse report webhook failed", zap.String("status", resp.Status))
+	}
+}
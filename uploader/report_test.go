@@ -0,0 +1,177 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestParseAbuseReport(t *testing.T) {
+	rep, err := parseAbuseReport(strings.NewReader(`{"reason":"spam","details":"ads","contact":"a@b.c"}`))
+	require.NoError(t, err)
+	require.Equal(t, abuseReport{Reason: "spam", Details: "ads", Contact: "a@b.c"}, rep)
+
+	for name, body := range map[string]string{
+		"empty":          ``,
+		"unknown reason": `{"reason":"boring"}`,
+		"no reason":      `{"details":"ads"}`,
+		"unknown field":  `{"reason":"spam","url":"http://example.com"}`,
+		"too large":      `{"reason":"spam","details":"` + strings.Repeat("a", maxReportSize) + `"}`,
+	} {
+		_, err = parseAbuseReport(strings.NewReader(body))
+		require.Error(t, err, name)
+	}
+}
+
+func TestAbuseReportsRateLimit(t *testing.T) {
+	r := NewAbuseReports(AbuseReportConfig{RateLimit: 2, RateWindow: time.Minute})
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		ok, _ := r.allow("10.0.0.1", now)
+		require.True(t, ok)
+	}
+	ok, retryAfter := r.allow("10.0.0.1", now.Add(10*time.Second))
+	require.False(t, ok)
+	require.Equal(t, 50*time.Second, retryAfter)
+
+	ok, _ = r.allow("10.0.0.2", now)
+	require.True(t, ok, "limits are per client")
+
+	ok, _ = r.allow("10.0.0.1", now.Add(time.Minute))
+	require.True(t, ok, "window is over")
+
+	r = NewAbuseReports(AbuseReportConfig{})
+	for i := 0; i < 100; i++ {
+		ok, _ = r.allow("10.0.0.1", now)
+		require.True(t, ok)
+	}
+}
+
+func TestReport(t *testing.T) {
+	owner := usertest.ID(t)
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+	reportsID := mem.AddContainer(containertest.Container(t))
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	w, err := mem.ObjectPutInit(context.Background(), hdr, nil, client.PrmObjectPutInit{})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	objID := w.StoredObjectID()
+
+	webhook := make(chan storedReport, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var rep storedReport
+		if err := json.NewDecoder(r.Body).Decode(&rep); err == nil {
+			webhook <- rep
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil)
+
+	report := func(id oid.ID, body string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.SetBodyString(body)
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		c.SetUserValue("oid", id.EncodeToString())
+		u.Report(&c)
+		return &c
+	}
+	const body = `{"reason":"phishing","details":"fake login page"}`
+
+	c := report(objID, body)
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode(), "disabled")
+
+	settings.SetAbuseReports(NewAbuseReports(AbuseReportConfig{
+		Container:      reportsID.EncodeToString(),
+		WebhookURL:     srv.URL,
+		WebhookTimeout: time.Second,
+		RateLimit:      2,
+		RateWindow:     time.Hour,
+	}))
+
+	c = report(objID, body)
+	require.Equal(t, fasthttp.StatusAccepted, c.Response.StatusCode(), string(c.Response.Body()))
+	var resp reportResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+
+	var reportID oid.ID
+	require.NoError(t, reportID.DecodeString(resp.ReportID))
+	var addr oid.Address
+	addr.SetContainer(reportsID)
+	addr.SetObject(reportID)
+	obj, ok := mem.Object(addr)
+	require.True(t, ok)
+	attrs := make(map[string]string)
+	for _, attr := range obj.Attributes() {
+		attrs[attr.Key()] = attr.Value()
+	}
+	require.Equal(t, cnrID.EncodeToString(), attrs[attrReportedContainer])
+	require.Equal(t, objID.EncodeToString(), attrs[attrReportedObject])
+	require.Equal(t, "phishing", attrs[attrReportReason])
+
+	var stored storedReport
+	require.NoError(t, json.Unmarshal(obj.Payload(), &stored))
+	require.Equal(t, "fake login page", stored.Details)
+
+	select {
+	case rep := <-webhook:
+		require.Equal(t, resp.ReportID, rep.ReportID)
+		require.Equal(t, objID.EncodeToString(), rep.ObjectID)
+		require.Equal(t, "phishing", rep.Reason)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook isn't called")
+	}
+
+	c = report(oidtest.ID(), body)
+	require.Equal(t, fasthttp.StatusNotFound, c.Response.StatusCode())
+
+	c = report(objID, body)
+	require.Equal(t, fasthttp.StatusTooManyRequests, c.Response.StatusCode())
+	require.Equal(t, errCodeReportRateLimited, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+	require.NotEmpty(t, c.Response.Header.Peek(fasthttp.HeaderRetryAfter))
+}
+
+func TestReportCaptcha(t *testing.T) {
+	owner := usertest.ID(t)
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(containertest.Container(t))
+
+	settings := &Settings{}
+	settings.SetAbuseReports(NewAbuseReports(AbuseReportConfig{
+		Container: mem.AddContainer(containertest.Container(t)).EncodeToString(),
+		Captcha:   NewCaptchaVerifier(newSiteverify(t, "secret", "good").URL, "secret", time.Second),
+	}))
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil)
+
+	var c fasthttp.RequestCtx
+	c.Request.SetBodyString(`{"reason":"spam"}`)
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	c.SetUserValue("oid", oidtest.ID().EncodeToString())
+	u.Report(&c)
+	require.Equal(t, fasthttp.StatusForbidden, c.Response.StatusCode())
+	require.Equal(t, errCodeCaptchaRequired, string(c.Response.Header.Peek(response.HeaderErrorCode)))
+}
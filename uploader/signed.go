@@ -0,0 +1,343 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// hdrObjectHeader is an upload header with base64-encoded object header
+// signed by the client. The request body is the object payload then, it's
+// streamed to storage nodes as is, so the object is owned and signed by the
+// client rather than by the gate.
+const hdrObjectHeader = "X-Neofs-Object-Header"
+
+// errCodeInvalidObjectHeader is an error code of uploads with malformed or
+// incorrectly signed object header or with payload not matching it.
+const errCodeInvalidObjectHeader = "INVALID_OBJECT_HEADER"
+
+var (
+	// errInvalidObjectHeader is returned if the signed object header can't be
+	// stored.
+	errInvalidObjectHeader = errors.New("invalid object header")
+
+	// errPayloadMismatch is returned if the payload doesn't match the size or
+	// the checksum from the signed object header.
+	errPayloadMismatch = errors.New("payload doesn't match object header")
+)
+
+// signedHeaderConflicts are upload headers changing the object formed by the
+// gate, they can't be applied to the signed header.
+var signedHeaderConflicts = []string{
+	hdrLockUntilEpoch,
+	hdrIfNoneMatchAttribute,
+	hdrOverwriteAttribute,
+	fasthttp.HeaderIfMatch,
+	hdrSlicing,
+	hdrContentSHA256,
+}
+
+// signedHeaderConflict returns the name of the request header which can't be
+// used with the signed object header, empty if there is none.
+func signedHeaderConflict(h *fasthttp.RequestHeader) string {
+	var res string
+	h.VisitAll(func(key, _ []byte) {
+		if res == "" && hasPrefixFold(key, []byte(utils.UserAttributeHeaderPrefix)) {
+			res = string(key)
+		}
+	})
+	if res != "" {
+		return res
+	}
+	for _, name := range signedHeaderConflicts {
+		if len(peekHeader(h, name)) != 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// decodeSignedHeader decodes base64-encoded object header signed by the
+// client.
+func decodeSignedHeader(val []byte) (*object.Object, error) {
+	data, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(val)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode base64: %v", errInvalidObjectHeader, err)
+	}
+
+	var obj object.Object
+	if err = obj.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("%w: decode: %v", errInvalidObjectHeader, err)
+	}
+	if len(obj.Payload()) != 0 {
+		return nil, fmt.Errorf("%w: payload must be sent in the body", errInvalidObjectHeader)
+	}
+	return &obj, nil
+}
+
+// checkSignedHeader checks that the object header signed by the client can
+// be stored in the container with the payload streamed separately. Zero
+// maxSize disables the payload size check.
+func checkSignedHeader(obj *object.Object, cnrID cid.ID, maxSize int64) error {
+	if id, ok := obj.ContainerID(); !ok || !id.Equals(cnrID) {
+		return fmt.Errorf("%w: container doesn't match the request one", errInvalidObjectHeader)
+	}
+	if obj.OwnerID() == nil {
+		return fmt.Errorf("%w: owner is not set", errInvalidObjectHeader)
+	}
+	if cs, ok := obj.PayloadChecksum(); !ok || cs.Type() != checksum.SHA256 {
+		return fmt.Errorf("%w: SHA-256 payload checksum is not set", errInvalidObjectHeader)
+	}
+	if maxSize > 0 && obj.PayloadSize() > uint64(maxSize) {
+		return fmt.Errorf("%w: payload size %d exceeds maximum object size %d, it must be split by the client",
+			errInvalidObjectHeader, obj.PayloadSize(), maxSize)
+	}
+	if err := obj.CheckHeaderVerificationFields(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidObjectHeader, err)
+	}
+	return nil
+}
+
+// signedPayloadReader checks that the payload matches the size and the
+// checksum from the signed object header. Mismatch error is returned instead
+// of io.EOF, so the object is not stored.
+type signedPayloadReader struct {
+	r    io.Reader
+	hash hash.Hash
+	read uint64
+	size uint64
+	sum  []byte
+}
+
+func newSignedPayloadReader(r io.Reader, obj *object.Object) *signedPayloadReader {
+	cs, _ := obj.PayloadChecksum()
+	return &signedPayloadReader{r: r, hash: sha256.New(), size: obj.PayloadSize(), sum: cs.Value()}
+}
+
+func (s *signedPayloadReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.hash.Write(p[:n])
+	s.read += uint64(n)
+	if s.read > s.size {
+		return n, fmt.Errorf("%w: payload exceeds %d bytes", errPayloadMismatch, s.size)
+	}
+	if errors.Is(err, io.EOF) {
+		if s.read != s.size {
+			return n, fmt.Errorf("%w: expected %d bytes, received %d", errPayloadMismatch, s.size, s.read)
+		}
+		if sum := s.hash.Sum(nil); !bytes.Equal(sum, s.sum) {
+			return n, fmt.Errorf("%w: expected checksum %x, calculated %x", errPayloadMismatch, s.sum, sum)
+		}
+	}
+	return n, err
+}
+
+// signedExpiration checks the expiration epoch of the signed object against
+// the expiration policy. The gate can't set the default expiration of the
+// signed object, so the object without expiration epoch is rejected if it must
+// expire. Epoch durations are returned if the expiration epoch is set.
+func (u *Uploader) signedExpiration(ctx context.Context, attributes map[string]string) (*epochDurations, error) {
+	policy := u.settings.expirationPolicy()
+	val, ok := attributes[object.AttributeExpirationEpoch]
+	if !ok {
+		if lifetime := policy.defaultLifetime(); lifetime > 0 {
+			return nil, fmt.Errorf("%w: %s must be set in the signed header, objects expire in %s",
+				errExpirationPolicy, object.AttributeExpirationEpoch, lifetime)
+		}
+		return nil, nil
+	}
+
+	durations, err := u.getEpochDurations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get epoch durations from network info: %w", err)
+	}
+	if err = checkExpirationEpoch(attributes, durations.currentEpoch); err != nil {
+		return nil, err
+	}
+	epoch, _ := strconv.ParseUint(val, 10, 64)
+	if err = policy.check(durations, epoch); err != nil {
+		return nil, err
+	}
+	return durations, nil
+}
+
+// checkSignedFilePath checks that FilePath attribute of the signed object is
+// normalized if normalization is enabled, it can't be changed by the gate.
+func (u *Uploader) checkSignedFilePath(attributes map[string]string) error {
+	val, ok := attributes[object.AttributeFilePath]
+	if !ok || !u.settings.NormalizeFilePath() {
+		return nil
+	}
+	normalized, err := normalizeFilePath(val)
+	if err != nil {
+		return err
+	}
+	if normalized != val {
+		return fmt.Errorf("%w: the path must be normalized to %q in the signed header", errInvalidFilePath, normalized)
+	}
+	return nil
+}
+
+// uploadSigned stores the object signed by the client and writes the
+// response. The object header is taken from X-Neofs-Object-Header header and
+// the body is streamed as its payload or, for raw uploads, the body is the
+// whole object in NeoFS binary format. drain reads the rest of the body.
+func (u *Uploader) uploadSigned(ctx context.Context, c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, body io.Reader, bt *bearer.Token, copies uint32, raw bool, drain func()) {
+	conflict := signedHeaderConflict(&c.Request.Header)
+	if raw && len(peekHeader(&c.Request.Header, hdrObjectHeader)) != 0 {
+		conflict = hdrObjectHeader
+	}
+	if conflict != "" {
+		response.Error(c, conflict+" header can't be used with signed objects", fasthttp.StatusBadRequest)
+		return
+	}
+
+	var (
+		obj     *object.Object
+		payload = body
+		err     error
+	)
+	if raw {
+		obj, payload, err = readRawObject(body)
+	} else {
+		obj, err = decodeSignedHeader(peekHeader(&c.Request.Header, hdrObjectHeader))
+	}
+	if err == nil {
+		err = checkSignedHeader(obj, cnrID, u.chunkSize())
+	}
+	if err == nil && !raw {
+		// body length is checked before streaming if it's known
+		if l := c.Request.Header.ContentLength(); l >= 0 && uint64(l) != obj.PayloadSize() {
+			err = fmt.Errorf("%w: payload size %d doesn't match Content-Length %d", errInvalidObjectHeader, obj.PayloadSize(), l)
+		}
+	}
+	if err != nil {
+		log.Error("could not process object header", zap.Error(err))
+		response.ErrorWithCode(c, errCodeInvalidObjectHeader, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	// the gate policies apply to the signed objects as to the ones formed by
+	// the gate, but the signed header can only be accepted or rejected
+	attributes := make(map[string]string, len(obj.Attributes()))
+	for _, attr := range obj.Attributes() {
+		attributes[attr.Key()] = attr.Value()
+	}
+	if err = u.settings.SystemAttributes().check(attributes); err != nil {
+		log.Error("could not process object header", zap.Error(err))
+		response.ErrorWithCode(c, errCodeSystemAttributeForbidden, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	if err = u.checkSignedFilePath(attributes); err != nil {
+		log.Error("invalid file path", zap.Error(err))
+		response.ErrorWithCode(c, errCodeInvalidFilePath, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	durations, err := u.signedExpiration(ctx, attributes)
+	if err != nil {
+		log.Error("invalid expiration", zap.Error(err))
+		switch {
+		case errors.Is(err, errExpirationPolicy):
+			response.ErrorWithCode(c, errCodeExpirationPolicy, err.Error(), fasthttp.StatusBadRequest)
+		case errors.Is(err, errInvalidSystemAttribute):
+			response.ErrorWithCode(c, errCodeInvalidSystemAttribute, err.Error(), fasthttp.StatusBadRequest)
+		default:
+			response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		}
+		return
+	}
+
+	payload, err = u.settings.ContentBlocklist().check(payload,
+		[]string{attributes[object.AttributeFileName], attributes[object.AttributeFilePath]},
+		[]string{attributes[object.AttributeContentType]},
+	)
+	if err != nil {
+		log.Error("could not upload content", zap.Error(err))
+		if errors.Is(err, errContentBlocked) {
+			drain()
+			response.ErrorWithCode(c, errCodeContentBlocked, err.Error(), fasthttp.StatusUnsupportedMediaType)
+			return
+		}
+		response.Error(c, "could not read payload: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var (
+		addr     oid.Address
+		replaced []oid.ID
+	)
+	// X-If-None-Match-Attribute and X-Overwrite-Attribute are rejected above,
+	// so only FilePath conflict policy can be applied
+	ifNoneMatchKey, overwriteKey := u.conflictAttributes(&c.Request.Header, attributes)
+	if key := ifNoneMatchKey; key != "" {
+		existing, err := u.findByAttribute(ctx, cnrID, key, attributes[key], bt)
+		if err != nil {
+			log.Error("could not search for existing object", zap.Error(err))
+			response.StorageError(c, eacl.OperationSearch, "could not search for existing object", err, fasthttp.StatusBadRequest)
+			return
+		}
+		if existing != nil {
+			addr.SetObject(*existing)
+			addr.SetContainer(cnrID)
+			log.Info("object with the same attribute already exists",
+				zap.String("attribute", key), zap.Stringer("address", addr))
+
+			drain()
+			u.writePutResponse(c, log, newPutResponse(addr, u.objectURL(c, addr)), fasthttp.StatusConflict)
+			return
+		}
+	}
+	if key := overwriteKey; key != "" {
+		if !u.canOverwrite(c, bt) {
+			return
+		}
+		if replaced, err = u.findAllByAttribute(ctx, cnrID, key, attributes[key], bt); err != nil {
+			log.Error("could not search for replaced objects", zap.Error(err))
+			response.StorageError(c, eacl.OperationSearch, "could not search for replaced objects", err, statusFromError(err))
+			return
+		}
+	}
+
+	id, err := u.putPreparedObject(ctx, *obj, newSignedPayloadReader(payload, obj), bt, int64(obj.PayloadSize()), copies)
+	if err != nil {
+		log.Error("put signed object", zap.Error(err))
+		if errors.Is(err, errPayloadMismatch) || errors.Is(err, errInvalidObjectHeader) {
+			response.ErrorWithCode(c, errCodeInvalidObjectHeader, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		putError(c, err)
+		return
+	}
+
+	addr.SetObject(id)
+	addr.SetContainer(cnrID)
+	resp := newPutResponse(addr, u.objectURL(c, addr))
+	if durations != nil {
+		epoch, _ := strconv.ParseUint(attributes[object.AttributeExpirationEpoch], 10, 64)
+		resp.Expiration = newExpirationInfo(durations, epoch, time.Now())
+	}
+	for _, old := range replaced {
+		resp.Replaced = append(resp.Replaced, u.removeReplaced(ctx, log, cnrID, old, bt))
+	}
+
+	drain()
+	u.writePutResponse(c, log, resp, fasthttp.StatusOK)
+}
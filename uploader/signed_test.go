@@ -0,0 +1,311 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// signedHeader returns the object header signed by the signer for the
+// payload, attrs are key-value pairs of extra attributes.
+func signedHeader(t *testing.T, signer user.Signer, cnrID cid.ID, payload []byte, attrs ...string) *object.Object {
+	fileName := object.NewAttribute()
+	fileName.SetKey(object.AttributeFileName)
+	fileName.SetValue("cat.txt")
+	attributes := []object.Attribute{*fileName}
+	for i := 0; i < len(attrs); i += 2 {
+		attr := object.NewAttribute()
+		attr.SetKey(attrs[i])
+		attr.SetValue(attrs[i+1])
+		attributes = append(attributes, *attr)
+	}
+
+	var obj object.Object
+	obj.SetContainerID(cnrID)
+	owner := signer.UserID()
+	obj.SetOwnerID(&owner)
+	obj.SetAttributes(attributes...)
+	obj.SetPayload(payload)
+	obj.SetPayloadSize(uint64(len(payload)))
+	require.NoError(t, obj.SetVerificationFields(signer))
+	obj.SetPayload(nil)
+	return &obj
+}
+
+func marshalHeader(t *testing.T, obj *object.Object) []byte {
+	data, err := obj.Marshal()
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecodeSignedHeader(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSigner(key.PrivateKey)
+	cnrID := cidtest.ID()
+	hdr := signedHeader(t, signer, cnrID, []byte("meow"))
+
+	encode := func(obj *object.Object) []byte {
+		return []byte(base64.StdEncoding.EncodeToString(marshalHeader(t, obj)))
+	}
+
+	obj, err := decodeSignedHeader(encode(hdr))
+	require.NoError(t, err)
+	require.Equal(t, marshalHeader(t, hdr), marshalHeader(t, obj))
+	require.NoError(t, checkSignedHeader(obj, cnrID, 100))
+
+	for name, tc := range map[string]struct {
+		val     []byte
+		cnrID   cid.ID
+		maxSize int64
+	}{
+		"base64":    {val: []byte("!"), cnrID: cnrID},
+		"container": {val: encode(hdr), cnrID: cidtest.ID()},
+		"size":      {val: encode(hdr), cnrID: cnrID, maxSize: 3},
+		"tampered": {val: func() []byte {
+			tampered := hdr.CutPayload()
+			tampered.SetPayloadSize(5)
+			return encode(tampered)
+		}(), cnrID: cnrID},
+		"payload": {val: func() []byte {
+			withPayload := *hdr
+			withPayload.SetPayload([]byte("meow"))
+			return encode(&withPayload)
+		}(), cnrID: cnrID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			obj, err := decodeSignedHeader(tc.val)
+			if err == nil {
+				err = checkSignedHeader(obj, tc.cnrID, tc.maxSize)
+			}
+			require.ErrorIs(t, err, errInvalidObjectHeader)
+		})
+	}
+}
+
+func TestUploadSigned(t *testing.T) {
+	u, mem, cnrID := newConditionalUploader(t)
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSigner(key.PrivateKey)
+
+	upload := func(hdr *object.Object, payload []byte, size int, headers ...string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.Header.DisableNormalizing()
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.Header.Set(hdrObjectHeader, base64.StdEncoding.EncodeToString(marshalHeader(t, hdr)))
+		for i := 0; i < len(headers); i += 2 {
+			c.Request.Header.Set(headers[i], headers[i+1])
+		}
+		c.Request.SetBodyStream(bytes.NewReader(payload), size)
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.Upload(&c)
+		return &c
+	}
+
+	payload := []byte("meow")
+	hdr := signedHeader(t, signer, cnrID, payload)
+
+	t.Run("stored as signed", func(t *testing.T) {
+		id := uploadedID(t, upload(hdr, payload, len(payload)))
+		expected, _ := hdr.ID()
+		require.Equal(t, expected, id)
+
+		obj, ok := mem.Object(newAddress(cnrID, id))
+		require.True(t, ok)
+		require.Equal(t, signer.UserID(), *obj.OwnerID())
+		require.Equal(t, payload, obj.Payload())
+		require.NoError(t, obj.CheckVerificationFields())
+	})
+
+	t.Run("payload mismatch", func(t *testing.T) {
+		for _, body := range [][]byte{[]byte("purr"), []byte("meow!"), []byte("meo")} {
+			c := upload(hdr, body, -1)
+			requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeInvalidObjectHeader)
+		}
+		c := upload(hdr, []byte("meow!"), 5)
+		requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeInvalidObjectHeader)
+	})
+
+	t.Run("conflicting headers", func(t *testing.T) {
+		for _, h := range []string{"X-Attribute-Tag", hdrLockUntilEpoch, hdrOverwriteAttribute} {
+			c := upload(hdr, payload, len(payload), h, "1")
+			require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+			require.Contains(t, string(c.Response.Body()), h)
+		}
+	})
+
+	t.Run("system attributes", func(t *testing.T) {
+		obj := signedHeader(t, signer, cnrID, payload, object.AttributeExpirationEpoch, "100")
+		u.settings.SetSystemAttributes(NewSystemAttributes(nil, nil))
+		defer u.settings.SetSystemAttributes(nil)
+		c := upload(obj, payload, len(payload))
+		requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeSystemAttributeForbidden)
+	})
+
+	t.Run("expiration policy", func(t *testing.T) {
+		setNetworkInfo(mem, 10)
+		u.settings.SetMaxExpirationLifetime(time.Hour)
+		defer u.settings.SetMaxExpirationLifetime(0)
+
+		c := upload(hdr, payload, len(payload))
+		requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeExpirationPolicy)
+
+		obj := signedHeader(t, signer, cnrID, payload, object.AttributeExpirationEpoch, "1000")
+		c = upload(obj, payload, len(payload))
+		requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeExpirationPolicy)
+
+		obj = signedHeader(t, signer, cnrID, payload, object.AttributeExpirationEpoch, "5")
+		c = upload(obj, payload, len(payload))
+		requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeInvalidSystemAttribute)
+
+		obj = signedHeader(t, signer, cnrID, payload, object.AttributeExpirationEpoch, "20")
+		uploadedID(t, upload(obj, payload, len(payload)))
+
+		u.settings.SetMaxExpirationLifetime(0)
+		u.settings.SetDefaultExpirationLifetime(time.Hour)
+		defer u.settings.SetDefaultExpirationLifetime(0)
+		c = upload(hdr, payload, len(payload))
+		requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeExpirationPolicy)
+	})
+
+	t.Run("file path normalization", func(t *testing.T) {
+		u.settings.SetNormalizeFilePath(true)
+		defer u.settings.SetNormalizeFilePath(false)
+
+		for _, val := range []string{"/cats//cat.txt", `cats\cat.txt`, ".."} {
+			obj := signedHeader(t, signer, cnrID, payload, object.AttributeFilePath, val)
+			c := upload(obj, payload, len(payload))
+			requireErrorCode(t, c, fasthttp.StatusBadRequest, errCodeInvalidFilePath)
+		}
+		obj := signedHeader(t, signer, cnrID, payload, object.AttributeFilePath, "cats/cat.txt")
+		uploadedID(t, upload(obj, payload, len(payload)))
+	})
+
+	t.Run("path conflict", func(t *testing.T) {
+		const path = "dogs/dog.txt"
+		first := signedHeader(t, signer, cnrID, []byte("woof"), object.AttributeFilePath, path)
+		firstID := uploadedID(t, upload(first, []byte("woof"), 4))
+
+		u.settings.SetPathConflict(PathConflictReject)
+		defer u.settings.SetPathConflict(PathConflictVersion)
+		second := signedHeader(t, signer, cnrID, []byte("bark"), object.AttributeFilePath, path)
+		c := upload(second, []byte("bark"), 4)
+		require.Equal(t, fasthttp.StatusConflict, c.Response.StatusCode())
+		secondID, _ := second.ID()
+		_, ok := mem.Object(newAddress(cnrID, secondID))
+		require.False(t, ok)
+
+		u.settings.SetPathConflict(PathConflictReplace)
+		requireErrorCode(t, upload(second, []byte("bark"), 4), fasthttp.StatusUnauthorized, errCodeBearerTokenRequired)
+		authKey, authVal := authHeader(t)
+		uploadedID(t, upload(second, []byte("bark"), 4, authKey, authVal))
+		_, ok = mem.Object(newAddress(cnrID, firstID))
+		require.False(t, ok)
+	})
+}
+
+// setNetworkInfo sets the network info with the current epoch and 100 second
+// epochs.
+// sessionBackend records whether objects are put within sessions, pool opens
+// them unless they're explicitly ignored.
+type sessionBackend struct {
+	*backend.Memory
+	withinSession []bool
+}
+
+func (b *sessionBackend) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (backend.ObjectWriter, error) {
+	_, err := prm.GetSession()
+	b.withinSession = append(b.withinSession, !errors.Is(err, client.ErrNoSessionExplicitly))
+	return b.Memory.ObjectPutInit(ctx, hdr, signer, prm)
+}
+
+func TestUploadPreparedWithoutSession(t *testing.T) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+	b := &sessionBackend{Memory: backend.NewMemory()}
+	cnrID := b.AddContainer(cnr)
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: b, Owner: &owner}, settings, nil)
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSigner(key.PrivateKey)
+
+	check := func(c *fasthttp.RequestCtx, hdr *object.Object) {
+		id := uploadedID(t, c)
+		expected, _ := hdr.ID()
+		require.Equal(t, expected, id)
+		require.Equal(t, []bool{false}, b.withinSession)
+		b.withinSession = nil
+
+		obj, ok := b.Object(newAddress(cnrID, id))
+		require.True(t, ok)
+		require.Equal(t, signer.UserID(), *obj.OwnerID())
+		require.Equal(t, hdr.Signature(), obj.Signature())
+		require.NoError(t, obj.CheckVerificationFields())
+	}
+
+	t.Run("signed header", func(t *testing.T) {
+		payload := []byte("meow")
+		hdr := signedHeader(t, signer, cnrID, payload)
+
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.Header.Set(hdrObjectHeader, base64.StdEncoding.EncodeToString(marshalHeader(t, hdr)))
+		c.Request.SetBodyStream(bytes.NewReader(payload), len(payload))
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.Upload(&c)
+		check(&c, hdr)
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		payload := []byte("purr")
+		hdr := signedHeader(t, signer, cnrID, payload)
+		full := *hdr
+		full.SetPayload(payload)
+		data := marshalHeader(t, &full)
+
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		c.Request.SetBodyStream(bytes.NewReader(data), len(data))
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.UploadRaw(&c)
+		check(&c, hdr)
+	})
+
+	t.Run("form", func(t *testing.T) {
+		uploadedID(t, uploadTagged(t, u, cnrID, "cat", "meow"))
+		require.Equal(t, []bool{true}, b.withinSession)
+	})
+}
+
+func setNetworkInfo(mem *backend.Memory, epoch uint64) {
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(epoch)
+	ni.SetEpochDuration(100)
+	ni.SetMsPerBlock(1000)
+	mem.SetNetworkInfo(ni)
+}
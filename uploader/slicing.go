@@ -0,0 +1,314 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+)
+
+// Payload slicing modes.
+const (
+	// SlicingNode streams the payload to a storage node which splits it into
+	// objects within the gate session.
+	SlicingNode = "node"
+	// SlicingGate splits the payload into objects on the gate side, they're
+	// formed and signed by the gate and written to storage nodes one by one.
+	SlicingGate = "gate"
+)
+
+// hdrSlicing is a request header overriding the slicing mode.
+const hdrSlicing = "X-Upload-Slicing"
+
+// slicingMode returns the slicing mode of the upload, the header is taken into
+// account only if it's allowed by settings.
+func (u *Uploader) slicingMode(h *fasthttp.RequestHeader) (string, error) {
+	val := string(h.Peek(hdrSlicing))
+	if val == "" || !u.settings.SlicingHeaderAllowed() {
+		return u.settings.Slicing(), nil
+	}
+	switch val {
+	case SlicingNode, SlicingGate:
+		return val, nil
+	default:
+		return "", fmt.Errorf("invalid %s header value '%s', '%s' or '%s' expected", hdrSlicing, val, SlicingNode, SlicingGate)
+	}
+}
+
+// bufferSize returns the size of the buffer payload is copied to storage nodes
+// with. Known payload size limits it, so small uploads don't allocate large
+// buffers.
+func (u *Uploader) bufferSize(sizeHint int64) int64 {
+	size := u.settings.SlicingBufferSize()
+	if size <= 0 {
+		size = u.chunkSize()
+	}
+	if sizeHint > 0 && sizeHint < size {
+		size = sizeHint
+	}
+	return size
+}
+
+// parallelUpload checks whether the upload of the given length is split into
+// enough objects to write them to storage nodes concurrently.
+func (u *Uploader) parallelUpload(contentLength int64) bool {
+	if u.settings.ParallelWrites() < 2 || contentLength <= 0 {
+		return false
+	}
+	size := u.chunkSize()
+	if size <= 0 {
+		return false
+	}
+	minParts := u.settings.ParallelMinParts()
+	if minParts < 2 {
+		minParts = 2
+	}
+	return (contentLength+size-1)/size >= minParts
+}
+
+// sliceObject splits the payload into objects on the gate side. Objects are
+// limited by the network maximum object size or by the payload size hint if
+// it's smaller, so only the necessary memory is allocated. Homomorphic hashes
+// are calculated unless they're disabled in the network. Objects are owned
+// and signed by the gate, so uploads with bearer tokens can't be sliced by it.
+// If parallel is set, objects are written to storage nodes concurrently as
+// long as the parallel buffer budget allows it, otherwise they're written one
+// by one.
+// Non-zero copies is the number of copies of every object stored before it's
+// accepted.
+func (u *Uploader) sliceObject(ctx context.Context, obj object.Object, r io.Reader, sizeHint int64, copies uint32, parallel bool) (oid.ID, error) {
+	ni, err := u.backend.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("network info: %w", err)
+	}
+
+	var opts slicer.Options
+	opts.SetCurrentNeoFSEpoch(ni.CurrentEpoch())
+	limit := ni.MaxObjectSize()
+	if sizeHint > 0 && uint64(sizeHint) < limit {
+		limit = uint64(sizeHint)
+	}
+	opts.SetObjectPayloadLimit(limit)
+	if !ni.HomomorphicHashingDisabled() {
+		opts.CalculateHomomorphicChecksum()
+	}
+
+	// all the objects of the upload are tracked as a single stream
+	defer u.streams.Open(instrument.StreamPut)()
+
+	ow := backend.ObjectWriterFor(u.backend)
+	if copies != 0 {
+		ow = copiesWriter{ObjectWriter: ow, copies: copies}
+	}
+
+	var writes int64
+	if parallel {
+		// the slicer buffer, the object being formed and the ones being
+		// written are kept in memory, they're taken from the budget shared by
+		// all uploads
+		parts := u.settings.parallelBuffers.reserve(int64(limit), u.settings.ParallelWrites()+2)
+		defer u.settings.parallelBuffers.release(parts * int64(limit))
+		writes = parts - 2
+	}
+
+	if writes < 2 {
+		id, err := slicer.Put(ctx, ow, obj, u.signer, r, opts)
+		if err != nil {
+			return oid.ID{}, fmt.Errorf("slice: %w", err)
+		}
+		return id, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := newParallelWriter(ow, int(writes), cancel)
+	id, err := slicer.Put(ctx, w, obj, u.signer, r, opts)
+	if waitErr := w.wait(); err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("slice: %w", err)
+	}
+	return id, nil
+}
+
+// bufferBudget limits the memory taken by objects of parallel writes of all
+// uploads. Parts are reserved without waiting, so uploads never block each
+// other.
+type bufferBudget struct {
+	mu   sync.Mutex
+	size int64
+	used int64
+}
+
+func (b *bufferBudget) setSize(size int64) {
+	b.mu.Lock()
+	b.size = size
+	b.mu.Unlock()
+}
+
+func (b *bufferBudget) getSize() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// reserve takes up to n parts of the given size from the budget and returns
+// the number of reserved parts.
+func (b *bufferBudget) reserve(part, n int64) int64 {
+	if part <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if free := (b.size - b.used) / part; free < n {
+		n = free
+	}
+	if n <= 0 {
+		return 0
+	}
+	b.used += n * part
+	return n
+}
+
+// release returns the reserved memory to the budget.
+func (b *bufferBudget) release(size int64) {
+	b.mu.Lock()
+	b.used -= size
+	b.mu.Unlock()
+}
+
+// parallelWriter writes objects formed by the slicer to storage nodes
+// concurrently, every write goes to the node chosen by the backend. Objects are
+// buffered until they're complete, the linking object is written after all the
+// children are stored. The first failed write cancels the others.
+type parallelWriter struct {
+	ow     slicer.ObjectWriter
+	sem    chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+func newParallelWriter(ow slicer.ObjectWriter, limit int, cancel context.CancelFunc) *parallelWriter {
+	return &parallelWriter{
+		ow:     ow,
+		sem:    make(chan struct{}, limit),
+		cancel: cancel,
+	}
+}
+
+// ObjectPutInit implements slicer.ObjectWriter.
+func (w *parallelWriter) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	if err := w.firstErr(); err != nil {
+		return nil, err
+	}
+	o := &bufferedObject{ctx: ctx, w: w, signer: signer, prm: prm}
+	// slicer modifies the header after the write to form the next object
+	hdr.CopyTo(&o.hdr)
+	return o, nil
+}
+
+// wait waits for all the started writes and returns the first error.
+func (w *parallelWriter) wait() error {
+	w.wg.Wait()
+	return w.firstErr()
+}
+
+func (w *parallelWriter) firstErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *parallelWriter) fail(err error) {
+	w.mu.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.mu.Unlock()
+	w.cancel()
+}
+
+func (w *parallelWriter) write(o *bufferedObject) error {
+	if len(o.hdr.Children()) > 0 {
+		// linking object refers to children, so they must be stored first
+		if err := w.wait(); err != nil {
+			return err
+		}
+		return o.put(w.ow)
+	}
+
+	select {
+	case w.sem <- struct{}{}:
+	case <-o.ctx.Done():
+		return o.ctx.Err()
+	}
+	if err := w.firstErr(); err != nil {
+		<-w.sem
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer func() {
+			<-w.sem
+			w.wg.Done()
+		}()
+		if err := o.put(w.ow); err != nil {
+			w.fail(err)
+		}
+	}()
+	return nil
+}
+
+// bufferedObject keeps the object formed by the slicer until it's complete.
+type bufferedObject struct {
+	ctx     context.Context
+	w       *parallelWriter
+	hdr     object.Object
+	signer  user.Signer
+	prm     client.PrmObjectPutInit
+	payload []byte
+}
+
+func (o *bufferedObject) Write(p []byte) (int, error) {
+	// slicer reuses its buffer, so the payload is copied
+	o.payload = append(o.payload, p...)
+	return len(p), nil
+}
+
+func (o *bufferedObject) Close() error {
+	return o.w.write(o)
+}
+
+func (o *bufferedObject) GetResult() client.ResObjectPut {
+	return client.ResObjectPut{}
+}
+
+func (o *bufferedObject) put(ow slicer.ObjectWriter) error {
+	stream, err := ow.ObjectPutInit(o.ctx, o.hdr, o.signer, o.prm)
+	if err != nil {
+		return fmt.Errorf("init object stream: %w", err)
+	}
+	if _, err = stream.Write(o.payload); err != nil {
+		_ = stream.Close()
+		return fmt.Errorf("write object payload: %w", err)
+	}
+	if err = stream.Close(); err != nil {
+		return fmt.Errorf("close object stream: %w", err)
+	}
+	return nil
+}
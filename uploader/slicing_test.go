@@ -0,0 +1,214 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSlicingMode(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+
+	var h fasthttp.RequestHeader
+	mode, err := u.slicingMode(&h)
+	require.NoError(t, err)
+	require.Equal(t, SlicingNode, mode)
+
+	// header is ignored unless allowed
+	h.Set(hdrSlicing, SlicingGate)
+	mode, err = u.slicingMode(&h)
+	require.NoError(t, err)
+	require.Equal(t, SlicingNode, mode)
+
+	u.settings.SetSlicingHeaderAllowed(true)
+	mode, err = u.slicingMode(&h)
+	require.NoError(t, err)
+	require.Equal(t, SlicingGate, mode)
+
+	h.Set(hdrSlicing, "client")
+	_, err = u.slicingMode(&h)
+	require.Error(t, err)
+
+	u.settings.SetSlicing(SlicingGate)
+	h.Del(hdrSlicing)
+	mode, err = u.slicingMode(&h)
+	require.NoError(t, err)
+	require.Equal(t, SlicingGate, mode)
+}
+
+func TestBufferSize(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+	u.settings.SetMaxObjectSize(1 << 26)
+
+	// maximum object size is used by default
+	require.EqualValues(t, 1<<26, u.bufferSize(0))
+
+	u.settings.SetSlicingBufferSize(1 << 20)
+	require.EqualValues(t, 1<<20, u.bufferSize(0))
+	require.EqualValues(t, 1<<20, u.bufferSize(-1))
+	require.EqualValues(t, 1<<20, u.bufferSize(1<<30))
+	require.EqualValues(t, 1000, u.bufferSize(1000))
+}
+
+func TestParallelUpload(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+	u.settings.SetMaxObjectSize(100)
+
+	// disabled by default
+	require.False(t, u.parallelUpload(1000))
+
+	u.settings.SetParallelWrites(4)
+	u.settings.SetParallelMinParts(4)
+	require.False(t, u.parallelUpload(0))
+	require.False(t, u.parallelUpload(300))
+	require.True(t, u.parallelUpload(301))
+	require.True(t, u.parallelUpload(1000))
+
+	u.settings.SetParallelWrites(1)
+	require.False(t, u.parallelUpload(1000))
+}
+
+func TestBufferBudget(t *testing.T) {
+	var b bufferBudget
+	require.Zero(t, b.reserve(10, 4))
+
+	b.setSize(55)
+	require.EqualValues(t, 4, b.reserve(10, 4))
+	require.EqualValues(t, 1, b.reserve(10, 4))
+	require.Zero(t, b.reserve(10, 4))
+
+	b.release(40)
+	require.EqualValues(t, 2, b.reserve(20, 4))
+	require.Zero(t, b.reserve(0, 4))
+
+	// shrunk budget doesn't reserve until enough memory is released
+	b.setSize(20)
+	b.release(10)
+	require.Zero(t, b.reserve(10, 1))
+	b.release(40)
+	require.EqualValues(t, 2, b.reserve(10, 4))
+}
+
+// memObjectWriter stores objects in memory, writes take some time, so they
+// overlap if they're done concurrently.
+type memObjectWriter struct {
+	mu       sync.Mutex
+	objects  []object.Object
+	inflight int
+	max      int
+	fail     bool
+}
+
+type memObjectStream struct {
+	w   *memObjectWriter
+	hdr object.Object
+	buf bytes.Buffer
+}
+
+func (w *memObjectWriter) ObjectPutInit(_ context.Context, hdr object.Object, _ user.Signer, _ client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(hdr.Children()) > 0 && w.inflight > 0 {
+		return nil, errors.New("linking object is written before children")
+	}
+	w.inflight++
+	if w.inflight > w.max {
+		w.max = w.inflight
+	}
+	return &memObjectStream{w: w, hdr: hdr}, nil
+}
+
+func (s *memObjectStream) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+func (s *memObjectStream) Close() error {
+	time.Sleep(10 * time.Millisecond)
+
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	s.w.inflight--
+	if s.w.fail {
+		return errors.New("node is down")
+	}
+	s.hdr.SetPayload(s.buf.Bytes())
+	s.w.objects = append(s.w.objects, s.hdr)
+	return nil
+}
+
+func (s *memObjectStream) GetResult() client.ResObjectPut {
+	return client.ResObjectPut{}
+}
+
+func TestParallelWriter(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+
+	owner := signer.UserID()
+	var hdr object.Object
+	hdr.SetContainerID(cidtest.ID())
+	hdr.SetOwnerID(&owner)
+
+	var opts slicer.Options
+	opts.SetObjectPayloadLimit(10)
+
+	payload := bytes.Repeat([]byte("0123456789"), 10)
+
+	t.Run("children are written concurrently", func(t *testing.T) {
+		mem := new(memObjectWriter)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		w := newParallelWriter(mem, 3, cancel)
+		id, err := slicer.Put(ctx, w, hdr, signer, bytes.NewReader(payload), opts)
+		require.NoError(t, err)
+		require.NoError(t, w.wait())
+
+		// 10 children and the linking object
+		require.Len(t, mem.objects, 11)
+		require.Equal(t, 3, mem.max)
+
+		link := mem.objects[len(mem.objects)-1]
+		require.Len(t, link.Children(), 10)
+		parentID, ok := link.Parent().ID()
+		require.True(t, ok)
+		require.Equal(t, id, parentID)
+
+		var stored []byte
+		for _, child := range link.Children() {
+			for _, obj := range mem.objects {
+				if objID, _ := obj.ID(); objID == child {
+					stored = append(stored, obj.Payload()...)
+				}
+			}
+		}
+		require.Equal(t, payload, stored)
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		mem := &memObjectWriter{fail: true}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		w := newParallelWriter(mem, 3, cancel)
+		_, err := slicer.Put(ctx, w, hdr, signer, bytes.NewReader(payload), opts)
+		if err == nil {
+			err = w.wait()
+		}
+		require.Error(t, err)
+		require.Empty(t, mem.objects)
+	})
+}
@@ -0,0 +1,125 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+const (
+	// errCodeSystemAttributeForbidden is an error code of uploads setting
+	// system attributes not allowed by the gate.
+	errCodeSystemAttributeForbidden = "SYSTEM_ATTRIBUTE_FORBIDDEN"
+
+	// errCodeInvalidSystemAttribute is an error code of uploads setting
+	// system attributes with invalid values.
+	errCodeInvalidSystemAttribute = "INVALID_SYSTEM_ATTRIBUTE"
+
+	// anySystemAttribute allows all system attributes not denied explicitly.
+	anySystemAttribute = "*"
+)
+
+var (
+	// errSystemAttributeForbidden is returned if the system attribute isn't
+	// allowed to be set by clients.
+	errSystemAttributeForbidden = errors.New("system attribute is not allowed")
+
+	// errInvalidSystemAttribute is returned if the system attribute value is
+	// invalid.
+	errInvalidSystemAttribute = errors.New("invalid system attribute")
+)
+
+// SystemAttributes is a set of system attributes clients are allowed to set
+// with X-Attribute-Neofs-* headers.
+type SystemAttributes struct {
+	any     bool
+	allowed map[string]struct{}
+	denied  map[string]struct{}
+}
+
+// NewSystemAttributes creates a set of system attributes allowed to be set by
+// clients. Attributes can be given with or without the __NEOFS__ prefix, in
+// header (Expiration-Epoch) or attribute (EXPIRATION_EPOCH) form. '*' in the
+// allowed list allows everything not denied, denied attributes take precedence.
+func NewSystemAttributes(allowed, denied []string) *SystemAttributes {
+	s := &SystemAttributes{
+		allowed: make(map[string]struct{}, len(allowed)),
+		denied:  make(map[string]struct{}, len(denied)),
+	}
+	for _, attr := range allowed {
+		if strings.TrimSpace(attr) == anySystemAttribute {
+			s.any = true
+			continue
+		}
+		if key := systemAttributeKey(attr); key != "" {
+			s.allowed[key] = struct{}{}
+		}
+	}
+	for _, attr := range denied {
+		if key := systemAttributeKey(attr); key != "" {
+			s.denied[key] = struct{}{}
+		}
+	}
+	return s
+}
+
+// systemAttributeKey converts configured attribute name to the system
+// attribute key.
+func systemAttributeKey(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "-", "_")
+	name = strings.TrimPrefix(name, utils.SystemAttributePrefix)
+	if name == "" {
+		return ""
+	}
+	return utils.SystemAttributePrefix + name
+}
+
+// allow checks whether the system attribute key can be set by clients. Nil
+// set allows everything.
+func (s *SystemAttributes) allow(key string) bool {
+	if s == nil {
+		return true
+	}
+	if _, ok := s.denied[key]; ok {
+		return false
+	}
+	if s.any {
+		return true
+	}
+	_, ok := s.allowed[key]
+	return ok
+}
+
+// check checks that all system attributes are allowed.
+func (s *SystemAttributes) check(attributes map[string]string) error {
+	for key := range attributes {
+		if strings.HasPrefix(key, utils.SystemAttributePrefix) && !s.allow(key) {
+			return fmt.Errorf("%w: %s", errSystemAttributeForbidden, key)
+		}
+	}
+	return nil
+}
+
+// checkExpirationEpoch checks that the expiration epoch attribute, if set,
+// is a number not less than the current epoch. Objects are available until
+// the end of the expiration epoch, so the current epoch is accepted.
+func checkExpirationEpoch(attributes map[string]string, currentEpoch uint64) error {
+	val, ok := attributes[object.AttributeExpirationEpoch]
+	if !ok {
+		return nil
+	}
+	epoch, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s must be a number, got %q", errInvalidSystemAttribute, object.AttributeExpirationEpoch, val)
+	}
+	if epoch < currentEpoch {
+		return fmt.Errorf("%w: %s %d is in the past, current epoch is %d",
+			errInvalidSystemAttribute, object.AttributeExpirationEpoch, epoch, currentEpoch)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package uploader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemAttributes(t *testing.T) {
+	attrs := func(keys ...string) map[string]string {
+		res := make(map[string]string, len(keys))
+		for _, k := range keys {
+			res[k] = "1"
+		}
+		return res
+	}
+
+	t.Run("nil allows everything", func(t *testing.T) {
+		var s *SystemAttributes
+		require.NoError(t, s.check(attrs("__NEOFS__TICK_EPOCH")))
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		s := NewSystemAttributes([]string{"EXPIRATION_EPOCH", "__NEOFS__EXPIRATION_DURATION", "expiration-timestamp"}, nil)
+		require.NoError(t, s.check(attrs(object.AttributeExpirationEpoch, utils.ExpirationDurationAttr,
+			utils.ExpirationTimestampAttr, "User-Attribute")))
+		require.ErrorIs(t, s.check(attrs(object.AttributeExpirationEpoch, "__NEOFS__TICK_EPOCH")), errSystemAttributeForbidden)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		s := NewSystemAttributes([]string{"*"}, []string{"Tick-Epoch"})
+		require.NoError(t, s.check(attrs("__NEOFS__TICK_TOPIC")))
+		require.ErrorIs(t, s.check(attrs("__NEOFS__TICK_EPOCH")), errSystemAttributeForbidden)
+
+		s = NewSystemAttributes([]string{"EXPIRATION_EPOCH"}, []string{"EXPIRATION_EPOCH"})
+		require.ErrorIs(t, s.check(attrs(object.AttributeExpirationEpoch)), errSystemAttributeForbidden)
+	})
+
+	t.Run("nothing allowed", func(t *testing.T) {
+		s := NewSystemAttributes(nil, nil)
+		require.NoError(t, s.check(attrs("User-Attribute")))
+		require.ErrorIs(t, s.check(attrs(object.AttributeExpirationEpoch)), errSystemAttributeForbidden)
+	})
+}
+
+func TestCheckExpirationEpoch(t *testing.T) {
+	require.NoError(t, checkExpirationEpoch(map[string]string{}, 10))
+	require.NoError(t, checkExpirationEpoch(map[string]string{object.AttributeExpirationEpoch: "10"}, 10))
+	require.NoError(t, checkExpirationEpoch(map[string]string{object.AttributeExpirationEpoch: "11"}, 10))
+	require.ErrorIs(t, checkExpirationEpoch(map[string]string{object.AttributeExpirationEpoch: "9"}, 10), errInvalidSystemAttribute)
+	require.ErrorIs(t, checkExpirationEpoch(map[string]string{object.AttributeExpirationEpoch: "-1"}, 10), errInvalidSystemAttribute)
+	require.ErrorIs(t, checkExpirationEpoch(map[string]string{object.AttributeExpirationEpoch: "soon"}, 10), errInvalidSystemAttribute)
+}
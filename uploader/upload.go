@@ -1,6 +1,7 @@
 package uploader
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,18 +9,25 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
 	"github.com/nspcc-dev/neofs-http-gw/response"
 	"github.com/nspcc-dev/neofs-http-gw/tokens"
 	"github.com/nspcc-dev/neofs-http-gw/utils"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
@@ -34,23 +42,78 @@ const (
 type Uploader struct {
 	appCtx            context.Context
 	log               *zap.Logger
-	pool              *pool.Pool
+	backend           backend.Backend
 	ownerID           *user.ID
 	settings          *Settings
 	containerResolver resolver.Resolver
 	signer            user.Signer
+	jobs              *jobs.Manager
+	epochs            epochTracker
+	versions          *compat.Versions
+	uploads           *uploadTracker
+	maxObjectSize     atomic.Int64
+	streams           *instrument.Streams
+	metrics           utils.Metrics
+	importClient      *http.Client
 }
 
 type epochDurations struct {
 	currentEpoch  uint64
 	msPerBlock    int64
 	blockPerEpoch uint64
+	// epochOffset is the time passed since the current epoch start, it's
+	// valid only if offsetKnown is set.
+	epochOffset time.Duration
+	offsetKnown bool
+	// roundDown makes expiration epoch calculation round down, so the object
+	// never lives longer than requested.
+	roundDown bool
 }
 
 // Settings stores reloading parameters, so it has to provide atomic getters and setters.
 type Settings struct {
-	defaultTimestamp atomic.Bool
-	maxObjectSize    atomic.Int64
+	defaultTimestamp  atomic.Bool
+	maxObjectSize     atomic.Int64
+	importEnabled     atomic.Bool
+	importConcurrency atomic.Int32
+	importMaxRecords  atomic.Int32
+	importTimeout     atomic.Int64
+	importHosts       atomic.Pointer[utils.HostAllowlist]
+	deleteEnabled     atomic.Bool
+	renameEnabled     atomic.Bool
+	prefixDelete      atomic.Bool
+	prefixConcurrency atomic.Int32
+	prefixMaxObjects  atomic.Int32
+	expirationFloor   atomic.Bool
+	maxClockSkew      atomic.Int64
+	ignoreClockSkew   atomic.Bool
+	minLifetime       atomic.Int64
+	maxLifetime       atomic.Int64
+	defaultLifetime   atomic.Int64
+	blocklist         atomic.Pointer[ContentBlocklist]
+	joinDuplicates    atomic.Bool
+	joinSeparator     atomic.Pointer[string]
+	attributeCase     atomic.Pointer[string]
+	systemAttributes  atomic.Pointer[SystemAttributes]
+	slicing           atomic.Pointer[string]
+	slicingHeader     atomic.Bool
+	slicingBufferSize atomic.Int64
+	slicingSizeHint   atomic.Bool
+	parallelWrites    atomic.Int64
+	parallelMinParts  atomic.Int64
+	parallelBuffers   bufferBudget
+	captcha           atomic.Pointer[CaptchaVerifier]
+	abuseReports      atomic.Pointer[AbuseReports]
+	maxCopiesNumber   atomic.Uint32
+	requestTimeout    atomic.Int64
+	externalURL       atomic.Pointer[string]
+	softDelete        atomic.Bool
+	markerRetention   atomic.Int64
+	markerInterval    atomic.Int64
+	markerContainers  atomic.Pointer[[]string]
+	normalizePath     atomic.Bool
+	pathConflict      atomic.Pointer[string]
+	bearerDefaults    atomic.Pointer[tokens.Defaults]
 }
 
 func (s *Settings) DefaultTimestamp() bool {
@@ -65,22 +128,476 @@ func (s *Settings) SetMaxObjectSize(val int64) {
 	s.maxObjectSize.Store(val)
 }
 
+func (s *Settings) ImportEnabled() bool {
+	return s.importEnabled.Load()
+}
+
+func (s *Settings) SetImportEnabled(val bool) {
+	s.importEnabled.Store(val)
+}
+
+func (s *Settings) ImportConcurrency() int {
+	return int(s.importConcurrency.Load())
+}
+
+func (s *Settings) SetImportConcurrency(val int) {
+	s.importConcurrency.Store(int32(val))
+}
+
+func (s *Settings) ImportMaxRecords() int {
+	return int(s.importMaxRecords.Load())
+}
+
+func (s *Settings) SetImportMaxRecords(val int) {
+	s.importMaxRecords.Store(int32(val))
+}
+
+func (s *Settings) ImportTimeout() time.Duration {
+	return time.Duration(s.importTimeout.Load())
+}
+
+func (s *Settings) SetImportTimeout(val time.Duration) {
+	s.importTimeout.Store(int64(val))
+}
+
+// ImportAllowedHosts returns the allowlist of hosts records are imported
+// from, nil means all hosts are allowed.
+func (s *Settings) ImportAllowedHosts() *utils.HostAllowlist {
+	return s.importHosts.Load()
+}
+
+func (s *Settings) SetImportAllowedHosts(val *utils.HostAllowlist) {
+	s.importHosts.Store(val)
+}
+
+// DeleteEnabled returns true if objects can be removed by DELETE requests
+// and replaced by uploads with X-Overwrite-Attribute header.
+func (s *Settings) DeleteEnabled() bool {
+	return s.deleteEnabled.Load()
+}
+
+func (s *Settings) SetDeleteEnabled(val bool) {
+	s.deleteEnabled.Store(val)
+}
+
+// RenameEnabled returns true if objects can be renamed.
+func (s *Settings) RenameEnabled() bool {
+	return s.renameEnabled.Load()
+}
+
+func (s *Settings) SetRenameEnabled(val bool) {
+	s.renameEnabled.Store(val)
+}
+
+func (s *Settings) DeleteByPrefixEnabled() bool {
+	return s.prefixDelete.Load()
+}
+
+func (s *Settings) SetDeleteByPrefixEnabled(val bool) {
+	s.prefixDelete.Store(val)
+}
+
+func (s *Settings) DeleteByPrefixConcurrency() int {
+	return int(s.prefixConcurrency.Load())
+}
+
+func (s *Settings) SetDeleteByPrefixConcurrency(val int) {
+	s.prefixConcurrency.Store(int32(val))
+}
+
+func (s *Settings) DeleteByPrefixMaxObjects() int {
+	return int(s.prefixMaxObjects.Load())
+}
+
+func (s *Settings) SetDeleteByPrefixMaxObjects(val int) {
+	s.prefixMaxObjects.Store(int32(val))
+}
+
+func (s *Settings) ExpirationFloor() bool {
+	return s.expirationFloor.Load()
+}
+
+func (s *Settings) SetExpirationFloor(val bool) {
+	s.expirationFloor.Store(val)
+}
+
+func (s *Settings) JoinDuplicateAttributes() bool {
+	return s.joinDuplicates.Load()
+}
+
+func (s *Settings) SetJoinDuplicateAttributes(val bool) {
+	s.joinDuplicates.Store(val)
+}
+
+// DuplicateAttributesSeparator returns the separator of joined values of
+// duplicated attribute headers.
+func (s *Settings) DuplicateAttributesSeparator() string {
+	if sep := s.joinSeparator.Load(); sep != nil {
+		return *sep
+	}
+	return ""
+}
+
+func (s *Settings) SetDuplicateAttributesSeparator(val string) {
+	s.joinSeparator.Store(&val)
+}
+
+// AttributeCase returns canonicalization mode of attribute keys.
+func (s *Settings) AttributeCase() string {
+	if mode := s.attributeCase.Load(); mode != nil {
+		return *mode
+	}
+	return utils.AttributeCasePreserve
+}
+
+func (s *Settings) SetAttributeCase(val string) {
+	s.attributeCase.Store(&val)
+}
+
+func (s *Settings) duplicateAttributes() duplicateAttributes {
+	return duplicateAttributes{join: s.JoinDuplicateAttributes(), separator: s.DuplicateAttributesSeparator()}
+}
+
+func (s *Settings) MinExpirationLifetime() time.Duration {
+	return time.Duration(s.minLifetime.Load())
+}
+
+func (s *Settings) SetMinExpirationLifetime(val time.Duration) {
+	s.minLifetime.Store(int64(val))
+}
+
+func (s *Settings) MaxExpirationLifetime() time.Duration {
+	return time.Duration(s.maxLifetime.Load())
+}
+
+func (s *Settings) SetMaxExpirationLifetime(val time.Duration) {
+	s.maxLifetime.Store(int64(val))
+}
+
+// DefaultExpirationLifetime returns the lifetime of objects uploaded without
+// expiration, zero means they don't expire unless the maximum is set.
+func (s *Settings) DefaultExpirationLifetime() time.Duration {
+	return time.Duration(s.defaultLifetime.Load())
+}
+
+func (s *Settings) SetDefaultExpirationLifetime(val time.Duration) {
+	s.defaultLifetime.Store(int64(val))
+}
+
+func (s *Settings) expirationPolicy() expirationPolicy {
+	return expirationPolicy{
+		min: s.MinExpirationLifetime(),
+		max: s.MaxExpirationLifetime(),
+		def: s.DefaultExpirationLifetime(),
+	}
+}
+
+// IgnoreClockSkew returns whether client Date header exceeding the maximum
+// clock skew is ignored instead of rejecting the upload.
+func (s *Settings) IgnoreClockSkew() bool {
+	return s.ignoreClockSkew.Load()
+}
+
+func (s *Settings) SetIgnoreClockSkew(val bool) {
+	s.ignoreClockSkew.Store(val)
+}
+
+func (s *Settings) MaxClockSkew() time.Duration {
+	return time.Duration(s.maxClockSkew.Load())
+}
+
+func (s *Settings) SetMaxClockSkew(val time.Duration) {
+	s.maxClockSkew.Store(int64(val))
+}
+
+// ContentBlocklist returns content types and file extensions not allowed to be
+// uploaded, it's nil if nothing is blocked.
+func (s *Settings) ContentBlocklist() *ContentBlocklist {
+	return s.blocklist.Load()
+}
+
+func (s *Settings) SetContentBlocklist(val *ContentBlocklist) {
+	s.blocklist.Store(val)
+}
+
+// Slicing returns the default payload slicing mode.
+func (s *Settings) Slicing() string {
+	if mode := s.slicing.Load(); mode != nil {
+		return *mode
+	}
+	return SlicingNode
+}
+
+func (s *Settings) SetSlicing(val string) {
+	s.slicing.Store(&val)
+}
+
+// SlicingHeaderAllowed returns whether clients can choose the slicing mode
+// with the request header.
+func (s *Settings) SlicingHeaderAllowed() bool {
+	return s.slicingHeader.Load()
+}
+
+func (s *Settings) SetSlicingHeaderAllowed(val bool) {
+	s.slicingHeader.Store(val)
+}
+
+// SlicingBufferSize returns the size of the buffer payload is streamed to
+// storage nodes with, zero means the maximum object size.
+func (s *Settings) SlicingBufferSize() int64 {
+	return s.slicingBufferSize.Load()
+}
+
+func (s *Settings) SetSlicingBufferSize(val int64) {
+	s.slicingBufferSize.Store(val)
+}
+
+// SlicingSizeHint returns whether Content-Length of the upload is used to
+// limit buffers.
+func (s *Settings) SlicingSizeHint() bool {
+	return s.slicingSizeHint.Load()
+}
+
+func (s *Settings) SetSlicingSizeHint(val bool) {
+	s.slicingSizeHint.Store(val)
+}
+
+// ParallelWrites returns the maximum number of objects of a single upload
+// written to storage nodes concurrently, values less than 2 disable parallel
+// writes.
+func (s *Settings) ParallelWrites() int64 {
+	return s.parallelWrites.Load()
+}
+
+func (s *Settings) SetParallelWrites(val int64) {
+	s.parallelWrites.Store(val)
+}
+
+// ParallelMinParts returns the minimum number of objects the upload is split
+// into to be written in parallel.
+func (s *Settings) ParallelMinParts() int64 {
+	return s.parallelMinParts.Load()
+}
+
+func (s *Settings) SetParallelMinParts(val int64) {
+	s.parallelMinParts.Store(val)
+}
+
+// ParallelBufferSize returns the maximum memory taken by objects of parallel
+// writes of all uploads.
+func (s *Settings) ParallelBufferSize() int64 {
+	return s.parallelBuffers.getSize()
+}
+
+func (s *Settings) SetParallelBufferSize(val int64) {
+	s.parallelBuffers.setSize(val)
+}
+
+// Captcha returns the verifier of challenge tokens required for anonymous
+// uploads, it's nil if they're not required.
+func (s *Settings) Captcha() *CaptchaVerifier {
+	return s.captcha.Load()
+}
+
+func (s *Settings) SetCaptcha(val *CaptchaVerifier) {
+	s.captcha.Store(val)
+}
+
+// BearerDefaults returns the bearer token attached to uploads without their
+// own tokens, it's nil if there is none.
+func (s *Settings) BearerDefaults() *tokens.Defaults {
+	return s.bearerDefaults.Load()
+}
+
+func (s *Settings) SetBearerDefaults(val *tokens.Defaults) {
+	s.bearerDefaults.Store(val)
+}
+
+// AbuseReports returns the abuse reports intake, it's nil if reports are
+// disabled.
+func (s *Settings) AbuseReports() *AbuseReports {
+	return s.abuseReports.Load()
+}
+
+func (s *Settings) SetAbuseReports(val *AbuseReports) {
+	s.abuseReports.Store(val)
+}
+
+// SystemAttributes returns system attributes allowed to be set by clients,
+// it's nil if any system attribute is allowed.
+func (s *Settings) SystemAttributes() *SystemAttributes {
+	return s.systemAttributes.Load()
+}
+
+func (s *Settings) SetSystemAttributes(val *SystemAttributes) {
+	s.systemAttributes.Store(val)
+}
+
+// MaxCopiesNumber returns the maximum copies number of uploaded objects
+// requested by clients, zero means copies number hints are disabled.
+func (s *Settings) MaxCopiesNumber() uint32 {
+	return s.maxCopiesNumber.Load()
+}
+
+func (s *Settings) SetMaxCopiesNumber(val uint32) {
+	s.maxCopiesNumber.Store(val)
+}
+
+// RequestTimeout returns the maximum duration of NeoFS operations made by a
+// request, zero means no limit.
+func (s *Settings) RequestTimeout() time.Duration {
+	return time.Duration(s.requestTimeout.Load())
+}
+
+func (s *Settings) SetRequestTimeout(val time.Duration) {
+	s.requestTimeout.Store(int64(val))
+}
+
+// ExternalURL returns the base URL the gate is reachable at by clients,
+// empty if it's not configured.
+func (s *Settings) ExternalURL() string {
+	if u := s.externalURL.Load(); u != nil {
+		return *u
+	}
+	return ""
+}
+
+func (s *Settings) SetExternalURL(val string) {
+	val = strings.TrimSuffix(val, "/")
+	s.externalURL.Store(&val)
+}
+
+func (s *Settings) SoftDelete() bool {
+	return s.softDelete.Load()
+}
+
+func (s *Settings) SetSoftDelete(val bool) {
+	s.softDelete.Store(val)
+}
+
+// DeleteMarkerRetention returns the period after which delete markers and
+// the versions hidden by them are removed, zero means they're kept forever.
+func (s *Settings) DeleteMarkerRetention() time.Duration {
+	return time.Duration(s.markerRetention.Load())
+}
+
+func (s *Settings) SetDeleteMarkerRetention(val time.Duration) {
+	s.markerRetention.Store(int64(val))
+}
+
+// DeleteMarkerCollectInterval returns the minimal interval between delete
+// markers collections, they search whole containers, so they're run less
+// often than other garbage collection tasks.
+func (s *Settings) DeleteMarkerCollectInterval() time.Duration {
+	return time.Duration(s.markerInterval.Load())
+}
+
+func (s *Settings) SetDeleteMarkerCollectInterval(val time.Duration) {
+	s.markerInterval.Store(int64(val))
+}
+
+// DeleteMarkerContainers returns containers (IDs or names) where outdated
+// delete markers are collected.
+func (s *Settings) DeleteMarkerContainers() []string {
+	if v := s.markerContainers.Load(); v != nil {
+		return *v
+	}
+	return nil
+}
+
+func (s *Settings) SetDeleteMarkerContainers(val []string) {
+	s.markerContainers.Store(&val)
+}
+
+// NormalizeFilePath returns whether FilePath attributes of uploaded objects
+// are normalized.
+func (s *Settings) NormalizeFilePath() bool {
+	return s.normalizePath.Load()
+}
+
+func (s *Settings) SetNormalizeFilePath(val bool) {
+	s.normalizePath.Store(val)
+}
+
+// PathConflict returns the policy of uploads with FilePath of an existing
+// object.
+func (s *Settings) PathConflict() string {
+	if v := s.pathConflict.Load(); v != nil {
+		return *v
+	}
+	return PathConflictVersion
+}
+
+func (s *Settings) SetPathConflict(val string) {
+	s.pathConflict.Store(&val)
+}
+
+// requestContext returns the context of NeoFS operations made by a request
+// handler (see utils.RequestContext). Handlers reading the request body cancel
+// it when the connection fails, e.g. the client disconnects or the server read
+// timeout expires (see abortReader).
+func (u *Uploader) requestContext(c *fasthttp.RequestCtx) (context.Context, context.CancelFunc) {
+	return utils.RequestContext(u.appCtx, c, u.settings.RequestTimeout())
+}
+
 // New creates a new Uploader using specified logger, connection pool and
 // other options.
 func New(ctx context.Context, params *utils.AppParams, settings *Settings, signer user.Signer) *Uploader {
 	return &Uploader{
 		appCtx:            ctx,
 		log:               params.Logger,
-		pool:              params.Pool,
+		backend:           params.Backend,
 		ownerID:           params.Owner,
 		settings:          settings,
 		containerResolver: params.Resolver,
 		signer:            signer,
+		jobs:              params.Jobs,
+		versions:          params.Versions,
+		uploads:           newUploadTracker(uploadProgressRetention, uploadProgressMaxAge, uploadProgressLimit),
+		streams:           params.Streams,
+		metrics:           params.Metrics,
+		importClient:      newImportClient(settings),
+	}
+}
+
+// SetMaxObjectSize sets the maximum object size of the network the uploader
+// puts objects to, it overrides the size from settings shared by uploaders of
+// all networks.
+func (u *Uploader) SetMaxObjectSize(val int64) {
+	u.maxObjectSize.Store(val)
+}
+
+// chunkSize returns the size of payload chunks written to NeoFS, it's the
+// maximum object size of the network.
+func (u *Uploader) chunkSize() int64 {
+	if size := u.maxObjectSize.Load(); size > 0 {
+		return size
 	}
+	return u.settings.maxObjectSize.Load()
+}
+
+// supports checks whether the feature is supported by connected nodes and
+// responds with 501 Not Implemented otherwise.
+func (u *Uploader) supports(c *fasthttp.RequestCtx, log *zap.Logger, f compat.Feature) bool {
+	if err := u.versions.Unsupported(f); err != nil {
+		log.Error("feature is not supported", zap.Error(err))
+		response.ErrorWithCode(c, compat.ErrCodeUnsupported, err.Error(), fasthttp.StatusNotImplemented)
+		return false
+	}
+	return true
 }
 
 // Upload handles multipart upload request.
 func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
+	u.upload(c, false)
+}
+
+// upload handles upload requests, raw ones have the objects in NeoFS binary
+// format in the body (see UploadRaw).
+func (u *Uploader) upload(c *fasthttp.RequestCtx, raw bool) {
+	ctx, cancel := u.requestContext(c)
+	defer cancel()
+
 	var (
 		file       MultipartFile
 		idObj      oid.ID
@@ -89,18 +606,117 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		log        = u.log.With(zap.String("cid", scid))
 		bodyStream = c.RequestBodyStream()
 		drainBuf   = make([]byte, drainBufSize)
+		drained    bool
 	)
 
+	if bodyStream == nil {
+		// the body isn't streamed if it's empty or streaming is disabled
+		bodyStream = bytes.NewReader(c.Request.Body())
+	}
+
+	// NeoFS operations are canceled as soon as the client disconnects, so
+	// storage nodes don't keep the partially written object
+	bodyStream = newAbortReader(bodyStream, int64(c.Request.Header.ContentLength()), func() {
+		log.Info("client aborted upload")
+		if u.metrics != nil {
+			u.metrics.IncUploadAborts()
+		}
+		cancel()
+	})
+
+	// body can be drained only once, subsequent reads of the finished
+	// chunked stream would consume the next pipelined request
+	drain := func() {
+		if !drained {
+			drained = true
+			drainBody(bodyStream, drainBuf)
+		}
+	}
+
 	if err := tokens.StoreBearerToken(c); err != nil {
 		log.Error("could not fetch bearer token", zap.Error(err))
 		response.Error(c, "could not fetch bearer token", fasthttp.StatusBadRequest)
 		return
 	}
 
-	idCnr, err := utils.GetContainerID(u.appCtx, scid, u.containerResolver)
+	idCnr, err := utils.GetContainerID(ctx, scid, u.containerResolver)
+	if err != nil {
+		utils.ContainerIDError(c, log, err)
+		return
+	}
+
+	// reject the upload before receiving the body if it won't be accepted
+	id, bt := u.fetchOwnerAndBearerToken(c)
+	if err = u.checkContainer(*idCnr, bt); err != nil {
+		log.Error("upload rejected", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusForbidden)
+		return
+	}
+
+	if v := u.settings.Captcha(); v != nil && bt == nil {
+		var remoteIP string
+		if ip := clientip.Load(c); ip != nil {
+			remoteIP = ip.String()
+		}
+		if err = v.verify(ctx, &c.Request.Header, remoteIP); err != nil {
+			log.Error("upload rejected", zap.Error(err))
+			if errors.Is(err, errCaptchaRequired) {
+				response.ErrorWithCode(c, errCodeCaptchaRequired, err.Error(), fasthttp.StatusForbidden)
+			} else {
+				response.Error(c, "could not verify captcha: "+err.Error(), fasthttp.StatusServiceUnavailable)
+			}
+			return
+		}
+	}
+
+	slicing, err := u.slicingMode(&c.Request.Header)
 	if err != nil {
-		log.Error("wrong container id", zap.Error(err))
-		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		log.Error("could not process headers", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	copies, err := parseCopiesNumber(string(c.Request.Header.Peek(hdrCopiesNumber)), u.settings.MaxCopiesNumber())
+	if err != nil {
+		log.Error("could not parse copies number", zap.Error(err))
+		if errors.Is(err, errCopiesNumber) {
+			response.ErrorWithCode(c, errCodeCopiesNumber, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if uploadID := string(c.Request.Header.Peek(hdrUploadID)); uploadID != "" {
+		if err := utils.ValidateUploadID(uploadID); err != nil {
+			response.ErrorWithCode(c, err.Code, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		progress, err := u.uploads.start(uploadID, int64(c.Request.Header.ContentLength()))
+		switch {
+		case errors.Is(err, errUploadInProgress):
+			log.Error("could not track upload progress", zap.String("upload_id", uploadID), zap.Error(err))
+			response.Error(c, err.Error(), fasthttp.StatusConflict)
+			return
+		case err != nil:
+			// the upload itself isn't affected, only its progress is unavailable
+			log.Warn("upload progress isn't tracked", zap.String("upload_id", uploadID), zap.Error(err))
+		default:
+			defer func() {
+				var objectID, errMsg string
+				if c.Response.StatusCode() == fasthttp.StatusOK {
+					objectID = idObj.EncodeToString()
+				} else {
+					errMsg = string(c.Response.Body())
+				}
+				progress.finish(objectID, errMsg)
+			}()
+			bodyStream = countingReader{r: bodyStream, progress: progress}
+		}
+	}
+
+	if raw || len(peekHeader(&c.Request.Header, hdrObjectHeader)) != 0 {
+		u.uploadSigned(ctx, c, log, *idCnr, bodyStream, bt, copies, raw, drain)
 		return
 	}
 
@@ -117,42 +733,290 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 			zap.Error(err),
 		)
 	}()
-	boundary := string(c.Request.Header.MultipartFormBoundary())
-	if file, err = fetchMultipartFile(u.log, bodyStream, boundary); err != nil {
+	if c.Request.Header.ContentLength() == 0 {
+		// metadata-only object
+		file = emptyFile{}
+	} else if file, err = fetchMultipartFile(u.log, bodyStream, string(c.Request.Header.MultipartFormBoundary())); err != nil {
 		log.Error("could not receive multipart/form", zap.Error(err))
 		response.Error(c, "could not receive multipart/form: "+err.Error(), fasthttp.StatusBadRequest)
 		return
 	}
-	filtered, err := filterHeaders(u.log, &c.Request.Header)
+	filtered, err := filterHeaders(u.log, &c.Request.Header, u.settings.duplicateAttributes(), u.settings.AttributeCase())
 	if err != nil {
 		log.Error("could not process headers", zap.Error(err))
+		if errors.Is(err, errDuplicateAttribute) {
+			response.ErrorWithCode(c, errCodeDuplicateAttribute, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
 		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
 		return
 	}
-	if needParseExpiration(filtered) {
-		epochDuration, err := getEpochDurations(c, u.pool)
+
+	if err = u.settings.SystemAttributes().check(filtered); err != nil {
+		log.Error("could not process headers", zap.Error(err))
+		response.ErrorWithCode(c, errCodeSystemAttributeForbidden, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = u.normalizeFilePathAttribute(filtered); err != nil {
+		log.Error("could not normalize file path", zap.Error(err))
+		response.ErrorWithCode(c, errCodeInvalidFilePath, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	payload, err := u.settings.ContentBlocklist().check(file,
+		[]string{file.FileName(), filtered[object.AttributeFileName], filtered[object.AttributeFilePath]},
+		[]string{file.ContentType(), filtered[object.AttributeContentType]},
+	)
+	if err != nil {
+		log.Error("could not upload content", zap.String("filename", file.FileName()), zap.Error(err))
+		if errors.Is(err, errContentBlocked) {
+			drain()
+			response.ErrorWithCode(c, errCodeContentBlocked, err.Error(), fasthttp.StatusUnsupportedMediaType)
+			return
+		}
+		response.Error(c, "could not read payload: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var (
+		epochDuration *epochDurations
+		now           = time.Now()
+	)
+	policy := u.settings.expirationPolicy()
+	if needParseExpiration(filtered) || policy.defaultLifetime() > 0 {
+		epochDuration, err = u.getEpochDurations(c)
 		if err != nil {
 			log.Error("could not get epoch durations from network info", zap.Error(err))
 			response.Error(c, "could not get epoch durations from network info: "+err.Error(), fasthttp.StatusBadRequest)
 			return
 		}
 
-		now := time.Now()
 		if rawHeader := c.Request.Header.Peek(fasthttp.HeaderDate); rawHeader != nil {
 			if parsed, err := time.Parse(http.TimeFormat, string(rawHeader)); err != nil {
 				log.Warn("could not parse client time", zap.String("Date header", string(rawHeader)), zap.Error(err))
 			} else {
-				now = parsed
+				err = checkClockSkew(parsed, now, u.settings.MaxClockSkew())
+				switch {
+				case err == nil:
+					now = parsed
+				case u.settings.IgnoreClockSkew():
+					log.Warn("client time is ignored", zap.String("Date header", string(rawHeader)), zap.Error(err))
+					c.Response.Header.Add(fasthttp.HeaderWarning, clockSkewWarning(err))
+				default:
+					log.Error("invalid client time", zap.String("Date header", string(rawHeader)), zap.Error(err))
+					response.Error(c, "invalid client time: "+err.Error(), fasthttp.StatusBadRequest)
+					return
+				}
 			}
 		}
 
-		if err = prepareExpirationHeader(filtered, epochDuration, now); err != nil {
+		if err = prepareExpirationHeader(filtered, epochDuration, now, policy); err != nil {
 			log.Error("could not parse expiration header", zap.Error(err))
+			if errors.Is(err, errExpirationPolicy) {
+				response.ErrorWithCode(c, errCodeExpirationPolicy, err.Error(), fasthttp.StatusBadRequest)
+				return
+			}
 			response.Error(c, "could not parse expiration header: "+err.Error(), fasthttp.StatusBadRequest)
 			return
 		}
+
+		if err = checkExpirationEpoch(filtered, epochDuration.currentEpoch); err != nil {
+			log.Error("invalid expiration epoch", zap.Error(err))
+			response.ErrorWithCode(c, errCodeInvalidSystemAttribute, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+	}
+
+	var lockEpoch uint64
+	if val := c.Request.Header.Peek(hdrLockUntilEpoch); len(val) != 0 {
+		if !u.supports(c, log, compat.FeatureLock) {
+			return
+		}
+		if lockEpoch, err = parseLockEpoch(string(val)); err != nil {
+			log.Error("could not parse lock header", zap.Error(err))
+			response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+	}
+
+	attributes := u.buildAttributes(filtered, file.FileName(), file.ContentType())
+	ifNoneMatchKey, overwriteKey := u.conflictAttributes(&c.Request.Header, filtered)
+
+	if key := ifNoneMatchKey; key != "" {
+		val, ok := attributeValue(attributes, key)
+		if !ok {
+			log.Error("conditional attribute is not set", zap.String("attribute", key))
+			response.Error(c, "attribute "+key+" from "+hdrIfNoneMatchAttribute+" header is not set", fasthttp.StatusBadRequest)
+			return
+		}
+
+		existing, err := u.findByAttribute(ctx, *idCnr, key, val, bt)
+		if err != nil {
+			log.Error("could not search for existing object", zap.Error(err))
+			response.StorageError(c, eacl.OperationSearch, "could not search for existing object", err, fasthttp.StatusBadRequest)
+			return
+		}
+
+		if existing != nil {
+			addr.SetObject(*existing)
+			addr.SetContainer(*idCnr)
+			log.Info("object with the same attribute already exists",
+				zap.String("attribute", key), zap.Stringer("address", addr))
+
+			drain()
+			u.writePutResponse(c, log, newPutResponse(addr, u.objectURL(c, addr)), fasthttp.StatusConflict)
+			return
+		}
+	}
+
+	var replaced []oid.ID
+	ifMatchVal := c.Request.Header.Peek(fasthttp.HeaderIfMatch)
+	if key := overwriteKey; key != "" {
+		if ifNoneMatchKey != "" {
+			response.Error(c, hdrOverwriteAttribute+" and "+hdrIfNoneMatchAttribute+" headers are mutually exclusive", fasthttp.StatusBadRequest)
+			return
+		}
+		if !u.canOverwrite(c, bt) {
+			return
+		}
+		val, ok := attributeValue(attributes, key)
+		if !ok {
+			log.Error("overwrite attribute is not set", zap.String("attribute", key))
+			response.Error(c, "attribute "+key+" from "+hdrOverwriteAttribute+" header is not set", fasthttp.StatusBadRequest)
+			return
+		}
+
+		if replaced, err = u.findAllByAttribute(ctx, *idCnr, key, val, bt); err != nil {
+			log.Error("could not search for replaced objects", zap.Error(err))
+			response.StorageError(c, eacl.OperationSearch, "could not search for replaced objects", err, statusFromError(err))
+			return
+		}
+
+		if len(ifMatchVal) != 0 && !ifMatch(ifMatchVal, replaced) {
+			log.Info("current object doesn't match If-Match header",
+				zap.String("attribute", key), zap.ByteString("if_match", ifMatchVal))
+			drain()
+			response.ErrorWithCode(c, errCodePreconditionFailed, "current object doesn't match If-Match header", fasthttp.StatusPreconditionFailed)
+			return
+		}
+	} else if len(ifMatchVal) != 0 {
+		response.Error(c, "If-Match header requires "+hdrOverwriteAttribute+" header", fasthttp.StatusBadRequest)
+		return
+	}
+
+	var obj object.Object
+	obj.SetContainerID(*idCnr)
+	obj.SetOwnerID(id)
+	obj.SetAttributes(attributes...)
+
+	if expectsContentDigest(&c.Request.Header) {
+		payload = newDigestReader(payload, func(sum []byte) error {
+			// trailers are read with the rest of the body
+			drain()
+			return checkContentDigest(&c.Request.Header, sum)
+		})
+	}
+
+	var sizeHint int64
+	if u.settings.SlicingSizeHint() {
+		// multipart body is larger than the file, so it's an upper bound
+		sizeHint = int64(c.Request.Header.ContentLength())
+	}
+	if slicing == SlicingGate && bt == nil {
+		parallel := u.parallelUpload(int64(c.Request.Header.ContentLength()))
+		idObj, err = u.sliceObject(ctx, obj, payload, sizeHint, copies, parallel)
+	} else {
+		idObj, err = u.putObject(ctx, obj, payload, bt, sizeHint, copies)
+	}
+	if err != nil {
+		log.Error("put object", zap.Error(err))
+		putError(c, err)
+		return
+	}
+
+	addr.SetObject(idObj)
+	addr.SetContainer(*idCnr)
+
+	resp := newPutResponse(addr, u.objectURL(c, addr))
+
+	if lockEpoch != 0 {
+		lockID, err := u.putLock(ctx, *idCnr, id, []oid.ID{idObj}, lockEpoch, bt)
+		if err != nil {
+			log.Error("could not lock object", zap.Stringer("address", addr), zap.Error(err))
+			drain()
+			response.Error(c, fmt.Sprintf("object %s is stored, but could not be locked: %s", addr, err), fasthttp.StatusInternalServerError)
+			return
+		}
+		resp.LockID = lockID.EncodeToString()
+	}
+	if epochDuration != nil {
+		if val, ok := filtered[object.AttributeExpirationEpoch]; ok {
+			if expEpoch, err := strconv.ParseUint(val, 10, 64); err == nil {
+				resp.Expiration = newExpirationInfo(epochDuration, expEpoch, now)
+			}
+		}
 	}
 
+	for _, old := range replaced {
+		resp.Replaced = append(resp.Replaced, u.removeReplaced(ctx, log, *idCnr, old, bt))
+	}
+
+	drain()
+	u.writePutResponse(c, log, resp, fasthttp.StatusOK)
+}
+
+// putError writes the response to the upload failed to store the object.
+func putError(c *fasthttp.RequestCtx, err error) {
+	status := fasthttp.StatusInternalServerError
+	if errors.Is(err, errDigestMismatch) || errors.Is(err, errDigestInvalid) || errors.Is(err, errClientAborted) {
+		status = fasthttp.StatusBadRequest
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		status = fasthttp.StatusGatewayTimeout
+	} else if errors.Is(err, apistatus.ErrContainerNotFound) {
+		status = fasthttp.StatusNotFound
+	} else if errors.Is(err, apistatus.ErrObjectAccessDenied) {
+		response.StorageError(c, eacl.OperationPut, "could not store object", err, status)
+		return
+	}
+	response.Error(c, err.Error(), status)
+}
+
+// writePutResponse writes put response with the given status code.
+func (u *Uploader) writePutResponse(c *fasthttp.RequestCtx, log *zap.Logger, resp *putResponse, code int) {
+	// Try to return the response, otherwise, if something went wrong, throw an error.
+	if err := resp.encode(c); err != nil {
+		log.Error("could not encode response", zap.Error(err))
+		response.Error(c, "could not encode response", fasthttp.StatusBadRequest)
+
+		return
+	}
+	// Report status code and content type.
+	c.Response.SetStatusCode(code)
+	c.Response.Header.SetContentType(jsonHeader)
+}
+
+// drainBody reads the rest of the request body.
+//
+// Multipart is multipart and thus can contain more than one part which
+// we ignore at the moment. Also, when dealing with chunked encoding
+// the last zero-length chunk might be left unread (because multipart
+// reader only cares about its boundary and doesn't look further) and
+// it will be (erroneously) interpreted as the start of the next
+// pipelined header. Thus we need to drain the body buffer.
+func drainBody(bodyStream io.Reader, drainBuf []byte) {
+	for {
+		// any error ends the body, reads of the closed connection fail
+		// forever
+		if _, err := bodyStream.Read(drainBuf); err != nil {
+			break
+		}
+	}
+}
+
+// buildAttributes prepares object attributes from filtered headers adding
+// FileName, Content-Type and Timestamp attributes if they weren't set explicitly.
+func (u *Uploader) buildAttributes(filtered map[string]string, fileName, contentType string) []object.Attribute {
 	attributes := make([]object.Attribute, 0, len(filtered))
 	// prepares attributes from filtered headers
 	for key, val := range filtered {
@@ -161,18 +1025,19 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		attribute.SetValue(val)
 		attributes = append(attributes, *attribute)
 	}
-	// sets FileName attribute if it wasn't set from header
-	if _, ok := filtered[object.AttributeFileName]; !ok {
+	// sets FileName attribute if it wasn't set from header, objects uploaded
+	// without a body have no file name
+	if _, ok := filtered[object.AttributeFileName]; !ok && fileName != "" {
 		filename := object.NewAttribute()
 		filename.SetKey(object.AttributeFileName)
-		filename.SetValue(file.FileName())
+		filename.SetValue(fileName)
 		attributes = append(attributes, *filename)
 	}
 	// sets Content-Type attribute if it wasn't set from header
-	if _, ok := filtered[object.AttributeContentType]; !ok && file.ContentType() != "" {
+	if _, ok := filtered[object.AttributeContentType]; !ok && contentType != "" {
 		cType := object.NewAttribute()
 		cType.SetKey(object.AttributeContentType)
-		cType.SetValue(file.ContentType())
+		cType.SetValue(contentType)
 		attributes = append(attributes, *cType)
 	}
 	// sets Timestamp attribute if it wasn't set from header and enabled by settings
@@ -182,66 +1047,57 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		timestamp.SetValue(strconv.FormatInt(time.Now().Unix(), 10))
 		attributes = append(attributes, *timestamp)
 	}
-	id, bt := u.fetchOwnerAndBearerToken(c)
+	return attributes
+}
 
-	var obj object.Object
-	obj.SetContainerID(*idCnr)
-	obj.SetOwnerID(id)
-	obj.SetAttributes(attributes...)
+// putObject streams the payload read from r to a storage node, object stream
+// is aborted if the payload can't be read completely. sizeHint is the expected
+// payload size used to limit the copy buffer, zero if unknown. Non-zero copies
+// is the number of copies stored before the object is accepted.
+func (u *Uploader) putObject(ctx context.Context, obj object.Object, r io.Reader, bt *bearer.Token, sizeHint int64, copies uint32) (oid.ID, error) {
+	return u.writeObject(ctx, obj, r, putObjectParams(bt, copies), sizeHint)
+}
+
+// putPreparedObject stores the object signed by the client as is. It's put
+// without a session, otherwise the node treats the object as not prepared and
+// makes a new one owned by the session issuer.
+func (u *Uploader) putPreparedObject(ctx context.Context, obj object.Object, r io.Reader, bt *bearer.Token, sizeHint int64, copies uint32) (oid.ID, error) {
+	prm := putObjectParams(bt, copies)
+	prm.IgnoreSession()
+	return u.writeObject(ctx, obj, r, prm, sizeHint)
+}
 
+func putObjectParams(bt *bearer.Token, copies uint32) client.PrmObjectPutInit {
 	var prm client.PrmObjectPutInit
 	if bt != nil {
 		prm.WithBearerToken(*bt)
 	}
+	if copies != 0 {
+		prm.SetCopiesNumber(copies)
+	}
+	return prm
+}
+
+func (u *Uploader) writeObject(ctx context.Context, obj object.Object, r io.Reader, prm client.PrmObjectPutInit, sizeHint int64) (oid.ID, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	writer, err := u.pool.ObjectPutInit(u.appCtx, obj, u.signer, prm)
+	writer, err := u.backend.ObjectPutInit(ctx, obj, u.signer, prm)
 	if err != nil {
-		log.Error("writer init", zap.Error(err))
-		response.Error(c, "writer init: "+err.Error(), fasthttp.StatusInternalServerError)
-		return
+		return oid.ID{}, fmt.Errorf("writer init: %w", err)
 	}
+	defer u.streams.Open(instrument.StreamPut)()
 
-	chunk := make([]byte, u.settings.maxObjectSize.Load())
-	_, err = io.CopyBuffer(writer, file, chunk)
-	if err != nil {
-		log.Error("write", zap.Error(err))
-		response.Error(c, "write: "+err.Error(), fasthttp.StatusInternalServerError)
-		return
+	chunk := make([]byte, u.bufferSize(sizeHint))
+	if _, err = io.CopyBuffer(writer, r, chunk); err != nil {
+		return oid.ID{}, fmt.Errorf("write: %w", err)
 	}
 
 	if err = writer.Close(); err != nil {
-		log.Error("close writer", zap.Error(err))
-		response.Error(c, "close writer: "+err.Error(), fasthttp.StatusInternalServerError)
-		return
+		return oid.ID{}, fmt.Errorf("close writer: %w", err)
 	}
 
-	idObj = writer.GetResult().StoredObjectID()
-
-	addr.SetObject(idObj)
-	addr.SetContainer(*idCnr)
-
-	// Try to return the response, otherwise, if something went wrong, throw an error.
-	if err = newPutResponse(addr).encode(c); err != nil {
-		log.Error("could not encode response", zap.Error(err))
-		response.Error(c, "could not encode response", fasthttp.StatusBadRequest)
-
-		return
-	}
-	// Multipart is multipart and thus can contain more than one part which
-	// we ignore at the moment. Also, when dealing with chunked encoding
-	// the last zero-length chunk might be left unread (because multipart
-	// reader only cares about its boundary and doesn't look further) and
-	// it will be (erroneously) interpreted as the start of the next
-	// pipelined header. Thus we need to drain the body buffer.
-	for {
-		_, err = bodyStream.Read(drainBuf)
-		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
-			break
-		}
-	}
-	// Report status code and content type.
-	c.Response.SetStatusCode(fasthttp.StatusOK)
-	c.Response.Header.SetContentType(jsonHeader)
+	return writer.StoredObjectID(), nil
 }
 
 func (u *Uploader) fetchOwnerAndBearerToken(ctx context.Context) (*user.ID, *bearer.Token) {
@@ -252,26 +1108,69 @@ func (u *Uploader) fetchOwnerAndBearerToken(ctx context.Context) (*user.ID, *bea
 	return u.ownerID, nil
 }
 
+// requireBearerToken responds with 401 Unauthorized if the request has no
+// bearer token. Objects are never removed with the gate key, otherwise anyone
+// reaching the gate could remove everything the gate is allowed to.
+func requireBearerToken(c *fasthttp.RequestCtx, bt *bearer.Token) bool {
+	if bt != nil {
+		return true
+	}
+	response.ErrorWithCode(c, errCodeBearerTokenRequired, "bearer token is required", fasthttp.StatusUnauthorized)
+	return false
+}
+
 type putResponse struct {
-	ObjectID    string `json:"object_id"`
-	ContainerID string `json:"container_id"`
+	ObjectID    string           `json:"object_id"`
+	ContainerID string           `json:"container_id"`
+	URL         string           `json:"url,omitempty"`
+	Expiration  *expirationInfo  `json:"expiration,omitempty"`
+	LockID      string           `json:"lock_id,omitempty"`
+	Replaced    []replacedObject `json:"replaced,omitempty"`
 }
 
-func newPutResponse(addr oid.Address) *putResponse {
+func newPutResponse(addr oid.Address, url string) *putResponse {
 	return &putResponse{
 		ObjectID:    addr.Object().EncodeToString(),
 		ContainerID: addr.Container().EncodeToString(),
+		URL:         url,
 	}
 }
 
+// objectURL returns the download URL of the stored object under the external
+// URL of the gate, empty if it's not configured. The network prefix of the
+// request is kept.
+func (u *Uploader) objectURL(c *fasthttp.RequestCtx, addr oid.Address) string {
+	base := u.settings.ExternalURL()
+	if base == "" {
+		return ""
+	}
+	prefix := utils.RoutePrefix(string(c.Path()), "/upload/")
+	return base + prefix + "/get/" + addr.Container().EncodeToString() + "/" + addr.Object().EncodeToString()
+}
+
 func (pr *putResponse) encode(w io.Writer) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "\t")
 	return enc.Encode(pr)
 }
 
-func getEpochDurations(ctx context.Context, p *pool.Pool) (*epochDurations, error) {
-	networkInfo, err := p.NetworkInfo(ctx, client.PrmNetworkInfo{})
+// writeJSON writes v as JSON response with the given status code.
+func writeJSON(c *fasthttp.RequestCtx, code int, v any) {
+	c.Response.SetStatusCode(code)
+	c.Response.Header.SetContentType(jsonHeader)
+	enc := json.NewEncoder(c)
+	enc.SetIndent("", "\t")
+	_ = enc.Encode(v)
+}
+
+// ObserveEpoch registers the current network epoch, it's used to estimate the
+// time passed since the epoch start for expiration calculations.
+func (u *Uploader) ObserveEpoch(epoch uint64) {
+	u.epochs.observe(epoch, time.Now())
+}
+
+func (u *Uploader) getEpochDurations(ctx context.Context) (*epochDurations, error) {
+	networkInfo, err := u.backend.NetworkInfo(ctx, client.PrmNetworkInfo{})
 	if err != nil {
 		return nil, err
 	}
@@ -280,11 +1179,18 @@ func getEpochDurations(ctx context.Context, p *pool.Pool) (*epochDurations, erro
 		currentEpoch:  networkInfo.CurrentEpoch(),
 		msPerBlock:    networkInfo.MsPerBlock(),
 		blockPerEpoch: networkInfo.EpochDuration(),
+		roundDown:     u.settings.ExpirationFloor(),
 	}
 
 	if res.blockPerEpoch == 0 {
 		return nil, fmt.Errorf("EpochDuration is empty")
 	}
+
+	minOffset, maxOffset, ok := u.epochs.observe(res.currentEpoch, time.Now())
+	res.epochOffset, res.offsetKnown = maxOffset, ok
+	if res.roundDown {
+		res.epochOffset = minOffset
+	}
 	return res, nil
 }
 
@@ -292,5 +1198,6 @@ func needParseExpiration(headers map[string]string) bool {
 	_, ok1 := headers[utils.ExpirationDurationAttr]
 	_, ok2 := headers[utils.ExpirationRFC3339Attr]
 	_, ok3 := headers[utils.ExpirationTimestampAttr]
-	return ok1 || ok2 || ok3
+	_, ok4 := headers[object.AttributeExpirationEpoch]
+	return ok1 || ok2 || ok3 || ok4
 }
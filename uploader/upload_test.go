@@ -0,0 +1,174 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	containertest "github.com/nspcc-dev/neofs-sdk-go/container/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestChunkSize(t *testing.T) {
+	u := &Uploader{settings: &Settings{}}
+	u.settings.SetMaxObjectSize(1 << 21)
+	require.EqualValues(t, 1<<21, u.chunkSize())
+
+	// network size overrides the shared one
+	u.SetMaxObjectSize(1 << 26)
+	require.EqualValues(t, 1<<26, u.chunkSize())
+}
+
+func TestUploadHandler(t *testing.T) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "cat.jpg")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("meow"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	var c fasthttp.RequestCtx
+	c.Request.Header.SetMethod(fasthttp.MethodPost)
+	c.Request.Header.SetContentType(mw.FormDataContentType())
+	c.Request.Header.Set("X-Attribute-Tag", "pet")
+	c.Request.SetBodyStream(bytes.NewReader(body.Bytes()), body.Len())
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	u.Upload(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+	var resp putResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	require.Equal(t, cnrID.EncodeToString(), resp.ContainerID)
+	require.Empty(t, resp.URL)
+
+	var addr oid.Address
+	require.NoError(t, addr.DecodeString(resp.ContainerID+"/"+resp.ObjectID))
+	obj, ok := mem.Object(addr)
+	require.True(t, ok)
+	require.Equal(t, []byte("meow"), obj.Payload())
+	require.Equal(t, owner, *obj.OwnerID())
+
+	attrs := make(map[string]string)
+	for _, attr := range obj.Attributes() {
+		attrs[attr.Key()] = attr.Value()
+	}
+	require.Equal(t, "cat.jpg", attrs[object.AttributeFileName])
+	require.Equal(t, "pet", attrs["Tag"])
+}
+
+func TestUploadExternalURL(t *testing.T) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	settings.SetExternalURL("https://gate.example.com/")
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil)
+
+	var c fasthttp.RequestCtx
+	// requests without body have no body stream
+	c.Request.SetRequestURI("/testnet/upload/" + cnrID.EncodeToString())
+	c.Request.Header.SetMethod(fasthttp.MethodPost)
+	c.SetUserValue("cid", cnrID.EncodeToString())
+	u.Upload(&c)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+
+	var resp putResponse
+	require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+	require.Equal(t, "https://gate.example.com/testnet/get/"+resp.ContainerID+"/"+resp.ObjectID, resp.URL)
+}
+
+func TestUploadEmptyPayload(t *testing.T) {
+	owner := usertest.ID(t)
+	cnr := containertest.Container(t)
+	cnr.SetOwner(owner)
+	cnr.SetBasicACL(acl.PublicRW)
+
+	mem := backend.NewMemory()
+	cnrID := mem.AddContainer(cnr)
+
+	settings := &Settings{}
+	settings.SetMaxObjectSize(1 << 20)
+	u := New(context.Background(), &utils.AppParams{Logger: zap.NewNop(), Backend: mem, Owner: &owner}, settings, nil)
+
+	upload := func(contentType string, body []byte) (*fasthttp.RequestCtx, map[string]string) {
+		var c fasthttp.RequestCtx
+		c.Request.Header.SetMethod(fasthttp.MethodPost)
+		if contentType != "" {
+			c.Request.Header.SetContentType(contentType)
+		}
+		c.Request.Header.Set("X-Attribute-Tag", "pet")
+		c.Request.SetBodyStream(bytes.NewReader(body), len(body))
+		c.SetUserValue("cid", cnrID.EncodeToString())
+		u.Upload(&c)
+		if c.Response.StatusCode() != fasthttp.StatusOK {
+			return &c, nil
+		}
+
+		var resp putResponse
+		require.NoError(t, json.Unmarshal(c.Response.Body(), &resp))
+		var addr oid.Address
+		require.NoError(t, addr.DecodeString(resp.ContainerID+"/"+resp.ObjectID))
+		obj, ok := mem.Object(addr)
+		require.True(t, ok)
+		require.Empty(t, obj.Payload())
+
+		attrs := make(map[string]string)
+		for _, attr := range obj.Attributes() {
+			attrs[attr.Key()] = attr.Value()
+		}
+		return &c, attrs
+	}
+
+	// metadata-only object without a body
+	c, attrs := upload("", nil)
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+	require.Equal(t, map[string]string{"Tag": "pet"}, attrs)
+
+	// empty file
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_, err := mw.CreateFormFile("file", "empty.txt")
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	c, attrs = upload(mw.FormDataContentType(), body.Bytes())
+	require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode(), string(c.Response.Body()))
+	require.Equal(t, "empty.txt", attrs[object.AttributeFileName])
+
+	// form without a file
+	body.Reset()
+	mw = multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("name", "value"))
+	require.NoError(t, mw.Close())
+	c, _ = upload(mw.FormDataContentType(), body.Bytes())
+	require.Equal(t, fasthttp.StatusBadRequest, c.Response.StatusCode())
+	require.Contains(t, string(c.Response.Body()), errNoFile.Error())
+}
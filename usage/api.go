@@ -0,0 +1,45 @@
+package usage
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type usageResponse struct {
+	Issuers []IssuerUsage `json:"issuers"`
+}
+
+// Handler returns the admin API handler exposing the usage: GET /v1/usage
+// responds with usage of all issuers or the one specified in `issuer` query
+// parameter.
+func (t *Tracker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/usage", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if t == nil {
+			http.Error(w, "accounting is disabled", http.StatusNotFound)
+			return
+		}
+
+		resp := usageResponse{Issuers: t.Snapshot()}
+		if issuer := r.URL.Query().Get("issuer"); issuer != "" {
+			filtered := resp.Issuers[:0]
+			for _, u := range resp.Issuers {
+				if u.Issuer == issuer {
+					filtered = append(filtered, u)
+				}
+			}
+			resp.Issuers = filtered
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(resp)
+	})
+	return mux
+}
@@ -0,0 +1,34 @@
+package usage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	tr := NewTracker(0)
+	tr.AddRequest("a")
+	tr.AddRequest("b")
+	tr.AddBytes("b", 3)
+
+	w := httptest.NewRecorder()
+	tr.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/usage?issuer=b", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp usageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []IssuerUsage{{Issuer: "b", Requests: 1, Bytes: 3}}, resp.Issuers)
+
+	w = httptest.NewRecorder()
+	tr.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/usage", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	var disabled *Tracker
+	w = httptest.NewRecorder()
+	disabled.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/usage", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,85 @@
+package usage
+
+import (
+	"crypto/subtle"
+	"net"
+
+	"github.com/nspcc-dev/neofs-http-gw/clientip"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// HeaderAPIKey is a request header with the client API key.
+	HeaderAPIKey = "X-API-Key"
+
+	// ErrCodeInvalidAPIKey is an error code of responses to requests with
+	// unknown API key.
+	ErrCodeInvalidAPIKey = "INVALID_API_KEY"
+)
+
+// Prefixes of the identities of clients without bearer token.
+const (
+	apiKeyPrefix  = "key:"
+	addressPrefix = "ip:"
+)
+
+// Identity returns the identity the request is accounted for: `key:<name>`
+// if the request has a known API key, the bearer token issuer if it's given
+// and `ip:<address>` of the client otherwise, IPv6 clients are identified by
+// their /64 network. Clients without token and key aren't accounted (empty
+// identity is returned) unless anonymous quota is set, so the usage isn't
+// flooded with addresses. The request is responded with false returned if
+// the API key is unknown. Nil Tracker returns empty identity.
+func (t *Tracker) Identity(c *fasthttp.RequestCtx, issuer string) (string, bool) {
+	if t == nil {
+		return "", true
+	}
+
+	if key := c.Request.Header.Peek(HeaderAPIKey); len(key) != 0 {
+		if name, ok := t.apiKeyName(key); ok {
+			return apiKeyPrefix + name, true
+		}
+		response.ErrorWithCode(c, ErrCodeInvalidAPIKey, "invalid API key", fasthttp.StatusUnauthorized)
+		return "", false
+	}
+	if issuer != "" {
+		return issuer, true
+	}
+	if q := t.quotas.Load(); q == nil || q.Anonymous == (Quota{}) {
+		return "", true
+	}
+	return addressPrefix + clientNetwork(clientip.Load(c)), true
+}
+
+// apiKeyName returns the name of the API key, keys are compared in constant
+// time to not leak them.
+func (t *Tracker) apiKeyName(key []byte) (string, bool) {
+	q := t.quotas.Load()
+	if q == nil {
+		return "", false
+	}
+	var (
+		name  string
+		found bool
+	)
+	for k, v := range q.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(k), key) == 1 {
+			name, found = v.Name, true
+		}
+	}
+	return name, found
+}
+
+// clientNetwork returns the address of IPv4 client and /64 network of IPv6
+// one, since IPv6 clients usually own the whole network.
+func clientNetwork(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	network := net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}
+	return network.String()
+}
@@ -0,0 +1,54 @@
+package usage
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestIdentity(t *testing.T) {
+	tr := NewTracker(0)
+	tr.SetQuotas(Quotas{
+		APIKeys: map[string]APIKey{"secret": {Name: "partner", Quota: Quota{Daily: Limits{Requests: 1}}}},
+	})
+
+	var c fasthttp.RequestCtx
+	c.Request.Header.Set(HeaderAPIKey, "secret")
+	id, ok := tr.Identity(&c, "issuer")
+	require.True(t, ok)
+	require.Equal(t, "key:partner", id, "API key takes precedence")
+	require.True(t, tr.quota(id) == Quota{Daily: Limits{Requests: 1}})
+
+	c.Request.Header.Set(HeaderAPIKey, "unknown")
+	_, ok = tr.Identity(&c, "issuer")
+	require.False(t, ok)
+	require.Equal(t, fasthttp.StatusUnauthorized, c.Response.StatusCode())
+
+	c.Request.Header.Del(HeaderAPIKey)
+	id, ok = tr.Identity(&c, "issuer")
+	require.True(t, ok)
+	require.Equal(t, "issuer", id)
+
+	id, ok = tr.Identity(&c, "")
+	require.True(t, ok)
+	require.Empty(t, id, "anonymous clients aren't accounted without quota")
+
+	tr.SetQuotas(Quotas{Anonymous: Quota{Daily: Limits{Bytes: 10}}})
+	id, ok = tr.Identity(&c, "")
+	require.True(t, ok)
+	require.Equal(t, "ip:0.0.0.0", id)
+	require.True(t, tr.quota(id) == Quota{Daily: Limits{Bytes: 10}})
+
+	var nilTracker *Tracker
+	id, ok = nilTracker.Identity(&c, "issuer")
+	require.True(t, ok)
+	require.Empty(t, id)
+}
+
+func TestClientNetwork(t *testing.T) {
+	require.Equal(t, "192.0.2.1", clientNetwork(net.ParseIP("192.0.2.1")))
+	require.Equal(t, "2001:db8:1:2::/64", clientNetwork(net.ParseIP("2001:db8:1:2:3:4:5:6")))
+	require.Empty(t, clientNetwork(nil))
+}
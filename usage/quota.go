@@ -0,0 +1,294 @@
+package usage
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// HeaderQuotaRemaining is a response header with the issuer quota headroom.
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+
+	// ErrCodeQuotaExceeded is an error code of responses to issuers exceeded their quota.
+	ErrCodeQuotaExceeded = "QUOTA_EXCEEDED"
+)
+
+// ErrQuotaExceeded is returned by the streams limited with the byte quota
+// once it's exhausted.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Limits are limits of requests and bytes sent per period, zero means no limit.
+type Limits struct {
+	Requests uint64
+	Bytes    uint64
+}
+
+// Quota is a set of daily and monthly limits.
+type Quota struct {
+	Daily   Limits
+	Monthly Limits
+}
+
+// Quotas are quotas applied to issuers.
+type Quotas struct {
+	// Default is applied to issuers without their own quota.
+	Default Quota
+	// Anonymous is applied to clients without bearer token and API key,
+	// each client IP address (IPv6 /64 network) has its own counters.
+	Anonymous Quota
+	// Issuers are quotas of specific issuers.
+	Issuers map[string]Quota
+	// APIKeys are clients identified by keys in HeaderAPIKey.
+	APIKeys map[string]APIKey
+
+	// keyQuotas are quotas of API keys by their identities.
+	keyQuotas map[string]Quota
+}
+
+// APIKey is a client identified by the key in HeaderAPIKey.
+type APIKey struct {
+	// Name is used in the usage instead of the key, so it's not exposed.
+	Name  string
+	Quota Quota
+}
+
+// Headroom is a quota headroom of the issuer.
+type Headroom struct {
+	// Requests and Bytes are the number of requests and bytes left in the most
+	// restrictive period, they are valid only if the corresponding limit is set.
+	Requests uint64
+	Bytes    uint64
+
+	RequestsLimited bool
+	BytesLimited    bool
+
+	// Reset is a time the exhausted period resets, zero if quota isn't exceeded.
+	Reset time.Time
+}
+
+// Exceeded checks whether the quota is exhausted.
+func (h Headroom) Exceeded() bool {
+	return !h.Reset.IsZero()
+}
+
+// String returns the headroom in the HeaderQuotaRemaining format:
+// `requests=<N>, bytes=<N>` with unlimited values omitted.
+func (h Headroom) String() string {
+	var parts []string
+	if h.RequestsLimited {
+		parts = append(parts, "requests="+strconv.FormatUint(h.Requests, 10))
+	}
+	if h.BytesLimited {
+		parts = append(parts, "bytes="+strconv.FormatUint(h.Bytes, 10))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type period struct {
+	start    time.Time
+	requests uint64
+	bytes    uint64
+}
+
+// current returns the period counters reset if the period has changed.
+func (p *period) current(start time.Time) *period {
+	if !p.start.Equal(start) {
+		*p = period{start: start}
+	}
+	return p
+}
+
+func dayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func monthStart(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// SetQuotas sets quotas applied to issuers.
+func (t *Tracker) SetQuotas(q Quotas) {
+	if t == nil {
+		return
+	}
+	q.keyQuotas = make(map[string]Quota, len(q.APIKeys))
+	for _, k := range q.APIKeys {
+		q.keyQuotas[apiKeyPrefix+k.Name] = k.Quota
+	}
+	t.quotas.Store(&q)
+}
+
+func (t *Tracker) quota(identity string) Quota {
+	q := t.quotas.Load()
+	if q == nil {
+		return Quota{}
+	}
+	switch {
+	case strings.HasPrefix(identity, apiKeyPrefix):
+		return q.keyQuotas[identity]
+	case strings.HasPrefix(identity, addressPrefix):
+		return q.Anonymous
+	}
+	if iq, ok := q.Issuers[identity]; ok {
+		return iq
+	}
+	return q.Default
+}
+
+// addPeriods accounts requests and bytes in the daily and monthly periods.
+func (t *Tracker) addPeriods(c *counters, requests, bytes uint64) {
+	now := t.now().UTC()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, p := range []*period{c.day.current(dayStart(now)), c.month.current(monthStart(now))} {
+		p.requests += requests
+		p.bytes += bytes
+	}
+}
+
+// Headroom returns the quota headroom of the issuer. Issuers exceeding
+// the accounted issuers limit share the quota of [OtherIssuer].
+func (t *Tracker) Headroom(issuer string) Headroom {
+	var res Headroom
+	if t == nil {
+		return res
+	}
+
+	q := t.quota(issuer)
+	if q == (Quota{}) {
+		return res
+	}
+
+	now := t.now().UTC()
+	day, month := dayStart(now), monthStart(now)
+
+	c := t.counters(issuer)
+	c.mu.Lock()
+	daily, monthly := *c.day.current(day), *c.month.current(month)
+	c.mu.Unlock()
+
+	for _, p := range []struct {
+		limits Limits
+		used   period
+		reset  time.Time
+	}{
+		{limits: q.Daily, used: daily, reset: day.AddDate(0, 0, 1)},
+		{limits: q.Monthly, used: monthly, reset: month.AddDate(0, 1, 0)},
+	} {
+		var exceeded bool
+		if p.limits.Requests > 0 {
+			left := remaining(p.limits.Requests, p.used.requests)
+			if !res.RequestsLimited || left < res.Requests {
+				res.Requests = left
+			}
+			res.RequestsLimited = true
+			exceeded = left == 0
+		}
+		if p.limits.Bytes > 0 {
+			left := remaining(p.limits.Bytes, p.used.bytes)
+			if !res.BytesLimited || left < res.Bytes {
+				res.Bytes = left
+			}
+			res.BytesLimited = true
+			exceeded = exceeded || left == 0
+		}
+		if exceeded && p.reset.After(res.Reset) {
+			res.Reset = p.reset
+		}
+	}
+
+	return res
+}
+
+// bytesExhausted checks whether the byte quota of the identity is exhausted.
+func (t *Tracker) bytesExhausted(identity string) bool {
+	h := t.Headroom(identity)
+	return h.BytesLimited && h.Bytes == 0
+}
+
+func remaining(limit, used uint64) uint64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// Enforce sets the quota headroom header and responds with 429 Too Many
+// Requests if the issuer exceeded its quota. Returns false if the request
+// must not be served. Byte quota is checked only before the request is served,
+// so the response payload must be streamed through LimitReader or
+// LimitWriter to be cut when the quota runs out.
+func (t *Tracker) Enforce(c *fasthttp.RequestCtx, issuer string) bool {
+	h := t.Headroom(issuer)
+	if !h.Exceeded() {
+		if s := h.String(); s != "" {
+			c.Response.Header.Set(HeaderQuotaRemaining, s)
+		}
+		return true
+	}
+
+	response.ErrorWithCode(c, ErrCodeQuotaExceeded, "quota exceeded", fasthttp.StatusTooManyRequests)
+	c.Response.Header.Set(HeaderQuotaRemaining, h.String())
+	retryAfter := int(h.Reset.Sub(t.now()).Seconds()) + 1
+	c.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return false
+}
+
+// LimitReader returns the reader accounting bytes read from r for the
+// identity. It fails with ErrQuotaExceeded once the byte quota of the identity
+// is exhausted, so the downloads started within the quota don't exceed it
+// much. Nil Tracker returns r as is.
+func (t *Tracker) LimitReader(identity string, r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &quotaReader{r: r, t: t, identity: identity}
+}
+
+// LimitWriter is the same as LimitReader, but accounts bytes written to w.
+func (t *Tracker) LimitWriter(identity string, w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	return &quotaWriter{w: w, t: t, identity: identity}
+}
+
+type quotaReader struct {
+	r        io.Reader
+	t        *Tracker
+	identity string
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	q.t.AddBytes(q.identity, n)
+	if err == nil && q.t.bytesExhausted(q.identity) {
+		err = ErrQuotaExceeded
+	}
+	return n, err
+}
+
+type quotaWriter struct {
+	w        io.Writer
+	t        *Tracker
+	identity string
+}
+
+func (q *quotaWriter) Write(p []byte) (int, error) {
+	n, err := q.w.Write(p)
+	q.t.AddBytes(q.identity, n)
+	if err == nil && q.t.bytesExhausted(q.identity) {
+		err = ErrQuotaExceeded
+	}
+	return n, err
+}
@@ -0,0 +1,120 @@
+// Package usage accounts downloads per bearer token issuer.
+package usage
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OtherIssuer is a name used to account issuers exceeding the limit.
+const OtherIssuer = "other"
+
+// DefaultMaxIssuers is a default limit of separately accounted issuers.
+const DefaultMaxIssuers = 10000
+
+type counters struct {
+	requests atomic.Uint64
+	bytes    atomic.Uint64
+
+	mu         sync.Mutex
+	day, month period
+}
+
+// Tracker accounts requests and bytes served per bearer token issuer. Nil
+// Tracker accounts nothing.
+type Tracker struct {
+	maxIssuers int
+	quotas     atomic.Pointer[Quotas]
+	now        func() time.Time
+
+	mu      sync.RWMutex
+	issuers map[string]*counters
+}
+
+// IssuerUsage is a usage snapshot of the issuer.
+type IssuerUsage struct {
+	Issuer   string `json:"issuer"`
+	Requests uint64 `json:"requests"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// NewTracker creates a Tracker. Issuers exceeding maxIssuers limit are
+// accounted as [OtherIssuer].
+func NewTracker(maxIssuers int) *Tracker {
+	if maxIssuers <= 0 {
+		maxIssuers = DefaultMaxIssuers
+	}
+	return &Tracker{
+		maxIssuers: maxIssuers,
+		now:        time.Now,
+		issuers:    make(map[string]*counters),
+	}
+}
+
+func (t *Tracker) counters(issuer string) *counters {
+	t.mu.RLock()
+	c, ok := t.issuers[issuer]
+	t.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok = t.issuers[issuer]; ok {
+		return c
+	}
+	if len(t.issuers) >= t.maxIssuers {
+		issuer = OtherIssuer
+		if c, ok = t.issuers[issuer]; ok {
+			return c
+		}
+	}
+	c = new(counters)
+	t.issuers[issuer] = c
+	return c
+}
+
+// AddRequest accounts the request served for the issuer.
+func (t *Tracker) AddRequest(issuer string) {
+	if t == nil {
+		return
+	}
+	c := t.counters(issuer)
+	c.requests.Add(1)
+	t.addPeriods(c, 1, 0)
+}
+
+// AddBytes accounts bytes sent for the issuer.
+func (t *Tracker) AddBytes(issuer string, n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+	c := t.counters(issuer)
+	c.bytes.Add(uint64(n))
+	t.addPeriods(c, 0, uint64(n))
+}
+
+// Snapshot returns usage of all issuers sorted by issuer.
+func (t *Tracker) Snapshot() []IssuerUsage {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	res := make([]IssuerUsage, 0, len(t.issuers))
+	for issuer, c := range t.issuers {
+		res = append(res, IssuerUsage{
+			Issuer:   issuer,
+			Requests: c.requests.Load(),
+			Bytes:    c.bytes.Load(),
+		})
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Issuer < res[j].Issuer })
+	return res
+}
@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker(2)
+
+	tr.AddRequest("b")
+	tr.AddBytes("b", 10)
+	tr.AddRequest("a")
+	tr.AddBytes("a", 5)
+	tr.AddBytes("a", 0)
+	tr.AddRequest("c")
+	tr.AddBytes("d", 7)
+
+	require.Equal(t, []IssuerUsage{
+		{Issuer: "a", Requests: 1, Bytes: 5},
+		{Issuer: "b", Requests: 1, Bytes: 10},
+		{Issuer: OtherIssuer, Requests: 1, Bytes: 7},
+	}, tr.Snapshot())
+
+	var nilTracker *Tracker
+	nilTracker.AddRequest("a")
+	nilTracker.AddBytes("a", 1)
+	require.Nil(t, nilTracker.Snapshot())
+}
+
+func TestQuota(t *testing.T) {
+	now := time.Date(2022, time.March, 30, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(0)
+	tr.now = func() time.Time { return now }
+	tr.SetQuotas(Quotas{
+		Default: Quota{Daily: Limits{Requests: 2}, Monthly: Limits{Bytes: 100}},
+		Issuers: map[string]Quota{"vip": {}},
+	})
+
+	h := tr.Headroom("a")
+	require.False(t, h.Exceeded())
+	require.Equal(t, "requests=2, bytes=100", h.String())
+
+	tr.AddRequest("a")
+	tr.AddBytes("a", 30)
+	require.Equal(t, "requests=1, bytes=70", tr.Headroom("a").String())
+
+	tr.AddRequest("a")
+	h = tr.Headroom("a")
+	require.True(t, h.Exceeded())
+	require.Equal(t, time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC), h.Reset)
+
+	var c fasthttp.RequestCtx
+	require.False(t, tr.Enforce(&c, "a"))
+	require.Equal(t, fasthttp.StatusTooManyRequests, c.Response.StatusCode())
+	require.Equal(t, "requests=0, bytes=70", string(c.Response.Header.Peek(HeaderQuotaRemaining)))
+	require.Equal(t, "43201", string(c.Response.Header.Peek(fasthttp.HeaderRetryAfter)))
+
+	t.Run("unlimited issuer", func(t *testing.T) {
+		tr.AddRequest("vip")
+		tr.AddRequest("vip")
+		tr.AddRequest("vip")
+
+		var c fasthttp.RequestCtx
+		require.True(t, tr.Enforce(&c, "vip"))
+		require.Empty(t, c.Response.Header.Peek(HeaderQuotaRemaining))
+	})
+
+	t.Run("next day", func(t *testing.T) {
+		now = now.Add(24 * time.Hour)
+		tr.AddBytes("a", 80)
+
+		h := tr.Headroom("a")
+		require.True(t, h.Exceeded())
+		require.Equal(t, "requests=2, bytes=0", h.String())
+		require.Equal(t, time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC), h.Reset)
+	})
+
+	t.Run("limited streams", func(t *testing.T) {
+		tr.SetQuotas(Quotas{Issuers: map[string]Quota{"b": {Daily: Limits{Bytes: 5}}}})
+
+		data, err := io.ReadAll(tr.LimitReader("b", strings.NewReader("0123456789")))
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		require.Equal(t, "0123456789", string(data), "the read started within the quota is completed")
+		_, err = io.ReadAll(tr.LimitReader("b", strings.NewReader("0")))
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+
+		var buf bytes.Buffer
+		w := tr.LimitWriter("c", &buf)
+		_, err = w.Write([]byte("unlimited"))
+		require.NoError(t, err)
+
+		tr.SetQuotas(Quotas{Default: Quota{Daily: Limits{Requests: 2}, Monthly: Limits{Bytes: 100}}})
+	})
+
+	t.Run("next month", func(t *testing.T) {
+		now = now.AddDate(0, 1, 0)
+
+		var c fasthttp.RequestCtx
+		require.True(t, tr.Enforce(&c, "a"))
+		require.Equal(t, "requests=2, bytes=100", string(c.Response.Header.Peek(HeaderQuotaRemaining)))
+	})
+}
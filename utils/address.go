@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// AddressScheme is a URI scheme of NeoFS object addresses.
+const AddressScheme = "neofs://"
+
+// hexObjectIDLen is a length of hex-encoded object ID, it can't be confused
+// with base58 encoding which is 43-44 symbols long for 32-byte IDs.
+const hexObjectIDLen = 2 * 32
+
+// ErrInvalidAddress is returned when object address string can't be split
+// into container and object parts.
+var ErrInvalidAddress = errors.New("invalid object address")
+
+// SplitAddress splits single string object address into container and object
+// parts. Supported formats are `<cid>:<oid>`, `<cid>/<oid>` and
+// `neofs://<cid>/<oid>`. Container part isn't decoded since it can be a
+// container name.
+func SplitAddress(addr string) (string, string, error) {
+	if len(addr) >= len(AddressScheme) && strings.EqualFold(addr[:len(AddressScheme)], AddressScheme) {
+		addr = addr[len(AddressScheme):]
+	}
+
+	i := strings.IndexAny(addr, ":/")
+	if i <= 0 || i == len(addr)-1 {
+		return "", "", ErrInvalidAddress
+	}
+
+	cnr, obj := addr[:i], addr[i+1:]
+	if strings.ContainsAny(obj, ":/") {
+		return "", "", ErrInvalidAddress
+	}
+
+	return cnr, obj, nil
+}
+
+// DecodeObjectID decodes object ID from base58 or hex string.
+func DecodeObjectID(s string) (oid.ID, error) {
+	var id oid.ID
+
+	if len(s) == hexObjectIDLen {
+		if data, err := hex.DecodeString(s); err == nil {
+			return id, id.Decode(data)
+		}
+	}
+
+	return id, id.DecodeString(s)
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAddress(t *testing.T) {
+	for _, tc := range []struct {
+		addr string
+		cnr  string
+		obj  string
+		err  bool
+	}{
+		{addr: "cid:oid", cnr: "cid", obj: "oid"},
+		{addr: "cid/oid", cnr: "cid", obj: "oid"},
+		{addr: "neofs://cid/oid", cnr: "cid", obj: "oid"},
+		{addr: "NEOFS://cid/oid", cnr: "cid", obj: "oid"},
+		{addr: "name.with-dots:oid", cnr: "name.with-dots", obj: "oid"},
+		{addr: "cid", err: true},
+		{addr: "cid:", err: true},
+		{addr: ":oid", err: true},
+		{addr: "cid/oid/extra", err: true},
+		{addr: "neofs://", err: true},
+	} {
+		t.Run(tc.addr, func(t *testing.T) {
+			cnr, obj, err := SplitAddress(tc.addr)
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidAddress)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.cnr, cnr)
+			require.Equal(t, tc.obj, obj)
+		})
+	}
+}
+
+func TestDecodeObjectID(t *testing.T) {
+	id := oidtest.ID()
+
+	res, err := DecodeObjectID(id.EncodeToString())
+	require.NoError(t, err)
+	require.Equal(t, id, res)
+
+	raw := make([]byte, 32)
+	id.Encode(raw)
+
+	res, err = DecodeObjectID(hex.EncodeToString(raw))
+	require.NoError(t, err)
+	require.Equal(t, id, res)
+
+	_, err = DecodeObjectID("invalid")
+	require.Error(t, err)
+}
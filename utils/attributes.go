@@ -1,5 +1,12 @@
 package utils
 
+import (
+	"net/textproto"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
 const (
 	UserAttributeHeaderPrefix = "X-Attribute-"
 	SystemAttributePrefix     = "__NEOFS__"
@@ -8,3 +15,43 @@ const (
 	ExpirationTimestampAttr = SystemAttributePrefix + "EXPIRATION_TIMESTAMP"
 	ExpirationRFC3339Attr   = SystemAttributePrefix + "EXPIRATION_RFC3339"
 )
+
+// Attribute key canonicalization modes.
+const (
+	// AttributeCasePreserve keeps attribute keys as they are.
+	AttributeCasePreserve = "preserve"
+	// AttributeCaseKnown replaces keys of well-known attributes matched
+	// case-insensitively (e.g. 'filename') with their canonical form
+	// ('FileName'), other keys are kept.
+	AttributeCaseKnown = "known"
+	// AttributeCaseCanonical replaces keys of well-known attributes like
+	// AttributeCaseKnown and converts other keys to the canonical MIME header
+	// form ('my-tag' becomes 'My-Tag').
+	AttributeCaseCanonical = "canonical"
+)
+
+// knownAttributes maps lower-cased keys of well-known attributes to their
+// canonical form.
+var knownAttributes = map[string]string{
+	strings.ToLower(object.AttributeName):        object.AttributeName,
+	strings.ToLower(object.AttributeFileName):    object.AttributeFileName,
+	strings.ToLower(object.AttributeFilePath):    object.AttributeFilePath,
+	strings.ToLower(object.AttributeTimestamp):   object.AttributeTimestamp,
+	strings.ToLower(object.AttributeContentType): object.AttributeContentType,
+}
+
+// CanonicalAttributeKey converts the user attribute key according to the
+// canonicalization mode, unknown modes preserve the key. System attributes
+// are never changed.
+func CanonicalAttributeKey(key, mode string) string {
+	if mode != AttributeCaseKnown && mode != AttributeCaseCanonical || strings.HasPrefix(key, SystemAttributePrefix) {
+		return key
+	}
+	if known, ok := knownAttributes[strings.ToLower(key)]; ok {
+		return known
+	}
+	if mode == AttributeCaseCanonical {
+		return textproto.CanonicalMIMEHeaderKey(key)
+	}
+	return key
+}
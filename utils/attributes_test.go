@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalAttributeKey(t *testing.T) {
+	for _, tc := range []struct {
+		key, mode, expected string
+	}{
+		{key: "filename", mode: AttributeCasePreserve, expected: "filename"},
+		{key: "filename", mode: "unknown", expected: "filename"},
+		{key: "filename", mode: AttributeCaseKnown, expected: "FileName"},
+		{key: "CONTENT-TYPE", mode: AttributeCaseKnown, expected: "Content-Type"},
+		{key: "my-tag", mode: AttributeCaseKnown, expected: "my-tag"},
+		{key: "filepath", mode: AttributeCaseCanonical, expected: "FilePath"},
+		{key: "my-tag", mode: AttributeCaseCanonical, expected: "My-Tag"},
+		{key: "MyAttribute", mode: AttributeCaseCanonical, expected: "Myattribute"},
+		{key: "__NEOFS__EXPIRATION_EPOCH", mode: AttributeCaseCanonical, expected: "__NEOFS__EXPIRATION_EPOCH"},
+	} {
+		require.Equal(t, tc.expected, CanonicalAttributeKey(tc.key, tc.mode), tc.key+" "+tc.mode)
+	}
+}
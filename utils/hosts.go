@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HostAllowlist contains allowed host names, e.g. hosts the gate serves to
+// prevent host header injection and poisoning of shared caches.
+type HostAllowlist struct {
+	exact    map[string]struct{}
+	suffixes []string
+}
+
+// NewHostAllowlist creates allowlist of host patterns, pattern is either a
+// host name or `*.` followed by a domain to allow all its subdomains.
+func NewHostAllowlist(patterns []string) (*HostAllowlist, error) {
+	l := &HostAllowlist{exact: make(map[string]struct{}, len(patterns))}
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		domain := strings.TrimPrefix(p, "*.")
+		wildcard := len(domain) < len(p)
+		if domain == "" || strings.ContainsAny(domain, "*/: ") {
+			return nil, fmt.Errorf("invalid host pattern %q", p)
+		}
+		if wildcard {
+			l.suffixes = append(l.suffixes, "."+domain)
+		} else {
+			l.exact[domain] = struct{}{}
+		}
+	}
+	return l, nil
+}
+
+// Allowed checks whether the lower-cased host name matches any pattern of the
+// allowlist.
+func (l *HostAllowlist) Allowed(host string) bool {
+	if _, ok := l.exact[host]; ok {
+		return true
+	}
+	for _, suffix := range l.suffixes {
+		if len(host) > len(suffix) && strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostAllowlist(t *testing.T) {
+	l, err := NewHostAllowlist([]string{"Gate.example.com", "*.cdn.example.com"})
+	require.NoError(t, err)
+
+	for host, allowed := range map[string]bool{
+		"gate.example.com":      true,
+		"a.cdn.example.com":     true,
+		"a.b.cdn.example.com":   true,
+		"cdn.example.com":       false,
+		"evilcdn.example.com":   false,
+		"example.com":           false,
+		"gate.example.com.evil": false,
+		"":                      false,
+	} {
+		require.Equal(t, allowed, l.Allowed(host), host)
+	}
+
+	for _, p := range []string{"", "*.", "*", "gate.*.com", "gate.example.com:8080"} {
+		_, err = NewHostAllowlist([]string{p})
+		require.Error(t, err, p)
+	}
+}
@@ -1,15 +1,42 @@
 package utils
 
 import (
+	"github.com/nspcc-dev/neofs-http-gw/attrcache"
+	"github.com/nspcc-dev/neofs-http-gw/backend"
+	"github.com/nspcc-dev/neofs-http-gw/compat"
+	"github.com/nspcc-dev/neofs-http-gw/extcache"
+	"github.com/nspcc-dev/neofs-http-gw/instrument"
+	"github.com/nspcc-dev/neofs-http-gw/jobs"
+	"github.com/nspcc-dev/neofs-http-gw/pathfilter"
+	"github.com/nspcc-dev/neofs-http-gw/purge"
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-http-gw/transform"
+	"github.com/nspcc-dev/neofs-http-gw/usage"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"go.uber.org/zap"
 )
 
 type AppParams struct {
-	Logger   *zap.Logger
-	Pool     *pool.Pool
-	Owner    *user.ID
-	Resolver resolver.Resolver
+	Logger      *zap.Logger
+	Backend     backend.Backend
+	Owner       *user.ID
+	Resolver    resolver.Resolver
+	Jobs        *jobs.Manager
+	Metrics     Metrics
+	Versions    *compat.Versions
+	Usage       *usage.Tracker
+	Purgers     *purge.Purgers
+	Streams     *instrument.Streams
+	ZipBuffers  *instrument.BufferPool
+	Transforms  *transform.Hooks
+	Resolutions *attrcache.Cache
+	PathFilters *pathfilter.Filters
+	ExtCache    *extcache.Invalidator
+}
+
+// Metrics collects statistics of request handlers.
+type Metrics interface {
+	AddDownloadedBytes(n int)
+	IncDownloadStalls(reason string)
+	IncUploadAborts()
 }
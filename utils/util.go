@@ -2,11 +2,61 @@ package utils
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/response"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
 )
 
+// Container resolving error codes.
+const (
+	ErrCodeContainerNotFound   = "CONTAINER_NOT_FOUND"
+	ErrCodeResolverUnavailable = "RESOLVER_UNAVAILABLE"
+)
+
+// ResolverRetryAfter is a time in seconds clients are advised to wait before
+// retrying requests failed because of resolver unavailability.
+const ResolverRetryAfter = 10
+
+// RequestContext returns the context of NeoFS operations made by the request
+// handler derived from the parent one. Unlike the parent, it's canceled by the
+// caller when the handler returns, so operations don't outlive the request.
+// The request timeout (if it's positive) and the deadline of the request
+// context set by the server are applied, the earliest of them wins.
+func RequestContext(parent context.Context, c *fasthttp.RequestCtx, timeout time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := c.Deadline()
+	if timeout > 0 {
+		if d := time.Now().Add(timeout); !ok || d.Before(deadline) {
+			deadline, ok = d, true
+		}
+	}
+	if ok {
+		return context.WithDeadline(parent, deadline)
+	}
+	return context.WithCancel(parent)
+}
+
+// RoutePrefix returns the part of the request path preceding the first of the
+// routes found in it (e.g. network prefix), it's kept in links to the gate.
+func RoutePrefix(path string, routes ...string) string {
+	end := -1
+	for _, route := range routes {
+		if i := strings.Index(path, route); i >= 0 && (end < 0 || i < end) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return ""
+	}
+	return path[:end]
+}
+
 // GetContainerID decode container id, if it's not a valid container id
 // then trey to resolve name using provided resolver.
 func GetContainerID(ctx context.Context, containerID string, resolver resolver.Resolver) (*cid.ID, error) {
@@ -17,3 +67,22 @@ func GetContainerID(ctx context.Context, containerID string, resolver resolver.R
 	}
 	return &cnrID, err
 }
+
+// ContainerIDError responds to the request with container which can't be
+// decoded or resolved: 404 if the name is unknown, 503 with Retry-After
+// header if resolvers are unavailable and 400 otherwise.
+func ContainerIDError(c *fasthttp.RequestCtx, log *zap.Logger, err error) {
+	switch {
+	case errors.Is(err, resolver.ErrNotFound):
+		log.Error("container not found", zap.Error(err))
+		response.ErrorWithCode(c, ErrCodeContainerNotFound, "container not found", fasthttp.StatusNotFound)
+	case errors.Is(err, resolver.ErrUnavailable):
+		log.Error("could not resolve container", zap.Error(err))
+		response.ErrorWithCode(c, ErrCodeResolverUnavailable, "container name resolver is unavailable, try again later",
+			fasthttp.StatusServiceUnavailable)
+		c.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(ResolverRetryAfter))
+	default:
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+	}
+}
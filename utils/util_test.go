@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/resolver"
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type failingResolver struct{}
+
+func (failingResolver) Resolve(context.Context, string) (cid.ID, error) {
+	return cid.ID{}, resolver.ErrUnavailable
+}
+
+func TestRequestContext(t *testing.T) {
+	var c fasthttp.RequestCtx
+
+	ctx, cancel := RequestContext(context.Background(), &c, 0)
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+	cancel()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	ctx, cancel = RequestContext(context.Background(), &c, time.Minute)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	// the parent is canceled with the request
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = RequestContext(parent, &c, time.Minute)
+	defer cancel()
+	cancelParent()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestGetContainerID(t *testing.T) {
+	id := cidtest.ID()
+
+	// raw container IDs don't depend on resolvers
+	res, err := GetContainerID(context.Background(), id.EncodeToString(), failingResolver{})
+	require.NoError(t, err)
+	require.Equal(t, id, *res)
+
+	_, err = GetContainerID(context.Background(), "name", failingResolver{})
+	require.ErrorIs(t, err, resolver.ErrUnavailable)
+}
+
+func TestContainerIDError(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		retryAfter string
+	}{
+		{name: "not found", err: resolver.ErrNotFound, status: fasthttp.StatusNotFound, code: ErrCodeContainerNotFound},
+		{name: "unavailable", err: resolver.ErrUnavailable, status: fasthttp.StatusServiceUnavailable,
+			code: ErrCodeResolverUnavailable, retryAfter: "10"},
+		{name: "other", err: errors.New("some error"), status: fasthttp.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			ContainerIDError(&ctx, zap.NewNop(), tc.err)
+
+			require.Equal(t, tc.status, ctx.Response.StatusCode())
+			require.Equal(t, tc.code, string(ctx.Response.Header.Peek(response.HeaderErrorCode)))
+			require.Equal(t, tc.retryAfter, string(ctx.Response.Header.Peek(fasthttp.HeaderRetryAfter)))
+		})
+	}
+}
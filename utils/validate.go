@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Path parameter validation error codes.
+const (
+	ErrCodeInvalidContainerID = "INVALID_CONTAINER_ID"
+	ErrCodeInvalidObjectID    = "INVALID_OBJECT_ID"
+	ErrCodeInvalidAddress     = "INVALID_ADDRESS"
+	ErrCodeInvalidAttribute   = "INVALID_ATTRIBUTE"
+	ErrCodeInvalidPrefix      = "INVALID_PREFIX"
+	ErrCodeInvalidJobID       = "INVALID_JOB_ID"
+	ErrCodeInvalidUploadID    = "INVALID_UPLOAD_ID"
+	ErrCodeInvalidRange       = "INVALID_RANGE"
+)
+
+// Path parameters length limits.
+const (
+	// MaxContainerParamLength is enough for NNS domain names.
+	MaxContainerParamLength = 255
+	// MaxObjectParamLength is enough for hex-encoded object ID.
+	MaxObjectParamLength = 64
+	// MaxAddressParamLength fits scheme, container name and object ID.
+	MaxAddressParamLength = len(AddressScheme) + MaxContainerParamLength + 1 + MaxObjectParamLength
+	// MaxAttributeParamLength limits attribute keys and values.
+	MaxAttributeParamLength = 1024
+	// MaxPrefixParamLength limits FilePath prefixes.
+	MaxPrefixParamLength = 1024
+	// MaxJobIDParamLength is a length of hex-encoded job ID.
+	MaxJobIDParamLength = 32
+	// MaxUploadIDParamLength limits client-generated upload IDs.
+	MaxUploadIDParamLength = 64
+	// MaxRangeParamLength fits decimal uint64 range offsets and lengths.
+	MaxRangeParamLength = 20
+)
+
+// ParamError describes invalid path parameter.
+type ParamError struct {
+	Code  string
+	Param string
+	Msg   string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid '%s' parameter: %s", e.Param, e.Msg)
+}
+
+type paramRule struct {
+	code      string
+	maxLen    int
+	unescape  bool
+	checkChar func(rune) bool
+}
+
+var pathParamRules = map[string]paramRule{
+	"cid":       {code: ErrCodeInvalidContainerID, maxLen: MaxContainerParamLength, checkChar: isDomainChar},
+	"oid":       {code: ErrCodeInvalidObjectID, maxLen: MaxObjectParamLength, checkChar: isAlphanumeric},
+	"address":   {code: ErrCodeInvalidAddress, maxLen: MaxAddressParamLength, unescape: true, checkChar: isNameChar},
+	"attr_key":  {code: ErrCodeInvalidAttribute, maxLen: MaxAttributeParamLength, unescape: true, checkChar: isPrintable},
+	"attr_val":  {code: ErrCodeInvalidAttribute, maxLen: MaxAttributeParamLength, unescape: true, checkChar: isPrintable},
+	"prefix":    {code: ErrCodeInvalidPrefix, maxLen: MaxPrefixParamLength, unescape: true, checkChar: isPrintable},
+	"path":      {code: ErrCodeInvalidAttribute, maxLen: MaxAttributeParamLength, unescape: true, checkChar: isPrintable},
+	"id":        {code: ErrCodeInvalidJobID, maxLen: MaxJobIDParamLength, checkChar: isAlphanumeric},
+	"upload_id": {code: ErrCodeInvalidUploadID, maxLen: MaxUploadIDParamLength, checkChar: isDomainChar},
+	"offset":    {code: ErrCodeInvalidRange, maxLen: MaxRangeParamLength, checkChar: isDigit},
+	"length":    {code: ErrCodeInvalidRange, maxLen: MaxRangeParamLength, checkChar: isDigit},
+}
+
+// ValidatePathParams checks known path parameters of the request, so malformed
+// values are rejected before any NeoFS call.
+func ValidatePathParams(ctx *fasthttp.RequestCtx) *ParamError {
+	var res *ParamError
+	ctx.VisitUserValues(func(key []byte, v any) {
+		if res != nil {
+			return
+		}
+		rule, ok := pathParamRules[string(key)]
+		if !ok {
+			return
+		}
+		val, ok := v.(string)
+		if !ok {
+			return
+		}
+		res = validateParam(string(key), val, rule)
+	})
+	return res
+}
+
+// ValidatePathParam checks the value of the known path parameter given not
+// from the routed request, e.g. before routing.
+func ValidatePathParam(name, val string) *ParamError {
+	rule, ok := pathParamRules[name]
+	if !ok {
+		return nil
+	}
+	return validateParam(name, val, rule)
+}
+
+// ValidateUploadID checks the upload ID given in a header with the same rule
+// as the path parameter.
+func ValidateUploadID(id string) *ParamError {
+	if id == "" {
+		return &ParamError{Code: ErrCodeInvalidUploadID, Param: "upload_id", Msg: "empty"}
+	}
+	return validateParam("upload_id", id, pathParamRules["upload_id"])
+}
+
+func validateParam(name, val string, rule paramRule) *ParamError {
+	newErr := func(msg string) *ParamError {
+		return &ParamError{Code: rule.code, Param: name, Msg: msg}
+	}
+
+	if rule.unescape {
+		unescaped, err := url.QueryUnescape(val)
+		if err != nil {
+			return newErr("invalid escaping")
+		}
+		val = unescaped
+	}
+
+	if len(val) > rule.maxLen {
+		return newErr(fmt.Sprintf("too long, max length is %d", rule.maxLen))
+	}
+	if !utf8.ValidString(val) {
+		return newErr("invalid UTF-8")
+	}
+	for _, r := range val {
+		if !rule.checkChar(r) {
+			return newErr(fmt.Sprintf("forbidden character %q", r))
+		}
+	}
+
+	return nil
+}
+
+func isPrintable(r rune) bool {
+	return unicode.IsPrint(r)
+}
+
+// isDomainChar allows characters of IDs and NNS names.
+func isDomainChar(r rune) bool {
+	return isAlphanumeric(r) || r == '.' || r == '-' || r == '_'
+}
+
+// isNameChar allows characters of IDs, NNS names and address separators.
+func isNameChar(r rune) bool {
+	return isDomainChar(r) || r == ':' || r == '/'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isAlphanumeric(r rune) bool {
+	return r < utf8.RuneSelf && (r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+}
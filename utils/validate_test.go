@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestValidatePathParams(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		params map[string]string
+		code   string
+	}{
+		{
+			name:   "valid",
+			params: map[string]string{"cid": "container.neofs", "oid": "9ivdDzTiDbE5VYoRW6GXYNRqrmXQrxm1Fk3bFjmeNUY9"},
+		},
+		{
+			name:   "valid attribute",
+			params: map[string]string{"attr_key": "FileName", "attr_val": "cat%20photo.jpg"},
+		},
+		{
+			name:   "valid address",
+			params: map[string]string{"address": "neofs%3A%2F%2Fcid%2Foid"},
+		},
+		{
+			name:   "unknown params are ignored",
+			params: map[string]string{"other": "\x00"},
+		},
+		{
+			name:   "long container",
+			params: map[string]string{"cid": strings.Repeat("a", MaxContainerParamLength+1)},
+			code:   ErrCodeInvalidContainerID,
+		},
+		{
+			name:   "object with forbidden characters",
+			params: map[string]string{"oid": "9ivdDzTiDbE5VYoRW6GXYNRqrmXQ-"},
+			code:   ErrCodeInvalidObjectID,
+		},
+		{
+			name:   "control character in escaped attribute",
+			params: map[string]string{"attr_val": "value%00"},
+			code:   ErrCodeInvalidAttribute,
+		},
+		{
+			name:   "invalid escaping",
+			params: map[string]string{"prefix": "%zz"},
+			code:   ErrCodeInvalidPrefix,
+		},
+		{
+			name:   "long path",
+			params: map[string]string{"path": strings.Repeat("a", MaxAttributeParamLength+1)},
+			code:   ErrCodeInvalidAttribute,
+		},
+		{
+			name:   "invalid utf-8",
+			params: map[string]string{"attr_key": "%ff"},
+			code:   ErrCodeInvalidAttribute,
+		},
+		{
+			name:   "upload id with forbidden characters",
+			params: map[string]string{"upload_id": "upload/1"},
+			code:   ErrCodeInvalidUploadID,
+		},
+		{
+			name:   "valid range",
+			params: map[string]string{"offset": "0", "length": "1048576"},
+		},
+		{
+			name:   "negative range offset",
+			params: map[string]string{"offset": "-1"},
+			code:   ErrCodeInvalidRange,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			for k, v := range tc.params {
+				ctx.SetUserValue(k, v)
+			}
+
+			err := ValidatePathParams(&ctx)
+			if tc.code == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Equal(t, tc.code, err.Code)
+		})
+	}
+}
+
+func TestValidateUploadID(t *testing.T) {
+	require.Nil(t, ValidateUploadID("f47ac10b-58cc-4372-a567-0e02b2c3d479"))
+	require.Nil(t, ValidateUploadID(strings.Repeat("a", MaxUploadIDParamLength)))
+
+	for _, id := range []string{"", "a b", "a/b", strings.Repeat("a", MaxUploadIDParamLength+1)} {
+		err := ValidateUploadID(id)
+		require.NotNil(t, err, id)
+		require.Equal(t, ErrCodeInvalidUploadID, err.Code)
+	}
+}
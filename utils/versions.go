@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+const (
+	// AttributeContentLanguage is an object attribute with the language of
+	// the content.
+	AttributeContentLanguage = "Content-Language"
+	// AttributeDeleteMarker marks objects hiding older versions of the file
+	// with the same FilePath, see soft deletion of path-based objects.
+	AttributeDeleteMarker = "Delete-Marker"
+)
+
+// ObjectVersion is one of the objects with the same FilePath. Objects are
+// immutable, so uploads to the same path make new versions of the file.
+type ObjectVersion struct {
+	ID            oid.ID
+	Timestamp     int64
+	CreationEpoch uint64
+	Size          uint64
+	ContentType   string
+	Language      string
+	DeleteMarker  bool
+}
+
+// NewObjectVersion returns the version of the object with the given header.
+func NewObjectVersion(id oid.ID, hdr *object.Object) ObjectVersion {
+	res := ObjectVersion{ID: id, CreationEpoch: hdr.CreationEpoch(), Size: hdr.PayloadSize()}
+	for _, attr := range hdr.Attributes() {
+		switch attr.Key() {
+		case object.AttributeTimestamp:
+			res.Timestamp, _ = strconv.ParseInt(attr.Value(), 10, 64)
+		case object.AttributeContentType:
+			res.ContentType = attr.Value()
+		case AttributeContentLanguage:
+			res.Language = attr.Value()
+		case AttributeDeleteMarker:
+			res.DeleteMarker, _ = strconv.ParseBool(attr.Value())
+		}
+	}
+	return res
+}
+
+// NewerThan checks whether the version is newer than the other one. Versions
+// are ordered by Timestamp, then by creation epoch, objects made at the same
+// time are ordered by ID, so the newest version is always the same one.
+func (v ObjectVersion) NewerThan(other ObjectVersion) bool {
+	if v.Timestamp != other.Timestamp {
+		return v.Timestamp > other.Timestamp
+	}
+	if v.CreationEpoch != other.CreationEpoch {
+		return v.CreationEpoch > other.CreationEpoch
+	}
+	return bytes.Compare(v.ID[:], other.ID[:]) > 0
+}
+
+// SortVersions sorts versions from the newest to the oldest.
+func SortVersions(versions []ObjectVersion) {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].NewerThan(versions[j])
+	})
+}
+
+// LiveVersions returns the sorted versions newer than the newest delete
+// marker, the file is removed if there are none.
+func LiveVersions(versions []ObjectVersion) []ObjectVersion {
+	for i := range versions {
+		if versions[i].DeleteMarker {
+			return versions[:i]
+		}
+	}
+	return versions
+}
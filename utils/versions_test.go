@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortVersions(t *testing.T) {
+	ids := []oid.ID{oidtest.ID(), oidtest.ID()}
+	if bytes.Compare(ids[0][:], ids[1][:]) > 0 {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+
+	versions := []ObjectVersion{
+		{ID: ids[0], Timestamp: 10, CreationEpoch: 2},
+		{ID: ids[1], Timestamp: 10, CreationEpoch: 1},
+		{ID: ids[0], Timestamp: 10, CreationEpoch: 1},
+		{ID: ids[0], Timestamp: 20},
+		{ID: ids[1]},
+	}
+	SortVersions(versions)
+	require.Equal(t, []ObjectVersion{
+		{ID: ids[0], Timestamp: 20},
+		{ID: ids[0], Timestamp: 10, CreationEpoch: 2},
+		{ID: ids[1], Timestamp: 10, CreationEpoch: 1},
+		{ID: ids[0], Timestamp: 10, CreationEpoch: 1},
+		{ID: ids[1]},
+	}, versions)
+}
+
+func TestLiveVersions(t *testing.T) {
+	versions := []ObjectVersion{
+		{Timestamp: 30},
+		{Timestamp: 20, DeleteMarker: true},
+		{Timestamp: 10},
+	}
+	require.Equal(t, versions[:1], LiveVersions(versions))
+	require.Empty(t, LiveVersions(versions[1:]))
+	require.Equal(t, versions[2:], LiveVersions(versions[2:]))
+}
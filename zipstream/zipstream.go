@@ -0,0 +1,551 @@
+// Package zipstream writes zip archives to a stream with ZIP64 extensions
+// for entries and archives exceeding 4 GiB or 65535 entries.
+//
+// Unlike archive/zip, ZIP64 extra field is written to the local header of
+// entries expected to be large, so readers processing the archive as a stream
+// can read 64-bit sizes from data descriptors.
+package zipstream
+
+import (
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// Compression methods.
+const (
+	Store   uint16 = 0
+	Deflate uint16 = 8
+)
+
+const (
+	localHeaderSignature     = 0x04034b50
+	dataDescriptorSignature  = 0x08074b50
+	centralHeaderSignature   = 0x02014b50
+	endSignature             = 0x06054b50
+	zip64EndSignature        = 0x06064b50
+	zip64EndLocatorSignature = 0x07064b50
+
+	localHeaderLen     = 30
+	centralHeaderLen   = 46
+	endLen             = 22
+	zip64EndLen        = 56
+	zip64EndLocatorLen = 20
+
+	zipVersion20 = 20
+	zipVersion45 = 45
+	creatorUnix  = 3
+
+	flagDataDescriptor = 0x8
+	flagUTF8           = 0x800
+
+	zip64ExtraID   = 0x0001
+	extTimeExtraID = 0x5455
+
+	uint16max = 1<<16 - 1
+	uint32max = 1<<32 - 1
+
+	// zip64SizeThreshold is a size of entries written with ZIP64 local
+	// header, it leaves room for deflate overhead.
+	zip64SizeThreshold = uint32max - 16<<20
+
+	// fileMode is an external attribute of entries: regular file with 0644
+	// permissions.
+	fileMode = 0o100644 << 16
+)
+
+// Skipper is implemented by writers discarding the beginning of the archive,
+// e.g. when only its range is sent. Skipped bytes aren't passed to them.
+type Skipper interface {
+	// Skip advances the position in the archive by n bytes.
+	Skip(n uint64) error
+}
+
+// ErrSizeExceeded is returned if the entry requiring ZIP64 local header
+// exceeds 4 GiB without declaring the size in FileHeader.
+var ErrSizeExceeded = errors.New("entry exceeds 4 GiB, but its declared size is smaller")
+
+// FileHeader describes an archive entry.
+type FileHeader struct {
+	Name     string
+	Modified time.Time
+	Method   uint16
+	// Size is an expected uncompressed size of the entry, entries of 4 GiB
+	// and more must declare it to be written with ZIP64 local header.
+	Size uint64
+}
+
+type entry struct {
+	FileHeader
+	offset           uint64
+	crc32            uint32
+	compressedSize   uint64
+	uncompressedSize uint64
+	zip64            bool
+}
+
+// Writer writes zip archive to the stream. Each entry is followed by data
+// descriptor with its CRC-32 and sizes.
+type Writer struct {
+	w      *countWriter
+	dir    []*entry
+	cur    *fileWriter
+	closed bool
+}
+
+// NewWriter creates a Writer writing the archive to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: &countWriter{w: w}}
+}
+
+// Create adds an entry to the archive and returns a writer for its contents.
+// The writer must be used before the next call of Create or Close.
+func (w *Writer) Create(fh FileHeader) (io.Writer, error) {
+	if w.closed {
+		return nil, errors.New("zip: writer is closed")
+	}
+	if err := w.closeEntry(); err != nil {
+		return nil, err
+	}
+	if len(fh.Name) > uint16max {
+		return nil, errors.New("zip: file name is too long")
+	}
+
+	e := &entry{
+		FileHeader: fh,
+		offset:     w.w.count,
+		zip64:      fh.Size >= zip64SizeThreshold,
+	}
+
+	if err := w.writeLocalHeader(e); err != nil {
+		return nil, err
+	}
+
+	fw := &fileWriter{entry: e, crc: crc32.NewIEEE(), compCount: &countWriter{w: w.w}}
+	switch fh.Method {
+	case Store:
+		fw.comp = nopCloser{fw.compCount}
+	case Deflate:
+		comp, err := flate.NewWriter(fw.compCount, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		fw.comp = comp
+	default:
+		return nil, fmt.Errorf("zip: unsupported compression method %d", fh.Method)
+	}
+
+	w.dir = append(w.dir, e)
+	w.cur = fw
+	return fw, nil
+}
+
+// DataOffset returns the offset in the archive the contents of the entry
+// start at if it's added next.
+func (w *Writer) DataOffset(fh FileHeader) uint64 {
+	offset := w.w.count
+	if w.cur != nil {
+		offset += descriptorLen(w.cur.entry)
+	}
+	e := &entry{FileHeader: fh, zip64: fh.Size >= zip64SizeThreshold}
+	return offset + localHeaderLen + uint64(len(fh.Name)+len(localExtra(e)))
+}
+
+// CreateAt adds the stored entry of the declared size with known CRC-32 of
+// its contents, they're written starting from the offset. Preceding bytes are
+// skipped, so the underlying writer must implement Skipper.
+func (w *Writer) CreateAt(fh FileHeader, crc uint32, offset uint64) (io.Writer, error) {
+	if fh.Method != Store {
+		return nil, errors.New("zip: only stored entries can be created at offset")
+	}
+	if offset > fh.Size {
+		return nil, errors.New("zip: offset exceeds entry size")
+	}
+	s, ok := w.w.w.(Skipper)
+	if !ok {
+		return nil, errors.New("zip: writer can't skip bytes")
+	}
+
+	fw, err := w.Create(fh)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Skip(offset); err != nil {
+		return nil, err
+	}
+	w.w.count += offset
+	w.cur.compCount.count = offset
+	w.cur.rawCount = offset
+	w.cur.crc = nil
+	w.cur.entry.crc32 = crc
+	return fw, nil
+}
+
+func (w *Writer) closeEntry() error {
+	if w.cur == nil {
+		return nil
+	}
+	fw := w.cur
+	w.cur = nil
+
+	if err := fw.comp.Close(); err != nil {
+		return err
+	}
+
+	e := fw.entry
+	if fw.crc != nil {
+		e.crc32 = fw.crc.Sum32()
+	}
+	e.compressedSize = fw.compCount.count
+	e.uncompressedSize = fw.rawCount
+	if !e.zip64 && (e.compressedSize >= uint32max || e.uncompressedSize >= uint32max) {
+		return ErrSizeExceeded
+	}
+
+	buf := make([]byte, descriptorLen(e))
+	if e.zip64 {
+		b := writeBuf(buf)
+		b.uint32(dataDescriptorSignature)
+		b.uint32(e.crc32)
+		b.uint64(e.compressedSize)
+		b.uint64(e.uncompressedSize)
+	} else {
+		b := writeBuf(buf)
+		b.uint32(dataDescriptorSignature)
+		b.uint32(e.crc32)
+		b.uint32(uint32(e.compressedSize))
+		b.uint32(uint32(e.uncompressedSize))
+	}
+	_, err := w.w.Write(buf)
+	return err
+}
+
+// localExtra returns extra field of the entry local header.
+func localExtra(e *entry) []byte {
+	extra := timeExtra(e.Modified)
+	if e.zip64 {
+		// sizes are unknown, so they're zero in the extra field and written
+		// to the data descriptor
+		var zip64Extra [20]byte
+		b := writeBuf(zip64Extra[:])
+		b.uint16(zip64ExtraID)
+		b.uint16(16)
+		extra = append(zip64Extra[:], extra...)
+	}
+	return extra
+}
+
+func descriptorLen(e *entry) uint64 {
+	if e.zip64 {
+		return 24
+	}
+	return 16
+}
+
+func (w *Writer) writeLocalHeader(e *entry) error {
+	extra := localExtra(e)
+	version := uint16(zipVersion20)
+	size := uint32(0)
+	if e.zip64 {
+		version = zipVersion45
+		size = uint32max
+	}
+
+	date, tm := msDosTime(e.Modified)
+	buf := make([]byte, localHeaderLen, localHeaderLen+len(e.Name)+len(extra))
+	b := writeBuf(buf)
+	b.uint32(localHeaderSignature)
+	b.uint16(version)
+	b.uint16(flags(e.Name))
+	b.uint16(e.Method)
+	b.uint16(tm)
+	b.uint16(date)
+	b.uint32(0) // CRC-32 is in the data descriptor
+	b.uint32(size)
+	b.uint32(size)
+	b.uint16(uint16(len(e.Name)))
+	b.uint16(uint16(len(extra)))
+	buf = append(buf, e.Name...)
+	buf = append(buf, extra...)
+
+	_, err := w.w.Write(buf)
+	return err
+}
+
+// Close finishes the last entry and writes the central directory. It doesn't
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return errors.New("zip: writer is closed")
+	}
+	if err := w.closeEntry(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	dirOffset := w.w.count
+	for _, e := range w.dir {
+		if err := w.writeCentralHeader(e); err != nil {
+			return err
+		}
+	}
+	dirSize := w.w.count - dirOffset
+
+	records := uint64(len(w.dir))
+	if needZip64End(records, dirSize, dirOffset) {
+		zip64EndOffset := w.w.count
+
+		var buf [zip64EndLen + zip64EndLocatorLen]byte
+		b := writeBuf(buf[:])
+		b.uint32(zip64EndSignature)
+		b.uint64(zip64EndLen - 12) // size of the record without signature and size
+		b.uint16(creatorUnix<<8 | zipVersion45)
+		b.uint16(zipVersion45)
+		b.uint32(0) // number of this disk
+		b.uint32(0) // number of the disk with the central directory
+		b.uint64(records)
+		b.uint64(records)
+		b.uint64(dirSize)
+		b.uint64(dirOffset)
+
+		b.uint32(zip64EndLocatorSignature)
+		b.uint32(0) // number of the disk with zip64 end record
+		b.uint64(zip64EndOffset)
+		b.uint32(1) // total number of disks
+
+		if _, err := w.w.Write(buf[:]); err != nil {
+			return err
+		}
+
+		if records > uint16max {
+			records = uint16max
+		}
+		if dirSize > uint32max {
+			dirSize = uint32max
+		}
+		if dirOffset > uint32max {
+			dirOffset = uint32max
+		}
+	}
+
+	var buf [endLen]byte
+	b := writeBuf(buf[:])
+	b.uint32(endSignature)
+	b.uint16(0) // number of this disk
+	b.uint16(0) // number of the disk with the central directory
+	b.uint16(uint16(records))
+	b.uint16(uint16(records))
+	b.uint32(uint32(dirSize))
+	b.uint32(uint32(dirOffset))
+	b.uint16(0) // comment length
+
+	_, err := w.w.Write(buf[:])
+	return err
+}
+
+// centralExtra returns extra field of the entry central directory header
+// and whether it contains ZIP64 fields.
+func centralExtra(e *entry) ([]byte, bool) {
+	var zip64Fields []uint64
+	// fields in the extra must be in this order
+	if e.uncompressedSize >= uint32max {
+		zip64Fields = append(zip64Fields, e.uncompressedSize)
+	}
+	if e.compressedSize >= uint32max {
+		zip64Fields = append(zip64Fields, e.compressedSize)
+	}
+	if e.offset >= uint32max {
+		zip64Fields = append(zip64Fields, e.offset)
+	}
+
+	extra := timeExtra(e.Modified)
+	if len(zip64Fields) == 0 {
+		return extra, false
+	}
+
+	zip64Extra := make([]byte, 4+8*len(zip64Fields))
+	b := writeBuf(zip64Extra)
+	b.uint16(zip64ExtraID)
+	b.uint16(uint16(8 * len(zip64Fields)))
+	for _, f := range zip64Fields {
+		b.uint64(f)
+	}
+	return append(zip64Extra, extra...), true
+}
+
+func needZip64End(records, dirSize, dirOffset uint64) bool {
+	return records >= uint16max || dirSize >= uint32max || dirOffset >= uint32max
+}
+
+// StoredSize returns the size of the archive with entries of declared sizes
+// written with Store method. It allows to know the archive size before it's
+// written.
+func StoredSize(headers []FileHeader) (uint64, error) {
+	var offset, dirSize uint64
+	for _, fh := range headers {
+		if fh.Method != Store {
+			return 0, errors.New("zip: size is known only for stored entries")
+		}
+		e := &entry{
+			FileHeader:       fh,
+			offset:           offset,
+			compressedSize:   fh.Size,
+			uncompressedSize: fh.Size,
+			zip64:            fh.Size >= zip64SizeThreshold,
+		}
+		extra, _ := centralExtra(e)
+		offset += localHeaderLen + uint64(len(fh.Name)+len(localExtra(e))) + fh.Size + descriptorLen(e)
+		dirSize += centralHeaderLen + uint64(len(fh.Name)+len(extra))
+	}
+
+	size := offset + dirSize + endLen
+	if needZip64End(uint64(len(headers)), dirSize, offset) {
+		size += zip64EndLen + zip64EndLocatorLen
+	}
+	return size, nil
+}
+
+func (w *Writer) writeCentralHeader(e *entry) error {
+	extra, zip64Fields := centralExtra(e)
+	version := uint16(zipVersion20)
+	if e.zip64 || zip64Fields {
+		version = zipVersion45
+	}
+
+	compressedSize, uncompressedSize, offset := uint32(e.compressedSize), uint32(e.uncompressedSize), uint32(e.offset)
+	if e.uncompressedSize >= uint32max {
+		uncompressedSize = uint32max
+	}
+	if e.compressedSize >= uint32max {
+		compressedSize = uint32max
+	}
+	if e.offset >= uint32max {
+		offset = uint32max
+	}
+
+	date, tm := msDosTime(e.Modified)
+	buf := make([]byte, centralHeaderLen, centralHeaderLen+len(e.Name)+len(extra))
+	b := writeBuf(buf)
+	b.uint32(centralHeaderSignature)
+	b.uint16(creatorUnix<<8 | version)
+	b.uint16(version)
+	b.uint16(flags(e.Name))
+	b.uint16(e.Method)
+	b.uint16(tm)
+	b.uint16(date)
+	b.uint32(e.crc32)
+	b.uint32(compressedSize)
+	b.uint32(uncompressedSize)
+	b.uint16(uint16(len(e.Name)))
+	b.uint16(uint16(len(extra)))
+	b.uint16(0) // comment length
+	b.uint16(0) // disk number start
+	b.uint16(0) // internal attributes
+	b.uint32(fileMode)
+	b.uint32(offset)
+	buf = append(buf, e.Name...)
+	buf = append(buf, extra...)
+
+	_, err := w.w.Write(buf)
+	return err
+}
+
+func flags(name string) uint16 {
+	f := uint16(flagDataDescriptor)
+	for i := 0; i < len(name); i++ {
+		if name[i] >= utf8.RuneSelf {
+			f |= flagUTF8
+			break
+		}
+	}
+	return f
+}
+
+// timeExtra returns extended timestamp extra field with modification time.
+func timeExtra(t time.Time) []byte {
+	if t.IsZero() {
+		return nil
+	}
+	buf := make([]byte, 9)
+	b := writeBuf(buf)
+	b.uint16(extTimeExtraID)
+	b.uint16(5)
+	b.uint8(1) // modification time flag
+	b.uint32(uint32(t.Unix()))
+	return buf
+}
+
+// msDosTime converts t to MS-DOS date and time, times before 1980 are
+// replaced with 1980-01-01.
+func msDosTime(t time.Time) (uint16, uint16) {
+	if t.IsZero() || t.Year() < 1980 {
+		t = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	t = t.UTC()
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	tm := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, tm
+}
+
+type fileWriter struct {
+	entry *entry
+	// crc is nil if CRC-32 of the contents is known in advance
+	crc       hash.Hash32
+	comp      io.WriteCloser
+	compCount *countWriter
+	rawCount  uint64
+}
+
+func (f *fileWriter) Write(p []byte) (int, error) {
+	if f.crc != nil {
+		f.crc.Write(p)
+	}
+	n, err := f.comp.Write(p)
+	f.rawCount += uint64(n)
+	return n, err
+}
+
+type countWriter struct {
+	w     io.Writer
+	count uint64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += uint64(n)
+	return n, err
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+type writeBuf []byte
+
+func (b *writeBuf) uint8(v uint8) {
+	(*b)[0] = v
+	*b = (*b)[1:]
+}
+
+func (b *writeBuf) uint16(v uint16) {
+	binary.LittleEndian.PutUint16(*b, v)
+	*b = (*b)[2:]
+}
+
+func (b *writeBuf) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(*b, v)
+	*b = (*b)[4:]
+}
+
+func (b *writeBuf) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(*b, v)
+	*b = (*b)[8:]
+}
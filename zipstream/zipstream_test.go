@@ -0,0 +1,273 @@
+package zipstream
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readArchive(t *testing.T, data []byte) *zip.Reader {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return r
+}
+
+func readEntry(t *testing.T, f *zip.File) string {
+	r, err := f.Open()
+	require.NoError(t, err)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestWriter(t *testing.T) {
+	modified := time.Date(2023, time.May, 4, 12, 30, 10, 0, time.UTC)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	for _, fh := range []FileHeader{
+		{Name: "stored.txt", Method: Store, Modified: modified},
+		{Name: "dir/deflated.txt", Method: Deflate, Modified: modified},
+		{Name: "файл.txt", Method: Store},
+	} {
+		fw, err := w.Create(fh)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("content of " + fh.Name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.Error(t, w.Close())
+
+	r := readArchive(t, buf.Bytes())
+	require.Len(t, r.File, 3)
+
+	require.Equal(t, "stored.txt", r.File[0].Name)
+	require.Equal(t, zip.Store, r.File[0].Method)
+	require.True(t, modified.Equal(r.File[0].Modified))
+	require.Equal(t, "content of stored.txt", readEntry(t, r.File[0]))
+
+	require.Equal(t, "dir/deflated.txt", r.File[1].Name)
+	require.Equal(t, zip.Deflate, r.File[1].Method)
+	require.Equal(t, "content of dir/deflated.txt", readEntry(t, r.File[1]))
+
+	require.Equal(t, "файл.txt", r.File[2].Name)
+	require.Equal(t, "content of файл.txt", readEntry(t, r.File[2]))
+
+	_, err := NewWriter(io.Discard).Create(FileHeader{Name: "a", Method: 99})
+	require.Error(t, err)
+}
+
+func TestZip64(t *testing.T) {
+	t.Run("large entry", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+
+		fw, err := w.Create(FileHeader{Name: "large", Size: uint32max})
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("data"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		data := buf.Bytes()
+		require.Equal(t, uint16(zipVersion45), binary.LittleEndian.Uint16(data[4:]))
+		require.Equal(t, uint32(uint32max), binary.LittleEndian.Uint32(data[22:]))
+		nameLen := int(binary.LittleEndian.Uint16(data[26:]))
+		require.Equal(t, uint16(zip64ExtraID), binary.LittleEndian.Uint16(data[localHeaderLen+nameLen:]))
+
+		// 64-bit sizes in data descriptor
+		descriptor := data[localHeaderLen+nameLen+int(binary.LittleEndian.Uint16(data[28:]))+4:]
+		require.Equal(t, uint32(dataDescriptorSignature), binary.LittleEndian.Uint32(descriptor))
+		require.Equal(t, uint64(4), binary.LittleEndian.Uint64(descriptor[8:]))
+		require.Equal(t, uint64(4), binary.LittleEndian.Uint64(descriptor[16:]))
+
+		r := readArchive(t, data)
+		require.Len(t, r.File, 1)
+		require.Equal(t, "data", readEntry(t, r.File[0]))
+	})
+
+	t.Run("many entries", func(t *testing.T) {
+		const n = uint16max + 10
+
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		for i := 0; i < n; i++ {
+			_, err := w.Create(FileHeader{Name: strconv.Itoa(i)})
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		r := readArchive(t, buf.Bytes())
+		require.Len(t, r.File, n)
+		require.Equal(t, strconv.Itoa(n-1), r.File[n-1].Name)
+	})
+}
+
+func TestStoredSize(t *testing.T) {
+	modified := time.Date(2023, time.May, 4, 12, 30, 10, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name    string
+		headers []FileHeader
+	}{
+		{name: "empty"},
+		{name: "entries", headers: []FileHeader{
+			{Name: "a.txt", Size: 10, Modified: modified},
+			{Name: "dir/b.txt", Size: 0},
+		}},
+		{name: "large entry", headers: []FileHeader{
+			{Name: "a.txt", Size: 10},
+			{Name: "large", Size: uint32max + 1},
+			{Name: "b.txt", Size: 10},
+		}},
+		{name: "many entries", headers: make([]FileHeader, uint16max+1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if testing.Short() && tc.name == "large entry" {
+				t.Skip("large entry is written for too long")
+			}
+
+			w := &countWriter{w: io.Discard}
+			zw := NewWriter(w)
+			buf := make([]byte, 1<<20)
+			for _, fh := range tc.headers {
+				fw, err := zw.Create(fh)
+				require.NoError(t, err)
+				for left := fh.Size; left > 0; {
+					n := uint64(len(buf))
+					if left < n {
+						n = left
+					}
+					_, err = fw.Write(buf[:n])
+					require.NoError(t, err)
+					left -= n
+				}
+			}
+			require.NoError(t, zw.Close())
+
+			size, err := StoredSize(tc.headers)
+			require.NoError(t, err)
+			require.Equal(t, w.count, size)
+		})
+	}
+
+	_, err := StoredSize([]FileHeader{{Name: "a", Method: Deflate}})
+	require.Error(t, err)
+}
+
+func BenchmarkWriter(b *testing.B) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 1<<12)
+
+	for _, method := range []uint16{Store, Deflate} {
+		b.Run("method "+strconv.Itoa(int(method)), func(b *testing.B) {
+			b.SetBytes(int64(10 * len(payload)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := NewWriter(io.Discard)
+				for j := 0; j < 10; j++ {
+					fw, err := w.Create(FileHeader{Name: "file" + strconv.Itoa(j), Method: method})
+					if err != nil {
+						b.Fatal(err)
+					}
+					if _, err = fw.Write(payload); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// skipWriter writes the archive from the start offset, bytes before it must
+// be skipped.
+type skipWriter struct {
+	bytes.Buffer
+	start, pos uint64
+}
+
+func (w *skipWriter) Write(p []byte) (int, error) {
+	from := w.pos
+	w.pos += uint64(len(p))
+	if w.pos <= w.start {
+		return len(p), nil
+	}
+	if from < w.start {
+		p = p[w.start-from:]
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *skipWriter) Skip(n uint64) error {
+	if w.pos+n > w.start {
+		return io.ErrShortWrite
+	}
+	w.pos += n
+	return nil
+}
+
+func TestCreateAt(t *testing.T) {
+	contents := []string{"first entry", "second entry", "third entry"}
+	write := func(w io.Writer, create func(zw *Writer, fh FileHeader, data string) error) {
+		zw := NewWriter(w)
+		for i, data := range contents {
+			require.NoError(t, create(zw, FileHeader{Name: strconv.Itoa(i), Size: uint64(len(data))}, data))
+		}
+		require.NoError(t, zw.Close())
+	}
+
+	var full bytes.Buffer
+	var offsets []uint64
+	write(&full, func(zw *Writer, fh FileHeader, data string) error {
+		offsets = append(offsets, zw.DataOffset(fh))
+		fw, err := zw.Create(fh)
+		if err == nil {
+			_, err = fw.Write([]byte(data))
+		}
+		return err
+	})
+	require.Equal(t, contents[1], string(full.Bytes()[offsets[1]:offsets[1]+uint64(len(contents[1]))]))
+
+	// the first entry is skipped, the second one is written from the middle
+	start := offsets[1] + 3
+	w := &skipWriter{start: start}
+	write(w, func(zw *Writer, fh FileHeader, data string) error {
+		offset := zw.DataOffset(fh)
+		if offset >= start {
+			fw, err := zw.Create(fh)
+			if err == nil {
+				_, err = fw.Write([]byte(data))
+			}
+			return err
+		}
+		skip := start - offset
+		if skip > fh.Size {
+			skip = fh.Size
+		}
+		fw, err := zw.CreateAt(fh, crc32.ChecksumIEEE([]byte(data)), skip)
+		if err == nil {
+			_, err = fw.Write([]byte(data[skip:]))
+		}
+		return err
+	})
+	require.Equal(t, full.Bytes()[start:], w.Bytes())
+	readArchive(t, full.Bytes())
+
+	_, err := NewWriter(io.Discard).CreateAt(FileHeader{Name: "a", Size: 1}, 0, 1)
+	require.Error(t, err, "writer can't skip")
+	_, err = NewWriter(&skipWriter{start: 100}).CreateAt(FileHeader{Name: "a", Size: 1}, 0, 2)
+	require.Error(t, err, "offset exceeds size")
+	_, err = NewWriter(&skipWriter{start: 100}).CreateAt(FileHeader{Name: "a", Method: Deflate}, 0, 0)
+	require.Error(t, err, "deflated entry")
+}